@@ -0,0 +1,34 @@
+// Command errcatalog prints the registered MCP error codes as a Markdown
+// reference (default) or JSON for client developers.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the catalog as JSON")
+	flag.Parse()
+
+	registry := mcperrors.DefaultRegistry()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(registry.Catalog()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("# Error Code Reference")
+	fmt.Println()
+	fmt.Println("Generated by `make error-reference`; do not edit by hand.")
+	fmt.Println()
+	fmt.Print(registry.Reference())
+}
@@ -0,0 +1,128 @@
+// Command mcpbench load-tests an MCP server. It opens concurrent client
+// connections, performs the handshakes and drives a weighted mix of
+// requests at a target rate, then reports latency percentiles and error
+// rates:
+//
+//	mcpbench -socket /run/mcp.sock -c 50 -d 30s -rps 2000 -mix "tools/list=1,call:echo=4" -args 'echo={"message":"hi"}'
+//	mcpbench -sse http://localhost:8080/sse -c 10 -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/conformance"
+	"github.com/meta-mcp/meta-mcp-server/internal/loadtest"
+)
+
+// stringsFlag collects a repeated flag
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func main() {
+	fs := flag.NewFlagSet("mcpbench", flag.ExitOnError)
+	stdio := fs.String("stdio", "", "command line of a server to start for each connection")
+	sse := fs.String("sse", "", "URL of an SSE server endpoint")
+	socket := fs.String("socket", "", "unix socket path, or tcp:host:port, of a server")
+	connections := fs.Int("c", 10, "concurrent connections")
+	duration := fs.Duration("d", 10*time.Second, "how long to send requests for")
+	rps := fs.Float64("rps", 0, "target requests per second across all connections (0 for no limit)")
+	mixSpec := fs.String("mix", "tools/list", `weighted operations, e.g. "tools/list=1,call:echo=4"`)
+	var argSpecs stringsFlag
+	fs.Var(&argSpecs, "args", `tool arguments as tool=json, e.g. 'echo={"message":"hi"}' (repeatable)`)
+	timeout := fs.Duration("timeout", 10*time.Second, "time allowed for each request")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	maxErrorRate := fs.Float64("max-error-rate", 1, "exit non-zero if the error rate exceeds this fraction")
+	fs.Parse(os.Args[1:])
+
+	if err := run(runOptions{
+		dial:         dialer(*stdio, *sse, *socket),
+		config:       loadtest.Config{Connections: *connections, Duration: *duration, RPS: *rps, Timeout: *timeout},
+		mix:          *mixSpec,
+		args:         argSpecs,
+		asJSON:       *asJSON,
+		maxErrorRate: *maxErrorRate,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "mcpbench:", err)
+		os.Exit(1)
+	}
+}
+
+// runOptions are the parsed command-line options
+type runOptions struct {
+	dial         conformance.Dialer
+	config       loadtest.Config
+	mix          string
+	args         []string
+	asJSON       bool
+	maxErrorRate float64
+}
+
+// run runs the load test and prints the report
+func run(opts runOptions) error {
+	if opts.dial == nil {
+		return errors.New("exactly one of -stdio, -sse or -socket is required")
+	}
+	args, err := loadtest.ParseArgs(opts.args)
+	if err != nil {
+		return fmt.Errorf("-args: %w", err)
+	}
+	opts.config.Mix, err = loadtest.ParseMix(opts.mix, args)
+	if err != nil {
+		return fmt.Errorf("-mix: %w", err)
+	}
+
+	// Interrupting ends the run early but still prints the report
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, opts.dial, opts.config)
+	if err != nil {
+		return err
+	}
+	if opts.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if rate := report.ErrorRate(); rate > opts.maxErrorRate {
+		return fmt.Errorf("error rate %.2f%% exceeds %.2f%%", 100*rate, 100*opts.maxErrorRate)
+	}
+	return nil
+}
+
+// dialer returns a dialer for exactly one of the server flags, or nil
+func dialer(stdio, sse, socket string) conformance.Dialer {
+	switch {
+	case stdio != "" && sse == "" && socket == "":
+		return conformance.StdioDialer(strings.Fields(stdio), nil)
+	case sse != "" && stdio == "" && socket == "":
+		return conformance.SSEDialer(sse)
+	case socket != "" && stdio == "" && sse == "":
+		network, address := "unix", socket
+		if rest, ok := strings.CutPrefix(address, "tcp:"); ok {
+			network, address = "tcp", rest
+		}
+		return conformance.SocketDialer(network, address)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// command is one mcpctl command, selected by its leading words
+type command struct {
+	name  string
+	words []string
+	fn    func(ctx context.Context, s *session, args []string) error
+}
+
+// commands are matched in order against the leading arguments
+var commands = []command{
+	{"tools list", []string{"tools", "list"}, listTools},
+	{"tools call", []string{"tools", "call"}, callTool},
+	{"resources list", []string{"resources", "list"}, listResources},
+	{"resources read", []string{"resources", "read"}, readResource},
+	{"prompts list", []string{"prompts", "list"}, listPrompts},
+	{"rpc", []string{"rpc"}, rawRPC},
+}
+
+// lookupCommand finds the command named by the leading arguments
+func lookupCommand(args []string) (command, bool) {
+	for _, cmd := range commands {
+		if len(args) < len(cmd.words) {
+			continue
+		}
+		matched := true
+		for i, word := range cmd.words {
+			if args[i] != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printJSON writes v as indented JSON to stdout
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// listTools prints the server's tools
+func listTools(ctx context.Context, s *session, args []string) error {
+	fs := flag.NewFlagSet("tools list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the full tool definitions as JSON")
+	fs.Parse(args)
+
+	result, err := s.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result.Tools)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDESCRIPTION")
+	for _, tool := range result.Tools {
+		fmt.Fprintf(tw, "%s\t%s\n", tool.Name, firstLine(tool.Description))
+	}
+	return tw.Flush()
+}
+
+// argFlag collects repeated -arg key=value flags
+type argFlag map[string]any
+
+func (a argFlag) String() string { return fmt.Sprint(map[string]any(a)) }
+
+// Set parses key=value. Values that are valid JSON (numbers, booleans,
+// objects, arrays, quoted strings) are decoded; anything else is a string.
+func (a argFlag) Set(s string) error {
+	key, raw, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	a[key] = value
+	return nil
+}
+
+// callTool calls a tool and prints its result
+func callTool(ctx context.Context, s *session, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("usage: tools call <name> [-arg key=value]... [-args json]")
+	}
+	name := args[0]
+
+	arguments := argFlag{}
+	fs := flag.NewFlagSet("tools call", flag.ExitOnError)
+	fs.Var(arguments, "arg", "argument as key=value (repeatable)")
+	argsJSON := fs.String("args", "", "arguments as a JSON object; -arg values are merged over it")
+	asJSON := fs.Bool("json", false, "print the full result as JSON")
+	fs.Parse(args[1:])
+
+	merged := map[string]any{}
+	if *argsJSON != "" {
+		if err := json.Unmarshal([]byte(*argsJSON), &merged); err != nil {
+			return fmt.Errorf("-args: %w", err)
+		}
+	}
+	for k, v := range arguments {
+		merged[k] = v
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = merged
+	result, err := s.client.CallTool(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else if err := printContent(result.Content); err != nil {
+		return err
+	}
+	if result.IsError {
+		return fmt.Errorf("tool %s returned an error", name)
+	}
+	return nil
+}
+
+// printContent prints text content as-is and other content as JSON
+func printContent(content []mcp.Content) error {
+	for _, c := range content {
+		if text, ok := mcp.AsTextContent(c); ok {
+			fmt.Println(text.Text)
+			continue
+		}
+		if err := printJSON(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listResources prints the server's resources
+func listResources(ctx context.Context, s *session, args []string) error {
+	fs := flag.NewFlagSet("resources list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the full resource descriptions as JSON")
+	fs.Parse(args)
+
+	result, err := s.client.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result.Resources)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "URI\tMIME TYPE\tNAME")
+	for _, resource := range result.Resources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", resource.URI, resource.MIMEType, resource.Name)
+	}
+	return tw.Flush()
+}
+
+// readResource writes the contents of a resource to stdout, decoding blobs
+func readResource(ctx context.Context, s *session, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: resources read <uri>")
+	}
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = args[0]
+	result, err := s.client.ReadResource(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	for _, contents := range result.Contents {
+		switch c := contents.(type) {
+		case mcp.TextResourceContents:
+			fmt.Print(c.Text)
+		case mcp.BlobResourceContents:
+			data, err := base64.StdEncoding.DecodeString(c.Blob)
+			if err != nil {
+				return fmt.Errorf("%s: %w", c.URI, err)
+			}
+			os.Stdout.Write(data)
+		}
+	}
+	return nil
+}
+
+// listPrompts prints the server's prompts
+func listPrompts(ctx context.Context, s *session, args []string) error {
+	fs := flag.NewFlagSet("prompts list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the full prompt descriptions as JSON")
+	fs.Parse(args)
+
+	result, err := s.client.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result.Prompts)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDESCRIPTION")
+	for _, prompt := range result.Prompts {
+		fmt.Fprintf(tw, "%s\t%s\n", prompt.Name, firstLine(prompt.Description))
+	}
+	return tw.Flush()
+}
+
+// rawMessage is a JSON-RPC request or notification read in raw mode. The
+// jsonrpc member may be omitted; requests without an id are sent as
+// notifications, and request ids are renumbered by the transport.
+type rawMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// rawRPC sends one request given on the command line, or one message per
+// line of stdin, printing each response as a line of JSON
+func rawRPC(ctx context.Context, s *session, args []string) error {
+	var nextID atomic.Int64
+
+	send := func(msg rawMessage) error {
+		var params any
+		if len(msg.Params) > 0 {
+			params = msg.Params
+		}
+		if msg.ID == nil {
+			notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+			notification.Method = msg.Method
+			if params != nil {
+				if err := json.Unmarshal(msg.Params, &notification.Params.AdditionalFields); err != nil {
+					return fmt.Errorf("params: %w", err)
+				}
+			}
+			return s.transport.SendNotification(ctx, notification)
+		}
+
+		response, err := s.transport.SendRequest(ctx, transport.JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(nextID.Add(1)),
+			Method:  msg.Method,
+			Params:  params,
+		})
+		if err != nil {
+			return err
+		}
+		line, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(line))
+		return nil
+	}
+
+	if len(args) > 0 {
+		msg := rawMessage{ID: json.RawMessage("1"), Method: args[0]}
+		if len(args) > 1 {
+			msg.Params = json.RawMessage(args[1])
+			if !json.Valid(msg.Params) {
+				return errors.New("params must be valid JSON")
+			}
+		}
+		return send(msg)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var msg rawMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			return fmt.Errorf("invalid message %q: %w", line, err)
+		}
+		if msg.Method == "" {
+			return fmt.Errorf("invalid message %q: method is required", line)
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
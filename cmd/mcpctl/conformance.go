@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/conformance"
+)
+
+// runConformance runs the conformance suite against the selected server
+// and prints the compliance report. Unlike the other commands it opens its
+// own raw connections, one per check, instead of an initialized session.
+func runConformance(ctx context.Context, opts connectOptions, args []string) error {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	only := fs.String("only", "", "comma-separated check IDs or categories to run")
+	version := fs.String("protocol-version", "", "protocol version to propose (defaults to the latest)")
+	checkTimeout := fs.Duration("check-timeout", 0, "time allowed for each check (defaults to 10s)")
+	fs.Parse(args)
+
+	dial, err := conformanceDialer(opts)
+	if err != nil {
+		return err
+	}
+
+	var patterns []string
+	if *only != "" {
+		patterns = strings.Split(*only, ",")
+	}
+	checks := conformance.Filter(conformance.Checks(), patterns)
+	if len(checks) == 0 {
+		return fmt.Errorf("no checks match %q", *only)
+	}
+
+	report := conformance.Run(ctx, dial, conformance.Config{
+		ProtocolVersion: *version,
+		Timeout:         *checkTimeout,
+		Checks:          checks,
+	})
+	if *asJSON {
+		err = printJSON(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.Compliant() {
+		return errors.New("server is not compliant")
+	}
+	return nil
+}
+
+// conformanceDialer returns a dialer for exactly one of the -stdio, -sse
+// and -socket flags
+func conformanceDialer(opts connectOptions) (conformance.Dialer, error) {
+	switch {
+	case opts.stdio != "" && opts.sse == "" && opts.socket == "":
+		return conformance.StdioDialer(strings.Fields(opts.stdio), nil), nil
+	case opts.sse != "" && opts.stdio == "" && opts.socket == "":
+		return conformance.SSEDialer(opts.sse), nil
+	case opts.socket != "" && opts.stdio == "" && opts.sse == "":
+		network, address := "unix", opts.socket
+		if rest, ok := strings.CutPrefix(address, "tcp:"); ok {
+			network, address = "tcp", rest
+		}
+		return conformance.SocketDialer(network, address), nil
+	default:
+		return nil, errors.New("exactly one of -stdio, -sse or -socket is required")
+	}
+}
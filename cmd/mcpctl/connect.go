@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// connectOptions selects the server to talk to
+type connectOptions struct {
+	stdio  string
+	sse    string
+	socket string
+	noInit bool
+}
+
+// session is a connected, initialized client
+type session struct {
+	client    *client.Client
+	transport transport.Interface
+	server    *mcp.InitializeResult
+
+	// process is the server started for -stdio
+	process *exec.Cmd
+}
+
+// connect opens the selected transport and performs the handshake
+func connect(ctx context.Context, opts connectOptions) (*session, error) {
+	s := &session{}
+	t, err := s.newTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.client = client.NewClient(t)
+	s.transport = t
+	if err := s.client.Start(ctx); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	c := s.client
+	c.OnNotification(func(notification mcp.JSONRPCNotification) {
+		fmt.Fprintf(os.Stderr, "notification: %s\n", notification.Method)
+	})
+
+	if opts.noInit {
+		return s, nil
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "mcpctl", Version: "1.0.0"}
+	s.server, err = c.Initialize(ctx, request)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	return s, nil
+}
+
+// Close ends the session. A stdio server is stopped by closing its stdin.
+func (s *session) Close() error {
+	var err error
+	if s.client != nil {
+		err = s.client.Close()
+	}
+	if s.process != nil {
+		s.process.Wait()
+	}
+	return err
+}
+
+// newTransport creates the transport for exactly one of the -stdio, -sse
+// and -socket flags
+func (s *session) newTransport(opts connectOptions) (transport.Interface, error) {
+	selected := 0
+	for _, v := range []string{opts.stdio, opts.sse, opts.socket} {
+		if v != "" {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return nil, errors.New("exactly one of -stdio, -sse or -socket is required")
+	}
+
+	switch {
+	case opts.stdio != "":
+		return s.startProcess(strings.Fields(opts.stdio))
+
+	case opts.sse != "":
+		return transport.NewSSE(opts.sse)
+
+	default:
+		network, address := "unix", opts.socket
+		if rest, ok := strings.CutPrefix(address, "tcp:"); ok {
+			network, address = "tcp", rest
+		}
+		conn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, fmt.Errorf("connect: %w", err)
+		}
+		return transport.NewIO(conn, halfCloser{conn}, io.NopCloser(strings.NewReader(""))), nil
+	}
+}
+
+// startProcess starts a stdio server. The server's stdout is read through
+// a pipe owned by the session rather than the command, so waiting for the
+// process cannot close it under the transport's reader, which stops at the
+// server's EOF; stderr goes straight to ours.
+func (s *session) startProcess(argv []string) (transport.Interface, error) {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		stdout.Close()
+		return nil, fmt.Errorf("start server: %w", err)
+	}
+
+	s.process = cmd
+	return transport.NewIO(stdout, stdin, io.NopCloser(strings.NewReader(""))), nil
+}
+
+// halfCloser closes only the write side of a socket, so the server sees
+// EOF and closes the connection while the transport is still reading
+type halfCloser struct {
+	net.Conn
+}
+
+func (c halfCloser) Close() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return c.Conn.Close()
+}
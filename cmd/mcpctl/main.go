@@ -0,0 +1,90 @@
+// Command mcpctl is a command-line MCP client for manual testing and
+// scripting. It connects to a server over stdio, SSE or a socket, performs
+// the handshake and runs one command:
+//
+//	mcpctl -stdio "meta-mcp-server run" tools list
+//	mcpctl -sse http://localhost:8080/sse tools call echo -arg message=hi
+//	mcpctl -socket /run/mcp.sock resources read file:///docs/README.md
+//	echo '{"method":"ping"}' | mcpctl -stdio meta-mcp-server rpc
+//	mcpctl -sse http://upstream:9000/sse conformance
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// usage is printed for -h and unknown commands
+const usage = `Usage: mcpctl [flags] <command> [args]
+
+Commands:
+  tools list [-json]                        list the server's tools
+  tools call <name> [-arg k=v]... [-args J] call a tool
+  resources list [-json]                    list the server's resources
+  resources read <uri>                      print a resource
+  prompts list [-json]                      list the server's prompts
+  rpc [method [params]]                     send raw JSON-RPC requests; without
+                                            a method, one request per stdin line
+  conformance [-json] [-only ids]           check protocol conformance and print
+                                            a compliance report
+
+Flags:
+`
+
+func main() {
+	fs := flag.NewFlagSet("mcpctl", flag.ExitOnError)
+	var opts connectOptions
+	fs.StringVar(&opts.stdio, "stdio", "", "command line of a server to start and talk to over stdio")
+	fs.StringVar(&opts.sse, "sse", "", "URL of an SSE server endpoint")
+	fs.StringVar(&opts.socket, "socket", "", "unix socket path, or tcp:host:port, of a server")
+	fs.BoolVar(&opts.noInit, "no-init", false, "skip the handshake (rpc only)")
+	timeout := fs.Duration("timeout", 30*time.Second, "time allowed for the whole command")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	args := fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if args[0] == "conformance" {
+		if err := runConformance(ctx, opts, args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "mcpctl:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	run, ok := lookupCommand(args)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "mcpctl: unknown command %q\n\n", args[0])
+		fs.Usage()
+		os.Exit(2)
+	}
+	if run.name != "rpc" && opts.noInit {
+		fmt.Fprintln(os.Stderr, "mcpctl: -no-init is only supported by rpc")
+		os.Exit(2)
+	}
+
+	session, err := connect(ctx, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mcpctl:", err)
+		os.Exit(1)
+	}
+	err = run.fn(ctx, session, args[len(run.words):])
+	session.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mcpctl:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,75 @@
+// Code generated by toolgen from calculate.json; DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// calculateInputSchema is the input schema of the calculate tool
+const calculateInputSchema = `{"type":"object","properties":{"operation":{"type":"string","description":"The operation to perform (add, subtract, multiply, divide)","enum":["add","subtract","multiply","divide"]},"x":{"type":"number","description":"First number"},"y":{"type":"number","description":"Second number"}},"required":["operation","x","y"]}`
+
+// CalculateTool returns the calculate tool definition
+func CalculateTool() mcp.Tool {
+	return mcp.NewToolWithRawSchema("calculate", "Perform basic arithmetic operations", json.RawMessage(calculateInputSchema))
+}
+
+// CalculateParams is generated from the tool's JSON Schema
+type CalculateParams struct {
+	// The operation to perform (add, subtract, multiply, divide)
+	Operation string `json:"operation"`
+	// First number
+	X float64 `json:"x"`
+	// Second number
+	Y float64 `json:"y"`
+}
+
+// validate checks constraints the JSON decoder cannot; raw holds the
+// undecoded arguments so missing required properties are detected
+func (p *CalculateParams) validate(raw map[string]interface{}, path string) error {
+	if _, ok := raw["operation"]; !ok {
+		return fmt.Errorf("%s is required", path+"operation")
+	}
+	switch p.Operation {
+	case "add", "subtract", "multiply", "divide":
+	default:
+		return fmt.Errorf("%s must be one of add, subtract, multiply, divide", path+"operation")
+	}
+	if _, ok := raw["x"]; !ok {
+		return fmt.Errorf("%s is required", path+"x")
+	}
+	if _, ok := raw["y"]; !ok {
+		return fmt.Errorf("%s is required", path+"y")
+	}
+	return nil
+}
+
+// ParseCalculateParams decodes and validates tool arguments
+func ParseCalculateParams(args map[string]interface{}) (CalculateParams, error) {
+	var params CalculateParams
+	data, err := json.Marshal(args)
+	if err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return params, params.validate(args, "")
+}
+
+// CalculateHandler adapts a typed function to an mcp-go tool handler. Invalid
+// arguments are reported as a tool error result.
+func CalculateHandler(fn func(ctx context.Context, params CalculateParams) (*mcp.CallToolResult, error)) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params, err := ParseCalculateParams(request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return fn(ctx, params)
+	}
+}
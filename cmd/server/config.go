@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+)
+
+// runConfig runs a config subcommand
+func runConfig(args []string) int {
+	switch {
+	case len(args) > 0 && args[0] == "migrate":
+		return runConfigMigrate(args[1:])
+	case len(args) > 0 && args[0] == "schema":
+		return runConfigSchema(args[1:])
+	}
+	fmt.Fprintln(os.Stderr, "Usage: meta-mcp-server config <migrate|schema> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "  migrate  rewrite the config file for schema version %d\n", config.CurrentVersion)
+	fmt.Fprintln(os.Stderr, "  schema   print the JSON Schema or Markdown reference of the config file")
+	return exitConfig
+}
+
+// runConfigSchema prints the JSON Schema of the config file, or its
+// Markdown reference with -markdown
+func runConfigSchema(args []string) int {
+	fs := flag.NewFlagSet("meta-mcp-server config schema", flag.ContinueOnError)
+	markdown := fs.Bool("markdown", false, "print the Markdown reference instead of the JSON Schema")
+	if err := fs.Parse(args); err != nil {
+		return exitCode(err)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "config schema: unexpected arguments")
+		return exitConfig
+	}
+
+	if *markdown {
+		fmt.Println("# Configuration Reference")
+		fmt.Println()
+		fmt.Println("Generated by `make config-reference`; do not edit by hand.")
+		fmt.Println()
+		fmt.Print(config.Reference())
+		return exitOK
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(config.Schema()); err != nil {
+		fmt.Fprintf(os.Stderr, "config schema: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runConfigMigrate rewrites an older config file for the current schema
+// version, keeping a backup of the original
+func runConfigMigrate(args []string) int {
+	fs := flag.NewFlagSet("meta-mcp-server config migrate", flag.ContinueOnError)
+	file := fs.String("config", os.Getenv(config.FileEnv), "YAML config file (or "+config.FileEnv+")")
+	dryRun := fs.Bool("dry-run", false, "print the migrated file instead of writing it")
+	backup := fs.Bool("backup", true, "keep the original file as <file>.bak")
+	if err := fs.Parse(args); err != nil {
+		return exitCode(err)
+	}
+	if *file == "" || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "config migrate: give the file with -config or "+config.FileEnv)
+		return exitConfig
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config migrate: %v\n", err)
+		return exitError
+	}
+	migrated, version, warnings, err := config.Migrate(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config migrate: %s: %v\n", *file, err)
+		return exitConfig
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", w)
+	}
+
+	if *dryRun {
+		os.Stdout.Write(migrated)
+		return exitOK
+	}
+	if version == config.CurrentVersion {
+		fmt.Printf("%s is already at version %d\n", *file, version)
+		return exitOK
+	}
+
+	info, err := os.Stat(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config migrate: %v\n", err)
+		return exitError
+	}
+	if *backup {
+		if err := os.WriteFile(*file+".bak", data, info.Mode().Perm()); err != nil {
+			fmt.Fprintf(os.Stderr, "config migrate: %v\n", err)
+			return exitError
+		}
+	}
+	if err := writeFileAtomic(*file, migrated, info.Mode().Perm()); err != nil {
+		fmt.Fprintf(os.Stderr, "config migrate: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("migrated %s from version %d to %d\n", *file, version, config.CurrentVersion)
+	return exitOK
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
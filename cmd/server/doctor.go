@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	clienttransport "github.com/mark3labs/mcp-go/client/transport"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/plugins"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// doctorTimeout bounds each check of the doctor command that runs a
+// process or a handshake
+const doctorTimeout = 5 * time.Second
+
+// checkStatus is the outcome of a doctor check
+type checkStatus string
+
+// Check outcomes, padded to line up in the checklist
+const (
+	checkOK   checkStatus = " OK "
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+// checkResult is one line of the doctor checklist
+type checkResult struct {
+	status checkStatus
+	detail string
+}
+
+// doctorCheck is a named check of the doctor command
+type doctorCheck struct {
+	name string
+	run  func() []checkResult
+}
+
+// runDoctor checks that the server can start with the configuration and
+// prints a checklist
+func runDoctor(args []string) int {
+	var offline bool
+	cfg, sources, err := loadConfig(config.Options{Name: "doctor", Args: args, Flags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&offline, "offline", false, "skip checks that need the network or start plugins")
+	}})
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) || errors.Is(err, config.ErrUsage) {
+			return exitCode(err)
+		}
+		printCheck(os.Stdout, "configuration", checkResult{checkFail, "see the errors above"})
+		return exitConfig
+	}
+
+	// Only problems are logged, so the server's own logs do not interleave
+	// with the checklist
+	logging.SetDefault(logging.New(logging.Config{Level: logging.LogLevelWarn, Sanitize: true}))
+
+	checks := []doctorCheck{
+		{"configuration", func() []checkResult { return checkSchema(sources) }},
+		{"upstream commands", func() []checkResult { return checkCommands(cfg.Plugins.File) }},
+		{"resources, prompts and plugins", func() []checkResult { return checkReferences(cfg, offline) }},
+		{"listeners", func() []checkResult { return checkListeners(cfg) }},
+		{"TLS certificate", func() []checkResult { return checkTLS(cfg.Listen) }},
+		{"loopback handshake", func() []checkResult { return checkHandshake(cfg) }},
+	}
+
+	// Checks overlap, e.g. a missing plugin command fails both the plugin
+	// and the command check, so each failure is reported once
+	failed := make(map[string]bool)
+	for _, check := range checks {
+		for _, result := range check.run() {
+			if result.status == checkFail {
+				if failed[result.detail] {
+					continue
+				}
+				failed[result.detail] = true
+			}
+			printCheck(os.Stdout, check.name, result)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Printf("\n%d check(s) failed\n", len(failed))
+		return exitError
+	}
+	fmt.Println("\nall checks passed")
+	return exitOK
+}
+
+// printCheck writes one checklist line
+func printCheck(w io.Writer, name string, result checkResult) {
+	if result.detail == "" {
+		fmt.Fprintf(w, "[%s] %s\n", result.status, name)
+		return
+	}
+	fmt.Fprintf(w, "[%s] %s: %s\n", result.status, name, result.detail)
+}
+
+// checkSchema reports the schema version of the config file, which has
+// already passed validation
+func checkSchema(sources config.Sources) []checkResult {
+	// A loaded file always sets the version, adding it when migrated
+	file, ok := strings.CutPrefix(sources["version"], "file ")
+	if !ok {
+		return []checkResult{{checkOK, "valid, no config file"}}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []checkResult{{checkFail, err.Error()}}
+	}
+	_, version, _, err := config.Migrate(data)
+	if err != nil {
+		return []checkResult{{checkFail, err.Error()}}
+	}
+	if version < config.CurrentVersion {
+		return []checkResult{{checkWarn, fmt.Sprintf("%s uses schema version %d; run \"meta-mcp-server config migrate\"", file, version)}}
+	}
+	return []checkResult{{checkOK, fmt.Sprintf("%s is valid, schema version %d", file, version)}}
+}
+
+// checkReferences opens the files and plugins the configuration refers to
+func checkReferences(cfg *config.Config, offline bool) []checkResult {
+	problems := checkConfig(cfg, offline)
+	if len(problems) == 0 {
+		return []checkResult{{status: checkOK}}
+	}
+	results := make([]checkResult, len(problems))
+	for i, problem := range problems {
+		results[i] = checkResult{checkFail, problem}
+	}
+	return results
+}
+
+// checkCommands finds the commands of the configured plugins and asks each
+// for its version
+func checkCommands(file string) []checkResult {
+	if file == "" {
+		return []checkResult{{checkSkip, "no plugins file"}}
+	}
+	pluginConfig, err := plugins.LoadConfig(file)
+	if err != nil {
+		return []checkResult{{checkFail, err.Error()}}
+	}
+
+	var results []checkResult
+	for _, spec := range pluginConfig.Plugins {
+		if spec.Command == "" {
+			continue
+		}
+		path, err := exec.LookPath(spec.Command)
+		if err != nil {
+			results = append(results, checkResult{checkFail, fmt.Sprintf("plugin %s: %v", spec.Name, err)})
+			continue
+		}
+		detail := fmt.Sprintf("plugin %s: %s", spec.Name, path)
+		if version := commandVersion(path); version != "" {
+			detail += " (" + version + ")"
+		}
+		results = append(results, checkResult{checkOK, detail})
+	}
+	if len(results) == 0 {
+		return []checkResult{{checkSkip, "no command plugins"}}
+	}
+	return results
+}
+
+// commandVersion returns the first line printed by "path --version", or ""
+// when the command does not support it
+func commandVersion(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := bytes.Cut(bytes.TrimSpace(out), []byte("\n"))
+	return string(line)
+}
+
+// checkListeners binds every configured address and releases it again
+func checkListeners(cfg *config.Config) []checkResult {
+	addrs := []struct{ name, addr string }{
+		{"listen.sse", cfg.Listen.SSE},
+		{"listen.websocket", cfg.Listen.WebSocket},
+		{"metrics.addr", cfg.Metrics.Addr},
+		{"health.addr", cfg.Health.Addr},
+	}
+	if cfg.Debug.Enabled {
+		addrs = append(addrs, struct{ name, addr string }{"debug.addr", cfg.Debug.Addr})
+	}
+
+	var results []checkResult
+	if path := cfg.Listen.Socket; path != "" {
+		results = append(results, checkSocket(path))
+	}
+	for _, a := range addrs {
+		if a.addr == "" {
+			continue
+		}
+		listener, err := net.Listen("tcp", a.addr)
+		if err != nil {
+			results = append(results, checkResult{checkFail, fmt.Sprintf("%s: %v", a.name, err)})
+			continue
+		}
+		listener.Close()
+		results = append(results, checkResult{checkOK, fmt.Sprintf("%s: %s", a.name, a.addr)})
+	}
+	if len(results) == 0 {
+		return []checkResult{{checkSkip, "stdio only"}}
+	}
+	return results
+}
+
+// checkSocket reports whether the server could listen on a Unix socket path
+func checkSocket(path string) checkResult {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return checkResult{checkFail, fmt.Sprintf("listen.socket: %s is in use", path)}
+		}
+		return checkResult{checkOK, fmt.Sprintf("listen.socket: %s (stale socket will be replaced)", path)}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return checkResult{checkFail, fmt.Sprintf("listen.socket: %v", err)}
+	}
+	listener.Close()
+	return checkResult{checkOK, "listen.socket: " + path}
+}
+
+// checkTLS loads the certificate of the HTTP transports
+func checkTLS(cfg config.ListenConfig) []checkResult {
+	certs, err := newCertManager(cfg)
+	if err != nil {
+		return []checkResult{{checkFail, err.Error()}}
+	}
+	if certs == nil {
+		return []checkResult{{checkSkip, "not configured"}}
+	}
+	defer certs.Stop()
+	if len(cfg.ACMEDomains) > 0 {
+		return []checkResult{{checkOK, fmt.Sprintf("ACME for %s", strings.Join(cfg.ACMEDomains, ", "))}}
+	}
+
+	notAfter := certs.GetStats().NotAfter
+	switch remaining := time.Until(notAfter); {
+	case remaining <= 0:
+		return []checkResult{{checkFail, fmt.Sprintf("%s expired on %s", cfg.TLSCert, notAfter.Format(time.DateOnly))}}
+	case remaining < 30*24*time.Hour:
+		return []checkResult{{checkWarn, fmt.Sprintf("%s expires on %s", cfg.TLSCert, notAfter.Format(time.DateOnly))}}
+	}
+	return []checkResult{{checkOK, fmt.Sprintf("%s, valid until %s", cfg.TLSCert, notAfter.Format(time.DateOnly))}}
+}
+
+// checkHandshake initializes a client against an in-process server built
+// from the configuration and lists its tools
+func checkHandshake(cfg *config.Config) []checkResult {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	hs := mcp.NewHandshakeServer(newHandshakeConfig(cfg))
+	registerBuiltinTools(tools.New(tools.Config{Server: hs}), cfg.Server.Version)
+
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.New(hs, server.Config{}).ServeConn(ctx, "doctor", server.NewLineConn(serverConn, serverConn, serverConn))
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	c := client.NewClient(clienttransport.NewIO(clientConn, clientConn, io.NopCloser(strings.NewReader(""))))
+	defer c.Close()
+	if err := c.Start(ctx); err != nil {
+		return []checkResult{{checkFail, err.Error()}}
+	}
+	request := mcpgo.InitializeRequest{}
+	request.Params.ProtocolVersion = mcpgo.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcpgo.Implementation{Name: "meta-mcp-server doctor", Version: Version}
+	result, err := c.Initialize(ctx, request)
+	if err != nil {
+		return []checkResult{{checkFail, fmt.Sprintf("initialize: %v", err)}}
+	}
+	list, err := c.ListTools(ctx, mcpgo.ListToolsRequest{})
+	if err != nil {
+		return []checkResult{{checkFail, fmt.Sprintf("tools/list: %v", err)}}
+	}
+	return []checkResult{{checkOK, fmt.Sprintf("%s %s, protocol %s, %d tools",
+		result.ServerInfo.Name, result.ServerInfo.Version, result.ProtocolVersion, len(list.Tools))}}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// runListTools prints the tools the server would publish
+func runListTools(args []string) int {
+	var format string
+	cfg, _, err := loadConfig(config.Options{Name: "list-tools", Args: args, Flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&format, "format", "table", "output format (table or json)")
+	}})
+	if err != nil {
+		return exitCode(err)
+	}
+	if format != "table" && format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return exitConfig
+	}
+
+	registry := tools.New(tools.Config{})
+	registerBuiltinTools(registry, cfg.Server.Version)
+
+	httpProvider, err := newHTTPProvider(cfg, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if httpProvider != nil {
+		registry.MustRegister(httpProvider.ToolDefinition())
+	}
+
+	if cfg.Plugins.File != "" {
+		ctx := context.Background()
+		manager, err := startPlugins(ctx, cfg.Plugins.File, registry)
+		if manager == nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer manager.Shutdown(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	infos := registry.List("")
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(infos); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		return exitOK
+	}
+	printTools(os.Stdout, infos)
+	return exitOK
+}
+
+// printTools writes infos as an aligned table
+func printTools(w io.Writer, infos []tools.Info) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tTAGS\tDESCRIPTION")
+	for _, info := range infos {
+		description, _, _ := strings.Cut(info.Description, "\n")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", info.Name, info.Version, strings.Join(info.Tags, ","), description)
+	}
+	tw.Flush()
+}
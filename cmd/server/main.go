@@ -2,134 +2,231 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"time"
+	"sort"
+	"strings"
 
-	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
 	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/plugins"
+	"github.com/meta-mcp/meta-mcp-server/internal/policy"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
 )
 
-func main() {
-	// Initialize logger based on environment
-	logConfig := logging.ConfigFromEnv()
-	logger := logging.New(logConfig)
-	logging.SetDefault(logger)
+//go:generate go run ../toolgen -in calculate.json -out calculate_gen.go
+
+// Process exit codes
+const (
+	exitOK        = 0 // clean shutdown or successful command
+	exitError     = 1 // startup failure or failed check
+	exitConfig    = 2 // invalid flags or configuration
+	exitTransport = 3 // a transport failed while serving
+)
 
-	// Create context with component information
-	ctx := logging.WithComponent(context.Background(), "main")
+// command is a subcommand of the server binary
+type command struct {
+	summary string
+	run     func(args []string) int
+}
+
+// commands are the available subcommands
+var commands = map[string]command{
+	"run":        {"serve MCP on the configured transports (default)", runServer},
+	"daemon":     {"run as a service: no stdio, PID file and reload on SIGHUP", runDaemon},
+	"validate":   {"check the configuration and upstream reachability", runValidate},
+	"config":     {"manage the config file (migrate, schema)", runConfig},
+	"doctor":     {"check that the server can start and print a checklist", runDoctor},
+	"list-tools": {"print the tool catalog", runListTools},
+	"version":    {"print build information", runVersion},
+}
 
-	// Configure the handshake-enabled server
-	config := mcp.HandshakeConfig{
-		Name:              "Meta-MCP Server",
-		Version:           "1.0.0",
-		HandshakeTimeout:  30 * time.Second,
-		SupportedVersions: []string{"1.0", "0.1.0"},
-		ServerOptions: []server.ServerOption{
-			mcp.WithToolCapabilities(true),
-			mcp.WithResourceCapabilities(true, true),
-			mcp.WithRecovery(),
-		},
+func main() {
+	args := os.Args[1:]
+
+	// Without a subcommand the server runs, so existing invocations that
+	// pass only flags keep working
+	name := "run"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
 	}
 
-	// Create a new handshake-enabled MCP server
-	server := mcp.NewHandshakeServer(config)
+	if name == "help" {
+		usage(os.Stdout)
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(exitConfig)
+	}
+	os.Exit(cmd.run(args))
+}
 
-	// Add an echo tool
-	echoTool := mcp.CreateEchoTool()
-	server.AddTool(echoTool, mcp.EchoHandler)
+// usage lists the subcommands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: meta-mcp-server <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "meta-mcp-server <command> -h" for the flags of a command.`)
+}
 
-	// Add a calculator tool
-	calculatorTool := mcp.NewTool("calculate",
-		mcp.WithDescription("Perform basic arithmetic operations"),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("The operation to perform (add, subtract, multiply, divide)"),
-		),
-		mcp.WithNumber("x",
-			mcp.Required(),
-			mcp.Description("First number"),
-		),
-		mcp.WithNumber("y",
-			mcp.Required(),
-			mcp.Description("Second number"),
-		),
-	)
-
-	server.AddTool(calculatorTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Get operation parameter
-		operation, err := request.RequireString("operation")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid operation: %v", err)), nil
-		}
+// loadConfig loads the configuration for the subcommand named by opts.Name,
+// reporting errors on stderr
+func loadConfig(opts config.Options) (*config.Config, config.Sources, error) {
+	opts.Name = "meta-mcp-server " + opts.Name
+	opts.FlagOutput = os.Stderr
+	opts.Warn = func(w config.FieldError) {
+		fmt.Fprintf(os.Stderr, "warning: %v (run \"meta-mcp-server config migrate\" to update the file)\n", w)
+	}
+	cfg, sources, err := config.Load(opts)
+	if err != nil && !errors.Is(err, config.ErrUsage) {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return cfg, sources, err
+}
 
-		// Get x parameter
-		x, err := request.RequireFloat("x")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid x parameter: %v", err)), nil
-		}
+// exitCode maps a configuration error to the process exit code
+func exitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitConfig
+}
 
-		// Get y parameter
-		y, err := request.RequireFloat("y")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid y parameter: %v", err)), nil
-		}
+// newLogConfig derives the logging configuration from the environment and
+// applies the log settings of cfg. Without a profile, only settings given
+// explicitly override the environment.
+func newLogConfig(cfg *config.Config, sources config.Sources) logging.Config {
+	logConfig := logging.ConfigFromEnv()
+	set := func(path string) bool {
+		_, ok := sources[path]
+		return ok || cfg.Profile != ""
+	}
+	if set("log.level") {
+		logConfig.Level = logging.ParseLogLevel(cfg.Log.Level)
+	}
+	if set("log.sanitize") {
+		logConfig.Sanitize = cfg.Log.Sanitize
+	}
+	if set("log.pretty") {
+		logConfig.Pretty = cfg.Log.Pretty
+	}
+	if set("log.debug") {
+		logConfig.DebugMode = cfg.Log.Debug
+	}
+	return logConfig
+}
 
-		// Perform calculation
-		var result float64
-		switch operation {
-		case "add":
-			result = x + y
-		case "subtract":
-			result = x - y
-		case "multiply":
-			result = x * y
-		case "divide":
-			if y == 0 {
-				return mcp.NewToolResultError("Cannot divide by zero"), nil
-			}
-			result = x / y
-		default:
-			return mcp.NewToolResultError(fmt.Sprintf("Unknown operation: %s", operation)), nil
-		}
+// registerBuiltinTools adds the tools implemented by the server itself
+func registerBuiltinTools(registry *tools.Registry, version string) {
+	// Add an echo tool
+	registry.MustRegister(tools.Definition{
+		Tool:    mcp.CreateEchoTool(),
+		Handler: mcp.EchoHandler,
+		Version: version,
+		Tags:    []string{"builtin"},
+	})
 
-		return mcp.NewToolResultText(fmt.Sprintf("%.2f", result)), nil
+	// Add a calculator tool
+	registry.MustRegister(tools.Definition{
+		Tool:    CalculateTool(),
+		Handler: CalculateHandler(calculate),
+		Version: version,
+		Tags:    []string{"builtin"},
 	})
 
-	// Add a simple resource
-	readmeResource := mcp.NewResource(
-		"file://README.md",
-		"Project README",
-	)
+	// Let clients make many small calls in one round trip
+	batch := registry.BatchDefinition(tools.BatchConfig{})
+	batch.Version = version
+	registry.MustRegister(batch)
+}
 
-	server.AddResource(readmeResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		// Read the README file
-		content, err := os.ReadFile("README.md")
+// newFileConfig returns the resource provider configuration, serving the
+// working directory when no roots are configured
+func newFileConfig(cfg *config.Config) (resources.FileConfig, error) {
+	fileConfig := resources.DefaultFileConfig(".")
+	if len(cfg.Resources.Roots) > 0 {
+		roots, err := resources.ParseRoots(strings.Join(cfg.Resources.Roots, ","))
 		if err != nil {
-			// Return a default message if README doesn't exist
-			content = []byte("# Meta-MCP Server\n\nA Model Context Protocol server implementation using mcp-go.")
+			return fileConfig, err
 		}
+		fileConfig.Roots = roots
+	}
+	fileConfig.Watch = cfg.Resources.Watch
+	fileConfig.Incremental = cfg.Resources.Incremental
+	return fileConfig, nil
+}
 
-		// Create ResourceContents using the struct directly
-		return []mcp.ResourceContents{
-			mcp.TextResourceContents{
-				URI:      request.Params.URI,
-				MIMEType: "text/markdown",
-				Text:     string(content),
-			},
-		}, nil
+// newHTTPProvider creates the fetch provider, or returns nil when no fetch
+// domains are configured. Reads go through cache unless it is nil.
+func newHTTPProvider(cfg *config.Config, cache *resources.Cache) (*resources.HTTPProvider, error) {
+	if len(cfg.Fetch.Domains) == 0 {
+		return nil, nil
+	}
+	return resources.NewHTTPProvider(resources.HTTPConfig{
+		Allow: policy.URLRule{Domains: cfg.Fetch.Domains},
+		Cache: cache,
 	})
+}
 
-	// Start the server using stdio transport with handshake support
-	logger.Info(ctx, "Starting Meta-MCP Server with handshake support...")
-	logger.WithFields(logging.LogFields{
-		"server_name":       config.Name,
-		"version":           config.Version,
-		"handshake_timeout": config.HandshakeTimeout,
-	}).Info(ctx, "Server configuration loaded")
+// newResourceCache creates the cache of resources/read results, or returns
+// nil when it is disabled
+func newResourceCache(cfg *config.Config) *resources.Cache {
+	if cfg.Resources.CacheEntries == 0 {
+		return nil
+	}
+	return resources.NewCache(resources.CacheConfig{
+		MaxEntries: cfg.Resources.CacheEntries,
+		MaxBytes:   cfg.Resources.CacheSize,
+		MaxAge:     cfg.Resources.CacheMaxAge,
+	})
+}
+
+// startPlugins loads the plugins declared in file into registry. It
+// returns a nil manager if the file cannot be loaded, and a manager together
+// with an error if only some plugins failed.
+func startPlugins(ctx context.Context, file string, registry *tools.Registry) (*plugins.Manager, error) {
+	pluginConfig, err := plugins.LoadConfig(file)
+	if err != nil {
+		return nil, err
+	}
+	pluginConfig.Registry = registry
+	manager := plugins.New(pluginConfig)
+	return manager, manager.Start(ctx)
+}
 
-	if err := mcp.ServeStdioWithHandshake(server); err != nil {
-		logger.Fatal(ctx, err, "Server error")
+// calculate implements the calculator tool
+func calculate(ctx context.Context, params CalculateParams) (*mcp.CallToolResult, error) {
+	var result float64
+	switch params.Operation {
+	case "add":
+		result = params.X + params.Y
+	case "subtract":
+		result = params.X - params.Y
+	case "multiply":
+		result = params.X * params.Y
+	case "divide":
+		if params.Y == 0 {
+			return mcp.NewToolResultError("Cannot divide by zero"), nil
+		}
+		result = params.X / params.Y
 	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%.2f", result)), nil
 }
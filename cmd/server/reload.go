@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/daemon"
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/health"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/plugins"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// reloader re-reads the configuration for Server.Reload. Plugins are
+// reloaded in place; changes to other sections are reported as needing a
+// restart.
+type reloader struct {
+	options  config.Options
+	registry *tools.Registry
+	health   *health.Health
+
+	mu      sync.Mutex
+	current *config.Config
+	plugins *plugins.Manager
+}
+
+// watchReloads calls reload on every SIGHUP until ctx is done
+func watchReloads(ctx context.Context, reload server.ReloadFunc) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+			// Failures are logged by the reloader
+			_ = reload(ctx)
+		}
+	}
+}
+
+// reload loads the configuration again with the original arguments,
+// keeping the current one if the new one is invalid
+func (r *reloader) reload(ctx context.Context) error {
+	logger := logging.Default().WithComponent("reload")
+	daemon.Notify(daemon.Reloading)
+	defer daemon.Notify(daemon.Ready)
+
+	opts := r.options
+	opts.Name = "meta-mcp-server " + opts.Name
+	opts.FlagOutput = io.Discard
+	cfg, _, err := config.Load(opts)
+	if err != nil {
+		logger.Error(ctx, err, "Reload failed, keeping the current configuration")
+		events.Publish(events.Default(), events.ConfigReloads, events.ConfigReloadEvent{Error: err.Error(), At: time.Now()})
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := changedSections(r.current, cfg)
+	for _, section := range changed {
+		if section != "plugins" {
+			logger.WithField("section", section).Warn(ctx, "Configuration change requires a restart")
+		}
+	}
+	pluginErr := r.reloadPlugins(ctx, cfg.Plugins.File)
+	if pluginErr != nil {
+		logger.Error(ctx, pluginErr, "Failed to reload plugins")
+	}
+	r.current = cfg
+	r.health.SetConfigVersion(strconv.Itoa(cfg.Version))
+	logger.Info(ctx, "Configuration reloaded")
+	events.Publish(events.Default(), events.ConfigReloads, events.ConfigReloadEvent{Changed: changed, At: time.Now()})
+	return pluginErr
+}
+
+// reloadPlugins brings the loaded plugins in line with file, starting a
+// manager if plugins were not configured before
+func (r *reloader) reloadPlugins(ctx context.Context, file string) error {
+	if file == "" {
+		if r.plugins == nil {
+			return nil
+		}
+		return r.plugins.Reload(ctx, nil)
+	}
+
+	if r.plugins == nil {
+		manager, err := startPlugins(ctx, file, r.registry)
+		if manager != nil {
+			r.plugins = manager
+		}
+		return err
+	}
+
+	pluginConfig, err := plugins.LoadConfig(file)
+	if err != nil {
+		return err
+	}
+	return r.plugins.Reload(ctx, pluginConfig.Plugins)
+}
+
+// shutdown stops the plugins
+func (r *reloader) shutdown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plugins != nil {
+		r.plugins.Shutdown(context.Background())
+	}
+}
+
+// changedSections returns the top-level sections that differ between two
+// configurations, named by their YAML keys
+func changedSections(old, new *config.Config) []string {
+	oldValue, newValue := reflect.ValueOf(*old), reflect.ValueOf(*new)
+	var changed []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			name, _, _ := strings.Cut(oldValue.Type().Field(i).Tag.Get("yaml"), ",")
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
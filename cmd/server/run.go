@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/admin"
+	"github.com/meta-mcp/meta-mcp-server/internal/buildinfo"
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/daemon"
+	"github.com/meta-mcp/meta-mcp-server/internal/debug"
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/health"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/memguard"
+	"github.com/meta-mcp/meta-mcp-server/internal/metrics"
+	"github.com/meta-mcp/meta-mcp-server/internal/policy"
+	"github.com/meta-mcp/meta-mcp-server/internal/profiling"
+	"github.com/meta-mcp/meta-mcp-server/internal/prompts"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/quota"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/scheduler"
+	"github.com/meta-mcp/meta-mcp-server/internal/schema"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/storage"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+	"github.com/meta-mcp/meta-mcp-server/internal/tracing"
+	"github.com/meta-mcp/meta-mcp-server/pkg/mcpcontext"
+)
+
+// errorBudgetMinRequests is how many requests the last minute must hold
+// before health.errorBudget applies, so a few early failures do not fail
+// readiness
+const errorBudgetMinRequests = 20
+
+// runServer serves MCP on the configured transports until a signal arrives
+// or a transport stops
+func runServer(args []string) int {
+	return run(config.Options{Name: "run", Args: args})
+}
+
+// runDaemon serves like run, but only on network transports so it can be
+// started by a service manager without a client on stdio
+func runDaemon(args []string) int {
+	return run(config.Options{Name: "daemon", Args: args, Defaults: func(cfg *config.Config) {
+		cfg.Listen.Stdio = false
+	}})
+}
+
+// run starts the server and returns the process exit code
+func run(opts config.Options) int {
+	// Merge defaults, config file, environment and flags
+	cfg, sources, err := loadConfig(opts)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	// Initialize logger based on environment and profile
+	logConfig := newLogConfig(cfg, sources)
+	logger := logging.New(logConfig)
+	logging.SetDefault(logger)
+	defer logger.Close()
+	mcperrors.SetDebugMode(logConfig.DebugMode)
+
+	// Create context with component information, cancelled on shutdown
+	// signals
+	ctx, stop := signal.NotifyContext(logging.WithComponent(context.Background(), "main"), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Guard against a second instance when a PID file is configured
+	if path := cfg.Daemon.PIDFile; path != "" {
+		pidFile, err := daemon.CreatePIDFile(path)
+		if err != nil {
+			logger.Error(ctx, err, "Failed to create PID file")
+			return exitError
+		}
+		defer pidFile.Remove()
+	}
+
+	// Export traces to an OpenTelemetry collector when an endpoint is
+	// configured
+	if endpoint := cfg.Tracing.Endpoint; endpoint != "" {
+		shutdownTracing, err := tracing.InitOTLP(ctx, cfg.Server.Name, endpoint)
+		if err != nil {
+			logger.Error(ctx, err, "Failed to set up trace export")
+			return exitConfig
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			shutdownTracing(ctx)
+		}()
+		logger.WithField("tracing_endpoint", endpoint).Info(ctx, "Exporting traces")
+	}
+
+	// Keep quota usage and unacknowledged notifications across restarts
+	// when a storage file is configured
+	var store storage.Store
+	if path := cfg.Storage.Path; path != "" {
+		boltStore, err := storage.OpenBolt(path)
+		if err != nil {
+			logger.Error(ctx, err, "Failed to open storage")
+			return exitError
+		}
+		defer boltStore.Close()
+		store = boltStore
+	}
+
+	// Shed work before in-flight messages exhaust memory
+	guard := memguard.New(memguard.Config{Limit: cfg.Server.MemoryLimit})
+	memguard.SetDefault(guard)
+
+	// Let subsystems announce events to whoever reacts to them
+	bus := events.New()
+	events.SetDefault(bus)
+	defer bus.Close()
+
+	// Create the configured transports, reloading their TLS certificate
+	// when it changes
+	transports, stopCerts, err := newTransports(ctx, cfg.Listen)
+	if err != nil {
+		logger.Error(ctx, err, "Failed to load TLS certificate")
+		return exitTransport
+	}
+	defer stopCerts()
+
+	// Keep the schemas of tools and prompts in one place, and check tool
+	// arguments against them
+	schemas := schema.New()
+
+	// The server owns the handshake server, its connections and hooks, and
+	// the tool registry. Local tools are published through the registry so
+	// they can be toggled at runtime.
+	var reload *reloader
+	rt := router.New()
+
+	// Let privileged clients profile single tool calls when debugging is
+	// enabled. Identities are those of listen.tokens, or local for stdio
+	// and Unix socket clients.
+	var toolMiddleware []mcpserver.ToolHandlerMiddleware
+	var profiler *profiling.Profiler
+	if cfg.Debug.Enabled && len(cfg.Debug.ProfileIdentities) > 0 {
+		profiler = profiling.New(profiling.Config{Authorize: admin.AllowIdentities(cfg.Debug.ProfileIdentities...)})
+		toolMiddleware = append(toolMiddleware, profiler.Middleware)
+	}
+	// Reject tool calls whose arguments break the rules of a policy file,
+	// before they count against quotas
+	var policies *policy.Engine
+	if policyFile := cfg.Policy.File; policyFile != "" {
+		policyConfig, err := policy.LoadConfig(policyFile)
+		if err != nil {
+			logger.Error(ctx, err, "Failed to load policy")
+			return exitConfig
+		}
+		policies = policy.NewEngine(policyConfig)
+		toolMiddleware = append(toolMiddleware, policy.ToolMiddleware(policies))
+	}
+	// Limit the tool calls of each identity when quotas are configured
+	var quotas *quota.Tracker
+	if cfg.Quota.Window > 0 {
+		quotas = quota.NewTracker(quota.Config{Limits: []quota.Limit{{
+			Window:      cfg.Quota.Window,
+			MaxCalls:    cfg.Quota.Calls,
+			MaxExecTime: cfg.Quota.ExecTime,
+		}}, Store: store})
+		toolMiddleware = append(toolMiddleware, quota.ToolMiddleware(quotas))
+	}
+	var receipts *tools.ReceiptStore
+	if cfg.Server.ReceiptWindow > 0 {
+		// Receipts are only shown to the identity they were issued to
+		receipts = tools.NewReceiptStore(tools.ReceiptConfig{Window: cfg.Server.ReceiptWindow, Scope: quota.DefaultIdentity})
+		rt.Register(tools.ReceiptMethod, tools.ReceiptHandler(receipts))
+	}
+	// Record sampled exchanges with clients when a wire log is configured
+	var wireLog *router.WireLogger
+	if path := cfg.Debug.WireLog; path != "" && !cfg.Debug.Enabled {
+		logger.WithField("profile", cfg.Profile).Warn(ctx, "Debug endpoints are disabled; ignoring debug.wireLog")
+	} else if path != "" {
+		wireSink, err := logging.NewFileSink(logging.FileConfig{Path: path})
+		if err != nil {
+			logger.Error(ctx, err, "Failed to open wire log")
+			return exitConfig
+		}
+		defer wireSink.Close()
+		wireLog = router.NewWireLogger(router.WireLogConfig{
+			Output:     wireSink,
+			SampleRate: float64(cfg.Debug.WireLogSample) / 100,
+			Methods:    cfg.Debug.WireLogMethods,
+		})
+		logger.WithField("wire_log", path).Info(ctx, "Recording client exchanges")
+	}
+	serverConfig := newServerConfig(cfg, store)
+	serverConfig.WireLog = wireLog
+	options := []server.Option{
+		server.WithRouter(rt),
+		server.WithAsync(router.AsyncRouterConfig{}),
+		server.WithHandshake(newHandshakeConfig(cfg)),
+		server.WithTransports(transports...),
+		server.WithConfig(serverConfig),
+		server.WithTools(tools.Config{
+			Idempotency:       tools.NewIdempotencyCache(tools.IdempotencyConfig{}),
+			Receipts:          receipts,
+			Schemas:           schemas,
+			ValidateArguments: true,
+			Middleware:        toolMiddleware,
+			PartialResults: func(ctx context.Context) bool {
+				client, _ := mcpcontext.ClientCapabilities(ctx)
+				_, ok := client.Experimental[tools.PartialResultsCapability]
+				return ok
+			},
+		}),
+		server.WithReload(func(ctx context.Context) error {
+			return reload.reload(ctx)
+		}),
+	}
+
+	// Count failed requests over the last minute, failing readiness while
+	// more than health.errorBudget percent of them failed
+	budgets := make(map[string]mcperrors.Budget)
+	if budget := cfg.Health.ErrorBudget; budget > 0 {
+		budgets[mcperrors.CategoryAll] = mcperrors.Budget{MaxRate: float64(budget) / 100, MinRequests: errorBudgetMinRequests}
+	}
+	errorRecorder := mcperrors.NewRecorder(mcperrors.RecorderConfig{
+		Budgets: budgets,
+		OnBudgetExceeded: func(e mcperrors.BudgetEvent) {
+			logger.WithFields(logging.LogFields{
+				"category":   e.Category,
+				"error_rate": e.Rate,
+				"requests":   e.Requests,
+			}).Warn(ctx, "Error budget exceeded")
+		},
+	})
+	options = append(options, server.WithHooks(errorRecorder.RegisterHooks))
+
+	// Export Prometheus metrics when an address is configured
+	metricsAddr := cfg.Metrics.Addr
+	serverMetrics := metrics.New(metrics.Config{
+		Path:           cfg.Metrics.Path,
+		IncludeRuntime: true,
+	})
+	metrics.SetDefault(serverMetrics)
+	if metricsAddr != "" {
+		options = append(options, server.WithHooks(serverMetrics.RegisterHooks))
+	}
+
+	srv := server.NewServer(options...)
+	hs := srv.MCP()
+	toolRegistry := srv.Tools()
+
+	if metricsAddr != "" {
+		serverMetrics.ObserveConnections(srv.Connections())
+		serverMetrics.ObserveHandshakes(hs)
+		serverMetrics.ObserveEvents(bus)
+		if err := serverMetrics.ObserveAsyncRouter(srv.Async()); err != nil {
+			logger.Error(ctx, err, "Failed to export async router metrics")
+		}
+		if err := serverMetrics.ObserveErrors(errorRecorder); err != nil {
+			logger.Error(ctx, err, "Failed to export error rate metrics")
+		}
+		go func() {
+			if err := serverMetrics.ListenAndServe(ctx, metricsAddr); err != nil {
+				logger.Error(ctx, err, "Metrics endpoint failed")
+			}
+		}()
+		logger.WithField("metrics_addr", metricsAddr).Info(ctx, "Serving Prometheus metrics")
+	}
+
+	// Serve the admin/* methods to the identities allowed to call them,
+	// rejecting other router requests while draining
+	stats := map[string]admin.StatsFunc{
+		"handshakes": func() interface{} { return hs.GetStats() },
+		"router":     func() interface{} { return rt.GetStats() },
+		"async":      func() interface{} { return srv.Async().Stats() },
+	}
+	if policies != nil {
+		stats["policy"] = func() interface{} { return policies.GetStats() }
+	}
+	adminAPI, err := admin.New(admin.Config{
+		Auth:        admin.AllowIdentities(cfg.Admin.Identities...),
+		Connections: srv.Connections(),
+		Upstreams:   srv.UpstreamHealth,
+		Reload:      srv.Reload,
+		Stats:       stats,
+	})
+	if err != nil {
+		logger.Error(ctx, err, "Failed to set up admin methods")
+		return exitError
+	}
+	adminAPI.Handle(tools.AdminMethod, tools.AdminHandler(toolRegistry))
+	adminAPI.Handle(schema.AdminMethod, schema.AdminHandler(schemas))
+	if quotas != nil {
+		adminAPI.Handle(quota.AdminMethod, quota.AdminHandler(quotas))
+	}
+	if profiler != nil {
+		adminAPI.Handle(profiling.AdminMethod, profiler.AdminHandler())
+	}
+	adminAPI.Register(rt)
+	srv.Use(adminAPI.DrainMiddleware(), router.ErrorRecorderMiddleware(errorRecorder))
+
+	// Report the health of the transports, upstreams and request queue
+	// through health/check, and as probes when an address is configured
+	serverHealth := health.New(health.Config{ConfigVersion: strconv.Itoa(cfg.Version)})
+	serverHealth.AddReadinessCheck("transports", health.TransportCheck(srv))
+	serverHealth.AddReadinessCheck("upstreams", health.UpstreamCheck(srv.UpstreamHealth))
+	serverHealth.AddReadinessCheck("queue", health.QueueCheck(srv.Async(), 0.9))
+	if cfg.Health.ErrorBudget > 0 {
+		serverHealth.AddReadinessCheck("errors", health.ErrorBudgetCheck(errorRecorder))
+	}
+	rt.Register(health.Method, serverHealth.Handler())
+	if healthAddr := cfg.Health.Addr; healthAddr != "" {
+		mux := http.NewServeMux()
+		serverHealth.Register(mux)
+		go func() {
+			if err := http.ListenAndServe(healthAddr, mux); err != nil {
+				logger.Error(ctx, err, "Health endpoint failed")
+			}
+		}()
+		logger.WithField("health_addr", healthAddr).Info(ctx, "Serving health probes")
+	}
+
+	// Serve token-protected debug endpoints when an address is configured
+	if debugAddr := cfg.Debug.Addr; debugAddr != "" && !cfg.Debug.Enabled {
+		logger.WithField("profile", cfg.Profile).Warn(ctx, "Debug endpoints are disabled; ignoring debug.addr")
+	} else if debugAddr != "" {
+		logs, _ := logger.RingBuffer()
+		debugServer, err := debug.New(debug.Config{
+			Token:       cfg.Debug.Token,
+			Connections: srv.Connections(),
+			Logs:        logs,
+			Guard:       guard,
+			AsyncRouter: srv.Async(),
+		})
+		if err != nil {
+			logger.Error(ctx, err, "Debug endpoint disabled")
+		} else {
+			go func() {
+				if err := debugServer.ListenAndServe(ctx, debugAddr); err != nil {
+					logger.Error(ctx, err, "Debug endpoint failed")
+				}
+			}()
+			logger.WithField("debug_addr", debugAddr).Info(ctx, "Serving debug endpoints")
+		}
+	}
+
+	registerBuiltinTools(toolRegistry, cfg.Server.Version)
+	if profiler != nil {
+		profiler.Register(hs)
+	}
+	buildinfo.Register(hs, newBuildInfo(cfg))
+
+	// Cache read resources, revalidating them with their source
+	resourceCache := newResourceCache(cfg)
+	if resourceCache != nil && metricsAddr != "" {
+		if err := serverMetrics.ObserveResourceCache(resourceCache); err != nil {
+			logger.Error(ctx, err, "Failed to export resource cache metrics")
+		}
+	}
+
+	// Expose files under the configured roots as resources
+	fileConfig, err := newFileConfig(cfg)
+	if err != nil {
+		logger.Error(ctx, err, "Invalid resources.roots")
+		return exitConfig
+	}
+	fileConfig.Cache = resourceCache
+	fileProvider, err := resources.NewFileProvider(fileConfig)
+	if err != nil {
+		logger.Error(ctx, err, "Failed to open resource roots")
+		return exitError
+	}
+	defer fileProvider.Close()
+	if err := fileProvider.Register(hs); err != nil {
+		logger.Error(ctx, err, "Failed to register file resources")
+	}
+	if documents := fileProvider.Documents(); documents != nil {
+		documents.RegisterRoutes(rt)
+	}
+	rt.Register(config.AdminMethod, config.AdminHandler())
+	rt.Register(mcperrors.CatalogMethod, router.HandlerFunc(mcperrors.DefaultRegistry().Handler()))
+
+	// Serve prompt templates when a prompts file is configured
+	if promptsFile := cfg.Prompts.File; promptsFile != "" {
+		promptConfig, err := prompts.LoadConfig(promptsFile)
+		if err != nil {
+			logger.Error(ctx, err, "Failed to load prompts")
+			return exitConfig
+		}
+		promptConfig.Resources = fileProvider.Read
+		promptConfig.Schemas = schemas
+		promptEngine, err := prompts.NewEngine(promptConfig)
+		if err != nil {
+			logger.Error(ctx, err, "Invalid prompts")
+			return exitConfig
+		}
+		promptEngine.Register(hs)
+	}
+
+	// Allow fetching from allowlisted domains when configured
+	httpProvider, err := newHTTPProvider(cfg, resourceCache)
+	if err != nil {
+		logger.Error(ctx, err, "Invalid fetch.domains")
+		return exitConfig
+	}
+	if httpProvider != nil {
+		httpProvider.Register(hs)
+		toolRegistry.MustRegister(httpProvider.ToolDefinition())
+	}
+
+	// Serve resources computed by tools when a virtual resources file is
+	// configured
+	if virtualFile := cfg.Resources.VirtualFile; virtualFile != "" {
+		virtualConfig, err := resources.LoadVirtualConfig(virtualFile)
+		if err != nil {
+			logger.Error(ctx, err, "Failed to load virtual resources")
+			return exitConfig
+		}
+		virtualConfig.Call = toolRegistry.Call
+		virtualProvider, err := resources.NewVirtualProvider(virtualConfig)
+		if err != nil {
+			logger.Error(ctx, err, "Invalid virtual resources")
+			return exitConfig
+		}
+		virtualProvider.Register(hs)
+	}
+
+	// Load tool plugins when a plugins file is configured
+	reload = &reloader{options: opts, current: cfg, registry: toolRegistry, health: serverHealth}
+	if cfg.Plugins.File != "" {
+		pluginManager, err := startPlugins(ctx, cfg.Plugins.File, toolRegistry)
+		if pluginManager == nil {
+			logger.Error(ctx, err, "Failed to load plugins")
+			return exitConfig
+		}
+		if err != nil {
+			logger.Error(ctx, err, "Some plugins failed to load")
+		}
+		reload.plugins = pluginManager
+	}
+	defer reload.shutdown()
+
+	// Run background jobs, delivering their results to connected clients
+	jobs := scheduler.New(scheduler.Config{Notifier: hs})
+	if err := jobs.Start(); err != nil {
+		logger.Error(ctx, err, "Failed to start scheduler")
+		return exitError
+	}
+	defer jobs.Shutdown(context.Background())
+	if metricsAddr != "" {
+		if err := serverMetrics.ObserveScheduler(jobs); err != nil {
+			logger.Error(ctx, err, "Failed to export scheduler metrics")
+		}
+	}
+
+	// Serve every configured transport with handshake support
+	logger.Info(ctx, newBuildInfo(cfg).Banner())
+	logger.Info(ctx, "Starting Meta-MCP Server with handshake support...")
+	logger.WithFields(logging.LogFields{
+		"server_name":       cfg.Server.Name,
+		"version":           cfg.Server.Version,
+		"handshake_timeout": cfg.Server.HandshakeTimeout,
+		"memory_limit":      cfg.Server.MemoryLimit,
+		"profile":           cfg.Profile,
+	}).Info(ctx, "Server configuration loaded")
+
+	// A signal shuts the server down through Shutdown, which tells the
+	// clients before closing their connections
+	if err := srv.Start(context.WithoutCancel(ctx)); err != nil {
+		logger.Error(ctx, err, "Server error")
+		return exitTransport
+	}
+
+	// Reload the configuration on SIGHUP while serving
+	go watchReloads(ctx, srv.Reload)
+
+	daemon.Notify(daemon.Ready)
+	select {
+	case <-ctx.Done():
+	case <-srv.Done():
+	}
+	daemon.Notify(daemon.Stopping)
+	srv.Shutdown(context.Background())
+	err = srv.Wait()
+	if err != nil {
+		logger.Error(ctx, err, "Server error")
+		return exitTransport
+	}
+	logger.Info(ctx, "Server stopped")
+	return exitOK
+}
+
+// newHandshakeConfig returns the configuration of the MCP server
+func newHandshakeConfig(cfg *config.Config) mcp.HandshakeConfig {
+	return mcp.HandshakeConfig{
+		Name:               cfg.Server.Name,
+		Version:            cfg.Server.Version,
+		HandshakeTimeout:   cfg.Server.HandshakeTimeout,
+		SupportedVersions:  cfg.Server.SupportedVersions,
+		RequireInitialized: !cfg.Server.LenientHandshake,
+		ServerOptions: []mcpserver.ServerOption{
+			mcp.WithToolCapabilities(true),
+			mcp.WithResourceCapabilities(true, true),
+			mcp.WithRecovery(),
+			mcpserver.WithToolHandlerMiddleware(tracing.ToolMiddleware()),
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/delivery"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/compat"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/storage"
+	"github.com/meta-mcp/meta-mcp-server/internal/throttle"
+	"github.com/meta-mcp/meta-mcp-server/internal/truncate"
+)
+
+// newTransports creates the configured transports. The certificate of the
+// HTTP transports is reloaded when it changes until ctx is done or stop is
+// called.
+func newTransports(ctx context.Context, cfg config.ListenConfig) (transports []server.Transport, stop func(), err error) {
+	tokens, err := cfg.Identities()
+	if err != nil {
+		return nil, nil, err
+	}
+	certs, err := newCertManager(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop = func() {}
+	if certs != nil {
+		stop = certs.Stop
+		go certs.Watch(ctx)
+	}
+
+	httpConfig := func(addr string) server.HTTPConfig {
+		c := server.HTTPConfig{Addr: addr, Tokens: tokens}
+		if len(cfg.Origins) > 0 {
+			cors := transport.DefaultCORSConfig()
+			cors.AllowedOrigins = cfg.Origins
+			c.CORS = &cors
+		}
+		if certs != nil {
+			c.TLS = certs.TLSConfig()
+		}
+		return c
+	}
+
+	if cfg.Stdio {
+		transports = append(transports, server.Stdio())
+	}
+	if cfg.Socket != "" {
+		transports = append(transports, server.NewSocket("unix", cfg.Socket))
+	}
+	if cfg.SSE != "" {
+		transports = append(transports, server.NewSSE(httpConfig(cfg.SSE)))
+	}
+	if cfg.WebSocket != "" {
+		transports = append(transports, server.NewWebSocket(httpConfig(cfg.WebSocket)))
+	}
+	return transports, stop, nil
+}
+
+// newServerConfig returns how clients are served. Clients are told about
+// tool changes only when the tools they may see change, and legacy clients
+// get messages adapted to their protocol version. Resources are converted
+// to the representation clients ask for, and initialize results describe
+// the build of the server. Unacknowledged notifications are kept in store
+// when it is not nil.
+func newServerConfig(cfg *config.Config, store storage.Store) server.Config {
+	serverConfig := server.Config{
+		OrderedNotifications: cfg.Server.OrderedNotifications,
+		MaxRequestTimeout:    cfg.Server.MaxRequestTimeout,
+		WriteTimeout:         cfg.Server.WriteTimeout,
+		Shims:                compat.Builtin,
+		History:              cfg.Debug.History,
+		WireChecks:           cfg.Debug.WireChecks,
+		Negotiator:           resources.NewNegotiator(resources.NegotiatorConfig{}),
+		DrainGrace:           cfg.Server.DrainGrace,
+	}
+	info := newBuildInfo(cfg)
+	serverConfig.BuildInfo = &info
+	// Clients may read web pages as markdown
+	serverConfig.Negotiator.Register(resources.HTMLToMarkdown)
+	if cfg.Server.ReliableNotifications {
+		serverConfig.Delivery = delivery.New(delivery.Config{Window: cfg.Server.ResumeWindow, Store: store})
+	}
+	if cfg.Server.MaxResponseSize > 0 {
+		serverConfig.Responses = truncate.New(truncate.Config{MaxBytes: cfg.Server.MaxResponseSize, TTL: cfg.Server.ContinuationTTL})
+	}
+	if cfg.Server.ConnectionBandwidth > 0 || cfg.Server.Bandwidth > 0 {
+		serverConfig.Throttle = throttle.New(throttle.Config{PerConnection: cfg.Server.ConnectionBandwidth, Global: cfg.Server.Bandwidth})
+	}
+	return serverConfig
+}
+
+// newCertManager loads the TLS certificate of the HTTP transports, or
+// obtains them over ACME, and returns nil when neither is configured
+func newCertManager(cfg config.ListenConfig) (*transport.CertManager, error) {
+	if len(cfg.ACMEDomains) > 0 {
+		return transport.NewCertManager(transport.CertConfig{ACME: &transport.ACMEConfig{
+			Hosts:    cfg.ACMEDomains,
+			CacheDir: cfg.ACMECacheDir,
+			Email:    cfg.ACMEEmail,
+		}})
+	}
+	if cfg.TLSCert == "" {
+		return nil, nil
+	}
+	return transport.NewCertManager(transport.CertConfig{CertFile: cfg.TLSCert, KeyFile: cfg.TLSKey})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/plugins"
+	"github.com/meta-mcp/meta-mcp-server/internal/policy"
+	"github.com/meta-mcp/meta-mcp-server/internal/prompts"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// upstreamTimeout bounds each reachability check of the validate command
+const upstreamTimeout = 5 * time.Second
+
+// runValidate checks the configuration and the resources it refers to
+// without serving
+func runValidate(args []string) int {
+	var offline, quiet bool
+	cfg, sources, err := loadConfig(config.Options{Name: "validate", Args: args, Flags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&offline, "offline", false, "skip checks that need the network or start plugins")
+		fs.BoolVar(&quiet, "quiet", false, "do not print the effective configuration")
+	}})
+	if err != nil {
+		return exitCode(err)
+	}
+	if !quiet {
+		config.Print(os.Stdout, cfg, sources)
+	}
+
+	problems := checkConfig(cfg, offline)
+	if len(problems) > 0 {
+		fmt.Fprintln(os.Stderr, "configuration check failed:")
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		return exitError
+	}
+	fmt.Fprintln(os.Stderr, "configuration OK")
+	return exitOK
+}
+
+// checkConfig opens everything the configuration refers to and returns the
+// problems found
+func checkConfig(cfg *config.Config, offline bool) []string {
+	var problems []string
+
+	fileConfig, err := newFileConfig(cfg)
+	if err == nil {
+		fileConfig.Watch = false
+		var provider *resources.FileProvider
+		if provider, err = resources.NewFileProvider(fileConfig); err == nil {
+			provider.Close()
+		}
+	}
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("resources.roots: %v", err))
+	}
+
+	if file := cfg.Prompts.File; file != "" {
+		promptConfig, err := prompts.LoadConfig(file)
+		if err == nil {
+			_, err = prompts.NewEngine(promptConfig)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("prompts.file: %v", err))
+		}
+	}
+
+	if file := cfg.Policy.File; file != "" {
+		if _, err := policy.LoadConfig(file); err != nil {
+			problems = append(problems, fmt.Sprintf("policy.file: %v", err))
+		}
+	}
+
+	if _, err := newHTTPProvider(cfg, nil); err != nil {
+		problems = append(problems, fmt.Sprintf("fetch.domains: %v", err))
+	}
+
+	var pluginProblems []string
+	if file := cfg.Plugins.File; file != "" {
+		pluginProblems = checkPlugins(file)
+		problems = append(problems, pluginProblems...)
+	}
+
+	if offline {
+		return problems
+	}
+
+	for _, domain := range cfg.Fetch.Domains {
+		ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+		_, err := net.DefaultResolver.LookupHost(ctx, domain)
+		cancel()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("fetch.domains: %s: %v", domain, err))
+		}
+	}
+
+	// Starting the plugins runs their handshake, which is the only way to
+	// tell that a plugin is usable
+	if file := cfg.Plugins.File; file != "" && len(pluginProblems) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+		defer cancel()
+		manager, err := startPlugins(ctx, file, tools.New(tools.Config{}))
+		if manager != nil {
+			defer manager.Shutdown(context.Background())
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("plugins.file: %v", err))
+		}
+	}
+
+	return problems
+}
+
+// checkPlugins reports plugins whose command or shared object is missing
+func checkPlugins(file string) []string {
+	pluginConfig, err := plugins.LoadConfig(file)
+	if err != nil {
+		return []string{fmt.Sprintf("plugins.file: %v", err)}
+	}
+
+	var problems []string
+	for _, spec := range pluginConfig.Plugins {
+		if spec.Command != "" {
+			if _, err := exec.LookPath(spec.Command); err != nil {
+				problems = append(problems, fmt.Sprintf("plugin %s: %v", spec.Name, err))
+			}
+		}
+		if spec.Path != "" {
+			if _, err := os.Stat(spec.Path); err != nil {
+				problems = append(problems, fmt.Sprintf("plugin %s: %v", spec.Name, err))
+			}
+		}
+	}
+	return problems
+}
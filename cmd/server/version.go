@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/buildinfo"
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+)
+
+// Build information, set by the Makefile through -ldflags
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
+
+// currentBuildInfo returns the build information of the binary
+func currentBuildInfo() buildinfo.Info {
+	return buildinfo.Read(Version, GitCommit, BuildTime)
+}
+
+// newBuildInfo returns the build information of the binary with the
+// features and protocol versions of cfg
+func newBuildInfo(cfg *config.Config) buildinfo.Info {
+	info := currentBuildInfo()
+	info.ProtocolVersions = cfg.Server.SupportedVersions
+	features := []struct {
+		name    string
+		enabled bool
+	}{
+		{"ordered-notifications", cfg.Server.OrderedNotifications},
+		{"lenient-handshake", cfg.Server.LenientHandshake},
+		{"reliable-notifications", cfg.Server.ReliableNotifications},
+		{"memory-guard", cfg.Server.MemoryLimit > 0},
+		{"response-truncation", cfg.Server.MaxResponseSize > 0},
+		{"bandwidth-throttling", cfg.Server.ConnectionBandwidth > 0 || cfg.Server.Bandwidth > 0},
+		{"content-negotiation", true},
+		{"resource-watch", cfg.Resources.Watch},
+		{"incremental-resources", cfg.Resources.Incremental},
+		{"resource-cache", cfg.Resources.CacheEntries > 0},
+		{"virtual-resources", cfg.Resources.VirtualFile != ""},
+		{"prompts", cfg.Prompts.File != ""},
+		{"fetch", len(cfg.Fetch.Domains) > 0},
+		{"plugins", cfg.Plugins.File != ""},
+		{"metrics", cfg.Metrics.Addr != ""},
+		{"health", cfg.Health.Addr != ""},
+		{"debug", cfg.Debug.Enabled && cfg.Debug.Addr != ""},
+		{"profiling", cfg.Debug.Enabled && len(cfg.Debug.ProfileIdentities) > 0},
+		{"wire-checks", cfg.Debug.WireChecks},
+		{"history", cfg.Debug.History > 0},
+	}
+	for _, feature := range features {
+		if feature.enabled {
+			info.Features = append(info.Features, feature.name)
+		}
+	}
+	return info
+}
+
+// runVersion prints build information
+func runVersion(args []string) int {
+	fs := flag.NewFlagSet("meta-mcp-server version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitCode(err)
+	}
+
+	info := currentBuildInfo()
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(info)
+		return exitOK
+	}
+	fmt.Printf("meta-mcp-server %s\n", info.Version)
+	fmt.Printf("  commit:   %s\n", info.GitCommit)
+	fmt.Printf("  built:    %s\n", info.BuildTime)
+	fmt.Printf("  go:       %s\n", info.GoVersion)
+	fmt.Printf("  platform: %s\n", info.Platform)
+	return exitOK
+}
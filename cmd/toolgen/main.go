@@ -0,0 +1,53 @@
+// Command toolgen generates a typed params struct, validation and handler
+// adapter from an MCP tool definition (name, description, inputSchema and
+// optional outputSchema) in JSON. Use it from go:generate:
+//
+//	//go:generate go run github.com/meta-mcp/meta-mcp-server/cmd/toolgen -in calculate.json -out calculate_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/tools/toolgen"
+)
+
+func main() {
+	in := flag.String("in", "", "tool definition JSON file")
+	out := flag.String("out", "", "output Go file (default stdout)")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	typeName := flag.String("type", "", "prefix for generated identifiers (default from tool name)")
+	flag.Parse()
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "toolgen: -in is required")
+		os.Exit(2)
+	}
+
+	definition, err := os.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "toolgen:", err)
+		os.Exit(1)
+	}
+
+	src, err := toolgen.Generate(definition, toolgen.Config{
+		Package:  *pkg,
+		TypeName: *typeName,
+		Source:   filepath.Base(*in),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "toolgen: %s: %v\n", *in, err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "toolgen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,220 @@
+// Package admin implements the reserved admin/* method namespace, a
+// programmatic control plane for operators of a running server.
+//
+// Every admin method is guarded by Config.Auth, so the namespace can be
+// served on the same router as client traffic. Built-in methods:
+//
+//   - admin/connections: connection table
+//   - admin/upstreams: upstream health
+//   - admin/reload: re-read configuration via Config.Reload
+//   - admin/logLevel: get or set the minimum log level
+//   - admin/stats: statistics from every configured source
+//   - admin/drain: stop accepting new requests ahead of a shutdown
+//
+// Other packages' admin handlers (admin/tools, admin/quota, ...) are added
+// with Handle so they share the same guard.
+//
+// Basic usage:
+//
+//	api, err := admin.New(admin.Config{
+//		Auth:        admin.AllowIdentities("ops@example.com"),
+//		Connections: connManager,
+//	})
+//	api.Handle(tools.AdminMethod, tools.AdminHandler(registry))
+//	api.Register(r)
+//	srv.Use(api.DrainMiddleware())
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/quota"
+)
+
+// MethodPrefix is the reserved namespace of admin methods
+const MethodPrefix = "admin/"
+
+// Built-in admin methods
+const (
+	MethodConnections = "admin/connections"
+	MethodUpstreams   = "admin/upstreams"
+	MethodReload      = "admin/reload"
+	MethodLogLevel    = "admin/logLevel"
+	MethodStats       = "admin/stats"
+	MethodDrain       = "admin/drain"
+	MethodHistory     = "admin/history"
+)
+
+var (
+	// ErrNoAuth is returned when the API is configured without an AuthFunc
+	ErrNoAuth = errors.New("admin: auth is required")
+
+	// errDenied is returned by AllowIdentities for other callers
+	errDenied = errors.New("admin access denied")
+)
+
+// UpstreamHealthFunc reports the health of a set of upstreams by name
+type UpstreamHealthFunc func() map[string]bool
+
+// StatsFunc returns a JSON-serializable statistics snapshot
+type StatsFunc func() interface{}
+
+// Config contains configuration for the admin API
+type Config struct {
+	// Auth authorizes every admin request (required)
+	Auth router.AuthFunc
+
+	// Connections is listed by admin/connections, and their message
+	// histories read by admin/history (optional)
+	Connections *connection.Manager
+
+	// Upstreams is listed by admin/upstreams (optional)
+	Upstreams UpstreamHealthFunc
+
+	// Reload re-reads configuration for admin/reload (optional)
+	Reload func(ctx context.Context) error
+
+	// Stats are the named sources reported by admin/stats
+	Stats map[string]StatsFunc
+
+	// OnDrain is called when draining starts (optional)
+	OnDrain func()
+}
+
+// API serves the admin namespace
+type API struct {
+	config Config
+
+	mu       sync.RWMutex
+	handlers map[string]router.Handler
+
+	draining atomic.Bool
+	inFlight atomic.Int64
+}
+
+// New creates the admin API with its built-in methods
+func New(config Config) (*API, error) {
+	if config.Auth == nil {
+		return nil, ErrNoAuth
+	}
+
+	a := &API{
+		config:   config,
+		handlers: make(map[string]router.Handler),
+	}
+	a.handlers[MethodConnections] = router.HandlerFunc(a.handleConnections)
+	a.handlers[MethodUpstreams] = router.HandlerFunc(a.handleUpstreams)
+	a.handlers[MethodReload] = router.HandlerFunc(a.handleReload)
+	a.handlers[MethodLogLevel] = router.HandlerFunc(a.handleLogLevel)
+	a.handlers[MethodStats] = router.HandlerFunc(a.handleStats)
+	a.handlers[MethodDrain] = router.HandlerFunc(a.handleDrain)
+	a.handlers[MethodHistory] = router.HandlerFunc(a.handleHistory)
+	return a, nil
+}
+
+// AllowIdentities authorizes callers whose identity, as resolved by
+// quota.DefaultIdentity, is one of ids
+func AllowIdentities(ids ...string) router.AuthFunc {
+	allowed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		allowed[id] = true
+	}
+	return func(ctx context.Context, method string) error {
+		if !allowed[quota.DefaultIdentity(ctx)] {
+			return errDenied
+		}
+		return nil
+	}
+}
+
+// IsReserved reports whether method belongs to the admin namespace
+func IsReserved(method string) bool {
+	return strings.HasPrefix(method, MethodPrefix)
+}
+
+// Handle adds an admin method. It panics if method is outside the admin
+// namespace.
+func (a *API) Handle(method string, handler router.Handler) {
+	if !IsReserved(method) {
+		panic(fmt.Sprintf("admin: method %q is outside the %s namespace", method, MethodPrefix))
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.handlers[method] = handler
+}
+
+// Methods returns the admin methods in sorted order
+func (a *API) Methods() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	methods := make([]string, 0, len(a.handlers))
+	for method := range a.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Register adds every admin method to r behind the auth guard, replacing any
+// handler previously registered under the same name
+func (a *API) Register(r *router.Router) {
+	guard := router.AuthMiddleware(a.config.Auth)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for method, handler := range a.handlers {
+		r.Register(method, guard(handler))
+	}
+}
+
+// Draining reports whether the server is draining
+func (a *API) Draining() bool {
+	return a.draining.Load()
+}
+
+// InFlight returns the number of non-admin requests being handled
+func (a *API) InFlight() int64 {
+	return a.inFlight.Load()
+}
+
+// DrainMiddleware counts requests in flight and, while draining, rejects
+// every request outside the admin namespace as service unavailable
+func (a *API) DrainMiddleware() router.Middleware {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			if IsReserved(req.Method) {
+				return next.Handle(ctx, req)
+			}
+			if a.draining.Load() {
+				err := mcperrors.NewServiceUnavailableError("server", "draining")
+				return jsonrpc.NewErrorResponse(err.ToJSONRPCError(), req.ID)
+			}
+
+			a.inFlight.Add(1)
+			defer a.inFlight.Add(-1)
+			return next.Handle(ctx, req)
+		})
+	}
+}
+
+// setDraining changes the drain state, calling OnDrain when it starts
+func (a *API) setDraining(draining bool) {
+	if a.draining.Swap(draining) != draining && draining {
+		logging.Default().Warn(logging.WithComponent(context.Background(), "admin"), "Draining: rejecting new requests")
+		if a.config.OnDrain != nil {
+			a.config.OnDrain()
+		}
+	}
+}
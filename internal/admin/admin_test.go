@@ -0,0 +1,212 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/quota"
+)
+
+// operatorContext returns a context authenticated as identity
+func operatorContext(identity string) context.Context {
+	rc := router.NewRequestContext("test")
+	rc.SetMetadata(quota.IdentityMetadataKey, identity)
+	return router.WithRequestContext(context.Background(), rc)
+}
+
+func newTestAPI(t *testing.T, config Config) (*API, *router.Router) {
+	if config.Auth == nil {
+		config.Auth = AllowIdentities("ops")
+	}
+	api, err := New(config)
+	require.NoError(t, err)
+	r := router.New()
+	api.Register(r)
+	return api, r
+}
+
+func call(r *router.Router, identity, method string, params any) *jsonrpc.Response {
+	return r.Handle(operatorContext(identity), jsonrpc.NewRequest(method, params, 1))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.ErrorIs(t, err, ErrNoAuth)
+
+	api, _ := newTestAPI(t, Config{})
+	assert.Equal(t, []string{MethodConnections, MethodDrain, MethodHistory, MethodLogLevel, MethodReload, MethodStats, MethodUpstreams}, api.Methods())
+	assert.Panics(t, func() { api.Handle("tools/list", nil) })
+}
+
+func TestAuth(t *testing.T) {
+	api, r := newTestAPI(t, Config{})
+	api.Handle("admin/custom", router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse("ok", req.ID)
+	}))
+	api.Register(r)
+
+	for _, method := range api.Methods() {
+		resp := call(r, "mallory", method, nil)
+		require.NotNil(t, resp.Error, method)
+		assert.Equal(t, jsonrpc.ErrorCodeUnauthorized, resp.Error.Code, method)
+	}
+
+	resp := call(r, "ops", "admin/custom", nil)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "ok", resp.Result)
+}
+
+func TestConnectionsAndUpstreams(t *testing.T) {
+	connections := connection.NewManager(time.Second)
+	connections.CreateConnection("conn-1")
+	_, r := newTestAPI(t, Config{
+		Connections: connections,
+		Upstreams:   func() map[string]bool { return map[string]bool{"github": true, "fs": false} },
+	})
+
+	resp := call(r, "ops", MethodConnections, nil)
+	require.Nil(t, resp.Error)
+	conns := resp.Result.(ConnectionsResult).Connections
+	require.Len(t, conns, 1)
+	assert.Equal(t, "conn-1", conns[0].ID)
+
+	resp = call(r, "ops", MethodUpstreams, nil)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, []UpstreamStatus{{Name: "fs"}, {Name: "github", Healthy: true}}, resp.Result.(UpstreamsResult).Upstreams)
+}
+
+func TestHistory(t *testing.T) {
+	connections := connection.NewManager(time.Second)
+	recorded, _ := connections.CreateConnection("conn-1")
+	connections.CreateConnection("conn-2")
+	history := connection.NewHistory(10, nil)
+	history.Record(connection.Inbound, []byte(`{"method":"tools/list"}`))
+	recorded.SetHistory(history)
+	_, r := newTestAPI(t, Config{Connections: connections})
+
+	resp := call(r, "ops", MethodHistory, HistoryParams{ConnectionID: "conn-1"})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(HistoryResult)
+	assert.Equal(t, "conn-1", result.ConnectionID)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, `{"method":"tools/list"}`, result.Messages[0].Message)
+
+	resp = call(r, "ops", MethodHistory, HistoryParams{ConnectionID: "conn-2"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeNotImplemented, resp.Error.Code)
+
+	for _, params := range []any{nil, HistoryParams{ConnectionID: "missing"}} {
+		resp = call(r, "ops", MethodHistory, params)
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, resp.Error.Code)
+	}
+}
+
+func TestReload(t *testing.T) {
+	_, r := newTestAPI(t, Config{})
+	resp := call(r, "ops", MethodReload, nil)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeNotImplemented, resp.Error.Code)
+
+	reloads := 0
+	fail := false
+	_, r = newTestAPI(t, Config{Reload: func(ctx context.Context) error {
+		if fail {
+			return errors.New("bad yaml")
+		}
+		reloads++
+		return nil
+	}})
+
+	resp = call(r, "ops", MethodReload, nil)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, 1, reloads)
+
+	fail = true
+	resp = call(r, "ops", MethodReload, nil)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPHandler, resp.Error.Code)
+}
+
+func TestLogLevel(t *testing.T) {
+	defer logging.SetLevel(logging.GetLevel())
+	logging.SetLevel(logging.LogLevelInfo)
+	_, r := newTestAPI(t, Config{})
+
+	resp := call(r, "ops", MethodLogLevel, nil)
+	require.Nil(t, resp.Error)
+	assert.Equal(t, LogLevelResult{Level: "info"}, resp.Result)
+
+	resp = call(r, "ops", MethodLogLevel, map[string]any{"level": "DEBUG"})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, LogLevelResult{Level: "debug", Previous: "info"}, resp.Result)
+	assert.Equal(t, logging.LogLevelDebug, logging.GetLevel())
+
+	resp = call(r, "ops", MethodLogLevel, map[string]any{"level": "verbose"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, resp.Error.Code)
+}
+
+func TestStats(t *testing.T) {
+	_, r := newTestAPI(t, Config{
+		Connections: connection.NewManager(time.Second),
+		Stats: map[string]StatsFunc{
+			"quota": func() interface{} { return quota.Stats{Allowed: 3} },
+		},
+	})
+
+	resp := call(r, "ops", MethodStats, nil)
+	require.Nil(t, resp.Error)
+	stats := resp.Result.(StatsResult).Stats
+	assert.Contains(t, stats, "admin")
+	assert.Contains(t, stats, "connections")
+	assert.Equal(t, quota.Stats{Allowed: 3}, stats["quota"])
+
+	resp = call(r, "ops", MethodStats, map[string]any{"sources": []string{"quota"}})
+	require.Nil(t, resp.Error)
+	assert.Len(t, resp.Result.(StatsResult).Stats, 1)
+
+	resp = call(r, "ops", MethodStats, map[string]any{"sources": []string{"nope"}})
+	require.NotNil(t, resp.Error)
+}
+
+func TestDrain(t *testing.T) {
+	drained := 0
+	api, r := newTestAPI(t, Config{OnDrain: func() { drained++ }})
+	r.RegisterFunc("tools/list", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		assert.Equal(t, int64(1), api.InFlight())
+		return jsonrpc.NewResponse("tools", req.ID)
+	})
+	handler := router.NewChain(api.DrainMiddleware()).Then(r)
+
+	resp := handler.Handle(operatorContext("ops"), jsonrpc.NewRequest("tools/list", nil, 1))
+	require.Nil(t, resp.Error)
+
+	resp = handler.Handle(operatorContext("ops"), jsonrpc.NewRequest(MethodDrain, nil, 2))
+	require.Nil(t, resp.Error)
+	assert.Equal(t, DrainResult{Draining: true}, resp.Result)
+	handler.Handle(operatorContext("ops"), jsonrpc.NewRequest(MethodDrain, nil, 3))
+	assert.Equal(t, 1, drained)
+
+	resp = handler.Handle(operatorContext("ops"), jsonrpc.NewRequest("tools/list", nil, 4))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPServiceUnavail, resp.Error.Code)
+
+	// Admin methods stay available while draining
+	resp = handler.Handle(operatorContext("ops"), jsonrpc.NewRequest(MethodDrain, map[string]any{"enabled": false}, 5))
+	require.Nil(t, resp.Error)
+	assert.False(t, api.Draining())
+
+	resp = handler.Handle(operatorContext("ops"), jsonrpc.NewRequest("tools/list", nil, 6))
+	assert.Nil(t, resp.Error)
+}
@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// ConnectionsResult is the result of admin/connections
+type ConnectionsResult struct {
+	Connections []connection.ConnectionInfo `json:"connections"`
+}
+
+// UpstreamStatus describes one upstream
+type UpstreamStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+}
+
+// UpstreamsResult is the result of admin/upstreams
+type UpstreamsResult struct {
+	Upstreams []UpstreamStatus `json:"upstreams"`
+}
+
+// ReloadResult is the result of admin/reload
+type ReloadResult struct {
+	Reloaded bool `json:"reloaded"`
+}
+
+// LogLevelParams are the parameters of admin/logLevel; an empty Level
+// reads the current level
+type LogLevelParams struct {
+	Level string `json:"level,omitempty"`
+}
+
+// LogLevelResult is the result of admin/logLevel
+type LogLevelResult struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// StatsParams are the parameters of admin/stats; an empty Sources reports
+// every source
+type StatsParams struct {
+	Sources []string `json:"sources,omitempty"`
+}
+
+// StatsResult is the result of admin/stats
+type StatsResult struct {
+	Stats map[string]interface{} `json:"stats"`
+}
+
+// DrainParams are the parameters of admin/drain; Enabled defaults to true,
+// and false resumes accepting requests
+type DrainParams struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// DrainResult is the result of admin/drain
+type DrainResult struct {
+	Draining bool  `json:"draining"`
+	InFlight int64 `json:"inFlight"`
+}
+
+// HistoryParams are the parameters of admin/history
+type HistoryParams struct {
+	ConnectionID string `json:"connectionId"`
+}
+
+// HistoryResult is the result of admin/history: the last messages of the
+// connection, redacted, oldest first
+type HistoryResult struct {
+	ConnectionID string                    `json:"connectionId"`
+	Messages     []connection.HistoryEntry `json:"messages"`
+}
+
+// logLevels are the levels accepted by admin/logLevel
+var logLevels = map[string]logging.LogLevel{
+	"debug": logging.LogLevelDebug,
+	"info":  logging.LogLevelInfo,
+	"warn":  logging.LogLevelWarn,
+	"error": logging.LogLevelError,
+}
+
+// handleConnections lists connections
+func (a *API) handleConnections(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	result := ConnectionsResult{Connections: []connection.ConnectionInfo{}}
+	if a.config.Connections != nil {
+		result.Connections = a.config.Connections.Snapshot()
+	}
+	return jsonrpc.NewResponse(result, req.ID)
+}
+
+// handleUpstreams lists upstream health
+func (a *API) handleUpstreams(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	result := UpstreamsResult{Upstreams: []UpstreamStatus{}}
+	if a.config.Upstreams != nil {
+		for name, healthy := range a.config.Upstreams() {
+			result.Upstreams = append(result.Upstreams, UpstreamStatus{Name: name, Healthy: healthy})
+		}
+	}
+	sort.Slice(result.Upstreams, func(i, j int) bool { return result.Upstreams[i].Name < result.Upstreams[j].Name })
+	return jsonrpc.NewResponse(result, req.ID)
+}
+
+// handleReload re-reads configuration
+func (a *API) handleReload(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	if a.config.Reload == nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewError(jsonrpc.ErrorCodeNotImplemented, "Reload not supported", nil), req.ID)
+	}
+	if err := a.config.Reload(ctx); err != nil {
+		mcpErr := mcperrors.FindMCPError(err)
+		if mcpErr == nil {
+			mcpErr = mcperrors.NewHandlerError("Reload failed", map[string]interface{}{"reason": err.Error()})
+		}
+		return jsonrpc.NewErrorResponse(mcpErr.ToJSONRPCError(), req.ID)
+	}
+
+	logging.Default().Info(logging.WithComponent(ctx, "admin"), "Configuration reloaded")
+	return jsonrpc.NewResponse(ReloadResult{Reloaded: true}, req.ID)
+}
+
+// handleLogLevel reads or changes the log level
+func (a *API) handleLogLevel(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	var params LogLevelParams
+	if err := req.BindParams(&params); err != nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+	}
+
+	current := strings.ToLower(logging.GetLevel().String())
+	if params.Level == "" {
+		return jsonrpc.NewResponse(LogLevelResult{Level: current}, req.ID)
+	}
+
+	level, ok := logLevels[strings.ToLower(params.Level)]
+	if !ok {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unknown level: "+params.Level), req.ID)
+	}
+	logging.SetLevel(level)
+	return jsonrpc.NewResponse(LogLevelResult{Level: strings.ToLower(level.String()), Previous: current}, req.ID)
+}
+
+// handleStats collects statistics
+func (a *API) handleStats(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	var params StatsParams
+	if err := req.BindParams(&params); err != nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+	}
+
+	sources := a.statsSources()
+	names := params.Sources
+	if len(names) == 0 {
+		for name := range sources {
+			names = append(names, name)
+		}
+	}
+
+	result := StatsResult{Stats: make(map[string]interface{}, len(names))}
+	for _, name := range names {
+		source, ok := sources[name]
+		if !ok {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unknown stats source: "+name), req.ID)
+		}
+		result.Stats[name] = source()
+	}
+	return jsonrpc.NewResponse(result, req.ID)
+}
+
+// statsSources returns the configured sources plus the built-in ones
+func (a *API) statsSources() map[string]StatsFunc {
+	sources := make(map[string]StatsFunc, len(a.config.Stats)+2)
+	sources["admin"] = func() interface{} {
+		return DrainResult{Draining: a.Draining(), InFlight: a.InFlight()}
+	}
+	if a.config.Connections != nil {
+		sources["connections"] = func() interface{} {
+			counts := make(map[string]int)
+			for state, n := range a.config.Connections.StateCounts() {
+				counts[state.String()] = n
+			}
+			return counts
+		}
+	}
+	for name, source := range a.config.Stats {
+		sources[name] = source
+	}
+	return sources
+}
+
+// handleDrain starts or stops draining
+func (a *API) handleDrain(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	var params DrainParams
+	if err := req.BindParams(&params); err != nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+	}
+
+	a.setDraining(params.Enabled == nil || *params.Enabled)
+	return jsonrpc.NewResponse(DrainResult{Draining: a.Draining(), InFlight: a.InFlight()}, req.ID)
+}
+
+// handleHistory returns the recorded messages of a connection
+func (a *API) handleHistory(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	var params HistoryParams
+	if err := req.BindParams(&params); err != nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+	}
+	if params.ConnectionID == "" {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("connectionId is required"), req.ID)
+	}
+
+	var conn *connection.Connection
+	if a.config.Connections != nil {
+		conn, _ = a.config.Connections.GetConnection(params.ConnectionID)
+	}
+	if conn == nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unknown connection: "+params.ConnectionID), req.ID)
+	}
+	history := conn.History()
+	if history == nil {
+		return jsonrpc.NewErrorResponse(jsonrpc.NewError(jsonrpc.ErrorCodeNotImplemented, "Message history is not recorded", nil), req.ID)
+	}
+	return jsonrpc.NewResponse(HistoryResult{ConnectionID: params.ConnectionID, Messages: history.Entries()}, req.ID)
+}
@@ -0,0 +1,125 @@
+// Package buildinfo describes the running server binary: its version,
+// commit and build time, the Go toolchain, the features enabled by its
+// configuration and the protocol versions it speaks.
+//
+// The description is served to clients as the resource URI
+// (meta://server/info) and, over connections served by internal/server,
+// in the serverInfo of the initialize result:
+//
+//	"serverInfo": {
+//		"name": "Meta-MCP Server",
+//		"version": "1.4.0",
+//		"build": {"version": "1.4.0", "gitCommit": "9c1e2f0", ...}
+//	}
+package buildinfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+)
+
+// URI is the resource serving Info
+const URI = "meta://server/info"
+
+// unknown marks build information that was not recorded
+const unknown = "unknown"
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+
+	// Features are the optional features the configuration enables
+	Features []string `json:"features,omitempty"`
+
+	// ProtocolVersions are the MCP versions the server negotiates
+	ProtocolVersions []string `json:"protocolVersions,omitempty"`
+}
+
+// Read returns the information of the running binary, falling back to the
+// VCS stamp embedded by the go tool for a commit or build time left empty
+// or "unknown"
+func Read(version, commit, buildTime string) Info {
+	info := Info{
+		Version:   orUnknown(version),
+		GitCommit: orUnknown(commit),
+		BuildTime: orUnknown(buildTime),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.GitCommit == unknown:
+				info.GitCommit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == unknown:
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// Banner returns a one-line summary of info for the startup log
+func (info Info) Banner() string {
+	commit := info.GitCommit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	banner := fmt.Sprintf("Meta-MCP Server %s (commit %s, built %s, %s %s)",
+		info.Version, commit, info.BuildTime, info.GoVersion, info.Platform)
+	if len(info.Features) > 0 {
+		banner += " features: " + strings.Join(info.Features, ", ")
+	}
+	return banner
+}
+
+// Server is the part of the mcp-go server Info is published to
+type Server interface {
+	AddResources(resources ...server.ServerResource)
+}
+
+// connectionInfo is the resource content: Info and the protocol version
+// negotiated by the reading connection
+type connectionInfo struct {
+	Info
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+}
+
+// Register serves info as the resource URI
+func Register(s Server, info Info) {
+	resource := mcp.NewResource(URI, "Server build information",
+		mcp.WithResourceDescription("Version, commit, build time, enabled features and protocol versions of the server"),
+		mcp.WithMIMEType("application/json"))
+	s.AddResources(server.ServerResource{Resource: resource, Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		content := connectionInfo{Info: info}
+		if conn, ok := connection.FromContext(ctx); ok {
+			content.ProtocolVersion = conn.Info().ProtocolVersion
+		}
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: URI, MIMEType: "application/json", Text: string(data)}}, nil
+	}})
+}
+
+// orUnknown returns s, or "unknown" when it is empty
+func orUnknown(s string) string {
+	if s == "" {
+		return unknown
+	}
+	return s
+}
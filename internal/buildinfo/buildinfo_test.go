@@ -0,0 +1,37 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	info := Read("1.2.3", "abc", "2026-01-02T03:04:05Z")
+	assert.Equal(t, Info{
+		Version:   "1.2.3",
+		GitCommit: "abc",
+		BuildTime: "2026-01-02T03:04:05Z",
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}, info)
+
+	// Missing values are marked; test binaries carry no VCS stamp
+	info = Read("", "", "")
+	assert.Equal(t, "unknown", info.Version)
+	assert.NotEmpty(t, info.GitCommit)
+	assert.NotEmpty(t, info.BuildTime)
+}
+
+func TestInfo_Banner(t *testing.T) {
+	info := Info{
+		Version:   "1.2.3",
+		GitCommit: "0123456789abcdef",
+		BuildTime: "2026-01-02",
+		GoVersion: "go1.24.2",
+		Platform:  "linux/amd64",
+		Features:  []string{"metrics", "prompts"},
+	}
+	assert.Equal(t, "Meta-MCP Server 1.2.3 (commit 0123456789ab, built 2026-01-02, go1.24.2 linux/amd64) features: metrics, prompts", info.Banner())
+}
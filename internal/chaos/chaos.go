@@ -0,0 +1,233 @@
+// Package chaos injects faults into message streams to exercise resilience
+// paths in tests: dropped, duplicated, reordered, corrupted and delayed
+// messages and abrupt disconnects.
+//
+// An Injector decides which fault, if any, hits each message passing
+// through it, either at random with configured probabilities or on a fixed
+// schedule. Injectors plug into connections and transports by wrapping
+// them:
+//
+//   - WrapConn wraps a server connection (server.Config.Faults does this for
+//     every client)
+//   - WrapTransport wraps a jsonrpc.Transport, such as an STDIO upstream
+//   - helpers.MockTransport.SetFaults applies an injector to the mock
+//
+// Basic usage:
+//
+//	faults := chaos.Faults{
+//		Inbound:  chaos.New(chaos.Config{Drop: 0.05, Seed: 1}),
+//		Outbound: chaos.New(chaos.Config{Schedule: map[int]chaos.Fault{3: chaos.FaultDisconnect}}),
+//	}
+//	conn = chaos.WrapConn(conn, faults)
+//
+// A fixed Seed makes random faults reproducible.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrInjectedDisconnect is returned by operations on a connection the
+// injector disconnected
+var ErrInjectedDisconnect = errors.New("chaos: injected disconnect")
+
+// Fault is a kind of fault applied to a message
+type Fault int
+
+// Faults an Injector can apply
+const (
+	// FaultNone delivers the message unchanged
+	FaultNone Fault = iota
+	// FaultDrop loses the message
+	FaultDrop
+	// FaultDuplicate delivers the message twice
+	FaultDuplicate
+	// FaultReorder delivers the message after the next one
+	FaultReorder
+	// FaultCorrupt replaces some bytes of the message
+	FaultCorrupt
+	// FaultDelay delivers the message late
+	FaultDelay
+	// FaultDisconnect closes the connection instead of delivering
+	FaultDisconnect
+)
+
+// String returns the name of the fault
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "none"
+	case FaultDrop:
+		return "drop"
+	case FaultDuplicate:
+		return "duplicate"
+	case FaultReorder:
+		return "reorder"
+	case FaultCorrupt:
+		return "corrupt"
+	case FaultDelay:
+		return "delay"
+	case FaultDisconnect:
+		return "disconnect"
+	default:
+		return fmt.Sprintf("Fault(%d)", int(f))
+	}
+}
+
+// Config contains configuration for an Injector. Probabilities are per
+// message; at most one fault applies to a message.
+type Config struct {
+	Drop       float64
+	Duplicate  float64
+	Reorder    float64
+	Corrupt    float64
+	Delay      float64
+	Disconnect float64
+
+	// MaxDelay bounds delays, which are uniform up to it (defaults to
+	// 100ms)
+	MaxDelay time.Duration
+
+	// Schedule applies faults to messages by 1-based position, overriding
+	// the probabilities for those messages
+	Schedule map[int]Fault
+
+	// Seed seeds the random source; zero picks a random seed
+	Seed uint64
+}
+
+// Stats contains injector statistics
+type Stats struct {
+	Messages int64            `json:"messages"`
+	Injected map[string]int64 `json:"injected"`
+}
+
+// Injector decides the fault for each message. It is safe for concurrent
+// use; a nil Injector injects nothing.
+type Injector struct {
+	config Config
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	messages int64
+	injected map[Fault]int64
+}
+
+// New creates an Injector
+func New(config Config) *Injector {
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 100 * time.Millisecond
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &Injector{
+		config:   config,
+		rand:     rand.New(rand.NewPCG(seed, seed)),
+		injected: make(map[Fault]int64),
+	}
+}
+
+// Next returns the fault for the next message
+func (inj *Injector) Next() Fault {
+	if inj == nil {
+		return FaultNone
+	}
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+
+	inj.messages++
+	fault, scheduled := inj.config.Schedule[int(inj.messages)]
+	if !scheduled {
+		fault = inj.roll()
+	}
+	if fault != FaultNone {
+		inj.injected[fault]++
+	}
+	return fault
+}
+
+// roll picks a fault at random with the configured probabilities. The
+// caller holds inj.mu.
+func (inj *Injector) roll() Fault {
+	r := inj.rand.Float64()
+	for _, candidate := range []struct {
+		fault Fault
+		p     float64
+	}{
+		{FaultDisconnect, inj.config.Disconnect},
+		{FaultDrop, inj.config.Drop},
+		{FaultCorrupt, inj.config.Corrupt},
+		{FaultDuplicate, inj.config.Duplicate},
+		{FaultReorder, inj.config.Reorder},
+		{FaultDelay, inj.config.Delay},
+	} {
+		if r < candidate.p {
+			return candidate.fault
+		}
+		r -= candidate.p
+	}
+	return FaultNone
+}
+
+// Delay returns how long to hold back a delayed message
+func (inj *Injector) Delay() time.Duration {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	return time.Duration(inj.rand.Int64N(int64(inj.config.MaxDelay))) + 1
+}
+
+// CorruptBytes returns a copy of data with up to three bytes replaced.
+// Newlines are neither removed nor introduced, so framing survives.
+func (inj *Injector) CorruptBytes(data []byte) []byte {
+	corrupted := append([]byte(nil), data...)
+	if len(corrupted) == 0 {
+		return corrupted
+	}
+
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	for n := 1 + inj.rand.IntN(3); n > 0; n-- {
+		i := inj.rand.IntN(len(corrupted))
+		if corrupted[i] == '\n' {
+			continue
+		}
+		// Printable ASCII other than the original byte
+		b := byte(' ' + inj.rand.IntN('~'-' '))
+		if b >= corrupted[i] {
+			b++
+		}
+		corrupted[i] = b
+	}
+	return corrupted
+}
+
+// Stats returns injector statistics
+func (inj *Injector) Stats() Stats {
+	stats := Stats{Injected: make(map[string]int64)}
+	if inj == nil {
+		return stats
+	}
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	stats.Messages = inj.messages
+	for fault, n := range inj.injected {
+		stats.Injected[fault.String()] = n
+	}
+	return stats
+}
+
+// Faults pairs the injectors for both directions of a connection; either
+// may be nil
+type Faults struct {
+	// Inbound applies to messages received from the peer
+	Inbound *Injector
+
+	// Outbound applies to messages sent to the peer
+	Outbound *Injector
+}
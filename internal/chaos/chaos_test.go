@@ -0,0 +1,243 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// sliceConn reads queued messages and records written ones
+type sliceConn struct {
+	in     [][]byte
+	out    [][]byte
+	closed bool
+}
+
+func (c *sliceConn) ReadMessage() ([]byte, error) {
+	if c.closed || len(c.in) == 0 {
+		return nil, io.EOF
+	}
+	message := c.in[0]
+	c.in = c.in[1:]
+	return message, nil
+}
+
+func (c *sliceConn) WriteMessage(message []byte) error {
+	if c.closed {
+		return io.ErrClosedPipe
+	}
+	c.out = append(c.out, message)
+	return nil
+}
+
+func (c *sliceConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func messages(names ...string) [][]byte {
+	out := make([][]byte, len(names))
+	for i, name := range names {
+		out[i] = []byte(name)
+	}
+	return out
+}
+
+func readAll(t *testing.T, conn Conn) ([]string, error) {
+	t.Helper()
+	var got []string
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			return got, err
+		}
+		got = append(got, string(message))
+	}
+}
+
+func TestInjector_Schedule(t *testing.T) {
+	inj := New(Config{Schedule: map[int]Fault{2: FaultDrop, 4: FaultDuplicate}})
+
+	var got []Fault
+	for range 5 {
+		got = append(got, inj.Next())
+	}
+	assert.Equal(t, []Fault{FaultNone, FaultDrop, FaultNone, FaultDuplicate, FaultNone}, got)
+
+	stats := inj.Stats()
+	assert.Equal(t, int64(5), stats.Messages)
+	assert.Equal(t, map[string]int64{"drop": 1, "duplicate": 1}, stats.Injected)
+}
+
+func TestInjector_SeededProbabilities(t *testing.T) {
+	config := Config{Drop: 0.2, Duplicate: 0.1, Delay: 0.1, Seed: 42}
+	a, b := New(config), New(config)
+
+	counts := make(map[Fault]int)
+	for range 1000 {
+		fault := a.Next()
+		require.Equal(t, fault, b.Next(), "same seed, same faults")
+		counts[fault]++
+	}
+	assert.InDelta(t, 200, counts[FaultDrop], 50)
+	assert.InDelta(t, 100, counts[FaultDuplicate], 40)
+	assert.InDelta(t, 100, counts[FaultDelay], 40)
+	assert.Zero(t, counts[FaultCorrupt]+counts[FaultReorder]+counts[FaultDisconnect])
+}
+
+func TestInjector_Nil(t *testing.T) {
+	var inj *Injector
+	assert.Equal(t, FaultNone, inj.Next())
+	assert.Equal(t, int64(0), inj.Stats().Messages)
+}
+
+func TestInjector_CorruptBytes(t *testing.T) {
+	inj := New(Config{Seed: 1})
+	data := []byte(`{"jsonrpc":"2.0","method":"ping"}` + "\n")
+
+	corrupted := inj.CorruptBytes(data)
+	assert.NotEqual(t, data, corrupted)
+	assert.Len(t, corrupted, len(data))
+	assert.Equal(t, byte('\n'), corrupted[len(corrupted)-1])
+	assert.Equal(t, `{"jsonrpc":"2.0","method":"ping"}`+"\n", string(data), "input unchanged")
+}
+
+func TestWrapConn_NoFaults(t *testing.T) {
+	conn := &sliceConn{}
+	assert.Same(t, conn, WrapConn(conn, Faults{}))
+}
+
+func TestWrapConn_Inbound(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule map[int]Fault
+		want     []string
+	}{
+		{"drop", map[int]Fault{2: FaultDrop}, []string{"a", "c"}},
+		{"duplicate", map[int]Fault{2: FaultDuplicate}, []string{"a", "b", "b", "c"}},
+		{"reorder", map[int]Fault{1: FaultReorder}, []string{"b", "a", "c"}},
+		{"reorder last", map[int]Fault{3: FaultReorder}, []string{"a", "b", "c"}},
+		{"delay", map[int]Fault{1: FaultDelay}, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := WrapConn(&sliceConn{in: messages("a", "b", "c")}, Faults{
+				Inbound: New(Config{Schedule: tt.schedule, MaxDelay: time.Millisecond}),
+			})
+			got, err := readAll(t, conn)
+			assert.ErrorIs(t, err, io.EOF)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWrapConn_Corrupt(t *testing.T) {
+	conn := WrapConn(&sliceConn{in: messages("hello")}, Faults{
+		Inbound: New(Config{Corrupt: 1, Seed: 1}),
+	})
+	message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Len(t, message, 5)
+	assert.NotEqual(t, "hello", string(message))
+}
+
+func TestWrapConn_Disconnect(t *testing.T) {
+	raw := &sliceConn{in: messages("a", "b")}
+	conn := WrapConn(raw, Faults{
+		Inbound: New(Config{Schedule: map[int]Fault{2: FaultDisconnect}}),
+	})
+
+	got, err := readAll(t, conn)
+	assert.ErrorIs(t, err, ErrInjectedDisconnect)
+	assert.Equal(t, []string{"a"}, got)
+	assert.True(t, raw.closed)
+	assert.ErrorIs(t, conn.WriteMessage([]byte("x")), ErrInjectedDisconnect)
+}
+
+func TestWrapConn_Outbound(t *testing.T) {
+	raw := &sliceConn{}
+	conn := WrapConn(raw, Faults{
+		Outbound: New(Config{Schedule: map[int]Fault{1: FaultReorder, 3: FaultDrop, 4: FaultDuplicate}}),
+	})
+	for _, message := range messages("a", "b", "c", "d") {
+		require.NoError(t, conn.WriteMessage(message))
+	}
+	assert.Equal(t, messages("b", "a", "d", "d"), raw.out)
+}
+
+// chanTransport is a jsonrpc.Transport over a channel of messages
+type chanTransport struct {
+	in     chan jsonrpc.Message
+	out    []jsonrpc.Message
+	closed bool
+}
+
+func (t *chanTransport) Send(ctx context.Context, message jsonrpc.Message) error {
+	t.out = append(t.out, message)
+	return nil
+}
+
+func (t *chanTransport) Receive(ctx context.Context) (jsonrpc.Message, error) {
+	message, ok := <-t.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return message, nil
+}
+
+func (t *chanTransport) SendBatch(ctx context.Context, messages []jsonrpc.Message) error {
+	t.out = append(t.out, messages...)
+	return nil
+}
+
+func (t *chanTransport) ReceiveBatch(ctx context.Context) ([]jsonrpc.Message, error) {
+	message, err := t.Receive(ctx)
+	return []jsonrpc.Message{message}, err
+}
+
+func (t *chanTransport) Close() error {
+	t.closed = true
+	return nil
+}
+
+func (t *chanTransport) IsConnected() bool {
+	return !t.closed
+}
+
+func TestWrapTransport(t *testing.T) {
+	raw := &chanTransport{in: make(chan jsonrpc.Message, 3)}
+	for i := 1; i <= 3; i++ {
+		raw.in <- jsonrpc.NewRequest("ping", nil, i)
+	}
+	close(raw.in)
+
+	transport := WrapTransport(raw, Faults{
+		Inbound:  New(Config{Schedule: map[int]Fault{1: FaultDuplicate, 2: FaultDrop}}),
+		Outbound: New(Config{Schedule: map[int]Fault{2: FaultDisconnect}}),
+	})
+	ctx := context.Background()
+
+	var ids []any
+	for {
+		message, err := transport.Receive(ctx)
+		if err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+		ids = append(ids, message.(*jsonrpc.Request).ID)
+	}
+	assert.Equal(t, []any{1, 1, 3}, ids)
+
+	require.NoError(t, transport.Send(ctx, jsonrpc.NewNotification("a", nil)))
+	err := transport.SendBatch(ctx, []jsonrpc.Message{jsonrpc.NewNotification("b", nil)})
+	assert.ErrorIs(t, err, ErrInjectedDisconnect)
+	assert.Len(t, raw.out, 1)
+	assert.True(t, raw.closed)
+	assert.False(t, transport.IsConnected())
+}
@@ -0,0 +1,157 @@
+package chaos
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Conn carries framed messages; it has the method set of server.Conn
+type Conn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(message []byte) error
+	Close() error
+}
+
+// WrapConn returns conn with faults applied to the messages read from and
+// written to it. A reordered message is delivered after the next one, or
+// lost if none follows.
+func WrapConn(conn Conn, faults Faults) Conn {
+	if faults.Inbound == nil && faults.Outbound == nil {
+		return conn
+	}
+	return &faultyConn{conn: conn, faults: faults}
+}
+
+// faultyConn is a Conn with injected faults
+type faultyConn struct {
+	conn   Conn
+	faults Faults
+
+	rmu     sync.Mutex
+	pending [][]byte // read but not yet returned
+	held    []byte   // reordered read, returned after the next message
+
+	wmu       sync.Mutex
+	heldWrite []byte // reordered write, written after the next message
+
+	disconnected atomic.Bool
+}
+
+// ReadMessage returns the next message that survives the inbound faults
+func (c *faultyConn) ReadMessage() ([]byte, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	for {
+		if len(c.pending) > 0 {
+			message := c.pending[0]
+			c.pending = c.pending[1:]
+			return message, nil
+		}
+		if c.isDisconnected() {
+			return nil, ErrInjectedDisconnect
+		}
+
+		message, err := c.conn.ReadMessage()
+		if err != nil {
+			if c.held != nil {
+				message, c.held = c.held, nil
+				return message, nil
+			}
+			return nil, err
+		}
+
+		switch fault := c.faults.Inbound.Next(); fault {
+		case FaultDrop:
+			continue
+		case FaultDuplicate:
+			c.pending = append(c.pending, message, message)
+		case FaultReorder:
+			if c.held == nil {
+				c.held = message
+				continue
+			}
+			c.pending = append(c.pending, message)
+		case FaultCorrupt:
+			c.pending = append(c.pending, c.faults.Inbound.CorruptBytes(message))
+		case FaultDelay:
+			time.Sleep(c.faults.Inbound.Delay())
+			c.pending = append(c.pending, message)
+		case FaultDisconnect:
+			c.disconnect()
+			return nil, ErrInjectedDisconnect
+		default:
+			c.pending = append(c.pending, message)
+		}
+
+		// A held message follows the first one read after it
+		if c.held != nil {
+			c.pending = append(c.pending, c.held)
+			c.held = nil
+		}
+	}
+}
+
+// WriteMessage writes message subject to the outbound faults
+func (c *faultyConn) WriteMessage(message []byte) error {
+	if c.isDisconnected() {
+		return ErrInjectedDisconnect
+	}
+
+	fault := c.faults.Outbound.Next()
+	if fault == FaultDelay {
+		time.Sleep(c.faults.Outbound.Delay())
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	var out [][]byte
+	switch fault {
+	case FaultDrop:
+	case FaultDuplicate:
+		out = [][]byte{message, message}
+	case FaultReorder:
+		if c.heldWrite == nil {
+			c.heldWrite = append([]byte(nil), message...)
+			return nil
+		}
+		out = [][]byte{message}
+	case FaultCorrupt:
+		out = [][]byte{c.faults.Outbound.CorruptBytes(message)}
+	case FaultDisconnect:
+		c.disconnect()
+		return ErrInjectedDisconnect
+	default:
+		out = [][]byte{message}
+	}
+	if c.heldWrite != nil && len(out) > 0 {
+		out = append(out, c.heldWrite)
+		c.heldWrite = nil
+	}
+
+	for _, m := range out {
+		if err := c.conn.WriteMessage(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the wrapped connection
+func (c *faultyConn) Close() error {
+	return c.conn.Close()
+}
+
+// disconnect closes the wrapped connection abruptly
+func (c *faultyConn) disconnect() {
+	if c.disconnected.CompareAndSwap(false, true) {
+		c.conn.Close()
+	}
+}
+
+// isDisconnected reports whether the injector disconnected the connection
+func (c *faultyConn) isDisconnected() bool {
+	return c.disconnected.Load()
+}
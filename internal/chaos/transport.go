@@ -0,0 +1,194 @@
+package chaos
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// WrapTransport returns transport with faults applied to the messages sent
+// and received through it. Corrupted messages are re-parsed: one that no
+// longer parses is lost on send and fails Receive with a parse error.
+func WrapTransport(transport jsonrpc.Transport, faults Faults) jsonrpc.Transport {
+	if faults.Inbound == nil && faults.Outbound == nil {
+		return transport
+	}
+	return &faultyTransport{Transport: transport, faults: faults}
+}
+
+// faultyTransport is a jsonrpc.Transport with injected faults
+type faultyTransport struct {
+	jsonrpc.Transport
+	faults Faults
+
+	rmu     sync.Mutex
+	pending []jsonrpc.Message
+	held    jsonrpc.Message
+
+	smu     sync.Mutex
+	heldOut jsonrpc.Message
+
+	disconnected atomic.Bool
+}
+
+// Send sends message subject to the outbound faults
+func (t *faultyTransport) Send(ctx context.Context, message jsonrpc.Message) error {
+	if t.disconnected.Load() {
+		return ErrInjectedDisconnect
+	}
+
+	fault := t.faults.Outbound.Next()
+	if fault == FaultDelay {
+		select {
+		case <-time.After(t.faults.Outbound.Delay()):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	t.smu.Lock()
+	defer t.smu.Unlock()
+
+	var out []jsonrpc.Message
+	switch fault {
+	case FaultDrop:
+	case FaultDuplicate:
+		out = []jsonrpc.Message{message, message}
+	case FaultReorder:
+		if t.heldOut == nil {
+			t.heldOut = message
+			return nil
+		}
+		out = []jsonrpc.Message{message}
+	case FaultCorrupt:
+		if corrupted, ok := t.corrupt(t.faults.Outbound, message); ok {
+			out = []jsonrpc.Message{corrupted}
+		}
+	case FaultDisconnect:
+		t.disconnect()
+		return ErrInjectedDisconnect
+	default:
+		out = []jsonrpc.Message{message}
+	}
+	if t.heldOut != nil && len(out) > 0 {
+		out = append(out, t.heldOut)
+		t.heldOut = nil
+	}
+
+	for _, m := range out {
+		if err := t.Transport.Send(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendBatch sends messages one at a time so each is subject to faults
+func (t *faultyTransport) SendBatch(ctx context.Context, messages []jsonrpc.Message) error {
+	for _, message := range messages {
+		if err := t.Send(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Receive returns the next message that survives the inbound faults
+func (t *faultyTransport) Receive(ctx context.Context) (jsonrpc.Message, error) {
+	t.rmu.Lock()
+	defer t.rmu.Unlock()
+
+	for {
+		if len(t.pending) > 0 {
+			message := t.pending[0]
+			t.pending = t.pending[1:]
+			return message, nil
+		}
+		if t.disconnected.Load() {
+			return nil, ErrInjectedDisconnect
+		}
+
+		message, err := t.Transport.Receive(ctx)
+		if err != nil {
+			if t.held != nil {
+				message, t.held = t.held, nil
+				return message, nil
+			}
+			return nil, err
+		}
+
+		switch t.faults.Inbound.Next() {
+		case FaultDrop:
+			continue
+		case FaultDuplicate:
+			t.pending = append(t.pending, message, message)
+		case FaultReorder:
+			if t.held == nil {
+				t.held = message
+				continue
+			}
+			t.pending = append(t.pending, message)
+		case FaultCorrupt:
+			corrupted, ok := t.corrupt(t.faults.Inbound, message)
+			if !ok {
+				return nil, jsonrpc.NewParseError("chaos: corrupted message")
+			}
+			t.pending = append(t.pending, corrupted)
+		case FaultDelay:
+			select {
+			case <-time.After(t.faults.Inbound.Delay()):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			t.pending = append(t.pending, message)
+		case FaultDisconnect:
+			t.disconnect()
+			return nil, ErrInjectedDisconnect
+		default:
+			t.pending = append(t.pending, message)
+		}
+
+		if t.held != nil {
+			t.pending = append(t.pending, t.held)
+			t.held = nil
+		}
+	}
+}
+
+// ReceiveBatch receives one message so it is subject to faults
+func (t *faultyTransport) ReceiveBatch(ctx context.Context) ([]jsonrpc.Message, error) {
+	message, err := t.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []jsonrpc.Message{message}, nil
+}
+
+// IsConnected reports false once the injector disconnected the transport
+func (t *faultyTransport) IsConnected() bool {
+	return !t.disconnected.Load() && t.Transport.IsConnected()
+}
+
+// corrupt corrupts the encoding of message and parses it back, reporting
+// false when the result is no longer a valid message
+func (t *faultyTransport) corrupt(inj *Injector, message jsonrpc.Message) (jsonrpc.Message, bool) {
+	data, err := jsonrpc.Marshal(message)
+	if err != nil {
+		return nil, false
+	}
+	corrupted, err := jsonrpc.ParseMessage(inj.CorruptBytes(data))
+	if err != nil {
+		return nil, false
+	}
+	return corrupted, true
+}
+
+// disconnect closes the wrapped transport abruptly
+func (t *faultyTransport) disconnect() {
+	if t.disconnected.CompareAndSwap(false, true) {
+		t.Transport.Close()
+	}
+}
@@ -0,0 +1,491 @@
+// Package client calls other MCP servers. It is the production counterpart
+// of the mock client in internal/testing: a Client speaks MCP over any
+// mcp-go transport (stdio, SSE, streamable HTTP, in-process), passes every
+// request through a middleware chain (retries, tracing, authentication),
+// pages through list results and delivers server notifications to
+// subscribers.
+//
+// Basic usage:
+//
+//	c := client.New(t, client.Config{
+//		Name:       "my-app",
+//		Version:    "1.0.0",
+//		Middleware: []client.Middleware{client.Tracing("fs"), client.Retry(errors.DefaultRetryPolicy())},
+//	})
+//	if err := c.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	if _, err := c.Initialize(ctx); err != nil {
+//		return err
+//	}
+//	for tool, err := range c.Tools(ctx) {
+//		...
+//	}
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/wirecheck"
+)
+
+// ErrNotInitialized is returned by requests sent before Initialize
+var ErrNotInitialized = errors.New("client not initialized")
+
+// Transport carries messages to and from a server. Any mcp-go client
+// transport can be used.
+type Transport = transport.Interface
+
+// Config contains configuration for a Client
+type Config struct {
+	// Name and Version identify the client in the handshake
+	Name    string
+	Version string
+
+	// ProtocolVersion is proposed in the handshake (defaults to the latest
+	// version)
+	ProtocolVersion string
+
+	// Capabilities are advertised in the handshake
+	Capabilities mcp.ClientCapabilities
+
+	// Middleware wraps every request; the first is the outermost
+	Middleware []Middleware
+
+	// RequestIDs generates the IDs of requests, e.g. ids.NewUUIDv7
+	// (defaults to sequential integers)
+	RequestIDs ids.Generator
+
+	// VerifyWire checks the sequence numbers and checksums a server in
+	// debug mode stamps on its messages (see wirecheck). Requests whose
+	// response is corrupted fail and corrupted notifications are dropped.
+	VerifyWire bool
+
+	// OnWireError is called with every problem VerifyWire finds, including
+	// missing, repeated and unstamped messages. A response to a request
+	// abandoned before it arrived shows as missing.
+	OnWireError func(err error)
+}
+
+// Client is a connection to an MCP server
+type Client struct {
+	transport Transport
+	config    Config
+	invoke    Invoker
+	nextID    atomic.Int64
+
+	mu          sync.RWMutex
+	initialize  *mcp.InitializeResult
+	subscribers map[uint64]subscriber
+	nextSub     uint64
+
+	// wire tracks the sequence of stamped messages when VerifyWire is set
+	wire wirecheck.Sequence
+}
+
+// subscriber receives the notifications of one method, or all of them when
+// method is empty
+type subscriber struct {
+	method  string
+	handler func(mcp.JSONRPCNotification)
+}
+
+// New creates a client speaking over t. Call Start and then Initialize
+// before other requests.
+func New(t Transport, config Config) *Client {
+	if config.ProtocolVersion == "" {
+		config.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	}
+	c := &Client{
+		transport:   t,
+		config:      config,
+		subscribers: make(map[uint64]subscriber),
+	}
+
+	c.invoke = c.send
+	for i := len(config.Middleware) - 1; i >= 0; i-- {
+		c.invoke = config.Middleware[i](c.invoke)
+	}
+	return c
+}
+
+// Start starts the transport. A streaming transport such as SSE stays open
+// as long as ctx.
+func (c *Client) Start(ctx context.Context) error {
+	// Notifications received before the handler is set are lost
+	c.transport.SetNotificationHandler(c.dispatch)
+	return c.transport.Start(ctx)
+}
+
+// Close closes the transport
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+// Initialize performs the handshake and returns the server's response
+func (c *Client) Initialize(ctx context.Context) (*mcp.InitializeResult, error) {
+	params := mcp.InitializeParams{
+		ProtocolVersion: c.config.ProtocolVersion,
+		ClientInfo:      mcp.Implementation{Name: c.config.Name, Version: c.config.Version},
+		Capabilities:    c.config.Capabilities,
+	}
+	var result mcp.InitializeResult
+	if err := c.call(ctx, string(mcp.MethodInitialize), params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, fmt.Errorf("send initialized notification: %w", err)
+	}
+
+	c.mu.Lock()
+	c.initialize = &result
+	c.mu.Unlock()
+	return &result, nil
+}
+
+// InitializeResult returns the server's handshake response, or nil before
+// Initialize
+func (c *Client) InitializeResult() *mcp.InitializeResult {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.initialize
+}
+
+// Ping checks that the server responds
+func (c *Client) Ping(ctx context.Context) error {
+	return c.Call(ctx, string(mcp.MethodPing), nil, nil)
+}
+
+// ListToolsPage returns one page of the server's tools; an empty cursor
+// requests the first page
+func (c *Client) ListToolsPage(ctx context.Context, cursor mcp.Cursor) (*mcp.ListToolsResult, error) {
+	var result mcp.ListToolsResult
+	if err := c.Call(ctx, string(mcp.MethodToolsList), pageParams(cursor), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Tools iterates over the server's tools, requesting pages as needed. It
+// stops after yielding an error.
+func (c *Client) Tools(ctx context.Context) iter.Seq2[mcp.Tool, error] {
+	return paginate(ctx, func(cursor mcp.Cursor) ([]mcp.Tool, mcp.Cursor, error) {
+		page, err := c.ListToolsPage(ctx, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Tools, page.NextCursor, nil
+	})
+}
+
+// ListTools returns all of the server's tools
+func (c *Client) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	return collect(c.Tools(ctx))
+}
+
+// CallTool calls a tool. A tool that fails reports it in the result's
+// IsError; an error is returned when the call itself fails.
+func (c *Client) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, string(mcp.MethodToolsCall), request.Params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+// ListResourcesPage returns one page of the server's resources
+func (c *Client) ListResourcesPage(ctx context.Context, cursor mcp.Cursor) (*mcp.ListResourcesResult, error) {
+	var result mcp.ListResourcesResult
+	if err := c.Call(ctx, string(mcp.MethodResourcesList), pageParams(cursor), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Resources iterates over the server's resources, requesting pages as
+// needed. It stops after yielding an error.
+func (c *Client) Resources(ctx context.Context) iter.Seq2[mcp.Resource, error] {
+	return paginate(ctx, func(cursor mcp.Cursor) ([]mcp.Resource, mcp.Cursor, error) {
+		page, err := c.ListResourcesPage(ctx, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Resources, page.NextCursor, nil
+	})
+}
+
+// ReadResource reads a resource
+func (c *Client) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, string(mcp.MethodResourcesRead), map[string]any{"uri": uri}, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseReadResourceResult(&raw)
+}
+
+// ListPromptsPage returns one page of the server's prompts
+func (c *Client) ListPromptsPage(ctx context.Context, cursor mcp.Cursor) (*mcp.ListPromptsResult, error) {
+	var result mcp.ListPromptsResult
+	if err := c.Call(ctx, string(mcp.MethodPromptsList), pageParams(cursor), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Prompts iterates over the server's prompts, requesting pages as needed.
+// It stops after yielding an error.
+func (c *Client) Prompts(ctx context.Context) iter.Seq2[mcp.Prompt, error] {
+	return paginate(ctx, func(cursor mcp.Cursor) ([]mcp.Prompt, mcp.Cursor, error) {
+		page, err := c.ListPromptsPage(ctx, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Prompts, page.NextCursor, nil
+	})
+}
+
+// GetPrompt renders a prompt with arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	var raw json.RawMessage
+	params := map[string]any{"name": name, "arguments": arguments}
+	if err := c.Call(ctx, string(mcp.MethodPromptsGet), params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseGetPromptResult(&raw)
+}
+
+// Call sends a request through the middleware and decodes its result into
+// result, which may be nil. Requests other than initialize fail with
+// ErrNotInitialized before the handshake.
+func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	if c.InitializeResult() == nil {
+		return ErrNotInitialized
+	}
+	return c.call(ctx, method, params, result)
+}
+
+// call sends a request through the middleware
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	object, err := paramsObject(params)
+	if err != nil {
+		return fmt.Errorf("encode %s params: %w", method, err)
+	}
+
+	req := &jsonrpc.Request{Version: jsonrpc.Version, Method: method}
+	if object != nil {
+		req.Params = object
+	}
+	raw, err := c.invoke(ctx, req)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// send is the innermost Invoker: it sends req over the transport. Error
+// responses are returned as *jsonrpc.Error with their code and data.
+func (c *Client) send(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+	id := mcp.NewRequestId(c.nextID.Add(1))
+	if c.config.RequestIDs != nil {
+		id = mcp.NewRequestId(c.config.RequestIDs())
+	}
+	response, err := c.transport.SendRequest(ctx, transport.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Method:  req.Method,
+		Params:  req.Params,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, mcperrors.WrapError(err, mcperrors.ErrorCodeMCPConnectionLost, "send "+req.Method)
+	}
+	if c.config.VerifyWire {
+		payload := response.Result
+		if response.Error != nil {
+			payload = response.Error.Data
+		}
+		if err := c.verify(payload); err != nil {
+			return nil, fmt.Errorf("%s: %w", req.Method, err)
+		}
+	}
+	if response.Error != nil {
+		rpcErr := &jsonrpc.Error{Code: response.Error.Code, Message: response.Error.Message}
+		if len(response.Error.Data) > 0 {
+			json.Unmarshal(response.Error.Data, &rpcErr.Data)
+		}
+		return nil, rpcErr
+	}
+	return response.Result, nil
+}
+
+// Notify sends a notification. Notifications bypass the middleware.
+func (c *Client) Notify(ctx context.Context, method string, params any) error {
+	object, err := paramsObject(params)
+	if err != nil {
+		return fmt.Errorf("encode %s params: %w", method, err)
+	}
+	notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+	notification.Method = method
+	if object != nil {
+		notification.Params.AdditionalFields = object
+	}
+	return c.transport.SendNotification(ctx, notification)
+}
+
+// Subscribe calls handler with every notification of method received from
+// the server, or with every notification when method is empty. Handlers
+// run on the transport's reader and must not block. The returned function
+// removes the subscription.
+func (c *Client) Subscribe(method string, handler func(mcp.JSONRPCNotification)) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextSub
+	c.nextSub++
+	c.subscribers[id] = subscriber{method: method, handler: handler}
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// dispatch delivers a notification to its subscribers
+func (c *Client) dispatch(notification mcp.JSONRPCNotification) {
+	if c.config.VerifyWire {
+		params, err := json.Marshal(notification.Params)
+		if err == nil {
+			err = c.verify(params)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	c.mu.RLock()
+	var handlers []func(mcp.JSONRPCNotification)
+	for _, s := range c.subscribers {
+		if s.method == "" || s.method == notification.Method {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(notification)
+	}
+}
+
+// verify checks the stamp of a message payload, reporting problems to
+// OnWireError. It returns an error only for corrupted payloads.
+func (c *Client) verify(payload json.RawMessage) error {
+	report := func(err error) {
+		if c.config.OnWireError != nil {
+			c.config.OnWireError(err)
+		}
+	}
+	seq, err := wirecheck.Verify(payload)
+	switch {
+	case errors.Is(err, wirecheck.ErrNoStamp):
+		report(err)
+		return nil
+	case err != nil:
+		report(err)
+		return err
+	}
+	if err := c.wire.Observe(seq); err != nil {
+		report(err)
+	}
+	return nil
+}
+
+// paramsObject encodes params as a JSON object so middleware can add
+// `_meta` fields. Nil params stay nil.
+func paramsObject(params any) (map[string]any, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if object, ok := params.(map[string]any); ok {
+		return object, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// pageParams returns the params of a list request for cursor
+func pageParams(cursor mcp.Cursor) any {
+	if cursor == "" {
+		return nil
+	}
+	return map[string]any{"cursor": cursor}
+}
+
+// paginate yields the items of every page returned by next, starting with
+// an empty cursor and stopping after a page without a next cursor
+func paginate[T any](ctx context.Context, next func(cursor mcp.Cursor) ([]T, mcp.Cursor, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var cursor mcp.Cursor
+		for {
+			items, nextCursor, err := next(cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if nextCursor == "" || nextCursor == cursor {
+				return
+			}
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			cursor = nextCursor
+		}
+	}
+}
+
+// collect gathers the items of seq, stopping at the first error
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
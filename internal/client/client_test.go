@@ -0,0 +1,261 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/wirecheck"
+)
+
+// newTestServer returns a server with n echo tools, listed two per page
+func newTestServer(n int) *server.MCPServer {
+	s := server.NewMCPServer("test", "1.2.3",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithPaginationLimit(2),
+	)
+	for i := range n {
+		s.AddTool(mcp.NewTool(fmt.Sprintf("echo%d", i), mcp.WithString("message")),
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(request.GetString("message", "")), nil
+			})
+	}
+	s.AddResource(mcp.NewResource("test://readme", "readme"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "hello"}}, nil
+		})
+	return s
+}
+
+// startClient starts and initializes a client over t
+func startClient(t *testing.T, tr Transport, config Config) *Client {
+	t.Helper()
+	c := New(tr, config)
+	require.NoError(t, c.Start(context.Background()))
+	t.Cleanup(func() { c.Close() })
+	_, err := c.Initialize(context.Background())
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_TypedRequests(t *testing.T) {
+	c := New(transport.NewInProcessTransport(newTestServer(5)), Config{Name: "test-client", Version: "1.0.0"})
+	require.NoError(t, c.Start(context.Background()))
+	defer c.Close()
+	ctx := context.Background()
+
+	assert.ErrorIs(t, c.Ping(ctx), ErrNotInitialized)
+	assert.Nil(t, c.InitializeResult())
+
+	result, err := c.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "test", result.ServerInfo.Name)
+	assert.Equal(t, result, c.InitializeResult())
+	require.NoError(t, c.Ping(ctx))
+
+	// Five tools arrive over three pages
+	page, err := c.ListToolsPage(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, page.Tools, 2)
+	assert.NotEmpty(t, page.NextCursor)
+	tools, err := c.ListTools(ctx)
+	require.NoError(t, err)
+	require.Len(t, tools, 5)
+	assert.Equal(t, "echo4", tools[4].Name)
+
+	// Iteration stops when the caller does
+	var seen int
+	for tool, err := range c.Tools(ctx) {
+		require.NoError(t, err)
+		seen++
+		if tool.Name == "echo2" {
+			break
+		}
+	}
+	assert.Equal(t, 3, seen)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "echo1"
+	request.Params.Arguments = map[string]any{"message": "hi"}
+	called, err := c.CallTool(ctx, request)
+	require.NoError(t, err)
+	text, ok := mcp.AsTextContent(called.Content[0])
+	require.True(t, ok)
+	assert.Equal(t, "hi", text.Text)
+
+	read, err := c.ReadResource(ctx, "test://readme")
+	require.NoError(t, err)
+	contents, ok := read.Contents[0].(mcp.TextResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, "hello", contents.Text)
+
+	// Error responses keep their JSON-RPC code
+	request.Params.Name = "missing"
+	_, err = c.CallTool(ctx, request)
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, mcp.INVALID_PARAMS, rpcErr.Code)
+}
+
+func TestClient_SSESubscriptionsAndAuth(t *testing.T) {
+	mcpServer := newTestServer(1)
+
+	var mu sync.Mutex
+	var authorization []string
+	httpServer := httptest.NewUnstartedServer(nil)
+	sse := server.NewSSEServer(mcpServer, server.WithBaseURL("http://"+httpServer.Listener.Addr().String()))
+	httpServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			mu.Unlock()
+		}
+		sse.ServeHTTP(w, r)
+	})
+	httpServer.Start()
+	t.Cleanup(httpServer.Close)
+
+	tr, err := NewSSE(httpServer.URL+"/sse", map[string]string{"X-Static": "1"})
+	require.NoError(t, err)
+	c := startClient(t, tr, Config{
+		Name:    "test-client",
+		Version: "1.0.0",
+		Middleware: []Middleware{Auth(func(ctx context.Context) (string, error) {
+			return "secret", nil
+		})},
+	})
+
+	changed := make(chan string, 10)
+	unsubscribe := c.Subscribe(mcp.MethodNotificationToolsListChanged, func(n mcp.JSONRPCNotification) {
+		changed <- n.Method
+	})
+	all := make(chan string, 10)
+	c.Subscribe("", func(n mcp.JSONRPCNotification) { all <- n.Method })
+
+	mcpServer.AddTool(mcp.NewTool("late"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("late"), nil
+	})
+	for _, ch := range []chan string{changed, all} {
+		select {
+		case method := <-ch:
+			assert.Equal(t, mcp.MethodNotificationToolsListChanged, method)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for list_changed")
+		}
+	}
+
+	// Unsubscribed handlers are no longer called
+	unsubscribe()
+	mcpServer.DeleteTools("late")
+	select {
+	case <-all:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for list_changed")
+	}
+	assert.Empty(t, changed)
+
+	// Requests carry the token; the initialized notification bypasses the
+	// middleware
+	require.NoError(t, c.Ping(context.Background()))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, authorization, "Bearer secret")
+	assert.Contains(t, authorization, "")
+}
+
+// serveStamped answers the requests read from r with s as a server in
+// debug mode would, stamping responses and notifications. Before the
+// response to a tools/call it sends a notification; results containing
+// "corrupt" are altered after stamping.
+func serveStamped(s *server.MCPServer, r io.Reader, w io.Writer) {
+	var seq uint64
+	write := func(message any) {
+		data, _ := json.Marshal(message)
+		seq++
+		stamped, _ := wirecheck.StampMessage(data, seq)
+		stamped = bytes.Replace(stamped, []byte("corrupt"), []byte("CORRUPT"), 1)
+		w.Write(append(stamped, '\n'))
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		var request struct {
+			Method string `json:"method"`
+		}
+		json.Unmarshal(line, &request)
+		if request.Method == string(mcp.MethodToolsCall) {
+			write(map[string]any{
+				"jsonrpc": mcp.JSONRPC_VERSION,
+				"method":  "notifications/message",
+				"params":  map[string]any{"level": "info", "data": "calling"},
+			})
+		}
+		if response := s.HandleMessage(context.Background(), line); response != nil {
+			write(response)
+		}
+	}
+}
+
+func TestClient_VerifyWire(t *testing.T) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	go serveStamped(newTestServer(1), serverR, serverW)
+	t.Cleanup(func() { serverW.Close() })
+
+	var mu sync.Mutex
+	var wireErrors []error
+	c := startClient(t, NewIO(clientR, clientW), Config{
+		Name:       "test-client",
+		Version:    "1.0.0",
+		VerifyWire: true,
+		OnWireError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			wireErrors = append(wireErrors, err)
+		},
+	})
+	messages := make(chan string, 10)
+	c.Subscribe("notifications/message", func(n mcp.JSONRPCNotification) { messages <- n.Method })
+
+	ctx := context.Background()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "echo0"
+	request.Params.Arguments = map[string]any{"message": "fine"}
+	result, err := c.CallTool(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, "fine", result.Content[0].(mcp.TextContent).Text)
+	select {
+	case <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the notification")
+	}
+	mu.Lock()
+	assert.Empty(t, wireErrors)
+	mu.Unlock()
+
+	// A response altered on the way fails the request
+	request.Params.Arguments = map[string]any{"message": "corrupt"}
+	_, err = c.CallTool(ctx, request)
+	assert.ErrorIs(t, err, wirecheck.ErrChecksum)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, wireErrors, 1)
+	assert.ErrorIs(t, wireErrors[0], wirecheck.ErrChecksum)
+}
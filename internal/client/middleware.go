@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tracing"
+)
+
+// Invoker sends a request and returns its raw result. The request's params
+// are nil or a JSON object that middleware may modify.
+type Invoker func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error)
+
+// Middleware wraps an Invoker
+type Middleware func(next Invoker) Invoker
+
+// Retry repeats requests that fail with a retryable error according to
+// policy
+func Retry(policy mcperrors.RetryPolicy) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			var result json.RawMessage
+			err := mcperrors.Retry(ctx, policy, func() error {
+				var err error
+				result, err = next(ctx, req)
+				return err
+			})
+			return result, err
+		}
+	}
+}
+
+// IdempotencyKeys gives every tools/call request without one an
+// idempotency key from generate in _meta. Placed before Retry, it makes the
+// retries of a call carry the same key, so the server can deduplicate them.
+func IdempotencyKeys(generate ids.Generator) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			if req.Method != "tools/call" {
+				return next(ctx, req)
+			}
+			if req.Params == nil {
+				req.Params = map[string]any{}
+			}
+			params, ok := req.Params.(map[string]any)
+			if !ok {
+				return next(ctx, req)
+			}
+			meta, ok := params[tracing.MetaKey].(map[string]any)
+			if !ok {
+				meta = map[string]any{}
+				params[tracing.MetaKey] = meta
+			}
+			if _, ok := meta[ids.IdempotencyKeyMeta]; !ok {
+				meta[ids.IdempotencyKeyMeta] = generate()
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// Tracing records every request in a client span named after server and
+// the method, and propagates the span to the server in `_meta`
+func Tracing(server string) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			ctx, span := tracing.StartUpstreamSpan(ctx, server, req)
+			result, err := next(ctx, req)
+
+			var rpcErr *jsonrpc.Error
+			switch {
+			case errors.As(err, &rpcErr):
+				tracing.EndWithResponse(span, &jsonrpc.Response{ID: req.ID, Error: rpcErr})
+				return result, err
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+			return result, err
+		}
+	}
+}
+
+// TokenSource returns the credentials of a request
+type TokenSource func(ctx context.Context) (string, error)
+
+// Auth sends a bearer token from tokens in the Authorization header of
+// every request. The header reaches HTTP transports created by NewSSE and
+// NewStreamableHTTP; see Headers.
+func Auth(tokens TokenSource) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			token, err := tokens(ctx)
+			if err != nil {
+				return nil, mcperrors.WrapError(err, mcperrors.ErrorCodeMCPUnauthorized, "get credentials")
+			}
+			return next(WithHeaders(ctx, map[string]string{"Authorization": "Bearer " + token}), req)
+		}
+	}
+}
+
+// headersKey is the context key of request headers
+type headersKey struct{}
+
+// WithHeaders returns a context carrying HTTP headers for the requests sent
+// with it, in addition to those already in ctx
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := maps.Clone(Headers(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(headers))
+	}
+	maps.Copy(merged, headers)
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// Headers returns the HTTP headers carried by ctx. It can be passed to the
+// header function options of mcp-go HTTP transports.
+func Headers(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
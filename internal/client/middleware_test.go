@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tracing"
+)
+
+func TestRetry(t *testing.T) {
+	var attempts int
+	invoke := Retry(mcperrors.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond})(
+		func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, mcperrors.NewConnectionLostError("reset")
+			}
+			return json.RawMessage(`{}`), nil
+		})
+
+	result, err := invoke(context.Background(), &jsonrpc.Request{Method: "tools/list"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(result))
+	assert.Equal(t, 3, attempts)
+
+	// Errors from the server are not retried by default
+	attempts = 0
+	invoke = Retry(mcperrors.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond})(
+		func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			attempts++
+			return nil, jsonrpc.NewInvalidParamsError(nil)
+		})
+	_, err = invoke(context.Background(), &jsonrpc.Request{Method: "tools/call"})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	var keys []any
+	var attempts int
+	invoke := IdempotencyKeys(func() string { return "key-1" })(
+		Retry(mcperrors.RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond})(
+			func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+				params, _ := req.Params.(map[string]any)
+				meta, _ := params[tracing.MetaKey].(map[string]any)
+				keys = append(keys, meta[ids.IdempotencyKeyMeta])
+				if attempts++; attempts == 1 {
+					return nil, mcperrors.NewConnectionLostError("reset")
+				}
+				return json.RawMessage(`{}`), nil
+			}))
+
+	_, err := invoke(context.Background(), &jsonrpc.Request{Method: "tools/call", Params: map[string]any{"name": "put"}})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"key-1", "key-1"}, keys, "retries share the key")
+
+	// A key set by the caller is kept, and other methods get none
+	keys = nil
+	_, err = invoke(context.Background(), &jsonrpc.Request{Method: "tools/call", Params: map[string]any{
+		tracing.MetaKey: map[string]any{ids.IdempotencyKeyMeta: "mine"},
+	}})
+	require.NoError(t, err)
+	_, err = invoke(context.Background(), &jsonrpc.Request{Method: "tools/list"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"mine", nil}, keys)
+}
+
+func TestTracing(t *testing.T) {
+	var meta map[string]any
+	invoke := Tracing("upstream")(func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+		meta, _ = req.Params.(map[string]any)[tracing.MetaKey].(map[string]any)
+		return nil, errors.New("boom")
+	})
+
+	_, err := invoke(context.Background(), &jsonrpc.Request{Method: "ping", Params: map[string]any{}})
+	assert.EqualError(t, err, "boom")
+	assert.NotNil(t, meta, "Expected trace context in _meta")
+}
+
+func TestAuthAndHeaders(t *testing.T) {
+	ctx := WithHeaders(context.Background(), map[string]string{"X-Tenant": "a"})
+
+	var headers map[string]string
+	invoke := Auth(func(ctx context.Context) (string, error) { return "token", nil })(
+		func(ctx context.Context, req *jsonrpc.Request) (json.RawMessage, error) {
+			headers = headerFunc(map[string]string{"X-Static": "1", "X-Tenant": "default"})(ctx)
+			return nil, nil
+		})
+	_, err := invoke(ctx, &jsonrpc.Request{Method: "ping"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token", "X-Tenant": "a", "X-Static": "1"}, headers)
+	assert.Equal(t, map[string]string{"X-Tenant": "a"}, Headers(ctx))
+
+	invoke = Auth(func(ctx context.Context) (string, error) { return "", errors.New("expired") })(nil)
+	_, err = invoke(ctx, &jsonrpc.Request{Method: "ping"})
+	assert.Equal(t, mcperrors.ErrorCodeMCPUnauthorized, mcperrors.FindMCPError(err).Code)
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// NewIO returns a transport speaking newline-delimited JSON-RPC over r and
+// w, such as the pipes of a server process
+func NewIO(r io.Reader, w io.WriteCloser) Transport {
+	return transport.NewIO(r, w, io.NopCloser(strings.NewReader("")))
+}
+
+// NewSSE returns a transport connecting to an SSE server at url. Every
+// request carries headers and the headers of its context (see Auth).
+func NewSSE(url string, headers map[string]string) (Transport, error) {
+	return transport.NewSSE(url, transport.WithHeaderFunc(headerFunc(headers)))
+}
+
+// NewStreamableHTTP returns a transport connecting to a streamable HTTP
+// server at url. Every request carries headers and the headers of its
+// context (see Auth).
+func NewStreamableHTTP(url string, headers map[string]string) (Transport, error) {
+	return transport.NewStreamableHTTP(url, transport.WithHTTPHeaderFunc(headerFunc(headers)))
+}
+
+// headerFunc returns the headers of a request: static ones overridden by
+// those of its context
+func headerFunc(static map[string]string) transport.HTTPHeaderFunc {
+	return func(ctx context.Context) map[string]string {
+		headers := maps.Clone(static)
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		maps.Copy(headers, Headers(ctx))
+		return headers
+	}
+}
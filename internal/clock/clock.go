@@ -0,0 +1,78 @@
+// Package clock abstracts the passage of time so handshake timeouts, retry
+// backoff and job schedules can be driven deterministically in tests.
+//
+// Components take a Clock in their configuration and fall back to Real when
+// it is nil. Tests pass a Fake and move it forward explicitly:
+//
+//	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+//	sched := scheduler.New(scheduler.Config{Clock: fake})
+//	...
+//	fake.BlockUntil(ctx, 1) // the job is waiting for its next run
+//	fake.Advance(time.Minute)
+package clock
+
+import "time"
+
+// Clock tells the time and creates timers
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+
+	// Until returns the duration until t
+	Until(t time.Time) time.Duration
+
+	// NewTimer creates a timer that sends the time on its channel after d
+	NewTimer(d time.Duration) Timer
+
+	// AfterFunc calls f after d; the timer's channel is nil
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a single event, like time.Timer
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing, reporting whether it was pending
+	Stop() bool
+
+	// Reset changes the timer to fire after d, reporting whether it was
+	// pending
+	Reset(d time.Duration) bool
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+// Or returns c, or Real if c is nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// realClock delegates to the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration { return time.Until(t) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+// realTimer adapts time.Timer
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
@@ -0,0 +1,83 @@
+package clock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_TimersFireInOrder(t *testing.T) {
+	fake := NewFake(epoch)
+	var order []string
+	fake.AfterFunc(3*time.Second, func() { order = append(order, "3s") })
+	fake.AfterFunc(time.Second, func() { order = append(order, "1s") })
+	timer := fake.NewTimer(2 * time.Second)
+	assert.Equal(t, 3, fake.Timers())
+
+	fake.Advance(1500 * time.Millisecond)
+	assert.Equal(t, []string{"1s"}, order)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	fake.Advance(time.Hour)
+	assert.Equal(t, []string{"1s", "3s"}, order)
+	assert.Equal(t, epoch.Add(2*time.Second), <-timer.C())
+	assert.Equal(t, epoch.Add(time.Hour+1500*time.Millisecond), fake.Now())
+	assert.Zero(t, fake.Timers())
+}
+
+func TestFake_StopAndReset(t *testing.T) {
+	fake := NewFake(epoch)
+	var fired atomic.Int32
+	timer := fake.AfterFunc(time.Second, func() { fired.Add(1) })
+
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	fake.Advance(time.Minute)
+	assert.Zero(t, fired.Load())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Reset(2*time.Second))
+	fake.Advance(time.Second)
+	assert.Zero(t, fired.Load())
+	fake.Advance(time.Second)
+	assert.EqualValues(t, 1, fired.Load())
+}
+
+func TestFake_DueTimerFiresAtOnce(t *testing.T) {
+	fake := NewFake(epoch)
+	timer := fake.NewTimer(0)
+	assert.Equal(t, epoch, <-timer.C())
+
+	done := make(chan struct{})
+	fake.AfterFunc(-time.Second, func() { close(done) })
+	<-done
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	fake := NewFake(epoch)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		fake.NewTimer(time.Minute)
+	}()
+	require.NoError(t, fake.BlockUntil(context.Background(), 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, fake.BlockUntil(ctx, 2), context.DeadlineExceeded)
+}
+
+func TestOr(t *testing.T) {
+	assert.Equal(t, Real, Or(nil))
+	fake := NewFake(epoch)
+	assert.Equal(t, Clock(fake), Or(fake))
+}
@@ -0,0 +1,179 @@
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Timers fire during Advance
+// and Set, in deadline order; AfterFunc callbacks run before Advance
+// returns. Timers created with a non-positive duration fire at once.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+// NewFake creates a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Until returns the fake duration until t
+func (f *Fake) Until(t time.Time) time.Duration {
+	return t.Sub(f.Now())
+}
+
+// NewTimer creates a timer that fires once the clock reaches now+d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// AfterFunc creates a timer that calls fn once the clock reaches now+d
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	t := &fakeTimer{clock: f, fn: fn}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that come due
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to t, firing the timers that come due. The clock
+// never moves backwards.
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) == 0 || f.timers[0].deadline.After(t) {
+			if t.After(f.now) {
+				f.now = t
+			}
+			f.mu.Unlock()
+			return
+		}
+		timer := f.timers[0]
+		f.timers = f.timers[1:]
+		if timer.deadline.After(f.now) {
+			f.now = timer.deadline
+		}
+		now := f.now
+		f.mu.Unlock()
+
+		timer.fire(now)
+	}
+}
+
+// Timers returns the number of pending timers
+func (f *Fake) Timers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil waits until at least n timers are pending, so a test can
+// advance the clock knowing the code under test is waiting on it
+func (f *Fake) BlockUntil(ctx context.Context, n int) error {
+	for {
+		f.mu.Lock()
+		pending, changed := len(f.timers), f.changed
+		f.mu.Unlock()
+		if pending >= n {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// schedule adds or removes a timer, reporting whether it was pending; the
+// caller holds f.mu
+func (f *Fake) schedule(t *fakeTimer, deadline time.Time, add bool) bool {
+	pending := false
+	for i, other := range f.timers {
+		if other == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			pending = true
+			break
+		}
+	}
+	if add {
+		t.deadline = deadline
+		i := sort.Search(len(f.timers), func(i int) bool { return f.timers[i].deadline.After(deadline) })
+		f.timers = append(f.timers, nil)
+		copy(f.timers[i+1:], f.timers[i:])
+		f.timers[i] = t
+	}
+
+	close(f.changed)
+	f.changed = make(chan struct{})
+	return pending
+}
+
+// fakeTimer is a timer of a Fake clock
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	fn       func()
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.schedule(t, time.Time{}, false)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	now := t.clock.now
+	pending := t.clock.schedule(t, now.Add(d), d > 0)
+	t.clock.mu.Unlock()
+
+	// A timer that is already due fires at once; its callback runs in its
+	// own goroutine, as the caller may hold locks the callback takes
+	if d <= 0 {
+		if t.fn != nil {
+			go t.fn()
+		} else {
+			t.fire(now)
+		}
+	}
+	return pending
+}
+
+// fire delivers the timer's event
+func (t *fakeTimer) fire(now time.Time) {
+	if t.fn != nil {
+		t.fn()
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+}
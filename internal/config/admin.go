@@ -0,0 +1,44 @@
+package config
+
+import (
+	"context"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// AdminMethod is the router method served by AdminHandler
+const AdminMethod = "config/schema"
+
+// AdminParams are the parameters of the config/schema method
+type AdminParams struct {
+	// Format is "json" for the JSON Schema (the default) or "markdown"
+	// for the reference
+	Format string `json:"format,omitempty"`
+}
+
+// AdminResult is the result of the config/schema method
+type AdminResult struct {
+	Schema   map[string]any `json:"schema,omitempty"`
+	Markdown string         `json:"markdown,omitempty"`
+}
+
+// AdminHandler returns a router handler describing the config file, for
+// editors offering completion and validation. Register it under
+// AdminMethod.
+func AdminHandler() router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params AdminParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+
+		switch params.Format {
+		case "", "json":
+			return jsonrpc.NewResponse(AdminResult{Schema: Schema()}, req.ID)
+		case "markdown":
+			return jsonrpc.NewResponse(AdminResult{Markdown: Reference()}, req.ID)
+		}
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("format must be json or markdown"), req.ID)
+	})
+}
@@ -0,0 +1,336 @@
+// Package config loads the server configuration from layered sources.
+//
+// Values are merged in increasing order of precedence:
+//
+//  1. Defaults (see Default), adjusted by the profile selected with
+//     -profile, META_MCP_PROFILE or the file's profile key
+//  2. A YAML config file, selected with -config or CONFIG_FILE
+//  3. Environment variables
+//  4. Command-line flags
+//
+// Struct tags describe how each field is sourced and checked: `yaml` names
+// the file key, `env` the environment variable, `flag` the command-line flag
+// and `usage` its help text. `validate` lists comma separated rules:
+// required, min=<n>, oneof=<a b ...>, hostport and file.
+//
+// Fields tagged `secret:"true"` may hold a reference instead of a literal:
+// "env:NAME" reads the environment variable NAME and "file:/path" reads a
+// file with surrounding whitespace trimmed. Secrets are never printed.
+//
+// Basic usage:
+//
+//	cfg, err := config.Load(config.Options{Args: os.Args[1:]})
+//	if err != nil {
+//		fmt.Fprintln(os.Stderr, err)
+//		os.Exit(2)
+//	}
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Config is the complete server configuration
+type Config struct {
+	Version   int             `yaml:"version"`
+	Profile   string          `yaml:"profile" env:"META_MCP_PROFILE" flag:"profile" usage:"profile adjusting the defaults (dev, staging or prod)"`
+	Server    ServerConfig    `yaml:"server"`
+	Log       LogConfig       `yaml:"log"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Health    HealthConfig    `yaml:"health"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Quota     QuotaConfig     `yaml:"quota"`
+	Policy    PolicyConfig    `yaml:"policy"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Debug     DebugConfig     `yaml:"debug"`
+	Resources ResourcesConfig `yaml:"resources"`
+	Prompts   PromptsConfig   `yaml:"prompts"`
+	Fetch     FetchConfig     `yaml:"fetch"`
+	Plugins   PluginsConfig   `yaml:"plugins"`
+	Listen    ListenConfig    `yaml:"listen"`
+	Daemon    DaemonConfig    `yaml:"daemon"`
+}
+
+// ServerConfig identifies the server and controls the handshake
+type ServerConfig struct {
+	Name              string        `yaml:"name" env:"SERVER_NAME" flag:"name" usage:"server name reported to clients" validate:"required"`
+	Version           string        `yaml:"version" env:"SERVER_VERSION" flag:"version" usage:"server version reported to clients" validate:"required"`
+	HandshakeTimeout  time.Duration `yaml:"handshakeTimeout" env:"HANDSHAKE_TIMEOUT" flag:"handshake-timeout" usage:"time allowed to complete the handshake" validate:"min=1s"`
+	SupportedVersions []string      `yaml:"supportedVersions" env:"SUPPORTED_VERSIONS" flag:"supported-versions" usage:"comma separated protocol versions" validate:"required"`
+	Strict            bool          `yaml:"strict" env:"STRICT" flag:"strict" usage:"reject risky settings such as unsanitized logs or any browser origin"`
+	MemoryLimit       int64         `yaml:"memoryLimit" env:"MEMORY_LIMIT" flag:"memory-limit" usage:"shed work when in-flight messages approach this many bytes (0 disables)" validate:"min=0"`
+	// OrderedNotifications numbers notifications per connection and writes
+	// them before later responses
+	OrderedNotifications bool `yaml:"orderedNotifications" env:"ORDERED_NOTIFICATIONS" flag:"ordered-notifications" usage:"number notifications in _meta.seq and deliver them in order with responses"`
+	// LenientHandshake serves requests before notifications/initialized,
+	// for clients that never send it
+	LenientHandshake bool `yaml:"lenientHandshake" env:"LENIENT_HANDSHAKE" flag:"lenient-handshake" usage:"serve requests before the client sends notifications/initialized"`
+	// MaxRequestTimeout caps the deadline clients request with
+	// _meta.timeoutMs
+	MaxRequestTimeout time.Duration `yaml:"maxRequestTimeout" env:"MAX_REQUEST_TIMEOUT" flag:"max-request-timeout" usage:"upper bound on request deadlines asked for in _meta.timeoutMs (0 for none)" validate:"min=0s"`
+	// WriteTimeout closes connections whose client stops reading
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"WRITE_TIMEOUT" flag:"write-timeout" usage:"close a connection when a write to its client does not complete within this time" validate:"min=0s"`
+	// ReliableNotifications re-sends list_changed and resources/updated
+	// notifications until clients that opted in acknowledge them
+	ReliableNotifications bool `yaml:"reliableNotifications" env:"RELIABLE_NOTIFICATIONS" flag:"reliable-notifications" usage:"keep critical notifications until clients sending _meta.resumeToken acknowledge them, and re-send them on reconnect"`
+	// ResumeWindow is how long unacknowledged notifications wait for a
+	// client to reconnect
+	ResumeWindow time.Duration `yaml:"resumeWindow" env:"RESUME_WINDOW" flag:"resume-window" usage:"how long unacknowledged notifications are kept for a disconnected client" validate:"min=0s"`
+	// MaxResponseSize truncates tool and resource results, marking them
+	// in _meta.truncated
+	MaxResponseSize int `yaml:"maxResponseSize" env:"MAX_RESPONSE_SIZE" flag:"max-response-size" usage:"truncate tool and resource results above this many bytes of text and data (0 disables)" validate:"min=0"`
+	// ContinuationTTL is how long clients can read the rest of a
+	// truncated result
+	ContinuationTTL time.Duration `yaml:"continuationTTL" env:"CONTINUATION_TTL" flag:"continuation-ttl" usage:"keep the rest of truncated results readable at a continuation URI for this long (0 drops it)" validate:"min=0s"`
+	// ConnectionBandwidth and Bandwidth cap the bytes per second written
+	// to each client and to all of them
+	ConnectionBandwidth int64 `yaml:"connectionBandwidth" env:"CONNECTION_BANDWIDTH" flag:"connection-bandwidth" usage:"write at most this many bytes per second to each client (0 disables)" validate:"min=0"`
+	Bandwidth           int64 `yaml:"bandwidth" env:"BANDWIDTH" flag:"bandwidth" usage:"write at most this many bytes per second to all clients together (0 disables)" validate:"min=0"`
+	// DrainGrace is how long clients told of a shutdown may take to
+	// disconnect
+	DrainGrace time.Duration `yaml:"drainGrace" env:"DRAIN_GRACE" flag:"drain-grace" usage:"on shutdown, notify clients and wait this long for them to disconnect before closing their connections" validate:"min=0s"`
+	// ReceiptWindow is how long execution receipts of side-effecting tool
+	// calls can be looked up and re-submitted
+	ReceiptWindow time.Duration `yaml:"receiptWindow" env:"RECEIPT_WINDOW" flag:"receipt-window" usage:"return execution receipts for calls of tools not annotated read-only and keep them this long (0 disables)" validate:"min=0s"`
+}
+
+// LogConfig controls logging. Unset fields keep the values derived from
+// ENVIRONMENT by the logging package.
+type LogConfig struct {
+	Level    string `yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"minimum log level" validate:"oneof=debug info warn error"`
+	Sanitize bool   `yaml:"sanitize" env:"LOG_SANITIZE" flag:"log-sanitize" usage:"redact sensitive values in logs"`
+	Pretty   bool   `yaml:"pretty" env:"LOG_PRETTY" flag:"log-pretty" usage:"write human-readable logs"`
+	Debug    bool   `yaml:"debug" env:"DEBUG" flag:"debug" usage:"include stack traces and other debug details in logs and errors"`
+}
+
+// MetricsConfig controls the Prometheus endpoint
+type MetricsConfig struct {
+	Addr string `yaml:"addr" env:"METRICS_ADDR" flag:"metrics-addr" usage:"serve Prometheus metrics on this address" validate:"hostport"`
+	Path string `yaml:"path" env:"METRICS_PATH" flag:"metrics-path" usage:"path of the metrics endpoint"`
+}
+
+// HealthConfig controls the health probe endpoint
+type HealthConfig struct {
+	Addr string `yaml:"addr" env:"HEALTH_ADDR" flag:"health-addr" usage:"serve health probes on this address" validate:"hostport"`
+	// ErrorBudget fails readiness while too many requests fail
+	ErrorBudget int `yaml:"errorBudget" env:"HEALTH_ERROR_BUDGET" flag:"health-error-budget" usage:"report not ready while more than this percent of requests failed over the last minute (0 disables)" validate:"min=0"`
+}
+
+// TracingConfig controls where traces are exported
+type TracingConfig struct {
+	Endpoint string `yaml:"endpoint" env:"TRACING_ENDPOINT" flag:"tracing-endpoint" usage:"export traces over OTLP/HTTP to this URL, e.g. http://localhost:4318"`
+}
+
+// AdminConfig controls the admin/* methods
+type AdminConfig struct {
+	Identities []string `yaml:"identities" env:"ADMIN_IDENTITIES" flag:"admin-identities" usage:"comma separated identities allowed to call admin/* methods, such as local or an identity of listen.tokens (none may by default)"`
+}
+
+// QuotaConfig limits the tool calls of each identity over a rolling window
+type QuotaConfig struct {
+	Window   time.Duration `yaml:"window" env:"QUOTA_WINDOW" flag:"quota-window" usage:"rolling window of the tool call quotas (0 disables them)" validate:"min=0s"`
+	Calls    int64         `yaml:"calls" env:"QUOTA_CALLS" flag:"quota-calls" usage:"tool calls each identity may make per window (0 for no limit)" validate:"min=0"`
+	ExecTime time.Duration `yaml:"execTime" env:"QUOTA_EXEC_TIME" flag:"quota-exec-time" usage:"tool execution time each identity may use per window (0 for no limit)" validate:"min=0s"`
+}
+
+// PolicyConfig controls the argument checks of tool calls
+type PolicyConfig struct {
+	File string `yaml:"file" env:"POLICY_FILE" flag:"policy" usage:"YAML file of per-tool path, URL and length rules checked against tools/call arguments" validate:"file"`
+}
+
+// StorageConfig selects where state that outlives the process is kept
+type StorageConfig struct {
+	Path string `yaml:"path" env:"STORAGE_PATH" flag:"storage-path" usage:"keep quota usage and unacknowledged notifications in this BoltDB file across restarts (in memory by default)"`
+}
+
+// DebugConfig controls the token-protected debug endpoint
+type DebugConfig struct {
+	Enabled bool   `yaml:"enabled" env:"DEBUG_ENABLED" flag:"debug-enabled" usage:"allow serving debug endpoints"`
+	Addr    string `yaml:"addr" env:"DEBUG_ADDR" flag:"debug-addr" usage:"serve debug endpoints on this address" validate:"hostport"`
+	Token   string `yaml:"token" env:"DEBUG_TOKEN" secret:"true"`
+	// History keeps the last messages of each connection for diagnosing
+	// protocol failures
+	History int `yaml:"history" env:"DEBUG_HISTORY" flag:"debug-history" usage:"keep the last N messages of each connection, redacted, for admin/history and failure logs (0 disables)" validate:"min=0"`
+	// WireChecks stamps outbound messages for clients to detect transport
+	// corruption
+	WireChecks bool `yaml:"wireChecks" env:"DEBUG_WIRE_CHECKS" flag:"debug-wire-checks" usage:"add sequence numbers and checksums to _meta.wire of responses and notifications"`
+	// ProfileIdentities may profile single tool calls with _meta.profile
+	ProfileIdentities []string `yaml:"profileIdentities" env:"DEBUG_PROFILE_IDENTITIES" flag:"debug-profile-identities" usage:"comma separated identities, such as local or an identity of listen.tokens, allowed to profile tool calls with _meta.profile and read the profiles"`
+	// WireLog records sampled exchanges with clients for offline analysis
+	WireLog        string   `yaml:"wireLog" env:"DEBUG_WIRE_LOG" flag:"debug-wire-log" usage:"append requests from clients and their responses, redacted, as JSON lines to this file"`
+	WireLogSample  int      `yaml:"wireLogSample" env:"DEBUG_WIRE_LOG_SAMPLE" flag:"debug-wire-log-sample" usage:"percent of exchanges recorded in debug.wireLog" validate:"min=0"`
+	WireLogMethods []string `yaml:"wireLogMethods" env:"DEBUG_WIRE_LOG_METHODS" flag:"debug-wire-log-methods" usage:"comma separated methods recorded in debug.wireLog (all by default)"`
+}
+
+// ResourcesConfig controls the filesystem resource provider
+type ResourcesConfig struct {
+	Roots       []string `yaml:"roots" env:"RESOURCE_ROOTS" flag:"resource-roots" usage:"comma separated [name=]path resource roots"`
+	Watch       bool     `yaml:"watch" env:"RESOURCE_WATCH" flag:"resource-watch" usage:"notify clients when resource files change"`
+	Incremental bool     `yaml:"incremental" env:"RESOURCE_INCREMENTAL" flag:"resource-incremental" usage:"send subscribed clients the edits to changed text files instead of full re-reads"`
+
+	CacheEntries int           `yaml:"cacheEntries" env:"RESOURCE_CACHE_ENTRIES" flag:"resource-cache-entries" usage:"cache up to this many read resources (0 disables)" validate:"min=0"`
+	CacheSize    int64         `yaml:"cacheSize" env:"RESOURCE_CACHE_SIZE" flag:"resource-cache-size" usage:"cap cached resource contents at this many bytes" validate:"min=0"`
+	CacheMaxAge  time.Duration `yaml:"cacheMaxAge" env:"RESOURCE_CACHE_MAX_AGE" flag:"resource-cache-max-age" usage:"serve cached resources without revalidating them for this long" validate:"min=0s"`
+
+	VirtualFile string `yaml:"virtualFile" env:"VIRTUAL_RESOURCES_FILE" flag:"virtual-resources" usage:"YAML file declaring resources computed by tools" validate:"file"`
+}
+
+// PromptsConfig controls the prompt template engine
+type PromptsConfig struct {
+	File string `yaml:"file" env:"PROMPTS_FILE" flag:"prompts" usage:"YAML file of prompt templates" validate:"file"`
+}
+
+// FetchConfig controls the HTTP fetch provider
+type FetchConfig struct {
+	Domains []string `yaml:"domains" env:"FETCH_DOMAINS" flag:"fetch-domains" usage:"comma separated domains the fetch tool may read"`
+}
+
+// PluginsConfig controls tool plugins
+type PluginsConfig struct {
+	File string `yaml:"file" env:"PLUGINS_FILE" flag:"plugins" usage:"YAML file declaring tool plugins" validate:"file"`
+}
+
+// ListenConfig selects the transports clients connect over
+type ListenConfig struct {
+	Stdio     bool     `yaml:"stdio" env:"LISTEN_STDIO" flag:"stdio" usage:"serve the parent process over stdin/stdout"`
+	Socket    string   `yaml:"socket" env:"LISTEN_SOCKET" flag:"listen-socket" usage:"also serve clients on this unix socket path"`
+	SSE       string   `yaml:"sse" env:"LISTEN_SSE" flag:"listen-sse" usage:"also serve SSE clients on this address" validate:"hostport"`
+	WebSocket string   `yaml:"websocket" env:"LISTEN_WEBSOCKET" flag:"listen-websocket" usage:"also serve WebSocket clients on this address" validate:"hostport"`
+	Origins   []string `yaml:"origins" env:"LISTEN_ORIGINS" flag:"listen-origins" usage:"comma separated browser origins allowed over SSE and WebSocket"`
+	TLSCert   string   `yaml:"tlsCert" env:"LISTEN_TLS_CERT" flag:"listen-tls-cert" usage:"serve SSE and WebSocket over HTTPS with this PEM certificate"`
+	TLSKey    string   `yaml:"tlsKey" env:"LISTEN_TLS_KEY" flag:"listen-tls-key" usage:"PEM private key of listen.tlsCert"`
+	// ACME obtains the certificates of the HTTP transports automatically
+	ACMEDomains  []string `yaml:"acmeDomains" env:"LISTEN_ACME_DOMAINS" flag:"listen-acme-domains" usage:"comma separated host names to obtain HTTPS certificates for over ACME (TLS-ALPN on port 443) instead of listen.tlsCert"`
+	ACMECacheDir string   `yaml:"acmeCacheDir" env:"LISTEN_ACME_CACHE_DIR" flag:"listen-acme-cache-dir" usage:"keep ACME certificates in this directory across restarts"`
+	ACMEEmail    string   `yaml:"acmeEmail" env:"LISTEN_ACME_EMAIL" flag:"listen-acme-email" usage:"contact address registered with the ACME certificate authority"`
+	// Tokens authenticate SSE and WebSocket clients; the identity names
+	// them for quotas, receipts and privileged features
+	Tokens string `yaml:"tokens" env:"LISTEN_TOKENS" secret:"true" usage:"identity=token pairs, separated by commas or newlines, of the bearer tokens SSE and WebSocket clients must present"`
+}
+
+// Identities returns the identities of Tokens by bearer token
+func (l ListenConfig) Identities() (map[string]string, error) {
+	identities := make(map[string]string)
+	for _, entry := range strings.FieldsFunc(l.Tokens, func(r rune) bool { return r == ',' || r == '\n' }) {
+		identity, token, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || identity == "" || token == "" {
+			return nil, errors.New("entries must be identity=token")
+		}
+		if _, dup := identities[token]; dup {
+			return nil, fmt.Errorf("identity %s reuses the token of another identity", identity)
+		}
+		identities[token] = identity
+	}
+	return identities, nil
+}
+
+// DaemonConfig controls running as a long-lived service
+type DaemonConfig struct {
+	PIDFile string `yaml:"pidFile" env:"PID_FILE" flag:"pid-file" usage:"write the process ID to this file while running"`
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	return Config{
+		Version: CurrentVersion,
+		Server: ServerConfig{
+			Name:              "Meta-MCP Server",
+			Version:           "1.0.0",
+			HandshakeTimeout:  30 * time.Second,
+			SupportedVersions: []string{"2025-03-26", "2024-11-05", "1.0", "0.1.0"},
+			MaxRequestTimeout: 5 * time.Minute,
+			WriteTimeout:      30 * time.Second,
+			ResumeWindow:      10 * time.Minute,
+			ContinuationTTL:   5 * time.Minute,
+			ReceiptWindow:     time.Hour,
+		},
+		Resources: ResourcesConfig{CacheEntries: 256, CacheSize: 16 << 20},
+		Log:       LogConfig{Level: "info", Sanitize: true},
+		Debug:     DebugConfig{Enabled: true, WireLogSample: 100},
+		Listen:    ListenConfig{Stdio: true},
+	}
+}
+
+// validate checks rules spanning several fields
+func (c *Config) validate() []FieldError {
+	var errs []FieldError
+	if _, ok := profiles[c.Profile]; c.Profile != "" && !ok {
+		errs = append(errs, FieldError{
+			Path:    "profile",
+			Message: fmt.Sprintf("unknown profile %q: use %s", c.Profile, strings.Join(ProfileNames(), ", ")),
+		})
+	}
+	if c.Debug.Enabled && c.Debug.Addr != "" && c.Debug.Token == "" {
+		errs = append(errs, FieldError{
+			Path:    "debug.token",
+			Message: "is required when debug.addr is set; set DEBUG_TOKEN or debug.token (e.g. \"file:/run/secrets/debug-token\")",
+		})
+	}
+	if l := c.Listen; !l.Stdio && l.Socket == "" && l.SSE == "" && l.WebSocket == "" {
+		errs = append(errs, FieldError{
+			Path:    "listen",
+			Message: "no transport enabled; keep listen.stdio or set listen.socket, listen.sse or listen.websocket",
+		})
+	}
+	if _, err := c.Listen.Identities(); err != nil {
+		errs = append(errs, FieldError{Path: "listen.tokens", Message: err.Error()})
+	}
+	if endpoint := c.Tracing.Endpoint; endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{Path: "tracing.endpoint", Message: fmt.Sprintf("%q is not an http or https URL", endpoint)})
+		}
+	}
+	if d := c.Debug; d.WireLog != "" && (d.WireLogSample < 1 || d.WireLogSample > 100) {
+		errs = append(errs, FieldError{Path: "debug.wireLogSample", Message: fmt.Sprintf("%d is not a percentage from 1 to 100", d.WireLogSample)})
+	}
+	if c.Health.ErrorBudget > 100 {
+		errs = append(errs, FieldError{Path: "health.errorBudget", Message: fmt.Sprintf("%d is not a percentage", c.Health.ErrorBudget)})
+	}
+	if q := c.Quota; (q.Window > 0) != (q.Calls > 0 || q.ExecTime > 0) {
+		errs = append(errs, FieldError{Path: "quota.window", Message: "quota.window and quota.calls or quota.execTime must be set together"})
+	}
+	if l := c.Listen; (l.TLSCert == "") != (l.TLSKey == "") {
+		errs = append(errs, FieldError{Path: "listen.tlsKey", Message: "listen.tlsCert and listen.tlsKey must be set together"})
+	}
+	if l := c.Listen; len(l.ACMEDomains) > 0 && l.TLSCert != "" {
+		errs = append(errs, FieldError{Path: "listen.acmeDomains", Message: "cannot be combined with listen.tlsCert"})
+	}
+	if c.Server.Strict {
+		if !c.Log.Sanitize {
+			errs = append(errs, FieldError{Path: "log.sanitize", Message: "must be enabled in strict mode"})
+		}
+		for _, origin := range c.Listen.Origins {
+			if origin == "*" {
+				errs = append(errs, FieldError{Path: "listen.origins", Message: "must list explicit origins in strict mode, not \"*\""})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// Print writes every field as "path = value", noting where non-default
+// values came from. Secrets are masked.
+func Print(w io.Writer, cfg *Config, sources Sources) {
+	for _, f := range collectFields(cfg) {
+		value := fmt.Sprint(f.value.Interface())
+		if f.value.Kind() == reflect.Slice {
+			value = strings.Join(f.value.Interface().([]string), ",")
+		}
+		if f.secret && value != "" {
+			value = "********"
+		}
+		if source, ok := sources[f.path]; ok {
+			fmt.Fprintf(w, "%s = %s (%s)\n", f.path, value, source)
+		} else {
+			fmt.Fprintf(w, "%s = %s\n", f.path, value)
+		}
+	}
+}
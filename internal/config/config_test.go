@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envMap returns a LookupEnv backed by a map
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, sources, err := Load(Options{LookupEnv: envMap(nil)})
+	require.NoError(t, err)
+	assert.Equal(t, Default(), *cfg)
+	assert.Empty(t, sources)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+server:
+  name: From File
+  handshakeTimeout: 10s
+metrics:
+  addr: ":9000"
+fetch:
+  domains: [example.com]
+`)
+
+	cfg, sources, err := Load(Options{
+		Args: []string{"-config", file, "-metrics-addr", ":9200", "-resource-watch"},
+		LookupEnv: envMap(map[string]string{
+			"HANDSHAKE_TIMEOUT": "15s",
+			"METRICS_ADDR":      ":9100",
+			"FETCH_DOMAINS":     "a.com, b.com",
+		}),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "From File", cfg.Server.Name)
+	assert.Equal(t, "1.0.0", cfg.Server.Version)
+	assert.Equal(t, 15*time.Second, cfg.Server.HandshakeTimeout)
+	assert.Equal(t, ":9200", cfg.Metrics.Addr)
+	assert.Equal(t, []string{"a.com", "b.com"}, cfg.Fetch.Domains)
+	assert.True(t, cfg.Resources.Watch)
+
+	assert.Equal(t, "file "+file, sources["server.name"])
+	assert.Equal(t, "env HANDSHAKE_TIMEOUT", sources["server.handshakeTimeout"])
+	assert.Equal(t, "flag -metrics-addr", sources["metrics.addr"])
+	assert.NotContains(t, sources, "server.version")
+}
+
+func TestLoad_FileFromEnv(t *testing.T) {
+	file := writeFile(t, "config.yaml", "health:\n  addr: \":8081\"\n")
+	cfg, _, err := Load(Options{LookupEnv: envMap(map[string]string{FileEnv: file})})
+	require.NoError(t, err)
+	assert.Equal(t, ":8081", cfg.Health.Addr)
+}
+
+func TestLoad_Secrets(t *testing.T) {
+	tokenFile := writeFile(t, "token", "s3cret\n")
+	env := map[string]string{"DEBUG_ADDR": "127.0.0.1:6060", "DEBUG_TOKEN": "file:" + tokenFile}
+
+	cfg, _, err := Load(Options{LookupEnv: envMap(env)})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.Debug.Token)
+
+	env["DEBUG_TOKEN"] = "env:OPS_TOKEN"
+	env["OPS_TOKEN"] = "from-env"
+	cfg, _, err = Load(Options{LookupEnv: envMap(env)})
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Debug.Token)
+
+	var buf bytes.Buffer
+	Print(&buf, cfg, nil)
+	assert.Contains(t, buf.String(), "debug.token = ********")
+	assert.NotContains(t, buf.String(), "from-env")
+
+	delete(env, "OPS_TOKEN")
+	_, _, err = Load(Options{LookupEnv: envMap(env)})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "debug.token", verr.Errors[0].Path)
+	assert.Contains(t, verr.Errors[0].Message, "OPS_TOKEN")
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	file := writeFile(t, "config.yaml", "server:\n  name: \"\"\n")
+
+	_, _, err := Load(Options{
+		Args: []string{"-config", file, "-health-addr", "8080", "-prompts", "/does/not/exist.yaml", "-stdio=false"},
+		LookupEnv: envMap(map[string]string{
+			"HANDSHAKE_TIMEOUT":     "10ms",
+			"MEMORY_LIMIT":          "-1",
+			"DEBUG_ADDR":            ":6060",
+			"LISTEN_TLS_CERT":       "server.crt",
+			"LISTEN_ACME_DOMAINS":   "mcp.example.com",
+			"TRACING_ENDPOINT":      "localhost:4318",
+			"QUOTA_CALLS":           "100",
+			"HEALTH_ERROR_BUDGET":   "150",
+			"DEBUG_WIRE_LOG":        "wire.log",
+			"DEBUG_WIRE_LOG_SAMPLE": "0",
+		}),
+	})
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	paths := make(map[string]FieldError)
+	for _, e := range verr.Errors {
+		paths[e.Path] = e
+	}
+	assert.Equal(t, "file "+file, paths["server.name"].Source)
+	assert.Contains(t, paths["server.handshakeTimeout"].Message, "at least 1s")
+	assert.Contains(t, paths["server.memoryLimit"].Message, "at least 0, got -1")
+	assert.Contains(t, paths["health.addr"].Message, "host:port")
+	assert.Equal(t, "flag -health-addr", paths["health.addr"].Source)
+	assert.Contains(t, paths["prompts.file"].Message, "cannot read")
+	assert.Contains(t, paths, "debug.token")
+	assert.Contains(t, paths, "listen")
+	assert.Contains(t, paths, "listen.tlsKey")
+	assert.Contains(t, paths["listen.acmeDomains"].Message, "listen.tlsCert")
+	assert.Contains(t, paths["tracing.endpoint"].Message, "not an http or https URL")
+	assert.Contains(t, paths, "quota.window")
+	assert.Contains(t, paths["health.errorBudget"].Message, "not a percentage")
+	assert.Contains(t, paths["debug.wireLogSample"].Message, "not a percentage")
+	assert.Contains(t, err.Error(), "invalid configuration:\n  - ")
+
+	_, _, err = Load(Options{LookupEnv: envMap(map[string]string{"RESOURCE_WATCH": "maybe"})})
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "env RESOURCE_WATCH", verr.Errors[0].Source)
+}
+
+func TestLoad_ExtraFlags(t *testing.T) {
+	var format string
+	cfg, _, err := Load(Options{
+		Args:      []string{"-format", "json", "-name", "Custom"},
+		LookupEnv: envMap(nil),
+		Flags:     func(fs *flag.FlagSet) { fs.StringVar(&format, "format", "table", "output format") },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "json", format)
+	assert.Equal(t, "Custom", cfg.Server.Name)
+
+	// Command defaults sit below every other source
+	cfg, sources, err := Load(Options{
+		Args:      []string{"-listen-socket", "/tmp/mcp.sock"},
+		LookupEnv: envMap(nil),
+		Defaults:  func(cfg *Config) { cfg.Listen.Stdio = false },
+	})
+	require.NoError(t, err)
+	assert.False(t, cfg.Listen.Stdio)
+	assert.NotContains(t, sources, "listen.stdio")
+}
+
+func TestLoad_Profile(t *testing.T) {
+	cfg, sources, err := Load(Options{
+		Args:      []string{"-profile", "dev"},
+		LookupEnv: envMap(map[string]string{ProfileEnv: "prod"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, LogConfig{Level: "debug", Pretty: true, Debug: true}, cfg.Log)
+	assert.Equal(t, "profile dev", sources["log.level"])
+	assert.Equal(t, "flag -profile", sources["profile"])
+
+	// A profile in the file sets defaults the environment still overrides
+	file := writeFile(t, "config.yaml", "profile: prod\nlog:\n  level: warn\n")
+	cfg, sources, err = Load(Options{
+		Args:      []string{"-config", file},
+		LookupEnv: envMap(map[string]string{"DEBUG_ADDR": ":6060"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.False(t, cfg.Debug.Enabled)
+	assert.True(t, cfg.Server.Strict)
+	assert.Equal(t, "file "+file, sources["log.level"])
+	assert.Equal(t, "profile prod", sources["debug.enabled"])
+
+	_, _, err = Load(Options{
+		Args:      []string{"-log-sanitize=false", "-listen-origins", "*"},
+		LookupEnv: envMap(map[string]string{ProfileEnv: "staging"}),
+	})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 2)
+	assert.Equal(t, "log.sanitize", verr.Errors[0].Path)
+	assert.Equal(t, "listen.origins", verr.Errors[1].Path)
+
+	_, _, err = Load(Options{LookupEnv: envMap(map[string]string{ProfileEnv: "qa"})})
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "env META_MCP_PROFILE", verr.Errors[0].Source)
+	assert.Contains(t, verr.Errors[0].Message, "dev, prod, staging")
+}
+
+func TestLoad_BadInput(t *testing.T) {
+	file := writeFile(t, "config.yaml", "server:\n  nmae: typo\n")
+	_, _, err := Load(Options{Args: []string{"-config", file}, LookupEnv: envMap(nil)})
+	assert.ErrorContains(t, err, "nmae")
+
+	_, _, err = Load(Options{Args: []string{"-config", "/does/not/exist.yaml"}, LookupEnv: envMap(nil)})
+	assert.Error(t, err)
+
+	_, _, err = Load(Options{Args: []string{"-bogus"}, LookupEnv: envMap(nil)})
+	assert.ErrorIs(t, err, ErrUsage)
+
+	_, _, err = Load(Options{Args: []string{"-h"}, LookupEnv: envMap(nil)})
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+	assert.True(t, errors.Is(err, ErrUsage))
+
+	_, _, err = Load(Options{Args: []string{"serve"}, LookupEnv: envMap(nil)})
+	assert.ErrorContains(t, err, "unexpected arguments")
+}
+
+func TestMigrate(t *testing.T) {
+	v1 := "# resource roots\nresources:\n  roots: docs=/srv/docs, /tmp # shared\n"
+	data, version, warnings, err := Migrate([]byte(v1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "resources.roots", warnings[0].Path)
+	assert.Equal(t, "# resource roots\nversion: 2\nresources:\n  roots: # shared\n    - docs=/srv/docs\n    - /tmp\n", string(data))
+
+	current, version, warnings, err := Migrate(data)
+	require.NoError(t, err)
+	assert.Equal(t, CurrentVersion, version)
+	assert.Empty(t, warnings)
+	assert.Equal(t, data, current)
+
+	_, _, _, err = Migrate([]byte("version: 99\n"))
+	assert.ErrorContains(t, err, "newer")
+	_, _, _, err = Migrate([]byte("version: x\n"))
+	assert.ErrorContains(t, err, "invalid version")
+
+	file := writeFile(t, "config.yaml", v1)
+	var warned []FieldError
+	cfg, sources, err := Load(Options{
+		Args:      []string{"-config", file},
+		LookupEnv: envMap(nil),
+		Warn:      func(w FieldError) { warned = append(warned, w) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs=/srv/docs", "/tmp"}, cfg.Resources.Roots)
+	assert.Equal(t, "file "+file, sources["resources.roots"])
+	require.Len(t, warned, 1)
+	assert.Contains(t, warned[0].Error(), "version 1")
+}
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv selects the config file when -config is not given
+const FileEnv = "CONFIG_FILE"
+
+// ErrUsage wraps command-line parse errors, which the flag set has already
+// reported to FlagOutput
+var ErrUsage = errors.New("invalid usage")
+
+// Options controls where Load reads configuration from
+type Options struct {
+	// File is the config file (overridden by -config and CONFIG_FILE when
+	// empty)
+	File string
+
+	// Args are the command-line arguments, without the program name
+	Args []string
+
+	// LookupEnv reads environment variables (defaults to os.LookupEnv)
+	LookupEnv func(key string) (string, bool)
+
+	// FlagOutput receives flag usage and parse errors (defaults to
+	// io.Discard)
+	FlagOutput io.Writer
+
+	// Name is the flag set name shown in usage (defaults to
+	// "meta-mcp-server")
+	Name string
+
+	// Flags registers additional command-specific flags
+	Flags func(fs *flag.FlagSet)
+
+	// Defaults adjusts the built-in defaults for a command
+	Defaults func(cfg *Config)
+
+	// Warn receives warnings about deprecated keys in an older config file,
+	// which is migrated in memory (optional)
+	Warn func(FieldError)
+}
+
+// Sources records where each configured field was last set, keyed by its
+// dotted YAML path
+type Sources map[string]string
+
+// Load builds the configuration from every source and validates it. A
+// validation failure is reported as a *ValidationError.
+func Load(opts Options) (*Config, Sources, error) {
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+	if opts.FlagOutput == nil {
+		opts.FlagOutput = io.Discard
+	}
+
+	cfg := Default()
+	if opts.Defaults != nil {
+		opts.Defaults(&cfg)
+	}
+	sources := make(Sources)
+	fields := collectFields(&cfg)
+
+	// Parse flags first to find -config; their values are applied last
+	file, flagValues, err := parseFlags(fields, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file == "" {
+		file = opts.File
+	}
+	if file == "" {
+		file, _ = opts.LookupEnv(FileEnv)
+	}
+
+	// The profile adjusts the defaults, so it applies before any source
+	applyProfile(&cfg, findProfile(file, flagValues, opts.LookupEnv), fields, sources)
+
+	if file != "" {
+		keys, err := loadFile(&cfg, file, opts.Warn)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range fields {
+			if keys[f.path] {
+				sources[f.path] = "file " + file
+			}
+		}
+	}
+
+	var errs []FieldError
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if raw, ok := opts.LookupEnv(f.env); ok {
+			if err := setValue(f.value, raw); err != nil {
+				errs = append(errs, FieldError{Path: f.path, Source: "env " + f.env, Message: err.Error()})
+				continue
+			}
+			sources[f.path] = "env " + f.env
+		}
+	}
+
+	for _, fv := range flagValues {
+		if err := setValue(fv.field.value, fv.raw); err != nil {
+			errs = append(errs, FieldError{Path: fv.field.path, Source: "flag -" + fv.field.flag, Message: err.Error()})
+			continue
+		}
+		sources[fv.field.path] = "flag -" + fv.field.flag
+	}
+
+	for _, f := range fields {
+		if !f.secret {
+			continue
+		}
+		resolved, err := ResolveSecret(f.value.String(), opts.LookupEnv)
+		if err != nil {
+			errs = append(errs, FieldError{Path: f.path, Source: sources[f.path], Message: err.Error()})
+			continue
+		}
+		f.value.SetString(resolved)
+	}
+
+	if len(errs) == 0 {
+		errs = validate(&cfg, fields, sources)
+	}
+	if len(errs) > 0 {
+		return nil, nil, &ValidationError{Errors: errs}
+	}
+	return &cfg, sources, nil
+}
+
+// field is a leaf configuration field
+type field struct {
+	path     string
+	value    reflect.Value
+	env      string
+	flag     string
+	usage    string
+	validate string
+	secret   bool
+}
+
+// durationType is the reflect type of time.Duration
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// collectFields walks cfg and returns its leaf fields
+func collectFields(cfg *Config) []field {
+	var fields []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			fv := v.Field(i)
+			if sf.Type.Kind() == reflect.Struct {
+				walk(fv, path)
+				continue
+			}
+			fields = append(fields, field{
+				path:     path,
+				value:    fv,
+				env:      sf.Tag.Get("env"),
+				flag:     sf.Tag.Get("flag"),
+				usage:    sf.Tag.Get("usage"),
+				validate: sf.Tag.Get("validate"),
+				secret:   sf.Tag.Get("secret") == "true",
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return fields
+}
+
+// flagValue is a flag given on the command line
+type flagValue struct {
+	field field
+	raw   string
+}
+
+// parseFlags parses args, returning the -config value and the other flags
+// in the order given
+func parseFlags(fields []field, opts Options) (string, []flagValue, error) {
+	name := opts.Name
+	if name == "" {
+		name = "meta-mcp-server"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(opts.FlagOutput)
+	if opts.Flags != nil {
+		opts.Flags(fs)
+	}
+
+	var file string
+	var values []flagValue
+	fs.StringVar(&file, "config", "", "YAML config file (or "+FileEnv+")")
+	for _, f := range fields {
+		if f.flag == "" {
+			continue
+		}
+		f := f
+		usage := f.usage
+		if f.env != "" {
+			usage += " (" + f.env + ")"
+		}
+		record := func(raw string) error {
+			values = append(values, flagValue{field: f, raw: raw})
+			return nil
+		}
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(f.flag, usage, record)
+		} else {
+			fs.Func(f.flag, usage, record)
+		}
+	}
+
+	if err := fs.Parse(opts.Args); err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return "", nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return file, values, nil
+}
+
+// loadFile decodes a YAML file over cfg, migrating older versions and
+// rejecting unknown keys, and returns the dotted paths it set
+func loadFile(cfg *Config, path string, warn func(FieldError)) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	data, version, warnings, err := Migrate(data)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	if warn != nil {
+		for _, w := range warnings {
+			w.Source = fmt.Sprintf("file %s, version %d", path, version)
+			warn(w)
+		}
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	keys := make(map[string]bool)
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err == nil && len(node.Content) > 0 {
+		recordKeys(node.Content[0], "", keys)
+	}
+	return keys, nil
+}
+
+// recordKeys adds the dotted paths of the leaves of node to keys
+func recordKeys(node *yaml.Node, prefix string, keys map[string]bool) {
+	if node.Kind != yaml.MappingNode {
+		keys[prefix] = true
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		path := node.Content[i].Value
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		recordKeys(node.Content[i+1], path, keys)
+	}
+}
+
+// setValue parses raw into v according to its type
+func setValue(v reflect.Value, raw string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q (use a value like \"30s\" or \"5m\")", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q (use true or false)", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
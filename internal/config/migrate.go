@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config file schema version of this build. Files
+// without a version key are version 1.
+const CurrentVersion = 2
+
+// migrations upgrade a file's mapping node from the version at their index
+// plus one to the next version, returning warnings about deprecated keys
+var migrations = []func(root *yaml.Node) []FieldError{
+	migrateV1,
+}
+
+// Migrate upgrades config file data to CurrentVersion. It returns the
+// upgraded YAML, with comments preserved, the version the data was written
+// for and warnings about deprecated keys that were rewritten. Data already
+// at CurrentVersion is returned unchanged.
+func Migrate(data []byte) ([]byte, int, []FieldError, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, CurrentVersion, nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, 0, nil, fmt.Errorf("want a mapping at the top level, got %s", root.Tag)
+	}
+
+	version := 1
+	if node := mappingValue(root, "version"); node != nil {
+		v, err := strconv.Atoi(node.Value)
+		if err != nil || v < 1 {
+			return nil, 0, nil, fmt.Errorf("invalid version %q", node.Value)
+		}
+		version = v
+	}
+	if version > CurrentVersion {
+		return nil, version, nil, fmt.Errorf("version %d is newer than this build supports (%d)", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, version, nil, nil
+	}
+
+	var warnings []FieldError
+	for v := version; v < CurrentVersion; v++ {
+		warnings = append(warnings, migrations[v-1](root)...)
+	}
+	setVersion(root, CurrentVersion)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, version, nil, err
+	}
+	return buf.Bytes(), version, warnings, nil
+}
+
+// migrateV1 turns the comma separated resources.roots string into a list
+func migrateV1(root *yaml.Node) []FieldError {
+	key, roots := mappingEntry(mappingValue(root, "resources"), "roots")
+	if roots == nil || roots.Kind != yaml.ScalarNode {
+		return nil
+	}
+
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, item := range strings.Split(roots.Value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+	}
+	// A sequence keeps no line comment of its own, so it moves to the key
+	if key.LineComment == "" {
+		key.LineComment = roots.LineComment
+	}
+	*roots = *list
+	return []FieldError{{
+		Path:    "resources.roots",
+		Message: "a comma separated string is deprecated; use a list",
+	}}
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
+
+// mappingEntry returns the key and value nodes of key in a mapping node,
+// or nils
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// setVersion sets the version key, adding it first when missing
+func setVersion(root *yaml.Node, version int) {
+	value := strconv.Itoa(version)
+	if node := mappingValue(root, "version"); node != nil {
+		node.Value, node.Tag, node.Style = value, "!!int", 0
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+	if len(root.Content) > 0 {
+		// Keep a leading comment at the top of the file
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, {Kind: yaml.ScalarNode, Tag: "!!int", Value: value}}, root.Content...)
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnv selects the profile when -profile is not given
+const ProfileEnv = "META_MCP_PROFILE"
+
+// Built-in profile names
+const (
+	ProfileDev     = "dev"
+	ProfileStaging = "staging"
+	ProfileProd    = "prod"
+)
+
+// profiles adjust the defaults for each profile. Values from the config
+// file, environment and flags still take precedence.
+var profiles = map[string]func(cfg *Config){
+	// Verbose, readable logs with debug details and debug endpoints
+	ProfileDev: func(cfg *Config) {
+		cfg.Log = LogConfig{Level: "debug", Pretty: true, Debug: true}
+		cfg.Debug.Enabled = true
+		cfg.Server.Strict = false
+	},
+	// Debug logs, still sanitized, and risky settings rejected
+	ProfileStaging: func(cfg *Config) {
+		cfg.Log = LogConfig{Level: "debug", Sanitize: true, Debug: true}
+		cfg.Debug.Enabled = true
+		cfg.Server.Strict = true
+	},
+	// Sanitized info logs, no debug details or endpoints, risky settings
+	// rejected
+	ProfileProd: func(cfg *Config) {
+		cfg.Log = LogConfig{Level: "info", Sanitize: true}
+		cfg.Debug.Enabled = false
+		cfg.Server.Strict = true
+	},
+}
+
+// ProfileNames returns the known profile names in sorted order
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findProfile returns the selected profile name. Flags take precedence over
+// the environment, which takes precedence over the config file.
+func findProfile(file string, flagValues []flagValue, lookupEnv func(string) (string, bool)) string {
+	profile := ""
+	for _, fv := range flagValues {
+		if fv.field.path == "profile" {
+			profile = fv.raw
+		}
+	}
+	if profile != "" {
+		return profile
+	}
+	if env, ok := lookupEnv(ProfileEnv); ok {
+		return env
+	}
+	if file == "" {
+		return ""
+	}
+
+	// Errors are reported when the file is loaded
+	var peek struct {
+		Profile string `yaml:"profile"`
+	}
+	if data, err := os.ReadFile(file); err == nil {
+		yaml.Unmarshal(data, &peek)
+	}
+	return peek.Profile
+}
+
+// applyProfile adjusts cfg for the named profile, recording the fields it
+// changed in sources. Unknown profiles are left to validation.
+func applyProfile(cfg *Config, name string, fields []field, sources Sources) {
+	apply, ok := profiles[name]
+	if !ok {
+		return
+	}
+
+	before := make([]any, len(fields))
+	for i, f := range fields {
+		before[i] = f.value.Interface()
+	}
+	apply(cfg)
+	for i, f := range fields {
+		if !reflect.DeepEqual(before[i], f.value.Interface()) {
+			sources[f.path] = "profile " + name
+		}
+	}
+}
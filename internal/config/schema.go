@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaURI identifies the JSON Schema dialect of Schema
+const SchemaURI = "http://json-schema.org/draft-07/schema#"
+
+// durationPattern matches the durations accepted by time.ParseDuration
+const durationPattern = `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+// Schema returns a JSON Schema of the config file, derived from the struct
+// tags of Config, for editors to complete and check config files. Defaults
+// are those of Default, without a profile.
+func Schema() map[string]any {
+	root := schemaObject()
+	root["$schema"] = SchemaURI
+	root["title"] = "Meta-MCP Server configuration"
+	cfg := Default()
+	for _, f := range collectFields(&cfg) {
+		parent := root
+		names := strings.Split(f.path, ".")
+		for _, name := range names[:len(names)-1] {
+			properties := parent["properties"].(map[string]any)
+			child, ok := properties[name].(map[string]any)
+			if !ok {
+				child = schemaObject()
+				properties[name] = child
+			}
+			parent = child
+		}
+		parent["properties"].(map[string]any)[names[len(names)-1]] = fieldSchema(f)
+	}
+	return root
+}
+
+// schemaObject returns the schema of a section, which rejects unknown keys
+// like the file loader does
+func schemaObject() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{},
+		"additionalProperties": false,
+	}
+}
+
+// fieldSchema returns the schema of a leaf field
+func fieldSchema(f field) map[string]any {
+	s := make(map[string]any)
+	switch {
+	case f.value.Type() == durationType:
+		s["type"] = "string"
+		s["pattern"] = durationPattern
+	case f.value.Kind() == reflect.String:
+		s["type"] = "string"
+	case f.value.Kind() == reflect.Bool:
+		s["type"] = "boolean"
+	case f.value.Kind() == reflect.Int || f.value.Kind() == reflect.Int64:
+		s["type"] = "integer"
+	case f.value.Kind() == reflect.Slice:
+		s["type"] = "array"
+		s["items"] = map[string]any{"type": "string"}
+	}
+
+	for _, rule := range strings.Split(f.validate, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if f.value.Kind() == reflect.Slice {
+				s["minItems"] = 1
+			} else if f.value.Kind() == reflect.String {
+				s["minLength"] = 1
+			}
+		case "min":
+			if n, err := strconv.ParseInt(arg, 10, 64); err == nil && f.value.Type() != durationType {
+				s["minimum"] = n
+			}
+		case "oneof":
+			s["enum"] = strings.Fields(arg)
+		}
+	}
+
+	if description := fieldDescription(f); description != "" {
+		s["description"] = description
+	}
+	if value, ok := defaultValue(f); ok {
+		s["default"] = value
+	}
+	return s
+}
+
+// fieldDescription returns the usage of f, completed with the rules that
+// the schema cannot express
+func fieldDescription(f field) string {
+	parts := []string{f.usage}
+	for _, rule := range strings.Split(f.validate, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch {
+		case name == "min" && f.value.Type() == durationType && arg != "0s":
+			parts = append(parts, "at least "+arg)
+		case name == "hostport":
+			parts = append(parts, "a host:port address")
+		case name == "file":
+			parts = append(parts, "an existing file")
+		}
+	}
+	if f.secret {
+		parts = append(parts, `a literal, "env:NAME" or "file:/path"`)
+	}
+	return strings.TrimPrefix(strings.Join(parts, "; "), "; ")
+}
+
+// defaultValue returns the default of f in its file form, unless it is the
+// zero value
+func defaultValue(f field) (any, bool) {
+	if f.value.IsZero() || (f.value.Kind() == reflect.Slice && f.value.Len() == 0) {
+		return nil, false
+	}
+	if f.value.Type() == durationType {
+		return time.Duration(f.value.Int()).String(), true
+	}
+	if f.secret {
+		return nil, false
+	}
+	return f.value.Interface(), true
+}
+
+// Reference returns a Markdown reference of every config field: its file
+// key, type, default, environment variable, flag and description, one
+// table per section
+func Reference() string {
+	var b strings.Builder
+	cfg := Default()
+	section := "-"
+	for _, f := range collectFields(&cfg) {
+		name, _, nested := strings.Cut(f.path, ".")
+		if !nested {
+			name = ""
+		}
+		if name != section {
+			section = name
+			title := section
+			if title == "" {
+				title = "Top level"
+			}
+			fmt.Fprintf(&b, "\n## %s\n\n", title)
+			b.WriteString("| Key | Type | Default | Env | Flag | Description |\n")
+			b.WriteString("|-----|------|---------|-----|------|-------------|\n")
+		}
+
+		def := ""
+		if value, ok := defaultValue(f); ok {
+			def = "`" + formatValue(value) + "`"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			f.path, fieldType(f), def, code(f.env), code(flagName(f.flag)),
+			strings.ReplaceAll(fieldDescription(f), "|", `\|`))
+	}
+	return strings.TrimPrefix(b.String(), "\n")
+}
+
+// fieldType names the type of f for the reference
+func fieldType(f field) string {
+	switch {
+	case f.value.Type() == durationType:
+		return "duration"
+	case f.value.Kind() == reflect.Slice:
+		return "list"
+	case f.value.Kind() == reflect.Int64:
+		return "int"
+	}
+	return f.value.Kind().String()
+}
+
+// formatValue formats a default for the reference
+func formatValue(value any) string {
+	if items, ok := value.([]string); ok {
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// flagName returns the command-line form of flag
+func flagName(flag string) string {
+	if flag == "" {
+		return ""
+	}
+	return "-" + flag
+}
+
+// code formats s as inline code, unless it is empty
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
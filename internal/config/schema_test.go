@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func validateDocument(t *testing.T, document string) *gojsonschema.Result {
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(Schema()), gojsonschema.NewStringLoader(document))
+	require.NoError(t, err)
+	return result
+}
+
+func TestSchema(t *testing.T) {
+	result := validateDocument(t, `{
+		"version": 2,
+		"server": {"name": "test", "handshakeTimeout": "1m30s", "supportedVersions": ["2025-03-26"]},
+		"log": {"level": "debug"},
+		"debug": {"token": "env:DEBUG_TOKEN"}
+	}`)
+	assert.True(t, result.Valid(), "%v", result.Errors())
+
+	for _, document := range []string{
+		`{"server": {"nmae": "typo"}}`,
+		`{"server": {"handshakeTimeout": "soon"}}`,
+		`{"server": {"supportedVersions": []}}`,
+		`{"server": {"memoryLimit": -1}}`,
+		`{"log": {"level": "verbose"}}`,
+		`{"listen": {"stdio": "yes"}}`,
+	} {
+		assert.False(t, validateDocument(t, document).Valid(), document)
+	}
+
+	properties := Schema()["properties"].(map[string]any)
+	timeout := properties["server"].(map[string]any)["properties"].(map[string]any)["handshakeTimeout"].(map[string]any)
+	assert.Equal(t, "30s", timeout["default"])
+	assert.Contains(t, timeout["description"], "at least 1s")
+}
+
+func TestSchema_CoversEveryField(t *testing.T) {
+	cfg := Default()
+	reference := Reference()
+	for _, f := range collectFields(&cfg) {
+		node := Schema()
+		for _, name := range strings.Split(f.path, ".") {
+			node, _ = node["properties"].(map[string]any)[name].(map[string]any)
+			require.NotNil(t, node, f.path)
+		}
+		assert.Contains(t, reference, "| `"+f.path+"` |")
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	handler := AdminHandler()
+	response := handler.Handle(context.Background(), &jsonrpc.Request{Version: jsonrpc.Version, Method: AdminMethod, ID: 1})
+	require.Nil(t, response.Error)
+	data, err := json.Marshal(response.Result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"$schema":"`+SchemaURI+`"`)
+
+	response = handler.Handle(context.Background(), &jsonrpc.Request{Version: jsonrpc.Version, Method: AdminMethod,
+		Params: map[string]any{"format": "markdown"}, ID: 2})
+	require.Nil(t, response.Error)
+	assert.Contains(t, response.Result.(AdminResult).Markdown, "`server.name`")
+
+	response = handler.Handle(context.Background(), &jsonrpc.Request{Version: jsonrpc.Version, Method: AdminMethod,
+		Params: map[string]any{"format": "xml"}, ID: 3})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, response.Error.Code)
+}
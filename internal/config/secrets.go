@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Secret reference prefixes
+const (
+	SecretEnvPrefix  = "env:"
+	SecretFilePrefix = "file:"
+)
+
+// ResolveSecret returns the value a secret reference points to. Values
+// without a reference prefix are returned unchanged.
+func ResolveSecret(ref string, lookupEnv func(string) (string, bool)) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, SecretEnvPrefix):
+		name := strings.TrimPrefix(ref, SecretEnvPrefix)
+		value, ok := lookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret references environment variable %s, which is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, SecretFilePrefix):
+		path := strings.TrimPrefix(ref, SecretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return ref, nil
+	}
+}
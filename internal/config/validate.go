@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes an invalid configuration field
+type FieldError struct {
+	// Path is the dotted YAML path of the field
+	Path string
+
+	// Source is where the value came from ("env METRICS_ADDR", ...); empty
+	// for defaults
+	Source string
+
+	// Message explains what is wrong and how to fix it
+	Message string
+}
+
+// Error implements the error interface
+func (e FieldError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("%s (from %s): %s", e.Path, e.Source, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationError lists every invalid field
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// validate applies the validate tags and cross-field rules
+func validate(cfg *Config, fields []field, sources Sources) []FieldError {
+	var errs []FieldError
+	for _, f := range fields {
+		if f.validate == "" {
+			continue
+		}
+		for _, rule := range strings.Split(f.validate, ",") {
+			if msg := checkRule(f.value, rule); msg != "" {
+				errs = append(errs, FieldError{Path: f.path, Source: sources[f.path], Message: msg})
+				break
+			}
+		}
+	}
+
+	for _, err := range cfg.validate() {
+		err.Source = sources[err.Path]
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// checkRule returns a message if v breaks rule
+func checkRule(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "required":
+		if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+			return "is required"
+		}
+	case "min":
+		if v.Type() == durationType {
+			min, err := time.ParseDuration(arg)
+			if err == nil && time.Duration(v.Int()) < min {
+				return fmt.Sprintf("must be at least %s, got %s", min, time.Duration(v.Int()))
+			}
+		} else if v.Kind() == reflect.Int || v.Kind() == reflect.Int64 {
+			min, err := strconv.ParseInt(arg, 10, 64)
+			if err == nil && v.Int() < min {
+				return fmt.Sprintf("must be at least %d, got %d", min, v.Int())
+			}
+		}
+	case "oneof":
+		allowed := strings.Fields(arg)
+		for _, a := range allowed {
+			if v.String() == a {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(allowed, ", "), v.String())
+	case "hostport":
+		if addr := v.String(); addr != "" {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Sprintf("invalid address %q: use host:port, e.g. \"127.0.0.1:9090\" or \":9090\"", addr)
+			}
+		}
+	case "file":
+		if path := v.String(); path != "" {
+			info, err := os.Stat(path)
+			switch {
+			case err != nil:
+				return fmt.Sprintf("cannot read %q: %v", path, err)
+			case info.IsDir():
+				return fmt.Sprintf("%q is a directory, not a file", path)
+			}
+		}
+	}
+	return ""
+}
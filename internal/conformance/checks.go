@@ -0,0 +1,419 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// JSON-RPC error codes the checks expect
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxPages bounds how many pages a list may take
+const maxPages = 1000
+
+// Checks returns the conformance checks in the order they run
+func Checks() []Check {
+	return []Check{
+		{"handshake/initialize", "initialize returns the protocol version, capabilities and server info", LevelRequired, checkInitialize},
+		{"handshake/version-negotiation", "an unsupported protocol version is answered with a supported one or an error", LevelRequired, checkVersionNegotiation},
+		{"handshake/request-before-initialize", "requests before initialize are rejected", LevelRecommended, checkRequestBeforeInitialize},
+		{"jsonrpc/response-id", "responses carry the ID of their request, string or number", LevelRequired, checkResponseID},
+		{"errors/parse-error", "invalid JSON is answered with -32700 and a null ID", LevelRequired, checkParseError},
+		{"errors/invalid-request", "malformed requests are answered with -32600", LevelRecommended, checkInvalidRequest},
+		{"errors/method-not-found", "unknown methods are answered with -32601", LevelRequired, checkMethodNotFound},
+		{"errors/invalid-params", "calls to unknown tools are answered with -32602", LevelRecommended, checkInvalidParams},
+		{"batch/requests", "a batch is answered with one response per request, or rejected as a whole", LevelRecommended, checkBatch},
+		{"batch/empty", "an empty batch is answered with an error", LevelRecommended, checkEmptyBatch},
+		{"cancellation/unknown-request", "cancelling an unknown request is ignored and gets no response", LevelRequired, checkCancelUnknown},
+		{"pagination/list", "list results page through nextCursor without repeating items", LevelRequired, checkPagination},
+		{"pagination/invalid-cursor", "an invalid cursor is answered with -32602", LevelRecommended, checkInvalidCursor},
+	}
+}
+
+// expectError checks that response is an error with the given code
+func expectError(response *Response, code int) error {
+	if response.Error == nil {
+		return fmt.Errorf("expected error %d, got result %s", code, response.Result)
+	}
+	if response.Error.Code != code {
+		return fmt.Errorf("expected error %d, got %v", code, response.Error)
+	}
+	return nil
+}
+
+// expectResult checks that response is a result
+func expectResult(response *Response) error {
+	if response.Error != nil {
+		return fmt.Errorf("expected result, got error %v", response.Error)
+	}
+	if len(response.Result) == 0 {
+		return errors.New("response has neither result nor error")
+	}
+	return nil
+}
+
+// nextResponse reads the next non-notification message as a single
+// response
+func nextResponse(ctx context.Context, c *Client) (*Response, error) {
+	message, err := c.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var response Response
+	if err := json.Unmarshal(message, &response); err != nil {
+		return nil, fmt.Errorf("expected a single response, got %s", message)
+	}
+	return &response, nil
+}
+
+func checkInitialize(ctx context.Context, c *Client) error {
+	result, err := c.Initialize(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case result.ProtocolVersion == "":
+		return errors.New("result has no protocolVersion")
+	case result.Capabilities == nil:
+		return errors.New("result has no capabilities")
+	case result.ServerInfo.Name == "":
+		return errors.New("result has no serverInfo.name")
+	}
+	c.Notef("protocol %s", result.ProtocolVersion)
+	return nil
+}
+
+func checkVersionNegotiation(ctx context.Context, c *Client) error {
+	response, err := c.Call(ctx, "initialize", map[string]any{
+		"protocolVersion": "1999-01-01",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "mcp-conformance", "version": "1.0.0"},
+	})
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		if response.Error.Code != codeInvalidParams {
+			return fmt.Errorf("expected a supported version or error %d, got %v", codeInvalidParams, response.Error)
+		}
+		c.Notef("rejected with %v", response.Error)
+		return nil
+	}
+
+	var result InitializeResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return fmt.Errorf("invalid result: %w", err)
+	}
+	switch result.ProtocolVersion {
+	case "":
+		return errors.New("result has no protocolVersion")
+	case "1999-01-01":
+		return errors.New("server accepted an unknown protocol version")
+	}
+	c.Notef("offered %s", result.ProtocolVersion)
+	return nil
+}
+
+func checkRequestBeforeInitialize(ctx context.Context, c *Client) error {
+	response, err := c.Call(ctx, "tools/list", nil)
+	if err != nil {
+		return err
+	}
+	if response.Error == nil {
+		return errors.New("tools/list was answered before initialize")
+	}
+	c.Notef("rejected with %v", response.Error)
+	return nil
+}
+
+func checkResponseID(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	for _, id := range []any{"conformance-1", 4242} {
+		response, err := c.CallID(ctx, id, "ping", nil)
+		if err != nil {
+			return fmt.Errorf("ping with ID %v: %w", id, err)
+		}
+		if err := expectResult(response); err != nil {
+			return fmt.Errorf("ping with ID %v: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func checkParseError(ctx context.Context, c *Client) error {
+	if err := c.Send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"`)); err != nil {
+		return err
+	}
+	response, err := nextResponse(ctx, c)
+	if err != nil {
+		return err
+	}
+	if id := normalizeID(response.ID); id != "null" {
+		return fmt.Errorf("expected a null ID, got %s", id)
+	}
+	return expectError(response, codeParseError)
+}
+
+func checkInvalidRequest(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	for _, tc := range []struct {
+		name    string
+		message string
+	}{
+		{"wrong jsonrpc version", `{"jsonrpc":"1.0","id":"conformance-version","method":"ping"}`},
+		{"missing method", `{"jsonrpc":"2.0","id":"conformance-method"}`},
+	} {
+		if err := c.Send(ctx, []byte(tc.message)); err != nil {
+			return err
+		}
+		var header struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.Unmarshal([]byte(tc.message), &header)
+		response, err := c.Await(ctx, header.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", tc.name, err)
+		}
+		if err := expectError(response, codeInvalidRequest); err != nil {
+			return fmt.Errorf("%s: %w", tc.name, err)
+		}
+	}
+	return nil
+}
+
+func checkMethodNotFound(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	response, err := c.Call(ctx, "conformance/no-such-method", nil)
+	if err != nil {
+		return err
+	}
+	return expectError(response, codeMethodNotFound)
+}
+
+func checkInvalidParams(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	if !c.HasCapability("tools") {
+		return Skip("server has no tools capability")
+	}
+	response, err := c.Call(ctx, "tools/call", map[string]any{"name": "conformance-no-such-tool"})
+	if err != nil {
+		return err
+	}
+	return expectError(response, codeInvalidParams)
+}
+
+func checkBatch(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	if err := c.Send(ctx, []byte(`[{"jsonrpc":"2.0","id":"batch-1","method":"ping"},{"jsonrpc":"2.0","id":"batch-2","method":"ping"}]`)); err != nil {
+		return err
+	}
+	message, err := c.Next(ctx)
+	if err != nil {
+		return err
+	}
+
+	var responses []Response
+	if err := json.Unmarshal(message, &responses); err != nil {
+		// Batches may be unsupported (they were dropped in 2025-06-18),
+		// but then the whole batch must be rejected
+		var response Response
+		if json.Unmarshal(message, &response) != nil || response.Error == nil {
+			return fmt.Errorf("expected a batch response or an error, got %s", message)
+		}
+		c.Notef("batches rejected with %v", response.Error)
+		return nil
+	}
+
+	if len(responses) != 2 {
+		return fmt.Errorf("expected 2 responses, got %d", len(responses))
+	}
+	seen := make(map[string]bool)
+	for _, response := range responses {
+		if err := expectResult(&response); err != nil {
+			return err
+		}
+		seen[normalizeID(response.ID)] = true
+	}
+	if !seen[`"batch-1"`] || !seen[`"batch-2"`] {
+		return fmt.Errorf("responses do not match the batch IDs: %s", message)
+	}
+	return nil
+}
+
+func checkEmptyBatch(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	if err := c.Send(ctx, []byte(`[]`)); err != nil {
+		return err
+	}
+	response, err := nextResponse(ctx, c)
+	if err != nil {
+		return err
+	}
+	if response.Error == nil {
+		return fmt.Errorf("expected an error, got result %s", response.Result)
+	}
+	if response.Error.Code != codeInvalidRequest {
+		c.Notef("rejected with %v", response.Error)
+	}
+	return nil
+}
+
+func checkCancelUnknown(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	cancelled := mcp.NewCancelledNotification(mcpgo.NewRequestId("conformance-unknown"), "conformance check")
+	if err := c.Notify(ctx, cancelled.Method, cancelled.Params); err != nil {
+		return err
+	}
+
+	// The ping response must be the next message: the notification gets
+	// none
+	if err := c.Send(ctx, []byte(`{"jsonrpc":"2.0","id":"after-cancel","method":"ping"}`)); err != nil {
+		return err
+	}
+	response, err := nextResponse(ctx, c)
+	if err != nil {
+		return err
+	}
+	if id := normalizeID(response.ID); id != `"after-cancel"` {
+		return fmt.Errorf("cancellation was answered with %s", id)
+	}
+	return expectResult(response)
+}
+
+// listMethod describes a paginated list method
+type listMethod struct {
+	capability string
+	method     string
+	field      string
+	key        string
+}
+
+// listMethods are the paginated list methods, by capability
+var listMethods = []listMethod{
+	{"tools", "tools/list", "tools", "name"},
+	{"resources", "resources/list", "resources", "uri"},
+	{"resources", "resources/templates/list", "resourceTemplates", "uriTemplate"},
+	{"prompts", "prompts/list", "prompts", "name"},
+}
+
+// supportedLists returns the list methods of the server's capabilities
+func supportedLists(c *Client) []listMethod {
+	var lists []listMethod
+	for _, list := range listMethods {
+		if c.HasCapability(list.capability) {
+			lists = append(lists, list)
+		}
+	}
+	return lists
+}
+
+func checkPagination(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	lists := supportedLists(c)
+	if len(lists) == 0 {
+		return Skip("server has no tools, resources or prompts capability")
+	}
+
+	var counts []string
+	for _, list := range lists {
+		n, err := pageThrough(ctx, c, list)
+		if err != nil {
+			return fmt.Errorf("%s: %w", list.method, err)
+		}
+		counts = append(counts, fmt.Sprintf("%s: %d", list.field, n))
+	}
+	c.Notef("%s", strings.Join(counts, ", "))
+	return nil
+}
+
+// pageThrough lists every item of list, returning how many there are
+func pageThrough(ctx context.Context, c *Client, list listMethod) (int, error) {
+	seen := make(map[string]bool)
+	var cursor *string
+	for page := 1; page <= maxPages; page++ {
+		var params map[string]any
+		if cursor != nil {
+			params = map[string]any{"cursor": *cursor}
+		}
+		response, err := c.Call(ctx, list.method, params)
+		if err != nil {
+			return 0, err
+		}
+		if err := expectResult(response); err != nil {
+			return 0, fmt.Errorf("page %d: %w", page, err)
+		}
+
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			return 0, fmt.Errorf("page %d: invalid result: %w", page, err)
+		}
+		var items []map[string]any
+		if err := json.Unmarshal(result[list.field], &items); err != nil {
+			return 0, fmt.Errorf("page %d: %s is not an array", page, list.field)
+		}
+		for _, item := range items {
+			key := fmt.Sprint(item[list.key])
+			if seen[key] {
+				return 0, fmt.Errorf("page %d repeats %q", page, key)
+			}
+			seen[key] = true
+		}
+
+		raw, ok := result["nextCursor"]
+		if !ok || string(raw) == "null" {
+			return len(seen), nil
+		}
+		var next string
+		if err := json.Unmarshal(raw, &next); err != nil {
+			return 0, fmt.Errorf("page %d: nextCursor is not a string", page)
+		}
+		if cursor != nil && next == *cursor {
+			return 0, fmt.Errorf("page %d returned its own cursor", page)
+		}
+		cursor = &next
+	}
+	return 0, fmt.Errorf("more than %d pages", maxPages)
+}
+
+func checkInvalidCursor(ctx context.Context, c *Client) error {
+	if _, err := c.Initialize(ctx); err != nil {
+		return err
+	}
+	lists := supportedLists(c)
+	if len(lists) == 0 {
+		return Skip("server has no tools, resources or prompts capability")
+	}
+	response, err := c.Call(ctx, lists[0].method, map[string]any{"cursor": "!conformance-invalid-cursor!"})
+	if err != nil {
+		return err
+	}
+	return expectError(response, codeInvalidParams)
+}
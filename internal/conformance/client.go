@@ -0,0 +1,222 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Response is a JSON-RPC response as received
+type Response struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// ServerInfo identifies the server under test
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InitializeResult is the part of the initialize result the suite uses
+type InitializeResult struct {
+	ProtocolVersion string                     `json:"protocolVersion"`
+	Capabilities    map[string]json.RawMessage `json:"capabilities"`
+	ServerInfo      ServerInfo                 `json:"serverInfo"`
+}
+
+// Client talks JSON-RPC to the server under test. It is not safe for
+// concurrent use.
+type Client struct {
+	conn    Conn
+	version string
+	nextID  int
+
+	// stash holds responses received while waiting for another
+	stash map[string]*Response
+
+	// Server is the result of the last successful Initialize
+	Server *InitializeResult
+
+	// note is reported with the check's result
+	note string
+}
+
+// NewClient creates a client on conn proposing version in the handshake
+func NewClient(conn Conn, version string) *Client {
+	return &Client{conn: conn, version: version, stash: make(map[string]*Response)}
+}
+
+// Notef records a note reported with the check's result
+func (c *Client) Notef(format string, args ...any) {
+	c.note = fmt.Sprintf(format, args...)
+}
+
+// HasCapability reports whether the initialized server declared capability
+func (c *Client) HasCapability(capability string) bool {
+	if c.Server == nil {
+		return false
+	}
+	_, ok := c.Server.Capabilities[capability]
+	return ok
+}
+
+// Initialize performs the handshake with the configured protocol version
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	result, err := c.InitializeVersion(ctx, c.version)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InitializeVersion sends initialize proposing version, without the
+// initialized notification that completes the handshake
+func (c *Client) InitializeVersion(ctx context.Context, version string) (*InitializeResult, error) {
+	response, err := c.Call(ctx, "initialize", map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "mcp-conformance", "version": "1.0.0"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("initialize: %w", response.Error)
+	}
+	var result InitializeResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return nil, fmt.Errorf("initialize: invalid result: %w", err)
+	}
+	c.Server = &result
+	return &result, nil
+}
+
+// Call sends a request with a fresh numeric ID and waits for its response
+func (c *Client) Call(ctx context.Context, method string, params any) (*Response, error) {
+	c.nextID++
+	return c.CallID(ctx, c.nextID, method, params)
+}
+
+// CallID sends a request with the given ID and waits for its response
+func (c *Client) CallID(ctx context.Context, id any, method string, params any) (*Response, error) {
+	message, err := encode(id, method, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Send(ctx, message); err != nil {
+		return nil, err
+	}
+	rawID, _ := json.Marshal(id)
+	return c.Await(ctx, rawID)
+}
+
+// Notify sends a notification
+func (c *Client) Notify(ctx context.Context, method string, params any) error {
+	message, err := encode(nil, method, params)
+	if err != nil {
+		return err
+	}
+	return c.Send(ctx, message)
+}
+
+// Send sends a raw message
+func (c *Client) Send(ctx context.Context, message []byte) error {
+	if err := c.conn.Send(ctx, message); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+	return nil
+}
+
+// Await waits for the response with the given encoded ID
+func (c *Client) Await(ctx context.Context, id json.RawMessage) (*Response, error) {
+	key := normalizeID(id)
+	for {
+		if response, ok := c.stash[key]; ok {
+			delete(c.stash, key)
+			return response, nil
+		}
+		message, err := c.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var response Response
+		if err := json.Unmarshal(message, &response); err != nil {
+			return nil, fmt.Errorf("unexpected message %s", message)
+		}
+		c.stash[normalizeID(response.ID)] = &response
+	}
+}
+
+// Next returns the next message from the server that is not a
+// notification or a request to the client. Server requests are answered:
+// pings with an empty result, anything else with method not found.
+func (c *Client) Next(ctx context.Context) ([]byte, error) {
+	for {
+		message, err := c.conn.Receive(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("receive: %w", err)
+		}
+		var header struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if bytes.HasPrefix(message, []byte("[")) || json.Unmarshal(message, &header) != nil || header.Method == "" {
+			return message, nil
+		}
+		if len(header.ID) == 0 {
+			continue
+		}
+
+		reply := map[string]any{"jsonrpc": "2.0", "id": header.ID}
+		if header.Method == "ping" {
+			reply["result"] = map[string]any{}
+		} else {
+			reply["error"] = map[string]any{"code": -32601, "message": "Method not found"}
+		}
+		data, _ := json.Marshal(reply)
+		if err := c.Send(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// encode builds a request, or a notification when id is nil
+func encode(id any, method string, params any) ([]byte, error) {
+	message := map[string]any{"jsonrpc": "2.0", "method": method}
+	if id != nil {
+		message["id"] = id
+	}
+	if params != nil {
+		message["params"] = params
+	}
+	return json.Marshal(message)
+}
+
+// normalizeID returns a canonical encoding of a raw ID so 1 and 1.0 match
+func normalizeID(id json.RawMessage) string {
+	if n, err := strconv.ParseFloat(string(id), 64); err == nil {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	if len(id) == 0 {
+		return "null"
+	}
+	return string(id)
+}
@@ -0,0 +1,177 @@
+// Package conformance checks that an MCP server follows the protocol:
+// handshake ordering, JSON-RPC error codes, batch handling, cancellation
+// and pagination. It talks raw JSON-RPC, so it can send the malformed and
+// out-of-order messages a well-behaved client never would, and it runs
+// against any server reachable over stdio, SSE or a socket.
+//
+// Basic usage:
+//
+//	report := conformance.Run(ctx, conformance.StdioDialer(argv, nil), conformance.Config{})
+//	report.WriteText(os.Stdout)
+//	if !report.Compliant() {
+//		// a required check failed
+//	}
+//
+// The same suite validates our own server in tests and upstream servers
+// before they are registered (mcpctl conformance).
+package conformance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Level is how strictly a check's requirement applies
+type Level string
+
+// Check levels
+const (
+	// LevelRequired checks MUST requirements; a failure makes the server
+	// non-compliant
+	LevelRequired Level = "required"
+	// LevelRecommended checks SHOULD requirements; a failure is a warning
+	LevelRecommended Level = "recommended"
+)
+
+// Status is the outcome of a check
+type Status string
+
+// Check outcomes
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusWarn Status = "warn"
+	StatusSkip Status = "skip"
+)
+
+// Check is one conformance check
+type Check struct {
+	// ID names the check as "<category>/<name>"
+	ID string
+
+	// Description states the requirement checked
+	Description string
+
+	Level Level
+
+	// Run checks the server over a fresh, uninitialized connection. It
+	// returns Skip when the check does not apply to the server.
+	Run func(ctx context.Context, c *Client) error
+}
+
+// Category returns the part of the check ID before the slash
+func (c Check) Category() string {
+	category, _, _ := strings.Cut(c.ID, "/")
+	return category
+}
+
+// skipError reports that a check does not apply
+type skipError struct {
+	reason string
+}
+
+func (e skipError) Error() string { return e.reason }
+
+// Skip returns an error that marks a check as skipped
+func Skip(reason string) error {
+	return skipError{reason: reason}
+}
+
+// Config contains configuration for a conformance run
+type Config struct {
+	// ProtocolVersion is proposed in the handshake (defaults to the
+	// latest version)
+	ProtocolVersion string
+
+	// Timeout bounds each check (defaults to 10s)
+	Timeout time.Duration
+
+	// Checks are run in order (defaults to Checks())
+	Checks []Check
+}
+
+// Filter returns the checks whose ID or category matches one of patterns;
+// no patterns selects every check
+func Filter(checks []Check, patterns []string) []Check {
+	if len(patterns) == 0 {
+		return checks
+	}
+	var selected []Check
+	for _, check := range checks {
+		for _, pattern := range patterns {
+			if check.ID == pattern || check.Category() == pattern {
+				selected = append(selected, check)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// Run runs the checks against the server, dialing a new connection for
+// each
+func Run(ctx context.Context, dial Dialer, config Config) *Report {
+	if config.ProtocolVersion == "" {
+		config.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Checks == nil {
+		config.Checks = Checks()
+	}
+
+	report := &Report{ProtocolVersion: config.ProtocolVersion, Started: time.Now()}
+	for _, check := range config.Checks {
+		result, server := runCheck(ctx, dial, config, check)
+		report.Results = append(report.Results, result)
+		if report.Server == nil && server != nil && server.ServerInfo.Name != "" {
+			report.Server = &server.ServerInfo
+		}
+	}
+	report.Duration = time.Since(report.Started)
+	return report
+}
+
+// runCheck runs one check on its own connection
+func runCheck(ctx context.Context, dial Dialer, config Config, check Check) (Result, *InitializeResult) {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	result := Result{Check: check.ID, Description: check.Description, Level: check.Level}
+	start := time.Now()
+
+	conn, err := dial(ctx)
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = "connect: " + err.Error()
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+	defer conn.Close()
+
+	client := NewClient(conn, config.ProtocolVersion)
+	err = check.Run(ctx, client)
+	result.Duration = time.Since(start)
+
+	var skip skipError
+	switch {
+	case errors.As(err, &skip):
+		result.Status = StatusSkip
+		result.Message = skip.reason
+	case err == nil:
+		result.Status = StatusPass
+		result.Message = client.note
+	case check.Level == LevelRecommended:
+		result.Status = StatusWarn
+		result.Message = err.Error()
+	default:
+		result.Status = StatusFail
+		result.Message = err.Error()
+	}
+	return result, client.Server
+}
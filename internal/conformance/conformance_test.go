@@ -0,0 +1,128 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+)
+
+func newHandshakeServer(t *testing.T) *mcp.HandshakeServer {
+	t.Helper()
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+	hs := mcp.NewHandshakeServer(config)
+	for i := range 3 {
+		hs.AddTool(mcpgo.NewTool(fmt.Sprintf("tool%d", i)),
+			func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+				return mcpgo.NewToolResultText("ok"), nil
+			})
+	}
+	return hs
+}
+
+// pipeDialer serves each connection in-process, as the stdio and socket
+// transports do
+func pipeDialer(hs *mcp.HandshakeServer) Dialer {
+	s := server.New(hs, server.Config{})
+	return func(ctx context.Context) (Conn, error) {
+		client, conn := net.Pipe()
+		go s.ServeConn(context.Background(), "conformance", server.NewLineConn(conn, conn, conn))
+		return NewStreamConn(client, client, client), nil
+	}
+}
+
+func TestRun_OwnServer(t *testing.T) {
+	report := Run(context.Background(), pipeDialer(newHandshakeServer(t)), Config{Timeout: 5 * time.Second})
+
+	for _, result := range report.Results {
+		t.Logf("%s %s: %s", result.Status, result.Check, result.Message)
+	}
+	assert.True(t, report.Compliant())
+	require.NotNil(t, report.Server)
+	assert.Equal(t, "Meta-MCP Server", report.Server.Name)
+	assert.Len(t, report.Results, len(Checks()))
+
+	result, ok := report.Result("pagination/list")
+	require.True(t, ok)
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, "tools: 3", result.Message)
+
+	result, _ = report.Result("batch/requests")
+	assert.Equal(t, StatusPass, result.Status)
+}
+
+func TestRun_SSE(t *testing.T) {
+	sse := server.NewSSE(server.HTTPConfig{Addr: "127.0.0.1:0"})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, newHandshakeServer(t), sse) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	addr := sse.(interface{ Addr() net.Addr }).Addr()
+	report := Run(context.Background(), SSEDialer("http://"+addr.String()+"/sse"), Config{
+		Timeout: 5 * time.Second,
+		Checks:  Filter(Checks(), []string{"handshake/initialize", "errors", "pagination"}),
+	})
+	for _, result := range report.Results {
+		t.Logf("%s %s: %s", result.Status, result.Check, result.Message)
+	}
+	assert.True(t, report.Compliant())
+	assert.Len(t, report.Results, 7)
+}
+
+func TestRun_NonCompliantServer(t *testing.T) {
+	// A server that answers every message with the same result
+	dial := func(ctx context.Context) (Conn, error) {
+		client, conn := net.Pipe()
+		go func() {
+			defer conn.Close()
+			buf := make([]byte, 64*1024)
+			for {
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+				conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}` + "\n"))
+			}
+		}()
+		return NewStreamConn(client, client, client), nil
+	}
+
+	report := Run(context.Background(), dial, Config{
+		Timeout: 200 * time.Millisecond,
+		Checks:  Filter(Checks(), []string{"handshake/initialize", "errors/method-not-found"}),
+	})
+	assert.False(t, report.Compliant())
+	assert.Equal(t, Summary{Failed: 2}, report.Summary())
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Regexp(t, `FAIL\s+handshake/initialize\s+required`, out.String())
+	assert.NotContains(t, out.String(), "Server:")
+	assert.True(t, strings.HasSuffix(out.String(), "0 passed, 2 failed, 0 warnings, 0 skipped: NOT compliant\n"))
+}
+
+func TestFilter(t *testing.T) {
+	checks := Filter(Checks(), []string{"batch", "errors/parse-error"})
+	var ids []string
+	for _, check := range checks {
+		ids = append(ids, check.ID)
+	}
+	assert.Equal(t, []string{"errors/parse-error", "batch/requests", "batch/empty"}, ids)
+	assert.Len(t, Filter(Checks(), nil), len(Checks()))
+}
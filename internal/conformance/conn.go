@@ -0,0 +1,360 @@
+package conformance
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMessageSize bounds a single message read from a server
+const maxMessageSize = 10 * 1024 * 1024
+
+// Conn carries raw JSON-RPC messages to and from a server. Messages are
+// not validated, so malformed ones can be sent on purpose.
+type Conn interface {
+	// Send sends one message, which may be a batch or invalid JSON
+	Send(ctx context.Context, message []byte) error
+
+	// Receive returns the next message from the server
+	Receive(ctx context.Context) ([]byte, error)
+
+	// Close disconnects from the server
+	Close() error
+}
+
+// Dialer opens a new connection to the server under test; every check gets
+// its own connection so handshake state does not leak between checks
+type Dialer func(ctx context.Context) (Conn, error)
+
+// streamConn is a Conn over newline-delimited messages
+type streamConn struct {
+	w      io.Writer
+	closer io.Closer
+
+	wmu      sync.Mutex
+	messages chan []byte
+	closing  chan struct{}
+	once     sync.Once
+	done     chan struct{}
+	err      error
+}
+
+// NewStreamConn returns a Conn reading newline-delimited messages from r
+// and writing them to w; closing it closes c
+func NewStreamConn(r io.Reader, w io.Writer, c io.Closer) Conn {
+	conn := &streamConn{
+		w:        w,
+		closer:   c,
+		messages: make(chan []byte, 64),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go conn.read(r)
+	return conn
+}
+
+// read delivers messages from r until it fails
+func (c *streamConn) read(r io.Reader) {
+	defer close(c.done)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		select {
+		case c.messages <- append([]byte(nil), line...):
+		case <-c.closing:
+			return
+		}
+	}
+	c.err = scanner.Err()
+	if c.err == nil {
+		c.err = io.EOF
+	}
+}
+
+func (c *streamConn) Send(ctx context.Context, message []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.w.Write(append(append([]byte(nil), message...), '\n'))
+	return err
+}
+
+func (c *streamConn) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case message := <-c.messages:
+		return message, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		// Messages read before the failure come first
+		select {
+		case message := <-c.messages:
+			return message, nil
+		default:
+			return nil, c.err
+		}
+	}
+}
+
+func (c *streamConn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.closing)
+		if c.closer != nil {
+			err = c.closer.Close()
+		}
+	})
+	return err
+}
+
+// StdioDialer starts the server command for every connection and talks
+// to it over its stdin and stdout. The server's stderr is discarded unless
+// stderr is set.
+func StdioDialer(argv []string, stderr io.Writer) Dialer {
+	return func(ctx context.Context) (Conn, error) {
+		if len(argv) == 0 {
+			return nil, errors.New("no server command")
+		}
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Stderr = stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("start server: %w", err)
+		}
+		return NewStreamConn(stdout, stdin, processCloser{cmd: cmd, stdin: stdin}), nil
+	}
+}
+
+// processCloser stops a stdio server by closing its stdin, killing it if
+// it does not exit promptly
+type processCloser struct {
+	cmd   *exec.Cmd
+	stdin io.Closer
+}
+
+func (p processCloser) Close() error {
+	p.stdin.Close()
+	exited := make(chan struct{})
+	go func() {
+		p.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		p.cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
+
+// SocketDialer connects to a server listening on a unix or tcp socket
+func SocketDialer(network, address string) Dialer {
+	return func(ctx context.Context) (Conn, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		return NewStreamConn(conn, conn, conn), nil
+	}
+}
+
+// SSEDialer connects to a server's Server-Sent Events endpoint. Messages
+// are posted to the endpoint the server announces; responses arrive on the
+// event stream, or in the body of a rejected post.
+func SSEDialer(endpoint string) Dialer {
+	return func(ctx context.Context) (Conn, error) {
+		return dialSSE(ctx, endpoint)
+	}
+}
+
+// sseConn is a Conn over the SSE transport
+type sseConn struct {
+	client   *http.Client
+	cancel   context.CancelFunc
+	post     chan string
+	messages chan []byte
+	done     chan struct{}
+	err      error
+
+	mu       sync.Mutex
+	endpoint string
+}
+
+// dialSSE opens the event stream and waits for the message endpoint
+func dialSSE(ctx context.Context, endpoint string) (Conn, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stream outlives ctx, which only bounds the dial
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	c := &sseConn{
+		client:   &http.Client{},
+		cancel:   cancel,
+		post:     make(chan string, 1),
+		messages: make(chan []byte, 256),
+		done:     make(chan struct{}),
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("connect: %s", resp.Status)
+	}
+	go c.read(resp.Body, base)
+
+	select {
+	case e := <-c.post:
+		c.mu.Lock()
+		c.endpoint = e
+		c.mu.Unlock()
+		return c, nil
+	case <-c.done:
+		cancel()
+		return nil, fmt.Errorf("stream closed before the endpoint event: %w", c.err)
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// read dispatches events from the stream until it ends
+func (c *sseConn) read(body io.ReadCloser, base *url.URL) {
+	defer close(c.done)
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			c.dispatch(event, strings.Join(data, "\n"), base)
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	c.err = scanner.Err()
+	if c.err == nil {
+		c.err = io.EOF
+	}
+}
+
+// dispatch handles one complete event
+func (c *sseConn) dispatch(event, data string, base *url.URL) {
+	switch event {
+	case "endpoint":
+		ref, err := base.Parse(data)
+		if err != nil {
+			return
+		}
+		select {
+		case c.post <- ref.String():
+		default:
+		}
+	case "message", "":
+		if data != "" {
+			c.deliver([]byte(data))
+		}
+	}
+}
+
+// deliver queues a message, dropping it if nobody is reading
+func (c *sseConn) deliver(message []byte) {
+	select {
+	case c.messages <- message:
+	default:
+	}
+}
+
+func (c *sseConn) Send(ctx context.Context, message []byte) error {
+	c.mu.Lock()
+	endpoint := c.endpoint
+	c.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMessageSize))
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && json.Valid(body) {
+		c.deliver(body)
+		return nil
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("post: %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *sseConn) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case message := <-c.messages:
+		return message, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.done:
+		select {
+		case message := <-c.messages:
+			return message, nil
+		default:
+			return nil, c.err
+		}
+	}
+}
+
+func (c *sseConn) Close() error {
+	c.cancel()
+	return nil
+}
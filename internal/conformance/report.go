@@ -0,0 +1,98 @@
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Check       string        `json:"check"`
+	Description string        `json:"description"`
+	Level       Level         `json:"level"`
+	Status      Status        `json:"status"`
+	Message     string        `json:"message,omitempty"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// Summary counts results by status
+type Summary struct {
+	Passed   int `json:"passed"`
+	Failed   int `json:"failed"`
+	Warnings int `json:"warnings"`
+	Skipped  int `json:"skipped"`
+}
+
+// Report is the compliance report of a conformance run
+type Report struct {
+	Server          *ServerInfo   `json:"server,omitempty"`
+	ProtocolVersion string        `json:"protocolVersion"`
+	Started         time.Time     `json:"started"`
+	Duration        time.Duration `json:"duration"`
+	Results         []Result      `json:"results"`
+}
+
+// Summary counts the report's results by status
+func (r *Report) Summary() Summary {
+	var s Summary
+	for _, result := range r.Results {
+		switch result.Status {
+		case StatusPass:
+			s.Passed++
+		case StatusFail:
+			s.Failed++
+		case StatusWarn:
+			s.Warnings++
+		case StatusSkip:
+			s.Skipped++
+		}
+	}
+	return s
+}
+
+// Compliant reports whether every required check passed or was skipped
+func (r *Report) Compliant() bool {
+	return r.Summary().Failed == 0
+}
+
+// Result returns the result of the check with the given ID
+func (r *Report) Result(check string) (Result, bool) {
+	for _, result := range r.Results {
+		if result.Check == check {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// WriteText writes the report as a table followed by a summary line
+func (r *Report) WriteText(w io.Writer) error {
+	if r.Server != nil {
+		fmt.Fprintf(w, "Server: %s %s (protocol %s)\n\n", r.Server.Name, r.Server.Version, r.ProtocolVersion)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tCHECK\tLEVEL\tDETAILS")
+	for _, result := range r.Results {
+		details := result.Description
+		if result.Message != "" {
+			details += ": " + result.Message
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", strings.ToUpper(string(result.Status)), result.Check, result.Level, details)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	s := r.Summary()
+	verdict := "compliant"
+	if !r.Compliant() {
+		verdict = "NOT compliant"
+	}
+	_, err := fmt.Fprintf(w, "\n%d passed, %d failed, %d warnings, %d skipped: %s\n",
+		s.Passed, s.Failed, s.Warnings, s.Skipped, verdict)
+	return err
+}
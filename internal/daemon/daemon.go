@@ -0,0 +1,120 @@
+// Package daemon contains helpers for running the server as a long-lived
+// service: a PID file guarding against a second instance, and readiness
+// notifications for systemd units of Type=notify.
+//
+// Basic usage:
+//
+//	pidFile, err := daemon.CreatePIDFile("/run/meta-mcp.pid")
+//	if err != nil {
+//		return err
+//	}
+//	defer pidFile.Remove()
+//
+//	daemon.Notify(daemon.Ready)
+//	defer daemon.Notify(daemon.Stopping)
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// NotifySocketEnv names the socket systemd listens on for notifications
+const NotifySocketEnv = "NOTIFY_SOCKET"
+
+// Service states reported with Notify
+const (
+	Ready     = "READY=1"
+	Reloading = "RELOADING=1"
+	Stopping  = "STOPPING=1"
+)
+
+// ErrAlreadyRunning is returned when the PID file names a live process
+var ErrAlreadyRunning = errors.New("already running")
+
+// PIDFile is a PID file owned by this process
+type PIDFile struct {
+	path string
+}
+
+// CreatePIDFile writes the current process ID to path. It fails with
+// ErrAlreadyRunning if the file names another live process; a stale file
+// left by a crashed process is replaced.
+func CreatePIDFile(path string) (*PIDFile, error) {
+	if pid, err := ReadPIDFile(path); err == nil && pid != os.Getpid() && processExists(pid) {
+		return nil, fmt.Errorf("%w: pid %d in %s", ErrAlreadyRunning, pid, path)
+	}
+
+	// Write a temporary file and rename it, so readers never see a
+	// partially written PID
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return &PIDFile{path: path}, nil
+}
+
+// Path returns the file path
+func (f *PIDFile) Path() string {
+	return f.path
+}
+
+// Remove deletes the file if it still holds this process's ID
+func (f *PIDFile) Remove() error {
+	pid, err := ReadPIDFile(f.path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && pid != os.Getpid()) {
+		return nil
+	}
+	return os.Remove(f.path)
+}
+
+// ReadPIDFile returns the process ID stored in path
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%s: invalid pid %q", path, strings.TrimSpace(string(data)))
+	}
+	return pid, nil
+}
+
+// processExists reports whether a process with pid is alive
+func processExists(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Notify sends state to the service manager. It does nothing and returns
+// false when the process was not started by systemd with NotifyAccess.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv(NotifySocketEnv)
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ names an abstract socket
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.pid")
+
+	f, err := CreatePIDFile(path)
+	require.NoError(t, err)
+	pid, err := ReadPIDFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+
+	// Creating it again from the same process is allowed
+	_, err = CreatePIDFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Remove())
+	assert.NoFileExists(t, path)
+	assert.NoError(t, f.Remove())
+}
+
+func TestPIDFile_OtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.pid")
+
+	// PID 1 is always alive
+	require.NoError(t, os.WriteFile(path, []byte("1\n"), 0o644))
+	_, err := CreatePIDFile(path)
+	assert.ErrorIs(t, err, ErrAlreadyRunning)
+
+	// A stale file is replaced
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(1<<30)), 0o644))
+	f, err := CreatePIDFile(path)
+	require.NoError(t, err)
+
+	// Remove leaves a file taken over by another process alone
+	require.NoError(t, os.WriteFile(path, []byte("1\n"), 0o644))
+	require.NoError(t, f.Remove())
+	assert.FileExists(t, path)
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o644))
+	_, err = ReadPIDFile(path)
+	assert.Error(t, err)
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv(NotifySocketEnv, "")
+	sent, err := Notify(Ready)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv(NotifySocketEnv, socket)
+	sent, err = Notify(Ready)
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, Ready, string(buf[:n]))
+}
@@ -0,0 +1,249 @@
+// Package debug provides an opt-in HTTP listener for production
+// troubleshooting.
+//
+// The listener serves net/http/pprof profiles together with JSON dumps of
+// the server's internal state. Every endpoint requires a bearer token, so the
+// listener is safe to bind on an internal interface but should still never
+// be exposed publicly:
+//
+//   - /debug/pprof/: CPU, heap, block and other runtime profiles
+//   - /debug/goroutines: full goroutine stack dump as text
+//   - /debug/router: registered request and notification methods
+//   - /debug/async: async router queue statistics and pending requests
+//   - /debug/connections: connection table
+//   - /debug/memory: memory guard usage and shed counts
+//   - /debug/logs: recent log records from a logging.RingBuffer as NDJSON
+//
+// Basic usage:
+//
+//	srv, err := debug.New(debug.Config{
+//		Token:       os.Getenv("DEBUG_TOKEN"),
+//		Router:      r,
+//		Connections: connManager,
+//	})
+//	go srv.ListenAndServe(ctx, "127.0.0.1:6060")
+package debug
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/memguard"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// PathPrefix is the prefix shared by every debug endpoint
+const PathPrefix = "/debug/"
+
+// TokenQueryParam may carry the token for clients that cannot set headers
+const TokenQueryParam = "token"
+
+// ErrNoToken is returned when the debug server is configured without a token
+var ErrNoToken = errors.New("debug: token is required")
+
+// Config contains configuration for the debug server
+type Config struct {
+	// Token authenticates requests (required)
+	Token string
+
+	// Router is dumped by /debug/router (optional)
+	Router *router.Router
+
+	// AsyncRouter is dumped by /debug/async (optional)
+	AsyncRouter *router.AsyncRouter
+
+	// Connections is dumped by /debug/connections (optional)
+	Connections *connection.Manager
+
+	// Logs is served by /debug/logs (optional)
+	Logs *logging.RingBuffer
+
+	// Guard is dumped by /debug/memory (optional)
+	Guard *memguard.Guard
+}
+
+// Server serves debug endpoints
+type Server struct {
+	config  Config
+	handler http.Handler
+}
+
+// RouterDump lists the methods registered on a router
+type RouterDump struct {
+	Methods             []string `json:"methods"`
+	NotificationMethods []string `json:"notificationMethods"`
+}
+
+// AsyncDump describes the async router queue
+type AsyncDump struct {
+	QueuedRequests  int      `json:"queuedRequests"`
+	QueueCapacity   int      `json:"queueCapacity"`
+	PendingRequests int      `json:"pendingRequests"`
+	Workers         int      `json:"workers"`
+	Running         bool     `json:"running"`
+	Pending         []string `json:"pending"`
+}
+
+// New creates a debug server
+func New(config Config) (*Server, error) {
+	if config.Token == "" {
+		return nil, ErrNoToken
+	}
+
+	s := &Server{config: config}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPrefix+"pprof/", pprof.Index)
+	mux.HandleFunc(PathPrefix+"pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc(PathPrefix+"pprof/profile", pprof.Profile)
+	mux.HandleFunc(PathPrefix+"pprof/symbol", pprof.Symbol)
+	mux.HandleFunc(PathPrefix+"pprof/trace", pprof.Trace)
+	mux.HandleFunc(PathPrefix+"goroutines", s.handleGoroutines)
+	mux.HandleFunc(PathPrefix+"router", s.handleRouter)
+	mux.HandleFunc(PathPrefix+"async", s.handleAsync)
+	mux.HandleFunc(PathPrefix+"connections", s.handleConnections)
+	mux.HandleFunc(PathPrefix+"logs", s.handleLogs)
+	mux.HandleFunc(PathPrefix+"memory", s.handleMemory)
+
+	s.handler = s.authenticate(mux)
+	return s, nil
+}
+
+// Handler returns the authenticated debug handler
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// ListenAndServe serves the debug endpoints on addr until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// authenticate rejects requests without the configured bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get(TokenQueryParam)
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="debug"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGoroutines writes the stacks of all goroutines
+func (s *Server) handleGoroutines(w http.ResponseWriter, r *http.Request) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf)
+}
+
+// handleRouter dumps router registrations
+func (s *Server) handleRouter(w http.ResponseWriter, r *http.Request) {
+	rt := s.config.Router
+	if rt == nil && s.config.AsyncRouter != nil {
+		rt = s.config.AsyncRouter.Router
+	}
+	if rt == nil {
+		http.Error(w, "router not configured", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, RouterDump{
+		Methods:             rt.GetRegisteredMethods(),
+		NotificationMethods: rt.GetRegisteredNotificationMethods(),
+	})
+}
+
+// handleAsync dumps the async router queue
+func (s *Server) handleAsync(w http.ResponseWriter, r *http.Request) {
+	ar := s.config.AsyncRouter
+	if ar == nil {
+		http.Error(w, "async router not configured", http.StatusNotFound)
+		return
+	}
+
+	stats := ar.Stats()
+	writeJSON(w, AsyncDump{
+		QueuedRequests:  stats.QueuedRequests,
+		QueueCapacity:   stats.QueueCapacity,
+		PendingRequests: stats.PendingRequests,
+		Workers:         stats.Workers,
+		Running:         stats.Running,
+		Pending:         ar.PendingCorrelationIDs(),
+	})
+}
+
+// handleConnections dumps the connection table
+func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
+	if s.config.Connections == nil {
+		http.Error(w, "connection manager not configured", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, s.config.Connections.Snapshot())
+}
+
+// handleMemory dumps memory guard statistics
+func (s *Server) handleMemory(w http.ResponseWriter, r *http.Request) {
+	if s.config.Guard == nil {
+		http.Error(w, "memory guard not configured", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, s.config.Guard.Stats())
+}
+
+// handleLogs writes the buffered log records
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if s.config.Logs == nil {
+		http.Error(w, "log buffer not configured", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	s.config.Logs.WriteTo(w)
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,91 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/memguard"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+func TestNew_RequiresToken(t *testing.T) {
+	_, err := New(Config{})
+	assert.ErrorIs(t, err, ErrNoToken)
+}
+
+func TestServer(t *testing.T) {
+	r := router.New()
+	r.RegisterFunc("tools/list", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(nil, req.ID)
+	})
+
+	ar := router.NewAsyncRouter(router.AsyncRouterConfig{Router: r, Workers: 1, QueueSize: 4})
+	conns := connection.NewManager(time.Second)
+	_, err := conns.CreateConnection("conn-1")
+	require.NoError(t, err)
+
+	guard := memguard.New(memguard.Config{Limit: 1000})
+	require.Nil(t, guard.Acquire(100, memguard.PriorityNormal))
+
+	srv, err := New(Config{Token: "s3cret", AsyncRouter: ar, Connections: conns, Guard: guard})
+	require.NoError(t, err)
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rejects missing and wrong tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, get("/debug/router", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, get("/debug/router", "wrong").Code)
+		assert.Equal(t, http.StatusOK, get("/debug/router?token=s3cret", "").Code)
+	})
+
+	t.Run("router", func(t *testing.T) {
+		var dump RouterDump
+		require.NoError(t, json.Unmarshal(get("/debug/router", "s3cret").Body.Bytes(), &dump))
+		assert.Equal(t, []string{"tools/list"}, dump.Methods)
+	})
+
+	t.Run("async", func(t *testing.T) {
+		var dump AsyncDump
+		require.NoError(t, json.Unmarshal(get("/debug/async", "s3cret").Body.Bytes(), &dump))
+		assert.Equal(t, 4, dump.QueueCapacity)
+		assert.False(t, dump.Running)
+	})
+
+	t.Run("connections", func(t *testing.T) {
+		var infos []connection.ConnectionInfo
+		require.NoError(t, json.Unmarshal(get("/debug/connections", "s3cret").Body.Bytes(), &infos))
+		require.Len(t, infos, 1)
+		assert.Equal(t, "conn-1", infos[0].ID)
+		assert.Equal(t, "New", infos[0].State)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		var stats memguard.Stats
+		require.NoError(t, json.Unmarshal(get("/debug/memory", "s3cret").Body.Bytes(), &stats))
+		assert.Equal(t, int64(1000), stats.Limit)
+		assert.Equal(t, int64(100), stats.Used)
+	})
+
+	t.Run("goroutines and pprof", func(t *testing.T) {
+		assert.True(t, strings.Contains(get("/debug/goroutines", "s3cret").Body.String(), "goroutine"))
+		assert.Equal(t, http.StatusOK, get("/debug/pprof/heap", "s3cret").Code)
+	})
+}
@@ -0,0 +1,355 @@
+// Package delivery gives critical notifications, such as list_changed and
+// resources/updated, at-least-once delivery to clients that acknowledge
+// them.
+//
+// A client opts in by sending a resume token of its choosing in the
+// _meta.resumeToken of initialize. Every tracked notification sent to it
+// then carries a dedupe key in _meta.dedupeKey and stays in the client's
+// Outbox until the client lists the key in a notifications/delivered
+// notification. When the client reconnects with the same token, the
+// notifications it did not acknowledge are sent again with their original
+// keys, so it can drop those it already processed.
+//
+// A newer notification supersedes a pending one about the same thing, e.g.
+// a second tools/list_changed or a resources/updated for the same URI, so
+// the outbox of a client holds at most one notification per topic.
+// Changes made while no connection of the client is open are not recorded:
+// a client reconnecting lists tools and resources again anyway.
+//
+// Basic usage:
+//
+//	outbox := delivery.New(delivery.Config{Window: 10 * time.Minute})
+//	srv := server.New(hs, server.Config{Delivery: outbox})
+//
+// Set Config.Store to keep outboxes across restarts.
+package delivery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/storage"
+)
+
+const (
+	// MetaResumeToken is the _meta key of initialize naming the outbox of
+	// the client
+	MetaResumeToken = "resumeToken"
+
+	// MetaDedupeKey is the _meta key identifying a tracked notification
+	MetaDedupeKey = "dedupeKey"
+
+	// MethodDelivered is the notification a client acknowledges tracked
+	// notifications with, listing their keys in params.dedupeKeys
+	MethodDelivered = "notifications/delivered"
+
+	// StorageBucket is the store bucket holding one outbox per client
+	StorageBucket = "delivery"
+)
+
+// DefaultMethods are the notifications tracked when Config.Methods is empty
+var DefaultMethods = []string{
+	mcpgo.MethodNotificationToolsListChanged,
+	mcpgo.MethodNotificationResourcesListChanged,
+	mcpgo.MethodNotificationPromptsListChanged,
+	mcpgo.MethodNotificationResourceUpdated,
+}
+
+// Config contains configuration for an Outbox
+type Config struct {
+	// Methods lists the tracked notifications (defaults to DefaultMethods)
+	Methods []string
+
+	// Window is how long the outbox of a client without a connection is
+	// kept for it to reconnect (defaults to 10m)
+	Window time.Duration
+
+	// MaxPending bounds the notifications kept per client; the oldest are
+	// dropped beyond it (defaults to 256)
+	MaxPending int
+
+	// Store persists outboxes across restarts (nil keeps them in memory)
+	Store storage.Store
+
+	// Now returns the current time (defaults to time.Now)
+	Now func() time.Time
+}
+
+// Stats contains outbox statistics
+type Stats struct {
+	Tracked      int64 `json:"tracked"`
+	Acknowledged int64 `json:"acknowledged"`
+	Resent       int64 `json:"resent"`
+	Dropped      int64 `json:"dropped"`
+	Expired      int64 `json:"expired"`
+}
+
+// pending is a tracked notification not acknowledged yet
+type pending struct {
+	Key     string          `json:"key"`
+	Topic   string          `json:"topic"`
+	Message json.RawMessage `json:"message"`
+}
+
+// box is the stored outbox of one client
+type box struct {
+	Next    uint64    `json:"next"`
+	Pending []pending `json:"pending,omitempty"`
+
+	// Detached is when the last connection of the client closed; zero
+	// while one is open
+	Detached time.Time `json:"detached,omitzero"`
+}
+
+// Outbox keeps tracked notifications per client until they are
+// acknowledged
+type Outbox struct {
+	config  Config
+	methods map[string]bool
+
+	tracked      atomic.Int64
+	acknowledged atomic.Int64
+	resent       atomic.Int64
+	dropped      atomic.Int64
+	expired      atomic.Int64
+}
+
+// New creates an outbox
+func New(config Config) *Outbox {
+	if len(config.Methods) == 0 {
+		config.Methods = DefaultMethods
+	}
+	if config.Window <= 0 {
+		config.Window = 10 * time.Minute
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = 256
+	}
+	if config.Store == nil {
+		config.Store = storage.NewMemoryStore()
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	o := &Outbox{config: config, methods: make(map[string]bool, len(config.Methods))}
+	for _, m := range config.Methods {
+		o.methods[m] = true
+	}
+	return o
+}
+
+// Tracks reports whether notifications of method are tracked
+func (o *Outbox) Tracks(method string) bool {
+	return o.methods[method]
+}
+
+// Attach marks the client of token connected and returns the notifications
+// it did not acknowledge, oldest first, to be sent again. An outbox left
+// without a connection for longer than the window starts over empty.
+func (o *Outbox) Attach(ctx context.Context, token string) ([]json.RawMessage, error) {
+	var messages []json.RawMessage
+	err := o.update(ctx, token, func(b *box) {
+		if o.expiredBox(b) {
+			o.expired.Add(1)
+			*b = box{Next: b.Next}
+		}
+		b.Detached = time.Time{}
+		for _, p := range b.Pending {
+			messages = append(messages, p.Message)
+		}
+	})
+	o.resent.Add(int64(len(messages)))
+	return messages, err
+}
+
+// Detach marks the client of token disconnected, starting its window, and
+// removes the outboxes whose window has passed
+func (o *Outbox) Detach(ctx context.Context, token string) error {
+	err := o.update(ctx, token, func(b *box) { b.Detached = o.config.Now() })
+	return errors.Join(err, o.Sweep(ctx))
+}
+
+// Track records notification for the client of token and returns it with
+// its dedupe key in _meta. Notifications of untracked methods are returned
+// unchanged.
+func (o *Outbox) Track(ctx context.Context, token string, notification mcpgo.JSONRPCNotification) (mcpgo.JSONRPCNotification, error) {
+	if !o.Tracks(notification.Method) {
+		return notification, nil
+	}
+
+	topic := topicOf(notification)
+	var encodeErr error
+	err := o.update(ctx, token, func(b *box) {
+		b.Next++
+		key := strconv.FormatUint(b.Next, 10)
+
+		// The original is shared with other sessions when it is broadcast
+		meta := make(map[string]any, len(notification.Params.Meta)+1)
+		for k, v := range notification.Params.Meta {
+			meta[k] = v
+		}
+		meta[MetaDedupeKey] = key
+		notification.Params.Meta = meta
+
+		message, err := json.Marshal(notification)
+		if err != nil {
+			encodeErr = err
+			return
+		}
+		b.Pending = slices.DeleteFunc(b.Pending, func(p pending) bool { return p.Topic == topic })
+		b.Pending = append(b.Pending, pending{Key: key, Topic: topic, Message: message})
+		if excess := len(b.Pending) - o.config.MaxPending; excess > 0 {
+			o.dropped.Add(int64(excess))
+			b.Pending = slices.Delete(b.Pending, 0, excess)
+		}
+	})
+	if err = errors.Join(err, encodeErr); err != nil {
+		return notification, err
+	}
+	o.tracked.Add(1)
+	return notification, nil
+}
+
+// Ack removes the notifications with the given dedupe keys from the outbox
+// of token, returning how many were pending
+func (o *Outbox) Ack(ctx context.Context, token string, keys []string) (int, error) {
+	removed := 0
+	err := o.update(ctx, token, func(b *box) {
+		before := len(b.Pending)
+		b.Pending = slices.DeleteFunc(b.Pending, func(p pending) bool { return slices.Contains(keys, p.Key) })
+		removed = before - len(b.Pending)
+	})
+	o.acknowledged.Add(int64(removed))
+	return removed, err
+}
+
+// Pending returns the number of notifications the client of token did not
+// acknowledge
+func (o *Outbox) Pending(ctx context.Context, token string) (int, error) {
+	var b box
+	err := storage.GetJSON(ctx, o.config.Store, StorageBucket, storageKey(token), &b)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	return len(b.Pending), err
+}
+
+// Sweep removes the outboxes left without a connection for longer than the
+// window
+func (o *Outbox) Sweep(ctx context.Context) error {
+	entries, err := o.config.Store.List(ctx, StorageBucket, "")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		var b box
+		if err := json.Unmarshal(entry.Value, &b); err != nil || !o.expiredBox(&b) {
+			continue
+		}
+		// Delete only if no connection attached meanwhile
+		err := o.config.Store.Update(ctx, StorageBucket, entry.Key, func(value []byte, found bool) ([]byte, error) {
+			var current box
+			if !found || json.Unmarshal(value, &current) != nil || !o.expiredBox(&current) {
+				return value, nil
+			}
+			o.expired.Add(1)
+			return nil, nil
+		})
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns outbox statistics
+func (o *Outbox) Stats() Stats {
+	return Stats{
+		Tracked:      o.tracked.Load(),
+		Acknowledged: o.acknowledged.Load(),
+		Resent:       o.resent.Load(),
+		Dropped:      o.dropped.Load(),
+		Expired:      o.expired.Load(),
+	}
+}
+
+// expiredBox reports whether b was left without a connection for longer
+// than the window
+func (o *Outbox) expiredBox(b *box) bool {
+	return !b.Detached.IsZero() && o.config.Now().Sub(b.Detached) > o.config.Window
+}
+
+// update atomically modifies the stored outbox of token, creating it if
+// needed
+func (o *Outbox) update(ctx context.Context, token string, modify func(b *box)) error {
+	err := o.config.Store.Update(ctx, StorageBucket, storageKey(token), func(value []byte, found bool) ([]byte, error) {
+		var b box
+		if found {
+			if err := json.Unmarshal(value, &b); err != nil {
+				// A corrupt outbox only costs re-sends; start over
+				logging.Default().WithComponent("delivery").Warn(ctx, "Discarding unreadable outbox: "+err.Error())
+				b = box{}
+			}
+		}
+		modify(&b)
+		return json.Marshal(&b)
+	})
+	if err != nil {
+		return fmt.Errorf("delivery: %w", err)
+	}
+	return nil
+}
+
+// storageKey keys the outbox of token by its digest, so stores never hold
+// resume tokens
+func storageKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// topicOf returns what notification is about; a newer notification on the
+// same topic supersedes a pending one
+func topicOf(notification mcpgo.JSONRPCNotification) string {
+	if uri, ok := notification.Params.AdditionalFields["uri"].(string); ok {
+		return notification.Method + " " + uri
+	}
+	return notification.Method
+}
+
+// ResumeToken returns the resume token in the params of an initialize
+// request, or "" when the client did not opt in
+func ResumeToken(params json.RawMessage) string {
+	var p struct {
+		Meta struct {
+			Token string `json:"resumeToken"`
+		} `json:"_meta"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &p) != nil {
+		return ""
+	}
+	return p.Meta.Token
+}
+
+// DeliveredKeys returns the dedupe keys acknowledged by the params of a
+// notifications/delivered notification
+func DeliveredKeys(params json.RawMessage) []string {
+	var p struct {
+		Keys []string `json:"dedupeKeys"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &p) != nil {
+		return nil
+	}
+	return p.Keys
+}
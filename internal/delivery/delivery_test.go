@@ -0,0 +1,174 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/storage"
+)
+
+func notification(method string, fields map[string]any) mcpgo.JSONRPCNotification {
+	return mcpgo.JSONRPCNotification{
+		JSONRPC: mcpgo.JSONRPC_VERSION,
+		Notification: mcpgo.Notification{
+			Method: method,
+			Params: mcpgo.NotificationParams{AdditionalFields: fields},
+		},
+	}
+}
+
+// dedupeKeys returns the dedupe keys of the encoded notifications
+func dedupeKeys(t *testing.T, messages []json.RawMessage) []string {
+	t.Helper()
+	keys := make([]string, 0, len(messages))
+	for _, message := range messages {
+		var n struct {
+			Params struct {
+				Meta map[string]any `json:"_meta"`
+			} `json:"params"`
+		}
+		require.NoError(t, json.Unmarshal(message, &n))
+		keys = append(keys, n.Params.Meta[MetaDedupeKey].(string))
+	}
+	return keys
+}
+
+func TestOutbox_TrackAckResend(t *testing.T) {
+	ctx := context.Background()
+	o := New(Config{})
+
+	messages, err := o.Attach(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+
+	original := notification(mcpgo.MethodNotificationToolsListChanged, nil)
+	tracked, err := o.Track(ctx, "client-1", original)
+	require.NoError(t, err)
+	assert.Equal(t, "1", tracked.Params.Meta[MetaDedupeKey])
+	assert.Nil(t, original.Params.Meta, "the broadcast notification must not change")
+
+	updated, err := o.Track(ctx, "client-1", notification(mcpgo.MethodNotificationResourceUpdated, map[string]any{"uri": "file:///a"}))
+	require.NoError(t, err)
+	assert.Equal(t, "2", updated.Params.Meta[MetaDedupeKey])
+
+	// Untracked notifications pass through
+	progress, err := o.Track(ctx, "client-1", notification("notifications/progress", nil))
+	require.NoError(t, err)
+	assert.Nil(t, progress.Params.Meta)
+
+	pending, err := o.Pending(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pending)
+
+	removed, err := o.Ack(ctx, "client-1", []string{"1", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	require.NoError(t, o.Detach(ctx, "client-1"))
+	messages, err = o.Attach(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, dedupeKeys(t, messages))
+
+	assert.Equal(t, Stats{Tracked: 2, Acknowledged: 1, Resent: 1}, o.Stats())
+}
+
+func TestOutbox_Supersedes(t *testing.T) {
+	ctx := context.Background()
+	o := New(Config{})
+
+	for _, n := range []mcpgo.JSONRPCNotification{
+		notification(mcpgo.MethodNotificationToolsListChanged, nil),
+		notification(mcpgo.MethodNotificationResourceUpdated, map[string]any{"uri": "file:///a"}),
+		notification(mcpgo.MethodNotificationResourceUpdated, map[string]any{"uri": "file:///b"}),
+		notification(mcpgo.MethodNotificationToolsListChanged, nil),
+		notification(mcpgo.MethodNotificationResourceUpdated, map[string]any{"uri": "file:///a"}),
+	} {
+		_, err := o.Track(ctx, "client-1", n)
+		require.NoError(t, err)
+	}
+
+	messages, err := o.Attach(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "4", "5"}, dedupeKeys(t, messages))
+}
+
+func TestOutbox_MaxPending(t *testing.T) {
+	ctx := context.Background()
+	o := New(Config{MaxPending: 2})
+
+	for _, uri := range []string{"file:///a", "file:///b", "file:///c"} {
+		_, err := o.Track(ctx, "client-1", notification(mcpgo.MethodNotificationResourceUpdated, map[string]any{"uri": uri}))
+		require.NoError(t, err)
+	}
+
+	messages, err := o.Attach(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, dedupeKeys(t, messages))
+	assert.Equal(t, int64(1), o.Stats().Dropped)
+}
+
+func TestOutbox_Window(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := storage.NewMemoryStore()
+	o := New(Config{Window: time.Minute, Store: store, Now: func() time.Time { return now }})
+
+	for _, token := range []string{"client-1", "client-2"} {
+		_, err := o.Track(ctx, token, notification(mcpgo.MethodNotificationToolsListChanged, nil))
+		require.NoError(t, err)
+	}
+	require.NoError(t, o.Detach(ctx, "client-1"))
+
+	// Within the window the client resumes where it left off
+	now = now.Add(30 * time.Second)
+	messages, err := o.Attach(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Len(t, messages, 1)
+	require.NoError(t, o.Detach(ctx, "client-1"))
+
+	// Past it, the outbox is gone; attached outboxes are kept
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, o.Sweep(ctx))
+	entries, err := store.List(ctx, StorageBucket, "")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, int64(1), o.Stats().Expired)
+
+	messages, err = o.Attach(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestOutbox_Store(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+
+	_, err := New(Config{Store: store}).Track(ctx, "secret-token", notification(mcpgo.MethodNotificationPromptsListChanged, nil))
+	require.NoError(t, err)
+
+	// Outboxes are keyed by a digest, never by the token itself
+	entries, err := store.List(ctx, StorageBucket, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].Key, "secret-token")
+
+	// A new outbox over the same store resumes the client
+	messages, err := New(Config{Store: store}).Attach(ctx, "secret-token")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, dedupeKeys(t, messages))
+}
+
+func TestParams(t *testing.T) {
+	assert.Equal(t, "abc", ResumeToken(json.RawMessage(`{"protocolVersion":"2025-03-26","_meta":{"resumeToken":"abc"}}`)))
+	assert.Empty(t, ResumeToken(json.RawMessage(`{"protocolVersion":"2025-03-26"}`)))
+	assert.Empty(t, ResumeToken(nil))
+
+	assert.Equal(t, []string{"1", "2"}, DeliveredKeys(json.RawMessage(`{"dedupeKeys":["1","2"]}`)))
+	assert.Empty(t, DeliveredKeys(json.RawMessage(`{"dedupeKeys":"1"}`)))
+}
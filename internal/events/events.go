@@ -0,0 +1,235 @@
+// Package events is an in-process event bus. Subsystems publish typed
+// events to topics without knowing who reacts to them; subscribers such as
+// metrics, auditing or client notifications consume them asynchronously.
+//
+// Every subscription has its own queue and goroutine, so publishing never
+// blocks: when a subscriber falls behind, events for it are dropped and
+// counted rather than slowing down the code path that published them.
+//
+// Basic usage:
+//
+//	bus := events.New()
+//	defer bus.Close()
+//
+//	sub := events.Subscribe(bus, events.UpstreamHealth, func(e events.UpstreamHealthEvent) {
+//		log.Printf("%s is %s", e.Upstream, e.State)
+//	})
+//	defer sub.Unsubscribe()
+//
+//	events.Publish(bus, events.UpstreamHealth, events.UpstreamHealthEvent{Upstream: "fs", State: "connected"})
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// queueSize is how many events a subscription holds before dropping
+const queueSize = 256
+
+// Topic names a stream of events of type E
+type Topic[E any] struct {
+	name string
+}
+
+// NewTopic creates a topic; topics are compared by name
+func NewTopic[E any](name string) Topic[E] {
+	return Topic[E]{name: name}
+}
+
+// Name returns the name of the topic
+func (t Topic[E]) Name() string {
+	return t.name
+}
+
+// TopicStats counts the activity of a topic
+type TopicStats struct {
+	Published   int64 `json:"published"`
+	Dropped     int64 `json:"dropped"`
+	Subscribers int   `json:"subscribers"`
+}
+
+// Bus delivers published events to the subscribers of their topic
+type Bus struct {
+	mu     sync.RWMutex
+	topics map[string]*topic
+	closed bool
+}
+
+// topic holds the subscriptions and counters of a topic
+type topic struct {
+	subscriptions []*Subscription
+	published     atomic.Int64
+	dropped       atomic.Int64
+}
+
+// Subscription is a subscriber's registration on a topic
+type Subscription struct {
+	bus     *Bus
+	topic   string
+	queue   chan any
+	handler func(any)
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New creates an event bus
+func New() *Bus {
+	return &Bus{topics: make(map[string]*topic)}
+}
+
+// Publish queues event for every subscriber of t. It never blocks; events
+// for subscribers whose queue is full are dropped. Publishing to a nil or
+// closed bus does nothing.
+func Publish[E any](b *Bus, t Topic[E], event E) {
+	if b == nil {
+		return
+	}
+	tp := b.topic(t.name)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+
+	tp.published.Add(1)
+	for _, s := range tp.subscriptions {
+		select {
+		case s.queue <- event:
+		default:
+			tp.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe calls handler with every event published to t from now on, in
+// order, on a goroutine of the subscription
+func Subscribe[E any](b *Bus, t Topic[E], handler func(E)) *Subscription {
+	s := &Subscription{
+		bus:     b,
+		topic:   t.name,
+		queue:   make(chan any, queueSize),
+		handler: func(event any) { handler(event.(E)) },
+		done:    make(chan struct{}),
+	}
+
+	tp := b.topic(t.name)
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(s.done)
+		return s
+	}
+	tp.subscriptions = append(tp.subscriptions, s)
+	b.mu.Unlock()
+
+	go s.run()
+	return s
+}
+
+// Unsubscribe stops deliveries once the events already queued have been
+// handled, and waits for that
+func (s *Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	if tp, ok := s.bus.topics[s.topic]; ok {
+		for i, other := range tp.subscriptions {
+			if other == s {
+				tp.subscriptions = append(tp.subscriptions[:i:i], tp.subscriptions[i+1:]...)
+				break
+			}
+		}
+	}
+	s.bus.mu.Unlock()
+	s.stop()
+}
+
+// stop closes the queue once and waits for run to drain it
+func (s *Subscription) stop() {
+	s.once.Do(func() { close(s.queue) })
+	<-s.done
+}
+
+// run handles queued events until the queue is closed
+func (s *Subscription) run() {
+	defer close(s.done)
+	for event := range s.queue {
+		s.handler(event)
+	}
+}
+
+// Stats returns the counters of every topic that has been subscribed to
+// or published on, by name
+func (b *Bus) Stats() map[string]TopicStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stats := make(map[string]TopicStats, len(b.topics))
+	for name, tp := range b.topics {
+		stats[name] = TopicStats{
+			Published:   tp.published.Load(),
+			Dropped:     tp.dropped.Load(),
+			Subscribers: len(tp.subscriptions),
+		}
+	}
+	return stats
+}
+
+// Close stops every subscription after its queued events have been
+// handled; later events are discarded
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	var subscriptions []*Subscription
+	for _, tp := range b.topics {
+		subscriptions = append(subscriptions, tp.subscriptions...)
+		tp.subscriptions = nil
+	}
+	b.mu.Unlock()
+
+	for _, s := range subscriptions {
+		s.stop()
+	}
+}
+
+// topic returns the topic name, registering it so it appears in Stats
+// before anyone subscribes
+func (b *Bus) topic(name string) *topic {
+	b.mu.RLock()
+	tp, ok := b.topics[name]
+	b.mu.RUnlock()
+	if ok {
+		return tp
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if tp, ok = b.topics[name]; !ok {
+		tp = &topic{}
+		b.topics[name] = tp
+	}
+	return tp
+}
+
+// Global bus instance
+var defaultBus = New()
+
+// SetDefault sets the default global bus
+func SetDefault(b *Bus) {
+	defaultBus = b
+}
+
+// Default returns the default global bus
+func Default() *Bus {
+	return defaultBus
+}
+
+// Or returns b, or the default bus if b is nil
+func Or(b *Bus) *Bus {
+	if b == nil {
+		return Default()
+	}
+	return b
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	var mu sync.Mutex
+	var received []string
+	sub := Subscribe(bus, UpstreamHealth, func(e UpstreamHealthEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e.Upstream+":"+e.State)
+	})
+	// Other topics are not delivered to the subscription
+	Publish(bus, QuotaExceeded, QuotaExceededEvent{Identity: "bot"})
+	Publish(bus, UpstreamHealth, UpstreamHealthEvent{Upstream: "fs", State: "connected"})
+	Publish(bus, UpstreamHealth, UpstreamHealthEvent{Upstream: "fs", State: "disconnected"})
+	sub.Unsubscribe()
+
+	// Unsubscribing waits for queued events
+	assert.Equal(t, []string{"fs:connected", "fs:disconnected"}, received)
+	Publish(bus, UpstreamHealth, UpstreamHealthEvent{Upstream: "fs", State: "connected"})
+	assert.Len(t, received, 2)
+
+	stats := bus.Stats()
+	assert.Equal(t, TopicStats{Published: 3}, stats[UpstreamHealth.Name()])
+	assert.Equal(t, TopicStats{Published: 1}, stats[QuotaExceeded.Name()])
+}
+
+func TestBus_DropsWhenSubscriberFallsBehind(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 1)
+	Subscribe(bus, Connections, func(e ConnectionEvent) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-block
+	})
+
+	Publish(bus, Connections, ConnectionEvent{ID: "first"})
+	<-started
+	for i := 0; i < queueSize+10; i++ {
+		Publish(bus, Connections, ConnectionEvent{ID: "next"})
+	}
+	close(block)
+
+	stats := bus.Stats()[Connections.Name()]
+	assert.Equal(t, int64(queueSize+11), stats.Published)
+	assert.Equal(t, int64(10), stats.Dropped)
+	assert.Equal(t, 1, stats.Subscribers)
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := New()
+	done := make(chan ConfigReloadEvent, 1)
+	Subscribe(bus, ConfigReloads, func(e ConfigReloadEvent) { done <- e })
+
+	Publish(bus, ConfigReloads, ConfigReloadEvent{Changed: []string{"server"}})
+	bus.Close()
+	select {
+	case e := <-done:
+		assert.Equal(t, []string{"server"}, e.Changed)
+	case <-time.After(time.Second):
+		t.Fatal("queued event not handled before Close returned")
+	}
+
+	// Publishing and subscribing after Close do nothing
+	Publish(bus, ConfigReloads, ConfigReloadEvent{})
+	sub := Subscribe(bus, ConfigReloads, func(e ConfigReloadEvent) { t.Error("unexpected event") })
+	sub.Unsubscribe()
+	require.Equal(t, int64(1), bus.Stats()[ConfigReloads.Name()].Published)
+
+	// A nil bus is a no-op
+	Publish(nil, ConfigReloads, ConfigReloadEvent{})
+}
+
+func TestOr(t *testing.T) {
+	bus := New()
+	assert.Same(t, bus, Or(bus))
+	assert.Same(t, Default(), Or(nil))
+}
@@ -0,0 +1,60 @@
+package events
+
+import "time"
+
+// Topics published by the server's subsystems
+var (
+	// Connections carries client connections opening and closing
+	Connections = NewTopic[ConnectionEvent]("connections")
+
+	// UpstreamHealth carries upstream state changes
+	UpstreamHealth = NewTopic[UpstreamHealthEvent]("upstream_health")
+
+	// ConfigReloads carries configuration reload attempts
+	ConfigReloads = NewTopic[ConfigReloadEvent]("config_reloads")
+
+	// QuotaExceeded carries requests rejected by a usage quota
+	QuotaExceeded = NewTopic[QuotaExceededEvent]("quota_exceeded")
+)
+
+// ConnectionEventType tells whether a connection opened or closed
+type ConnectionEventType string
+
+// Connection event types
+const (
+	ConnectionOpened ConnectionEventType = "opened"
+	ConnectionClosed ConnectionEventType = "closed"
+)
+
+// ConnectionEvent reports a client connection opening or closing
+type ConnectionEvent struct {
+	ID        string              `json:"id"`
+	Transport string              `json:"transport"`
+	Type      ConnectionEventType `json:"type"`
+	At        time.Time           `json:"at"`
+}
+
+// UpstreamHealthEvent reports an upstream changing state, e.g. from
+// "connected" to "disconnected"; Error is why it disconnected, if known
+type UpstreamHealthEvent struct {
+	Upstream string    `json:"upstream"`
+	State    string    `json:"state"`
+	Error    string    `json:"error,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// ConfigReloadEvent reports a configuration reload. Changed lists the
+// sections that changed; Error is set when the reload was rejected.
+type ConfigReloadEvent struct {
+	Changed []string  `json:"changed,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// QuotaExceededEvent reports a request rejected because Identity used up
+// its quota for Window
+type QuotaExceededEvent struct {
+	Identity string    `json:"identity"`
+	Window   string    `json:"window"`
+	At       time.Time `json:"at"`
+}
@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+)
+
+// TransportSource reports the health of connections or transports by ID,
+// like transport.Manager and server.Server
+type TransportSource interface {
+	HealthCheck() map[string]transport.HealthStatus
+}
+
+// TransportCheck reports the health of every connection held by a transport
+// manager, or of every transport of a server. The check is degraded when
+// some of them are down and down when all of them are.
+func TransportCheck(source TransportSource) CheckFunc {
+	return func(ctx context.Context) Result {
+		statuses := source.HealthCheck()
+		if len(statuses) == 0 {
+			return Result{Status: StatusOK, Message: "no transports configured"}
+		}
+
+		details := make(map[string]interface{}, len(statuses))
+		var down []string
+		for id, s := range statuses {
+			healthy := s.Connected && (s.ProcessID == 0 || s.Running)
+			entry := map[string]interface{}{"connected": s.Connected}
+			if s.ProcessID != 0 {
+				entry["pid"] = s.ProcessID
+				entry["running"] = s.Running
+			}
+			if s.LastError != nil {
+				entry["lastError"] = s.LastError.Error()
+			}
+			details[id] = entry
+			if !healthy {
+				down = append(down, id)
+			}
+		}
+
+		return aggregate(len(statuses), down, details)
+	}
+}
+
+// UpstreamCheck reports upstream health from a function returning the
+// health of each upstream by name
+func UpstreamCheck(source func() map[string]bool) CheckFunc {
+	return func(ctx context.Context) Result {
+		upstreams := source()
+		if len(upstreams) == 0 {
+			return Result{Status: StatusOK, Message: "no upstreams configured"}
+		}
+
+		details := make(map[string]interface{}, len(upstreams))
+		var down []string
+		for name, healthy := range upstreams {
+			details[name] = healthy
+			if !healthy {
+				down = append(down, name)
+			}
+		}
+
+		return aggregate(len(upstreams), down, details)
+	}
+}
+
+// QueueCheck reports async router queue saturation. The check is degraded
+// once the queue is fuller than threshold (0-1) and down when it is full or
+// the router is not running.
+func QueueCheck(ar *router.AsyncRouter, threshold float64) CheckFunc {
+	return func(ctx context.Context) Result {
+		stats := ar.Stats()
+		details := map[string]interface{}{
+			"queued":   stats.QueuedRequests,
+			"capacity": stats.QueueCapacity,
+			"pending":  stats.PendingRequests,
+			"workers":  stats.Workers,
+		}
+
+		if !stats.Running {
+			return Result{Status: StatusDown, Message: "async router not running", Details: details}
+		}
+
+		saturation := 0.0
+		if stats.QueueCapacity > 0 {
+			saturation = float64(stats.QueuedRequests) / float64(stats.QueueCapacity)
+		}
+		details["saturation"] = saturation
+
+		switch {
+		case saturation >= 1:
+			return Result{Status: StatusDown, Message: "queue full", Details: details}
+		case saturation >= threshold:
+			return Result{Status: StatusDegraded, Message: fmt.Sprintf("queue %.0f%% full", saturation*100), Details: details}
+		default:
+			return Result{Status: StatusOK, Details: details}
+		}
+	}
+}
+
+// ErrorBudgetCheck reports down while recorder has an error budget
+// exceeded, so that load balancers stop sending traffic until the error
+// rate has fallen back within budget over the recorder window
+func ErrorBudgetCheck(recorder *mcperrors.Recorder) CheckFunc {
+	return func(ctx context.Context) Result {
+		snapshot := recorder.Snapshot()
+		details := map[string]interface{}{
+			"requests": snapshot.Requests,
+			"errors":   snapshot.Errors,
+			"rate":     snapshot.Rates[mcperrors.CategoryAll],
+		}
+
+		exceeded := recorder.Exceeding()
+		if len(exceeded) == 0 {
+			return Result{Status: StatusOK, Details: details}
+		}
+		categories := make([]string, len(exceeded))
+		for i, event := range exceeded {
+			categories[i] = event.Category
+		}
+		return Result{Status: StatusDown, Message: fmt.Sprintf("error budget exceeded: %v", categories), Details: details}
+	}
+}
+
+// aggregate builds a result from the number of components and those down
+func aggregate(total int, down []string, details map[string]interface{}) Result {
+	sort.Strings(down)
+	switch {
+	case len(down) == 0:
+		return Result{Status: StatusOK, Details: details}
+	case len(down) == total:
+		return Result{Status: StatusDown, Message: fmt.Sprintf("all down: %v", down), Details: details}
+	default:
+		return Result{Status: StatusDegraded, Message: fmt.Sprintf("down: %v", down), Details: details}
+	}
+}
@@ -0,0 +1,258 @@
+// Package health provides liveness and readiness reporting for the server.
+//
+// A Health value aggregates named checks. Liveness checks answer "is the
+// process able to make progress"; readiness checks answer "should traffic
+// be routed here" and typically include transports, upstreams and queue
+// saturation. Reports are served over HTTP for container orchestration
+// probes and as the `health/check` MCP method.
+//
+// Basic usage:
+//
+//	h := health.New(health.Config{ConfigVersion: "3"})
+//	h.AddReadinessCheck("transports", health.TransportCheck(srv))
+//	h.AddReadinessCheck("upstreams", health.UpstreamCheck(srv.UpstreamHealth))
+//	h.AddReadinessCheck("queue", health.QueueCheck(srv.Async(), 0.9))
+//
+//	mux := http.NewServeMux()
+//	h.Register(mux)
+//	r.Register(health.Method, h.Handler())
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// Method is the MCP admin method reporting health
+const Method = "health/check"
+
+// HTTP probe paths
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// DefaultCheckTimeout bounds how long a single check may run
+const DefaultCheckTimeout = 5 * time.Second
+
+// Status is the outcome of a check or report
+type Status string
+
+const (
+	// StatusOK indicates the component is healthy
+	StatusOK Status = "ok"
+	// StatusDegraded indicates the component works with reduced capacity
+	StatusDegraded Status = "degraded"
+	// StatusDown indicates the component is unavailable
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Status  Status                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// CheckFunc evaluates the health of one component
+type CheckFunc func(ctx context.Context) Result
+
+// Report aggregates check results
+type Report struct {
+	Status        Status            `json:"status"`
+	ConfigVersion string            `json:"configVersion,omitempty"`
+	Uptime        string            `json:"uptime"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Checks        map[string]Result `json:"checks"`
+}
+
+// Config contains configuration for Health
+type Config struct {
+	// ConfigVersion identifies the loaded configuration
+	ConfigVersion string
+
+	// CheckTimeout bounds each check (defaults to DefaultCheckTimeout)
+	CheckTimeout time.Duration
+}
+
+// Health aggregates liveness and readiness checks
+type Health struct {
+	mu            sync.RWMutex
+	liveness      map[string]CheckFunc
+	readiness     map[string]CheckFunc
+	configVersion string
+	checkTimeout  time.Duration
+	started       time.Time
+}
+
+// New creates a new Health aggregator
+func New(config Config) *Health {
+	if config.CheckTimeout <= 0 {
+		config.CheckTimeout = DefaultCheckTimeout
+	}
+	return &Health{
+		liveness:      make(map[string]CheckFunc),
+		readiness:     make(map[string]CheckFunc),
+		configVersion: config.ConfigVersion,
+		checkTimeout:  config.CheckTimeout,
+		started:       time.Now(),
+	}
+}
+
+// AddLivenessCheck registers a liveness check
+func (h *Health) AddLivenessCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness[name] = check
+}
+
+// AddReadinessCheck registers a readiness check
+func (h *Health) AddReadinessCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness[name] = check
+}
+
+// SetConfigVersion records the version of the loaded configuration
+func (h *Health) SetConfigVersion(version string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.configVersion = version
+}
+
+// Liveness runs the liveness checks
+func (h *Health) Liveness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := copyChecks(h.liveness)
+	h.mu.RUnlock()
+	return h.run(ctx, checks)
+}
+
+// Readiness runs the liveness and readiness checks
+func (h *Health) Readiness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := copyChecks(h.liveness)
+	for name, check := range h.readiness {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+	return h.run(ctx, checks)
+}
+
+// LivenessHandler serves the liveness report, answering 503 when down
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Liveness(r.Context()))
+	})
+}
+
+// ReadinessHandler serves the readiness report, answering 503 unless every
+// check is ok or degraded
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Readiness(r.Context()))
+	})
+}
+
+// Register installs the probe handlers on mux
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.Handle(LivenessPath, h.LivenessHandler())
+	mux.Handle(ReadinessPath, h.ReadinessHandler())
+}
+
+// Handler returns a router handler for the health/check method reporting
+// readiness
+func (h *Health) Handler() router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(h.Readiness(ctx), req.ID)
+	})
+}
+
+// run evaluates checks concurrently and aggregates their status
+func (h *Health) run(ctx context.Context, checks map[string]CheckFunc) Report {
+	h.mu.RLock()
+	version := h.configVersion
+	h.mu.RUnlock()
+
+	report := Report{
+		Status:        StatusOK,
+		ConfigVersion: version,
+		Uptime:        time.Since(h.started).Round(time.Second).String(),
+		Timestamp:     time.Now().UTC(),
+		Checks:        make(map[string]Result, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			result := h.runOne(ctx, check)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		report.Status = worst(report.Status, result.Status)
+	}
+
+	return report
+}
+
+// runOne evaluates a single check with a timeout
+func (h *Health) runOne(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.checkTimeout)
+	defer cancel()
+
+	done := make(chan Result, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		return Result{Status: StatusDown, Message: "check timed out"}
+	}
+}
+
+// writeReport writes report as JSON with a probe-friendly status code
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// worst returns the more severe of two statuses
+func worst(a, b Status) Status {
+	rank := map[Status]int{StatusOK: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// copyChecks returns a shallow copy of checks
+func copyChecks(checks map[string]CheckFunc) map[string]CheckFunc {
+	out := make(map[string]CheckFunc, len(checks))
+	for name, check := range checks {
+		out[name] = check
+	}
+	return out
+}
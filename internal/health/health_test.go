@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+func TestHealth_Probes(t *testing.T) {
+	h := New(Config{ConfigVersion: "v7"})
+	h.AddLivenessCheck("process", func(ctx context.Context) Result { return Result{Status: StatusOK} })
+
+	upstreams := map[string]bool{"github": true}
+	h.AddReadinessCheck("upstreams", UpstreamCheck(func() map[string]bool { return upstreams }))
+
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	get := func(path string) (int, Report) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		return rec.Code, report
+	}
+
+	code, report := get(ReadinessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, "v7", report.ConfigVersion)
+	assert.Len(t, report.Checks, 2)
+
+	upstreams["filesystem"] = false
+	code, report = get(ReadinessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusDegraded, report.Status)
+
+	upstreams["github"] = false
+	code, report = get(ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusDown, report.Status)
+
+	// Liveness ignores readiness checks
+	code, report = get(LivenessPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestHealth_CheckTimeout(t *testing.T) {
+	h := New(Config{CheckTimeout: 10 * time.Millisecond})
+	h.AddReadinessCheck("slow", func(ctx context.Context) Result {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		return Result{Status: StatusOK}
+	})
+
+	report := h.Readiness(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "check timed out", report.Checks["slow"].Message)
+}
+
+func TestQueueCheck(t *testing.T) {
+	ar := router.NewAsyncRouter(router.AsyncRouterConfig{Router: router.New(), Workers: 1, QueueSize: 10})
+	check := QueueCheck(ar, 0.8)
+
+	assert.Equal(t, StatusDown, check(context.Background()).Status)
+
+	require.NoError(t, ar.Start())
+	defer ar.Shutdown(context.Background())
+
+	result := check(context.Background())
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Equal(t, 10, result.Details["capacity"])
+}
+
+func TestErrorBudgetCheck(t *testing.T) {
+	now := time.Unix(1000, 0)
+	recorder := mcperrors.NewRecorder(mcperrors.RecorderConfig{
+		Window:  10 * time.Second,
+		Budgets: map[string]mcperrors.Budget{mcperrors.CategoryAll: {MaxRate: 0.5, MinRequests: 2}},
+		Now:     func() time.Time { return now },
+	})
+	check := ErrorBudgetCheck(recorder)
+
+	recorder.RecordSuccess()
+	recorder.RecordCode(mcperrors.ErrorCodeMCPToolError)
+	assert.Equal(t, StatusOK, check(context.Background()).Status)
+
+	recorder.RecordCode(mcperrors.ErrorCodeMCPToolError)
+	result := check(context.Background())
+	assert.Equal(t, StatusDown, result.Status)
+	assert.Contains(t, result.Message, "all")
+	assert.Equal(t, int64(2), result.Details["errors"])
+
+	// Ready again once the failures leave the window
+	now = now.Add(11 * time.Second)
+	assert.Equal(t, StatusOK, check(context.Background()).Status)
+}
+
+func TestHandler(t *testing.T) {
+	h := New(Config{ConfigVersion: "v1"})
+	resp := h.Handler().Handle(context.Background(), &jsonrpc.Request{ID: 1, Method: Method})
+
+	require.Nil(t, resp.Error)
+	report := resp.Result.(Report)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, "v1", report.ConfigVersion)
+}
@@ -0,0 +1,90 @@
+// Package ids generates time-ordered unique identifiers for requests the
+// server sends and for idempotency keys.
+//
+// Both formats start with a millisecond timestamp, so identifiers sort by
+// creation time and group well in logs and indexes:
+//
+//	ids.NewUUIDv7() // "01890a5d-ac96-774b-bcce-b302099a8057"
+//	ids.NewULID()   // "01H455VB4PEX5VSKNK084SN02Q"
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyMeta is the tools/call _meta field carrying the
+// idempotency key shared by a call and its retries
+const IdempotencyKeyMeta = "idempotencyKey"
+
+// Generator returns a new identifier on every call
+type Generator func() string
+
+// NewUUIDv7 returns an RFC 9562 version 7 UUID
+func NewUUIDv7() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// crockford is the ULID alphabet, Crockford's base32
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidState makes ULIDs generated within the same millisecond increase
+var ulidState struct {
+	mu      sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+// NewULID returns a ULID. ULIDs generated in the same millisecond by this
+// process are monotonic: the random part of the previous one is
+// incremented.
+func NewULID() string {
+	return newULID(time.Now())
+}
+
+// newULID returns a ULID for the time now
+func newULID(now time.Time) string {
+	ms := uint64(now.UnixMilli())
+
+	ulidState.mu.Lock()
+	if ms > ulidState.ms {
+		ulidState.ms = ms
+		rand.Read(ulidState.entropy[:])
+	} else {
+		// Same millisecond, or the clock went back: keep counting from the
+		// previous ULID
+		ms = ulidState.ms
+		for i := len(ulidState.entropy) - 1; i >= 0; i-- {
+			ulidState.entropy[i]++
+			if ulidState.entropy[i] != 0 {
+				break
+			}
+		}
+	}
+	var raw [16]byte
+	binary.BigEndian.PutUint16(raw[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(raw[2:6], uint32(ms))
+	copy(raw[6:], ulidState.entropy[:])
+	ulidState.mu.Unlock()
+
+	return encode(raw)
+}
+
+// encode writes the 128 bits of raw as 26 base32 characters, the first
+// carrying the top 3 bits
+func encode(raw [16]byte) string {
+	hi := binary.BigEndian.Uint64(raw[0:8])
+	lo := binary.BigEndian.Uint64(raw[8:16])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
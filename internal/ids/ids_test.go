@@ -0,0 +1,46 @@
+package ids
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUUIDv7(t *testing.T) {
+	id, err := uuid.Parse(NewUUIDv7())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+	assert.NotEqual(t, NewUUIDv7(), NewUUIDv7())
+}
+
+func TestNewULID(t *testing.T) {
+	ulidState.ms = 0
+	at := time.UnixMilli(1_700_000_000_000)
+	id := newULID(at)
+	require.Len(t, id, 26)
+	// The first 10 characters encode the timestamp
+	assert.Equal(t, "01HF7YAT00", id[:10])
+
+	// ULIDs of the same millisecond, or an earlier one, keep increasing
+	generated := []string{id, newULID(at), newULID(at), newULID(at.Add(-time.Second))}
+	assert.True(t, sort.StringsAreSorted(generated), generated)
+	for i := 1; i < len(generated); i++ {
+		assert.NotEqual(t, generated[i-1], generated[i])
+	}
+
+	later := newULID(at.Add(time.Millisecond))
+	assert.Greater(t, later, generated[len(generated)-1])
+}
+
+func TestEncode(t *testing.T) {
+	var raw [16]byte
+	assert.Equal(t, "00000000000000000000000000", encode(raw))
+	for i := range raw {
+		raw[i] = 0xff
+	}
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encode(raw))
+}
@@ -0,0 +1,324 @@
+// Package loadtest drives an MCP server with many concurrent clients to
+// measure latency and error rates under load.
+//
+// Each of Config.Connections clients dials its own connection, performs
+// the handshake and then sends requests one at a time, picking each from
+// a weighted mix of operations. Requests are paced across all clients to
+// the target rate, or sent back to back when no rate is set.
+//
+// Basic usage:
+//
+//	mix, _ := loadtest.ParseMix("tools/list=1,call:echo=4", nil)
+//	report, err := loadtest.Run(ctx, conformance.SocketDialer("unix", "/run/mcp.sock"), loadtest.Config{
+//		Connections: 50,
+//		Duration:    30 * time.Second,
+//		RPS:         2000,
+//		Mix:         mix,
+//	})
+//
+// cmd/mcpbench wraps the package as a command-line tool.
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/conformance"
+)
+
+// Operation is one kind of request in the load mix
+type Operation struct {
+	// Name labels the operation in reports
+	Name string
+
+	// Method and Params form the request
+	Method string
+	Params any
+
+	// Weight is the operation's share of the mix relative to the others
+	Weight int
+}
+
+// Config contains configuration for a load test
+type Config struct {
+	// Connections is the number of concurrent clients (defaults to 1)
+	Connections int
+
+	// Duration is how long requests are sent for (defaults to 10s)
+	Duration time.Duration
+
+	// RPS is the target rate across all connections; zero sends as fast
+	// as responses come back
+	RPS float64
+
+	// Mix is the weighted set of operations (defaults to tools/list)
+	Mix []Operation
+
+	// Timeout bounds each request (defaults to 10s)
+	Timeout time.Duration
+
+	// ProtocolVersion is proposed in the handshake (defaults to the
+	// latest version)
+	ProtocolVersion string
+}
+
+// Run runs a load test against the server. It fails only if no client
+// could connect; per-request failures are counted in the report.
+func Run(ctx context.Context, dial conformance.Dialer, config Config) (*Report, error) {
+	if config.Connections <= 0 {
+		config.Connections = 1
+	}
+	if config.Duration <= 0 {
+		config.Duration = 10 * time.Second
+	}
+	if len(config.Mix) == 0 {
+		config.Mix = []Operation{{Name: "tools/list", Method: "tools/list", Weight: 1}}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.ProtocolVersion == "" {
+		config.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	}
+	picker, err := newPicker(config.Mix)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := newRecorder(config.Mix)
+
+	// Connect everyone before the clock starts
+	clients := make([]*conformance.Client, config.Connections)
+	conns := make([]conformance.Conn, 0, config.Connections)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hctx, cancel := context.WithTimeout(ctx, config.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			conn, err := dial(hctx)
+			if err != nil {
+				rec.connectFailed(err)
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+
+			client := conformance.NewClient(conn, config.ProtocolVersion)
+			if _, err := client.Initialize(hctx); err != nil {
+				rec.connectFailed(err)
+				return
+			}
+			rec.handshake(time.Since(start))
+			clients[i] = client
+		}()
+	}
+	wg.Wait()
+	if rec.connected() == 0 {
+		return nil, fmt.Errorf("no client connected: %s", rec.firstConnectError())
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+	tokens := pace(runCtx, config.RPS)
+
+	started := time.Now()
+	for _, client := range clients {
+		if client == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(runCtx, client, picker, tokens, config.Timeout, rec)
+		}()
+	}
+	wg.Wait()
+	return rec.report(config, time.Since(started)), nil
+}
+
+// worker sends requests on one connection until ctx is done
+func worker(ctx context.Context, client *conformance.Client, picker *picker, tokens <-chan struct{}, timeout time.Duration, rec *recorder) {
+	for {
+		if tokens != nil {
+			select {
+			case <-tokens:
+			case <-ctx.Done():
+				return
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+
+		op := picker.pick()
+		callCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		response, err := client.Call(callCtx, op.Method, op.Params)
+		latency := time.Since(start)
+		cancel()
+
+		switch {
+		case err != nil:
+			// A broken or timed-out connection is out of step with its
+			// responses, so the client stops
+			rec.failure(op.index, err)
+			return
+		case response.Error != nil:
+			rec.errored(op.index, latency, response.Error)
+		default:
+			rec.success(op.index, latency)
+		}
+	}
+}
+
+// pace returns a channel yielding rps tokens a second until ctx is done,
+// or nil for no limit
+func pace(ctx context.Context, rps float64) <-chan struct{} {
+	if rps <= 0 {
+		return nil
+	}
+	tokens := make(chan struct{})
+	interval := time.Duration(float64(time.Second) / rps)
+	go func() {
+		start := time.Now()
+		for n := 0; ; n++ {
+			// Scheduling against the start time keeps the rate from
+			// drifting when a token is taken late
+			if wait := time.Until(start.Add(time.Duration(n) * interval)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return tokens
+}
+
+// weightedOperation is an operation with its index in the mix
+type weightedOperation struct {
+	Operation
+	index int
+}
+
+// picker picks operations at random by weight
+type picker struct {
+	ops   []weightedOperation
+	total int
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newPicker creates a picker for mix
+func newPicker(mix []Operation) (*picker, error) {
+	p := &picker{rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}
+	for i, op := range mix {
+		if op.Weight <= 0 {
+			return nil, fmt.Errorf("operation %s: weight must be positive", op.Name)
+		}
+		if op.Method == "" {
+			return nil, fmt.Errorf("operation %s: no method", op.Name)
+		}
+		p.ops = append(p.ops, weightedOperation{Operation: op, index: i})
+		p.total += op.Weight
+	}
+	return p, nil
+}
+
+// pick returns the next operation
+func (p *picker) pick() weightedOperation {
+	p.mu.Lock()
+	n := p.rand.IntN(p.total)
+	p.mu.Unlock()
+	for _, op := range p.ops {
+		if n < op.Weight {
+			return op
+		}
+		n -= op.Weight
+	}
+	return p.ops[len(p.ops)-1]
+}
+
+// ParseMix parses a comma-separated list of operations, each optionally
+// followed by "=weight" (default 1). An operation is a method name such as
+// "tools/list" or "ping", or "call:<tool>" for a tool call; args supplies
+// the arguments of each tool by name.
+func ParseMix(spec string, args map[string]map[string]any) ([]Operation, error) {
+	var mix []Operation
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, weightText, hasWeight := strings.Cut(item, "=")
+		weight := 1
+		if hasWeight {
+			w, err := strconv.Atoi(weightText)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("%s: weight must be a positive integer", item)
+			}
+			weight = w
+		}
+
+		op := Operation{Name: name, Method: name, Weight: weight}
+		if tool, ok := strings.CutPrefix(name, "call:"); ok {
+			if tool == "" {
+				return nil, fmt.Errorf("%s: no tool name", item)
+			}
+			arguments := args[tool]
+			if arguments == nil {
+				arguments = map[string]any{}
+			}
+			op.Method = string(mcp.MethodToolsCall)
+			op.Params = map[string]any{"name": tool, "arguments": arguments}
+		}
+		mix = append(mix, op)
+	}
+	if len(mix) == 0 {
+		return nil, errors.New("empty operation mix")
+	}
+	return mix, nil
+}
+
+// ParseArgs parses tool arguments given as "tool=<json object>"
+func ParseArgs(specs []string) (map[string]map[string]any, error) {
+	args := make(map[string]map[string]any, len(specs))
+	for _, spec := range specs {
+		tool, raw, ok := strings.Cut(spec, "=")
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("expected tool=json, got %q", spec)
+		}
+		var arguments map[string]any
+		if err := json.Unmarshal([]byte(raw), &arguments); err != nil {
+			return nil, fmt.Errorf("%s: %w", tool, err)
+		}
+		args[tool] = arguments
+	}
+	return args, nil
+}
@@ -0,0 +1,115 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/conformance"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+)
+
+// pipeDialer serves each connection in-process on a real server
+func pipeDialer(t *testing.T) conformance.Dialer {
+	t.Helper()
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+	hs := mcp.NewHandshakeServer(config)
+	hs.AddTool(mcpgo.NewTool("echo", mcpgo.WithString("message")),
+		func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+			return mcpgo.NewToolResultText(request.GetString("message", "")), nil
+		})
+
+	s := server.New(hs, server.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return func(context.Context) (conformance.Conn, error) {
+		client, conn := net.Pipe()
+		go s.ServeConn(ctx, "bench", server.NewLineConn(conn, conn, conn))
+		return conformance.NewStreamConn(client, client, client), nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	args, err := ParseArgs([]string{`echo={"message":"hi"}`})
+	require.NoError(t, err)
+	mix, err := ParseMix("tools/list=1,call:echo=2,call:missing", args)
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), pipeDialer(t), Config{
+		Connections: 4,
+		Duration:    300 * time.Millisecond,
+		RPS:         200,
+		Mix:         mix,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Connected)
+	assert.InDelta(t, 60, report.Requests, 25, "paced to the target rate")
+	assert.Zero(t, report.Failures)
+	require.Len(t, report.Operations, 3)
+	assert.Equal(t, report.Operations[2].Requests, report.Operations[2].Errors, "unknown tool always errors")
+	assert.Zero(t, report.Operations[0].Errors+report.Operations[1].Errors)
+	assert.Equal(t, report.Operations[2].Errors, report.Errors)
+	assert.LessOrEqual(t, report.Latency.P50, report.Latency.P99)
+	assert.LessOrEqual(t, report.Latency.P99, report.Latency.Max)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "4/4 connected")
+	assert.Contains(t, out.String(), "call:echo")
+}
+
+func TestRun_Unlimited(t *testing.T) {
+	report, err := Run(context.Background(), pipeDialer(t), Config{Connections: 2, Duration: 100 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Positive(t, report.Requests)
+	assert.Equal(t, "tools/list", report.Operations[0].Name)
+	assert.Zero(t, report.ErrorRate())
+}
+
+func TestRun_NoConnection(t *testing.T) {
+	dial := func(context.Context) (conformance.Conn, error) { return nil, errors.New("refused") }
+	_, err := Run(context.Background(), dial, Config{Connections: 2, Duration: time.Millisecond})
+	assert.ErrorContains(t, err, "refused")
+}
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("ping, call:echo=3", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Operation{
+		{Name: "ping", Method: "ping", Weight: 1},
+		{Name: "call:echo", Method: "tools/call", Params: map[string]any{"name": "echo", "arguments": map[string]any{}}, Weight: 3},
+	}, mix)
+
+	for _, spec := range []string{"", "ping=0", "ping=x", "call:"} {
+		_, err := ParseMix(spec, nil)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	l := summarize(samples)
+	assert.Equal(t, Latency{
+		Min:  time.Millisecond,
+		Mean: 50500 * time.Microsecond,
+		P50:  50 * time.Millisecond,
+		P90:  90 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+	}, l)
+}
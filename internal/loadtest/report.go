@@ -0,0 +1,260 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/conformance"
+)
+
+// Latency summarizes a set of latencies
+type Latency struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// summarize computes latency statistics, sorting samples in place
+func summarize(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	slices.Sort(samples)
+	var total time.Duration
+	for _, d := range samples {
+		total += d
+	}
+	return Latency{
+		Min:  samples[0],
+		Mean: total / time.Duration(len(samples)),
+		P50:  percentile(samples, 50),
+		P90:  percentile(samples, 90),
+		P99:  percentile(samples, 99),
+		Max:  samples[len(samples)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// OperationStats contains the results of one operation in the mix
+type OperationStats struct {
+	Name     string  `json:"name"`
+	Requests int     `json:"requests"`
+	Errors   int     `json:"errors"`
+	Failures int     `json:"failures"`
+	Latency  Latency `json:"latency"`
+}
+
+// Report contains the results of a load test
+type Report struct {
+	Connections int           `json:"connections"`
+	Connected   int           `json:"connected"`
+	TargetRPS   float64       `json:"targetRps,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Handshake   Latency       `json:"handshake"`
+
+	// Requests counts responses and failures; Errors are JSON-RPC error
+	// responses and Failures requests that got no response
+	Requests int     `json:"requests"`
+	Errors   int     `json:"errors"`
+	Failures int     `json:"failures"`
+	RPS      float64 `json:"rps"`
+	Latency  Latency `json:"latency"`
+
+	Operations []OperationStats `json:"operations"`
+
+	// ErrorCounts counts errors and failures by message
+	ErrorCounts map[string]int `json:"errorCounts,omitempty"`
+}
+
+// ErrorRate returns the fraction of requests that failed or got an error
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Errors+r.Failures) / float64(r.Requests)
+}
+
+// WriteText writes the report as a human-readable summary
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Connections: %d/%d connected, handshake p50 %s p99 %s\n",
+		r.Connected, r.Connections, r.Handshake.P50, r.Handshake.P99)
+	target := "unlimited"
+	if r.TargetRPS > 0 {
+		target = fmt.Sprintf("%.0f", r.TargetRPS)
+	}
+	fmt.Fprintf(w, "Requests:    %d in %s, %.1f/s (target %s)\n", r.Requests, r.Duration.Round(time.Millisecond), r.RPS, target)
+	fmt.Fprintf(w, "Errors:      %d error responses, %d failures (%.2f%%)\n\n", r.Errors, r.Failures, 100*r.ErrorRate())
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OPERATION\tREQUESTS\tERRORS\tP50\tP90\tP99\tMAX\t")
+	rows := append(slices.Clone(r.Operations), OperationStats{Name: "total", Requests: r.Requests, Errors: r.Errors + r.Failures, Latency: r.Latency})
+	for _, op := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n", op.Name, op.Requests, op.Errors+op.Failures,
+			round(op.Latency.P50), round(op.Latency.P90), round(op.Latency.P99), round(op.Latency.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.ErrorCounts) > 0 {
+		fmt.Fprintln(w, "\nErrors by message:")
+		messages := make([]string, 0, len(r.ErrorCounts))
+		for message := range r.ErrorCounts {
+			messages = append(messages, message)
+		}
+		sort.Slice(messages, func(i, j int) bool { return r.ErrorCounts[messages[i]] > r.ErrorCounts[messages[j]] })
+		for _, message := range messages {
+			fmt.Fprintf(w, "  %6d  %s\n", r.ErrorCounts[message], message)
+		}
+	}
+	return nil
+}
+
+// round shortens a latency for display
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+// maxErrorMessages bounds the distinct messages counted in a report
+const maxErrorMessages = 20
+
+// opSamples collects the results of one operation
+type opSamples struct {
+	latencies []time.Duration
+	errors    int
+	failures  int
+}
+
+// recorder collects results from concurrent clients
+type recorder struct {
+	mu         sync.Mutex
+	names      []string
+	ops        []opSamples
+	handshakes []time.Duration
+	connectErr []error
+	errorCount map[string]int
+}
+
+// newRecorder creates a recorder for mix
+func newRecorder(mix []Operation) *recorder {
+	r := &recorder{ops: make([]opSamples, len(mix)), errorCount: make(map[string]int)}
+	for _, op := range mix {
+		r.names = append(r.names, op.Name)
+	}
+	return r
+}
+
+func (r *recorder) handshake(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handshakes = append(r.handshakes, d)
+}
+
+func (r *recorder) connectFailed(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connectErr = append(r.connectErr, err)
+	r.countError("connect: " + err.Error())
+}
+
+func (r *recorder) connected() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.handshakes)
+}
+
+func (r *recorder) firstConnectError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.connectErr) == 0 {
+		return nil
+	}
+	return r.connectErr[0]
+}
+
+func (r *recorder) success(op int, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops[op].latencies = append(r.ops[op].latencies, d)
+}
+
+func (r *recorder) errored(op int, d time.Duration, err *conformance.Error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops[op].latencies = append(r.ops[op].latencies, d)
+	r.ops[op].errors++
+	r.countError(err.Error())
+}
+
+func (r *recorder) failure(op int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops[op].failures++
+	r.countError(err.Error())
+}
+
+// countError counts an error message; the caller holds r.mu
+func (r *recorder) countError(message string) {
+	if _, ok := r.errorCount[message]; !ok && len(r.errorCount) >= maxErrorMessages {
+		message = "other"
+	}
+	r.errorCount[message]++
+}
+
+// report builds the report of a run that sent requests for elapsed
+func (r *recorder) report(config Config, elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		Connections: config.Connections,
+		Connected:   len(r.handshakes),
+		TargetRPS:   config.RPS,
+		Duration:    elapsed,
+		Handshake:   summarize(r.handshakes),
+	}
+	var all []time.Duration
+	for i, samples := range r.ops {
+		all = append(all, samples.latencies...)
+		stats := OperationStats{
+			Name:     r.names[i],
+			Requests: len(samples.latencies) + samples.failures,
+			Errors:   samples.errors,
+			Failures: samples.failures,
+			Latency:  summarize(samples.latencies),
+		}
+		report.Operations = append(report.Operations, stats)
+		report.Requests += stats.Requests
+		report.Errors += stats.Errors
+		report.Failures += stats.Failures
+	}
+	report.Latency = summarize(all)
+	if elapsed > 0 {
+		report.RPS = float64(report.Requests) / elapsed.Seconds()
+	}
+	if len(r.errorCount) > 0 {
+		report.ErrorCounts = r.errorCount
+	}
+	return report
+}
@@ -1,9 +1,12 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Environment constants
@@ -70,9 +73,109 @@ func ConfigFromEnv() Config {
 		cfg.Sanitize = strings.ToLower(sanitize) == "true" || sanitize == "1"
 	}
 
+	if spec := os.Getenv("LOG_SINKS"); spec != "" {
+		sinks, err := SinksFromEnv(spec, cfg.Pretty)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logging: %v; falling back to stderr\n", err)
+		} else {
+			cfg.Sinks = sinks
+		}
+	}
+
 	return cfg
 }
 
+// Sink names accepted in LOG_SINKS
+const (
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+	SinkRing   = "ring"
+)
+
+// SinksFromEnv builds sinks from a comma separated list of sink names, each
+// optionally suffixed with a minimum level (e.g. "stderr:info,file:debug").
+// Sink options are read from the environment:
+//
+//   - file: LOG_FILE, LOG_FILE_MAX_SIZE_MB, LOG_FILE_MAX_AGE, LOG_FILE_MAX_BACKUPS
+//   - syslog: LOG_SYSLOG_NETWORK, LOG_SYSLOG_ADDR, LOG_SYSLOG_TAG
+//   - ring: LOG_RING_SIZE
+//
+// The stderr sink writes human-readable output when pretty is set.
+func SinksFromEnv(spec string, pretty bool) ([]Sink, error) {
+	var sinks []Sink
+	closeAll := func() {
+		for _, sink := range sinks {
+			sink.Close()
+		}
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		name, level, hasLevel := strings.Cut(strings.TrimSpace(entry), ":")
+		if name == "" {
+			continue
+		}
+
+		var sink Sink
+		switch strings.ToLower(name) {
+		case SinkStderr:
+			sink = NewStderrSink()
+			if pretty {
+				sink = NewConsoleSink(os.Stderr)
+			}
+		case SinkFile:
+			fileSink, err := NewFileSink(FileConfig{
+				Path:       os.Getenv("LOG_FILE"),
+				MaxSize:    int64(envInt("LOG_FILE_MAX_SIZE_MB")) << 20,
+				MaxAge:     envDuration("LOG_FILE_MAX_AGE"),
+				MaxBackups: envInt("LOG_FILE_MAX_BACKUPS"),
+			})
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			sink = fileSink
+		case SinkSyslog:
+			syslogSink, err := NewSyslogSink(SyslogConfig{
+				Network: os.Getenv("LOG_SYSLOG_NETWORK"),
+				Address: os.Getenv("LOG_SYSLOG_ADDR"),
+				Tag:     os.Getenv("LOG_SYSLOG_TAG"),
+			})
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			sink = syslogSink
+		case SinkRing:
+			sink = NewRingBuffer(envInt("LOG_RING_SIZE"))
+		default:
+			closeAll()
+			return nil, fmt.Errorf("unknown log sink %q", name)
+		}
+
+		if hasLevel {
+			sink = WithMinLevel(sink, ParseLogLevel(level))
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// envInt reads an integer environment variable, returning 0 when unset or
+// invalid
+func envInt(key string) int {
+	n, _ := strconv.Atoi(os.Getenv(key))
+	return n
+}
+
+// envDuration reads a duration environment variable, returning 0 when unset
+// or invalid
+func envDuration(key string) time.Duration {
+	d, _ := time.ParseDuration(os.Getenv(key))
+	return d
+}
+
 // ParseLogLevel parses a string log level into a LogLevel
 func ParseLogLevel(level string) LogLevel {
 	switch strings.ToLower(level) {
@@ -2,6 +2,12 @@ package logging
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
@@ -26,6 +32,9 @@ const (
 
 	// MethodKey is the context key for method names
 	MethodKey contextKey = "method"
+
+	// TenantKey is the context key for the tenant a request belongs to
+	TenantKey contextKey = "tenant"
 )
 
 // WithCorrelationID adds a correlation ID to the context
@@ -58,6 +67,11 @@ func WithMethod(ctx context.Context, method string) context.Context {
 	return context.WithValue(ctx, MethodKey, method)
 }
 
+// WithTenant adds a tenant to the context
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, TenantKey, tenant)
+}
+
 // extractCorrelationID extracts the correlation ID from the context
 func extractCorrelationID(ctx context.Context) string {
 	if ctx == nil {
@@ -98,40 +112,15 @@ func extractAllContextFields(ctx context.Context) map[string]interface{} {
 	}
 
 	fields := make(map[string]interface{})
-
-	// Extract standard fields
-	if corrID := extractCorrelationID(ctx); corrID != "" {
-		fields["correlation_id"] = corrID
-	}
-
-	if reqID := extractRequestID(ctx); reqID != "" {
-		fields["request_id"] = reqID
-	}
-
-	if userID, ok := ctx.Value(UserIDKey).(string); ok && userID != "" {
-		fields["user_id"] = userID
-	}
-
-	if sessionID, ok := ctx.Value(SessionIDKey).(string); ok && sessionID != "" {
-		fields["session_id"] = sessionID
-	}
-
-	if component, ok := ctx.Value(ComponentKey).(string); ok && component != "" {
-		fields["component"] = component
-	}
-
-	if method, ok := ctx.Value(MethodKey).(string); ok && method != "" {
-		fields["method"] = method
-	}
+	forEachContextField(ctx, func(key string, value interface{}) {
+		fields[key] = value
+	})
 
 	// Extract RouterContext fields if present
 	if rc := extractRouterContext(ctx); rc != nil {
 		if rc.Method != "" && fields["method"] == nil {
 			fields["method"] = rc.Method
 		}
-		if rc.CorrelationID != "" && fields["correlation_id"] == nil {
-			fields["correlation_id"] = rc.CorrelationID
-		}
 		// Add metadata if present
 		for k, v := range rc.Metadata {
 			if _, exists := fields[k]; !exists {
@@ -143,6 +132,57 @@ func extractAllContextFields(ctx context.Context) map[string]interface{} {
 	return fields
 }
 
+// forEachContextField calls fn with each standard field set in ctx, in a
+// fixed order: correlation, request, connection and session IDs, method,
+// tenant, user, component, then the trace and span IDs of the active span
+func forEachContextField(ctx context.Context, fn func(key string, value interface{})) {
+	if ctx == nil {
+		return
+	}
+
+	if corrID := extractCorrelationID(ctx); corrID != "" {
+		fn(FieldCorrelationID, corrID)
+	}
+	if reqID := extractRequestID(ctx); reqID != "" {
+		fn(FieldRequestID, reqID)
+	}
+	if connID, ok := connection.GetConnectionID(ctx); ok && connID != "" {
+		fn(FieldConnectionID, connID)
+	}
+	for _, f := range [...]struct {
+		key   contextKey
+		field string
+	}{
+		{SessionIDKey, FieldSessionID},
+		{MethodKey, FieldMethod},
+		{TenantKey, FieldTenant},
+		{UserIDKey, FieldUserID},
+		{ComponentKey, FieldComponent},
+	} {
+		if value, ok := ctx.Value(f.key).(string); ok && value != "" {
+			fn(f.field, value)
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		fn(FieldTraceID, span.TraceID().String())
+		fn(FieldSpanID, span.SpanID().String())
+	}
+}
+
+// FormatRequestID renders a JSON-RPC request ID for WithRequestID
+func FormatRequestID(id interface{}) string {
+	switch id := id.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
 // routerContextKey is the key used by the router package for RequestContext
 type routerContextKey struct{}
 
@@ -26,6 +26,9 @@ const (
 	FieldSessionID   = "session_id"
 	FieldClientID    = "client_id"
 	FieldComponent   = "component"
+	FieldTenant      = "tenant"
+	FieldTraceID     = "trace_id"
+	FieldSpanID      = "span_id"
 	FieldService     = "service"
 	FieldVersion     = "version"
 	FieldEnvironment = "environment"
@@ -9,14 +9,21 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
 	"github.com/rs/zerolog"
 )
 
 // Logger wraps zerolog.Logger and provides additional functionality
 type Logger struct {
 	logger    zerolog.Logger
+	redactor  *redact.Redactor
 	debugMode bool
 	sanitize  bool
+	sinks     *multiSink
+
+	// bound holds the keys of fields added with WithField and friends;
+	// context fields never repeat them
+	bound map[string]bool
 }
 
 // LogLevel represents the severity level for logging
@@ -58,8 +65,14 @@ type Config struct {
 	DebugMode bool
 	// Sanitize enables sanitization of sensitive data
 	Sanitize bool
+	// Redactor overrides the redaction engine used when Sanitize is set
+	// (defaults to redact.Default())
+	Redactor *redact.Redactor
 	// Pretty enables human-readable console output (for development)
 	Pretty bool
+	// Sinks replaces Output and Pretty with several destinations written
+	// simultaneously
+	Sinks []Sink
 }
 
 // New creates a new Logger instance with the given configuration
@@ -68,34 +81,27 @@ func New(cfg Config) *Logger {
 		cfg.Output = os.Stderr
 	}
 
-	// Convert our LogLevel to zerolog.Level
-	var zlLevel zerolog.Level
-	switch cfg.Level {
-	case LogLevelDebug:
-		zlLevel = zerolog.DebugLevel
-	case LogLevelInfo:
-		zlLevel = zerolog.InfoLevel
-	case LogLevelWarn:
-		zlLevel = zerolog.WarnLevel
-	case LogLevelError:
-		zlLevel = zerolog.ErrorLevel
-	case LogLevelFatal:
-		zlLevel = zerolog.FatalLevel
-	default:
-		zlLevel = zerolog.InfoLevel
+	if cfg.Redactor == nil {
+		cfg.Redactor = redact.Default()
 	}
 
 	// Configure zerolog
-	zerolog.SetGlobalLevel(zlLevel)
+	zerolog.SetGlobalLevel(toZerologLevel(cfg.Level))
 
 	var zl zerolog.Logger
-	if cfg.Pretty {
+	var sinks *multiSink
+	switch {
+	case len(cfg.Sinks) > 0:
+		// Fan out to every configured sink
+		sinks = newMultiSink(cfg.Sinks)
+		zl = zerolog.New(sinks)
+	case cfg.Pretty:
 		// Development mode with pretty console output
 		zl = zerolog.New(zerolog.ConsoleWriter{
 			Out:        cfg.Output,
 			TimeFormat: time.RFC3339,
 		})
-	} else {
+	default:
 		// Production mode with JSON output
 		zl = zerolog.New(cfg.Output)
 	}
@@ -110,48 +116,120 @@ func New(cfg Config) *Logger {
 
 	return &Logger{
 		logger:    zl,
+		redactor:  cfg.Redactor,
 		debugMode: cfg.DebugMode,
 		sanitize:  cfg.Sanitize,
+		sinks:     sinks,
 	}
 }
 
-// WithContext returns a new Logger with context fields
-func (l *Logger) WithContext(ctx context.Context) *Logger {
-	newLogger := *l
+// toZerologLevel converts our LogLevel to zerolog.Level
+func toZerologLevel(level LogLevel) zerolog.Level {
+	switch level {
+	case LogLevelDebug:
+		return zerolog.DebugLevel
+	case LogLevelInfo:
+		return zerolog.InfoLevel
+	case LogLevelWarn:
+		return zerolog.WarnLevel
+	case LogLevelError:
+		return zerolog.ErrorLevel
+	case LogLevelFatal:
+		return zerolog.FatalLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// SetLevel changes the minimum level of every logger at runtime
+func SetLevel(level LogLevel) {
+	zerolog.SetGlobalLevel(toZerologLevel(level))
+}
+
+// GetLevel returns the current minimum level
+func GetLevel() LogLevel {
+	switch zerolog.GlobalLevel() {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return LogLevelDebug
+	case zerolog.WarnLevel:
+		return LogLevelWarn
+	case zerolog.ErrorLevel:
+		return LogLevelError
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return LogLevelFatal
+	default:
+		return LogLevelInfo
+	}
+}
 
-	// Extract correlation ID if present
-	if corrID := extractCorrelationID(ctx); corrID != "" {
-		newLogger.logger = l.logger.With().Str("correlation_id", corrID).Logger()
+// Close flushes and closes the logger's sinks
+func (l *Logger) Close() error {
+	if l.sinks == nil {
+		return nil
 	}
+	return l.sinks.Close()
+}
 
-	// Extract other context values as needed
-	// This can be extended based on your context keys
+// RingBuffer returns the in-memory sink, if one is configured
+func (l *Logger) RingBuffer() (*RingBuffer, bool) {
+	if l.sinks == nil {
+		return nil, false
+	}
+	return l.sinks.ringBuffer()
+}
 
+// WithContext returns a new Logger with the standard fields found in ctx:
+// correlation, request, connection and session IDs, method, tenant, user,
+// component and the active trace. Fields already set on the logger win.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	newLogger := *l
+	event := l.logger.With()
+	forEachContextField(ctx, func(key string, value interface{}) {
+		if !l.bound[key] {
+			event = event.Interface(key, l.redactValue(key, value))
+		}
+	})
+	newLogger.logger = event.Logger()
 	return &newLogger
 }
 
 // WithCorrelationID returns a new Logger with the specified correlation ID
 func (l *Logger) WithCorrelationID(id string) *Logger {
-	newLogger := *l
-	newLogger.logger = l.logger.With().Str("correlation_id", id).Logger()
-	return &newLogger
+	newLogger := l.bind(FieldCorrelationID)
+	newLogger.logger = l.logger.With().Str(FieldCorrelationID, id).Logger()
+	return newLogger
 }
 
 // WithField returns a new Logger with an additional field
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	newLogger := *l
-	newLogger.logger = l.logger.With().Interface(key, value).Logger()
-	return &newLogger
+	newLogger := l.bind(key)
+	newLogger.logger = l.logger.With().Interface(key, l.redactValue(key, value)).Logger()
+	return newLogger
 }
 
 // WithFields returns a new Logger with additional fields
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	newLogger := *l
+	keys := make([]string, 0, len(fields))
 	event := l.logger.With()
 	for k, v := range fields {
-		event = event.Interface(k, v)
+		keys = append(keys, k)
+		event = event.Interface(k, l.redactValue(k, v))
 	}
+	newLogger := l.bind(keys...)
 	newLogger.logger = event.Logger()
+	return newLogger
+}
+
+// bind returns a copy of the logger recording keys as bound
+func (l *Logger) bind(keys ...string) *Logger {
+	newLogger := *l
+	newLogger.bound = make(map[string]bool, len(l.bound)+len(keys))
+	for key := range l.bound {
+		newLogger.bound[key] = true
+	}
+	for _, key := range keys {
+		newLogger.bound[key] = true
+	}
 	return &newLogger
 }
 
@@ -179,7 +257,7 @@ func (l *Logger) Warn(ctx context.Context, msg string) {
 func (l *Logger) Error(ctx context.Context, err error, msg string) {
 	event := l.WithContext(ctx).logger.Error()
 	if err != nil {
-		event = event.Err(err)
+		event = event.Str(zerolog.ErrorFieldName, l.redactError(err))
 		// Add error type for better debugging
 		event = event.Str("error_type", fmt.Sprintf("%T", err))
 	}
@@ -190,7 +268,7 @@ func (l *Logger) Error(ctx context.Context, err error, msg string) {
 func (l *Logger) Fatal(ctx context.Context, err error, msg string) {
 	event := l.WithContext(ctx).logger.Fatal()
 	if err != nil {
-		event = event.Err(err)
+		event = event.Str(zerolog.ErrorFieldName, l.redactError(err))
 		event = event.Str("error_type", fmt.Sprintf("%T", err))
 	}
 	event.Msg(msg)
@@ -238,7 +316,7 @@ func (l *Logger) LogError(ctx context.Context, err error, level LogLevel, messag
 	}
 
 	// Add error information
-	event = event.Err(err).
+	event = event.Str(zerolog.ErrorFieldName, l.redactError(err)).
 		Str("error_type", fmt.Sprintf("%T", err))
 
 	// Add caller information if available
@@ -267,6 +345,22 @@ func (l *Logger) LogWithRecovery(ctx context.Context, fn func()) {
 	fn()
 }
 
+// redactValue applies the redaction engine to a field value when sanitization is enabled
+func (l *Logger) redactValue(key string, value interface{}) interface{} {
+	if !l.sanitize || l.redactor == nil {
+		return value
+	}
+	return l.redactor.Value(key, value)
+}
+
+// redactError returns the error message, scrubbed when sanitization is enabled
+func (l *Logger) redactError(err error) string {
+	if !l.sanitize || l.redactor == nil {
+		return err.Error()
+	}
+	return l.redactor.String(err.Error())
+}
+
 // GetZerolog returns the underlying zerolog.Logger for advanced usage
 // This should be used sparingly to maintain abstraction
 func (l *Logger) GetZerolog() zerolog.Logger {
@@ -0,0 +1,178 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default rotation settings
+const (
+	DefaultMaxFileSize = 100 << 20 // 100 MiB
+	rotateTimeFormat   = "20060102T150405.000"
+)
+
+// FileConfig configures a rotating file sink
+type FileConfig struct {
+	// Path of the active log file
+	Path string
+
+	// MaxSize rotates the file once it would exceed this many bytes
+	// (defaults to DefaultMaxFileSize)
+	MaxSize int64
+
+	// MaxAge removes rotated files older than this (0 keeps them forever)
+	MaxAge time.Duration
+
+	// MaxBackups limits the number of rotated files kept (0 keeps all)
+	MaxBackups int
+}
+
+// FileSink is a sink writing to a file that rotates by size and prunes
+// rotated files by age and count
+type FileSink struct {
+	config FileConfig
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	now    func() time.Time
+}
+
+// NewFileSink opens a rotating file sink, creating parent directories
+func NewFileSink(config FileConfig) (*FileSink, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxFileSize
+	}
+
+	s := &FileSink{config: config, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write implements Sink, rotating first when the record would overflow the
+// file
+func (s *FileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return 0, os.ErrClosed
+	}
+	if s.size > 0 && s.size+int64(len(p)) > s.config.MaxSize {
+		if err := s.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// Rotate forces a rotation of the active file
+func (s *FileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotate()
+}
+
+// Close implements Sink
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// open opens or creates the active file
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.config.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate moves the active file aside, reopens it and prunes old files
+func (s *FileSink) rotate() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		s.file = nil
+	}
+
+	if err := os.Rename(s.config.Path, s.backupName(s.now())); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	s.prune()
+	return nil
+}
+
+// backupName returns the rotated file name for time t
+func (s *FileSink) backupName(t time.Time) string {
+	ext := filepath.Ext(s.config.Path)
+	base := strings.TrimSuffix(s.config.Path, ext)
+	return fmt.Sprintf("%s-%s%s", base, t.UTC().Format(rotateTimeFormat), ext)
+}
+
+// backups returns rotated files, newest first
+func (s *FileSink) backups() []string {
+	ext := filepath.Ext(s.config.Path)
+	base := strings.TrimSuffix(s.config.Path, ext)
+	matches, _ := filepath.Glob(base + "-*" + ext)
+
+	// Timestamps sort lexically
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	return matches
+}
+
+// prune removes rotated files beyond MaxBackups or older than MaxAge
+func (s *FileSink) prune() {
+	cutoff := time.Time{}
+	if s.config.MaxAge > 0 {
+		cutoff = s.now().Add(-s.config.MaxAge)
+	}
+
+	for i, path := range s.backups() {
+		if s.config.MaxBackups > 0 && i >= s.config.MaxBackups {
+			os.Remove(path)
+			continue
+		}
+		if !cutoff.IsZero() {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(path)
+			}
+		}
+	}
+}
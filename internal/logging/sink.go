@@ -0,0 +1,173 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Sink is a destination for log records. Records are written one JSON
+// object per Write call.
+type Sink interface {
+	io.Writer
+	Close() error
+}
+
+// nopCloser adapts a writer that must not be closed, such as os.Stderr
+type nopCloser struct {
+	io.Writer
+}
+
+// Close implements Sink
+func (nopCloser) Close() error { return nil }
+
+// NewWriterSink wraps w as a sink. Closing the sink does not close w.
+func NewWriterSink(w io.Writer) Sink {
+	return nopCloser{Writer: w}
+}
+
+// NewStderrSink returns a sink writing JSON records to stderr
+func NewStderrSink() Sink {
+	return NewWriterSink(os.Stderr)
+}
+
+// NewConsoleSink returns a sink writing human-readable records to w
+func NewConsoleSink(w io.Writer) Sink {
+	return NewWriterSink(zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339})
+}
+
+// levelSink drops records below a minimum level
+type levelSink struct {
+	Sink
+	level zerolog.Level
+}
+
+// WithMinLevel returns a sink that only receives records at or above level
+func WithMinLevel(sink Sink, level LogLevel) Sink {
+	return &levelSink{Sink: sink, level: toZerologLevel(level)}
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (s *levelSink) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < s.level {
+		return len(p), nil
+	}
+	if lw, ok := s.Sink.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return s.Sink.Write(p)
+}
+
+// RingBuffer is an in-memory sink keeping the most recent records, used to
+// inspect logs through the debug endpoint
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []string
+	next    int
+	full    bool
+}
+
+// DefaultRingSize is the number of records kept by a RingBuffer by default
+const DefaultRingSize = 1000
+
+// NewRingBuffer creates a ring buffer holding up to size records
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultRingSize
+	}
+	return &RingBuffer{entries: make([]string, size)}
+}
+
+// Write implements Sink
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = string(p)
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+// Close implements Sink
+func (r *RingBuffer) Close() error { return nil }
+
+// Entries returns the buffered records, oldest first
+func (r *RingBuffer) Entries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.entries[:r.next]...)
+	}
+	out := make([]string, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// WriteTo writes the buffered records to w, oldest first
+func (r *RingBuffer) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, entry := range r.Entries() {
+		n, err := io.WriteString(w, entry)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// multiSink fans records out to several sinks
+type multiSink struct {
+	sinks  []Sink
+	writer zerolog.LevelWriter
+}
+
+// newMultiSink combines sinks into one
+func newMultiSink(sinks []Sink) *multiSink {
+	writers := make([]io.Writer, len(sinks))
+	for i, sink := range sinks {
+		writers[i] = sink
+	}
+	return &multiSink{sinks: sinks, writer: zerolog.MultiLevelWriter(writers...)}
+}
+
+// Write implements Sink
+func (m *multiSink) Write(p []byte) (int, error) {
+	return m.writer.Write(p)
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (m *multiSink) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	return m.writer.WriteLevel(level, p)
+}
+
+// Close closes every sink and returns the first error
+func (m *multiSink) Close() error {
+	var first error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ringBuffer finds the first RingBuffer among sinks
+func (m *multiSink) ringBuffer() (*RingBuffer, bool) {
+	for _, sink := range m.sinks {
+		if ls, ok := sink.(*levelSink); ok {
+			sink = ls.Sink
+		}
+		if rb, ok := sink.(*RingBuffer); ok {
+			return rb, true
+		}
+	}
+	return nil, false
+}
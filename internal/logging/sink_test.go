@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMultipleSinks(t *testing.T) {
+	var all, errorsOnly bytes.Buffer
+	ring := NewRingBuffer(2)
+
+	logger := New(Config{
+		Level: LogLevelDebug,
+		Sinks: []Sink{
+			NewWriterSink(&all),
+			WithMinLevel(NewWriterSink(&errorsOnly), LogLevelError),
+			ring,
+		},
+	})
+
+	ctx := context.Background()
+	logger.Info(ctx, "first")
+	logger.Info(ctx, "second")
+	logger.Error(ctx, nil, "third")
+
+	if got := strings.Count(all.String(), "\n"); got != 3 {
+		t.Errorf("Expected 3 records in unfiltered sink, got %d", got)
+	}
+	if strings.Contains(errorsOnly.String(), "first") || !strings.Contains(errorsOnly.String(), "third") {
+		t.Errorf("Expected only error records, got %s", errorsOnly.String())
+	}
+
+	rb, ok := logger.RingBuffer()
+	if !ok || rb != ring {
+		t.Fatal("Expected logger to expose its ring buffer")
+	}
+	entries := rb.Entries()
+	if len(entries) != 2 || !strings.Contains(entries[0], "second") || !strings.Contains(entries[1], "third") {
+		t.Errorf("Expected the two most recent records, got %v", entries)
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Errorf("Unexpected close error: %v", err)
+	}
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+
+	sink, err := NewFileSink(FileConfig{Path: path, MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := sink.Write([]byte("12345678\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	backups := sink.backups()
+	if len(backups) != 2 {
+		t.Errorf("Expected 2 backups to be kept, got %v", backups)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read active file: %v", err)
+	}
+	if string(data) != "12345678\n" {
+		t.Errorf("Expected active file to hold the last record, got %q", data)
+	}
+}
+
+func TestSinksFromEnv(t *testing.T) {
+	t.Setenv("LOG_FILE", filepath.Join(t.TempDir(), "app.log"))
+	t.Setenv("LOG_RING_SIZE", "5")
+
+	sinks, err := SinksFromEnv("stderr:warn, file, ring", false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer newMultiSink(sinks).Close()
+
+	if len(sinks) != 3 {
+		t.Fatalf("Expected 3 sinks, got %d", len(sinks))
+	}
+	if _, ok := sinks[0].(*levelSink); !ok {
+		t.Errorf("Expected stderr sink to be level filtered")
+	}
+	if _, ok := sinks[1].(*FileSink); !ok {
+		t.Errorf("Expected a file sink, got %T", sinks[1])
+	}
+
+	if _, err := SinksFromEnv("carrier-pigeon", false); err == nil {
+		t.Error("Expected error for unknown sink")
+	}
+}
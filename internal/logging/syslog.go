@@ -0,0 +1,41 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/rs/zerolog"
+)
+
+// SyslogConfig configures a syslog sink
+type SyslogConfig struct {
+	// Network and Address of the syslog daemon; both empty uses the local
+	// daemon
+	Network string
+	Address string
+
+	// Tag identifies the program (defaults to the process name)
+	Tag string
+}
+
+// syslogSink forwards records to syslog at the matching severity
+type syslogSink struct {
+	zerolog.LevelWriter
+	w *syslog.Writer
+}
+
+// NewSyslogSink connects to syslog
+func NewSyslogSink(config SyslogConfig) (Sink, error) {
+	w, err := syslog.Dial(config.Network, config.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, config.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogSink{LevelWriter: zerolog.SyslogLevelWriter(w), w: w}, nil
+}
+
+// Close implements Sink
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
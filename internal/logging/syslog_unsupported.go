@@ -0,0 +1,17 @@
+//go:build windows || plan9
+
+package logging
+
+import "errors"
+
+// SyslogConfig configures a syslog sink
+type SyslogConfig struct {
+	Network string
+	Address string
+	Tag     string
+}
+
+// NewSyslogSink is not supported on this platform
+func NewSyslogSink(config SyslogConfig) (Sink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
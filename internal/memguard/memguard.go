@@ -0,0 +1,211 @@
+// Package memguard keeps the memory held by in-flight messages under a
+// ceiling by shedding work before the process runs out of memory.
+//
+// A Guard tracks the approximate bytes of messages that have been read but
+// not yet answered, queued requests and buffered responses. Each unit of
+// work acquires its size before it is accepted and releases it when done.
+// As usage approaches the limit, work is shed lowest priority first:
+//
+//   - PriorityLow (notifications from clients) above 60% of the limit
+//   - PriorityNormal (tool calls, reads and other requests) above 80%
+//   - PriorityHigh (list requests) above 95%
+//   - PriorityCritical (initialize, ping, cancellations) is never shed
+//
+// Shed work fails with a memory limit error, which clients may retry.
+//
+// Basic usage:
+//
+//	guard := memguard.New(memguard.Config{Limit: 256 << 20})
+//	memguard.SetDefault(guard)
+//
+//	size := int64(len(message))
+//	if err := guard.Acquire(size, memguard.MethodPriority(method)); err != nil {
+//		return err.ToMCPError(id)
+//	}
+//	defer guard.Release(size)
+//
+// A nil Guard admits everything, so callers need not check whether one is
+// configured.
+package memguard
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// Priority orders work by how important it is to keep it; lower priority
+// work is shed first
+type Priority int
+
+// Priorities from first to last shed
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+// numPriorities is the number of priorities
+const numPriorities = int(PriorityCritical) + 1
+
+// shedAt is the fraction of the limit above which work of each priority is
+// shed; critical work has none
+var shedAt = [numPriorities]float64{
+	PriorityLow:    0.60,
+	PriorityNormal: 0.80,
+	PriorityHigh:   0.95,
+}
+
+// String returns the name of the priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+}
+
+// Config contains configuration for a Guard
+type Config struct {
+	// Limit is the ceiling in bytes; zero or less only tracks usage
+	Limit int64
+}
+
+// Stats contains guard statistics
+type Stats struct {
+	Limit int64            `json:"limit"`
+	Used  int64            `json:"used"`
+	Peak  int64            `json:"peak"`
+	Shed  map[string]int64 `json:"shed"`
+}
+
+// Guard tracks in-flight bytes against a limit. It is safe for concurrent
+// use and takes no locks.
+type Guard struct {
+	limit    int64
+	ceilings [numPriorities]int64
+	used     atomic.Int64
+	peak     atomic.Int64
+	shed     [numPriorities]atomic.Int64
+}
+
+// New creates a Guard
+func New(config Config) *Guard {
+	g := &Guard{limit: config.Limit}
+	if config.Limit > 0 {
+		for p := range g.ceilings {
+			if shedAt[p] > 0 {
+				g.ceilings[p] = int64(float64(config.Limit) * shedAt[p])
+			}
+		}
+	}
+	return g
+}
+
+// Acquire accounts n bytes of work with priority p. It fails with a memory
+// limit error, accounting nothing, when the work would take usage above
+// the ceiling of its priority. Every successful Acquire must be matched by
+// a Release of the same size.
+func (g *Guard) Acquire(n int64, p Priority) *mcperrors.MCPError {
+	if g == nil {
+		return nil
+	}
+	if p < 0 || int(p) >= numPriorities {
+		p = PriorityNormal
+	}
+
+	ceiling := g.ceilings[p]
+	for {
+		used := g.used.Load()
+		if ceiling > 0 && used+n > ceiling {
+			g.shed[p].Add(1)
+			return mcperrors.NewMemoryLimitError(used+n, g.limit).WithContext("priority", p.String())
+		}
+		if g.used.CompareAndSwap(used, used+n) {
+			g.observePeak(used + n)
+			return nil
+		}
+	}
+}
+
+// Release returns n bytes accounted by Acquire
+func (g *Guard) Release(n int64) {
+	if g == nil {
+		return
+	}
+	g.used.Add(-n)
+}
+
+// Used returns the bytes currently accounted
+func (g *Guard) Used() int64 {
+	if g == nil {
+		return 0
+	}
+	return g.used.Load()
+}
+
+// Stats returns guard statistics
+func (g *Guard) Stats() Stats {
+	stats := Stats{Shed: make(map[string]int64)}
+	if g == nil {
+		return stats
+	}
+	stats.Limit = g.limit
+	stats.Used = g.used.Load()
+	stats.Peak = g.peak.Load()
+	for p := range g.shed {
+		if n := g.shed[p].Load(); n > 0 {
+			stats.Shed[Priority(p).String()] = n
+		}
+	}
+	return stats
+}
+
+// observePeak raises the peak to used if it is higher
+func (g *Guard) observePeak(used int64) {
+	for {
+		peak := g.peak.Load()
+		if used <= peak || g.peak.CompareAndSwap(peak, used) {
+			return
+		}
+	}
+}
+
+// MethodPriority returns the priority of an MCP message by method. An
+// empty method stands for a response, which completes work already
+// accepted and is critical.
+func MethodPriority(method string) Priority {
+	switch method {
+	case "", "initialize", "notifications/initialized", "ping", "notifications/cancelled":
+		return PriorityCritical
+	case "tools/list", "resources/list", "resources/templates/list", "prompts/list":
+		return PriorityHigh
+	}
+	if strings.HasPrefix(method, "notifications/") {
+		return PriorityLow
+	}
+	return PriorityNormal
+}
+
+// Global guard instance; nil admits everything
+var defaultGuard *Guard
+
+// SetDefault sets the default global guard
+func SetDefault(g *Guard) {
+	defaultGuard = g
+}
+
+// Default returns the default global guard
+func Default() *Guard {
+	return defaultGuard
+}
@@ -0,0 +1,102 @@
+package memguard
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+func TestGuard_ShedsLowestPriorityFirst(t *testing.T) {
+	g := New(Config{Limit: 1000})
+
+	// 700 bytes in flight: above the low ceiling only
+	require.Nil(t, g.Acquire(700, PriorityCritical))
+	err := g.Acquire(1, PriorityLow)
+	require.NotNil(t, err)
+	assert.Equal(t, mcperrors.ErrorCodeMCPMemoryLimit, err.Code)
+	assert.Equal(t, "low", err.Context["priority"])
+	assert.Nil(t, g.Acquire(50, PriorityNormal))
+
+	// 850 bytes: normal work is shed too
+	require.Nil(t, g.Acquire(100, PriorityHigh))
+	assert.NotNil(t, g.Acquire(1, PriorityNormal))
+	assert.Nil(t, g.Acquire(50, PriorityHigh))
+
+	// 900 bytes: high work is shed above 950, critical never
+	assert.NotNil(t, g.Acquire(100, PriorityHigh))
+	assert.Nil(t, g.Acquire(500, PriorityCritical))
+	assert.Equal(t, int64(1400), g.Used())
+
+	stats := g.Stats()
+	assert.Equal(t, int64(1000), stats.Limit)
+	assert.Equal(t, int64(1400), stats.Peak)
+	assert.Equal(t, map[string]int64{"low": 1, "normal": 1, "high": 1}, stats.Shed)
+}
+
+func TestGuard_Release(t *testing.T) {
+	g := New(Config{Limit: 100})
+	require.Nil(t, g.Acquire(70, PriorityNormal))
+	assert.NotNil(t, g.Acquire(20, PriorityNormal))
+
+	g.Release(70)
+	assert.Equal(t, int64(0), g.Used())
+	assert.Nil(t, g.Acquire(20, PriorityNormal))
+	assert.Equal(t, int64(70), g.Stats().Peak)
+}
+
+func TestGuard_NoLimit(t *testing.T) {
+	g := New(Config{})
+	assert.Nil(t, g.Acquire(1<<40, PriorityLow))
+	assert.Equal(t, int64(1<<40), g.Used())
+	assert.Empty(t, g.Stats().Shed)
+}
+
+func TestGuard_Nil(t *testing.T) {
+	var g *Guard
+	assert.Nil(t, g.Acquire(100, PriorityLow))
+	g.Release(100)
+	assert.Equal(t, int64(0), g.Used())
+	assert.Empty(t, g.Stats().Shed)
+}
+
+func TestGuard_Concurrent(t *testing.T) {
+	g := New(Config{Limit: 1000})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if g.Acquire(10, PriorityNormal) == nil {
+					g.Release(10)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(0), g.Used())
+	assert.LessOrEqual(t, g.Stats().Peak, int64(800))
+}
+
+func TestMethodPriority(t *testing.T) {
+	tests := []struct {
+		method string
+		want   Priority
+	}{
+		{"", PriorityCritical},
+		{"initialize", PriorityCritical},
+		{"ping", PriorityCritical},
+		{"notifications/cancelled", PriorityCritical},
+		{"tools/list", PriorityHigh},
+		{"tools/call", PriorityNormal},
+		{"resources/read", PriorityNormal},
+		{"notifications/roots/list_changed", PriorityLow},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MethodPriority(tt.method), tt.method)
+	}
+}
@@ -0,0 +1,339 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/scheduler"
+)
+
+// UpstreamHealthFunc reports the health of a set of upstreams by name
+type UpstreamHealthFunc func() map[string]bool
+
+// ObserveConnections exports connection counts by state from manager
+func (m *Metrics) ObserveConnections(manager *connection.Manager) error {
+	desc := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "", "connections"),
+		"Connections by handshake state.",
+		[]string{"state"}, nil,
+	)
+	states := []connection.ConnectionState{
+		connection.StateNew, connection.StateInitializing, connection.StateReady, connection.StateClosed,
+	}
+
+	return m.registry.Register(&funcCollector{
+		desc: desc,
+		collect: func(ch chan<- prometheus.Metric) {
+			counts := manager.StateCounts()
+			for _, state := range states {
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue,
+					float64(counts[state]), strings.ToLower(state.String()))
+			}
+		},
+	})
+}
+
+// ObserveHandshakes exports the count and latency of the handshakes served
+// by hs, by outcome
+func (m *Metrics) ObserveHandshakes(hs *protocolmcp.HandshakeServer) error {
+	desc := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "", "handshake_duration_seconds"),
+		"Latency of initialize handshakes, from the request to its response or timeout, by outcome.",
+		[]string{"outcome"}, nil,
+	)
+
+	return m.registry.Register(&funcCollector{
+		desc: desc,
+		collect: func(ch chan<- prometheus.Metric) {
+			stats := hs.GetStats()
+			for _, outcome := range protocolmcp.HandshakeOutcomes {
+				s := stats.Outcomes[outcome]
+				ch <- prometheus.MustNewConstSummary(desc, uint64(s.Count),
+					s.TotalLatency.Seconds(), nil, string(outcome))
+			}
+		},
+	})
+}
+
+// ObserveAsyncRouter exports queue depth and pending request counts from ar
+func (m *Metrics) ObserveAsyncRouter(ar *router.AsyncRouter) error {
+	queueDepth := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.config.Namespace,
+		Name:      "queue_depth",
+		Help:      "Requests waiting in the async router queue.",
+	}, func() float64 { return float64(ar.Stats().QueuedRequests) })
+
+	pending := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.config.Namespace,
+		Name:      "pending_requests",
+		Help:      "Async requests awaiting a response.",
+	}, func() float64 { return float64(ar.Stats().PendingRequests) })
+
+	if err := m.registry.Register(queueDepth); err != nil {
+		return err
+	}
+	return m.registry.Register(pending)
+}
+
+// ObserveErrors exports the windowed error rates of recorder by category
+func (m *Metrics) ObserveErrors(recorder *mcperrors.Recorder) error {
+	desc := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "", "error_rate"),
+		"Fraction of requests failing by error category over the recorder window.",
+		[]string{"category"}, nil,
+	)
+
+	return m.registry.Register(&funcCollector{
+		desc: desc,
+		collect: func(ch chan<- prometheus.Metric) {
+			for category, rate := range recorder.Snapshot().Rates {
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, rate, category)
+			}
+		},
+	})
+}
+
+// ObserveScheduler exports run, failure and skip counts and the last run
+// duration of each scheduled job
+func (m *Metrics) ObserveScheduler(s *scheduler.Scheduler) error {
+	runs := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "scheduler", "runs_total"),
+		"Completed runs of scheduled jobs.",
+		[]string{"job"}, nil,
+	)
+	failures := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "scheduler", "failures_total"),
+		"Failed runs of scheduled jobs.",
+		[]string{"job"}, nil,
+	)
+	skipped := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "scheduler", "skipped_total"),
+		"Runs of scheduled jobs skipped because the previous run was still going.",
+		[]string{"job"}, nil,
+	)
+	duration := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "scheduler", "last_duration_seconds"),
+		"Duration of the last run of scheduled jobs.",
+		[]string{"job"}, nil,
+	)
+
+	for _, c := range []*funcCollector{
+		{desc: runs, collect: func(ch chan<- prometheus.Metric) {
+			for _, job := range s.Stats() {
+				ch <- prometheus.MustNewConstMetric(runs, prometheus.CounterValue, float64(job.Runs), job.Name)
+			}
+		}},
+		{desc: failures, collect: func(ch chan<- prometheus.Metric) {
+			for _, job := range s.Stats() {
+				ch <- prometheus.MustNewConstMetric(failures, prometheus.CounterValue, float64(job.Failures), job.Name)
+			}
+		}},
+		{desc: skipped, collect: func(ch chan<- prometheus.Metric) {
+			for _, job := range s.Stats() {
+				ch <- prometheus.MustNewConstMetric(skipped, prometheus.CounterValue, float64(job.Skipped), job.Name)
+			}
+		}},
+		{desc: duration, collect: func(ch chan<- prometheus.Metric) {
+			for _, job := range s.Stats() {
+				ch <- prometheus.MustNewConstMetric(duration, prometheus.GaugeValue, job.LastDuration.Seconds(), job.Name)
+			}
+		}},
+	} {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveResourceCache exports the lookups, evictions and size of a
+// resource cache
+func (m *Metrics) ObserveResourceCache(cache *resources.Cache) error {
+	lookups := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "resource_cache", "lookups_total"),
+		"Resource cache lookups by result: hit (fresh), revalidated (unchanged at the source) or miss.",
+		[]string{"result"}, nil,
+	)
+	evictions := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "resource_cache", "evictions_total"),
+		"Resources evicted to respect the cache limits.",
+		nil, nil,
+	)
+	entries := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "resource_cache", "entries"),
+		"Resources in the cache.",
+		nil, nil,
+	)
+	size := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "resource_cache", "bytes"),
+		"Approximate size of the cached contents.",
+		nil, nil,
+	)
+
+	for _, c := range []*funcCollector{
+		{desc: lookups, collect: func(ch chan<- prometheus.Metric) {
+			stats := cache.Stats()
+			ch <- prometheus.MustNewConstMetric(lookups, prometheus.CounterValue, float64(stats.Hits), "hit")
+			ch <- prometheus.MustNewConstMetric(lookups, prometheus.CounterValue, float64(stats.Revalidations), "revalidated")
+			ch <- prometheus.MustNewConstMetric(lookups, prometheus.CounterValue, float64(stats.Misses), "miss")
+		}},
+		{desc: evictions, collect: func(ch chan<- prometheus.Metric) {
+			ch <- prometheus.MustNewConstMetric(evictions, prometheus.CounterValue, float64(cache.Stats().Evictions))
+		}},
+		{desc: entries, collect: func(ch chan<- prometheus.Metric) {
+			ch <- prometheus.MustNewConstMetric(entries, prometheus.GaugeValue, float64(cache.Stats().Entries))
+		}},
+		{desc: size, collect: func(ch chan<- prometheus.Metric) {
+			ch <- prometheus.MustNewConstMetric(size, prometheus.GaugeValue, float64(cache.Stats().Bytes))
+		}},
+	} {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveUpstreams adds a source of upstream health evaluated on every scrape
+func (m *Metrics) ObserveUpstreams(source UpstreamHealthFunc) {
+	m.upstreams.addSource(source)
+}
+
+// ObserveTransports reports the health of every connection in a transport
+// manager as upstream health
+func (m *Metrics) ObserveTransports(manager *transport.Manager) {
+	m.ObserveUpstreams(func() map[string]bool {
+		health := make(map[string]bool)
+		for id, status := range manager.HealthCheck() {
+			health[id] = status.Connected && (status.ProcessID == 0 || status.Running)
+		}
+		return health
+	})
+}
+
+// ObserveEvents exports the published and dropped events of every topic of
+// bus, and records upstream health from its state changes
+func (m *Metrics) ObserveEvents(bus *events.Bus) error {
+	published := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "events", "published_total"),
+		"Events published by topic.",
+		[]string{"topic"}, nil,
+	)
+	dropped := prometheus.NewDesc(
+		prometheus.BuildFQName(m.config.Namespace, "events", "dropped_total"),
+		"Events dropped because a subscriber fell behind, by topic.",
+		[]string{"topic"}, nil,
+	)
+
+	for _, c := range []*funcCollector{
+		{desc: published, collect: func(ch chan<- prometheus.Metric) {
+			for topic, stats := range bus.Stats() {
+				ch <- prometheus.MustNewConstMetric(published, prometheus.CounterValue, float64(stats.Published), topic)
+			}
+		}},
+		{desc: dropped, collect: func(ch chan<- prometheus.Metric) {
+			for topic, stats := range bus.Stats() {
+				ch <- prometheus.MustNewConstMetric(dropped, prometheus.CounterValue, float64(stats.Dropped), topic)
+			}
+		}},
+	} {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	events.Subscribe(bus, events.UpstreamHealth, func(e events.UpstreamHealthEvent) {
+		m.SetUpstreamHealth(e.Upstream, e.State != "disconnected")
+	})
+	return nil
+}
+
+// funcCollector adapts a collect function to prometheus.Collector
+type funcCollector struct {
+	desc    *prometheus.Desc
+	collect func(ch chan<- prometheus.Metric)
+}
+
+// Describe implements prometheus.Collector
+func (c *funcCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *funcCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(ch)
+}
+
+// upstreamCollector merges explicitly set upstream health with health
+// sources evaluated at scrape time
+type upstreamCollector struct {
+	desc    *prometheus.Desc
+	mu      sync.RWMutex
+	static  map[string]bool
+	sources []UpstreamHealthFunc
+}
+
+// newUpstreamCollector creates the upstream_up collector
+func newUpstreamCollector(namespace string) *upstreamCollector {
+	return &upstreamCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "upstream_up"),
+			"Whether the upstream is healthy (1) or not (0).",
+			[]string{"upstream"}, nil,
+		),
+		static: make(map[string]bool),
+	}
+}
+
+// set records the health of a single upstream
+func (c *upstreamCollector) set(upstream string, healthy bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.static[upstream] = healthy
+}
+
+// addSource adds a scrape-time health source
+func (c *upstreamCollector) addSource(source UpstreamHealthFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources = append(c.sources, source)
+}
+
+// Describe implements prometheus.Collector
+func (c *upstreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *upstreamCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	health := make(map[string]bool, len(c.static))
+	for name, healthy := range c.static {
+		health[name] = healthy
+	}
+	sources := append([]UpstreamHealthFunc(nil), c.sources...)
+	c.mu.RUnlock()
+
+	for _, source := range sources {
+		for name, healthy := range source() {
+			health[name] = healthy
+		}
+	}
+
+	for name, healthy := range health {
+		value := 0.0
+		if healthy {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, value, name)
+	}
+}
@@ -0,0 +1,178 @@
+// Package metrics exports Prometheus metrics for the Meta-MCP server.
+//
+// Metrics are grouped in a Metrics value that owns its own registry, so
+// tests and embedded servers do not collide on the global Prometheus
+// registry. The exported series are:
+//
+//   - requests_total{method,code}: handled requests by method and result code
+//   - request_duration_seconds{method}: handler latency histogram
+//   - queue_depth: requests waiting in the async router queue
+//   - pending_requests: async requests awaiting a response
+//   - upstream_up{upstream}: 1 if the upstream transport is connected
+//   - connections{state}: connections by handshake state
+//   - handshake_duration_seconds{outcome}: handshake count and latency by
+//     outcome (success, version_mismatch, auth_failure, timeout, ...)
+//   - transport_bytes_total{transport,direction}: bytes moved by transports
+//   - error_rate{category}: windowed error rate from an errors.Recorder
+//
+// Basic usage:
+//
+//	m := metrics.New(metrics.Config{})
+//	chain := router.NewChain(m.Middleware())
+//	m.ObserveConnections(connManager)
+//
+//	go m.ListenAndServe(ctx, ":9090")
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultPath is the HTTP path metrics are served on
+const DefaultPath = "/metrics"
+
+// DefaultNamespace prefixes every metric name
+const DefaultNamespace = "meta_mcp"
+
+// Config contains configuration for Metrics
+type Config struct {
+	// Namespace prefixes metric names (defaults to DefaultNamespace)
+	Namespace string
+
+	// Path is the HTTP path for the exporter (defaults to DefaultPath)
+	Path string
+
+	// Buckets overrides the latency histogram buckets
+	Buckets []float64
+
+	// IncludeRuntime registers Go runtime and process collectors
+	IncludeRuntime bool
+}
+
+// Metrics holds the server's Prometheus collectors
+type Metrics struct {
+	config   Config
+	registry *prometheus.Registry
+
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	upstreams      *upstreamCollector
+	transportBytes *prometheus.CounterVec
+}
+
+// New creates a Metrics instance with its own registry
+func New(config Config) *Metrics {
+	if config.Namespace == "" {
+		config.Namespace = DefaultNamespace
+	}
+	if config.Path == "" {
+		config.Path = DefaultPath
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = prometheus.DefBuckets
+	}
+
+	m := &Metrics{
+		config:   config,
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "requests_total",
+			Help:      "Handled requests by method and result code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Handler latency by method.",
+			Buckets:   config.Buckets,
+		}, []string{"method"}),
+		upstreams: newUpstreamCollector(config.Namespace),
+		transportBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "transport_bytes_total",
+			Help:      "Bytes moved by transports.",
+		}, []string{"transport", "direction"}),
+	}
+
+	m.registry.MustRegister(m.requests, m.duration, m.upstreams, m.transportBytes)
+	if config.IncludeRuntime {
+		m.registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+
+	return m
+}
+
+// Registry returns the underlying Prometheus registry
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveRequest records a completed request
+func (m *Metrics) ObserveRequest(method, code string, duration time.Duration) {
+	m.requests.WithLabelValues(method, code).Inc()
+	m.duration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+// SetUpstreamHealth records the health of a named upstream
+func (m *Metrics) SetUpstreamHealth(upstream string, healthy bool) {
+	m.upstreams.set(upstream, healthy)
+}
+
+// AddTransportBytes records bytes sent ("out") or received ("in") by a transport
+func (m *Metrics) AddTransportBytes(transport, direction string, n int) {
+	if n > 0 {
+		m.transportBytes.WithLabelValues(transport, direction).Add(float64(n))
+	}
+}
+
+// Handler returns the HTTP handler serving the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ListenAndServe serves the metrics endpoint on addr until ctx is cancelled
+func (m *Metrics) ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(m.config.Path, m.Handler())
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Global metrics instance
+var defaultMetrics = New(Config{IncludeRuntime: true})
+
+// SetDefault sets the default global metrics instance
+func SetDefault(m *Metrics) {
+	defaultMetrics = m
+}
+
+// Default returns the default global metrics instance
+func Default() *Metrics {
+	return defaultMetrics
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/scheduler"
+)
+
+// scrape returns the exposition text served by m
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", DefaultPath, nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New(Config{})
+
+	handler := router.NewChain(m.Middleware()).ThenFunc(
+		func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			if req.Method == "tools/call" {
+				return jsonrpc.NewErrorResponse(jsonrpc.NewMethodNotFoundError("x"), req.ID)
+			}
+			return jsonrpc.NewResponse("ok", req.ID)
+		})
+
+	handler.Handle(context.Background(), &jsonrpc.Request{ID: 1, Method: "tools/list"})
+	handler.Handle(context.Background(), &jsonrpc.Request{ID: 2, Method: "tools/list"})
+	handler.Handle(context.Background(), &jsonrpc.Request{ID: 3, Method: "tools/call"})
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `meta_mcp_requests_total{code="ok",method="tools/list"} 2`)
+	assert.Contains(t, out, `meta_mcp_requests_total{code="-32601",method="tools/call"} 1`)
+	assert.Contains(t, out, `meta_mcp_request_duration_seconds_count{method="tools/list"} 2`)
+}
+
+func TestRegisterHooks(t *testing.T) {
+	m := New(Config{Namespace: "test"})
+	hooks := &server.Hooks{}
+	m.RegisterHooks(hooks)
+
+	ctx := context.Background()
+	for _, hook := range hooks.OnBeforeAny {
+		hook(ctx, 1, "tools/call", nil)
+	}
+	for _, hook := range hooks.OnError {
+		hook(ctx, 1, "tools/call", nil, mcperrors.NewToolNotFoundError("missing"))
+	}
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `test_requests_total{code="-32041",method="tools/call"} 1`)
+}
+
+// testSession is a client session known by its ID
+type testSession string
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return string(s) }
+
+func TestRegisterHooks_Sessions(t *testing.T) {
+	m := New(Config{Namespace: "test", Buckets: []float64{0.005, 1}})
+	hooks := &server.Hooks{}
+	m.RegisterHooks(hooks)
+
+	// Two clients overlap with the same request ID
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	first := mcpServer.WithContext(context.Background(), testSession("first"))
+	second := mcpServer.WithContext(context.Background(), testSession("second"))
+	for _, hook := range hooks.OnBeforeAny {
+		hook(first, 1, "tools/call", nil)
+	}
+	time.Sleep(20 * time.Millisecond)
+	for _, hook := range hooks.OnBeforeAny {
+		hook(second, 1, "tools/call", nil)
+	}
+	for _, hook := range hooks.OnSuccess {
+		hook(first, 1, "tools/call", nil, nil)
+		hook(second, 1, "tools/call", nil, nil)
+	}
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `test_request_duration_seconds_bucket{method="tools/call",le="0.005"} 1`)
+	assert.Contains(t, out, `test_request_duration_seconds_count{method="tools/call"} 2`)
+}
+
+func TestCollectors(t *testing.T) {
+	m := New(Config{})
+
+	manager := connection.NewManager(time.Second)
+	manager.CreateConnection("a")
+	manager.CreateConnection("b")
+	require.NoError(t, m.ObserveConnections(manager))
+	require.NoError(t, m.ObserveHandshakes(protocolmcp.NewHandshakeServer(protocolmcp.DefaultHandshakeConfig())))
+
+	ar := router.NewAsyncRouter(router.AsyncRouterConfig{Router: router.New(), Workers: 1})
+	require.NoError(t, m.ObserveAsyncRouter(ar))
+
+	m.SetUpstreamHealth("github", true)
+	m.ObserveUpstreams(func() map[string]bool { return map[string]bool{"filesystem": false} })
+	m.AddTransportBytes("stdio", "out", 128)
+
+	recorder := mcperrors.NewRecorder(mcperrors.RecorderConfig{})
+	recorder.RecordSuccess()
+	recorder.RecordCode(mcperrors.ErrorCodeMCPConnectionLost)
+	require.NoError(t, m.ObserveErrors(recorder))
+
+	jobs := scheduler.New(scheduler.Config{})
+	jobs.Add(scheduler.Job{
+		Name:     "refresh",
+		Schedule: scheduler.Every(time.Hour),
+		Run:      func(ctx context.Context) (interface{}, error) { return nil, nil },
+	})
+	require.NoError(t, m.ObserveScheduler(jobs))
+	require.NoError(t, m.ObserveResourceCache(resources.NewCache(resources.CacheConfig{})))
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `meta_mcp_connections{state="new"} 2`)
+	assert.Contains(t, out, `meta_mcp_connections{state="ready"} 0`)
+	assert.Contains(t, out, `meta_mcp_handshake_duration_seconds_count{outcome="timeout"} 0`)
+	assert.Contains(t, out, `meta_mcp_queue_depth 0`)
+	assert.Contains(t, out, `meta_mcp_upstream_up{upstream="github"} 1`)
+	assert.Contains(t, out, `meta_mcp_upstream_up{upstream="filesystem"} 0`)
+	assert.Contains(t, out, `meta_mcp_transport_bytes_total{direction="out",transport="stdio"} 128`)
+	assert.Contains(t, out, `meta_mcp_error_rate{category="transport"} 0.5`)
+	assert.Contains(t, out, `meta_mcp_scheduler_runs_total{job="refresh"} 0`)
+	assert.Contains(t, out, `meta_mcp_resource_cache_lookups_total{result="miss"} 0`)
+	assert.Contains(t, out, `meta_mcp_resource_cache_entries 0`)
+
+	// Registering the same source twice is reported, not panicked
+	assert.Error(t, m.ObserveConnections(manager))
+	assert.False(t, strings.Contains(out, "go_goroutines"))
+}
+
+func TestObserveEvents(t *testing.T) {
+	m := New(Config{})
+	bus := events.New()
+	require.NoError(t, m.ObserveEvents(bus))
+
+	events.Publish(bus, events.UpstreamHealth, events.UpstreamHealthEvent{Upstream: "github", State: "connected"})
+	events.Publish(bus, events.UpstreamHealth, events.UpstreamHealthEvent{Upstream: "github", State: "disconnected"})
+	events.Publish(bus, events.QuotaExceeded, events.QuotaExceededEvent{Identity: "bot"})
+	// Closing waits for the health subscription to handle its events
+	bus.Close()
+
+	out := scrape(t, m)
+	assert.Contains(t, out, `meta_mcp_events_published_total{topic="upstream_health"} 2`)
+	assert.Contains(t, out, `meta_mcp_events_published_total{topic="quota_exceeded"} 1`)
+	assert.Contains(t, out, `meta_mcp_events_dropped_total{topic="upstream_health"} 0`)
+	assert.Contains(t, out, `meta_mcp_upstream_up{upstream="github"} 0`)
+}
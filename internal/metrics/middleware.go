@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// CodeOK is the code label recorded for successful requests
+const CodeOK = "ok"
+
+// Middleware records request counts and latency for router handlers
+func (m *Metrics) Middleware() router.Middleware {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			start := time.Now()
+			resp := next.Handle(ctx, req)
+
+			code := CodeOK
+			if resp != nil && resp.Error != nil {
+				code = strconv.Itoa(resp.Error.Code)
+			}
+			m.ObserveRequest(req.Method, code, time.Since(start))
+
+			return resp
+		})
+	}
+}
+
+// RegisterHooks records request counts and latency for an mcp-go server.
+// Requests are timed from the BeforeAny hook to the success or error hook.
+// Clients pick request IDs independently, so requests are told apart by
+// session as well.
+func (m *Metrics) RegisterHooks(hooks *server.Hooks) {
+	var inflight sync.Map
+
+	key := func(ctx context.Context, method mcp.MCPMethod, id any) string {
+		var sessionID string
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			sessionID = session.SessionID()
+		}
+		return fmt.Sprintf("%s/%s/%v", sessionID, method, id)
+	}
+	finish := func(ctx context.Context, method mcp.MCPMethod, id any, code string) {
+		var duration time.Duration
+		if start, ok := inflight.LoadAndDelete(key(ctx, method, id)); ok {
+			duration = time.Since(start.(time.Time))
+		}
+		m.ObserveRequest(string(method), code, duration)
+	}
+
+	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
+		inflight.Store(key(ctx, method, id), time.Now())
+	})
+	hooks.AddOnSuccess(func(ctx context.Context, id any, method mcp.MCPMethod, message any, result any) {
+		finish(ctx, method, id, CodeOK)
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		finish(ctx, method, id, errorCode(err))
+	})
+}
+
+// errorCode extracts a JSON-RPC error code label from err
+func errorCode(err error) string {
+	var mcpErr *mcperrors.MCPError
+	if errors.As(err, &mcpErr) {
+		return strconv.Itoa(mcpErr.Code)
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return strconv.Itoa(rpcErr.Code)
+	}
+	return strconv.Itoa(jsonrpc.ErrorCodeInternal)
+}
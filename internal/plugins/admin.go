@@ -0,0 +1,57 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// AdminMethod is the router method served by AdminHandler
+const AdminMethod = "admin/plugins"
+
+// AdminParams are the parameters of the admin/plugins method
+type AdminParams struct {
+	// Action is "list" (default), "restart" or "unload"
+	Action string `json:"action,omitempty"`
+
+	// Name is the plugin to restart or unload
+	Name string `json:"name,omitempty"`
+}
+
+// AdminResult is the result of the admin/plugins method
+type AdminResult struct {
+	Plugins []Status `json:"plugins"`
+}
+
+// AdminHandler returns a router handler listing plugins and restarting or
+// unloading them at runtime. Register it under AdminMethod.
+func AdminHandler(m *Manager) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params AdminParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+
+		var err error
+		switch params.Action {
+		case "", "list":
+		case "restart":
+			err = m.Restart(params.Name)
+		case "unload":
+			err = m.Unload(ctx, params.Name)
+		default:
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unknown action: "+params.Action), req.ID)
+		}
+		if errors.Is(err, ErrPluginNotFound) {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+		if err != nil {
+			return jsonrpc.NewErrorResponse(mcperrors.NewHandlerError(err.Error(), nil).ToJSONRPCError(), req.ID)
+		}
+
+		return jsonrpc.NewResponse(AdminResult{Plugins: m.Status()}, req.ID)
+	})
+}
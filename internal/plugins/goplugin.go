@@ -0,0 +1,27 @@
+//go:build plugins
+
+package plugins
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// openGoPlugin opens a shared object and returns the tools it exports
+func openGoPlugin(path string) ([]tools.Definition, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(GoPluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := sym.(func() []tools.Definition)
+	if !ok {
+		return nil, fmt.Errorf("symbol %s has type %T, want func() []tools.Definition", GoPluginSymbol, sym)
+	}
+	return fn(), nil
+}
@@ -0,0 +1,10 @@
+//go:build !plugins
+
+package plugins
+
+import "github.com/meta-mcp/meta-mcp-server/internal/tools"
+
+// openGoPlugin reports that Go plugin support was not compiled in
+func openGoPlugin(path string) ([]tools.Definition, error) {
+	return nil, ErrGoPluginsDisabled
+}
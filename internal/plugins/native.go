@@ -0,0 +1,99 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// goPlugin is an in-process plugin. Go plugins cannot be unloaded, so
+// stopping one only unregisters its tools.
+type goPlugin struct {
+	manager   *Manager
+	spec      Spec
+	startedAt time.Time
+
+	mu    sync.Mutex
+	state State
+	tools []string
+}
+
+// loadGoPlugin opens a Go plugin and registers its tools
+func loadGoPlugin(m *Manager, spec Spec) (*goPlugin, error) {
+	if spec.Path == "" {
+		return nil, fmt.Errorf("plugin %s: path is required", spec.Name)
+	}
+	defs, err := openGoPlugin(spec.Path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", spec.Name, err)
+	}
+	return newGoPlugin(m, spec, defs)
+}
+
+// newGoPlugin registers in-process tool definitions, isolating their panics
+func newGoPlugin(m *Manager, spec Spec, defs []tools.Definition) (*goPlugin, error) {
+	for i := range defs {
+		defs[i].Handler = recoverHandler(defs[i].Tool.Name, defs[i].Handler)
+	}
+	names, err := m.syncTools(spec, nil, defs)
+	if err != nil {
+		m.unregisterTools(names)
+		return nil, err
+	}
+	return &goPlugin{
+		manager:   m,
+		spec:      spec,
+		startedAt: time.Now(),
+		state:     StateRunning,
+		tools:     names,
+	}, nil
+}
+
+// status reports the plugin's state
+func (g *goPlugin) status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Status{
+		Name:      g.spec.Name,
+		Kind:      KindGo,
+		State:     g.state,
+		Tools:     append([]string{}, g.tools...),
+		StartedAt: g.startedAt,
+	}
+}
+
+// restart is not supported for in-process plugins
+func (g *goPlugin) restart() error {
+	return fmt.Errorf("plugin %s: go plugins cannot be restarted", g.spec.Name)
+}
+
+// stop unregisters the plugin's tools
+func (g *goPlugin) stop(ctx context.Context) error {
+	g.mu.Lock()
+	names := g.tools
+	g.tools = nil
+	g.state = StateStopped
+	g.mu.Unlock()
+
+	g.manager.unregisterTools(names)
+	return nil
+}
+
+// recoverHandler converts panics in a plugin handler into tool errors
+func recoverHandler(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				result, err = nil, mcperrors.NewToolError(name, mcperrors.FromPanic(r))
+			}
+		}()
+		return next(ctx, request)
+	}
+}
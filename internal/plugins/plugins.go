@@ -0,0 +1,456 @@
+// Package plugins loads tool handlers at runtime from plugins registered
+// with a tools.Registry.
+//
+// Two kinds of plugin are supported:
+//
+//   - Process plugins are helper executables supervised by the Manager. They
+//     speak newline-delimited JSON-RPC 2.0 over stdin and stdout and log to
+//     stderr. A crashing plugin fails only its in-flight calls; it is
+//     restarted with exponential backoff until MaxRestarts consecutive
+//     crashes, after which its tools are disabled.
+//   - Go plugins are shared objects opened with the standard plugin package.
+//     They run in-process, so only panics are isolated. Support is compiled
+//     in with the "plugins" build tag.
+//
+// # Process contract
+//
+// After starting the process the Manager sends an "initialize" request and
+// expects the plugin's tools in reply:
+//
+//	→ {"jsonrpc":"2.0","id":1,"method":"initialize","params":{"plugin":"git"}}
+//	← {"jsonrpc":"2.0","id":1,"result":{"version":"1.2.0","tools":[{"name":"git_log","description":"...","inputSchema":{...}}]}}
+//
+// Tool calls are "tools/call" requests whose result is an MCP
+// CallToolResult; a JSON-RPC error fails the call:
+//
+//	→ {"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"git_log","arguments":{"limit":5}}}
+//	← {"jsonrpc":"2.0","id":2,"result":{"content":[{"type":"text","text":"..."}]}}
+//
+// Requests may be answered in any order. The Manager closes stdin to ask the
+// plugin to exit and kills it after StopTimeout.
+//
+// # Go plugins
+//
+// A Go plugin exports a GoPluginSymbol function returning its tools:
+//
+//	func Tools() []tools.Definition
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// GoPluginSymbol is the function a Go plugin exports to provide its tools
+const GoPluginSymbol = "Tools"
+
+// Kind is the kind of plugin
+type Kind string
+
+// Plugin kinds
+const (
+	KindProcess Kind = "process"
+	KindGo      Kind = "go"
+)
+
+// State is the lifecycle state of a plugin
+type State string
+
+// Plugin states
+const (
+	StateStarting   State = "starting"
+	StateRunning    State = "running"
+	StateRestarting State = "restarting"
+	StateFailed     State = "failed"
+	StateStopped    State = "stopped"
+)
+
+var (
+	// ErrPluginExists is returned when loading a name already in use
+	ErrPluginExists = errors.New("plugin already loaded")
+
+	// ErrPluginNotFound is returned for operations on unknown plugins
+	ErrPluginNotFound = errors.New("plugin not loaded")
+
+	// ErrGoPluginsDisabled is returned when loading a Go plugin in a binary
+	// built without the "plugins" tag
+	ErrGoPluginsDisabled = errors.New("go plugins are not supported by this build")
+
+	// ErrManagerStopped is returned when loading into a shut down manager
+	ErrManagerStopped = errors.New("plugin manager stopped")
+)
+
+// Spec declares a plugin
+type Spec struct {
+	// Name identifies the plugin
+	Name string `yaml:"name" json:"name"`
+
+	// Kind selects the loader (defaults to go when Path is set and process
+	// otherwise)
+	Kind Kind `yaml:"kind,omitempty" json:"kind,omitempty"`
+
+	// Command, Args, Env and Dir start a process plugin. Env entries are
+	// added to the server's environment.
+	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
+	Env     []string `yaml:"env,omitempty" json:"env,omitempty"`
+	Dir     string   `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// Path is the shared object of a Go plugin
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// Tags are added to every tool of the plugin
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// withDefaults infers the kind when it is not set
+func (s Spec) withDefaults() Spec {
+	if s.Kind == "" {
+		s.Kind = KindProcess
+		if s.Path != "" {
+			s.Kind = KindGo
+		}
+	}
+	return s
+}
+
+// Status describes a loaded plugin
+type Status struct {
+	Name      string    `json:"name"`
+	Kind      Kind      `json:"kind"`
+	State     State     `json:"state"`
+	Version   string    `json:"version,omitempty"`
+	Tools     []string  `json:"tools"`
+	PID       int       `json:"pid,omitempty"`
+	Restarts  int       `json:"restarts"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Config contains configuration for a Manager
+type Config struct {
+	// Registry receives the plugins' tools (required)
+	Registry *tools.Registry `yaml:"-"`
+
+	// Plugins are loaded by Start
+	Plugins []Spec `yaml:"plugins"`
+
+	// StartTimeout bounds a process plugin's initialize call (defaults to 10s)
+	StartTimeout time.Duration `yaml:"startTimeout,omitempty"`
+
+	// CallTimeout bounds each tool call (defaults to 30s)
+	CallTimeout time.Duration `yaml:"callTimeout,omitempty"`
+
+	// StopTimeout is how long a process plugin may take to exit before it
+	// is killed (defaults to 5s)
+	StopTimeout time.Duration `yaml:"stopTimeout,omitempty"`
+
+	// MaxRestarts is the number of consecutive crashes tolerated before a
+	// plugin is marked failed (defaults to 5)
+	MaxRestarts int `yaml:"maxRestarts,omitempty"`
+
+	// RestartBackoff is the delay before the first restart, doubled after
+	// each consecutive crash up to MaxRestartBackoff (defaults to 500ms and
+	// 30s)
+	RestartBackoff    time.Duration `yaml:"restartBackoff,omitempty"`
+	MaxRestartBackoff time.Duration `yaml:"maxRestartBackoff,omitempty"`
+
+	// StableAfter is how long a process must run before its crash count is
+	// reset (defaults to 1m)
+	StableAfter time.Duration `yaml:"stableAfter,omitempty"`
+}
+
+// LoadConfig reads plugin declarations from a YAML file
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// instance is a loaded plugin
+type instance interface {
+	status() Status
+	restart() error
+	stop(ctx context.Context) error
+}
+
+// Manager loads plugins and supervises their lifecycle
+type Manager struct {
+	config Config
+
+	mu      sync.Mutex
+	plugins map[string]instance
+	specs   map[string]Spec
+	stopped bool
+}
+
+// New creates a plugin manager
+func New(config Config) *Manager {
+	if config.StartTimeout <= 0 {
+		config.StartTimeout = 10 * time.Second
+	}
+	if config.CallTimeout <= 0 {
+		config.CallTimeout = 30 * time.Second
+	}
+	if config.StopTimeout <= 0 {
+		config.StopTimeout = 5 * time.Second
+	}
+	if config.MaxRestarts <= 0 {
+		config.MaxRestarts = 5
+	}
+	if config.RestartBackoff <= 0 {
+		config.RestartBackoff = 500 * time.Millisecond
+	}
+	if config.MaxRestartBackoff <= 0 {
+		config.MaxRestartBackoff = 30 * time.Second
+	}
+	if config.StableAfter <= 0 {
+		config.StableAfter = time.Minute
+	}
+
+	return &Manager{
+		config:  config,
+		plugins: make(map[string]instance),
+		specs:   make(map[string]Spec),
+	}
+}
+
+// Start loads every configured plugin, returning the errors of those that
+// failed to load
+func (m *Manager) Start(ctx context.Context) error {
+	var errs []error
+	for _, spec := range m.config.Plugins {
+		if err := m.Load(ctx, spec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Load starts a plugin and registers its tools
+func (m *Manager) Load(ctx context.Context, spec Spec) error {
+	if spec.Name == "" {
+		return errors.New("plugin name is required")
+	}
+	spec = spec.withDefaults()
+
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return ErrManagerStopped
+	}
+	if _, ok := m.plugins[spec.Name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrPluginExists, spec.Name)
+	}
+	// Reserve the name while starting
+	m.plugins[spec.Name] = nil
+	m.mu.Unlock()
+
+	var (
+		inst instance
+		err  error
+	)
+	switch spec.Kind {
+	case KindProcess:
+		if spec.Command == "" {
+			err = fmt.Errorf("plugin %s: command is required", spec.Name)
+			break
+		}
+		inst, err = startProcess(ctx, m, spec)
+	case KindGo:
+		inst, err = loadGoPlugin(m, spec)
+	default:
+		err = fmt.Errorf("plugin %s: unknown kind %q", spec.Name, spec.Kind)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		delete(m.plugins, spec.Name)
+		return err
+	}
+	m.plugins[spec.Name] = inst
+	m.specs[spec.Name] = spec
+	return nil
+}
+
+// Unload stops a plugin and unregisters its tools
+func (m *Manager) Unload(ctx context.Context, name string) error {
+	m.mu.Lock()
+	inst, ok := m.plugins[name]
+	if !ok || inst == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+	}
+	delete(m.plugins, name)
+	delete(m.specs, name)
+	m.mu.Unlock()
+
+	return inst.stop(ctx)
+}
+
+// Reload makes the loaded plugins match specs: plugins no longer declared
+// are unloaded, changed ones are reloaded and new ones are loaded. Plugins
+// whose spec is unchanged keep running. It returns the errors of plugins
+// that failed to load.
+func (m *Manager) Reload(ctx context.Context, specs []Spec) error {
+	wanted := make(map[string]Spec, len(specs))
+	for _, spec := range specs {
+		wanted[spec.Name] = spec.withDefaults()
+	}
+
+	m.mu.Lock()
+	loaded := make(map[string]Spec, len(m.specs))
+	for name, spec := range m.specs {
+		loaded[name] = spec
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for name, spec := range loaded {
+		if next, ok := wanted[name]; ok && reflect.DeepEqual(spec, next) {
+			delete(wanted, name)
+			continue
+		}
+		if err := m.Unload(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := m.Load(ctx, wanted[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Restart restarts a process plugin, clearing its crash count so a failed
+// plugin is given another chance
+func (m *Manager) Restart(name string) error {
+	m.mu.Lock()
+	inst, ok := m.plugins[name]
+	m.mu.Unlock()
+	if !ok || inst == nil {
+		return fmt.Errorf("%w: %s", ErrPluginNotFound, name)
+	}
+	return inst.restart()
+}
+
+// Status returns the status of every loaded plugin sorted by name
+func (m *Manager) Status() []Status {
+	m.mu.Lock()
+	insts := make([]instance, 0, len(m.plugins))
+	for _, inst := range m.plugins {
+		if inst != nil {
+			insts = append(insts, inst)
+		}
+	}
+	m.mu.Unlock()
+
+	statuses := make([]Status, len(insts))
+	for i, inst := range insts {
+		statuses[i] = inst.status()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Shutdown stops every plugin; no plugins can be loaded afterwards
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopped = true
+	plugins := m.plugins
+	m.plugins = make(map[string]instance)
+	m.specs = make(map[string]Spec)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, inst := range plugins {
+		if inst == nil {
+			continue
+		}
+		if err := inst.stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncTools registers defs for a plugin, updating tools it already owns and
+// unregistering owned tools that are no longer provided. It returns the
+// names of the tools the plugin now owns.
+func (m *Manager) syncTools(spec Spec, owned []string, defs []tools.Definition) ([]string, error) {
+	registry := m.config.Registry
+	provided := make(map[string]bool, len(defs))
+	names := make([]string, 0, len(defs))
+	previous := make(map[string]bool, len(owned))
+	for _, name := range owned {
+		previous[name] = true
+	}
+
+	for _, def := range defs {
+		tags := append([]string{"plugin", "plugin:" + spec.Name}, def.Tags...)
+		def.Tags = append(tags, spec.Tags...)
+		name := def.Tool.Name
+
+		var err error
+		if previous[name] {
+			err = registry.Update(def)
+			if err == nil {
+				err = registry.Enable(name)
+			}
+		} else {
+			err = registry.Register(def)
+		}
+		if err != nil {
+			return names, fmt.Errorf("plugin %s: %w", spec.Name, err)
+		}
+		provided[name] = true
+		names = append(names, name)
+	}
+
+	for _, name := range owned {
+		if !provided[name] {
+			registry.Unregister(name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// disableTools hides a plugin's tools while it is unavailable
+func (m *Manager) disableTools(names []string) {
+	for _, name := range names {
+		m.config.Registry.Disable(name)
+	}
+}
+
+// unregisterTools removes a plugin's tools
+func (m *Manager) unregisterTools(names []string) {
+	for _, name := range names {
+		m.config.Registry.Unregister(name)
+	}
+}
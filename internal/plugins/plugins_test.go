@@ -0,0 +1,264 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// TestMain lets the test binary act as a process plugin
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("PLUGIN_HELPER"); mode != "" {
+		runHelperPlugin(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runHelperPlugin implements the process contract for tests
+func runHelperPlugin(mode string) {
+	if mode == "exit" {
+		os.Exit(2)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     int64          `json:"id"`
+			Method string         `json:"method"`
+			Params CallToolParams `json:"params"`
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+
+		switch req.Method {
+		case MethodInitialize:
+			fmt.Fprintln(os.Stderr, "helper starting")
+			out.Encode(jsonrpc.NewResponse(InitializeResult{
+				Version: "1.0.0",
+				Tools: []ToolDescriptor{
+					{Name: "helper_echo", Description: "Echo a message", InputSchema: json.RawMessage(`{"type":"object","properties":{"message":{"type":"string"}}}`)},
+					{Name: "helper_crash"},
+					{Name: "helper_fail"},
+				},
+			}, req.ID))
+			if mode == "crashloop" {
+				time.Sleep(10 * time.Millisecond)
+				os.Exit(3)
+			}
+		case MethodCallTool:
+			switch req.Params.Name {
+			case "helper_echo":
+				out.Encode(jsonrpc.NewResponse(mcp.NewToolResultText(fmt.Sprint(req.Params.Arguments["message"])), req.ID))
+			case "helper_crash":
+				os.Exit(3)
+			default:
+				out.Encode(jsonrpc.NewErrorResponse(jsonrpc.NewError(-32000, "upstream failure", nil), req.ID))
+			}
+		}
+	}
+}
+
+func helperSpec(t *testing.T, name, mode string) Spec {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	return Spec{Name: name, Command: exe, Env: []string{"PLUGIN_HELPER=" + mode}, Tags: []string{"test"}}
+}
+
+func newTestManager(registry *tools.Registry) *Manager {
+	return New(Config{
+		Registry:          registry,
+		StartTimeout:      5 * time.Second,
+		StopTimeout:       time.Second,
+		MaxRestarts:       2,
+		RestartBackoff:    10 * time.Millisecond,
+		MaxRestartBackoff: 20 * time.Millisecond,
+	})
+}
+
+func callTool(registry *tools.Registry, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return registry.Call(context.Background(), request)
+}
+
+func TestProcessPlugin(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	m := newTestManager(registry)
+	ctx := context.Background()
+
+	require.NoError(t, m.Load(ctx, helperSpec(t, "helper", "serve")))
+	assert.ErrorIs(t, m.Load(ctx, helperSpec(t, "helper", "serve")), ErrPluginExists)
+
+	infos := registry.List("plugin:helper")
+	require.Len(t, infos, 3)
+	assert.Equal(t, "1.0.0", infos[0].Version)
+	assert.Contains(t, infos[0].Tags, "test")
+
+	result, err := callTool(registry, "helper_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Content[0].(mcp.TextContent).Text)
+
+	_, err = callTool(registry, "helper_fail", nil)
+	assert.Equal(t, mcperrors.ErrorCodeMCPToolError, mcperrors.FindMCPError(err).Code)
+
+	// A crash fails only the call in flight; the plugin is restarted
+	_, err = callTool(registry, "helper_crash", nil)
+	assert.Equal(t, mcperrors.ErrorCodeMCPServiceUnavail, mcperrors.FindMCPError(err).Code)
+	require.Eventually(t, func() bool {
+		status := m.Status()[0]
+		return status.State == StateRunning && status.Restarts == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	result, err = callTool(registry, "helper_echo", map[string]any{"message": "again"})
+	require.NoError(t, err)
+	assert.Equal(t, "again", result.Content[0].(mcp.TextContent).Text)
+	assert.NotZero(t, m.Status()[0].PID)
+
+	require.NoError(t, m.Unload(ctx, "helper"))
+	assert.Empty(t, registry.List(""))
+	assert.ErrorIs(t, m.Unload(ctx, "helper"), ErrPluginNotFound)
+}
+
+func TestProcessPlugin_CrashLoop(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	m := newTestManager(registry)
+	defer m.Shutdown(context.Background())
+
+	require.NoError(t, m.Load(context.Background(), helperSpec(t, "flaky", "crashloop")))
+	require.Eventually(t, func() bool { return m.Status()[0].State == StateFailed }, 5*time.Second, 5*time.Millisecond)
+
+	status := m.Status()[0]
+	assert.Equal(t, 2, status.Restarts)
+	assert.NotEmpty(t, status.LastError)
+
+	info, ok := registry.Get("helper_echo")
+	require.True(t, ok)
+	assert.False(t, info.Enabled)
+
+	// Restarting a failed plugin resumes supervision
+	require.NoError(t, m.Restart("flaky"))
+	require.Eventually(t, func() bool { return m.Status()[0].Restarts > 2 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestManager_LoadErrors(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	m := newTestManager(registry)
+	ctx := context.Background()
+
+	assert.Error(t, m.Load(ctx, Spec{}))
+	assert.Error(t, m.Load(ctx, Spec{Name: "nocommand", Kind: KindProcess}))
+	assert.Error(t, m.Load(ctx, Spec{Name: "missing", Command: filepath.Join(t.TempDir(), "missing")}))
+	assert.Error(t, m.Load(ctx, helperSpec(t, "exits", "exit")))
+	assert.Error(t, m.Load(ctx, Spec{Name: "weird", Kind: "wasm"}))
+	assert.ErrorIs(t, m.Load(ctx, Spec{Name: "so", Path: "plugin.so"}), ErrGoPluginsDisabled)
+	assert.Empty(t, m.Status())
+
+	require.NoError(t, m.Shutdown(ctx))
+	assert.ErrorIs(t, m.Load(ctx, helperSpec(t, "late", "serve")), ErrManagerStopped)
+}
+
+func TestManager_Reload(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	m := newTestManager(registry)
+	ctx := context.Background()
+	defer m.Shutdown(ctx)
+
+	spec := helperSpec(t, "helper", "serve")
+	require.NoError(t, m.Reload(ctx, []Spec{spec}))
+	pid := m.Status()[0].PID
+
+	// An unchanged spec keeps the plugin running
+	require.NoError(t, m.Reload(ctx, []Spec{spec}))
+	assert.Equal(t, pid, m.Status()[0].PID)
+
+	// A changed spec restarts it
+	spec.Tags = []string{"changed"}
+	require.NoError(t, m.Reload(ctx, []Spec{spec}))
+	assert.NotEqual(t, pid, m.Status()[0].PID)
+	info, ok := registry.Get("helper_echo")
+	require.True(t, ok)
+	assert.Contains(t, info.Tags, "changed")
+
+	// Failures are reported; removed plugins are unloaded
+	assert.Error(t, m.Reload(ctx, []Spec{{Name: "nocommand", Kind: KindProcess}}))
+	assert.Empty(t, m.Status())
+	assert.Empty(t, registry.List(""))
+}
+
+func TestGoPlugin_RecoversPanics(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	m := newTestManager(registry)
+
+	p, err := newGoPlugin(m, Spec{Name: "native", Kind: KindGo}, []tools.Definition{{
+		Tool: mcp.NewTool("native_panic"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			panic("boom")
+		},
+	}})
+	require.NoError(t, err)
+
+	_, err = callTool(registry, "native_panic", nil)
+	assert.Equal(t, mcperrors.ErrorCodeMCPToolError, mcperrors.FindMCPError(err).Code)
+	assert.Error(t, p.restart())
+
+	require.NoError(t, p.stop(context.Background()))
+	assert.Empty(t, registry.List(""))
+}
+
+func TestAdminHandler(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	m := newTestManager(registry)
+	defer m.Shutdown(context.Background())
+	require.NoError(t, m.Load(context.Background(), helperSpec(t, "helper", "serve")))
+
+	handler := AdminHandler(m)
+	resp := handler.Handle(context.Background(), jsonrpc.NewRequest(AdminMethod, nil, 1))
+	require.Nil(t, resp.Error)
+	assert.Len(t, resp.Result.(AdminResult).Plugins, 1)
+
+	resp = handler.Handle(context.Background(), jsonrpc.NewRequest(AdminMethod, map[string]any{"action": "restart", "name": "nope"}, 2))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, resp.Error.Code)
+
+	resp = handler.Handle(context.Background(), jsonrpc.NewRequest(AdminMethod, map[string]any{"action": "unload", "name": "helper"}, 3))
+	require.Nil(t, resp.Error)
+	assert.Empty(t, resp.Result.(AdminResult).Plugins)
+
+	resp = handler.Handle(context.Background(), jsonrpc.NewRequest(AdminMethod, map[string]any{"action": "explode"}, 4))
+	require.NotNil(t, resp.Error)
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+callTimeout: 5s
+plugins:
+  - name: git
+    command: meta-git-plugin
+    args: ["--repo", "."]
+  - name: native
+    path: ./native.so
+`), 0o644))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, config.CallTimeout)
+	require.Len(t, config.Plugins, 2)
+	assert.Equal(t, []string{"--repo", "."}, config.Plugins[0].Args)
+	assert.Equal(t, "./native.so", config.Plugins[1].Path)
+}
@@ -0,0 +1,521 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// Process contract methods
+const (
+	MethodInitialize = "initialize"
+	MethodCallTool   = "tools/call"
+)
+
+// maxMessageSize bounds a single line written by a process plugin
+const maxMessageSize = 16 << 20
+
+// errProcessExited fails calls in flight when a process plugin exits
+var errProcessExited = errors.New("plugin process exited")
+
+// InitializeParams are the parameters of the initialize request
+type InitializeParams struct {
+	Plugin string `json:"plugin"`
+}
+
+// InitializeResult is a process plugin's reply to initialize
+type InitializeResult struct {
+	Version string           `json:"version,omitempty"`
+	Tools   []ToolDescriptor `json:"tools"`
+}
+
+// ToolDescriptor describes a tool provided by a process plugin
+type ToolDescriptor struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	InputSchema json.RawMessage    `json:"inputSchema,omitempty"`
+	Annotations mcp.ToolAnnotation `json:"annotations,omitempty"`
+}
+
+// CallToolParams are the parameters of a tools/call request
+type CallToolParams struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// processPlugin supervises a helper process
+type processPlugin struct {
+	manager *Manager
+	spec    Spec
+	stopCh  chan struct{}
+	done    chan struct{}
+
+	mu        sync.Mutex
+	state     State
+	current   *processRun
+	version   string
+	tools     []string
+	restarts  int
+	crashes   int
+	lastError string
+	stopping  bool
+}
+
+// processRun is one execution of a plugin process
+type processRun struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	startedAt time.Time
+	nextID    atomic.Int64
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan *rpcResponse
+
+	exited  chan struct{}
+	exitErr error
+}
+
+// rpcResponse is a response line read from a plugin
+type rpcResponse struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc.Error  `json:"error"`
+}
+
+// startProcess launches a process plugin, registers its tools and starts
+// supervising it
+func startProcess(ctx context.Context, m *Manager, spec Spec) (*processPlugin, error) {
+	p := &processPlugin{
+		manager: m,
+		spec:    spec,
+		state:   StateStarting,
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := p.launch(ctx); err != nil {
+		close(p.done)
+		return nil, fmt.Errorf("plugin %s: %w", spec.Name, err)
+	}
+	go p.supervise()
+	return p, nil
+}
+
+// launch starts the process and initializes it
+func (p *processPlugin) launch(ctx context.Context) error {
+	run, err := p.spawn()
+	if err != nil {
+		return err
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, p.manager.config.StartTimeout)
+	defer cancel()
+	var result InitializeResult
+	if err := run.call(initCtx, MethodInitialize, InitializeParams{Plugin: p.spec.Name}, &result); err != nil {
+		run.kill()
+		return fmt.Errorf("initialize: %w", err)
+	}
+
+	defs := make([]tools.Definition, 0, len(result.Tools))
+	for _, desc := range result.Tools {
+		defs = append(defs, p.definition(desc, result.Version))
+	}
+
+	p.mu.Lock()
+	owned := p.tools
+	p.mu.Unlock()
+	names, err := p.manager.syncTools(p.spec, owned, defs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tools = names
+	if err != nil {
+		run.kill()
+		return err
+	}
+	p.current = run
+	p.version = result.Version
+	p.state = StateRunning
+	return nil
+}
+
+// spawn starts the process and its I/O goroutines
+func (p *processPlugin) spawn() (*processRun, error) {
+	cmd := exec.Command(p.spec.Command, p.spec.Args...)
+	cmd.Dir = p.spec.Dir
+	cmd.Env = append(os.Environ(), p.spec.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	run := &processRun{
+		cmd:       cmd,
+		stdin:     stdin,
+		startedAt: time.Now(),
+		pending:   make(map[int64]chan *rpcResponse),
+		exited:    make(chan struct{}),
+	}
+
+	logger := logging.Default().WithField("plugin", p.spec.Name)
+	ctx := logging.WithComponent(context.Background(), "plugins")
+	// Both pipes must be drained before Wait closes them
+	var output sync.WaitGroup
+	output.Add(2)
+	go func() {
+		defer output.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.WithField("pid", cmd.Process.Pid).Info(ctx, scanner.Text())
+		}
+	}()
+	go func() {
+		defer output.Done()
+		run.read(stdout, logger, ctx)
+	}()
+	go func() {
+		output.Wait()
+		run.exitErr = cmd.Wait()
+		run.failPending()
+		close(run.exited)
+	}()
+
+	return run, nil
+}
+
+// supervise restarts the process whenever it exits unexpectedly
+func (p *processPlugin) supervise() {
+	defer close(p.done)
+	ctx := logging.WithComponent(context.Background(), "plugins")
+	logger := logging.Default().WithField("plugin", p.spec.Name)
+
+	for {
+		p.mu.Lock()
+		run := p.current
+		p.mu.Unlock()
+
+		if run != nil {
+			select {
+			case <-run.exited:
+			case <-p.stopCh:
+				return
+			}
+		}
+
+		p.mu.Lock()
+		if p.stopping {
+			p.mu.Unlock()
+			return
+		}
+		if run != nil {
+			if time.Since(run.startedAt) >= p.manager.config.StableAfter {
+				p.crashes = 0
+			}
+			p.lastError = exitReason(run.exitErr)
+			logger.Error(ctx, errors.New(p.lastError), "Plugin process exited")
+		}
+		p.current = nil
+		p.crashes++
+		if p.crashes > p.manager.config.MaxRestarts {
+			p.state = StateFailed
+			names := p.tools
+			p.mu.Unlock()
+			p.manager.disableTools(names)
+			logger.Error(ctx, errors.New(p.lastError), "Plugin failed too many times; tools disabled")
+			return
+		}
+		p.state = StateRestarting
+		delay := p.backoff()
+		p.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-p.stopCh:
+			return
+		}
+
+		p.mu.Lock()
+		p.restarts++
+		p.mu.Unlock()
+		if err := p.launch(context.Background()); err != nil {
+			p.mu.Lock()
+			p.lastError = err.Error()
+			p.mu.Unlock()
+			logger.Error(ctx, err, "Plugin restart failed")
+		}
+	}
+}
+
+// backoff returns the delay before the next restart. Caller must hold p.mu.
+func (p *processPlugin) backoff() time.Duration {
+	config := p.manager.config
+	delay := config.RestartBackoff
+	for i := 1; i < p.crashes && delay < config.MaxRestartBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, config.MaxRestartBackoff)
+}
+
+// definition converts a tool descriptor to a registry definition
+func (p *processPlugin) definition(desc ToolDescriptor, version string) tools.Definition {
+	schema := desc.InputSchema
+	if len(schema) == 0 {
+		schema = json.RawMessage(`{"type":"object"}`)
+	}
+	tool := mcp.NewToolWithRawSchema(desc.Name, desc.Description, schema)
+	tool.Annotations = desc.Annotations
+
+	return tools.Definition{
+		Tool:    tool,
+		Handler: p.handler(desc.Name),
+		Version: version,
+		Source:  p.spec.Name,
+	}
+}
+
+// handler forwards calls of a tool to the current process
+func (p *processPlugin) handler(name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		p.mu.Lock()
+		run, state := p.current, p.state
+		p.mu.Unlock()
+		if run == nil {
+			return nil, mcperrors.NewServiceUnavailableError("plugin "+p.spec.Name, string(state))
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, p.manager.config.CallTimeout)
+		defer cancel()
+
+		var raw json.RawMessage
+		err := run.call(ctx, MethodCallTool, CallToolParams{Name: name, Arguments: request.GetArguments()}, &raw)
+		switch {
+		case errors.Is(err, errProcessExited):
+			return nil, mcperrors.NewServiceUnavailableError("plugin "+p.spec.Name, "process exited")
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, mcperrors.NewTransportTimeoutError("plugin "+p.spec.Name+" "+MethodCallTool,
+				p.manager.config.CallTimeout.String())
+		case err != nil:
+			return nil, mcperrors.NewToolError(name, err)
+		}
+
+		result, err := mcp.ParseCallToolResult(&raw)
+		if err != nil {
+			return nil, mcperrors.NewToolError(name, err)
+		}
+		return result, nil
+	}
+}
+
+// status reports the plugin's state
+func (p *processPlugin) status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := Status{
+		Name:      p.spec.Name,
+		Kind:      KindProcess,
+		State:     p.state,
+		Version:   p.version,
+		Tools:     append([]string{}, p.tools...),
+		Restarts:  p.restarts,
+		LastError: p.lastError,
+	}
+	if p.current != nil {
+		s.PID = p.current.cmd.Process.Pid
+		s.StartedAt = p.current.startedAt
+	}
+	return s
+}
+
+// restart kills the running process so the supervisor relaunches it, or
+// resumes supervision of a failed plugin
+func (p *processPlugin) restart() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopping {
+		return fmt.Errorf("%w: %s", ErrPluginNotFound, p.spec.Name)
+	}
+	p.crashes = 0
+	switch {
+	case p.state == StateFailed:
+		p.state = StateRestarting
+		p.done = make(chan struct{})
+		go p.supervise()
+	case p.current != nil:
+		p.current.kill()
+	}
+	return nil
+}
+
+// stop asks the process to exit, killing it after StopTimeout, and
+// unregisters its tools
+func (p *processPlugin) stop(ctx context.Context) error {
+	p.mu.Lock()
+	if p.stopping {
+		p.mu.Unlock()
+		return nil
+	}
+	p.stopping = true
+	close(p.stopCh)
+	done := p.done
+	p.mu.Unlock()
+
+	// Wait for the supervisor so a launch in progress cannot race the stop
+	<-done
+
+	p.mu.Lock()
+	p.state = StateStopped
+	run, names := p.current, p.tools
+	p.current = nil
+	p.tools = nil
+	p.mu.Unlock()
+
+	p.manager.unregisterTools(names)
+	if run == nil {
+		return nil
+	}
+	run.stdin.Close()
+
+	timer := time.NewTimer(p.manager.config.StopTimeout)
+	defer timer.Stop()
+	select {
+	case <-run.exited:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	run.kill()
+	<-run.exited
+	return nil
+}
+
+// call sends a request and decodes its result into result
+func (r *processRun) call(ctx context.Context, method string, params any, result any) error {
+	id := r.nextID.Add(1)
+	ch := make(chan *rpcResponse, 1)
+
+	r.mu.Lock()
+	if r.pending == nil {
+		r.mu.Unlock()
+		return errProcessExited
+	}
+	r.pending[id] = ch
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		if r.pending != nil {
+			delete(r.pending, id)
+		}
+		r.mu.Unlock()
+	}()
+
+	line, err := json.Marshal(jsonrpc.NewRequest(method, params, id))
+	if err != nil {
+		return err
+	}
+	r.writeMu.Lock()
+	_, err = r.stdin.Write(append(line, '\n'))
+	r.writeMu.Unlock()
+	if err != nil {
+		return errProcessExited
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return errProcessExited
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// read dispatches responses written by the process until stdout closes
+func (r *processRun) read(stdout io.Reader, logger *logging.Logger, ctx context.Context) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var resp rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil || resp.ID == nil {
+			if resp.Method == "" {
+				logger.Warn(ctx, "Ignoring malformed plugin output")
+			}
+			continue
+		}
+
+		r.mu.Lock()
+		ch, ok := r.pending[*resp.ID]
+		delete(r.pending, *resp.ID)
+		r.mu.Unlock()
+		if ok {
+			ch <- &resp
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Error(ctx, err, "Plugin output unreadable; stopping plugin")
+		r.kill()
+	}
+}
+
+// failPending fails every call in flight
+func (r *processRun) failPending() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ch := range r.pending {
+		close(ch)
+	}
+	r.pending = nil
+}
+
+// kill terminates the process
+func (r *processRun) kill() {
+	if r.cmd.Process != nil {
+		r.cmd.Process.Kill()
+	}
+}
+
+// exitReason describes how a process exited
+func exitReason(err error) string {
+	if err == nil {
+		return "exited with status 0"
+	}
+	return err.Error()
+}
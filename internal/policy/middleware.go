@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// callToolParams is the subset of tools/call params inspected by the policy layer
+type callToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// Middleware rejects tools/call requests that violate the engine's policies
+// before they reach the handler. Dry runs are checked the same way, so a
+// planning step learns about violations before the real call.
+func Middleware(e *Engine) router.Middleware {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			if req.Method != "tools/call" {
+				return next.Handle(ctx, req)
+			}
+
+			var params callToolParams
+			if err := req.BindParams(&params); err != nil {
+				return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+			}
+
+			if err := e.Check(params.Name, params.Arguments); err != nil {
+				return jsonrpc.NewErrorResponse(err.ToJSONRPCError(), req.ID)
+			}
+
+			return next.Handle(ctx, req)
+		})
+	}
+}
+
+// ToolMiddleware rejects mcp-go tool calls that violate the engine's
+// policies. It is installed with server.WithToolHandlerMiddleware.
+func ToolMiddleware(e *Engine) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := e.Check(request.Params.Name, request.GetArguments()); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
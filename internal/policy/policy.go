@@ -0,0 +1,290 @@
+// Package policy implements pre-dispatch argument checks for tools/call.
+//
+// A policy Engine validates tool arguments against per-tool constraints
+// before the tool handler runs, limiting the blast radius of prompt-injected
+// calls. Supported constraints:
+//
+//   - Path rules: the argument must resolve (after symlink evaluation) to a
+//     location under one of the allowed roots.
+//   - URL rules: the argument must be a URL with an allowed scheme whose host
+//     matches the domain allowlist ("example.com" or "*.example.com").
+//   - Length limits: string arguments, including strings nested in objects
+//     and arrays, must not exceed a maximum length.
+//
+// Basic usage:
+//
+//	engine := policy.NewEngine(policy.Config{
+//		Default: policy.ToolPolicy{MaxStringLength: 4096},
+//		Tools: map[string]policy.ToolPolicy{
+//			"read_file": {
+//				Arguments: map[string]policy.ArgumentRule{
+//					"path": {Path: &policy.PathRule{Roots: []string{"/srv/data"}}},
+//				},
+//			},
+//		},
+//	})
+//
+//	if err := engine.Check("read_file", args); err != nil {
+//		// err is a Forbidden MCPError listing every violation
+//	}
+package policy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// ArgumentRule constrains a single named argument
+type ArgumentRule struct {
+	// Path requires the argument to be a path under an allowed root
+	Path *PathRule `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// URL requires the argument to be a URL on an allowed domain
+	URL *URLRule `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// MaxLength limits the argument's string length (0 = unlimited)
+	MaxLength int `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+
+	// Required rejects calls that omit the argument
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// ToolPolicy contains the constraints for one tool
+type ToolPolicy struct {
+	// Arguments maps argument names to their rules
+	Arguments map[string]ArgumentRule `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+
+	// MaxStringLength limits every string value in the arguments (0 = unlimited)
+	MaxStringLength int `json:"maxStringLength,omitempty" yaml:"maxStringLength,omitempty"`
+}
+
+// Config contains configuration for an Engine
+type Config struct {
+	// Default applies to tools without an entry in Tools
+	Default ToolPolicy `json:"default" yaml:"default"`
+
+	// Tools maps tool names to their policies
+	Tools map[string]ToolPolicy `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// LoadConfig reads a policy configuration from a YAML file
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Violation describes a single failed constraint
+type Violation struct {
+	Tool     string `json:"tool"`
+	Argument string `json:"argument"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// String returns a human-readable description of the violation
+func (v Violation) String() string {
+	return fmt.Sprintf("%s.%s: %s", v.Tool, v.Argument, v.Message)
+}
+
+// Stats contains policy engine statistics
+type Stats struct {
+	Checked  int64            `json:"checked"`
+	Rejected int64            `json:"rejected"`
+	ByRule   map[string]int64 `json:"byRule"`
+}
+
+// Engine evaluates tool calls against the configured policies
+type Engine struct {
+	mu     sync.RWMutex
+	config Config
+	stats  Stats
+}
+
+// NewEngine creates a new policy engine
+func NewEngine(config Config) *Engine {
+	return &Engine{
+		config: config,
+		stats:  Stats{ByRule: make(map[string]int64)},
+	}
+}
+
+// SetPolicy sets or replaces the policy for a tool
+func (e *Engine) SetPolicy(tool string, p ToolPolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.config.Tools == nil {
+		e.config.Tools = make(map[string]ToolPolicy)
+	}
+	e.config.Tools[tool] = p
+}
+
+// PolicyFor returns the policy applied to a tool
+func (e *Engine) PolicyFor(tool string) ToolPolicy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if p, ok := e.config.Tools[tool]; ok {
+		return p
+	}
+	return e.config.Default
+}
+
+// Evaluate returns every violation of the tool's policy by args
+func (e *Engine) Evaluate(tool string, args map[string]interface{}) []Violation {
+	p := e.PolicyFor(tool)
+	var violations []Violation
+
+	names := make([]string, 0, len(p.Arguments))
+	for name := range p.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rule := p.Arguments[name]
+		value, present := args[name]
+		if !present || value == nil {
+			if rule.Required {
+				violations = append(violations, Violation{tool, name, "required", "argument is required"})
+			}
+			continue
+		}
+		for _, v := range rule.check(value) {
+			v.Tool, v.Argument = tool, name
+			violations = append(violations, v)
+		}
+	}
+
+	if p.MaxStringLength > 0 {
+		keys := make([]string, 0, len(args))
+		for k := range args {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if path, n := oversizedString(k, args[k], p.MaxStringLength); n > 0 {
+				violations = append(violations, Violation{tool, path, "max_string_length",
+					fmt.Sprintf("string length %d exceeds maximum %d", n, p.MaxStringLength)})
+			}
+		}
+	}
+
+	e.mu.Lock()
+	e.stats.Checked++
+	if len(violations) > 0 {
+		e.stats.Rejected++
+		for _, v := range violations {
+			e.stats.ByRule[v.Rule]++
+		}
+	}
+	e.mu.Unlock()
+
+	return violations
+}
+
+// Check evaluates args and returns a Forbidden error listing the violations,
+// or nil if the call is allowed
+func (e *Engine) Check(tool string, args map[string]interface{}) *mcperrors.MCPError {
+	violations := e.Evaluate(tool, args)
+	if len(violations) == 0 {
+		return nil
+	}
+	return NewViolationError(tool, violations)
+}
+
+// GetStats returns policy engine statistics
+func (e *Engine) GetStats() Stats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	byRule := make(map[string]int64, len(e.stats.ByRule))
+	for k, v := range e.stats.ByRule {
+		byRule[k] = v
+	}
+	return Stats{
+		Checked:  e.stats.Checked,
+		Rejected: e.stats.Rejected,
+		ByRule:   byRule,
+	}
+}
+
+// NewViolationError creates the error returned for rejected tool calls
+func NewViolationError(tool string, violations []Violation) *mcperrors.MCPError {
+	message := "Tool arguments violate policy"
+	if len(violations) == 1 {
+		message = fmt.Sprintf("Tool arguments violate policy: %s", violations[0])
+	}
+
+	err := mcperrors.NewMCPError(mcperrors.ErrorCodeMCPForbidden, message, map[string]interface{}{
+		"tool":       tool,
+		"violations": violations,
+	})
+	err.WithContext("tool", tool)
+	return err
+}
+
+// check applies the rule to a present argument value
+func (r ArgumentRule) check(value interface{}) []Violation {
+	s, isString := value.(string)
+
+	var violations []Violation
+	if (r.Path != nil || r.URL != nil) && !isString {
+		return []Violation{{Rule: "type", Message: fmt.Sprintf("expected string, got %T", value)}}
+	}
+	if r.MaxLength > 0 && isString && len(s) > r.MaxLength {
+		violations = append(violations, Violation{Rule: "max_length",
+			Message: fmt.Sprintf("length %d exceeds maximum %d", len(s), r.MaxLength)})
+	}
+	if r.Path != nil {
+		if err := r.Path.Check(s); err != nil {
+			violations = append(violations, Violation{Rule: "path", Message: err.Error()})
+		}
+	}
+	if r.URL != nil {
+		if err := r.URL.Check(s); err != nil {
+			violations = append(violations, Violation{Rule: "url", Message: err.Error()})
+		}
+	}
+	return violations
+}
+
+// oversizedString finds the first string nested in value longer than limit,
+// returning its dotted path and length
+func oversizedString(path string, value interface{}, limit int) (string, int) {
+	switch v := value.(type) {
+	case string:
+		if len(v) > limit {
+			return path, len(v)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, n := oversizedString(path+"."+k, v[k], limit); n > 0 {
+				return p, n
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if p, n := oversizedString(fmt.Sprintf("%s[%d]", path, i), item, limit); n > 0 {
+				return p, n
+			}
+		}
+	}
+	return "", 0
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathRule requires a path argument to stay under one of the allowed roots
+type PathRule struct {
+	// Roots lists the directories the path must resolve under
+	Roots []string `json:"roots" yaml:"roots"`
+
+	// AllowRelative resolves relative paths against the first root instead
+	// of rejecting them
+	AllowRelative bool `json:"allowRelative,omitempty" yaml:"allowRelative,omitempty"`
+}
+
+// Check returns an error if path escapes the allowed roots. Symlinks are
+// resolved for the longest existing prefix of the path so a link inside a
+// root cannot point outside it.
+func (r *PathRule) Check(path string) error {
+	if len(r.Roots) == 0 {
+		return fmt.Errorf("no allowed roots configured")
+	}
+	if strings.ContainsRune(path, 0) {
+		return fmt.Errorf("path contains NUL byte")
+	}
+
+	if !filepath.IsAbs(path) {
+		if !r.AllowRelative {
+			return fmt.Errorf("path %q must be absolute", path)
+		}
+		path = filepath.Join(r.Roots[0], path)
+	}
+
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return fmt.Errorf("cannot resolve path %q: %w", path, err)
+	}
+
+	for _, root := range r.Roots {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		if within(resolvedRoot, resolved) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("path %q is outside the allowed roots", path)
+}
+
+// resolvePath cleans path and evaluates symlinks of its longest existing
+// prefix, re-appending any components that do not exist yet
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	existing := abs
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{resolved}, rest...)...), nil
+}
+
+// within reports whether path is root or a descendant of root
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// URLRule requires a URL argument to use an allowed scheme and domain
+type URLRule struct {
+	// Domains lists allowed hosts; "*.example.com" matches any subdomain
+	Domains []string `json:"domains" yaml:"domains"`
+
+	// Schemes lists allowed schemes (defaults to https)
+	Schemes []string `json:"schemes,omitempty" yaml:"schemes,omitempty"`
+}
+
+// Check returns an error if raw is not an allowed URL
+func (r *URLRule) Check(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	schemes := r.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	if !containsFold(schemes, u.Scheme) {
+		return fmt.Errorf("URL scheme %q is not allowed", u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("URL must not contain credentials")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("URL has no host")
+	}
+	for _, domain := range r.Domains {
+		if matchDomain(strings.ToLower(domain), host) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("URL host %q is not in the allowlist", host)
+}
+
+// matchDomain matches host against an allowlist entry
+func matchDomain(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package profiling
+
+import (
+	"context"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// AdminMethod is the router method served by AdminHandler
+const AdminMethod = "admin/profile"
+
+// AdminParams are the parameters of the admin/profile method; without a
+// Tool it only lists profiles
+type AdminParams struct {
+	// Tool is the tool whose next calls are profiled
+	Tool string `json:"tool,omitempty"`
+
+	// Calls is how many calls of Tool are profiled (defaults to 1)
+	Calls int `json:"calls,omitempty"`
+
+	// Disarm stops profiling calls of Tool
+	Disarm bool `json:"disarm,omitempty"`
+}
+
+// AdminResult is the result of the admin/profile method
+type AdminResult struct {
+	// Armed is the number of calls still to be profiled by tool
+	Armed map[string]int `json:"armed"`
+
+	// Profiles are the kept profiles, oldest first; read them at their URI
+	Profiles []Info `json:"profiles"`
+}
+
+// AdminHandler returns a router handler arming the profiling of a tool's
+// next calls and listing profiles. Register it under AdminMethod.
+func (p *Profiler) AdminHandler() router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params AdminParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+		if params.Calls < 0 {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("calls must not be negative"), req.ID)
+		}
+
+		switch {
+		case params.Tool != "" && params.Disarm:
+			p.Arm(params.Tool, 0)
+		case params.Tool != "":
+			p.Arm(params.Tool, max(params.Calls, 1))
+		}
+		return jsonrpc.NewResponse(AdminResult{Armed: p.Armed(), Profiles: p.List()}, req.ID)
+	})
+}
@@ -0,0 +1,304 @@
+// Package profiling captures CPU profiles and allocation counts of single
+// tool calls, to find out why one tool is slow or memory hungry without
+// profiling the whole server for a long time.
+//
+// A call is profiled when a privileged client asks for it with
+// _meta.profile set to true, or when an operator armed profiling of the
+// tool's next calls with the admin/profile method. The profile is kept in
+// memory and served as a resource at its URI, which the result names in
+// _meta.profile:
+//
+//	"_meta": {"profile": {
+//		"id": "3f0c...",
+//		"uri": "debug://profiles/3f0c...",
+//		"tool": "search",
+//		"durationMs": 812,
+//		"allocBytes": 73400320,
+//		"allocObjects": 912233
+//	}}
+//
+// The Go runtime profiles the whole process, so calls running at the same
+// time show up in a profile too; samples taken while the profiled handler
+// runs carry the pprof labels "tool" and "profile" to tell them apart.
+// Allocation counts are process-wide deltas as well. Only one call is
+// profiled at a time; others asking meanwhile run unprofiled.
+//
+// Basic usage:
+//
+//	p := profiling.New(profiling.Config{Authorize: admin.AllowIdentities("local", "ops")})
+//	registry := tools.New(tools.Config{Middleware: []server.ToolHandlerMiddleware{p.Middleware}})
+//	p.Register(hs)
+//	api.Handle(profiling.AdminMethod, p.AdminHandler())
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+const (
+	// MetaProfile is the _meta key of tools/call asking for a profile,
+	// and of the result describing it
+	MetaProfile = "profile"
+
+	// URIPrefix starts the resource URIs of profiles
+	URIPrefix = "debug://profiles/"
+
+	// MIMEType is the type of profile resources: gzipped pprof protobuf
+	MIMEType = "application/vnd.google.protobuf+gzip"
+
+	// DefaultMaxProfiles is the default number of profiles kept
+	DefaultMaxProfiles = 16
+)
+
+// ErrUnknownProfile is returned for a profile that was evicted or never
+// existed
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Config contains configuration for a Profiler
+type Config struct {
+	// Authorize decides whether the caller of ctx may ask for profiles and
+	// read them; the method is the tool called or the profile URI read.
+	// Without it, _meta.profile is ignored and profiles can only be armed
+	// with admin/profile.
+	Authorize router.AuthFunc
+
+	// MaxProfiles caps the profiles kept; the oldest are dropped first
+	// (defaults to DefaultMaxProfiles)
+	MaxProfiles int
+
+	// Clock times the calls (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// Info describes a captured profile
+type Info struct {
+	ID           string    `json:"id"`
+	URI          string    `json:"uri"`
+	Tool         string    `json:"tool"`
+	Started      time.Time `json:"started"`
+	DurationMs   int64     `json:"durationMs"`
+	AllocBytes   uint64    `json:"allocBytes"`
+	AllocObjects uint64    `json:"allocObjects"`
+
+	// CPU is false when the CPU profiler was in use elsewhere, e.g. by a
+	// debug endpoint; the allocation counts are still captured
+	CPU bool `json:"cpu"`
+}
+
+// profile is a captured profile and its artifact
+type profile struct {
+	info Info
+	data []byte
+}
+
+// Profiler profiles the tool calls that ask for it
+type Profiler struct {
+	config Config
+
+	// busy is held while a call is being profiled
+	busy sync.Mutex
+
+	mu       sync.Mutex
+	armed    map[string]int
+	profiles map[string]*profile
+	order    []string
+}
+
+// New creates a profiler without profiles
+func New(config Config) *Profiler {
+	if config.MaxProfiles <= 0 {
+		config.MaxProfiles = DefaultMaxProfiles
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &Profiler{config: config, armed: make(map[string]int), profiles: make(map[string]*profile)}
+}
+
+// Arm profiles the next calls of tool, whoever makes them
+func (p *Profiler) Arm(tool string, calls int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if calls <= 0 {
+		delete(p.armed, tool)
+		return
+	}
+	p.armed[tool] = calls
+}
+
+// Armed returns the number of calls still to be profiled by tool
+func (p *Profiler) Armed() map[string]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	armed := make(map[string]int, len(p.armed))
+	for tool, calls := range p.armed {
+		armed[tool] = calls
+	}
+	return armed
+}
+
+// List returns the kept profiles, oldest first
+func (p *Profiler) List() []Info {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	infos := make([]Info, len(p.order))
+	for i, id := range p.order {
+		infos[i] = p.profiles[id].info
+	}
+	return infos
+}
+
+// Profile returns the pprof artifact of the profile with id
+func (p *Profiler) Profile(id string) (Info, []byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prof, ok := p.profiles[id]
+	if !ok {
+		return Info{}, nil, ErrUnknownProfile
+	}
+	return prof.info, prof.data, nil
+}
+
+// Middleware profiles the calls that ask for it, adding the description
+// of the profile to the _meta of their result
+func (p *Profiler) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !p.wanted(ctx, request) {
+			return next(ctx, request)
+		}
+		if !p.busy.TryLock() {
+			logging.Default().WithComponent("profiling").WithField("tool", request.Params.Name).
+				Info(ctx, "Another call is being profiled; running unprofiled")
+			return next(ctx, request)
+		}
+		defer p.busy.Unlock()
+
+		result, info, err := p.capture(ctx, request, next)
+		if result != nil {
+			if result.Meta == nil {
+				result.Meta = make(map[string]any)
+			}
+			result.Meta[MetaProfile] = info
+		}
+		return result, err
+	}
+}
+
+// wanted reports whether a call is to be profiled, consuming an armed call
+// of its tool
+func (p *Profiler) wanted(ctx context.Context, request mcp.CallToolRequest) bool {
+	tool := request.Params.Name
+	p.mu.Lock()
+	if calls, ok := p.armed[tool]; ok {
+		if calls <= 1 {
+			delete(p.armed, tool)
+		} else {
+			p.armed[tool] = calls - 1
+		}
+		p.mu.Unlock()
+		return true
+	}
+	p.mu.Unlock()
+
+	if request.Params.Meta == nil || p.config.Authorize == nil {
+		return false
+	}
+	if asked, _ := request.Params.Meta.AdditionalFields[MetaProfile].(bool); !asked {
+		return false
+	}
+	if err := p.config.Authorize(ctx, tool); err != nil {
+		logging.Default().WithComponent("profiling").WithField("tool", tool).
+			Debug(ctx, "Ignoring profile request: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// capture runs next under the CPU profiler, keeping the profile
+func (p *Profiler) capture(ctx context.Context, request mcp.CallToolRequest, next server.ToolHandlerFunc) (*mcp.CallToolResult, Info, error) {
+	info := Info{ID: uuid.NewString(), Tool: request.Params.Name}
+	info.URI = URIPrefix + info.ID
+
+	var cpu bytes.Buffer
+	info.CPU = pprof.StartCPUProfile(&cpu) == nil
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	info.Started = p.config.Clock.Now()
+
+	var result *mcp.CallToolResult
+	var err error
+	pprof.Do(ctx, pprof.Labels("tool", info.Tool, "profile", info.ID), func(ctx context.Context) {
+		result, err = next(ctx, request)
+	})
+
+	info.DurationMs = p.config.Clock.Since(info.Started).Milliseconds()
+	runtime.ReadMemStats(&after)
+	if info.CPU {
+		pprof.StopCPUProfile()
+	}
+	info.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	info.AllocObjects = after.Mallocs - before.Mallocs
+
+	p.keep(&profile{info: info, data: cpu.Bytes()})
+	return result, info, err
+}
+
+// keep stores a profile, evicting the oldest beyond MaxProfiles
+func (p *Profiler) keep(prof *profile) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.profiles[prof.info.ID] = prof
+	p.order = append(p.order, prof.info.ID)
+	for len(p.order) > p.config.MaxProfiles {
+		delete(p.profiles, p.order[0])
+		p.order = p.order[1:]
+	}
+}
+
+// Server is the part of the mcp-go server profiles are published to
+type Server interface {
+	AddResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc)
+}
+
+// Register serves profiles as resources under URIPrefix
+func (p *Profiler) Register(s Server) {
+	template := mcp.NewResourceTemplate(URIPrefix+"{id}", "Tool call profiles",
+		mcp.WithTemplateDescription("CPU profiles of single tool calls, in pprof format"),
+		mcp.WithTemplateMIMEType(MIMEType))
+	s.AddResourceTemplate(template, p.handleRead)
+}
+
+// handleRead serves resources/read for profiles
+func (p *Profiler) handleRead(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	if p.config.Authorize == nil {
+		return nil, mcperrors.NewUnauthorizedError(uri)
+	}
+	if err := p.config.Authorize(ctx, uri); err != nil {
+		return nil, mcperrors.NewUnauthorizedError(uri).WithDebugInfo("reason", err.Error())
+	}
+	id, ok := strings.CutPrefix(uri, URIPrefix)
+	if !ok {
+		return nil, mcperrors.NewResourceNotFoundError(uri)
+	}
+	_, data, err := p.Profile(id)
+	if err != nil {
+		return nil, mcperrors.NewResourceNotFoundError(uri)
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{URI: uri, MIMEType: MIMEType, Blob: base64.StdEncoding.EncodeToString(data)}}, nil
+}
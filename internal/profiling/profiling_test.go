@@ -0,0 +1,139 @@
+package profiling
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// privileged marks the context of a client allowed to profile
+type privileged struct{}
+
+func allowPrivileged(ctx context.Context, method string) error {
+	if ctx.Value(privileged{}) == nil {
+		return errors.New("not privileged")
+	}
+	return nil
+}
+
+// allocate is a tool handler allocating memory
+func allocate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var kept [][]byte
+	for i := 0; i < 100; i++ {
+		kept = append(kept, make([]byte, 1<<10))
+	}
+	return mcp.NewToolResultText(strconv.Itoa(len(kept))), nil
+}
+
+func callRequest(tool string, profile bool) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = tool
+	if profile {
+		request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{MetaProfile: true}}
+	}
+	return request
+}
+
+func TestProfiler_MetaProfile(t *testing.T) {
+	p := New(Config{Authorize: allowPrivileged})
+	handler := p.Middleware(allocate)
+	ctx := context.WithValue(context.Background(), privileged{}, true)
+
+	result, err := handler(ctx, callRequest("alloc", true))
+	require.NoError(t, err)
+	info, ok := result.Meta[MetaProfile].(Info)
+	require.True(t, ok)
+	assert.Equal(t, "alloc", info.Tool)
+	assert.Equal(t, URIPrefix+info.ID, info.URI)
+	assert.GreaterOrEqual(t, info.AllocBytes, uint64(100<<10))
+	assert.GreaterOrEqual(t, info.AllocObjects, uint64(100))
+	assert.Equal(t, []Info{info}, p.List())
+
+	// Only privileged clients get profiles
+	result, err = handler(context.Background(), callRequest("alloc", true))
+	require.NoError(t, err)
+	assert.NotContains(t, result.Meta, MetaProfile)
+	result, err = handler(ctx, callRequest("alloc", false))
+	require.NoError(t, err)
+	assert.NotContains(t, result.Meta, MetaProfile)
+	assert.Len(t, p.List(), 1)
+}
+
+func TestProfiler_Read(t *testing.T) {
+	p := New(Config{Authorize: allowPrivileged})
+	ctx := context.WithValue(context.Background(), privileged{}, true)
+	result, err := p.Middleware(allocate)(ctx, callRequest("alloc", true))
+	require.NoError(t, err)
+	info := result.Meta[MetaProfile].(Info)
+
+	var request mcp.ReadResourceRequest
+	request.Params.URI = info.URI
+	contents, err := p.handleRead(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	blob := contents[0].(mcp.BlobResourceContents)
+	assert.Equal(t, MIMEType, blob.MIMEType)
+	if info.CPU {
+		data, err := base64.StdEncoding.DecodeString(blob.Blob)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x1f, 0x8b}, data[:2], "profiles are gzipped")
+	}
+
+	_, err = p.handleRead(context.Background(), request)
+	assert.Equal(t, mcperrors.ErrorCodeMCPUnauthorized, mcperrors.FindMCPError(err).Code)
+
+	request.Params.URI = URIPrefix + "missing"
+	_, err = p.handleRead(ctx, request)
+	assert.Equal(t, mcperrors.ErrorCodeMCPResourceNotFound, mcperrors.FindMCPError(err).Code)
+}
+
+func TestProfiler_Armed(t *testing.T) {
+	p := New(Config{MaxProfiles: 2})
+	handler := p.Middleware(allocate)
+	ctx := context.Background()
+
+	response := p.AdminHandler().Handle(ctx, &jsonrpc.Request{Version: jsonrpc.Version, Method: AdminMethod,
+		Params: map[string]any{"tool": "alloc", "calls": 3}, ID: 1})
+	require.Nil(t, response.Error)
+	assert.Equal(t, map[string]int{"alloc": 3}, response.Result.(AdminResult).Armed)
+
+	// Armed calls are profiled for any caller, others are not
+	for i := 0; i < 4; i++ {
+		_, err := handler(ctx, callRequest("alloc", false))
+		require.NoError(t, err)
+	}
+	result, err := handler(ctx, callRequest("other", false))
+	require.NoError(t, err)
+	assert.NotContains(t, result.Meta, MetaProfile)
+
+	// The oldest profiles are dropped
+	assert.Len(t, p.List(), 2)
+	assert.Empty(t, p.Armed())
+
+	p.Arm("alloc", 1)
+	response = p.AdminHandler().Handle(ctx, &jsonrpc.Request{Version: jsonrpc.Version, Method: AdminMethod,
+		Params: map[string]any{"tool": "alloc", "disarm": true}, ID: 2})
+	require.Nil(t, response.Error)
+	assert.Empty(t, response.Result.(AdminResult).Armed)
+}
+
+func TestProfiler_Register(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0", server.WithResourceCapabilities(false, false))
+	New(Config{}).Register(s)
+	message := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/templates/list"}`))
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	templates := response.Result.(mcp.ListResourceTemplatesResult).ResourceTemplates
+	require.Len(t, templates, 1)
+	assert.Equal(t, URIPrefix+"{id}", templates[0].URITemplate.Raw())
+}
@@ -0,0 +1,508 @@
+// Package prompts serves MCP prompts defined in configuration or code.
+// Prompt messages are Go templates rendered with the validated arguments;
+// messages may embed resources whose URI is itself a template. Arguments
+// are typed, and completion providers suggest values for them.
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/schema"
+)
+
+// Argument types
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+// MaxCompletions is the most completion values returned, per the MCP spec
+const MaxCompletions = 100
+
+// ErrPromptNotFound is returned for unknown prompt names
+var ErrPromptNotFound = errors.New("prompt not found")
+
+// Server is the part of the mcp-go server prompts are published to
+type Server interface {
+	AddPrompt(prompt mcp.Prompt, handler server.PromptHandlerFunc)
+}
+
+// CompletionFunc suggests values for an argument given its current prefix
+type CompletionFunc func(ctx context.Context, value string) ([]string, error)
+
+// ResourceReader reads a resource embedded into a prompt message
+type ResourceReader func(ctx context.Context, uri string) ([]mcp.ResourceContents, error)
+
+// Argument describes a prompt argument
+type Argument struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+
+	// Type is string (default), number, integer or boolean. Values are
+	// converted before rendering.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// Enum restricts the allowed values and provides default completions
+	Enum []string `yaml:"enum,omitempty" json:"enum,omitempty"`
+
+	// Pattern is a regular expression string values must match
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+
+	// Default is used when the argument is not supplied
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+
+	// Complete suggests values; it overrides Enum completion
+	Complete CompletionFunc `yaml:"-" json:"-"`
+
+	pattern *regexp.Regexp
+}
+
+// Message is a prompt message template. Exactly one of Text and Resource is
+// set.
+type Message struct {
+	// Role is "user" (default) or "assistant"
+	Role string `yaml:"role,omitempty" json:"role,omitempty"`
+
+	// Text is a Go template rendered with the arguments
+	Text string `yaml:"text,omitempty" json:"text,omitempty"`
+
+	// Resource is a Go template producing the URI of a resource to embed
+	Resource string `yaml:"resource,omitempty" json:"resource,omitempty"`
+}
+
+// Definition describes a prompt
+type Definition struct {
+	Name        string     `yaml:"name" json:"name"`
+	Description string     `yaml:"description,omitempty" json:"description,omitempty"`
+	Arguments   []Argument `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+	Messages    []Message  `yaml:"messages" json:"messages"`
+}
+
+// Config contains configuration for an Engine
+type Config struct {
+	// Prompts are added when the engine is created
+	Prompts []Definition `yaml:"prompts" json:"prompts"`
+
+	// Resources reads embedded resources (required for prompts embedding
+	// resources)
+	Resources ResourceReader `yaml:"-" json:"-"`
+
+	// Funcs are made available to templates
+	Funcs template.FuncMap `yaml:"-" json:"-"`
+
+	// Schemas receives a schema of the arguments of every prompt (optional)
+	Schemas *schema.Registry `yaml:"-" json:"-"`
+}
+
+// LoadConfig reads prompt definitions from a YAML file
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ArgumentError reports arguments that fail validation
+type ArgumentError struct {
+	Prompt   string
+	Argument string
+	Reason   string
+}
+
+// Error implements the error interface
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("prompt %s: argument %s %s", e.Prompt, e.Argument, e.Reason)
+}
+
+// compiled is a prompt ready to render
+type compiled struct {
+	def       Definition
+	arguments map[string]*Argument
+	messages  []compiledMessage
+}
+
+// compiledMessage is a parsed message template
+type compiledMessage struct {
+	role     mcp.Role
+	text     *template.Template
+	resource *template.Template
+}
+
+// Engine renders prompts
+type Engine struct {
+	resources ResourceReader
+	funcs     template.FuncMap
+	schemas   *schema.Registry
+
+	mu      sync.RWMutex
+	prompts map[string]*compiled
+}
+
+// NewEngine creates an engine with the configured prompts
+func NewEngine(config Config) (*Engine, error) {
+	e := &Engine{
+		resources: config.Resources,
+		funcs:     config.Funcs,
+		schemas:   config.Schemas,
+		prompts:   make(map[string]*compiled),
+	}
+	for _, def := range config.Prompts {
+		if err := e.Add(def); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Add compiles and adds a prompt, replacing any prompt of the same name
+func (e *Engine) Add(def Definition) error {
+	c, err := e.compile(def)
+	if err != nil {
+		return fmt.Errorf("prompt %s: %w", def.Name, err)
+	}
+
+	e.mu.Lock()
+	e.prompts[def.Name] = c
+	e.mu.Unlock()
+	if e.schemas != nil {
+		e.schemas.Put(schema.KindPrompt, def.Name, "local", c.schema())
+	}
+	return nil
+}
+
+// SetCompletion sets the completion provider of a prompt argument
+func (e *Engine) SetCompletion(prompt, argument string, fn CompletionFunc) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.prompts[prompt]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPromptNotFound, prompt)
+	}
+	arg, ok := c.arguments[argument]
+	if !ok {
+		return fmt.Errorf("prompt %s has no argument %s", prompt, argument)
+	}
+	arg.Complete = fn
+	return nil
+}
+
+// List returns the prompts sorted by name
+func (e *Engine) List() []mcp.Prompt {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	prompts := make([]mcp.Prompt, 0, len(e.prompts))
+	for _, c := range e.prompts {
+		prompts = append(prompts, c.prompt())
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts
+}
+
+// Get validates the arguments and renders a prompt. Invalid arguments are
+// reported as *ArgumentError.
+func (e *Engine) Get(ctx context.Context, name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	e.mu.RLock()
+	c, ok := e.prompts[name]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+	}
+
+	values, err := c.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.GetPromptResult{Description: c.def.Description}
+	for _, msg := range c.messages {
+		content, err := e.render(ctx, msg, values)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", name, err)
+		}
+		result.Messages = append(result.Messages, mcp.NewPromptMessage(msg.role, content))
+	}
+	return result, nil
+}
+
+// Complete suggests values for a prompt argument. Arguments without a
+// provider complete from their enum.
+func (e *Engine) Complete(ctx context.Context, prompt, argument, value string) ([]string, error) {
+	e.mu.RLock()
+	c, ok := e.prompts[prompt]
+	var arg *Argument
+	if ok {
+		arg = c.arguments[argument]
+	}
+	e.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, prompt)
+	}
+	if arg == nil {
+		return nil, &ArgumentError{Prompt: prompt, Argument: argument, Reason: "is not defined"}
+	}
+
+	var values []string
+	if arg.Complete != nil {
+		var err error
+		if values, err = arg.Complete(ctx, value); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, v := range arg.Enum {
+			if strings.HasPrefix(v, value) {
+				values = append(values, v)
+			}
+		}
+	}
+	if len(values) > MaxCompletions {
+		values = values[:MaxCompletions]
+	}
+	return values, nil
+}
+
+// Register publishes the prompts on an mcp-go server. mcp-go reports
+// handler errors, including argument errors, as internal errors; use
+// RegisterRoutes where InvalidParams matters.
+func (e *Engine) Register(s Server) {
+	for _, prompt := range e.List() {
+		name := prompt.Name
+		s.AddPrompt(prompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return e.Get(ctx, name, request.Params.Arguments)
+		})
+	}
+}
+
+// compile parses a definition's templates and patterns
+func (e *Engine) compile(def Definition) (*compiled, error) {
+	if def.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(def.Messages) == 0 {
+		return nil, errors.New("at least one message is required")
+	}
+
+	c := &compiled{def: def, arguments: make(map[string]*Argument)}
+	c.def.Arguments = append([]Argument(nil), def.Arguments...)
+	for i := range def.Arguments {
+		arg := def.Arguments[i]
+		switch arg.Type {
+		case "":
+			arg.Type = TypeString
+		case TypeString, TypeNumber, TypeInteger, TypeBoolean:
+		default:
+			return nil, fmt.Errorf("argument %s: unknown type %q", arg.Name, arg.Type)
+		}
+		if arg.Pattern != "" {
+			pattern, err := regexp.Compile(arg.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s: %w", arg.Name, err)
+			}
+			arg.pattern = pattern
+		}
+		c.def.Arguments[i] = arg
+		c.arguments[arg.Name] = &c.def.Arguments[i]
+	}
+
+	for i, msg := range def.Messages {
+		role := mcp.Role(msg.Role)
+		if role == "" {
+			role = mcp.RoleUser
+		}
+		if role != mcp.RoleUser && role != mcp.RoleAssistant {
+			return nil, fmt.Errorf("message %d: unknown role %q", i, msg.Role)
+		}
+		if (msg.Text == "") == (msg.Resource == "") {
+			return nil, fmt.Errorf("message %d: exactly one of text and resource is required", i)
+		}
+		if msg.Resource != "" && e.resources == nil {
+			return nil, fmt.Errorf("message %d: embeds a resource but no resource reader is configured", i)
+		}
+
+		source := msg.Text + msg.Resource
+		tmpl, err := template.New(fmt.Sprintf("%s/%d", def.Name, i)).
+			Funcs(e.funcs).Option("missingkey=error").Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+
+		cm := compiledMessage{role: role}
+		if msg.Text != "" {
+			cm.text = tmpl
+		} else {
+			cm.resource = tmpl
+		}
+		c.messages = append(c.messages, cm)
+	}
+	return c, nil
+}
+
+// render produces the content of one message
+func (e *Engine) render(ctx context.Context, msg compiledMessage, values map[string]interface{}) (mcp.Content, error) {
+	tmpl := msg.text
+	if tmpl == nil {
+		tmpl = msg.resource
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, err
+	}
+	if msg.text != nil {
+		return mcp.NewTextContent(buf.String()), nil
+	}
+
+	uri := strings.TrimSpace(buf.String())
+	contents, err := e.resources(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("embed %s: %w", uri, err)
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("embed %s: resource is empty", uri)
+	}
+	return mcp.NewEmbeddedResource(contents[0]), nil
+}
+
+// prompt returns the MCP description of the prompt
+func (c *compiled) prompt() mcp.Prompt {
+	prompt := mcp.Prompt{Name: c.def.Name, Description: c.def.Description}
+	for _, arg := range c.def.Arguments {
+		prompt.Arguments = append(prompt.Arguments, mcp.PromptArgument{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Required:    arg.Required,
+		})
+	}
+	return prompt
+}
+
+// schema returns a JSON Schema of the arguments of the prompt, once
+// converted to their type
+func (c *compiled) schema() json.RawMessage {
+	properties := make(map[string]any, len(c.def.Arguments))
+	required := []string{}
+	for _, arg := range c.def.Arguments {
+		property := map[string]any{"type": TypeString}
+		if arg.Type != "" {
+			property["type"] = arg.Type
+		}
+		if arg.Description != "" {
+			property["description"] = arg.Description
+		}
+		if len(arg.Enum) > 0 && property["type"] == TypeString {
+			property["enum"] = arg.Enum
+		}
+		if arg.Pattern != "" {
+			property["pattern"] = arg.Pattern
+		}
+		properties[arg.Name] = property
+		if arg.Required {
+			required = append(required, arg.Name)
+		}
+	}
+	data, _ := json.Marshal(map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	})
+	return data
+}
+
+// bind validates and converts the supplied arguments
+func (c *compiled) bind(args map[string]string) (map[string]interface{}, error) {
+	for name := range args {
+		if _, ok := c.arguments[name]; !ok {
+			return nil, &ArgumentError{Prompt: c.def.Name, Argument: name, Reason: "is not defined"}
+		}
+	}
+
+	values := make(map[string]interface{}, len(c.def.Arguments))
+	for _, arg := range c.def.Arguments {
+		raw, ok := args[arg.Name]
+		if !ok {
+			if arg.Required {
+				return nil, &ArgumentError{Prompt: c.def.Name, Argument: arg.Name, Reason: "is required"}
+			}
+			raw = arg.Default
+		}
+
+		value, err := arg.convert(raw)
+		if err != nil {
+			return nil, &ArgumentError{Prompt: c.def.Name, Argument: arg.Name, Reason: err.Error()}
+		}
+		values[arg.Name] = value
+	}
+	return values, nil
+}
+
+// convert checks a raw value and converts it to the argument's type.
+// Empty optional values stay empty strings.
+func (a *Argument) convert(raw string) (interface{}, error) {
+	if raw == "" && !a.Required {
+		return "", nil
+	}
+	if len(a.Enum) > 0 && !contains(a.Enum, raw) {
+		return nil, fmt.Errorf("must be one of %s", strings.Join(a.Enum, ", "))
+	}
+	if a.pattern != nil && !a.pattern.MatchString(raw) {
+		return nil, fmt.Errorf("must match %s", a.Pattern)
+	}
+
+	switch a.Type {
+	case TypeNumber:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return v, nil
+	case TypeInteger:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return v, nil
+	case TypeBoolean:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be a boolean")
+		}
+		return v, nil
+	}
+	return raw, nil
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
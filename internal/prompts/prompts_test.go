@@ -0,0 +1,210 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reviewPrompt() Definition {
+	return Definition{
+		Name:        "code_review",
+		Description: "Review a file",
+		Arguments: []Argument{
+			{Name: "file", Required: true, Pattern: `^[\w./-]+$`},
+			{Name: "focus", Enum: []string{"security", "style", "performance"}, Default: "style"},
+			{Name: "max_issues", Type: TypeInteger, Default: "5"},
+		},
+		Messages: []Message{
+			{Text: "Review {{.file}} focusing on {{.focus}}. Report at most {{.max_issues}} issues."},
+			{Resource: "file://project/{{.file}}"},
+			{Role: "assistant", Text: "{{if eq .focus \"security\"}}Checking for vulnerabilities.{{else}}Looking at the code.{{end}}"},
+		},
+	}
+}
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	e, err := NewEngine(Config{
+		Prompts: []Definition{reviewPrompt()},
+		Resources: func(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+			if !strings.HasPrefix(uri, "file://project/") {
+				return nil, errors.New("not found")
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, Text: "package main"}}, nil
+		},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func TestEngine_Get(t *testing.T) {
+	e := newTestEngine(t)
+
+	result, err := e.Get(context.Background(), "code_review", map[string]string{"file": "main.go", "focus": "security"})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 3)
+	assert.Equal(t, "Review main.go focusing on security. Report at most 5 issues.", result.Messages[0].Content.(mcp.TextContent).Text)
+	embedded := result.Messages[1].Content.(mcp.EmbeddedResource)
+	assert.Equal(t, "file://project/main.go", embedded.Resource.(mcp.TextResourceContents).URI)
+	assert.Equal(t, mcp.RoleAssistant, result.Messages[2].Role)
+	assert.Equal(t, "Checking for vulnerabilities.", result.Messages[2].Content.(mcp.TextContent).Text)
+}
+
+func TestEngine_ArgumentValidation(t *testing.T) {
+	e := newTestEngine(t)
+
+	tests := []struct {
+		name     string
+		args     map[string]string
+		argument string
+	}{
+		{"missing required", map[string]string{}, "file"},
+		{"pattern", map[string]string{"file": "a b"}, "file"},
+		{"enum", map[string]string{"file": "a.go", "focus": "vibes"}, "focus"},
+		{"type", map[string]string{"file": "a.go", "max_issues": "many"}, "max_issues"},
+		{"unknown", map[string]string{"file": "a.go", "extra": "1"}, "extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.Get(context.Background(), "code_review", tt.args)
+			var argErr *ArgumentError
+			require.True(t, errors.As(err, &argErr), "got %v", err)
+			assert.Equal(t, tt.argument, argErr.Argument)
+		})
+	}
+
+	_, err := e.Get(context.Background(), "missing", nil)
+	assert.ErrorIs(t, err, ErrPromptNotFound)
+}
+
+func TestEngine_Schemas(t *testing.T) {
+	schemas := schema.New()
+	def := reviewPrompt()
+	def.Messages = def.Messages[:1]
+	_, err := NewEngine(Config{Prompts: []Definition{def}, Schemas: schemas})
+	require.NoError(t, err)
+
+	entry, ok := schemas.Get(schema.KindPrompt, "code_review")
+	require.True(t, ok)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"file": {"type": "string", "pattern": "^[\\w./-]+$"},
+			"focus": {"type": "string", "enum": ["security", "style", "performance"]},
+			"max_issues": {"type": "integer"}
+		},
+		"required": ["file"],
+		"additionalProperties": false
+	}`, string(entry.Schema))
+
+	assert.NoError(t, schemas.Validate(schema.KindPrompt, "code_review", map[string]any{"file": "a.go", "max_issues": 3}))
+	assert.Error(t, schemas.Validate(schema.KindPrompt, "code_review", map[string]any{"file": "a.go", "focus": "vibes"}))
+}
+
+func TestEngine_Complete(t *testing.T) {
+	e := newTestEngine(t)
+	ctx := context.Background()
+
+	values, err := e.Complete(ctx, "code_review", "focus", "s")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"security", "style"}, values)
+
+	require.NoError(t, e.SetCompletion("code_review", "file", func(ctx context.Context, value string) ([]string, error) {
+		return []string{value + "main.go", value + "util.go"}, nil
+	}))
+	values, err = e.Complete(ctx, "code_review", "file", "cmd/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cmd/main.go", "cmd/util.go"}, values)
+
+	assert.Error(t, e.SetCompletion("code_review", "nope", nil))
+}
+
+func TestNewEngine_Errors(t *testing.T) {
+	tests := []Definition{
+		{Name: "", Messages: []Message{{Text: "x"}}},
+		{Name: "empty"},
+		{Name: "bad_template", Messages: []Message{{Text: "{{.x"}}},
+		{Name: "bad_role", Messages: []Message{{Role: "system", Text: "x"}}},
+		{Name: "both", Messages: []Message{{Text: "x", Resource: "y"}}},
+		{Name: "no_reader", Messages: []Message{{Resource: "file://x"}}},
+		{Name: "bad_type", Arguments: []Argument{{Name: "a", Type: "date"}}, Messages: []Message{{Text: "x"}}},
+	}
+
+	for _, def := range tests {
+		_, err := NewEngine(Config{Prompts: []Definition{def}})
+		assert.Error(t, err, def.Name)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+prompts:
+  - name: greet
+    arguments:
+      - name: who
+        required: true
+    messages:
+      - text: "Hello {{.who}}"
+`), 0o644))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	e, err := NewEngine(config)
+	require.NoError(t, err)
+
+	result, err := e.Get(context.Background(), "greet", map[string]string{"who": "world"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", result.Messages[0].Content.(mcp.TextContent).Text)
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	r := router.New()
+	RegisterRoutes(r, newTestEngine(t))
+	ctx := context.Background()
+
+	resp := r.Handle(ctx, &jsonrpc.Request{ID: 1, Method: "prompts/list"})
+	require.Nil(t, resp.Error)
+
+	resp = r.Handle(ctx, &jsonrpc.Request{ID: 2, Method: "prompts/get", Params: map[string]interface{}{
+		"name": "code_review", "arguments": map[string]interface{}{"file": "a b"},
+	}})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, resp.Error.Code)
+
+	resp = r.Handle(ctx, &jsonrpc.Request{ID: 3, Method: "prompts/get", Params: map[string]interface{}{"name": "missing"}})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, resp.Error.Code)
+
+	resp = r.Handle(ctx, &jsonrpc.Request{ID: 4, Method: MethodComplete, Params: map[string]interface{}{
+		"ref":      map[string]interface{}{"type": "ref/prompt", "name": "code_review"},
+		"argument": map[string]interface{}{"name": "focus", "value": "p"},
+	}})
+	require.Nil(t, resp.Error)
+	body := resp.Result.(map[string]interface{})["completion"].(completion)
+	assert.Equal(t, []string{"performance"}, body.Values)
+}
+
+func TestRegister(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0", server.WithPromptCapabilities(true))
+	newTestEngine(t).Register(s)
+
+	msg := s.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"code_review","arguments":{"file":"x.go"}}}`))
+	resp, ok := msg.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected message %#v", msg)
+	assert.Len(t, resp.Result.(mcp.GetPromptResult).Messages, 3)
+}
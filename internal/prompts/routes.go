@@ -0,0 +1,98 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// MethodComplete is the MCP method requesting argument completions
+const MethodComplete = "completion/complete"
+
+// getParams are the parameters of prompts/get
+type getParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// completeParams are the parameters of completion/complete
+type completeParams struct {
+	Ref struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"ref"`
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+}
+
+// completion is the result body of completion/complete
+type completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total"`
+	HasMore bool     `json:"hasMore"`
+}
+
+// RegisterRoutes serves prompts/list, prompts/get and completion/complete
+// on the router. Unknown prompts and invalid arguments are InvalidParams
+// errors.
+func RegisterRoutes(r *router.Router, e *Engine) {
+	r.RegisterFunc(mcp.MethodListPrompts, func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(map[string]interface{}{"prompts": e.List()}, req.ID)
+	})
+
+	r.RegisterFunc(mcp.MethodGetPrompt, func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params getParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+
+		result, err := e.Get(ctx, params.Name, params.Arguments)
+		if err != nil {
+			return errorResponse(err, params.Name, req.ID)
+		}
+		return jsonrpc.NewResponse(result, req.ID)
+	})
+
+	r.RegisterFunc(MethodComplete, func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params completeParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+		if params.Ref.Type != "ref/prompt" {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unsupported reference type: "+params.Ref.Type), req.ID)
+		}
+
+		values, err := e.Complete(ctx, params.Ref.Name, params.Argument.Name, params.Argument.Value)
+		if err != nil {
+			return errorResponse(err, params.Ref.Name, req.ID)
+		}
+		if values == nil {
+			values = []string{}
+		}
+		return jsonrpc.NewResponse(map[string]interface{}{
+			"completion": completion{Values: values, Total: len(values)},
+		}, req.ID)
+	})
+}
+
+// errorResponse maps engine errors onto JSON-RPC errors
+func errorResponse(err error, prompt string, id interface{}) *jsonrpc.Response {
+	var argErr *ArgumentError
+	switch {
+	case errors.As(err, &argErr):
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(map[string]interface{}{
+			"prompt":   argErr.Prompt,
+			"argument": argErr.Argument,
+			"reason":   argErr.Reason,
+		}), id)
+	case errors.Is(err, ErrPromptNotFound):
+		return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), id)
+	}
+	return jsonrpc.NewErrorResponse(mcperrors.NewPromptError(prompt, err).ToJSONRPCError(), id)
+}
@@ -0,0 +1,168 @@
+// Package compat adapts the wire format of the server to legacy clients.
+//
+// Newer protocol versions add fields, content types and capabilities that
+// clients speaking an older version reject or misread. Rather than
+// scattering version checks through the handlers, every adaptation is a
+// Shim in a table, selected once per connection from the negotiated
+// protocol version and the client's name:
+//
+//	adapter := compat.Builtin.Select("2024-11-05", "legacy-client")
+//	message = adapter.Outbound(message)
+//
+// An adapter without matching shims passes messages through untouched, so
+// current clients pay nothing.
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+)
+
+// Protocol versions shims are keyed by
+const (
+	Version20241105 = "2024-11-05"
+	Version20250326 = "2025-03-26"
+	Version20250618 = "2025-06-18"
+)
+
+// Message is a decoded JSON-RPC message. Numbers decode as json.Number so
+// messages survive adaptation unchanged.
+type Message map[string]any
+
+// Method returns the method of a request or notification, or "" for a
+// response
+func (m Message) Method() string {
+	method, _ := m["method"].(string)
+	return method
+}
+
+// Params returns the params object, or nil
+func (m Message) Params() map[string]any {
+	params, _ := m["params"].(map[string]any)
+	return params
+}
+
+// Result returns the result object of a response, or nil
+func (m Message) Result() map[string]any {
+	result, _ := m["result"].(map[string]any)
+	return result
+}
+
+// Shim adapts messages for the clients it applies to
+type Shim struct {
+	// Name identifies the shim in logs
+	Name string
+
+	// Before applies the shim to clients that negotiated a protocol
+	// version older than it; empty applies it to every version
+	Before string
+
+	// Clients limits the shim to clients whose clientInfo.name is listed;
+	// empty matches every client
+	Clients []string
+
+	// Inbound rewrites a message from the client, reporting whether it
+	// changed it
+	Inbound func(m Message) bool
+
+	// Outbound rewrites a message to the client, reporting whether it
+	// changed it
+	Outbound func(m Message) bool
+}
+
+// applies reports whether the shim applies to a client named client that
+// negotiated version
+func (s Shim) applies(version, client string) bool {
+	// Versions are dates, so they compare as strings
+	if s.Before != "" && (version == "" || version >= s.Before) {
+		return false
+	}
+	return len(s.Clients) == 0 || slices.Contains(s.Clients, client)
+}
+
+// Table is a list of shims, applied in order
+type Table []Shim
+
+// Select returns an adapter applying the shims for a client named client
+// that negotiated version. It returns nil when none apply.
+func (t Table) Select(version, client string) *Adapter {
+	var a Adapter
+	for _, shim := range t {
+		if !shim.applies(version, client) {
+			continue
+		}
+		a.names = append(a.names, shim.Name)
+		if shim.Inbound != nil {
+			a.inbound = append(a.inbound, shim.Inbound)
+		}
+		if shim.Outbound != nil {
+			a.outbound = append(a.outbound, shim.Outbound)
+		}
+	}
+	if len(a.names) == 0 {
+		return nil
+	}
+	return &a
+}
+
+// Adapter applies the shims selected for one client. A nil adapter passes
+// messages through.
+type Adapter struct {
+	names    []string
+	inbound  []func(Message) bool
+	outbound []func(Message) bool
+}
+
+// Names returns the names of the selected shims
+func (a *Adapter) Names() []string {
+	if a == nil {
+		return nil
+	}
+	return a.names
+}
+
+// Inbound adapts an encoded message from the client
+func (a *Adapter) Inbound(message []byte) []byte {
+	if a == nil {
+		return message
+	}
+	return apply(message, a.inbound)
+}
+
+// Outbound adapts an encoded message to the client
+func (a *Adapter) Outbound(message []byte) []byte {
+	if a == nil {
+		return message
+	}
+	return apply(message, a.outbound)
+}
+
+// apply runs shims on message, re-encoding it only if one changed it.
+// Messages that are not JSON objects pass through.
+func apply(message []byte, shims []func(Message) bool) []byte {
+	if len(shims) == 0 {
+		return message
+	}
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	var m Message
+	if err := decoder.Decode(&m); err != nil || m == nil {
+		return message
+	}
+
+	changed := false
+	for _, shim := range shims {
+		if shim(m) {
+			changed = true
+		}
+	}
+	if !changed {
+		return message
+	}
+	adapted, err := json.Marshal(m)
+	if err != nil {
+		return message
+	}
+	return adapted
+}
@@ -0,0 +1,116 @@
+package compat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_Select(t *testing.T) {
+	table := Table{
+		{Name: "old", Before: Version20250326, Outbound: func(Message) bool { return false }},
+		{Name: "client", Clients: []string{"legacy-ide"}, Inbound: func(Message) bool { return false }},
+	}
+
+	assert.Nil(t, table.Select(Version20250326, "other"))
+	assert.Nil(t, table.Select("", "other"), "unknown versions get no version shims")
+	assert.Equal(t, []string{"old"}, table.Select(Version20241105, "other").Names())
+	assert.Equal(t, []string{"client"}, table.Select(Version20250326, "legacy-ide").Names())
+	assert.Equal(t, []string{"old", "client"}, table.Select(Version20241105, "legacy-ide").Names())
+}
+
+func TestAdapter_PassesThrough(t *testing.T) {
+	var nilAdapter *Adapter
+	message := []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`)
+	assert.Equal(t, message, nilAdapter.Outbound(message))
+
+	// Messages no shim changes keep their encoding
+	adapter := Builtin.Select(Version20241105, "client")
+	require.NotNil(t, adapter)
+	message = []byte(`{"jsonrpc":"2.0", "id":1, "result":{"value":1.50}}`)
+	assert.Equal(t, message, adapter.Outbound(message))
+	assert.Equal(t, []byte(`not json`), adapter.Outbound([]byte(`not json`)))
+}
+
+func TestAdapter_Inbound(t *testing.T) {
+	table := Table{{
+		Name:    "rename-cursor",
+		Clients: []string{"legacy-ide"},
+		Inbound: func(m Message) bool {
+			params := m.Params()
+			cursor, ok := params["nextCursor"]
+			if !ok {
+				return false
+			}
+			delete(params, "nextCursor")
+			params["cursor"] = cursor
+			return true
+		},
+	}}
+	adapter := table.Select(Version20250326, "legacy-ide")
+	adapted := adapter.Inbound([]byte(`{"jsonrpc":"2.0","id":7,"method":"tools/list","params":{"nextCursor":"abc"}}`))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":7,"method":"tools/list","params":{"cursor":"abc"}}`, string(adapted))
+}
+
+func TestBuiltin(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		message string
+		want    string
+	}{
+		{
+			name:    "tool fields",
+			version: Version20241105,
+			message: `{"id":1,"result":{"tools":[{"name":"a","title":"A","annotations":{"readOnlyHint":true},"outputSchema":{"type":"object"}}]}}`,
+			want:    `{"id":1,"result":{"tools":[{"name":"a"}]}}`,
+		},
+		{
+			name:    "tool fields of 2025-03-26",
+			version: Version20250326,
+			message: `{"id":1,"result":{"tools":[{"name":"a","title":"A","annotations":{"readOnlyHint":true},"outputSchema":{"type":"object"}}]}}`,
+			want:    `{"id":1,"result":{"tools":[{"name":"a","annotations":{"readOnlyHint":true}}]}}`,
+		},
+		{
+			name:    "audio content",
+			version: Version20241105,
+			message: `{"id":1,"result":{"content":[{"type":"audio","data":"AAA=","mimeType":"audio/wav"},{"type":"text","text":"hi"}]}}`,
+			want:    `{"id":1,"result":{"content":[{"type":"text","text":"[audio content omitted: audio/wav]"},{"type":"text","text":"hi"}]}}`,
+		},
+		{
+			name:    "resource links in prompt messages",
+			version: Version20250326,
+			message: `{"id":1,"result":{"messages":[{"role":"user","content":{"type":"resource_link","uri":"file:///a","name":"a"}}]}}`,
+			want:    `{"id":1,"result":{"messages":[{"role":"user","content":{"type":"text","text":"file:///a"}}]}}`,
+		},
+		{
+			name:    "structured content",
+			version: Version20250326,
+			message: `{"id":1,"result":{"content":[],"structuredContent":{"temperature":21.5}}}`,
+			want:    `{"id":1,"result":{"content":[{"type":"text","text":"{\"temperature\":21.5}"}]}}`,
+		},
+		{
+			name:    "progress message",
+			version: Version20241105,
+			message: `{"method":"notifications/progress","params":{"progressToken":1,"progress":2,"message":"copying"}}`,
+			want:    `{"method":"notifications/progress","params":{"progressToken":1,"progress":2}}`,
+		},
+		{
+			name:    "initialize capabilities",
+			version: Version20241105,
+			message: `{"id":0,"result":{"protocolVersion":"2024-11-05","capabilities":{"completions":{},"tools":{},"prompts":{"listChanged":true},"logging":{}}}}`,
+			want:    `{"id":0,"result":{"protocolVersion":"2024-11-05","capabilities":{"tools":{"listChanged":false},"prompts":{"listChanged":true},"logging":{}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapted := Builtin.Select(tt.version, "client").Outbound([]byte(tt.message))
+			assert.JSONEq(t, tt.want, string(adapted))
+		})
+	}
+
+	// Current clients get messages untouched
+	assert.Nil(t, Builtin.Select(Version20250618, "client"))
+}
@@ -0,0 +1,165 @@
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Builtin adapts to clients of the protocol versions before the latest
+var Builtin = Table{
+	// 2025-03-26 added tool annotations, audio content, progress messages
+	// and the completions capability
+	{Name: "tool-annotations", Before: Version20250326, Outbound: dropToolFields("annotations")},
+	{Name: "audio-content", Before: Version20250326, Outbound: downgradeContent("audio", func(item map[string]any) string {
+		return fmt.Sprintf("[audio content omitted: %v]", item["mimeType"])
+	})},
+	{Name: "progress-message", Before: Version20250326, Outbound: dropProgressMessage},
+	{Name: "completions-capability", Before: Version20250326, Outbound: dropCompletionsCapability},
+	{Name: "list-changed-flags", Before: Version20250326, Outbound: injectListChangedFlags},
+
+	// 2025-06-18 added structured tool output, titles and resource links
+	{Name: "structured-content", Before: Version20250618, Outbound: flattenStructuredContent},
+	{Name: "tool-output-schema", Before: Version20250618, Outbound: dropToolFields("outputSchema", "title")},
+	{Name: "resource-link", Before: Version20250618, Outbound: downgradeContent("resource_link", func(item map[string]any) string {
+		return fmt.Sprintf("%v", item["uri"])
+	})},
+}
+
+// dropToolFields removes fields from the tools of tools/list results
+func dropToolFields(fields ...string) func(Message) bool {
+	return func(m Message) bool {
+		tools, _ := m.Result()["tools"].([]any)
+		changed := false
+		for _, tool := range tools {
+			tool, ok := tool.(map[string]any)
+			if !ok {
+				continue
+			}
+			for _, field := range fields {
+				if _, ok := tool[field]; ok {
+					delete(tool, field)
+					changed = true
+				}
+			}
+		}
+		return changed
+	}
+}
+
+// downgradeContent replaces content items of kind with text content
+// described by text
+func downgradeContent(kind string, text func(item map[string]any) string) func(Message) bool {
+	return func(m Message) bool {
+		return eachContent(m, func(item map[string]any) bool {
+			if item["type"] != kind {
+				return false
+			}
+			replacement := text(item)
+			clear(item)
+			item["type"] = "text"
+			item["text"] = replacement
+			return true
+		})
+	}
+}
+
+// eachContent calls fn with the content items of tool results, prompt
+// messages and sampling requests, reporting whether fn changed any
+func eachContent(m Message, fn func(item map[string]any) bool) bool {
+	changed := false
+	visit := func(content any) {
+		switch content := content.(type) {
+		case []any:
+			for _, item := range content {
+				if item, ok := item.(map[string]any); ok && fn(item) {
+					changed = true
+				}
+			}
+		case map[string]any:
+			if fn(content) {
+				changed = true
+			}
+		}
+	}
+	for _, body := range []map[string]any{m.Result(), m.Params()} {
+		visit(body["content"])
+		messages, _ := body["messages"].([]any)
+		for _, message := range messages {
+			if message, ok := message.(map[string]any); ok {
+				visit(message["content"])
+			}
+		}
+	}
+	return changed
+}
+
+// dropProgressMessage removes the message of progress notifications
+func dropProgressMessage(m Message) bool {
+	params := m.Params()
+	if m.Method() != "notifications/progress" || params == nil {
+		return false
+	}
+	if _, ok := params["message"]; !ok {
+		return false
+	}
+	delete(params, "message")
+	return true
+}
+
+// serverCapabilities returns the capabilities of an initialize result, or
+// nil for other messages
+func serverCapabilities(m Message) map[string]any {
+	result := m.Result()
+	if _, ok := result["protocolVersion"]; !ok {
+		return nil
+	}
+	capabilities, _ := result["capabilities"].(map[string]any)
+	return capabilities
+}
+
+// dropCompletionsCapability removes the completions capability from the
+// initialize result
+func dropCompletionsCapability(m Message) bool {
+	capabilities := serverCapabilities(m)
+	if _, ok := capabilities["completions"]; !ok {
+		return false
+	}
+	delete(capabilities, "completions")
+	return true
+}
+
+// injectListChangedFlags spells out listChanged on the tools, prompts and
+// resources capabilities; clients of 2024-11-05 may treat a missing flag
+// as malformed
+func injectListChangedFlags(m Message) bool {
+	changed := false
+	for name, capability := range serverCapabilities(m) {
+		capability, ok := capability.(map[string]any)
+		if !ok || (name != "tools" && name != "prompts" && name != "resources") {
+			continue
+		}
+		if _, ok := capability["listChanged"]; !ok {
+			capability["listChanged"] = false
+			changed = true
+		}
+	}
+	return changed
+}
+
+// flattenStructuredContent removes structuredContent from tool results,
+// serializing it as text content when the result has no other content
+func flattenStructuredContent(m Message) bool {
+	result := m.Result()
+	structured, ok := result["structuredContent"]
+	if !ok {
+		return false
+	}
+	delete(result, "structuredContent")
+	if content, _ := result["content"].([]any); len(content) == 0 {
+		text, err := json.Marshal(structured)
+		if err == nil {
+			result["content"] = []any{map[string]any{"type": "text", "text": string(text)}}
+		}
+	}
+	return true
+}
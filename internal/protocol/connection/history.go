@@ -0,0 +1,103 @@
+package connection
+
+import (
+	"sync"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
+)
+
+// MaxHistoryMessage bounds the bytes kept of one message in a History;
+// longer messages are truncated.
+const MaxHistoryMessage = 4096
+
+// Direction tells whether a message was sent or received by the server.
+type Direction string
+
+const (
+	// Inbound messages were received from the client.
+	Inbound Direction = "in"
+	// Outbound messages were sent to the client.
+	Outbound Direction = "out"
+)
+
+// HistoryEntry is one message recorded by a History.
+type HistoryEntry struct {
+	Time      time.Time `json:"time"`
+	Direction Direction `json:"direction"`
+	Message   string    `json:"message"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// History keeps the last messages of a connection for diagnosing protocol
+// failures after the fact. Messages are redacted before they are stored,
+// so secrets they carry are never held. It is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	next    int
+	full    bool
+
+	redactor *redact.Redactor
+}
+
+// NewHistory creates a history keeping the last size messages, redacted by
+// redactor. A nil redactor means redact.Default().
+func NewHistory(size int, redactor *redact.Redactor) *History {
+	if size <= 0 {
+		size = 1
+	}
+	return &History{entries: make([]HistoryEntry, size), redactor: redactor}
+}
+
+// Record adds a message sent or received in direction, evicting the oldest
+// one when the history is full.
+func (h *History) Record(direction Direction, message []byte) {
+	redactor := h.redactor
+	if redactor == nil {
+		redactor = redact.Default()
+	}
+	entry := HistoryEntry{Time: time.Now(), Direction: direction}
+	redacted := redactor.JSON(message)
+	if len(redacted) > MaxHistoryMessage {
+		redacted = redacted[:MaxHistoryMessage]
+		entry.Truncated = true
+	}
+	entry.Message = string(redacted)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Entries returns the recorded messages, oldest first.
+func (h *History) Entries() []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]HistoryEntry(nil), h.entries[:h.next]...)
+	}
+	entries := make([]HistoryEntry, 0, len(h.entries))
+	entries = append(entries, h.entries[h.next:]...)
+	return append(entries, h.entries[:h.next]...)
+}
+
+// SetHistory records the messages of the connection in h; nil stops
+// recording.
+func (c *Connection) SetHistory(h *History) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = h
+}
+
+// History returns the message history of the connection, or nil when its
+// messages are not recorded.
+func (c *Connection) History() *History {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.history
+}
@@ -0,0 +1,66 @@
+package connection
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory_KeepsLastMessages(t *testing.T) {
+	h := NewHistory(2, nil)
+	if got := h.Entries(); len(got) != 0 {
+		t.Fatalf("Entries() = %v, want none", got)
+	}
+
+	h.Record(Inbound, []byte(`{"id":1}`))
+	h.Record(Outbound, []byte(`{"id":2}`))
+	h.Record(Inbound, []byte(`{"id":3}`))
+
+	entries := h.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("len(Entries()) = %d, want 2", len(entries))
+	}
+	if entries[0].Message != `{"id":2}` || entries[0].Direction != Outbound {
+		t.Errorf("Entries()[0] = %+v, want outbound id 2", entries[0])
+	}
+	if entries[1].Message != `{"id":3}` || entries[1].Direction != Inbound {
+		t.Errorf("Entries()[1] = %+v, want inbound id 3", entries[1])
+	}
+}
+
+func TestHistory_RedactsAndTruncates(t *testing.T) {
+	h := NewHistory(4, nil)
+	message := []byte(`{"params":{"password":"hunter2"}}`)
+	h.Record(Inbound, message)
+	h.Record(Inbound, []byte(`{"text":"`+strings.Repeat("x", MaxHistoryMessage)+`"}`))
+	copy(message, "xxxxxxxxxx")
+
+	entries := h.Entries()
+	if strings.Contains(entries[0].Message, "hunter2") || !strings.Contains(entries[0].Message, "params") {
+		t.Errorf("Entries()[0].Message = %s, want password redacted", entries[0].Message)
+	}
+	if entries[0].Truncated {
+		t.Error("Entries()[0].Truncated = true, want false")
+	}
+	if len(entries[1].Message) != MaxHistoryMessage || !entries[1].Truncated {
+		t.Errorf("Entries()[1] has %d bytes, truncated %v; want %d, true", len(entries[1].Message), entries[1].Truncated, MaxHistoryMessage)
+	}
+	if time.Since(entries[0].Time) > time.Minute {
+		t.Errorf("Entries()[0].Time = %v, want now", entries[0].Time)
+	}
+}
+
+func TestConnection_History(t *testing.T) {
+	conn, err := NewManager(time.Second).CreateConnection("conn1")
+	if err != nil {
+		t.Fatalf("CreateConnection() error = %v", err)
+	}
+	if conn.History() != nil {
+		t.Error("History() != nil before SetHistory")
+	}
+	h := NewHistory(1, nil)
+	conn.SetHistory(h)
+	if conn.History() != h {
+		t.Error("History() did not return the history set")
+	}
+}
@@ -0,0 +1,298 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// DefaultRequestTimeout bounds a server-initiated request whose context has
+// no deadline when the connection sets no RequestTimeout.
+const DefaultRequestTimeout = 30 * time.Second
+
+// Server-initiated methods mcp-go has no constants for.
+const (
+	// MethodRootsList asks the client for the roots it exposes.
+	MethodRootsList = "roots/list"
+
+	// MethodElicitationCreate asks the client to collect structured input
+	// from its user.
+	MethodElicitationCreate = "elicitation/create"
+)
+
+var (
+	// ErrNoSender is returned for requests on a connection whose transport
+	// did not attach a sender.
+	ErrNoSender = errors.New("connection cannot send requests to the client")
+
+	// ErrConnectionClosed is returned for requests on a closed connection,
+	// and for requests still pending when it closes.
+	ErrConnectionClosed = errors.New("connection closed")
+
+	// ErrCapabilityNotSupported is returned for requests the client did not
+	// advertise the capability for.
+	ErrCapabilityNotSupported = errors.New("capability not supported by the client")
+)
+
+// Sender writes an encoded JSON-RPC message to the client of a connection.
+// It must be safe for concurrent use.
+type Sender func(message []byte) error
+
+// ResponseError is an error response from the client to a server-initiated
+// request.
+type ResponseError struct {
+	Method  string `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s: client error %d: %s", e.Method, e.Code, e.Message)
+}
+
+// ElicitParams are the parameters of an elicitation/create request.
+type ElicitParams struct {
+	// Message is shown to the user.
+	Message string `json:"message"`
+
+	// RequestedSchema is a flat object schema of the fields to collect.
+	RequestedSchema any `json:"requestedSchema"`
+}
+
+// Elicitation actions taken by the user.
+const (
+	ElicitAccept  = "accept"
+	ElicitDecline = "decline"
+	ElicitCancel  = "cancel"
+)
+
+// ElicitResult is the client's answer to an elicitation/create request.
+type ElicitResult struct {
+	Action  string         `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
+
+// outbound tracks the server-initiated requests of a connection awaiting
+// their responses.
+type outbound struct {
+	send    Sender
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]chan outboundResponse
+	closed  bool
+}
+
+// outboundResponse is the response to a server-initiated request.
+type outboundResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *ResponseError  `json:"error"`
+	err    error
+}
+
+// clientCapabilityChecks lists the server-initiated methods that depend on
+// a client capability. Methods not listed, such as ping, are always sent.
+var clientCapabilityChecks = map[string]capabilityCheck{
+	string(mcp.MethodSamplingCreateMessage): {"sampling", func(c mcp.ClientCapabilities) bool { return c.Sampling != nil }},
+	MethodRootsList:                         {"roots", func(c mcp.ClientCapabilities) bool { return c.Roots != nil }},
+	// mcp-go's ClientCapabilities has no elicitation field yet, so clients
+	// advertise it as an experimental capability.
+	MethodElicitationCreate: {"elicitation", func(c mcp.ClientCapabilities) bool { _, ok := c.Experimental["elicitation"]; return ok }},
+}
+
+// capabilityCheck names the client capability a method depends on and
+// reports whether the client advertised it.
+type capabilityCheck struct {
+	capability string
+	supported  func(client mcp.ClientCapabilities) bool
+}
+
+// AttachSender enables server-initiated requests on the connection, sending
+// them with send. Transports attach a sender once the connection is
+// created and hand client responses to HandleResponse.
+func (c *Connection) AttachSender(send Sender) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outbound = &outbound{send: send, pending: make(map[string]chan outboundResponse)}
+}
+
+// Request sends a request for method to the client and decodes the result
+// into result, which may be nil. It waits until the client responds, ctx
+// is done, the request times out or the connection closes. Requests whose
+// method depends on a client capability fail without being sent if the
+// client did not advertise it.
+//
+// The client's response is read by the transport, so a handler must not
+// wait for a request while it blocks the transport from reading.
+func (c *Connection) Request(ctx context.Context, method string, params any, result any) error {
+	c.mu.RLock()
+	out, state, timeout, clk := c.outbound, c.State, c.RequestTimeout, c.clock
+	client, negotiated := c.clientCapabilities, c.negotiated
+	c.mu.RUnlock()
+
+	if state == StateClosed {
+		return fmt.Errorf("%s: %w", method, ErrConnectionClosed)
+	}
+	if out == nil {
+		return fmt.Errorf("%s: %w", method, ErrNoSender)
+	}
+	if check, ok := clientCapabilityChecks[method]; ok && negotiated && !check.supported(client) {
+		return fmt.Errorf("%s: %w: %s", method, ErrCapabilityNotSupported, check.capability)
+	}
+
+	id, responses, ok := out.register()
+	if !ok {
+		return fmt.Errorf("%s: %w", method, ErrConnectionClosed)
+	}
+	defer out.forget(id)
+
+	message, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(id),
+		Params:  params,
+		Request: mcp.Request{Method: method},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: encode request: %w", method, err)
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	var expired <-chan time.Time
+	if _, ok := ctx.Deadline(); !ok {
+		timer := clock.Or(clk).NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C()
+	}
+	if err := out.send(message); err != nil {
+		return fmt.Errorf("%s: send request: %w", method, err)
+	}
+
+	select {
+	case response := <-responses:
+		switch {
+		case response.err != nil:
+			return fmt.Errorf("%s: %w", method, response.err)
+		case response.Error != nil:
+			response.Error.Method = method
+			return response.Error
+		case result != nil:
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("%s: decode result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	case <-expired:
+		return fmt.Errorf("%s: no response within %s: %w", method, timeout, context.DeadlineExceeded)
+	}
+}
+
+// HandleResponse delivers message, a JSON-RPC response from the client, to
+// the request awaiting it. It reports false if message answers no pending
+// request of the connection.
+func (c *Connection) HandleResponse(message []byte) bool {
+	c.mu.RLock()
+	out := c.outbound
+	c.mu.RUnlock()
+	if out == nil {
+		return false
+	}
+
+	var response struct {
+		ID mcp.RequestId `json:"id"`
+		outboundResponse
+	}
+	if err := json.Unmarshal(message, &response); err != nil {
+		return false
+	}
+	id, ok := response.ID.Value().(string)
+	if !ok {
+		return false
+	}
+
+	out.mu.Lock()
+	responses, ok := out.pending[id]
+	delete(out.pending, id)
+	out.mu.Unlock()
+	if ok {
+		responses <- response.outboundResponse
+	}
+	return ok
+}
+
+// Ping checks that the client is responsive.
+func (c *Connection) Ping(ctx context.Context) error {
+	return c.Request(ctx, string(mcp.MethodPing), nil, nil)
+}
+
+// CreateMessage asks the client to sample an LLM completion.
+func (c *Connection) CreateMessage(ctx context.Context, params mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	var result mcp.CreateMessageResult
+	if err := c.Request(ctx, string(mcp.MethodSamplingCreateMessage), params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListRoots asks the client for the roots it exposes to the server.
+func (c *Connection) ListRoots(ctx context.Context) (*mcp.ListRootsResult, error) {
+	var result mcp.ListRootsResult
+	if err := c.Request(ctx, MethodRootsList, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Elicit asks the client to collect input from its user.
+func (c *Connection) Elicit(ctx context.Context, params ElicitParams) (*ElicitResult, error) {
+	var result ElicitResult
+	if err := c.Request(ctx, MethodElicitationCreate, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// register allocates the ID of a new request and the channel its response
+// is delivered on. It reports false once the connection has closed.
+func (o *outbound) register() (string, chan outboundResponse, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return "", nil, false
+	}
+	o.nextID++
+	id := "srv-" + strconv.FormatUint(o.nextID, 10)
+	responses := make(chan outboundResponse, 1)
+	o.pending[id] = responses
+	return id, responses, true
+}
+
+// forget stops waiting for the response to id.
+func (o *outbound) forget(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.pending, id)
+}
+
+// close ends every pending request with ErrConnectionClosed and rejects
+// new ones.
+func (o *outbound) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	for id, responses := range o.pending {
+		responses <- outboundResponse{err: ErrConnectionClosed}
+		delete(o.pending, id)
+	}
+}
@@ -0,0 +1,166 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// sentRequest is a server-initiated request captured by a test sender.
+type sentRequest struct {
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// newOutboundConnection returns a ready connection whose sent requests are
+// delivered on the returned channel.
+func newOutboundConnection(t *testing.T, manager *Manager) (*Connection, <-chan sentRequest) {
+	t.Helper()
+	conn, err := manager.CreateConnection("conn1")
+	if err != nil {
+		t.Fatalf("CreateConnection() error = %v", err)
+	}
+	if err := conn.StartHandshake(nil); err != nil {
+		t.Fatalf("StartHandshake() error = %v", err)
+	}
+	if err := conn.CompleteHandshake("2025-03-26", nil); err != nil {
+		t.Fatalf("CompleteHandshake() error = %v", err)
+	}
+
+	sent := make(chan sentRequest, 10)
+	conn.AttachSender(func(message []byte) error {
+		var request sentRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			t.Errorf("sent invalid message %s: %v", message, err)
+		}
+		sent <- request
+		return nil
+	})
+	return conn, sent
+}
+
+// respond answers the next request sent on conn with response, a JSON
+// object holding result or error.
+func respond(t *testing.T, conn *Connection, sent <-chan sentRequest, method, response string) {
+	t.Helper()
+	request := <-sent
+	if request.Method != method {
+		t.Errorf("sent method = %q, want %q", request.Method, method)
+	}
+	message := `{"jsonrpc":"2.0","id":"` + request.ID + `",` + response[1:]
+	if !conn.HandleResponse([]byte(message)) {
+		t.Errorf("HandleResponse(%s) = false, want true", message)
+	}
+}
+
+func TestConnection_Requests(t *testing.T) {
+	conn, sent := newOutboundConnection(t, NewManager(time.Minute))
+	conn.SetCapabilities(mcp.ClientCapabilities{
+		Roots: &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{},
+		Sampling:     &struct{}{},
+		Experimental: map[string]any{"elicitation": map[string]any{}},
+	}, mcp.ServerCapabilities{})
+	ctx := context.Background()
+
+	go respond(t, conn, sent, "ping", `{"result":{}}`)
+	if err := conn.Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	go respond(t, conn, sent, "roots/list", `{"result":{"roots":[{"uri":"file:///src","name":"src"}]}}`)
+	roots, err := conn.ListRoots(ctx)
+	if err != nil || len(roots.Roots) != 1 || roots.Roots[0].URI != "file:///src" {
+		t.Errorf("ListRoots() = %+v, %v", roots, err)
+	}
+
+	go respond(t, conn, sent, "sampling/createMessage",
+		`{"result":{"role":"assistant","content":{"type":"text","text":"hi"},"model":"m"}}`)
+	sampled, err := conn.CreateMessage(ctx, mcp.CreateMessageParams{MaxTokens: 10})
+	if err != nil || sampled.Model != "m" {
+		t.Errorf("CreateMessage() = %+v, %v", sampled, err)
+	}
+
+	go respond(t, conn, sent, "elicitation/create", `{"result":{"action":"accept","content":{"name":"x"}}}`)
+	elicited, err := conn.Elicit(ctx, ElicitParams{Message: "Name?", RequestedSchema: map[string]any{"type": "object"}})
+	if err != nil || elicited.Action != ElicitAccept || elicited.Content["name"] != "x" {
+		t.Errorf("Elicit() = %+v, %v", elicited, err)
+	}
+
+	go respond(t, conn, sent, "roots/list", `{"error":{"code":-32601,"message":"Method not found"}}`)
+	_, err = conn.ListRoots(ctx)
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.Code != -32601 || responseErr.Method != "roots/list" {
+		t.Errorf("ListRoots() error = %v, want client error -32601", err)
+	}
+
+	// Responses to no pending request are not consumed
+	if conn.HandleResponse([]byte(`{"jsonrpc":"2.0","id":"srv-99","result":{}}`)) {
+		t.Error("HandleResponse() of an unknown ID = true, want false")
+	}
+}
+
+func TestConnection_RequestCapabilities(t *testing.T) {
+	conn, sent := newOutboundConnection(t, NewManager(time.Minute))
+	conn.SetCapabilities(mcp.ClientCapabilities{}, mcp.ServerCapabilities{})
+	ctx := context.Background()
+
+	if _, err := conn.ListRoots(ctx); !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("ListRoots() error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if _, err := conn.CreateMessage(ctx, mcp.CreateMessageParams{}); !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("CreateMessage() error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if _, err := conn.Elicit(ctx, ElicitParams{}); !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Elicit() error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("sent %d requests, want none", len(sent))
+	}
+
+	// Ping needs no capability
+	go respond(t, conn, sent, "ping", `{"result":{}}`)
+	if err := conn.Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
+func TestConnection_RequestTimeoutAndClose(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1700000000, 0))
+	manager := NewManager(time.Minute)
+	manager.SetClock(fake)
+	conn, sent := newOutboundConnection(t, manager)
+	conn.RequestTimeout = time.Second
+
+	errs := make(chan error, 1)
+	go func() { errs <- conn.Ping(context.Background()) }()
+	<-sent
+	fake.Advance(time.Second)
+	if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ping() error = %v, want DeadlineExceeded", err)
+	}
+
+	// Closing the connection fails pending requests and later ones
+	go func() { errs <- conn.Ping(context.Background()) }()
+	<-sent
+	manager.RemoveConnection(conn.ID)
+	if err := <-errs; !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Ping() error = %v, want ErrConnectionClosed", err)
+	}
+	if err := conn.Ping(context.Background()); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Ping() after close error = %v, want ErrConnectionClosed", err)
+	}
+
+	unattached, _ := manager.CreateConnection("conn2")
+	if err := unattached.Ping(context.Background()); !errors.Is(err, ErrNoSender) {
+		t.Errorf("Ping() without sender error = %v, want ErrNoSender", err)
+	}
+}
@@ -4,8 +4,15 @@ package connection
 import (
 	"context"
 	"fmt"
+	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
 )
 
 // ConnectionState represents the current state of an MCP connection.
@@ -46,6 +53,12 @@ const (
 	ConnectionIDKey contextKey = "mcp:connection:id"
 	// ConnectionStateKey is the context key for storing connection state.
 	ConnectionStateKey contextKey = "mcp:connection:state"
+
+	// connectionKey is the context key for storing the connection itself.
+	connectionKey contextKey = "mcp:connection"
+
+	// identityKey is the context key for storing the authenticated identity.
+	identityKey contextKey = "mcp:connection:identity"
 )
 
 // Connection represents a single MCP connection with its state and metadata.
@@ -57,17 +70,106 @@ type Connection struct {
 	ProtocolVersion  string
 	ClientInfo       map[string]interface{}
 
+	// RequestTimeout bounds server-initiated requests whose context has no
+	// deadline; zero means DefaultRequestTimeout.
+	RequestTimeout time.Duration
+
+	// initialized is set when the client confirms the handshake with
+	// notifications/initialized.
+	initialized bool
+
+	// capabilities holds what each side advertised during the handshake;
+	// negotiated is false until they are recorded.
+	clientCapabilities mcp.ClientCapabilities
+	serverCapabilities mcp.ServerCapabilities
+	negotiated         bool
+
+	// outbound correlates server-initiated requests with the client's
+	// responses once a transport attached a sender.
+	outbound *outbound
+
+	// history keeps the last messages of the connection when the transport
+	// records them.
+	history *History
+
+	// unhealthy explains why the transport gave up on the connection, e.g.
+	// a client that stopped reading; empty while it is healthy.
+	unhealthy string
+
 	mu            sync.RWMutex
 	handshakeOnce sync.Once
-	timeoutTimer  *time.Timer
+	timeoutTimer  clock.Timer
+
+	// clock times the handshake; nil means the system clock.
+	clock clock.Clock
+
+	// counts is updated on every state change while the connection is
+	// tracked by a manager.
+	counts *stateCounts
 }
 
 // Manager manages connection states for multiple concurrent connections.
 type Manager struct {
 	connections map[string]*Connection
 	mu          sync.RWMutex
+	counts      stateCounts
 
 	defaultTimeout time.Duration
+	clock          clock.Clock
+}
+
+// stateNone stands for a connection that is not tracked, as the source or
+// target of a stateCounts transition.
+const stateNone ConnectionState = -1
+
+// stateCounts counts connections by state. Transitions are serialized and
+// bump seq before and after changing the counts, so seq is odd while one
+// is in progress. Readers take no lock; they retry when a transition
+// overlapped their read, so a snapshot never counts a connection twice.
+type stateCounts struct {
+	mu     sync.Mutex
+	seq    atomic.Uint64
+	counts [StateClosed + 1]atomic.Int64
+}
+
+// transition moves a connection from one state to another.
+func (s *stateCounts) transition(from, to ConnectionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq.Add(1)
+	if from >= 0 && int(from) < len(s.counts) {
+		s.counts[from].Add(-1)
+	}
+	if to >= 0 && int(to) < len(s.counts) {
+		s.counts[to].Add(1)
+	}
+	s.seq.Add(1)
+}
+
+// snapshot returns the number of connections in each state that has any.
+func (s *stateCounts) snapshot() map[ConnectionState]int {
+	var counts [len(s.counts)]int64
+	for {
+		seq := s.seq.Load()
+		if seq%2 == 0 {
+			for i := range s.counts {
+				counts[i] = s.counts[i].Load()
+			}
+			if s.seq.Load() == seq {
+				break
+			}
+		}
+		runtime.Gosched()
+	}
+
+	snapshot := make(map[ConnectionState]int)
+	for state, n := range counts {
+		if n > 0 {
+			snapshot[ConnectionState(state)] = int(n)
+		}
+	}
+	return snapshot
 }
 
 // NewManager creates a new connection manager with the specified default timeout.
@@ -82,6 +184,14 @@ func NewManager(defaultTimeout time.Duration) *Manager {
 	}
 }
 
+// SetClock sets the clock that times the handshakes of connections created
+// afterwards. Tests use a fake clock to trigger timeouts deterministically.
+func (m *Manager) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = c
+}
+
 // CreateConnection creates a new connection with the given ID.
 func (m *Manager) CreateConnection(id string) (*Connection, error) {
 	m.mu.Lock()
@@ -96,9 +206,12 @@ func (m *Manager) CreateConnection(id string) (*Connection, error) {
 		State:            StateNew,
 		HandshakeTimeout: m.defaultTimeout,
 		ClientInfo:       make(map[string]interface{}),
+		counts:           &m.counts,
+		clock:            m.clock,
 	}
 
 	m.connections[id] = conn
+	m.counts.transition(stateNone, StateNew)
 	return conn, nil
 }
 
@@ -119,7 +232,76 @@ func (m *Manager) RemoveConnection(id string) {
 	if conn, exists := m.connections[id]; exists {
 		conn.Close()
 		delete(m.connections, id)
+
+		conn.mu.Lock()
+		m.counts.transition(conn.State, stateNone)
+		conn.counts = nil
+		conn.mu.Unlock()
+	}
+}
+
+// StateCounts returns the number of connections in each state. The counts
+// are kept up to date by state changes, so reading them takes no lock.
+func (m *Manager) StateCounts() map[ConnectionState]int {
+	return m.counts.snapshot()
+}
+
+// ConnectionInfo is a point-in-time copy of a connection's state.
+type ConnectionInfo struct {
+	ID               string                 `json:"id"`
+	State            string                 `json:"state"`
+	HandshakeStarted time.Time              `json:"handshakeStarted,omitempty"`
+	ProtocolVersion  string                 `json:"protocolVersion,omitempty"`
+	ClientInfo       map[string]interface{} `json:"clientInfo,omitempty"`
+	Unhealthy        string                 `json:"unhealthy,omitempty"`
+}
+
+// Snapshot returns a copy of every tracked connection, sorted by ID.
+func (m *Manager) Snapshot() []ConnectionInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]ConnectionInfo, 0, len(m.connections))
+	for _, conn := range m.connections {
+		infos = append(infos, conn.Info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Info returns a copy of the connection's state.
+func (c *Connection) Info() ConnectionInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	info := ConnectionInfo{
+		ID:               c.ID,
+		State:            c.State.String(),
+		HandshakeStarted: c.HandshakeStarted,
+		ProtocolVersion:  c.ProtocolVersion,
+		ClientInfo:       make(map[string]interface{}, len(c.ClientInfo)),
+		Unhealthy:        c.unhealthy,
+	}
+	for k, v := range c.ClientInfo {
+		info.ClientInfo[k] = v
 	}
+	return info
+}
+
+// MarkUnhealthy records that the transport gave up on the connection and
+// why. The connection is expected to be closed soon after.
+func (c *Connection) MarkUnhealthy(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unhealthy = reason
+}
+
+// Healthy reports whether the connection has not been marked unhealthy.
+func (c *Connection) Healthy() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.unhealthy == ""
 }
 
 // GetState returns the current state of the connection.
@@ -139,12 +321,12 @@ func (c *Connection) SetState(newState ConnectionState) error {
 		return fmt.Errorf("invalid state transition from %s to %s", c.State, newState)
 	}
 
-	c.State = newState
+	c.setState(newState)
 
 	// Handle state-specific logic
 	switch newState {
 	case StateInitializing:
-		c.HandshakeStarted = time.Now()
+		c.HandshakeStarted = clock.Or(c.clock).Now()
 	case StateReady, StateClosed:
 		// Cancel timeout timer if it exists
 		if c.timeoutTimer != nil {
@@ -161,6 +343,45 @@ func (c *Connection) IsReady() bool {
 	return c.GetState() == StateReady
 }
 
+// MarkInitialized records the client's notifications/initialized, which
+// confirms a completed handshake.
+func (c *Connection) MarkInitialized() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.State != StateReady {
+		return fmt.Errorf("cannot confirm handshake in state %s", c.State)
+	}
+	c.initialized = true
+	return nil
+}
+
+// IsInitialized returns true if the connection is ready and the client has
+// sent notifications/initialized.
+func (c *Connection) IsInitialized() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.State == StateReady && c.initialized
+}
+
+// SetCapabilities records the capabilities the client and server advertised
+// during the handshake.
+func (c *Connection) SetCapabilities(client mcp.ClientCapabilities, server mcp.ServerCapabilities) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientCapabilities = client
+	c.serverCapabilities = server
+	c.negotiated = true
+}
+
+// Capabilities returns the capabilities recorded by SetCapabilities. The
+// last result is false if none have been recorded.
+func (c *Connection) Capabilities() (mcp.ClientCapabilities, mcp.ServerCapabilities, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clientCapabilities, c.serverCapabilities, c.negotiated
+}
+
 // StartHandshake initiates the handshake process with timeout.
 func (c *Connection) StartHandshake(timeoutCallback func()) error {
 	var err error
@@ -175,10 +396,10 @@ func (c *Connection) StartHandshake(timeoutCallback func()) error {
 
 		// Start timeout timer
 		c.mu.Lock()
-		c.timeoutTimer = time.AfterFunc(c.HandshakeTimeout, func() {
+		c.timeoutTimer = clock.Or(c.clock).AfterFunc(c.HandshakeTimeout, func() {
 			c.mu.Lock()
 			if c.State == StateInitializing {
-				c.State = StateClosed
+				c.setState(StateClosed)
 			}
 			c.mu.Unlock()
 
@@ -210,7 +431,7 @@ func (c *Connection) CompleteHandshake(protocolVersion string, clientInfo map[st
 		return fmt.Errorf("cannot complete handshake in state %s", c.State)
 	}
 
-	c.State = StateReady
+	c.setState(StateReady)
 	c.ProtocolVersion = protocolVersion
 
 	// Store client info
@@ -232,12 +453,24 @@ func (c *Connection) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.State = StateClosed
+	c.setState(StateClosed)
 
 	if c.timeoutTimer != nil {
 		c.timeoutTimer.Stop()
 		c.timeoutTimer = nil
 	}
+	if c.outbound != nil {
+		c.outbound.close()
+	}
+}
+
+// setState changes the state, updating the manager's counts. The caller
+// holds c.mu.
+func (c *Connection) setState(state ConnectionState) {
+	if c.counts != nil && state != c.State {
+		c.counts.transition(c.State, state)
+	}
+	c.State = state
 }
 
 // isValidTransition checks if a state transition is allowed.
@@ -276,3 +509,28 @@ func GetConnectionID(ctx context.Context) (string, bool) {
 	id, ok := ctx.Value(ConnectionIDKey).(string)
 	return id, ok
 }
+
+// WithConnection adds a connection and its ID to the context, so handlers
+// can read its state without the manager.
+func WithConnection(ctx context.Context, conn *Connection) context.Context {
+	ctx = WithConnectionID(ctx, conn.ID)
+	return context.WithValue(ctx, connectionKey, conn)
+}
+
+// FromContext retrieves the connection added by WithConnection.
+func FromContext(ctx context.Context) (*Connection, bool) {
+	conn, ok := ctx.Value(connectionKey).(*Connection)
+	return conn, ok
+}
+
+// WithIdentity adds the identity a transport authenticated the client as
+// to the context. Quotas, receipts and privileged features key on it.
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// IdentityFromContext retrieves the identity added by WithIdentity.
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(identityKey).(string)
+	return identity, ok && identity != ""
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
 )
 
 func TestConnectionState_String(t *testing.T) {
@@ -93,6 +95,73 @@ func TestManager_RemoveConnection(t *testing.T) {
 	manager.RemoveConnection("conn2")
 }
 
+func TestManager_StateCounts(t *testing.T) {
+	manager := NewManager(10 * time.Second)
+
+	manager.CreateConnection("conn1")
+	conn2, _ := manager.CreateConnection("conn2")
+	conn2.SetState(StateInitializing)
+
+	counts := manager.StateCounts()
+	if counts[StateNew] != 1 || counts[StateInitializing] != 1 {
+		t.Errorf("Unexpected state counts: %v", counts)
+	}
+}
+
+func TestManager_StateCountsConcurrent(t *testing.T) {
+	manager := NewManager(10 * time.Second)
+
+	const n = 200
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("conn%d", i)
+			conn, _ := manager.CreateConnection(id)
+			conn.SetState(StateInitializing)
+			conn.CompleteHandshake("2025-03-26", nil)
+			if i%2 == 0 {
+				manager.RemoveConnection(id)
+			}
+		}(i)
+	}
+
+	// Snapshots stay within bounds while connections change state
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		total := 0
+		for _, count := range manager.StateCounts() {
+			total += count
+		}
+		if total > n {
+			t.Fatalf("Counted %d connections, only %d exist", total, n)
+		}
+	}
+
+	counts := manager.StateCounts()
+	if len(counts) != 1 || counts[StateReady] != n/2 {
+		t.Errorf("Unexpected state counts: %v", counts)
+	}
+
+	// Removed connections no longer count
+	conn, _ := manager.GetConnection("conn1")
+	manager.RemoveConnection("conn1")
+	conn.Close()
+	if counts := manager.StateCounts(); counts[StateReady] != n/2-1 || counts[StateClosed] != 0 {
+		t.Errorf("Unexpected state counts after removal: %v", counts)
+	}
+}
+
 func TestConnection_StateTransitions(t *testing.T) {
 	conn := &Connection{
 		ID:         "test",
@@ -136,11 +205,13 @@ func TestConnection_StateTransitions(t *testing.T) {
 }
 
 func TestConnection_StartHandshake(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
 	conn := &Connection{
 		ID:               "test",
 		State:            StateNew,
 		HandshakeTimeout: 100 * time.Millisecond,
 		ClientInfo:       make(map[string]interface{}),
+		clock:            fake,
 	}
 
 	timeoutCalled := false
@@ -155,6 +226,9 @@ func TestConnection_StartHandshake(t *testing.T) {
 	if conn.State != StateInitializing {
 		t.Errorf("State after StartHandshake() = %v, want StateInitializing", conn.State)
 	}
+	if !conn.HandshakeStarted.Equal(fake.Now()) {
+		t.Errorf("HandshakeStarted = %v, want %v", conn.HandshakeStarted, fake.Now())
+	}
 
 	// Test that handshake can only be started once
 	err = conn.StartHandshake(nil)
@@ -162,15 +236,39 @@ func TestConnection_StartHandshake(t *testing.T) {
 		t.Error("Expected error when starting handshake twice")
 	}
 
-	// Wait for timeout
-	time.Sleep(150 * time.Millisecond)
+	fake.Advance(99 * time.Millisecond)
+	if timeoutCalled {
+		t.Error("Timeout callback was called before the timeout")
+	}
+
+	// The fake clock runs the callback before Advance returns
+	fake.Advance(time.Millisecond)
 
 	if !timeoutCalled {
 		t.Error("Timeout callback was not called")
 	}
 
-	if conn.State != StateClosed {
-		t.Errorf("State after timeout = %v, want StateClosed", conn.State)
+	if conn.GetState() != StateClosed {
+		t.Errorf("State after timeout = %v, want StateClosed", conn.GetState())
+	}
+}
+
+func TestManager_SetClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	manager := NewManager(time.Second)
+	manager.SetClock(fake)
+
+	conn, err := manager.CreateConnection("test")
+	if err != nil {
+		t.Fatalf("CreateConnection() error = %v", err)
+	}
+	if err := conn.StartHandshake(nil); err != nil {
+		t.Fatalf("StartHandshake() error = %v", err)
+	}
+
+	fake.Advance(time.Second)
+	if conn.GetState() != StateClosed {
+		t.Errorf("State after timeout = %v, want StateClosed", conn.GetState())
 	}
 }
 
@@ -222,6 +320,33 @@ func TestConnection_IsReady(t *testing.T) {
 	}
 }
 
+func TestConnection_MarkInitialized(t *testing.T) {
+	conn := &Connection{State: StateInitializing}
+
+	if err := conn.MarkInitialized(); err == nil {
+		t.Error("MarkInitialized() succeeded before the handshake completed")
+	}
+	if conn.IsInitialized() {
+		t.Error("IsInitialized() = true for StateInitializing")
+	}
+
+	conn.State = StateReady
+	if conn.IsInitialized() {
+		t.Error("IsInitialized() = true before notifications/initialized")
+	}
+	if err := conn.MarkInitialized(); err != nil {
+		t.Fatalf("MarkInitialized() error = %v", err)
+	}
+	if !conn.IsInitialized() {
+		t.Error("IsInitialized() = false after MarkInitialized()")
+	}
+
+	conn.State = StateClosed
+	if conn.IsInitialized() {
+		t.Error("IsInitialized() = true for StateClosed")
+	}
+}
+
 func TestConnection_ConcurrentAccess(t *testing.T) {
 	manager := NewManager(10 * time.Second)
 	conn, _ := manager.CreateConnection("test")
@@ -279,6 +404,24 @@ func TestConnectionFromContext(t *testing.T) {
 	}
 }
 
+func TestWithConnection(t *testing.T) {
+	manager := NewManager(10 * time.Second)
+	conn, _ := manager.CreateConnection("test-id")
+
+	ctx := WithConnection(context.Background(), conn)
+	retrieved, ok := FromContext(ctx)
+	if !ok || retrieved != conn {
+		t.Errorf("FromContext() = %v, %v, want the connection", retrieved, ok)
+	}
+	if id, ok := GetConnectionID(ctx); !ok || id != "test-id" {
+		t.Errorf("GetConnectionID() = %q, %v, want test-id", id, ok)
+	}
+
+	if _, ok := FromContext(WithConnectionID(context.Background(), "test-id")); ok {
+		t.Error("FromContext() ok = true for context with only an ID")
+	}
+}
+
 // Benchmarks for connection management performance
 func BenchmarkManagerCreateConnection(b *testing.B) {
 	manager := NewManager(10 * time.Second)
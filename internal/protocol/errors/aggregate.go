@@ -0,0 +1,166 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// ItemStatus is the outcome of one item in a fan-out operation
+type ItemStatus string
+
+// Item statuses
+const (
+	ItemSucceeded ItemStatus = "succeeded"
+	ItemFailed    ItemStatus = "failed"
+	ItemSkipped   ItemStatus = "skipped"
+)
+
+// ItemResult records the outcome of one item in a fan-out operation
+type ItemResult struct {
+	// ID identifies the item (request ID, upstream name, ...)
+	ID string
+
+	// Status is the item's outcome
+	Status ItemStatus
+
+	// Err is set for failed items
+	Err error
+
+	// Reason explains why a skipped item was not attempted
+	Reason string
+}
+
+// AggregateSummary counts item outcomes
+type AggregateSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// ItemError is the client-safe form of a failed item's error
+type ItemError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ItemData is the client-facing representation of an ItemResult
+type ItemData struct {
+	ID     string     `json:"id"`
+	Status ItemStatus `json:"status"`
+	Error  *ItemError `json:"error,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// AggregateData is the error data reported for a partially successful
+// operation
+type AggregateData struct {
+	Summary AggregateSummary `json:"summary"`
+	Items   []ItemData       `json:"items"`
+}
+
+// NewPartialError builds an aggregate error from item results. It returns
+// nil when no item failed.
+func NewPartialError(items []ItemResult, code int, message string) *AggregateError {
+	var failed []error
+	for _, item := range items {
+		if item.Status == ItemFailed && item.Err != nil {
+			failed = append(failed, item.Err)
+		}
+	}
+
+	ae := NewAggregateError(failed, code, message)
+	if ae != nil {
+		ae.Items = items
+	}
+	return ae
+}
+
+// Summary counts the outcomes of the recorded items
+func (ae *AggregateError) Summary() AggregateSummary {
+	summary := AggregateSummary{Total: len(ae.Items)}
+	for _, item := range ae.Items {
+		switch item.Status {
+		case ItemSucceeded:
+			summary.Succeeded++
+		case ItemFailed:
+			summary.Failed++
+		case ItemSkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// PartialSuccess reports whether at least one item succeeded
+func (ae *AggregateError) PartialSuccess() bool {
+	return ae.Summary().Succeeded > 0
+}
+
+// Data returns the structured representation of the item results. Errors
+// that are not MCPErrors are reported with the aggregate's code and its
+// standard message so internal details don't reach clients.
+func (ae *AggregateError) Data() AggregateData {
+	data := AggregateData{
+		Summary: ae.Summary(),
+		Items:   make([]ItemData, len(ae.Items)),
+	}
+	for i, item := range ae.Items {
+		data.Items[i] = ItemData{ID: item.ID, Status: item.Status, Reason: item.Reason}
+		if item.Status != ItemFailed || item.Err == nil {
+			continue
+		}
+		if mcpErr := FindMCPError(item.Err); mcpErr != nil {
+			data.Items[i].Error = &ItemError{Code: mcpErr.Code, Message: mcpErr.Message}
+		} else {
+			data.Items[i].Error = &ItemError{Code: ae.Code, Message: GetMCPErrorMessage(ae.Code)}
+		}
+	}
+	return data
+}
+
+// FanOut runs fn for each id with at most limit calls in flight (0 means no
+// limit) and returns the results in id order. Items not started before ctx
+// is done are marked skipped.
+func FanOut(ctx context.Context, ids []string, limit int, fn func(ctx context.Context, id string) error) []ItemResult {
+	if limit <= 0 || limit > len(ids) {
+		limit = len(ids)
+	}
+
+	results := make([]ItemResult, len(ids))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			results[i] = ItemResult{ID: id, Status: ItemSkipped, Reason: err.Error()}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			results[i] = ItemResult{ID: id, Status: ItemSkipped, Reason: ctx.Err().Error()}
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					results[i] = ItemResult{ID: id, Status: ItemFailed, Err: FromPanic(r)}
+				}
+			}()
+
+			if err := fn(ctx, id); err != nil {
+				results[i] = ItemResult{ID: id, Status: ItemFailed, Err: err}
+				return
+			}
+			results[i] = ItemResult{ID: id, Status: ItemSucceeded}
+		}(i, id)
+	}
+
+	wg.Wait()
+	return results
+}
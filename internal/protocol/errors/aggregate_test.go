@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPartialError(t *testing.T) {
+	items := []ItemResult{
+		{ID: "github", Status: ItemSucceeded},
+		{ID: "gitlab", Status: ItemFailed, Err: NewToolNotFoundError("search")},
+		{ID: "fs", Status: ItemFailed, Err: errors.New("open /etc/secret: permission denied")},
+		{ID: "jira", Status: ItemSkipped, Reason: "disabled"},
+	}
+
+	ae := NewPartialError(items, ErrorCodeMCPHandler, "Partial failure")
+	require.NotNil(t, ae)
+	assert.Len(t, ae.Errors, 2)
+	assert.True(t, ae.PartialSuccess())
+	assert.Equal(t, AggregateSummary{Total: 4, Succeeded: 1, Failed: 2, Skipped: 1}, ae.Summary())
+
+	mcpErr := ae.ToMCPError()
+	raw, err := json.Marshal(mcpErr.ToJSONRPCError().Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"summary": {"total": 4, "succeeded": 1, "failed": 2, "skipped": 1},
+		"items": [
+			{"id": "github", "status": "succeeded"},
+			{"id": "gitlab", "status": "failed", "error": {"code": -32041, "message": "Tool not found: search"}},
+			{"id": "fs", "status": "failed", "error": {"code": -32040, "message": "Handler error"}},
+			{"id": "jira", "status": "skipped", "reason": "disabled"}
+		]
+	}`, string(raw))
+
+	assert.Nil(t, NewPartialError([]ItemResult{{ID: "a", Status: ItemSucceeded}}, ErrorCodeMCPHandler, "ok"))
+}
+
+func TestFanOut(t *testing.T) {
+	results := FanOut(context.Background(), []string{"a", "b", "c"}, 2, func(ctx context.Context, id string) error {
+		switch id {
+		case "b":
+			return NewConnectionLostError("reset")
+		case "c":
+			panic("boom")
+		}
+		return nil
+	})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, ItemSucceeded, results[0].Status)
+	assert.Equal(t, ItemFailed, results[1].Status)
+	assert.Equal(t, ItemFailed, results[2].Status)
+	assert.Equal(t, ErrorCodeMCPSystem, FindMCPError(results[2].Err).Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = FanOut(ctx, []string{"a"}, 0, func(ctx context.Context, id string) error { return nil })
+	assert.Equal(t, ItemSkipped, results[0].Status)
+	assert.Equal(t, context.Canceled.Error(), results[0].Reason)
+}
@@ -50,6 +50,27 @@
 //		log.Printf("MCP error code: %d", mcpErr.Code)
 //	}
 //
+// # Upstream Errors
+//
+// Errors returned by upstream MCP servers use foreign codes. A Translator
+// normalizes them into this taxonomy, keeping the original as the cause and
+// in debug info:
+//
+//	tr, _ := NewTranslator(TranslationConfig{Rules: DefaultTranslationRules()})
+//	mcpErr := tr.Translate("github", resp.Error)
+//
+// # Panics
+//
+// FromPanic converts a recovered value into a system error whose data names
+// the panic type (nil_pointer, index_out_of_range, ...). The stack trace is
+// captured into debug info only after SetDebugMode(true):
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			resp = jsonrpc.NewErrorResponse(FromPanic(r).ToJSONRPCError(), req.ID)
+//		}
+//	}()
+//
 // # Structured Logging
 //
 // The package provides structured logging with automatic context extraction:
@@ -95,6 +116,13 @@
 //		// Don't retry, handle gracefully
 //	}
 //
+// Retry repeats an operation with exponential backoff and jitter while its
+// errors are retryable:
+//
+//	err := Retry(ctx, DefaultRetryPolicy(), func() error {
+//		return client.Call(ctx, req)
+//	})
+//
 // # Aggregate Errors
 //
 // For operations that can produce multiple errors:
@@ -107,6 +135,42 @@
 //		mcpErr := aggErr.ToMCPError()
 //	}
 //
+// Fan-out operations report partial success with per-item status; the
+// error data carries a summary and each item's outcome:
+//
+//	results := FanOut(ctx, upstreams, 4, callUpstream)
+//	if aggErr := NewPartialError(results, ErrorCodeMCPHandler, "Some upstreams failed"); aggErr != nil {
+//		mcpErr := aggErr.ToMCPError()
+//	}
+//
+// # Code Registry
+//
+// Packages defining new error codes register them at init so collisions
+// fail fast at startup:
+//
+//	func init() {
+//		errors.MustRegisterCode(-32090, "UpstreamDown", "Upstream server unavailable", "proxy")
+//	}
+//
+// The catalog is served by the errors/catalog admin method and rendered to
+// docs/error-codes.md with `make error-reference`:
+//
+//	r.RegisterFunc(CatalogMethod, DefaultRegistry().Handler())
+//
+// # Error Budgets
+//
+// A Recorder counts errors by code and category over a sliding window and
+// reports when a category exceeds its error budget. The server records
+// every response through RegisterHooks and router.ErrorRecorderMiddleware,
+// and health.ErrorBudgetCheck fails readiness while a budget is exceeded:
+//
+//	recorder := NewRecorder(RecorderConfig{
+//		Budgets: map[string]Budget{"transport": {MaxRate: 0.1, MinRequests: 20}},
+//		OnBudgetExceeded: func(e BudgetEvent) { log.Printf("%s errors at %.0f%%", e.Category, e.Rate*100) },
+//	})
+//	recorder.RecordResponse(resp)
+//	rate := recorder.ErrorRate(CategoryAll)
+//
 // # Best Practices
 //
 //   - Use factory functions for common error types
@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"runtime"
-	"strings"
 
 	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
 )
 
 // LogLevel represents the severity level for error logging
@@ -41,6 +41,7 @@ func (l LogLevel) String() string {
 // ErrorLogger provides structured logging for MCP errors
 type ErrorLogger struct {
 	logger    *logging.Logger
+	redactor  *redact.Redactor
 	debugMode bool
 	sanitize  bool
 }
@@ -63,11 +64,19 @@ func NewErrorLogger(debugMode bool, sanitize bool) *ErrorLogger {
 
 	return &ErrorLogger{
 		logger:    logger,
+		redactor:  redact.Default(),
 		debugMode: debugMode,
 		sanitize:  sanitize,
 	}
 }
 
+// WithRedactor returns a copy of the logger that uses the given redactor
+func (el *ErrorLogger) WithRedactor(r *redact.Redactor) *ErrorLogger {
+	newLogger := *el
+	newLogger.redactor = r
+	return &newLogger
+}
+
 // LogError logs an MCP error with structured fields
 func (el *ErrorLogger) LogError(ctx context.Context, err error, level LogLevel, message string) {
 	if err == nil {
@@ -122,8 +131,8 @@ func (el *ErrorLogger) addMCPErrorFields(logger *logging.Logger, mcpErr *MCPErro
 	if len(mcpErr.Context) > 0 {
 		contextMap := make(map[string]interface{})
 		for k, v := range mcpErr.Context {
-			if el.sanitize && el.isSensitiveKey(k) {
-				contextMap[k] = "[REDACTED]"
+			if el.sanitize {
+				contextMap[k] = el.redactor.Value(k, v)
 			} else {
 				contextMap[k] = v
 			}
@@ -138,7 +147,11 @@ func (el *ErrorLogger) addMCPErrorFields(logger *logging.Logger, mcpErr *MCPErro
 
 	// Add cause chain if available
 	if mcpErr.Cause != nil {
-		logger = logger.WithField("cause", mcpErr.Cause.Error())
+		cause := mcpErr.Cause.Error()
+		if el.sanitize {
+			cause = el.redactor.String(cause)
+		}
+		logger = logger.WithField("cause", cause)
 
 		// Add full error chain in debug mode
 		if el.debugMode {
@@ -156,23 +169,6 @@ func (el *ErrorLogger) addMCPErrorFields(logger *logging.Logger, mcpErr *MCPErro
 	return logger
 }
 
-// isSensitiveKey checks if a context key contains sensitive information
-func (el *ErrorLogger) isSensitiveKey(key string) bool {
-	sensitiveKeys := []string{
-		"password", "token", "secret", "key", "auth", "credential",
-		"session", "cookie", "bearer", "api_key", "private",
-	}
-
-	keyLower := strings.ToLower(key)
-	for _, sensitive := range sensitiveKeys {
-		if strings.Contains(keyLower, sensitive) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // SanitizeError removes sensitive information from an error for production logging
 func (el *ErrorLogger) SanitizeError(err error) error {
 	if err == nil {
@@ -185,43 +181,18 @@ func (el *ErrorLogger) SanitizeError(err error) error {
 		return fmt.Errorf("internal error occurred")
 	}
 
-	// Create a sanitized copy
-	sanitized := &MCPError{
-		Code:      mcpErr.Code,
-		Message:   mcpErr.Message,
-		Data:      nil, // Remove data to prevent leaks
-		Category:  mcpErr.Category,
-		Context:   make(map[string]interface{}),
-		Sanitized: true,
-	}
-
-	// Copy only non-sensitive context
-	for k, v := range mcpErr.Context {
-		if !el.isSensitiveKey(k) {
-			sanitized.Context[k] = v
-		}
-	}
-
-	// Don't include debug info or cause in sanitized version
-	return sanitized
+	return mcpErr.SanitizeWith(el.redactor)
 }
 
 // LogWithRecovery logs an error and recovers from panics
 func (el *ErrorLogger) LogWithRecovery(ctx context.Context, operation string) {
 	if r := recover(); r != nil {
-		var err error
-		if e, ok := r.(error); ok {
-			err = e
-		} else {
-			err = fmt.Errorf("panic: %v", r)
-		}
-
 		// Create a system error for the panic
-		panicErr := NewSystemError(fmt.Sprintf("Panic during %s", operation), nil)
-		panicErr.Cause = err
+		panicErr := FromPanic(r)
+		panicErr.Message = fmt.Sprintf("Panic during %s", operation)
 		panicErr.WithContext("operation", operation)
 
-		if el.debugMode {
+		if el.debugMode && !DebugMode() {
 			// Add stack trace in debug mode
 			buf := make([]byte, 4096)
 			n := runtime.Stack(buf, false)
@@ -76,7 +76,7 @@ func TestErrorLogger_IsSensitiveKey(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.key, func(t *testing.T) {
-			result := errorLogger.isSensitiveKey(tt.key)
+			result := errorLogger.redactor.IsSensitiveKey(tt.key)
 			assert.Equal(t, tt.sensitive, result)
 		})
 	}
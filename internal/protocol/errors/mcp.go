@@ -2,22 +2,24 @@ package errors
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
 )
 
 // MCP-specific error codes in the reserved range (-32000 to -32099)
 // These extend the JSON-RPC error codes for MCP protocol-specific errors
 const (
 	// Protocol-level errors (-32000 to -32019)
-	ErrorCodeMCPProtocol         = -32000 // Generic MCP protocol error
-	ErrorCodeMCPVersionMismatch  = -32001 // Protocol version mismatch
-	ErrorCodeMCPCapabilityError  = -32002 // Capability negotiation error
-	ErrorCodeMCPInitializeError  = -32003 // Initialization sequence error
-	ErrorCodeMCPHandshakeTimeout = -32004 // Handshake timeout
-	ErrorCodeMCPInvalidState     = -32005 // Invalid protocol state
+	ErrorCodeMCPProtocol               = -32000 // Generic MCP protocol error
+	ErrorCodeMCPVersionMismatch        = -32001 // Protocol version mismatch
+	ErrorCodeMCPCapabilityError        = -32002 // Capability negotiation error
+	ErrorCodeMCPInitializeError        = -32003 // Initialization sequence error
+	ErrorCodeMCPHandshakeTimeout       = -32004 // Handshake timeout
+	ErrorCodeMCPInvalidState           = -32005 // Invalid protocol state
+	ErrorCodeMCPNotInitialized         = -32011 // Request before initialization
+	ErrorCodeMCPCapabilityNotSupported = -32012 // Capability not negotiated
 
 	// Transport-level errors (-32020 to -32039)
 	ErrorCodeMCPTransport        = -32020 // Generic transport error
@@ -159,12 +161,14 @@ func IsMCPError(code int) bool {
 // Error messages for MCP error codes
 var mcpErrorMessages = map[int]string{
 	// Protocol errors
-	ErrorCodeMCPProtocol:         "MCP protocol error",
-	ErrorCodeMCPVersionMismatch:  "Protocol version mismatch",
-	ErrorCodeMCPCapabilityError:  "Capability negotiation error",
-	ErrorCodeMCPInitializeError:  "Initialization sequence error",
-	ErrorCodeMCPHandshakeTimeout: "Handshake timeout",
-	ErrorCodeMCPInvalidState:     "Invalid protocol state",
+	ErrorCodeMCPProtocol:               "MCP protocol error",
+	ErrorCodeMCPVersionMismatch:        "Protocol version mismatch",
+	ErrorCodeMCPCapabilityError:        "Capability negotiation error",
+	ErrorCodeMCPInitializeError:        "Initialization sequence error",
+	ErrorCodeMCPHandshakeTimeout:       "Handshake timeout",
+	ErrorCodeMCPInvalidState:           "Invalid protocol state",
+	ErrorCodeMCPNotInitialized:         "Server not initialized",
+	ErrorCodeMCPCapabilityNotSupported: "Capability not supported",
 
 	// Transport errors
 	ErrorCodeMCPTransport:        "Transport error",
@@ -208,13 +212,20 @@ func GetMCPErrorMessage(code int) string {
 
 // Sanitize removes sensitive information from the error for production use
 func (e *MCPError) Sanitize() *MCPError {
+	return e.SanitizeWith(redact.Default())
+}
+
+// SanitizeWith removes sensitive information using the given redactor.
+// Context entries under sensitive keys are dropped and remaining values are
+// scrubbed of embedded secrets.
+func (e *MCPError) SanitizeWith(r *redact.Redactor) *MCPError {
 	if e.Sanitized {
 		return e // Already sanitized
 	}
 
 	sanitized := &MCPError{
 		Code:      e.Code,
-		Message:   e.Message,
+		Message:   r.String(e.Message),
 		Data:      nil, // Remove data to prevent leaks
 		Category:  e.Category,
 		Context:   make(map[string]interface{}),
@@ -222,23 +233,9 @@ func (e *MCPError) Sanitize() *MCPError {
 	}
 
 	// Copy only non-sensitive context
-	sensitiveKeys := []string{
-		"password", "token", "secret", "auth", "credential",
-		"session", "cookie", "bearer", "api_key", "private",
-		"access_key", "secret_key", "private_key", "public_key",
-	}
-
 	for k, v := range e.Context {
-		isSensitive := false
-		for _, sensitive := range sensitiveKeys {
-			if strings.Contains(strings.ToLower(k), sensitive) {
-				isSensitive = true
-				break
-			}
-		}
-
-		if !isSensitive {
-			sanitized.Context[k] = v
+		if !r.IsSensitiveKey(k) {
+			sanitized.Context[k] = r.Value(k, v)
 		}
 	}
 
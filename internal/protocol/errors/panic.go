@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+// Panic classifications reported by FromPanic
+const (
+	PanicNilPointer    = "nil_pointer"
+	PanicIndexRange    = "index_out_of_range"
+	PanicNilMap        = "nil_map"
+	PanicTypeAssertion = "type_assertion"
+	PanicDivideByZero  = "divide_by_zero"
+	PanicClosedChannel = "closed_channel"
+	PanicRuntime       = "runtime"
+	PanicError         = "error"
+	PanicValue         = "value"
+)
+
+// PanicTypeKey holds the panic classification in error data and context
+const PanicTypeKey = "panic_type"
+
+// debugMode controls whether FromPanic captures stack traces
+var debugMode atomic.Bool
+
+// SetDebugMode enables capturing debug-only details such as panic stacks
+func SetDebugMode(enabled bool) {
+	debugMode.Store(enabled)
+}
+
+// DebugMode reports whether debug-only details are captured
+func DebugMode() bool {
+	return debugMode.Load()
+}
+
+// FromPanic converts a value returned by recover() into a system MCPError.
+// The panic is classified into the error data so clients can tell crashes
+// apart without seeing internals; the panic value and stack trace are added
+// to debug info only in debug mode. It returns nil for a nil value.
+func FromPanic(recovered any) *MCPError {
+	if recovered == nil {
+		return nil
+	}
+
+	kind := classifyPanic(recovered)
+	mcpErr := NewMCPError(ErrorCodeMCPSystem, "Panic recovered", map[string]interface{}{
+		PanicTypeKey: kind,
+	})
+	mcpErr.WithContext(PanicTypeKey, kind)
+
+	if err, ok := recovered.(error); ok {
+		mcpErr.Cause = err
+	} else {
+		mcpErr.Cause = fmt.Errorf("panic: %v", recovered)
+	}
+
+	if DebugMode() {
+		mcpErr.WithDebugInfo("panic_value", fmt.Sprint(recovered))
+		mcpErr.WithDebugInfo("stack_trace", string(debug.Stack()))
+	}
+
+	return mcpErr
+}
+
+// classifyPanic names the kind of a recovered panic value
+func classifyPanic(recovered any) string {
+	var rtErr runtime.Error
+	if err, ok := recovered.(error); ok && errors.As(err, &rtErr) {
+		var typeErr *runtime.TypeAssertionError
+		if errors.As(err, &typeErr) {
+			return PanicTypeAssertion
+		}
+
+		msg := rtErr.Error()
+		switch {
+		case strings.Contains(msg, "nil pointer dereference"):
+			return PanicNilPointer
+		case strings.Contains(msg, "index out of range"), strings.Contains(msg, "slice bounds out of range"):
+			return PanicIndexRange
+		case strings.Contains(msg, "assignment to entry in nil map"):
+			return PanicNilMap
+		case strings.Contains(msg, "divide by zero"):
+			return PanicDivideByZero
+		case strings.Contains(msg, "closed channel"):
+			return PanicClosedChannel
+		default:
+			return PanicRuntime
+		}
+	}
+
+	if _, ok := recovered.(error); ok {
+		return PanicError
+	}
+	return PanicValue
+}
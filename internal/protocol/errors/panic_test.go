@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverFrom runs fn and returns the converted panic
+func recoverFrom(fn func()) (mcpErr *MCPError) {
+	defer func() {
+		mcpErr = FromPanic(recover())
+	}()
+	fn()
+	return nil
+}
+
+func TestFromPanic_Classification(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+		kind string
+	}{
+		{"nil pointer", func() {
+			var p *MCPError
+			_ = p.Code
+		}, PanicNilPointer},
+		{"index", func() {
+			s := []int{}
+			i := 3
+			_ = s[i]
+		}, PanicIndexRange},
+		{"nil map", func() {
+			var m map[string]int
+			m["x"] = 1
+		}, PanicNilMap},
+		{"type assertion", func() {
+			var v any = "x"
+			_ = v.(int)
+		}, PanicTypeAssertion},
+		{"divide by zero", func() {
+			zero := 0
+			_ = 1 / zero
+		}, PanicDivideByZero},
+		{"closed channel", func() {
+			ch := make(chan int)
+			close(ch)
+			close(ch)
+		}, PanicClosedChannel},
+		{"error", func() { panic(errors.New("boom")) }, PanicError},
+		{"value", func() { panic("boom") }, PanicValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpErr := recoverFrom(tt.fn)
+			require.NotNil(t, mcpErr)
+			assert.Equal(t, ErrorCodeMCPSystem, mcpErr.Code)
+			assert.Equal(t, tt.kind, mcpErr.Data.(map[string]interface{})[PanicTypeKey])
+			assert.Equal(t, tt.kind, mcpErr.Context[PanicTypeKey])
+			assert.Error(t, mcpErr.Cause)
+		})
+	}
+}
+
+func TestFromPanic_DebugInfo(t *testing.T) {
+	assert.Nil(t, FromPanic(nil))
+
+	cause := errors.New("boom")
+	mcpErr := FromPanic(cause)
+	assert.Same(t, cause, mcpErr.Cause)
+	assert.Empty(t, mcpErr.DebugInfo)
+
+	SetDebugMode(true)
+	defer SetDebugMode(false)
+
+	mcpErr = FromPanic("boom")
+	assert.Equal(t, "boom", mcpErr.DebugInfo["panic_value"])
+	assert.Contains(t, mcpErr.DebugInfo["stack_trace"], "TestFromPanic_DebugInfo")
+	assert.NotContains(t, mcpErr.ToJSONRPCError().Data, "stack_trace")
+}
@@ -0,0 +1,306 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// CategoryAll is the budget key covering errors of every category
+const CategoryAll = "all"
+
+// Default recorder settings
+const (
+	DefaultRecorderWindow  = time.Minute
+	DefaultRecorderBuckets = 60
+)
+
+// Budget is the tolerated error rate for a category over the window
+type Budget struct {
+	// MaxRate is the highest tolerated fraction of failed requests (0-1)
+	MaxRate float64
+
+	// MinRequests avoids firing on tiny samples
+	MinRequests int64
+}
+
+// BudgetEvent describes an exceeded error budget
+type BudgetEvent struct {
+	Category string
+	Rate     float64
+	Budget   Budget
+	Requests int64
+	Errors   int64
+}
+
+// RecorderConfig contains configuration for a Recorder
+type RecorderConfig struct {
+	// Window is the sliding window length (defaults to DefaultRecorderWindow)
+	Window time.Duration
+
+	// Buckets is the window resolution (defaults to DefaultRecorderBuckets)
+	Buckets int
+
+	// Budgets maps categories (or CategoryAll) to error budgets
+	Budgets map[string]Budget
+
+	// OnBudgetExceeded is called once each time a budget is breached. It is
+	// called again only after the rate has recovered and been breached anew.
+	OnBudgetExceeded func(BudgetEvent)
+
+	// Now overrides the clock (for tests)
+	Now func() time.Time
+}
+
+// RecorderSnapshot summarizes the current window
+type RecorderSnapshot struct {
+	Window     time.Duration      `json:"window"`
+	Requests   int64              `json:"requests"`
+	Errors     int64              `json:"errors"`
+	ByCode     map[int]int64      `json:"byCode"`
+	ByCategory map[string]int64   `json:"byCategory"`
+	Rates      map[string]float64 `json:"rates"`
+}
+
+// bucket holds counts for one slice of the window
+type bucket struct {
+	epoch    int64
+	requests int64
+	codes    map[int]int64
+}
+
+// Recorder counts MCP errors by code and category over a sliding window
+// and reports when error budgets are exceeded
+type Recorder struct {
+	config  RecorderConfig
+	width   time.Duration
+	mu      sync.Mutex
+	buckets []bucket
+	firing  map[string]bool
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder(config RecorderConfig) *Recorder {
+	if config.Window <= 0 {
+		config.Window = DefaultRecorderWindow
+	}
+	if config.Buckets <= 0 {
+		config.Buckets = DefaultRecorderBuckets
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	width := config.Window / time.Duration(config.Buckets)
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+
+	return &Recorder{
+		config:  config,
+		width:   width,
+		buckets: make([]bucket, config.Buckets),
+		firing:  make(map[string]bool),
+	}
+}
+
+// RecordSuccess records a request that completed without error
+func (r *Recorder) RecordSuccess() {
+	r.record(0, false)
+}
+
+// RecordCode records a request that failed with code
+func (r *Recorder) RecordCode(code int) {
+	r.record(code, true)
+}
+
+// Record records the outcome of a request. A nil error is a success; other
+// errors are classified by the MCP or JSON-RPC error in their chain and
+// default to ErrorCodeMCPSystem.
+func (r *Recorder) Record(err error) {
+	if err == nil {
+		r.RecordSuccess()
+		return
+	}
+	r.RecordCode(codeOf(err))
+}
+
+// RecordResponse records the outcome of a JSON-RPC response
+func (r *Recorder) RecordResponse(resp *jsonrpc.Response) {
+	if resp == nil || resp.Error == nil {
+		r.RecordSuccess()
+		return
+	}
+	r.RecordCode(resp.Error.Code)
+}
+
+// RegisterHooks records the outcome of every request answered by an
+// mcp-go server
+func (r *Recorder) RegisterHooks(hooks *server.Hooks) {
+	hooks.AddOnSuccess(func(ctx context.Context, id any, method mcp.MCPMethod, message any, result any) {
+		r.RecordSuccess()
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		r.Record(err)
+	})
+}
+
+// ErrorRate returns the fraction of requests in the window that failed
+// with an error of category (or any error for CategoryAll)
+func (r *Recorder) ErrorRate(category string) float64 {
+	snapshot := r.Snapshot()
+	return snapshot.Rates[category]
+}
+
+// Exceeded reports whether the budget for category is currently exceeded
+func (r *Recorder) Exceeded(category string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.firing[category]
+}
+
+// Exceeding returns the budgets exceeded over the current window, ordered
+// by category. Unlike Exceeded it notices a recovered rate without waiting
+// for the next request.
+func (r *Recorder) Exceeding() []BudgetEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.snapshot(r.epoch())
+	var events []BudgetEvent
+	for category, budget := range r.config.Budgets {
+		if event, exceeded := breach(s, category, budget); exceeded {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Category < events[j].Category })
+	return events
+}
+
+// Snapshot returns the counts for the current window
+func (r *Recorder) Snapshot() RecorderSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot(r.epoch())
+}
+
+// Reset clears all recorded data
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buckets = make([]bucket, len(r.buckets))
+	r.firing = make(map[string]bool)
+}
+
+// record adds one request to the current bucket and evaluates budgets
+func (r *Recorder) record(code int, failed bool) {
+	r.mu.Lock()
+
+	epoch := r.epoch()
+	b := &r.buckets[epoch%int64(len(r.buckets))]
+	if b.epoch != epoch {
+		*b = bucket{epoch: epoch}
+	}
+	b.requests++
+	if failed {
+		if b.codes == nil {
+			b.codes = make(map[int]int64)
+		}
+		b.codes[code]++
+	}
+
+	events := r.evaluate(r.snapshot(epoch))
+	r.mu.Unlock()
+
+	if r.config.OnBudgetExceeded != nil {
+		for _, event := range events {
+			r.config.OnBudgetExceeded(event)
+		}
+	}
+}
+
+// epoch returns the index of the current bucket
+func (r *Recorder) epoch() int64 {
+	return r.config.Now().UnixNano() / int64(r.width)
+}
+
+// snapshot aggregates buckets within the window ending at epoch
+func (r *Recorder) snapshot(epoch int64) RecorderSnapshot {
+	s := RecorderSnapshot{
+		Window:     r.config.Window,
+		ByCode:     make(map[int]int64),
+		ByCategory: make(map[string]int64),
+		Rates:      make(map[string]float64),
+	}
+
+	oldest := epoch - int64(len(r.buckets)) + 1
+	for i := range r.buckets {
+		b := &r.buckets[i]
+		if b.epoch < oldest || b.epoch > epoch {
+			continue
+		}
+		s.Requests += b.requests
+		for code, n := range b.codes {
+			s.Errors += n
+			s.ByCode[code] += n
+			s.ByCategory[GetCategory(code)] += n
+		}
+	}
+
+	if s.Requests > 0 {
+		s.Rates[CategoryAll] = float64(s.Errors) / float64(s.Requests)
+		for category, n := range s.ByCategory {
+			s.Rates[category] = float64(n) / float64(s.Requests)
+		}
+	}
+	return s
+}
+
+// evaluate updates budget state and returns newly exceeded budgets
+func (r *Recorder) evaluate(s RecorderSnapshot) []BudgetEvent {
+	var events []BudgetEvent
+	for category, budget := range r.config.Budgets {
+		event, exceeded := breach(s, category, budget)
+		if exceeded && !r.firing[category] {
+			events = append(events, event)
+		}
+		r.firing[category] = exceeded
+	}
+	return events
+}
+
+// breach reports whether budget for category is exceeded in s
+func breach(s RecorderSnapshot, category string, budget Budget) (BudgetEvent, bool) {
+	rate := s.Rates[category]
+	errs := s.ByCategory[category]
+	if category == CategoryAll {
+		errs = s.Errors
+	}
+	event := BudgetEvent{
+		Category: category,
+		Rate:     rate,
+		Budget:   budget,
+		Requests: s.Requests,
+		Errors:   errs,
+	}
+	return event, s.Requests >= budget.MinRequests && rate > budget.MaxRate
+}
+
+// codeOf returns the error code carried by err
+func codeOf(err error) int {
+	if mcpErr := FindMCPError(err); mcpErr != nil {
+		return mcpErr.Code
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	return ErrorCodeMCPSystem
+}
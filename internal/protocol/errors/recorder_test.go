@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestRecorder_SlidingWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := NewRecorder(RecorderConfig{
+		Window:  10 * time.Second,
+		Buckets: 10,
+		Now:     func() time.Time { return now },
+	})
+
+	r.RecordSuccess()
+	r.Record(NewToolNotFoundError("echo"))
+	r.Record(errors.New("boom"))
+	r.RecordResponse(jsonrpc.NewErrorResponse(&jsonrpc.Error{Code: ErrorCodeMCPConnectionLost}, 1))
+
+	s := r.Snapshot()
+	assert.Equal(t, int64(4), s.Requests)
+	assert.Equal(t, int64(3), s.Errors)
+	assert.Equal(t, int64(1), s.ByCode[ErrorCodeMCPToolNotFound])
+	assert.Equal(t, int64(1), s.ByCode[ErrorCodeMCPSystem])
+	assert.Equal(t, int64(1), s.ByCategory["transport"])
+	assert.InDelta(t, 0.75, r.ErrorRate(CategoryAll), 1e-9)
+	assert.InDelta(t, 0.25, r.ErrorRate("handler"), 1e-9)
+
+	// Half the window later the old bucket still counts
+	now = now.Add(5 * time.Second)
+	r.RecordSuccess()
+	assert.Equal(t, int64(5), r.Snapshot().Requests)
+
+	// Once it slides out only the recent request remains
+	now = now.Add(6 * time.Second)
+	s = r.Snapshot()
+	assert.Equal(t, int64(1), s.Requests)
+	assert.Equal(t, int64(0), s.Errors)
+}
+
+func TestRecorder_Budget(t *testing.T) {
+	var events []BudgetEvent
+	r := NewRecorder(RecorderConfig{
+		Budgets: map[string]Budget{
+			"transport": {MaxRate: 0.5, MinRequests: 4},
+		},
+		OnBudgetExceeded: func(e BudgetEvent) { events = append(events, e) },
+	})
+
+	// Below MinRequests the budget is not evaluated
+	r.RecordCode(ErrorCodeMCPConnectionLost)
+	r.RecordCode(ErrorCodeMCPConnectionLost)
+	r.RecordCode(ErrorCodeMCPConnectionLost)
+	assert.Empty(t, events)
+
+	r.RecordCode(ErrorCodeMCPConnectionLost)
+	require.Len(t, events, 1)
+	assert.Equal(t, "transport", events[0].Category)
+	assert.Equal(t, int64(4), events[0].Errors)
+	assert.True(t, r.Exceeded("transport"))
+
+	// Still breached: no duplicate event
+	r.RecordCode(ErrorCodeMCPConnectionLost)
+	assert.Len(t, events, 1)
+
+	// Recover, then breach again
+	for i := 0; i < 6; i++ {
+		r.RecordSuccess()
+	}
+	assert.False(t, r.Exceeded("transport"))
+	for i := 0; i < 4; i++ {
+		r.RecordCode(ErrorCodeMCPTransportTimeout)
+	}
+	assert.Len(t, events, 2)
+}
+
+func TestRecorder_Exceeding(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := NewRecorder(RecorderConfig{
+		Window:  10 * time.Second,
+		Buckets: 10,
+		Budgets: map[string]Budget{
+			CategoryAll: {MaxRate: 0.5, MinRequests: 2},
+			"transport": {MaxRate: 0.5, MinRequests: 2},
+		},
+		Now: func() time.Time { return now },
+	})
+
+	r.RecordCode(ErrorCodeMCPToolNotFound)
+	r.RecordCode(ErrorCodeMCPToolNotFound)
+	exceeded := r.Exceeding()
+	require.Len(t, exceeded, 1)
+	assert.Equal(t, CategoryAll, exceeded[0].Category)
+	assert.Equal(t, int64(2), exceeded[0].Errors)
+
+	// The errors slide out of the window without another request
+	now = now.Add(11 * time.Second)
+	assert.Empty(t, r.Exceeding())
+	assert.True(t, r.Exceeded(CategoryAll))
+}
@@ -0,0 +1,211 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// CatalogMethod is the admin method listing registered error codes
+const CatalogMethod = "errors/catalog"
+
+// CodeInfo describes a registered error code
+type CodeInfo struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Owner       string `json:"owner"`
+}
+
+// CodeCollisionError is returned when a code is registered twice
+type CodeCollisionError struct {
+	Existing CodeInfo
+	New      CodeInfo
+}
+
+// Error implements the error interface
+func (e *CodeCollisionError) Error() string {
+	return fmt.Sprintf("error code %d (%s, %s) collides with %s registered by %s",
+		e.New.Code, e.New.Name, e.New.Owner, e.Existing.Name, e.Existing.Owner)
+}
+
+// Registry tracks error codes used across packages and rejects collisions
+type Registry struct {
+	mu    sync.RWMutex
+	codes map[int]CodeInfo
+	names map[string]int
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		codes: make(map[int]CodeInfo),
+		names: make(map[string]int),
+	}
+}
+
+// Register adds info to the registry. Registering a code or name that is
+// already taken returns a *CodeCollisionError.
+func (r *Registry) Register(info CodeInfo) error {
+	if info.Name == "" {
+		return fmt.Errorf("error code %d registered without a name", info.Code)
+	}
+	if info.Category == "" {
+		info.Category = GetCategory(info.Code)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.codes[info.Code]; ok {
+		return &CodeCollisionError{Existing: existing, New: info}
+	}
+	if code, ok := r.names[info.Name]; ok {
+		return &CodeCollisionError{Existing: r.codes[code], New: info}
+	}
+
+	r.codes[info.Code] = info
+	r.names[info.Name] = info.Code
+	return nil
+}
+
+// MustRegister registers info and panics on collision. It is intended for
+// package init functions so collisions fail fast at startup.
+func (r *Registry) MustRegister(info CodeInfo) {
+	if err := r.Register(info); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the registration for code
+func (r *Registry) Lookup(code int) (CodeInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.codes[code]
+	return info, ok
+}
+
+// Catalog returns every registered code, ordered from -32000 downwards
+func (r *Registry) Catalog() []CodeInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	catalog := make([]CodeInfo, 0, len(r.codes))
+	for _, info := range r.codes {
+		catalog = append(catalog, info)
+	}
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].Code > catalog[j].Code })
+	return catalog
+}
+
+// Reference renders the catalog as a Markdown table for client developers
+func (r *Registry) Reference() string {
+	var b strings.Builder
+	b.WriteString("| Code | Name | Category | Description | Owner |\n")
+	b.WriteString("|------|------|----------|-------------|-------|\n")
+	for _, info := range r.Catalog() {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n",
+			info.Code, info.Name, info.Category, info.Description, info.Owner)
+	}
+	return b.String()
+}
+
+// Handler returns a JSON-RPC handler for the errors/catalog method. Its
+// signature matches router.HandlerFunc.
+func (r *Registry) Handler() func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+	return func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(map[string]interface{}{"codes": r.Catalog()}, req.ID)
+	}
+}
+
+// Global registry holding the codes of every package
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the global error code registry
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// RegisterCode registers a code in the global registry
+func RegisterCode(code int, name, description, owner string) error {
+	return defaultRegistry.Register(CodeInfo{Code: code, Name: name, Description: description, Owner: owner})
+}
+
+// MustRegisterCode registers a code in the global registry, panicking on
+// collision
+func MustRegisterCode(code int, name, description, owner string) {
+	defaultRegistry.MustRegister(CodeInfo{Code: code, Name: name, Description: description, Owner: owner})
+}
+
+// errorCodeNames names the codes defined by this package
+var errorCodeNames = map[int]string{
+	jsonrpc.ErrorCodeParse:          "ParseError",
+	jsonrpc.ErrorCodeInvalidRequest: "InvalidRequest",
+	jsonrpc.ErrorCodeMethodNotFound: "MethodNotFound",
+	jsonrpc.ErrorCodeInvalidParams:  "InvalidParams",
+	jsonrpc.ErrorCodeInternal:       "InternalError",
+
+	ErrorCodeMCPProtocol:               "Protocol",
+	ErrorCodeMCPVersionMismatch:        "VersionMismatch",
+	ErrorCodeMCPCapabilityError:        "CapabilityError",
+	ErrorCodeMCPInitializeError:        "InitializeError",
+	ErrorCodeMCPHandshakeTimeout:       "HandshakeTimeout",
+	ErrorCodeMCPInvalidState:           "InvalidState",
+	ErrorCodeMCPNotInitialized:         "NotInitialized",
+	ErrorCodeMCPCapabilityNotSupported: "CapabilityNotSupported",
+
+	ErrorCodeMCPTransport:        "Transport",
+	ErrorCodeMCPConnectionLost:   "ConnectionLost",
+	ErrorCodeMCPConnectionFailed: "ConnectionFailed",
+	ErrorCodeMCPTransportTimeout: "TransportTimeout",
+	ErrorCodeMCPMessageTooLarge:  "MessageTooLarge",
+	ErrorCodeMCPEncodingError:    "EncodingError",
+
+	ErrorCodeMCPHandler:          "Handler",
+	ErrorCodeMCPToolNotFound:     "ToolNotFound",
+	ErrorCodeMCPToolError:        "ToolError",
+	ErrorCodeMCPResourceNotFound: "ResourceNotFound",
+	ErrorCodeMCPResourceError:    "ResourceError",
+	ErrorCodeMCPPromptNotFound:   "PromptNotFound",
+	ErrorCodeMCPPromptError:      "PromptError",
+
+	ErrorCodeMCPSecurity:      "Security",
+	ErrorCodeMCPUnauthorized:  "Unauthorized",
+	ErrorCodeMCPForbidden:     "Forbidden",
+	ErrorCodeMCPRateLimit:     "RateLimit",
+	ErrorCodeMCPQuotaExceeded: "QuotaExceeded",
+
+	ErrorCodeMCPSystem:         "System",
+	ErrorCodeMCPResourceLimit:  "ResourceLimit",
+	ErrorCodeMCPMemoryLimit:    "MemoryLimit",
+	ErrorCodeMCPDiskSpace:      "DiskSpace",
+	ErrorCodeMCPServiceUnavail: "ServiceUnavailable",
+}
+
+// The standard JSON-RPC codes are registered here because the jsonrpc
+// package cannot import this one. Its server-range codes (-32001 to -32010)
+// predate the MCP taxonomy and overlap it, so they are deliberately left
+// unregistered; new code should use the MCP codes. The mcp package's
+// codes alias these, so registering them covers that package too.
+func init() {
+	for code, name := range errorCodeNames {
+		description := GetMCPErrorMessage(code)
+		category := GetCategory(code)
+		if !IsMCPError(code) {
+			description = jsonrpc.NewStandardError(code, nil).Message
+			category = "jsonrpc"
+		}
+		defaultRegistry.MustRegister(CodeInfo{
+			Code:        code,
+			Name:        name,
+			Description: description,
+			Category:    category,
+			Owner:       "errors",
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestRegistry_Collisions(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(CodeInfo{Code: -32090, Name: "UpstreamDown", Owner: "proxy"}))
+
+	err := r.Register(CodeInfo{Code: -32090, Name: "CacheMiss", Owner: "cache"})
+	var collision *CodeCollisionError
+	require.True(t, errors.As(err, &collision))
+	assert.Equal(t, "proxy", collision.Existing.Owner)
+	assert.Contains(t, err.Error(), "-32090")
+
+	assert.Error(t, r.Register(CodeInfo{Code: -32091, Name: "UpstreamDown", Owner: "cache"}))
+	assert.Error(t, r.Register(CodeInfo{Code: -32092}))
+	assert.Panics(t, func() { r.MustRegister(CodeInfo{Code: -32090, Name: "Other"}) })
+
+	info, ok := r.Lookup(-32090)
+	require.True(t, ok)
+	assert.Equal(t, "system", info.Category)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	r := DefaultRegistry()
+
+	// Every code defined by this package is registered
+	for code := range mcpErrorMessages {
+		_, ok := r.Lookup(code)
+		assert.True(t, ok, "code %d not registered", code)
+	}
+
+	info, ok := r.Lookup(jsonrpc.ErrorCodeParse)
+	require.True(t, ok)
+	assert.Equal(t, "jsonrpc", info.Category)
+	assert.Equal(t, "Parse error", info.Description)
+
+	catalog := r.Catalog()
+	assert.Equal(t, ErrorCodeMCPProtocol, catalog[0].Code)
+	assert.True(t, strings.Contains(r.Reference(), "| -32041 | ToolNotFound | handler | Tool not found | errors |"))
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(CodeInfo{Code: -32090, Name: "UpstreamDown"})
+
+	resp := r.Handler()(context.Background(), &jsonrpc.Request{ID: 1, Method: CatalogMethod})
+	require.Nil(t, resp.Error)
+	codes := resp.Result.(map[string]interface{})["codes"].([]CodeInfo)
+	assert.Len(t, codes, 1)
+}
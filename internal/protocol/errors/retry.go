@@ -0,0 +1,168 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// RetryPolicy controls how Retry repeats a failing operation. Delays grow
+// exponentially from InitialInterval by Multiplier up to MaxInterval, each
+// randomized by ±Jitter.
+type RetryPolicy struct {
+	// MaxAttempts bounds the number of calls, including the first (0 means
+	// no limit other than MaxElapsedTime)
+	MaxAttempts int
+
+	// InitialInterval is the delay before the first retry
+	InitialInterval time.Duration
+
+	// MaxInterval caps the delay between attempts
+	MaxInterval time.Duration
+
+	// Multiplier scales the delay after each attempt
+	Multiplier float64
+
+	// Jitter is the randomization factor applied to each delay (0-1)
+	Jitter float64
+
+	// MaxElapsedTime stops retrying once exceeded (0 means no limit)
+	MaxElapsedTime time.Duration
+
+	// Retryable decides whether an error is retried (defaults to IsRetryable)
+	Retryable func(error) bool
+
+	// Categories overrides the policy for errors of a category. Zero fields
+	// of an override inherit from this policy.
+	Categories map[string]RetryPolicy
+
+	// OnRetry is called before sleeping ahead of each retry
+	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// Clock times the delays (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// DefaultRetryPolicy returns the policy used for upstream calls and
+// transports
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     5,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxElapsedTime:  time.Minute,
+		Categories: map[string]RetryPolicy{
+			// Rate limits clear slowly; back off harder
+			"security": {InitialInterval: time.Second, Multiplier: 3},
+		},
+	}
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, the
+// policy is exhausted or ctx is done. It returns the last error from fn, or
+// ctx.Err() if the context ended first.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	policy = policy.withDefaults()
+	start := policy.Clock.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !policy.Retryable(err) {
+			return err
+		}
+
+		effective := policy.forError(err)
+		if effective.MaxAttempts > 0 && attempt >= effective.MaxAttempts {
+			return err
+		}
+
+		delay := effective.delay(attempt)
+		if effective.MaxElapsedTime > 0 && policy.Clock.Since(start)+delay > effective.MaxElapsedTime {
+			return err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, delay)
+		}
+
+		timer := policy.Clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// withDefaults fills unset fields
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = 100 * time.Millisecond
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = 10 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = 0
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	p.Clock = clock.Or(p.Clock)
+	return p
+}
+
+// forError returns the policy after applying the category override for err
+func (p RetryPolicy) forError(err error) RetryPolicy {
+	mcpErr := FindMCPError(err)
+	if mcpErr == nil {
+		return p
+	}
+	override, ok := p.Categories[GetCategory(mcpErr.Code)]
+	if !ok {
+		return p
+	}
+
+	if override.MaxAttempts > 0 {
+		p.MaxAttempts = override.MaxAttempts
+	}
+	if override.InitialInterval > 0 {
+		p.InitialInterval = override.InitialInterval
+	}
+	if override.MaxInterval > 0 {
+		p.MaxInterval = override.MaxInterval
+	}
+	if override.Multiplier >= 1 {
+		p.Multiplier = override.Multiplier
+	}
+	if override.Jitter > 0 && override.Jitter <= 1 {
+		p.Jitter = override.Jitter
+	}
+	if override.MaxElapsedTime > 0 {
+		p.MaxElapsedTime = override.MaxElapsedTime
+	}
+	return p
+}
+
+// delay returns the randomized backoff before retry number attempt
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	backoff := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt-1))
+	if backoff > float64(p.MaxInterval) {
+		backoff = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
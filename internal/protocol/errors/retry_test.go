@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+func fastPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     4,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+	}
+}
+
+func TestRetry_SucceedsAfterRetryableErrors(t *testing.T) {
+	calls := 0
+	var delays []time.Duration
+	policy := fastPolicy()
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) { delays = append(delays, delay) }
+
+	err := Retry(context.Background(), policy, func() error {
+		calls++
+		if calls < 3 {
+			return NewConnectionLostError("reset")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, delays)
+}
+
+func TestRetry_StopsOnNonRetryable(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy(), func() error {
+		calls++
+		return NewToolNotFoundError("echo")
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, ErrorCodeMCPToolNotFound, FindMCPError(err).Code)
+}
+
+func TestRetry_Exhaustion(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy(), func() error {
+		calls++
+		return NewConnectionLostError("reset")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 4, calls)
+
+	// MaxElapsedTime stops before the attempts run out: the second delay
+	// (40s) would end after it (30s)
+	calls = 0
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	policy := fastPolicy()
+	policy.MaxAttempts = 0
+	policy.InitialInterval = 20 * time.Second
+	policy.MaxInterval = time.Minute
+	policy.MaxElapsedTime = 30 * time.Second
+	policy.Clock = fake
+	go func() {
+		fake.BlockUntil(context.Background(), 1)
+		fake.Advance(20 * time.Second)
+	}()
+	Retry(context.Background(), policy, func() error {
+		calls++
+		return NewConnectionLostError("reset")
+	})
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_CategoryOverride(t *testing.T) {
+	policy := fastPolicy()
+	policy.Categories = map[string]RetryPolicy{"security": {MaxAttempts: 2}}
+
+	calls := 0
+	Retry(context.Background(), policy, func() error {
+		calls++
+		return NewRateLimitError(10, "1m")
+	})
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := fastPolicy()
+	policy.InitialInterval = time.Hour
+	policy.MaxInterval = time.Hour
+	policy.Retryable = func(error) bool { return true }
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+
+	err := Retry(ctx, policy, func() error { return errors.New("boom") })
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,161 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// TranslationRule maps matching upstream errors to a local error code
+type TranslationRule struct {
+	// Upstream restricts the rule to one upstream server (empty matches all)
+	Upstream string `yaml:"upstream,omitempty" json:"upstream,omitempty"`
+
+	// Codes matches upstream error codes (empty matches all)
+	Codes []int `yaml:"codes,omitempty" json:"codes,omitempty"`
+
+	// MessagePattern is a regular expression matched against the upstream
+	// message (empty matches all)
+	MessagePattern string `yaml:"messagePattern,omitempty" json:"messagePattern,omitempty"`
+
+	// To is the local error code
+	To int `yaml:"to" json:"to"`
+
+	// Message replaces the upstream message (defaults to the standard
+	// message for To)
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// TranslationConfig contains configuration for a Translator
+type TranslationConfig struct {
+	// Rules are evaluated in order; the first match wins
+	Rules []TranslationRule `yaml:"rules" json:"rules"`
+
+	// DefaultCode applies to unmatched errors (defaults to
+	// ErrorCodeMCPHandler)
+	DefaultCode int `yaml:"defaultCode,omitempty" json:"defaultCode,omitempty"`
+}
+
+// DefaultTranslationRules maps codes used by the reference MCP SDKs onto
+// this package's taxonomy. Standard JSON-RPC codes are kept as-is.
+func DefaultTranslationRules() []TranslationRule {
+	return []TranslationRule{
+		{Codes: []int{jsonrpc.ErrorCodeParse, jsonrpc.ErrorCodeInvalidRequest,
+			jsonrpc.ErrorCodeMethodNotFound, jsonrpc.ErrorCodeInvalidParams, jsonrpc.ErrorCodeInternal}},
+		{Codes: []int{-32000}, MessagePattern: `(?i)connection closed`, To: ErrorCodeMCPConnectionLost},
+		{Codes: []int{-32001}, MessagePattern: `(?i)timed? ?out`, To: ErrorCodeMCPTransportTimeout},
+		{Codes: []int{-32002}, MessagePattern: `(?i)resource`, To: ErrorCodeMCPResourceNotFound},
+		{MessagePattern: `(?i)unknown tool|tool .*not found`, To: ErrorCodeMCPToolNotFound},
+		{MessagePattern: `(?i)rate limit|too many requests`, To: ErrorCodeMCPRateLimit},
+		{MessagePattern: `(?i)unauthori[sz]ed`, To: ErrorCodeMCPUnauthorized},
+	}
+}
+
+// Translator normalizes errors returned by upstream MCP servers into this
+// package's taxonomy. Upstream codes are foreign: the same number may mean
+// something different to each server.
+type Translator struct {
+	rules       []TranslationRule
+	defaultCode int
+}
+
+// NewTranslator creates a translator, compiling rule patterns
+func NewTranslator(config TranslationConfig) (*Translator, error) {
+	if config.DefaultCode == 0 {
+		config.DefaultCode = ErrorCodeMCPHandler
+	}
+
+	rules := make([]TranslationRule, len(config.Rules))
+	for i, rule := range config.Rules {
+		if rule.MessagePattern != "" {
+			pattern, err := regexp.Compile(rule.MessagePattern)
+			if err != nil {
+				return nil, fmt.Errorf("translation rule %d: invalid message pattern: %w", i, err)
+			}
+			rule.pattern = pattern
+		}
+		rules[i] = rule
+	}
+
+	return &Translator{rules: rules, defaultCode: config.DefaultCode}, nil
+}
+
+// Translate converts an upstream JSON-RPC error into an MCPError. The
+// upstream error is kept as the cause and in debug info; a rule with a zero
+// To keeps the upstream code.
+func (t *Translator) Translate(upstream string, upstreamErr *jsonrpc.Error) *MCPError {
+	if upstreamErr == nil {
+		return nil
+	}
+
+	code, message := t.defaultCode, ""
+	if rule, ok := t.match(upstream, upstreamErr); ok {
+		code, message = rule.To, rule.Message
+		if code == 0 {
+			code, message = upstreamErr.Code, upstreamErr.Message
+		}
+	}
+	if message == "" {
+		message = GetMCPErrorMessage(code)
+		if !IsMCPError(code) {
+			message = upstreamErr.Message
+		}
+	}
+
+	mcpErr := WrapError(upstreamErr, code, message)
+	mcpErr.WithContext("upstream", upstream)
+	mcpErr.WithDebugInfo("upstream_error", map[string]interface{}{
+		"code":    upstreamErr.Code,
+		"message": upstreamErr.Message,
+		"data":    upstreamErr.Data,
+	})
+	return mcpErr
+}
+
+// TranslateError translates err if it carries a JSON-RPC error and wraps
+// any other error as a generic upstream failure
+func (t *Translator) TranslateError(upstream string, err error) *MCPError {
+	if err == nil {
+		return nil
+	}
+	if mcpErr := FindMCPError(err); mcpErr != nil {
+		return mcpErr
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return t.Translate(upstream, rpcErr)
+	}
+	return WrapError(err, t.defaultCode, GetMCPErrorMessage(t.defaultCode)).
+		WithContext("upstream", upstream)
+}
+
+// match returns the first rule matching the upstream error
+func (t *Translator) match(upstream string, upstreamErr *jsonrpc.Error) (TranslationRule, bool) {
+	for _, rule := range t.rules {
+		if rule.Upstream != "" && rule.Upstream != upstream {
+			continue
+		}
+		if len(rule.Codes) > 0 && !containsCode(rule.Codes, upstreamErr.Code) {
+			continue
+		}
+		if rule.pattern != nil && !rule.pattern.MatchString(upstreamErr.Message) {
+			continue
+		}
+		return rule, true
+	}
+	return TranslationRule{}, false
+}
+
+// containsCode reports whether codes contains code
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestTranslator(t *testing.T) {
+	rules := append([]TranslationRule{
+		{Upstream: "github", Codes: []int{-32050}, To: ErrorCodeMCPRateLimit, Message: "GitHub rate limit"},
+	}, DefaultTranslationRules()...)
+	tr, err := NewTranslator(TranslationConfig{Rules: rules})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		upstream string
+		err      *jsonrpc.Error
+		code     int
+		message  string
+	}{
+		{"upstream specific", "github", &jsonrpc.Error{Code: -32050, Message: "secondary rate limit"}, ErrorCodeMCPRateLimit, "GitHub rate limit"},
+		{"upstream specific ignored elsewhere", "gitlab", &jsonrpc.Error{Code: -32050, Message: "boom"}, ErrorCodeMCPHandler, "Handler error"},
+		{"standard code kept", "gitlab", &jsonrpc.Error{Code: jsonrpc.ErrorCodeInvalidParams, Message: "missing x"}, jsonrpc.ErrorCodeInvalidParams, "missing x"},
+		{"sdk timeout", "fs", &jsonrpc.Error{Code: -32001, Message: "Request timed out"}, ErrorCodeMCPTransportTimeout, "Transport timeout"},
+		{"message match", "fs", &jsonrpc.Error{Code: 42, Message: "Unknown tool: grep"}, ErrorCodeMCPToolNotFound, "Tool not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpErr := tr.Translate(tt.upstream, tt.err)
+			assert.Equal(t, tt.code, mcpErr.Code)
+			assert.Equal(t, tt.message, mcpErr.Message)
+			assert.Equal(t, tt.upstream, mcpErr.Context["upstream"])
+			assert.Equal(t, tt.err.Code, mcpErr.DebugInfo["upstream_error"].(map[string]interface{})["code"])
+
+			var original *jsonrpc.Error
+			require.True(t, errors.As(mcpErr.Cause, &original))
+			assert.Same(t, tt.err, original)
+		})
+	}
+}
+
+func TestTranslator_TranslateError(t *testing.T) {
+	tr, err := NewTranslator(TranslationConfig{})
+	require.NoError(t, err)
+
+	assert.Nil(t, tr.TranslateError("fs", nil))
+
+	local := NewToolNotFoundError("echo")
+	assert.Same(t, local, tr.TranslateError("fs", local))
+
+	wrapped := tr.TranslateError("fs", errors.New("broken pipe"))
+	assert.Equal(t, ErrorCodeMCPHandler, wrapped.Code)
+
+	_, err = NewTranslator(TranslationConfig{Rules: []TranslationRule{{MessagePattern: "("}}})
+	assert.Error(t, err)
+}
@@ -177,6 +177,10 @@ type AggregateError struct {
 	Message  string
 	Code     int
 	Category string
+
+	// Items records the outcome of every item in a fan-out operation,
+	// including those that succeeded or were skipped
+	Items []ItemResult
 }
 
 // Error implements the error interface for AggregateError
@@ -239,6 +243,9 @@ func (ae *AggregateError) ToMCPError() *MCPError {
 			mcpErr.WithContext("additional_errors", additionalErrors)
 		}
 		mcpErr.WithContext("error_count", len(ae.Errors))
+		if len(ae.Items) > 0 {
+			mcpErr.Data = ae.Data()
+		}
 	}
 
 	return mcpErr
@@ -17,6 +17,9 @@ type InitializeHooksConfig struct {
 	ConnectionManager *connection.Manager
 	SupportedVersions []string
 	ServerInfo        mcp.Implementation
+
+	// OnTimeout is called when the handshake of a connection times out
+	OnTimeout func(conn *connection.Connection)
 }
 
 // CreateInitializeHooks creates and returns initialization hooks for the MCP server.
@@ -80,6 +83,9 @@ func CreateInitializeHooks(config InitializeHooksConfig) (server.OnBeforeInitial
 		timeoutCallback := func() {
 			logger.WithField(logging.FieldConnectionID, conn.ID).
 				Warn(ctx, "Handshake timeout")
+			if config.OnTimeout != nil {
+				config.OnTimeout(conn)
+			}
 		}
 
 		if err := conn.StartHandshake(timeoutCallback); err != nil {
@@ -123,6 +129,13 @@ func CreateInitializeHooks(config InitializeHooksConfig) (server.OnBeforeInitial
 			return
 		}
 
+		// Record the negotiated capabilities for capability-aware validation
+		var clientCapabilities mcp.ClientCapabilities
+		if message != nil {
+			clientCapabilities = message.Params.Capabilities
+		}
+		conn.SetCapabilities(clientCapabilities, result.Capabilities)
+
 		logger.WithFields(logging.LogFields{
 			logging.FieldConnectionID:    conn.ID,
 			logging.FieldConnectionState: conn.GetState().String(),
@@ -7,6 +7,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/meta-mcp/meta-mcp-server/internal/logging"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -63,6 +64,11 @@ func CreateValidationHooks(config ValidationHooksConfig) server.BeforeAnyHookFun
 
 			// For now, we'll log the rejection. The actual error response would need
 			// to be handled by the request handler or through a custom middleware layer.
+		} else if err := CheckCapability(conn, string(method)); err != nil {
+			logger.WithFields(logging.LogFields{
+				logging.FieldMethod:       string(method),
+				logging.FieldConnectionID: conn.ID,
+			}).Warn(ctx, "Rejecting method - capability not negotiated")
 		} else {
 			logger.WithFields(logging.LogFields{
 				logging.FieldMethod:       string(method),
@@ -93,7 +99,7 @@ func CreateRequestValidator(manager *connection.Manager) func(ctx context.Contex
 		// Check if handshake is complete
 		if !conn.IsReady() {
 			return &jsonrpc.Error{
-				Code:    -32011, // ErrorCodeServerNotInitialized
+				Code:    mcperrors.ErrorCodeMCPNotInitialized,
 				Message: "Connection not initialized",
 				Data: map[string]interface{}{
 					"state":  conn.GetState().String(),
@@ -102,8 +108,69 @@ func CreateRequestValidator(manager *connection.Manager) func(ctx context.Contex
 			}
 		}
 
+		// Check the method's capability was negotiated
+		if err := CheckCapability(conn, method); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// capabilityCheck names the capability a method depends on and reports
+// whether it was negotiated.
+type capabilityCheck struct {
+	capability string
+	supported  func(client mcp.ClientCapabilities, server mcp.ServerCapabilities) bool
+}
+
+// capabilityChecks lists the methods that depend on an optional capability.
+// Requests from the client need a server capability and requests from the
+// server need a client capability.
+var capabilityChecks = map[string]capabilityCheck{
+	"resources/subscribe":                   {"resources.subscribe", serverSubscribes},
+	"resources/unsubscribe":                 {"resources.subscribe", serverSubscribes},
+	string(mcp.MethodSetLogLevel):           {"logging", serverLogs},
+	string(mcp.MethodSamplingCreateMessage): {"sampling", clientSamples},
+	"roots/list":                            {"roots", clientHasRoots},
+}
+
+func serverSubscribes(_ mcp.ClientCapabilities, server mcp.ServerCapabilities) bool {
+	return server.Resources != nil && server.Resources.Subscribe
+}
+
+func serverLogs(_ mcp.ClientCapabilities, server mcp.ServerCapabilities) bool {
+	return server.Logging != nil
+}
+
+func clientSamples(client mcp.ClientCapabilities, _ mcp.ServerCapabilities) bool {
+	return client.Sampling != nil
+}
+
+func clientHasRoots(client mcp.ClientCapabilities, _ mcp.ServerCapabilities) bool {
+	return client.Roots != nil
+}
+
+// CheckCapability returns a capability not supported error if method depends
+// on a capability that was not negotiated on the connection. Connections
+// without recorded capabilities are not checked.
+func CheckCapability(conn *connection.Connection, method string) *jsonrpc.Error {
+	check, ok := capabilityChecks[method]
+	if !ok {
 		return nil
 	}
+	client, server, negotiated := conn.Capabilities()
+	if !negotiated || check.supported(client, server) {
+		return nil
+	}
+	return &jsonrpc.Error{
+		Code:    mcperrors.ErrorCodeMCPCapabilityNotSupported,
+		Message: "Capability not supported",
+		Data: map[string]interface{}{
+			"capability": check.capability,
+			"method":     method,
+		},
+	}
 }
 
 // isNotification checks if a message is a notification (has no ID).
@@ -8,7 +8,6 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
-	"github.com/meta-mcp/meta-mcp-server/test/testutil"
 )
 
 
@@ -83,9 +82,9 @@ func TestCreateValidationHooks(t *testing.T) {
 			// Create connection manager
 			var manager *connection.Manager
 			if tt.hasConnectionID {
-				manager = testutil.CreateTestManagerWithConnection(tt.connectionID, tt.connectionState)
+				manager = newTestManagerWithConnection(tt.connectionID, tt.connectionState)
 			} else {
-				manager = testutil.CreateTestManager()
+				manager = newTestManager()
 			}
 
 			// Create config
@@ -190,9 +189,9 @@ func TestCreateRequestValidator(t *testing.T) {
 			// Create connection manager
 			var manager *connection.Manager
 			if tt.hasConnectionID {
-				manager = testutil.CreateTestManagerWithConnection(tt.connectionID, tt.connectionState)
+				manager = newTestManagerWithConnection(tt.connectionID, tt.connectionState)
 			} else {
-				manager = testutil.CreateTestManager()
+				manager = newTestManager()
 			}
 
 			// Create validator
@@ -312,9 +311,9 @@ func TestCreateErrorHook(t *testing.T) {
 			// Create connection manager
 			var manager *connection.Manager
 			if tt.hasConnectionID {
-				manager = testutil.CreateTestManagerWithConnection(tt.connectionID, tt.connectionState)
+				manager = newTestManagerWithConnection(tt.connectionID, tt.connectionState)
 			} else {
-				manager = testutil.CreateTestManager()
+				manager = newTestManager()
 			}
 
 			// Create config
@@ -373,7 +372,7 @@ func TestCreateSuccessHook(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create config
 			config := ValidationHooksConfig{
-				ConnectionManager: testutil.CreateTestManager(),
+				ConnectionManager: newTestManager(),
 			}
 
 			// Create the hook
@@ -431,7 +430,7 @@ func TestValidationHooksEdgeCases(t *testing.T) {
 
 // Test concurrent access scenarios
 func TestValidationHooksConcurrency(t *testing.T) {
-	manager := testutil.CreateTestManager()
+	manager := newTestManager()
 	config := ValidationHooksConfig{
 		ConnectionManager: manager,
 	}
@@ -474,7 +473,7 @@ func TestValidationHooksConcurrency(t *testing.T) {
 
 // Test error scenarios in CreateRequestValidator
 func TestCreateRequestValidatorErrorCases(t *testing.T) {
-	manager := testutil.CreateTestManager()
+	manager := newTestManager()
 	validator := CreateRequestValidator(manager)
 
 	t.Run("connection_not_found", func(t *testing.T) {
@@ -497,7 +496,7 @@ func TestCreateRequestValidatorErrorCases(t *testing.T) {
 
 // Benchmark tests
 func BenchmarkCreateValidationHooks(b *testing.B) {
-	manager := testutil.CreateTestManagerWithConnection("bench-conn", connection.StateReady)
+	manager := newTestManagerWithConnection("bench-conn", connection.StateReady)
 	
 	config := ValidationHooksConfig{
 		ConnectionManager: manager,
@@ -513,7 +512,7 @@ func BenchmarkCreateValidationHooks(b *testing.B) {
 }
 
 func BenchmarkCreateRequestValidator(b *testing.B) {
-	manager := testutil.CreateTestManagerWithConnection("bench-conn", connection.StateReady)
+	manager := newTestManagerWithConnection("bench-conn", connection.StateReady)
 	
 	validator := CreateRequestValidator(manager)
 	ctx := connection.WithConnectionID(context.Background(), "bench-conn")
@@ -522,4 +521,58 @@ func BenchmarkCreateRequestValidator(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = validator(ctx, "tools/list")
 	}
-}
\ No newline at end of file
+}
+func TestCheckCapability(t *testing.T) {
+	conn := &connection.Connection{ID: "caps", State: connection.StateReady}
+
+	// Nothing is rejected before capabilities are negotiated
+	if err := CheckCapability(conn, "resources/subscribe"); err != nil {
+		t.Errorf("Expected no error without negotiated capabilities, got %v", err)
+	}
+
+	var server mcp.ServerCapabilities
+	server.Resources = &struct {
+		Subscribe   bool `json:"subscribe,omitempty"`
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{ListChanged: true}
+	conn.SetCapabilities(mcp.ClientCapabilities{Sampling: &struct{}{}}, server)
+
+	tests := []struct {
+		method  string
+		wantErr bool
+	}{
+		{"resources/subscribe", true},
+		{"resources/unsubscribe", true},
+		{"logging/setLevel", true},
+		{"roots/list", true},
+		{"sampling/createMessage", false},
+		{"resources/list", false},
+		{"tools/call", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			err := CheckCapability(conn, tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckCapability(%s) = %v, wantErr %v", tt.method, err, tt.wantErr)
+			}
+			if err != nil && err.Code != -32012 {
+				t.Errorf("Expected error code -32012, got %d", err.Code)
+			}
+		})
+	}
+
+	// The request validator applies the same checks
+	manager := newTestManager()
+	managed, _ := manager.CreateConnection("validated")
+	managed.State = connection.StateReady
+	managed.SetCapabilities(mcp.ClientCapabilities{}, mcp.ServerCapabilities{})
+	validator := CreateRequestValidator(manager)
+	ctx := connection.WithConnectionID(context.Background(), "validated")
+	var rpcErr *jsonrpc.Error
+	if err := validator(ctx, "sampling/createMessage"); !errors.As(err, &rpcErr) || rpcErr.Code != -32012 {
+		t.Errorf("Expected a capability not supported error, got %v", err)
+	}
+	if err := validator(ctx, "tools/list"); err != nil {
+		t.Errorf("Expected tools/list to be allowed, got %v", err)
+	}
+}
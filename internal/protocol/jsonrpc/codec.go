@@ -10,73 +10,86 @@ type Message interface {
 	Validate() error
 }
 
-// ParseMessage parses a single JSON-RPC message from raw bytes
+// ParseMessage parses a single JSON-RPC message from raw bytes. The message
+// comes from a pool; pass it to ReleaseMessage once it is no longer used.
 func ParseMessage(raw []byte) (Message, error) {
-	// First, parse into a generic map to determine the message type
-	var generic map[string]json.RawMessage
-	if err := json.Unmarshal(raw, &generic); err != nil {
+	// First, parse the top-level members to determine the message type
+	env := envelopePool.Get().(*envelope)
+	defer func() {
+		env.reset()
+		envelopePool.Put(env)
+	}()
+	if err := json.Unmarshal(raw, env); err != nil {
 		return nil, NewParseError("Invalid JSON")
 	}
 
 	// Check for required jsonrpc field
-	versionRaw, hasVersion := generic["jsonrpc"]
-	if !hasVersion {
+	if len(env.Version) == 0 {
 		return nil, NewInvalidRequestError("Missing jsonrpc field")
 	}
-
-	var version string
-	if err := json.Unmarshal(versionRaw, &version); err != nil {
-		return nil, NewInvalidRequestError("Invalid jsonrpc field")
-	}
-
-	if version != Version {
-		return nil, NewInvalidRequestError("jsonrpc field must be \"2.0\"")
+	if !bytes.Equal(env.Version, quotedVersion) {
+		var version string
+		if err := json.Unmarshal(env.Version, &version); err != nil {
+			return nil, NewInvalidRequestError("Invalid jsonrpc field")
+		}
+		if version != Version {
+			return nil, NewInvalidRequestError("jsonrpc field must be \"2.0\"")
+		}
 	}
 
 	// Determine message type based on presence of fields
-	_, hasMethod := generic["method"]
-	_, hasResult := generic["result"]
-	_, hasError := generic["error"]
-	_, hasID := generic["id"]
+	hasMethod := len(env.Method) > 0
+	hasResult := len(env.Result) > 0
+	hasError := len(env.Error) > 0
+	hasID := len(env.ID) > 0
 
 	if hasMethod {
 		// This is either a Request or Notification
 		if hasID {
 			// Request
-			var req Request
-			if err := json.Unmarshal(raw, &req); err != nil {
+			req := AcquireRequest()
+			if err := json.Unmarshal(raw, req); err != nil {
+				ReleaseRequest(req)
 				return nil, NewParseError("Invalid request format")
 			}
 			if err := req.Validate(); err != nil {
+				ReleaseRequest(req)
 				return nil, err
 			}
-			return &req, nil
+			return req, nil
 		} else {
 			// Notification
-			var notif Notification
-			if err := json.Unmarshal(raw, &notif); err != nil {
+			notif := AcquireNotification()
+			if err := json.Unmarshal(raw, notif); err != nil {
+				ReleaseNotification(notif)
 				return nil, NewParseError("Invalid notification format")
 			}
 			if err := notif.Validate(); err != nil {
+				ReleaseNotification(notif)
 				return nil, err
 			}
-			return &notif, nil
+			return notif, nil
 		}
 	} else if hasResult || hasError {
 		// This is a Response
-		var resp Response
-		if err := json.Unmarshal(raw, &resp); err != nil {
+		resp := AcquireResponse()
+		if err := json.Unmarshal(raw, resp); err != nil {
+			ReleaseResponse(resp)
 			return nil, NewParseError("Invalid response format")
 		}
 		if err := resp.Validate(); err != nil {
+			ReleaseResponse(resp)
 			return nil, err
 		}
-		return &resp, nil
+		return resp, nil
 	}
 
 	return nil, NewInvalidRequestError("Invalid message format")
 }
 
+// quotedVersion is the jsonrpc member as it appears on the wire
+var quotedVersion = []byte(`"` + Version + `"`)
+
 // Parse handles both single messages and batch requests
 func Parse(raw []byte) ([]Message, error) {
 	trimmed := bytes.TrimSpace(raw)
@@ -114,6 +127,16 @@ func parseBatch(raw []byte) ([]Message, error) {
 
 	results := make([]Message, 0, len(rawMessages))
 	for _, rawMsg := range rawMessages {
+		if request, reserved := reservedCall(rawMsg); reserved {
+			// Calls to reserved methods are left to the router, which
+			// answers those without a RegisterReserved handler with method
+			// not found; notifications get no response
+			if request != nil {
+				results = append(results, request)
+			}
+			continue
+		}
+
 		msg, err := ParseMessage(rawMsg)
 		if err != nil {
 			// For batch requests, we continue parsing other messages
@@ -131,11 +154,54 @@ func parseBatch(raw []byte) ([]Message, error) {
 	return results, nil
 }
 
+// reservedCall reports whether raw is a well-formed call to a reserved
+// method, which Validate rejects, returning the request or nil for a
+// notification
+func reservedCall(raw json.RawMessage) (*Request, bool) {
+	var call struct {
+		Version string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  any             `json:"params"`
+		ID      json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(raw, &call) != nil || call.Version != Version || !IsReserved(call.Method) {
+		return nil, false
+	}
+	if len(call.ID) == 0 {
+		return nil, true
+	}
+	var id any
+	if json.Unmarshal(call.ID, &id) != nil || !ValidateID(id) {
+		return nil, false
+	}
+	return &Request{Version: Version, Method: call.Method, Params: call.Params, ID: id}, true
+}
+
 // Marshal serializes a message to JSON bytes
 func Marshal(msg Message) ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+// MarshalStable serializes a message like Marshal, but with the members of
+// every object, including those inside params and results, in sorted order,
+// so equal messages always encode to the same bytes
+func MarshalStable(msg Message) ([]byte, error) {
+	data, err := Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoding into generic values sorts object members when re-encoded;
+	// numbers are kept as written
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
 // MarshalBatch serializes multiple messages as a JSON array
 func MarshalBatch(messages []Message) ([]byte, error) {
 	if len(messages) == 0 {
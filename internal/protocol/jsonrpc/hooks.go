@@ -0,0 +1,682 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hook converts values of type T to and from JSON in place of their
+// encoding/json representation
+type Hook[T any] struct {
+	Marshal   func(v T) ([]byte, error)
+	Unmarshal func(data []byte) (T, error)
+}
+
+// Hooks encode and decode params and results, applying the hooks
+// registered for the types they hold, including in nested structs, slices,
+// maps and interface values. Values of types without hooks are left to
+// encoding/json, so an empty set behaves exactly like it.
+//
+// Hooks do not apply inside types implementing json.Marshaler or
+// json.Unmarshaler, nor inside structs embedding unexported structs.
+// It is safe for concurrent use.
+type Hooks struct {
+	mu    sync.RWMutex
+	hooks map[reflect.Type]hook
+
+	// hooked caches whether values of a type may hold values with hooks
+	hooked sync.Map
+}
+
+// hook is a Hook with its type erased
+type hook struct {
+	marshal   func(v reflect.Value) ([]byte, error)
+	unmarshal func(data []byte, v reflect.Value) error
+}
+
+// defaultHooks are the hooks of BindParams and NewResponse
+var defaultHooks = NewHooks()
+
+// NewHooks creates an empty set of hooks
+func NewHooks() *Hooks {
+	return &Hooks{hooks: make(map[reflect.Type]hook)}
+}
+
+// DefaultHooks returns the hooks applied by BindParams and NewResponse.
+// Register them during initialization, before messages are handled.
+func DefaultHooks() *Hooks {
+	return defaultHooks
+}
+
+// Register makes hs convert values of type T with h, replacing any hook
+// registered for T before
+func Register[T any](hs *Hooks, h Hook[T]) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.hooks[reflect.TypeFor[T]()] = hook{
+		marshal: func(v reflect.Value) ([]byte, error) {
+			return h.Marshal(v.Interface().(T))
+		},
+		unmarshal: func(data []byte, v reflect.Value) error {
+			value, err := h.Unmarshal(data)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(&value).Elem())
+			return nil
+		},
+	}
+	hs.hooked.Clear()
+}
+
+// Marshal encodes v like json.Marshal, applying the hooks
+func (hs *Hooks) Marshal(v any) ([]byte, error) {
+	if v == nil || !hs.applies(reflect.TypeOf(v)) {
+		return json.Marshal(v)
+	}
+	encoded, err := hs.encode(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+// Unmarshal decodes data into v like json.Unmarshal, applying the hooks
+func (hs *Hooks) Unmarshal(data []byte, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || !hs.applies(target.Type()) {
+		return json.Unmarshal(data, v)
+	}
+	if !json.Valid(data) {
+		// Report syntax errors as encoding/json does
+		return json.Unmarshal(data, v)
+	}
+	return hs.decode(data, target.Elem())
+}
+
+// Value returns v wrapped so that encoding/json applies the hooks when
+// encoding it, or v itself when none apply to its type
+func (hs *Hooks) Value(v any) any {
+	if v == nil || !hs.applies(reflect.TypeOf(v)) {
+		return v
+	}
+	return encodedValue{hooks: hs, value: v}
+}
+
+// encodedValue is a value encoded by Hooks
+type encodedValue struct {
+	hooks *Hooks
+	value any
+}
+
+// MarshalJSON implements json.Marshaler
+func (e encodedValue) MarshalJSON() ([]byte, error) {
+	return e.hooks.Marshal(e.value)
+}
+
+// lookup returns the hook of t
+func (hs *Hooks) lookup(t reflect.Type) (hook, bool) {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	h, ok := hs.hooks[t]
+	return h, ok
+}
+
+// applies reports whether values of t may hold values with hooks
+func (hs *Hooks) applies(t reflect.Type) bool {
+	if cached, ok := hs.hooked.Load(t); ok {
+		return cached.(bool)
+	}
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	if len(hs.hooks) == 0 {
+		return false
+	}
+	applies := hs.walk(t, make(map[reflect.Type]bool))
+	hs.hooked.Store(t, applies)
+	return applies
+}
+
+var (
+	marshalerType   = reflect.TypeFor[json.Marshaler]()
+	unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+)
+
+// walk looks for types with hooks in t; seen breaks cycles of recursive
+// types. The caller holds hs.mu.
+func (hs *Hooks) walk(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if _, ok := hs.hooks[t]; ok {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	if t.Kind() != reflect.Interface && (t.Implements(marshalerType) || reflect.PointerTo(t).Implements(unmarshalerType)) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		// The dynamic value may have a hook
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return hs.walk(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if f.Anonymous && !f.IsExported() && embedded.Kind() == reflect.Struct {
+				return false
+			}
+		}
+		for _, f := range fieldsOf(t) {
+			if hs.walk(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// field is a JSON member of a struct
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+	quoted    bool
+}
+
+// fieldsOf lists the members of struct type t the way encoding/json names
+// them, promoting the fields of embedded structs
+func fieldsOf(t reflect.Type) []field {
+	return collectFields(t, map[reflect.Type]bool{})
+}
+
+// collectFields lists the fields of t; embedding lists the structs being
+// promoted from, which are not promoted again
+func collectFields(t reflect.Type, embedding map[reflect.Type]bool) []field {
+	embedding[t] = true
+	defer delete(embedding, t)
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if embedding[embedded] {
+					continue
+				}
+				for _, promoted := range collectFields(embedded, embedding) {
+					promoted.index = append([]int{i}, promoted.index...)
+					fields = append(fields, promoted)
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, field{
+			name:      name,
+			index:     []int{i},
+			typ:       f.Type,
+			omitEmpty: hasOption(options, "omitempty"),
+			quoted:    hasOption(options, "string") && quotable(f.Type),
+		})
+	}
+
+	// Shallower fields hide deeper ones of the same name
+	visible := fields[:0]
+	depth := make(map[string]int, len(fields))
+	for _, f := range fields {
+		if d, ok := depth[f.name]; ok && d <= len(f.index) {
+			continue
+		}
+		depth[f.name] = len(f.index)
+		visible = append(visible, f)
+	}
+	kept := visible[:0]
+	for _, f := range visible {
+		if depth[f.name] == len(f.index) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// hasOption reports whether the comma-separated tag options hold option
+func hasOption(options, option string) bool {
+	for options != "" {
+		var name string
+		name, options, _ = strings.Cut(options, ",")
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
+
+// quotable reports whether the ",string" option applies to t
+func quotable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// member is a member of an encoded object
+type member struct {
+	name  string
+	value any
+}
+
+// object is an encoded struct, keeping the order of its fields
+type object []member
+
+// MarshalJSON implements json.Marshaler
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(m.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encode converts v into a value encoding/json encodes with the hooks
+// applied
+func (hs *Hooks) encode(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	if h, ok := hs.lookup(t); ok {
+		if nilable(t) && v.IsNil() {
+			return nil, nil
+		}
+		data, err := h.marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", t, err)
+		}
+		return json.RawMessage(data), nil
+	}
+	if !hs.applies(t) {
+		return v.Interface(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return hs.encode(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		elements := make([]any, v.Len())
+		for i := range elements {
+			element, err := hs.encode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return elements, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		entries := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := mapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			if entries[key], err = hs.encode(iter.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	case reflect.Struct:
+		o := make(object, 0, t.NumField())
+		for _, f := range fieldsOf(t) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && isEmpty(fv)) {
+				continue
+			}
+			var value any
+			var err error
+			if f.quoted {
+				var data []byte
+				data, err = json.Marshal(fv.Interface())
+				value = string(data)
+			} else {
+				value, err = hs.encode(fv)
+			}
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, member{name: f.name, value: value})
+		}
+		return o, nil
+	}
+	return v.Interface(), nil
+}
+
+// decode decodes data, valid JSON, into the settable v with the hooks
+// applied
+func (hs *Hooks) decode(data []byte, v reflect.Value) error {
+	t := v.Type()
+	null := bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+	if h, ok := hs.lookup(t); ok {
+		if null {
+			v.SetZero()
+			return nil
+		}
+		if err := h.unmarshal(data, v); err != nil {
+			return fmt.Errorf("unmarshal %s: %w", t, err)
+		}
+		return nil
+	}
+	if !hs.applies(t) || t.Kind() == reflect.Interface {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if null {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return hs.decode(data, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if null {
+			if t.Kind() == reflect.Slice {
+				v.SetZero()
+			}
+			return nil
+		}
+		var elements []json.RawMessage
+		if err := json.Unmarshal(data, &elements); err != nil {
+			return err
+		}
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(elements), len(elements)))
+		} else {
+			v.SetZero()
+		}
+		for i, element := range elements {
+			if i >= v.Len() {
+				break
+			}
+			if err := hs.decode(element, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if null {
+			v.SetZero()
+			return nil
+		}
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, len(entries)))
+		}
+		for name, entry := range entries {
+			key, err := parseMapKey(name, t.Key())
+			if err != nil {
+				return err
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := hs.decode(entry, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, value)
+		}
+		return nil
+	case reflect.Struct:
+		if null {
+			return nil
+		}
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(data, &members); err != nil {
+			return err
+		}
+		for _, f := range fieldsOf(t) {
+			raw, ok := memberOf(members, f.name)
+			if !ok {
+				continue
+			}
+			fv := allocField(v, f.index)
+			if f.quoted {
+				var s string
+				if err := json.Unmarshal(raw, &s); err != nil {
+					return fmt.Errorf("field %s: %w", f.name, err)
+				}
+				raw = []byte(s)
+			}
+			if err := hs.decode(raw, fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+// memberOf returns the member named name, matching case-insensitively when
+// there is no exact match as encoding/json does
+func memberOf(members map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := members[name]; ok {
+		return raw, true
+	}
+	for key, raw := range members {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// fieldByIndex returns the field of struct v at index, or false when it is
+// promoted from a nil embedded pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, n := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(n)
+	}
+	return v, true
+}
+
+// allocField returns the field of struct v at index, allocating the nil
+// embedded pointers on the way
+func allocField(v reflect.Value, index []int) reflect.Value {
+	for i, n := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(n)
+	}
+	return v
+}
+
+// nilable reports whether values of t can be nil
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// isEmpty reports whether omitempty omits v
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v.IsZero()
+	}
+	return false
+}
+
+// mapKey returns the member name encoding/json uses for map key k
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if m, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", k.Type())
+}
+
+// parseMapKey converts member name to a map key of type t
+func parseMapKey(name string, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(name).Convert(t), nil
+	}
+	key := reflect.New(t)
+	if u, ok := key.Interface().(encoding.TextUnmarshaler); ok {
+		return key.Elem(), u.UnmarshalText([]byte(name))
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(name, 10, t.Bits())
+		key.Elem().SetInt(n)
+		return key.Elem(), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(name, 10, t.Bits())
+		key.Elem().SetUint(n)
+		return key.Elem(), err
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported map key type %s", t)
+}
+
+// TimeLayout returns a hook encoding times as strings in layout, e.g.
+// time.RFC1123 or time.DateOnly
+func TimeLayout(layout string) Hook[time.Time] {
+	return Hook[time.Time]{
+		Marshal: func(t time.Time) ([]byte, error) {
+			return json.Marshal(t.Format(layout))
+		},
+		Unmarshal: func(data []byte) (time.Time, error) {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return time.Time{}, err
+			}
+			return time.Parse(layout, s)
+		},
+	}
+}
+
+// BigIntString returns a hook encoding big integers as decimal strings,
+// which clients parsing numbers as float64 cannot round. Numbers are
+// accepted too.
+func BigIntString() Hook[*big.Int] {
+	return Hook[*big.Int]{
+		Marshal: func(n *big.Int) ([]byte, error) {
+			return json.Marshal(n.String())
+		},
+		Unmarshal: func(data []byte) (*big.Int, error) {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				s = string(bytes.TrimSpace(data))
+			}
+			n, ok := new(big.Int).SetString(s, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid integer %q", s)
+			}
+			return n, nil
+		},
+	}
+}
+
+// Base64URL returns a hook encoding byte slices in unpadded base64url
+// rather than standard base64. Padded input is accepted too.
+func Base64URL() Hook[[]byte] {
+	return Hook[[]byte]{
+		Marshal: func(b []byte) ([]byte, error) {
+			return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+		},
+		Unmarshal: func(data []byte) ([]byte, error) {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, err
+			}
+			return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookedParams struct {
+	When    time.Time         `json:"when"`
+	Amount  *big.Int          `json:"amount"`
+	Key     []byte            `json:"key,omitempty"`
+	Tags    []time.Time       `json:"tags,omitempty"`
+	ByName  map[string][]byte `json:"byName,omitempty"`
+	Count   int               `json:"count,string"`
+	Ignored string            `json:"-"`
+	HookedEmbedded
+}
+
+type HookedEmbedded struct {
+	Note string `json:"note"`
+}
+
+func newTestHooks() *Hooks {
+	hooks := NewHooks()
+	Register(hooks, TimeLayout(time.DateOnly))
+	Register(hooks, BigIntString())
+	Register(hooks, Base64URL())
+	return hooks
+}
+
+func TestHooks_Marshal(t *testing.T) {
+	hooks := newTestHooks()
+	amount, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	params := hookedParams{
+		When:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Amount:  amount,
+		Key:     []byte{0xfb, 0xff},
+		ByName:  map[string][]byte{"a": {0xfb}},
+		Count:   3,
+		Ignored: "x",
+	}
+	params.Note = "n"
+
+	data, err := hooks.Marshal(params)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"when": "2024-03-01",
+		"amount": "123456789012345678901234567890",
+		"key": "-_8",
+		"byName": {"a": "-w"},
+		"count": "3",
+		"note": "n"
+	}`, string(data))
+	assert.True(t, strings.HasPrefix(string(data), `{"when":`), "fields keep their order: %s", data)
+
+	// Hooks apply to values held by interfaces too
+	data, err = hooks.Marshal(map[string]any{"when": params.When, "nested": []any{params.Key}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"when":"2024-03-01","nested":["-_8"]}`, string(data))
+}
+
+func TestHooks_Unmarshal(t *testing.T) {
+	hooks := newTestHooks()
+
+	var params hookedParams
+	err := hooks.Unmarshal([]byte(`{
+		"when": "2024-03-01",
+		"amount": 98765432109876543210,
+		"key": "-_8=",
+		"tags": ["2024-01-02"],
+		"byName": {"a": "-w"},
+		"COUNT": "7",
+		"note": "n"
+	}`), &params)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), params.When)
+	assert.Equal(t, "98765432109876543210", params.Amount.String())
+	assert.Equal(t, []byte{0xfb, 0xff}, params.Key)
+	assert.Equal(t, []time.Time{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, params.Tags)
+	assert.Equal(t, map[string][]byte{"a": {0xfb}}, params.ByName)
+	assert.Equal(t, 7, params.Count)
+	assert.Equal(t, "n", params.Note)
+
+	// Hook errors name the type
+	err = hooks.Unmarshal([]byte(`{"when":"March 1st"}`), &params)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "time.Time")
+
+	// Syntax errors are those of encoding/json
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(hooks.Unmarshal([]byte(`{`), &params), &syntaxErr))
+}
+
+func TestHooks_Empty(t *testing.T) {
+	// Without hooks, values encode and decode as with encoding/json
+	hooks := NewHooks()
+	params := hookedParams{When: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Key: []byte{1}, Amount: big.NewInt(1)}
+
+	got, err := hooks.Marshal(params)
+	require.NoError(t, err)
+	want, err := json.Marshal(params)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+	assert.Equal(t, params, hooks.Value(params))
+}
+
+func TestRequest_BindParamsWith(t *testing.T) {
+	hooks := newTestHooks()
+
+	// Params decoded from the wire
+	req := NewRequest("test", map[string]any{"when": "2024-03-01", "amount": "42"}, 1)
+	var params hookedParams
+	require.NoError(t, req.BindParamsWith(hooks, &params))
+	assert.Equal(t, 2024, params.When.Year())
+	assert.Equal(t, int64(42), params.Amount.Int64())
+
+	// Params built in process round-trip through the hooks
+	req = NewRequest("test", hookedParams{When: params.When, Amount: params.Amount}, 2)
+	var again hookedParams
+	require.NoError(t, req.BindParamsWith(hooks, &again))
+	assert.Equal(t, params.When, again.When)
+
+	req = NewRequest("test", map[string]any{"when": "soon"}, 3)
+	err := req.BindParamsWith(hooks, &params)
+	var rpcErr *Error
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, ErrorCodeInvalidParams, rpcErr.Code)
+}
+
+// hookedAmount is only registered with the default hooks
+type hookedAmount struct {
+	Cents int64
+}
+
+func TestDefaultHooks(t *testing.T) {
+	Register(DefaultHooks(), Hook[hookedAmount]{
+		Marshal: func(a hookedAmount) ([]byte, error) {
+			return json.Marshal(big.NewRat(a.Cents, 100).FloatString(2))
+		},
+		Unmarshal: func(data []byte) (hookedAmount, error) {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return hookedAmount{}, err
+			}
+			r, ok := new(big.Rat).SetString(s)
+			if !ok {
+				return hookedAmount{}, errors.New("invalid amount")
+			}
+			cents := new(big.Rat).Mul(r, big.NewRat(100, 1))
+			return hookedAmount{Cents: cents.Num().Int64()}, nil
+		},
+	})
+
+	type result struct {
+		Total hookedAmount `json:"total"`
+	}
+	resp := NewResponse(result{Total: hookedAmount{Cents: 1250}}, 1)
+	data, err := Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","result":{"total":"12.50"},"id":1}`, string(data))
+
+	req := NewRequest("test", map[string]any{"total": "3.25"}, 1)
+	var params result
+	require.NoError(t, req.BindParams(&params))
+	assert.Equal(t, int64(325), params.Total.Cents)
+}
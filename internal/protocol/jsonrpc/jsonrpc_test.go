@@ -394,6 +394,22 @@ func TestMarshalBatch(t *testing.T) {
 	}
 }
 
+func TestMarshalStable(t *testing.T) {
+	req := NewRequest("tools/call", map[string]any{
+		"name":      "search",
+		"arguments": json.RawMessage(`{"query":"x","limit":10,"filters":{"z":1,"a":1.50}}`),
+	}, 7)
+
+	data, err := MarshalStable(req)
+	if err != nil {
+		t.Fatalf("MarshalStable failed: %v", err)
+	}
+	want := `{"id":7,"jsonrpc":"2.0","method":"tools/call","params":{"arguments":{"filters":{"a":1.50,"z":1},"limit":10,"query":"x"},"name":"search"}}`
+	if string(data) != want {
+		t.Errorf("MarshalStable = %s, want %s", data, want)
+	}
+}
+
 func TestParseEdgeCases(t *testing.T) {
 	// Test empty input
 	_, err := Parse([]byte(""))
@@ -559,6 +575,52 @@ func TestParseBatchEdgeCases(t *testing.T) {
 	}
 }
 
+func TestParseBatchReservedMethods(t *testing.T) {
+	batch := `[
+		{"jsonrpc":"2.0","method":"test","id":1},
+		{"jsonrpc":"2.0","method":"rpc.discover","id":"r-2"},
+		{"jsonrpc":"2.0","method":"rpc.ping"},
+		{"jsonrpc":"2.0","method":"rpc.discover","id":{"bad":true}}
+	]`
+	messages, err := Parse([]byte(batch))
+	if err != nil {
+		t.Fatalf("Parse batch with reserved methods should not fail: %v", err)
+	}
+	// The reserved notification gets no response
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	if req, ok := messages[0].(*Request); !ok || req.Method != "test" {
+		t.Errorf("First message should be the test request, got %#v", messages[0])
+	}
+
+	// The reserved call is left to the router
+	req, ok := messages[1].(*Request)
+	if !ok || req.Method != "rpc.discover" || req.ID != "r-2" {
+		t.Fatalf("Second message should be the rpc.discover request, got %#v", messages[1])
+	}
+
+	// A reserved call with an invalid ID is an invalid request
+	resp, ok := messages[2].(*Response)
+	if !ok || resp.Error == nil || resp.ID != nil {
+		t.Errorf("Third message should be an error response without ID, got %#v", messages[2])
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	for method, want := range map[string]bool{
+		"rpc.discover": true,
+		"rpc.":         true,
+		"rpc":          false,
+		"rpcx.test":    false,
+		"tools/list":   false,
+	} {
+		if got := IsReserved(method); got != want {
+			t.Errorf("IsReserved(%q) = %v, want %v", method, got, want)
+		}
+	}
+}
+
 func TestValidateIDTypes(t *testing.T) {
 	// Test all valid ID types for Request validation
 	validIDs := []any{
@@ -631,6 +693,7 @@ func TestNotificationValidationComplete(t *testing.T) {
 // Benchmarks for parser performance
 func BenchmarkParseRequest(b *testing.B) {
 	requestJSON := []byte(`{"jsonrpc":"2.0","method":"test_method","params":{"key":"value"},"id":1}`)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := ParseMessage(requestJSON)
@@ -642,6 +705,7 @@ func BenchmarkParseRequest(b *testing.B) {
 
 func BenchmarkParseResponse(b *testing.B) {
 	responseJSON := []byte(`{"jsonrpc":"2.0","result":{"success":true},"id":1}`)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := ParseMessage(responseJSON)
@@ -653,6 +717,7 @@ func BenchmarkParseResponse(b *testing.B) {
 
 func BenchmarkMarshalRequest(b *testing.B) {
 	req := NewRequest("test_method", map[string]any{"key": "value"}, 1)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := Marshal(req)
@@ -664,6 +729,7 @@ func BenchmarkMarshalRequest(b *testing.B) {
 
 func BenchmarkMarshalResponse(b *testing.B) {
 	resp := NewResponse(map[string]any{"success": true}, 1)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := Marshal(resp)
@@ -1,7 +1,6 @@
 package jsonrpc
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
@@ -48,39 +47,31 @@ func (e *Error) Error() string {
 
 // NewRequest creates a new JSON-RPC request
 func NewRequest(method string, params any, id any) *Request {
-	return &Request{
-		Version: Version,
-		Method:  method,
-		Params:  params,
-		ID:      id,
-	}
+	req := AcquireRequest()
+	req.Version, req.Method, req.Params, req.ID = Version, method, params, id
+	return req
 }
 
 // NewNotification creates a new JSON-RPC notification
 func NewNotification(method string, params any) *Notification {
-	return &Notification{
-		Version: Version,
-		Method:  method,
-		Params:  params,
-	}
+	notif := AcquireNotification()
+	notif.Version, notif.Method, notif.Params = Version, method, params
+	return notif
 }
 
-// NewResponse creates a new JSON-RPC response with result
+// NewResponse creates a new JSON-RPC response with result. The hooks of
+// DefaultHooks apply when the result is encoded.
 func NewResponse(result any, id any) *Response {
-	return &Response{
-		Version: Version,
-		Result:  result,
-		ID:      id,
-	}
+	resp := AcquireResponse()
+	resp.Version, resp.Result, resp.ID = Version, defaultHooks.Value(result), id
+	return resp
 }
 
 // NewErrorResponse creates a new JSON-RPC response with error
 func NewErrorResponse(err *Error, id any) *Response {
-	return &Response{
-		Version: Version,
-		Error:   err,
-		ID:      id,
-	}
+	resp := AcquireResponse()
+	resp.Version, resp.Error, resp.ID = Version, err, id
+	return resp
 }
 
 // IsRequest returns true if this is a request (has ID and is not a notification)
@@ -94,20 +85,26 @@ func (r *Request) IsNotification() bool {
 }
 
 // BindParams unmarshals the params from a request into a given struct.
-// This simplifies handling of named or positional parameters.
+// This simplifies handling of named or positional parameters. The hooks of
+// DefaultHooks apply.
 func (r *Request) BindParams(v any) error {
+	return r.BindParamsWith(defaultHooks, v)
+}
+
+// BindParamsWith binds the params like BindParams, applying hooks instead
+func (r *Request) BindParamsWith(hooks *Hooks, v any) error {
 	if r.Params == nil {
 		// No params, nothing to bind
 		return nil
 	}
 
 	// Re-marshal and unmarshal to convert from any to specific struct
-	paramsBytes, err := json.Marshal(r.Params)
+	paramsBytes, err := hooks.Marshal(r.Params)
 	if err != nil {
 		return NewError(ErrorCodeInternal, "Failed to re-marshal params", err.Error())
 	}
 
-	if err := json.Unmarshal(paramsBytes, v); err != nil {
+	if err := hooks.Unmarshal(paramsBytes, v); err != nil {
 		return NewError(ErrorCodeInvalidParams, "Failed to bind params to target", err.Error())
 	}
 
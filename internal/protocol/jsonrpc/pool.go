@@ -0,0 +1,125 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Messages are pooled because tool-heavy sessions create thousands of them
+// per minute. Releasing is optional: a message that is never released is
+// collected as usual. A released message must not be used again.
+var (
+	requestPool      = sync.Pool{New: func() any { return new(Request) }}
+	responsePool     = sync.Pool{New: func() any { return new(Response) }}
+	notificationPool = sync.Pool{New: func() any { return new(Notification) }}
+	envelopePool     = sync.Pool{New: func() any { return new(envelope) }}
+	encoderPool      = sync.Pool{New: func() any { return newEncoder() }}
+)
+
+// maxPooledBuffer is the largest encode buffer returned to the pool, so one
+// huge message does not pin its memory
+const maxPooledBuffer = 64 * 1024
+
+// AcquireRequest returns an empty request from the pool
+func AcquireRequest() *Request {
+	return requestPool.Get().(*Request)
+}
+
+// ReleaseRequest returns r to the pool
+func ReleaseRequest(r *Request) {
+	if r == nil {
+		return
+	}
+	*r = Request{}
+	requestPool.Put(r)
+}
+
+// AcquireResponse returns an empty response from the pool
+func AcquireResponse() *Response {
+	return responsePool.Get().(*Response)
+}
+
+// ReleaseResponse returns r to the pool. Its error object is not pooled.
+func ReleaseResponse(r *Response) {
+	if r == nil {
+		return
+	}
+	*r = Response{}
+	responsePool.Put(r)
+}
+
+// AcquireNotification returns an empty notification from the pool
+func AcquireNotification() *Notification {
+	return notificationPool.Get().(*Notification)
+}
+
+// ReleaseNotification returns n to the pool
+func ReleaseNotification(n *Notification) {
+	if n == nil {
+		return
+	}
+	*n = Notification{}
+	notificationPool.Put(n)
+}
+
+// ReleaseMessage returns a message created by ParseMessage or one of the
+// constructors to its pool
+func ReleaseMessage(msg Message) {
+	switch m := msg.(type) {
+	case *Request:
+		ReleaseRequest(m)
+	case *Response:
+		ReleaseResponse(m)
+	case *Notification:
+		ReleaseNotification(m)
+	}
+}
+
+// envelope holds the top-level members ParseMessage uses to tell message
+// types apart. Pooled envelopes reuse the memory of their raw values.
+type envelope struct {
+	Version json.RawMessage `json:"jsonrpc"`
+	Method  json.RawMessage `json:"method"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// reset empties e, keeping its memory
+func (e *envelope) reset() {
+	e.Version, e.Method, e.ID, e.Result, e.Error = e.Version[:0], e.Method[:0], e.ID[:0], e.Result[:0], e.Error[:0]
+}
+
+// encoder is a reusable JSON encoder writing to its own buffer
+type encoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// newEncoder creates an encoder
+func newEncoder() *encoder {
+	e := &encoder{}
+	e.enc = json.NewEncoder(&e.buf)
+	return e
+}
+
+// Encode writes msg to w as JSON followed by a newline, the framing of
+// newline-delimited transports. It encodes into a pooled buffer, so unlike
+// Marshal it does not allocate the output.
+func Encode(w io.Writer, msg Message) error {
+	e := encoderPool.Get().(*encoder)
+	defer func() {
+		if e.buf.Cap() <= maxPooledBuffer {
+			e.buf.Reset()
+			encoderPool.Put(e)
+		}
+	}()
+
+	if err := e.enc.Encode(msg); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
@@ -0,0 +1,131 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestReleaseClearsMessages(t *testing.T) {
+	req := NewRequest("test", map[string]any{"key": "value"}, 1)
+	ReleaseRequest(req)
+	if *req != (Request{}) {
+		t.Errorf("released request not cleared: %+v", req)
+	}
+
+	resp := NewErrorResponse(NewInternalError(nil), 1)
+	ReleaseResponse(resp)
+	if *resp != (Response{}) {
+		t.Errorf("released response not cleared: %+v", resp)
+	}
+
+	notif := NewNotification("test", nil)
+	ReleaseMessage(notif)
+	if *notif != (Notification{}) {
+		t.Errorf("released notification not cleared: %+v", notif)
+	}
+
+	// Nil messages are ignored
+	ReleaseRequest(nil)
+	ReleaseResponse(nil)
+	ReleaseNotification(nil)
+	ReleaseMessage(nil)
+}
+
+func TestParseMessageReuse(t *testing.T) {
+	// Parsing after releasing must not see members of the previous message
+	msg, err := ParseMessage([]byte(`{"jsonrpc":"2.0","method":"first","params":{"a":1},"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ReleaseMessage(msg)
+
+	msg, err = ParseMessage([]byte(`{"jsonrpc":"2.0","method":"second"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	notif, ok := msg.(*Notification)
+	if !ok {
+		t.Fatalf("got %T, want *Notification", msg)
+	}
+	if notif.Method != "second" || notif.Params != nil {
+		t.Errorf("unexpected notification %+v", notif)
+	}
+	ReleaseMessage(msg)
+
+	// An escaped version string is still accepted
+	if _, err := ParseMessage([]byte(`{"jsonrpc":"2\u002e0","result":true,"id":1}`)); err != nil {
+		t.Errorf("escaped version rejected: %v", err)
+	}
+	if _, err := ParseMessage([]byte(`{"jsonrpc":2,"result":true,"id":1}`)); err == nil {
+		t.Error("numeric version accepted")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	messages := []Message{
+		NewRequest("test", map[string]any{"html": "<b>"}, "abc"),
+		NewResponse(map[string]any{"ok": true}, 1),
+		NewNotification("event", nil),
+	}
+	for _, msg := range messages {
+		want, err := Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := Encode(&buf, msg); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != string(want)+"\n" {
+			t.Errorf("Encode() = %q, want %q", got, string(want)+"\n")
+		}
+	}
+
+	if err := Encode(io.Discard, NewResponse(func() {}, 1)); err == nil {
+		t.Error("Encode() of an unsupported value should fail")
+	}
+}
+
+// Benchmarks comparing pooled and unpooled message handling; run with
+// -benchmem to see allocs/op
+func BenchmarkParseRequestRelease(b *testing.B) {
+	requestJSON := []byte(`{"jsonrpc":"2.0","method":"test_method","params":{"key":"value"},"id":1}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg, err := ParseMessage(requestJSON)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ReleaseMessage(msg)
+	}
+}
+
+func BenchmarkEncodeResponse(b *testing.B) {
+	resp := NewResponse(map[string]any{"success": true}, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Encode(io.Discard, resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRoundTrip(b *testing.B) {
+	requestJSON := []byte(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"echo"},"id":1}`)
+	result := map[string]any{"content": "ok"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg, err := ParseMessage(requestJSON)
+		if err != nil {
+			b.Fatal(err)
+		}
+		req := msg.(*Request)
+		resp := NewResponse(result, req.ID)
+		if err := Encode(io.Discard, resp); err != nil {
+			b.Fatal(err)
+		}
+		ReleaseResponse(resp)
+		ReleaseRequest(req)
+	}
+}
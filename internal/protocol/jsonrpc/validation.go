@@ -17,7 +17,7 @@ func (r *Request) Validate() error {
 	}
 
 	// Method names that begin with "rpc." are reserved for rpc-internal methods
-	if strings.HasPrefix(r.Method, "rpc.") {
+	if IsReserved(r.Method) {
 		return NewMethodNotFoundError(r.Method)
 	}
 
@@ -71,7 +71,7 @@ func (n *Notification) Validate() error {
 	}
 
 	// Method names that begin with "rpc." are reserved for rpc-internal methods
-	if strings.HasPrefix(n.Method, "rpc.") {
+	if IsReserved(n.Method) {
 		return NewMethodNotFoundError(n.Method)
 	}
 
@@ -105,9 +105,18 @@ func ValidateMethod(method string) bool {
 	}
 
 	// Method names that begin with "rpc." are reserved
-	if strings.HasPrefix(method, "rpc.") {
+	if IsReserved(method) {
 		return false
 	}
 
 	return true
 }
+
+// ReservedPrefix starts the method names the JSON-RPC specification reserves
+// for rpc-internal methods and extensions
+const ReservedPrefix = "rpc."
+
+// IsReserved reports whether method is reserved for rpc-internal methods
+func IsReserved(method string) bool {
+	return strings.HasPrefix(method, ReservedPrefix)
+}
@@ -1,5 +1,7 @@
 package mcp
 
+import mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+
 // Re-export commonly used constants from mcp-go
 const (
 	// Protocol version constants - using mcp-go defaults
@@ -38,6 +40,8 @@ const (
 	MethodNotificationResourcesChanged = "notifications/resources/list_changed"
 	MethodNotificationToolsChanged     = "notifications/tools/list_changed"
 	MethodNotificationPromptsChanged   = "notifications/prompts/list_changed"
+	MethodNotificationResourceUpdated  = "notifications/resources/updated"
+	MethodNotificationMessage          = "notifications/message"
 )
 
 // MCP-specific error codes (extending JSON-RPC error codes)
@@ -54,33 +58,36 @@ const (
 	// ErrorCodeInternalError represents an internal error
 	ErrorCodeInternalError = -32603
 
-	// MCP-specific error codes (range: -32000 to -32099)
-	ErrorCodeResourceNotFound    = -32001
-	ErrorCodeResourceUnavailable = -32002
-	ErrorCodeToolNotFound        = -32003
-	ErrorCodeToolExecutionError  = -32004
-	ErrorCodePromptNotFound      = -32005
-	ErrorCodeInvalidCapability   = -32006
-	ErrorCodeProtocolMismatch    = -32007
-	ErrorCodeUnauthorized        = -32008
-	ErrorCodeRateLimited         = -32009
-	ErrorCodeTimeout             = -32010
-	ErrorCodeServerNotInitialized = -32011
+	// MCP-specific error codes, aliases of the registered codes in the
+	// protocol errors package
+	ErrorCodeResourceNotFound       = mcperrors.ErrorCodeMCPResourceNotFound
+	ErrorCodeResourceUnavailable    = mcperrors.ErrorCodeMCPResourceError
+	ErrorCodeToolNotFound           = mcperrors.ErrorCodeMCPToolNotFound
+	ErrorCodeToolExecutionError     = mcperrors.ErrorCodeMCPToolError
+	ErrorCodePromptNotFound         = mcperrors.ErrorCodeMCPPromptNotFound
+	ErrorCodeInvalidCapability      = mcperrors.ErrorCodeMCPCapabilityError
+	ErrorCodeProtocolMismatch       = mcperrors.ErrorCodeMCPVersionMismatch
+	ErrorCodeUnauthorized           = mcperrors.ErrorCodeMCPUnauthorized
+	ErrorCodeRateLimited            = mcperrors.ErrorCodeMCPRateLimit
+	ErrorCodeTimeout                = mcperrors.ErrorCodeMCPTransportTimeout
+	ErrorCodeServerNotInitialized   = mcperrors.ErrorCodeMCPNotInitialized
+	ErrorCodeCapabilityNotSupported = mcperrors.ErrorCodeMCPCapabilityNotSupported
 )
 
 // Error messages for MCP-specific error codes
 var MCPErrorMessages = map[int]string{
-	ErrorCodeResourceNotFound:    "Resource not found",
-	ErrorCodeResourceUnavailable: "Resource unavailable",
-	ErrorCodeToolNotFound:        "Tool not found",
-	ErrorCodeToolExecutionError:  "Tool execution error",
-	ErrorCodePromptNotFound:      "Prompt not found",
-	ErrorCodeInvalidCapability:   "Invalid capability",
-	ErrorCodeProtocolMismatch:    "Protocol version mismatch",
-	ErrorCodeUnauthorized:        "Unauthorized access",
-	ErrorCodeRateLimited:         "Rate limit exceeded",
-	ErrorCodeTimeout:             "Request timeout",
-	ErrorCodeServerNotInitialized: "Server not initialized",
+	ErrorCodeResourceNotFound:       "Resource not found",
+	ErrorCodeResourceUnavailable:    "Resource unavailable",
+	ErrorCodeToolNotFound:           "Tool not found",
+	ErrorCodeToolExecutionError:     "Tool execution error",
+	ErrorCodePromptNotFound:         "Prompt not found",
+	ErrorCodeInvalidCapability:      "Invalid capability",
+	ErrorCodeProtocolMismatch:       "Protocol version mismatch",
+	ErrorCodeUnauthorized:           "Unauthorized access",
+	ErrorCodeRateLimited:            "Rate limit exceeded",
+	ErrorCodeTimeout:                "Request timeout",
+	ErrorCodeServerNotInitialized:   "Server not initialized",
+	ErrorCodeCapabilityNotSupported: "Capability not supported",
 }
 
 // Capability constants
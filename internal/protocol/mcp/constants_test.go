@@ -0,0 +1,20 @@
+package mcp
+
+import (
+	"testing"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+func TestMCPErrorCodesRegistered(t *testing.T) {
+	for code, message := range MCPErrorMessages {
+		info, ok := mcperrors.DefaultRegistry().Lookup(code)
+		if !ok {
+			t.Errorf("code %d (%s) is not registered", code, message)
+			continue
+		}
+		if info.Owner != "errors" {
+			t.Errorf("code %d owned by %q, want errors", code, info.Owner)
+		}
+	}
+}
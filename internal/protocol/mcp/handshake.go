@@ -7,6 +7,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
 	"github.com/meta-mcp/meta-mcp-server/internal/logging"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/handlers"
@@ -19,6 +20,19 @@ type HandshakeConfig struct {
 	HandshakeTimeout  time.Duration
 	SupportedVersions []string
 	ServerOptions     []server.ServerOption
+
+	// ConfigureHooks registers additional hooks (metrics, auditing, ...)
+	// alongside the handshake hooks
+	ConfigureHooks []func(hooks *server.Hooks)
+
+	// Clock times handshakes (defaults to the system clock)
+	Clock clock.Clock
+
+	// RequireInitialized rejects requests other than ping until the client
+	// sends notifications/initialized, as the specification requires. When
+	// false, requests are served as soon as initialize has been answered,
+	// for lenient clients that never send the notification.
+	RequireInitialized bool
 }
 
 // DefaultHandshakeConfig returns a default configuration.
@@ -36,12 +50,16 @@ type HandshakeServer struct {
 	*Server
 	connectionManager *connection.Manager
 	config            HandshakeConfig
+
+	// stats counts handshakes by outcome
+	stats handshakeStats
 }
 
 // NewHandshakeServer creates a new MCP server with handshake support.
 func NewHandshakeServer(config HandshakeConfig) *HandshakeServer {
 	// Create connection manager
 	connManager := connection.NewManager(config.HandshakeTimeout)
+	connManager.SetClock(config.Clock)
 
 	// Create handshake server instance first (needed for hooks)
 	hs := &HandshakeServer{
@@ -77,6 +95,7 @@ func (hs *HandshakeServer) createHooks() *server.Hooks {
 			Name:    hs.config.Name,
 			Version: hs.config.Version,
 		},
+		OnTimeout: hs.onTimeout,
 	})
 
 	// Create validation hooks
@@ -99,6 +118,22 @@ func (hs *HandshakeServer) createHooks() *server.Hooks {
 	hooks.AddBeforeAny(beforeAny)
 	hooks.AddOnError(errorHook)
 	hooks.AddOnSuccess(successHook)
+	hs.statsHooks(hooks)
+
+	// Track every session registered by a transport as a connection, keyed
+	// by its session ID
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		if _, err := hs.CreateConnection(ctx, session.SessionID()); err != nil {
+			logger.WithField(logging.FieldConnectionID, session.SessionID()).Error(ctx, err, "Failed to track session")
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		hs.CloseConnection(session.SessionID())
+	})
+
+	for _, configure := range hs.config.ConfigureHooks {
+		configure(hooks)
+	}
 
 	logger.Debug(context.Background(), "Hooks registered successfully")
 
@@ -146,28 +181,28 @@ func (hs *HandshakeServer) GetConnectionManager() *connection.Manager {
 
 // ServeStdioWithHandshake starts the server with stdio transport and handshake support.
 func ServeStdioWithHandshake(hs *HandshakeServer, opts ...server.StdioOption) error {
-	// Generate a connection ID for stdio transport
-	connectionID := "stdio-" + generateConnectionID()
-
-	// Create connection context
-	ctx := context.Background()
-	ctx, err := hs.CreateConnection(ctx, connectionID)
-	if err != nil {
-		return err
-	}
-
-	// Ensure connection is cleaned up on exit
-	defer hs.CloseConnection(connectionID)
-
 	logger := logging.Default().WithComponent("handshake")
-	logger.WithField(logging.FieldConnectionID, connectionID).Info(ctx, "Starting stdio server")
+	logger.Info(context.Background(), "Starting stdio server")
 
-	// Start the server
-	// Note: We need to pass the context with connection ID to the server
-	// This might require modification of mcp-go or a custom stdio implementation
+	// The stdio session is tracked by the session hooks; attach its
+	// connection ID to every request
+	opts = append(opts, server.WithStdioContextFunc(hs.SessionContext))
 	return ServeStdio(hs.Server, opts...)
 }
 
+// SessionContext adds the connection of the client session in ctx, or its
+// ID while it is not tracked, so handshake state applies to transports that
+// create their own sessions.
+func (hs *HandshakeServer) SessionContext(ctx context.Context) context.Context {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		if conn, ok := hs.connectionManager.GetConnection(session.SessionID()); ok {
+			return connection.WithConnection(ctx, conn)
+		}
+		ctx = connection.WithConnectionID(ctx, session.SessionID())
+	}
+	return ctx
+}
+
 // HandleMessage processes a JSON-RPC message with handshake validation.
 // This method enables request interception for pre-handshake validation.
 func (hs *HandshakeServer) HandleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
@@ -202,6 +237,15 @@ func (hs *HandshakeServer) HandleMessage(ctx context.Context, message json.RawMe
 		return mcp.NewJSONRPCError(mcp.RequestId{}, mcp.PARSE_ERROR, "Parse error", nil)
 	}
 
+	// The client confirms the handshake with notifications/initialized
+	if req.Method == "notifications/initialized" {
+		if err := conn.MarkInitialized(); err != nil {
+			logger := logging.Default().WithComponent("handshake")
+			logger.WithField(logging.FieldConnectionID, connID).Warn(ctx, "Ignoring initialized notification: "+err.Error())
+		}
+		return hs.Server.HandleMessage(ctx, message)
+	}
+
 	// Check if connection is ready for non-initialize requests
 	if req.Method != "initialize" && !conn.IsReady() {
 		logger := logging.Default().WithComponent("handshake")
@@ -215,10 +259,49 @@ func (hs *HandshakeServer) HandleMessage(ctx context.Context, message json.RawMe
 			"Initialize handshake must be completed before other requests")
 	}
 
+	// In strict mode requests also wait for notifications/initialized;
+	// ping is allowed so clients can probe the connection meanwhile
+	if hs.config.RequireInitialized && req.Method != "initialize" && req.Method != string(mcp.MethodPing) &&
+		!req.ID.IsNil() && !conn.IsInitialized() {
+		logger := logging.Default().WithComponent("handshake")
+		logger.WithFields(logging.LogFields{
+			logging.FieldMethod:          req.Method,
+			logging.FieldConnectionID:    connID,
+			logging.FieldConnectionState: "awaiting_initialized",
+		}).Warn(ctx, "Rejecting request - initialized notification not received")
+		return mcp.NewJSONRPCError(req.ID, ErrorCodeServerNotInitialized, "Not initialized",
+			"Client must send notifications/initialized before other requests")
+	}
+
+	// Reject methods whose capability was not negotiated, rather than
+	// letting them fail as unknown methods
+	if !req.ID.IsNil() {
+		if err := handlers.CheckCapability(conn, req.Method); err != nil {
+			logger := logging.Default().WithComponent("handshake")
+			logger.WithFields(logging.LogFields{
+				logging.FieldMethod:       req.Method,
+				logging.FieldConnectionID: connID,
+			}).Warn(ctx, "Rejecting request - capability not negotiated")
+			return mcp.NewJSONRPCError(req.ID, err.Code, err.Message, err.Data)
+		}
+	}
+
 	// Delegate to base server for actual handling
 	return hs.Server.HandleMessage(ctx, message)
 }
 
+// RequestSampling asks the client of the connection in ctx to sample an LLM
+// completion. It fails without a request if the client did not advertise
+// sampling.
+func (hs *HandshakeServer) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	if conn, ok := connection.ConnectionFromContext(ctx, hs.connectionManager); ok {
+		if err := handlers.CheckCapability(conn, string(mcp.MethodSamplingCreateMessage)); err != nil {
+			return nil, err
+		}
+	}
+	return hs.Server.RequestSampling(ctx, request)
+}
+
 // generateConnectionID generates a unique connection ID.
 func generateConnectionID() string {
 	// Use timestamp with nanoseconds for uniqueness
@@ -0,0 +1,209 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// HandshakeOutcome classifies how an initialize handshake ended
+type HandshakeOutcome string
+
+// Handshake outcomes
+const (
+	HandshakeSuccess HandshakeOutcome = "success"
+
+	// HandshakeVersionMismatch is an initialize proposing a protocol
+	// version outside SupportedVersions, or rejected for its version
+	HandshakeVersionMismatch HandshakeOutcome = "version_mismatch"
+
+	// HandshakeAuthFailure is an initialize rejected as unauthorized or
+	// forbidden
+	HandshakeAuthFailure HandshakeOutcome = "auth_failure"
+
+	// HandshakeTimeout is a handshake not completed within
+	// HandshakeTimeout
+	HandshakeTimeout HandshakeOutcome = "timeout"
+
+	// HandshakeMalformed is an initialize that could not be parsed or had
+	// invalid params
+	HandshakeMalformed HandshakeOutcome = "malformed"
+
+	// HandshakeError is an initialize failing for any other reason
+	HandshakeError HandshakeOutcome = "error"
+)
+
+// HandshakeOutcomes lists every outcome in reporting order
+var HandshakeOutcomes = []HandshakeOutcome{
+	HandshakeSuccess, HandshakeVersionMismatch, HandshakeAuthFailure,
+	HandshakeTimeout, HandshakeMalformed, HandshakeError,
+}
+
+// OutcomeStats counts the handshakes ending with one outcome and sums
+// their latency, from the initialize request to its response or timeout
+type OutcomeStats struct {
+	Count        int64         `json:"count"`
+	TotalLatency time.Duration `json:"totalLatency"`
+	MaxLatency   time.Duration `json:"maxLatency"`
+}
+
+// MeanLatency returns the average latency, or zero without handshakes
+func (s OutcomeStats) MeanLatency() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Count)
+}
+
+// HandshakeStats describes the handshakes served so far
+type HandshakeStats struct {
+	// Outcomes has an entry for every outcome in HandshakeOutcomes
+	Outcomes map[HandshakeOutcome]OutcomeStats `json:"outcomes"`
+}
+
+// handshakeStats accumulates HandshakeStats
+type handshakeStats struct {
+	mu       sync.Mutex
+	outcomes map[HandshakeOutcome]OutcomeStats
+	pending  map[string]time.Time
+}
+
+// begin notes that the initialize request under key started at start
+func (s *handshakeStats) begin(key string, start time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		s.pending = make(map[string]time.Time)
+	}
+	s.pending[key] = start
+}
+
+// end forgets the initialize request under key, returning when it started
+func (s *handshakeStats) end(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start, ok := s.pending[key]
+	delete(s.pending, key)
+	return start, ok
+}
+
+// record accounts a handshake ending with outcome after latency
+func (s *handshakeStats) record(outcome HandshakeOutcome, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outcomes == nil {
+		s.outcomes = make(map[HandshakeOutcome]OutcomeStats)
+	}
+	stats := s.outcomes[outcome]
+	stats.Count++
+	stats.TotalLatency += latency
+	stats.MaxLatency = max(stats.MaxLatency, latency)
+	s.outcomes[outcome] = stats
+}
+
+// GetStats returns handshake counts and latencies by outcome
+func (hs *HandshakeServer) GetStats() HandshakeStats {
+	hs.stats.mu.Lock()
+	defer hs.stats.mu.Unlock()
+	stats := HandshakeStats{Outcomes: make(map[HandshakeOutcome]OutcomeStats, len(HandshakeOutcomes))}
+	for _, outcome := range HandshakeOutcomes {
+		stats.Outcomes[outcome] = hs.stats.outcomes[outcome]
+	}
+	return stats
+}
+
+// statsHooks adds the hooks recording the outcome of every initialize
+// request to hooks, whichever transport it came over
+func (hs *HandshakeServer) statsHooks(hooks *server.Hooks) {
+	hooks.AddBeforeInitialize(func(ctx context.Context, id any, _ *mcp.InitializeRequest) {
+		hs.stats.begin(handshakeKey(ctx, id), clock.Or(hs.config.Clock).Now())
+	})
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		outcome := HandshakeSuccess
+		if !hs.supportsVersion(request.Params.ProtocolVersion) {
+			outcome = HandshakeVersionMismatch
+		}
+		hs.finish(ctx, id, outcome)
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, _ any, err error) {
+		if method != mcp.MethodInitialize {
+			return
+		}
+		outcome := HandshakeError
+		var rpcErr interface{ ToJSONRPCError() mcp.JSONRPCError }
+		if mcpErr := mcperrors.FindMCPError(err); mcpErr != nil {
+			outcome = classifyHandshakeError(mcpErr.Code)
+		} else if errors.As(err, &rpcErr) {
+			outcome = classifyHandshakeError(rpcErr.ToJSONRPCError().Error.Code)
+		}
+		hs.finish(ctx, id, outcome)
+	})
+}
+
+// finish records the outcome of the initialize request id. Latency is zero
+// for requests too malformed to reach the before hook.
+func (hs *HandshakeServer) finish(ctx context.Context, id any, outcome HandshakeOutcome) {
+	c := clock.Or(hs.config.Clock)
+	start, ok := hs.stats.end(handshakeKey(ctx, id))
+	if conn, found := connection.ConnectionFromContext(ctx, hs.connectionManager); found && conn.GetState() == connection.StateClosed {
+		// Timed out meanwhile; onTimeout recorded it
+		return
+	}
+
+	var latency time.Duration
+	if ok {
+		latency = c.Since(start)
+	}
+	hs.stats.record(outcome, latency)
+	if outcome != HandshakeSuccess {
+		connID, _ := connection.GetConnectionID(ctx)
+		logging.Default().WithComponent("handshake").WithFields(logging.LogFields{
+			logging.FieldConnectionID: connID,
+			"outcome":                 outcome,
+			"latency":                 latency,
+		}).Warn(ctx, "Handshake failed")
+	}
+}
+
+// onTimeout records the handshake of conn as timed out
+func (hs *HandshakeServer) onTimeout(conn *connection.Connection) {
+	hs.stats.record(HandshakeTimeout, conn.HandshakeTimeout)
+}
+
+// supportsVersion reports whether a proposed protocol version is among
+// SupportedVersions; any version is when none are configured
+func (hs *HandshakeServer) supportsVersion(version string) bool {
+	return len(hs.config.SupportedVersions) == 0 || slices.Contains(hs.config.SupportedVersions, version)
+}
+
+// handshakeKey identifies the initialize request id of the connection in ctx
+func handshakeKey(ctx context.Context, id any) string {
+	connID, _ := connection.GetConnectionID(ctx)
+	return fmt.Sprintf("%s\x00%v", connID, id)
+}
+
+// classifyHandshakeError returns the outcome of an initialize answered
+// with an error of code
+func classifyHandshakeError(code int) HandshakeOutcome {
+	switch code {
+	case mcp.PARSE_ERROR, mcp.INVALID_REQUEST, mcp.INVALID_PARAMS:
+		return HandshakeMalformed
+	case ErrorCodeProtocolMismatch:
+		return HandshakeVersionMismatch
+	case mcperrors.ErrorCodeMCPUnauthorized, mcperrors.ErrorCodeMCPForbidden:
+		return HandshakeAuthFailure
+	default:
+		return HandshakeError
+	}
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+func TestHandshakeServer_GetStats(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1700000000, 0))
+	config := DefaultHandshakeConfig()
+	config.Clock = fake
+	config.HandshakeTimeout = 10 * time.Second
+	config.SupportedVersions = []string{"1.0"}
+	// Clients take the time named by their name to be answered
+	config.ConfigureHooks = []func(hooks *server.Hooks){func(hooks *server.Hooks) {
+		hooks.AddBeforeInitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest) {
+			d, _ := time.ParseDuration(request.Params.ClientInfo.Name)
+			fake.Advance(d)
+		})
+	}}
+	hs := NewHandshakeServer(config)
+
+	initialize := func(connID, params string) mcp.JSONRPCMessage {
+		t.Helper()
+		ctx, err := hs.CreateConnection(context.Background(), connID)
+		if err != nil {
+			t.Fatalf("CreateConnection() error = %v", err)
+		}
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":%s}`, params)
+		return hs.HandleMessage(ctx, json.RawMessage(message))
+	}
+	params := func(version, name string) string {
+		return fmt.Sprintf(`{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":%q,"version":"1"}}`, version, name)
+	}
+
+	initialize("c1", params("1.0", "2s"))
+	initialize("c2", params("1.0", "4s"))
+	initialize("c3", params("2099-01-01", "1s"))
+	if _, ok := initialize("c4", `"bad"`).(mcp.JSONRPCError); !ok {
+		t.Fatal("malformed initialize was answered")
+	}
+	initialize("c5", params("1.0", "11s"))
+
+	stats := hs.GetStats()
+	if len(stats.Outcomes) != len(HandshakeOutcomes) {
+		t.Errorf("Outcomes = %v, want an entry per outcome", stats.Outcomes)
+	}
+	want := map[HandshakeOutcome]OutcomeStats{
+		HandshakeSuccess:         {Count: 2, TotalLatency: 6 * time.Second, MaxLatency: 4 * time.Second},
+		HandshakeVersionMismatch: {Count: 1, TotalLatency: time.Second, MaxLatency: time.Second},
+		HandshakeMalformed:       {Count: 1},
+		HandshakeTimeout:         {Count: 1, TotalLatency: 10 * time.Second, MaxLatency: 10 * time.Second},
+	}
+	for _, outcome := range HandshakeOutcomes {
+		if got := stats.Outcomes[outcome]; got != want[outcome] {
+			t.Errorf("Outcomes[%s] = %+v, want %+v", outcome, got, want[outcome])
+		}
+	}
+	if mean := stats.Outcomes[HandshakeSuccess].MeanLatency(); mean != 3*time.Second {
+		t.Errorf("MeanLatency() = %v, want 3s", mean)
+	}
+}
+
+func TestClassifyHandshakeError(t *testing.T) {
+	tests := []struct {
+		code int
+		want HandshakeOutcome
+	}{
+		{mcp.PARSE_ERROR, HandshakeMalformed},
+		{mcp.INVALID_PARAMS, HandshakeMalformed},
+		{ErrorCodeProtocolMismatch, HandshakeVersionMismatch},
+		{mcperrors.ErrorCodeMCPUnauthorized, HandshakeAuthFailure},
+		{mcp.INTERNAL_ERROR, HandshakeError},
+	}
+	for _, tt := range tests {
+		if got := classifyHandshakeError(tt.code); got != tt.want {
+			t.Errorf("classifyHandshakeError(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
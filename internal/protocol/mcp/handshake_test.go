@@ -297,3 +297,74 @@ func TestHandleMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleMessage_RequireInitialized(t *testing.T) {
+	config := DefaultHandshakeConfig()
+	config.RequireInitialized = true
+	hs := NewHandshakeServer(config)
+
+	conn, _ := hs.connectionManager.CreateConnection("strict")
+	conn.State = connection.StateReady
+	ctx := connection.WithConnectionID(context.Background(), "strict")
+
+	errorCode := func(result mcp.JSONRPCMessage) int {
+		if errResp, ok := result.(mcp.JSONRPCError); ok {
+			return errResp.Error.Code
+		}
+		return 0
+	}
+
+	// Requests wait for the initialized notification, except ping
+	if code := errorCode(hs.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"tools/list","id":1}`))); code != ErrorCodeServerNotInitialized {
+		t.Errorf("Expected error code %d before initialized, got %d", ErrorCodeServerNotInitialized, code)
+	}
+	if code := errorCode(hs.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"ping","id":2}`))); code != 0 {
+		t.Errorf("Expected ping to be served before initialized, got error code %d", code)
+	}
+
+	hs.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if !conn.IsInitialized() {
+		t.Fatal("Expected the connection to be initialized")
+	}
+	if code := errorCode(hs.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"tools/list","id":3}`))); code == ErrorCodeServerNotInitialized {
+		t.Error("Expected tools/list to be served after initialized")
+	}
+
+	// Lenient servers serve requests as soon as the handshake completes
+	lenient := NewHandshakeServer(DefaultHandshakeConfig())
+	conn, _ = lenient.connectionManager.CreateConnection("lenient")
+	conn.State = connection.StateReady
+	ctx = connection.WithConnectionID(context.Background(), "lenient")
+	if code := errorCode(lenient.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"tools/list","id":4}`))); code == ErrorCodeServerNotInitialized {
+		t.Error("Expected a lenient server to serve tools/list without initialized")
+	}
+}
+
+func TestHandleMessage_CapabilityNotSupported(t *testing.T) {
+	config := DefaultHandshakeConfig()
+	config.SupportedVersions = mcp.ValidProtocolVersions
+	config.ServerOptions = []server.ServerOption{WithResourceCapabilities(false, true)}
+	hs := NewHandshakeServer(config)
+
+	ctx, err := hs.CreateConnection(context.Background(), "caps")
+	if err != nil {
+		t.Fatalf("CreateConnection failed: %v", err)
+	}
+	result := hs.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+
+		mcp.LATEST_PROTOCOL_VERSION+`","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+	if _, ok := result.(mcp.JSONRPCResponse); !ok {
+		t.Fatalf("Expected an initialize response, got %+v", result)
+	}
+
+	// The server did not advertise subscribe
+	result = hs.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"test://a"}}`))
+	errResp, ok := result.(mcp.JSONRPCError)
+	if !ok || errResp.Error.Code != ErrorCodeCapabilityNotSupported {
+		t.Errorf("Expected error code %d, got %+v", ErrorCodeCapabilityNotSupported, result)
+	}
+
+	// The client did not advertise sampling
+	if _, err := hs.RequestSampling(ctx, mcp.CreateMessageRequest{}); err == nil {
+		t.Error("Expected sampling to be refused")
+	}
+}
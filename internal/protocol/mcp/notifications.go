@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Notification is a standard MCP notification, ready for the mcp-go
+// server's SendNotificationToClient and SendNotificationToAllClients
+type Notification struct {
+	Method string
+	Params map[string]any
+}
+
+// ErrInvalidNotification is returned by Validate for malformed
+// notifications
+var ErrInvalidNotification = errors.New("invalid notification")
+
+// NewProgressNotification reports progress on the request that carried
+// token in _meta.progressToken. total and message are omitted when zero.
+func NewProgressNotification(token mcp.ProgressToken, progress, total float64, message string) Notification {
+	params := map[string]any{"progressToken": token, "progress": progress}
+	if total != 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	return Notification{Method: MethodNotificationProgress, Params: params}
+}
+
+// NewResourceUpdatedNotification announces that the resource at uri changed
+func NewResourceUpdatedNotification(uri string) Notification {
+	return Notification{Method: MethodNotificationResourceUpdated, Params: map[string]any{"uri": uri}}
+}
+
+// NewResourcesListChangedNotification announces that the list of resources
+// changed
+func NewResourcesListChangedNotification() Notification {
+	return Notification{Method: MethodNotificationResourcesChanged}
+}
+
+// NewToolsListChangedNotification announces that the list of tools changed
+func NewToolsListChangedNotification() Notification {
+	return Notification{Method: MethodNotificationToolsChanged}
+}
+
+// NewPromptsListChangedNotification announces that the list of prompts
+// changed
+func NewPromptsListChangedNotification() Notification {
+	return Notification{Method: MethodNotificationPromptsChanged}
+}
+
+// NewLogMessageNotification sends a log message to the client. logger names
+// its source and is omitted when empty.
+func NewLogMessageNotification(level mcp.LoggingLevel, logger string, data any) Notification {
+	params := map[string]any{"level": level, "data": data}
+	if logger != "" {
+		params["logger"] = logger
+	}
+	return Notification{Method: MethodNotificationMessage, Params: params}
+}
+
+// NewCancelledNotification cancels the request requestID. reason is
+// omitted when empty.
+func NewCancelledNotification(requestID mcp.RequestId, reason string) Notification {
+	params := map[string]any{"requestId": requestID}
+	if reason != "" {
+		params["reason"] = reason
+	}
+	return Notification{Method: MethodNotificationCancelled, Params: params}
+}
+
+// Validate checks that n is a well-formed standard notification
+func (n Notification) Validate() error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidNotification, n.Method, fmt.Sprintf(format, args...))
+	}
+
+	switch n.Method {
+	case MethodNotificationProgress:
+		if !isRequestID(n.Params["progressToken"]) {
+			return invalid("progressToken must be a string or an integer")
+		}
+		progress, ok := n.Params["progress"].(float64)
+		if !ok {
+			return invalid("progress must be a number")
+		}
+		if total, ok := n.Params["total"]; ok {
+			if total, ok := total.(float64); !ok || total < progress {
+				return invalid("total must be a number no less than progress")
+			}
+		}
+	case MethodNotificationResourceUpdated:
+		if uri, _ := n.Params["uri"].(string); uri == "" {
+			return invalid("uri is required")
+		}
+	case MethodNotificationResourcesChanged, MethodNotificationToolsChanged, MethodNotificationPromptsChanged:
+		if len(n.Params) > 0 {
+			return invalid("takes no params")
+		}
+	case MethodNotificationMessage:
+		if !isLoggingLevel(n.Params["level"]) {
+			return invalid("unknown level %v", n.Params["level"])
+		}
+		if _, ok := n.Params["data"]; !ok {
+			return invalid("data is required")
+		}
+	case MethodNotificationCancelled:
+		if !isRequestID(n.Params["requestId"]) {
+			return invalid("requestId must be a string or an integer")
+		}
+	default:
+		return fmt.Errorf("%w: %s is not a standard notification", ErrInvalidNotification, n.Method)
+	}
+	return nil
+}
+
+// isRequestID reports whether v is a string or an integer, the types of
+// request IDs and progress tokens
+func isRequestID(v any) bool {
+	switch id := v.(type) {
+	case mcp.RequestId:
+		return !id.IsNil()
+	case string:
+		return true
+	case int, int32, int64:
+		return true
+	case float64:
+		return id == math.Trunc(id)
+	}
+	return false
+}
+
+// isLoggingLevel reports whether v is one of the MCP log levels
+func isLoggingLevel(v any) bool {
+	level, ok := v.(mcp.LoggingLevel)
+	if s, isString := v.(string); isString {
+		level, ok = mcp.LoggingLevel(s), true
+	}
+	if !ok {
+		return false
+	}
+	switch level {
+	case mcp.LoggingLevelDebug, mcp.LoggingLevelInfo, mcp.LoggingLevelNotice, mcp.LoggingLevelWarning,
+		mcp.LoggingLevelError, mcp.LoggingLevelCritical, mcp.LoggingLevelAlert, mcp.LoggingLevelEmergency:
+		return true
+	}
+	return false
+}
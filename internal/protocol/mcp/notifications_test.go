@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNotificationConstructors(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      Notification
+		method string
+		params string
+	}{
+		{
+			name:   "progress",
+			n:      NewProgressNotification("token", 1, 4, "copying"),
+			method: "notifications/progress",
+			params: `{"progressToken":"token","progress":1,"total":4,"message":"copying"}`,
+		},
+		{
+			name:   "progress without total",
+			n:      NewProgressNotification(7, 0.5, 0, ""),
+			method: "notifications/progress",
+			params: `{"progressToken":7,"progress":0.5}`,
+		},
+		{
+			name:   "resource updated",
+			n:      NewResourceUpdatedNotification("file:///a.txt"),
+			method: "notifications/resources/updated",
+			params: `{"uri":"file:///a.txt"}`,
+		},
+		{
+			name:   "resources list changed",
+			n:      NewResourcesListChangedNotification(),
+			method: "notifications/resources/list_changed",
+			params: `null`,
+		},
+		{
+			name:   "tools list changed",
+			n:      NewToolsListChangedNotification(),
+			method: "notifications/tools/list_changed",
+			params: `null`,
+		},
+		{
+			name:   "prompts list changed",
+			n:      NewPromptsListChangedNotification(),
+			method: "notifications/prompts/list_changed",
+			params: `null`,
+		},
+		{
+			name:   "log message",
+			n:      NewLogMessageNotification(mcp.LoggingLevelWarning, "upstream", map[string]any{"msg": "slow"}),
+			method: "notifications/message",
+			params: `{"level":"warning","logger":"upstream","data":{"msg":"slow"}}`,
+		},
+		{
+			name:   "cancelled",
+			n:      NewCancelledNotification(mcp.NewRequestId(int64(3)), "timed out"),
+			method: "notifications/cancelled",
+			params: `{"requestId":3,"reason":"timed out"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.n.Method != tt.method {
+				t.Errorf("Method = %q, want %q", tt.n.Method, tt.method)
+			}
+			if err := tt.n.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+
+			data, err := json.Marshal(tt.n.Params)
+			if err != nil {
+				t.Fatalf("Marshal() = %v", err)
+			}
+			var got, want any
+			_ = json.Unmarshal(data, &got)
+			_ = json.Unmarshal([]byte(tt.params), &want)
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("Params = %s, want %s", gotJSON, wantJSON)
+			}
+
+			// Decoded params, as received from the wire, validate too
+			decoded := Notification{Method: tt.n.Method}
+			_ = json.Unmarshal(data, &decoded.Params)
+			if err := decoded.Validate(); err != nil {
+				t.Errorf("Validate() after decoding = %v", err)
+			}
+		})
+	}
+}
+
+func TestNotificationValidate_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Notification
+	}{
+		{"unknown method", Notification{Method: "notifications/unknown"}},
+		{"progress without token", Notification{Method: MethodNotificationProgress, Params: map[string]any{"progress": 1.0}}},
+		{"progress with fractional token", NewProgressNotification(1.5, 1, 0, "")},
+		{"progress beyond total", NewProgressNotification("t", 5, 4, "")},
+		{"resource updated without uri", NewResourceUpdatedNotification("")},
+		{"list changed with params", Notification{Method: MethodNotificationToolsChanged, Params: map[string]any{"x": 1}}},
+		{"unknown log level", NewLogMessageNotification("verbose", "", "hi")},
+		{"log message without data", Notification{Method: MethodNotificationMessage, Params: map[string]any{"level": "info"}}},
+		{"cancelled without request", NewCancelledNotification(mcp.RequestId{}, "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.n.Validate(); !errors.Is(err, ErrInvalidNotification) {
+				t.Errorf("Validate() = %v, want ErrInvalidNotification", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// progressTokenKey is the context key of the progress token of a request
+type progressTokenKey struct{}
+
+// WithProgressToken adds the progress token of the request being handled
+// to ctx
+func WithProgressToken(ctx context.Context, token mcp.ProgressToken) context.Context {
+	return context.WithValue(ctx, progressTokenKey{}, token)
+}
+
+// ProgressTokenFromContext returns the progress token added by
+// WithProgressToken. The result is false when the request asked for no
+// progress.
+func ProgressTokenFromContext(ctx context.Context) (mcp.ProgressToken, bool) {
+	token := ctx.Value(progressTokenKey{})
+	return token, token != nil
+}
+
+// ProgressTokenFromParams returns _meta.progressToken of request params,
+// or nil when there is none
+func ProgressTokenFromParams(params json.RawMessage) mcp.ProgressToken {
+	if len(params) == 0 {
+		return nil
+	}
+	var p struct {
+		Meta struct {
+			ProgressToken mcp.ProgressToken `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	return p.Meta.ProgressToken
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -22,7 +23,8 @@ type asyncRequest struct {
 	ctx           context.Context
 	request       *jsonrpc.Request
 	correlationID string
-	responseChan  chan<- *jsonrpc.Response
+	// release runs once the request is done with
+	release func()
 }
 
 // AsyncRouter provides asynchronous request handling with correlation
@@ -140,16 +142,25 @@ func (ar *AsyncRouter) processRequest(asyncReq asyncRequest) {
 		handler = ar.middleware.Then(ar.Router)
 	}
 
-	// Handle the request
-	response := handler.Handle(asyncReq.ctx, asyncReq.request)
+	// Handle the request, converting panics so the worker survives
+	response := ar.safeHandle(handler, asyncReq)
 
-	// Send response
-	select {
-	case asyncReq.responseChan <- response:
-		// Response sent successfully
-	case <-asyncReq.ctx.Done():
-		// Context cancelled, response discarded
-	}
+	// Fails if the request already timed out or was canceled, in which case
+	// the response is discarded
+	ar.tracker.Complete(asyncReq.correlationID, response)
+	asyncReq.release()
+}
+
+// safeHandle runs handler, converting a panic into an error response
+func (ar *AsyncRouter) safeHandle(handler Handler, asyncReq asyncRequest) (response *jsonrpc.Response) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := mcperrors.FromPanic(r).WithContext("correlation_id", asyncReq.correlationID)
+			mcperrors.LogMCPError(asyncReq.ctx, panicErr, mcperrors.LogLevelError, "Async handler panicked")
+			response = jsonrpc.NewErrorResponse(panicErr.ToJSONRPCError(), asyncReq.request.ID)
+		}
+	}()
+	return handler.Handle(asyncReq.ctx, asyncReq.request)
 }
 
 // HandleAsync handles a request asynchronously and returns a correlation ID
@@ -173,40 +184,30 @@ func (ar *AsyncRouter) HandleAsync(ctx context.Context, request *jsonrpc.Request
 		rc.CorrelationID = correlationID
 	}
 
-	// Create response channel
-	responseChan := make(chan *jsonrpc.Response, 1)
+	// Register for correlation tracking BEFORE queuing
+	ar.tracker.Register(correlationID)
+
+	// A canceled request fails without waiting for its worker. Unlike a
+	// goroutine per request, AfterFunc costs nothing until ctx is done.
+	stop := context.AfterFunc(ctx, func() {
+		ar.tracker.CompleteWithError(correlationID, ctx.Err())
+	})
 
 	// Create async request
 	asyncReq := asyncRequest{
 		ctx:           ctx,
 		request:       request,
 		correlationID: correlationID,
-		responseChan:  responseChan,
-	}
-
-	// Register for correlation tracking BEFORE queuing
-	ar.tracker.Register(correlationID)
-
-	// Start goroutine to wait for response BEFORE queuing
-	go func() {
-		defer func() {
+		release: func() {
+			stop()
 			// If context has a cancel function in metadata, call it
-			if rc, ok := GetRequestContext(ctx); ok {
-				if cancelFn, ok := rc.GetMetadata("_cancel"); ok {
-					if cancel, ok := cancelFn.(context.CancelFunc); ok {
-						cancel()
-					}
+			if cancelFn, ok := rc.GetMetadata("_cancel"); ok {
+				if cancel, ok := cancelFn.(context.CancelFunc); ok {
+					cancel()
 				}
 			}
-		}()
-
-		select {
-		case response := <-responseChan:
-			ar.tracker.Complete(correlationID, response)
-		case <-ctx.Done():
-			ar.tracker.CompleteWithError(correlationID, ctx.Err())
-		}
-	}()
+		},
+	}
 
 	// Try to queue request AFTER setting up response handling
 	select {
@@ -216,7 +217,7 @@ func (ar *AsyncRouter) HandleAsync(ctx context.Context, request *jsonrpc.Request
 	default:
 		// Queue full - clean up
 		ar.tracker.Cancel(correlationID)
-		close(responseChan)
+		asyncReq.release()
 		return "", ErrQueueFull
 	}
 }
@@ -247,7 +248,10 @@ func (ar *AsyncRouter) GetResponse(correlationID string, timeout time.Duration)
 	return ar.tracker.WaitForResponse(correlationID, timeout)
 }
 
-// HandleAsyncWithCallback handles a request asynchronously and calls the callback with the response
+// HandleAsyncWithCallback handles a request asynchronously and calls the
+// callback with the response. The callback runs on the goroutine that
+// completes the request, a worker or the tracker's deadline dispatcher, so it
+// must not block.
 func (ar *AsyncRouter) HandleAsyncWithCallback(ctx context.Context, request *jsonrpc.Request, callback func(*jsonrpc.Response, error)) error {
 	correlationID, err := ar.HandleAsync(ctx, request)
 	if err != nil {
@@ -260,13 +264,11 @@ func (ar *AsyncRouter) HandleAsyncWithCallback(ctx context.Context, request *jso
 		timeout = rc.Timeout
 	}
 
-	// Wait for response in background
-	go func() {
-		response, err := ar.GetResponse(correlationID, timeout)
-		callback(response, err)
-	}()
-
-	return nil
+	// The tracker delivers the result, so no goroutine waits for it
+	if err := ar.tracker.SetDeadline(correlationID, time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	return ar.tracker.OnComplete(correlationID, callback)
 }
 
 // Handle implements the Handler interface for synchronous compatibility
@@ -343,6 +345,7 @@ func (ar *AsyncRouter) Shutdown(ctx context.Context) error {
 // Stats returns statistics about the async router
 type AsyncRouterStats struct {
 	QueuedRequests  int
+	QueueCapacity   int
 	PendingRequests int
 	Workers         int
 	Running         bool
@@ -357,8 +360,15 @@ func (ar *AsyncRouter) Stats() AsyncRouterStats {
 
 	return AsyncRouterStats{
 		QueuedRequests:  len(ar.requestChan),
+		QueueCapacity:   ar.queueSize,
 		PendingRequests: trackerStats.PendingCount,
 		Workers:         ar.workers,
 		Running:         ar.running,
 	}
 }
+
+// PendingCorrelationIDs returns the correlation IDs of requests awaiting a
+// response
+func (ar *AsyncRouter) PendingCorrelationIDs() []string {
+	return ar.tracker.PendingIDs()
+}
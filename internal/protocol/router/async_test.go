@@ -3,11 +3,13 @@ package router
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -332,6 +334,75 @@ func TestAsyncRouterQueueFull(t *testing.T) {
 	}
 }
 
+func TestAsyncRouterPendingWithoutGoroutines(t *testing.T) {
+	release := make(chan struct{})
+	baseRouter := New()
+	baseRouter.RegisterFunc("test.wait", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		<-release
+		return &jsonrpc.Response{ID: req.ID}
+	})
+
+	const n = 10000
+	ar := NewAsyncRouter(AsyncRouterConfig{
+		Router:    baseRouter,
+		Workers:   1,
+		QueueSize: n,
+	})
+	if err := ar.Start(); err != nil {
+		t.Fatalf("Failed to start router: %v", err)
+	}
+	defer ar.Shutdown(context.Background())
+
+	before := runtime.NumGoroutine()
+
+	// Half the requests wait through callbacks, half through cancelable
+	// contexts; neither may start a goroutine while pending
+	ctx, cancel := context.WithCancel(context.Background())
+	var done sync.WaitGroup
+	var failed atomic.Int32
+	var canceledID string
+	done.Add(n / 2)
+	for i := 0; i < n; i++ {
+		req := &jsonrpc.Request{ID: i, Method: "test.wait"}
+		if i%2 == 0 {
+			err := ar.HandleAsyncWithCallback(context.Background(), req, func(resp *jsonrpc.Response, err error) {
+				if err != nil {
+					failed.Add(1)
+				}
+				done.Done()
+			})
+			if err != nil {
+				t.Fatalf("HandleAsyncWithCallback failed: %v", err)
+			}
+			continue
+		}
+		correlationID, err := ar.HandleAsync(ctx, req)
+		if err != nil {
+			t.Fatalf("HandleAsync failed: %v", err)
+		}
+		canceledID = correlationID
+	}
+
+	if delta := runtime.NumGoroutine() - before; delta > 0 {
+		t.Errorf("Expected no extra goroutines for %d pending requests, got %d", n, delta)
+	}
+	if stats := ar.Stats(); stats.PendingRequests != n {
+		t.Errorf("Expected %d pending requests, got %d", n, stats.PendingRequests)
+	}
+
+	// Canceling fails the requests at once, before a worker reaches them
+	cancel()
+	if _, err := ar.GetResponse(canceledID, time.Second); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	done.Wait()
+	if failed.Load() != 0 {
+		t.Errorf("Expected all callbacks to succeed, %d failed", failed.Load())
+	}
+}
+
 func TestAsyncRouterWithMiddleware(t *testing.T) {
 	baseRouter := New()
 	baseRouter.RegisterFunc("test.method", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
@@ -506,3 +577,41 @@ func BenchmarkAsyncRouterSynchronousHandle(b *testing.B) {
 		}
 	}
 }
+
+func TestAsyncRouterRecoversPanics(t *testing.T) {
+	baseRouter := New()
+	baseRouter.RegisterFunc("test.panic", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	})
+
+	ar := NewAsyncRouter(AsyncRouterConfig{
+		Router:    baseRouter,
+		Workers:   1,
+		QueueSize: 10,
+	})
+	if err := ar.Start(); err != nil {
+		t.Fatalf("Failed to start router: %v", err)
+	}
+	defer ar.Shutdown(context.Background())
+
+	// The single worker must survive both panics
+	for i := 0; i < 2; i++ {
+		correlationID, err := ar.HandleAsync(context.Background(), &jsonrpc.Request{ID: i, Method: "test.panic"})
+		if err != nil {
+			t.Fatalf("HandleAsync failed: %v", err)
+		}
+
+		resp, err := ar.GetResponse(correlationID, time.Second)
+		if err != nil {
+			t.Fatalf("GetResponse failed: %v", err)
+		}
+		if resp.Error == nil || resp.Error.Code != mcperrors.ErrorCodeMCPSystem {
+			t.Fatalf("Expected system error, got %+v", resp.Error)
+		}
+		if data, _ := resp.Error.Data.(map[string]interface{}); data[mcperrors.PanicTypeKey] != mcperrors.PanicNilMap {
+			t.Errorf("Expected panic type %q, got %v", mcperrors.PanicNilMap, resp.Error.Data)
+		}
+	}
+}
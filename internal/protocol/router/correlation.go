@@ -1,11 +1,13 @@
 package router
 
 import (
+	"container/heap"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -15,6 +17,13 @@ var (
 
 	// ErrCorrelationTimeout is returned when waiting for a response times out
 	ErrCorrelationTimeout = errors.New("correlation timeout")
+
+	// ErrCorrelationCanceled is returned when a correlation is canceled
+	// before it completes
+	ErrCorrelationCanceled = errors.New("correlation canceled")
+
+	// errCorrelationCompleted is returned when a correlation is completed twice
+	errCorrelationCompleted = errors.New("correlation already completed")
 )
 
 // responseChannel holds a response and any error
@@ -23,6 +32,20 @@ type responseChannel struct {
 	error    chan error
 	closed   bool
 	mu       sync.Mutex
+
+	// completed is set by the first result, so later ones are rejected and
+	// the buffered channels never block
+	completed bool
+
+	// callback, if set, receives the result instead of the channels
+	callback func(*jsonrpc.Response, error)
+
+	// id, deadline and index place the correlation in the deadline heap;
+	// index is -1 when it has no deadline. Guarded by the tracker's
+	// deadlinesMu.
+	id       string
+	deadline time.Time
+	index    int
 }
 
 // safeClose safely closes the channels if not already closed
@@ -37,38 +60,72 @@ func (rc *responseChannel) safeClose() {
 	}
 }
 
-// CorrelationTracker manages request/response correlation for async operations
+// deadlineHeap orders correlations by deadline for container/heap
+type deadlineHeap []*responseChannel
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *deadlineHeap) Push(x any) {
+	rc := x.(*responseChannel)
+	rc.index = len(*h)
+	*h = append(*h, rc)
+}
+
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	rc := old[len(old)-1]
+	old[len(old)-1] = nil
+	rc.index = -1
+	*h = old[:len(old)-1]
+	return rc
+}
+
+// CorrelationTracker manages request/response correlation for async
+// operations. Deadlines are kept in one heap served by a single dispatcher
+// goroutine, so pending correlations cost no goroutine or timer each, and
+// tens of thousands of them can wait at once.
 type CorrelationTracker struct {
 	// pending maps correlation IDs to response channels
 	pending sync.Map
 
-	// cleanupInterval specifies how often to clean up expired entries
-	cleanupInterval time.Duration
+	// deadlines holds the correlations that expire, earliest first
+	deadlinesMu sync.Mutex
+	deadlines   deadlineHeap
+
+	// wake tells the dispatcher the earliest deadline changed
+	wake chan struct{}
 
 	// done signals shutdown
 	done chan struct{}
 
-	// wg tracks cleanup goroutine
+	// wg tracks the dispatcher goroutine
 	wg sync.WaitGroup
 }
 
 // NewCorrelationTracker creates a new CorrelationTracker
 func NewCorrelationTracker() *CorrelationTracker {
 	ct := &CorrelationTracker{
-		cleanupInterval: 30 * time.Second,
-		done:            make(chan struct{}),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
 	}
 
-	// Start cleanup goroutine
+	// Start the deadline dispatcher
 	ct.wg.Add(1)
-	go ct.cleanupLoop()
+	go ct.dispatch()
 
 	return ct
 }
 
-// GenerateCorrelationID creates a new unique correlation ID
+// GenerateCorrelationID creates a new unique, time-ordered correlation ID
 func (ct *CorrelationTracker) GenerateCorrelationID() string {
-	return uuid.New().String()
+	return ids.NewUUIDv7()
 }
 
 // Register registers a new correlation ID and returns channels for the response
@@ -76,6 +133,8 @@ func (ct *CorrelationTracker) Register(correlationID string) (<-chan *jsonrpc.Re
 	respChan := &responseChannel{
 		response: make(chan *jsonrpc.Response, 1),
 		error:    make(chan error, 1),
+		id:       correlationID,
+		index:    -1,
 	}
 
 	ct.pending.Store(correlationID, respChan)
@@ -96,34 +155,63 @@ func (ct *CorrelationTracker) GetSendChannels(correlationID string) (chan<- *jso
 
 // Complete completes a correlation with a response
 func (ct *CorrelationTracker) Complete(correlationID string, response *jsonrpc.Response) error {
+	return ct.complete(correlationID, response, nil)
+}
+
+// CompleteWithError completes a correlation with an error
+func (ct *CorrelationTracker) CompleteWithError(correlationID string, err error) error {
+	return ct.complete(correlationID, nil, err)
+}
+
+// complete completes the correlation registered under correlationID
+func (ct *CorrelationTracker) complete(correlationID string, response *jsonrpc.Response, err error) error {
 	value, ok := ct.pending.Load(correlationID)
 	if !ok {
 		return ErrCorrelationNotFound
 	}
+	return ct.deliver(value.(*responseChannel), response, err)
+}
 
-	respChan := value.(*responseChannel)
-
+// deliver passes the result of a correlation to its callback or, without
+// one, buffers it for WaitForResponse. Only the first result is delivered.
+func (ct *CorrelationTracker) deliver(respChan *responseChannel, response *jsonrpc.Response, err error) error {
 	// Use mutex to coordinate with safeClose()
 	respChan.mu.Lock()
-	defer respChan.mu.Unlock()
-
 	if respChan.closed {
+		respChan.mu.Unlock()
 		return errors.New("response channel already closed")
 	}
+	if respChan.completed {
+		respChan.mu.Unlock()
+		return errCorrelationCompleted
+	}
+	respChan.completed = true
+
+	callback := respChan.callback
+	if callback == nil {
+		// The channels are buffered and written once, so this cannot block
+		if err != nil {
+			respChan.error <- err
+		} else {
+			respChan.response <- response
+		}
+	}
+	respChan.mu.Unlock()
 
-	select {
-	case respChan.response <- response:
-		// ✅ FIXED: Don't close channels here - let consumer handle cleanup
-	default:
-		// Channel already closed or full
-		return errors.New("response channel blocked")
+	ct.unschedule(respChan)
+	if callback != nil {
+		ct.remove(respChan)
+		callback(response, err)
 	}
 
 	return nil
 }
 
-// CompleteWithError completes a correlation with an error
-func (ct *CorrelationTracker) CompleteWithError(correlationID string, err error) error {
+// OnComplete arranges for callback to receive the result of a correlation
+// instead of WaitForResponse. It runs on the goroutine that completes the
+// correlation, which may be the deadline dispatcher, so it must not block. A
+// correlation that already completed calls back immediately.
+func (ct *CorrelationTracker) OnComplete(correlationID string, callback func(*jsonrpc.Response, error)) error {
 	value, ok := ct.pending.Load(correlationID)
 	if !ok {
 		return ErrCorrelationNotFound
@@ -131,26 +219,83 @@ func (ct *CorrelationTracker) CompleteWithError(correlationID string, err error)
 
 	respChan := value.(*responseChannel)
 
-	// Use mutex to coordinate with safeClose()
 	respChan.mu.Lock()
-	defer respChan.mu.Unlock()
-
 	if respChan.closed {
-		return errors.New("error channel already closed")
+		respChan.mu.Unlock()
+		return ErrCorrelationCanceled
+	}
+	if !respChan.completed {
+		respChan.callback = callback
+		respChan.mu.Unlock()
+		return nil
 	}
+	respChan.mu.Unlock()
 
-	select {
-	case respChan.error <- err:
-		// ✅ FIXED: Don't close channels here - let consumer handle cleanup
+	response, err := ct.receive(respChan)
+	callback(response, err)
+	return nil
+}
+
+// SetDeadline fails the correlation with ErrCorrelationTimeout if it has not
+// completed by deadline. An earlier deadline already set is kept.
+func (ct *CorrelationTracker) SetDeadline(correlationID string, deadline time.Time) error {
+	value, ok := ct.pending.Load(correlationID)
+	if !ok {
+		return ErrCorrelationNotFound
+	}
+
+	respChan := value.(*responseChannel)
+
+	ct.deadlinesMu.Lock()
+	switch {
+	case respChan.index < 0:
+		respChan.deadline = deadline
+		heap.Push(&ct.deadlines, respChan)
+	case deadline.Before(respChan.deadline):
+		respChan.deadline = deadline
+		heap.Fix(&ct.deadlines, respChan.index)
 	default:
-		// Channel already closed or full
-		return errors.New("error channel blocked")
+		ct.deadlinesMu.Unlock()
+		return nil
+	}
+	earliest := respChan.index == 0
+	ct.deadlinesMu.Unlock()
+
+	if earliest {
+		select {
+		case ct.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	// A correlation that completed meanwhile must not stay in the heap
+	respChan.mu.Lock()
+	completed := respChan.completed || respChan.closed
+	respChan.mu.Unlock()
+	if completed {
+		ct.unschedule(respChan)
 	}
 
 	return nil
 }
 
-// Cancel cancels a pending correlation
+// unschedule removes a correlation from the deadline heap
+func (ct *CorrelationTracker) unschedule(respChan *responseChannel) {
+	ct.deadlinesMu.Lock()
+	if respChan.index >= 0 {
+		heap.Remove(&ct.deadlines, respChan.index)
+	}
+	ct.deadlinesMu.Unlock()
+}
+
+// remove deletes a correlation and closes its channels
+func (ct *CorrelationTracker) remove(respChan *responseChannel) {
+	ct.pending.CompareAndDelete(respChan.id, respChan)
+	respChan.safeClose()
+}
+
+// Cancel cancels a pending correlation. A registered callback receives
+// ErrCorrelationCanceled.
 func (ct *CorrelationTracker) Cancel(correlationID string) {
 	value, ok := ct.pending.LoadAndDelete(correlationID)
 	if !ok {
@@ -158,10 +303,24 @@ func (ct *CorrelationTracker) Cancel(correlationID string) {
 	}
 
 	respChan := value.(*responseChannel)
+
+	respChan.mu.Lock()
+	var callback func(*jsonrpc.Response, error)
+	if !respChan.completed {
+		respChan.completed = true
+		callback = respChan.callback
+	}
+	respChan.mu.Unlock()
+
+	ct.unschedule(respChan)
 	respChan.safeClose()
+	if callback != nil {
+		callback(nil, ErrCorrelationCanceled)
+	}
 }
 
-// WaitForResponse waits for a response with the given correlation ID
+// WaitForResponse waits for a response with the given correlation ID. The
+// timeout is served by the deadline dispatcher rather than a timer per call.
 func (ct *CorrelationTracker) WaitForResponse(correlationID string, timeout time.Duration) (*jsonrpc.Response, error) {
 	value, ok := ct.pending.Load(correlationID)
 	if !ok {
@@ -171,59 +330,77 @@ func (ct *CorrelationTracker) WaitForResponse(correlationID string, timeout time
 	respChan := value.(*responseChannel)
 
 	if timeout > 0 {
-		timer := time.NewTimer(timeout)
-		defer timer.Stop()
-
-		select {
-		case response := <-respChan.response:
-			respChan.safeClose()             // ✅ FIXED: Close channels after consuming response
-			ct.pending.Delete(correlationID) // ✅ FIXED: Delete after consuming response
-			return response, nil
-		case err := <-respChan.error:
-			respChan.safeClose()             // ✅ FIXED: Close channels after consuming error
-			ct.pending.Delete(correlationID) // ✅ FIXED: Delete after consuming error
+		if err := ct.SetDeadline(correlationID, time.Now().Add(timeout)); err != nil {
 			return nil, err
-		case <-timer.C:
-			ct.Cancel(correlationID) // Cancel already handles deletion and closing
-			return nil, ErrCorrelationTimeout
 		}
 	}
 
-	// No timeout, wait indefinitely
+	return ct.receive(respChan)
+}
+
+// receive waits for the result of a correlation and removes it
+func (ct *CorrelationTracker) receive(respChan *responseChannel) (*jsonrpc.Response, error) {
+	defer ct.remove(respChan)
+
 	select {
-	case response := <-respChan.response:
-		respChan.safeClose()             // ✅ FIXED: Close channels after consuming response
-		ct.pending.Delete(correlationID) // ✅ FIXED: Delete after consuming response
+	case response, ok := <-respChan.response:
+		if !ok {
+			return nil, ErrCorrelationCanceled
+		}
 		return response, nil
-	case err := <-respChan.error:
-		respChan.safeClose()             // ✅ FIXED: Close channels after consuming error
-		ct.pending.Delete(correlationID) // ✅ FIXED: Delete after consuming error
+	case err, ok := <-respChan.error:
+		if !ok {
+			return nil, ErrCorrelationCanceled
+		}
 		return nil, err
 	}
 }
 
-// cleanupLoop periodically cleans up leaked correlations
-func (ct *CorrelationTracker) cleanupLoop() {
+// dispatch fails correlations as their deadlines pass, sleeping until the
+// earliest one
+func (ct *CorrelationTracker) dispatch() {
 	defer ct.wg.Done()
 
-	ticker := time.NewTicker(ct.cleanupInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
 
 	for {
+		if next := ct.expire(time.Now()); !next.IsZero() {
+			timer.Reset(time.Until(next))
+		}
+
 		select {
-		case <-ticker.C:
-			ct.cleanup()
+		case <-timer.C:
+		case <-ct.wake:
+			timer.Stop()
 		case <-ct.done:
 			return
 		}
 	}
 }
 
-// cleanup removes expired entries
-func (ct *CorrelationTracker) cleanup() {
-	// In a production system, we would track creation time
-	// and remove entries older than a threshold
-	// For now, this is a placeholder
+// expire fails the correlations whose deadline is not after now and returns
+// the next deadline, or the zero time if none is left
+func (ct *CorrelationTracker) expire(now time.Time) time.Time {
+	var expired []*responseChannel
+	var next time.Time
+
+	ct.deadlinesMu.Lock()
+	for len(ct.deadlines) > 0 {
+		if ct.deadlines[0].deadline.After(now) {
+			next = ct.deadlines[0].deadline
+			break
+		}
+		expired = append(expired, heap.Pop(&ct.deadlines).(*responseChannel))
+	}
+	ct.deadlinesMu.Unlock()
+
+	for _, respChan := range expired {
+		ct.deliver(respChan, nil, ErrCorrelationTimeout)
+	}
+
+	return next
 }
 
 // Shutdown gracefully shuts down the correlation tracker
@@ -239,6 +416,17 @@ func (ct *CorrelationTracker) Shutdown() {
 	})
 }
 
+// PendingIDs returns the correlation IDs awaiting a response
+func (ct *CorrelationTracker) PendingIDs() []string {
+	var ids []string
+	ct.pending.Range(func(key, _ interface{}) bool {
+		ids = append(ids, key.(string))
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
 // Stats returns statistics about the correlation tracker
 type CorrelationStats struct {
 	PendingCount int
@@ -2,6 +2,8 @@ package router
 
 import (
 	"fmt"
+	"runtime"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -278,3 +280,147 @@ func TestCorrelationTrackerShutdown(t *testing.T) {
 		t.Errorf("Expected 0 pending after shutdown, got %d", stats.PendingCount)
 	}
 }
+
+func TestCorrelationTrackerDeadlines(t *testing.T) {
+	ct := NewCorrelationTracker()
+	defer ct.Shutdown()
+
+	// Deadlines expire in order, whatever order they were set in
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, d := range []time.Duration{30, 10, 20} {
+		id := fmt.Sprintf("after-%d", d)
+		ct.Register(id)
+		if err := ct.SetDeadline(id, time.Now().Add(d*time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		ct.OnComplete(id, func(_ *jsonrpc.Response, err error) {
+			defer wg.Done()
+			if err != ErrCorrelationTimeout {
+				t.Errorf("%s: expected ErrCorrelationTimeout, got %v", id, err)
+			}
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	if fmt.Sprint(order) != "[after-10 after-20 after-30]" {
+		t.Errorf("Unexpected expiry order %v", order)
+	}
+
+	// A later deadline does not extend an earlier one
+	ct.Register("kept")
+	ct.SetDeadline("kept", time.Now().Add(10*time.Millisecond))
+	ct.SetDeadline("kept", time.Now().Add(time.Hour))
+	if _, err := ct.WaitForResponse("kept", 0); err != ErrCorrelationTimeout {
+		t.Errorf("Expected ErrCorrelationTimeout, got %v", err)
+	}
+
+	// A completed correlation leaves the heap
+	ct.Register("done")
+	ct.SetDeadline("done", time.Now().Add(time.Hour))
+	ct.Complete("done", &jsonrpc.Response{ID: "done"})
+	ct.deadlinesMu.Lock()
+	scheduled := len(ct.deadlines)
+	ct.deadlinesMu.Unlock()
+	if scheduled != 0 {
+		t.Errorf("Expected no scheduled deadlines, got %d", scheduled)
+	}
+}
+
+func TestCorrelationTrackerOnComplete(t *testing.T) {
+	ct := NewCorrelationTracker()
+	defer ct.Shutdown()
+
+	// Completing calls back and removes the correlation
+	ct.Register("first")
+	var got *jsonrpc.Response
+	ct.OnComplete("first", func(resp *jsonrpc.Response, err error) { got = resp })
+	ct.Complete("first", &jsonrpc.Response{ID: "first"})
+	if got == nil || got.ID != "first" {
+		t.Errorf("Callback did not receive the response, got %v", got)
+	}
+	if ids := ct.PendingIDs(); len(ids) != 0 {
+		t.Errorf("Expected no pending correlations, got %v", ids)
+	}
+
+	// A result that arrived first is passed on at once
+	ct.Register("second")
+	ct.Complete("second", &jsonrpc.Response{ID: "second"})
+	got = nil
+	ct.OnComplete("second", func(resp *jsonrpc.Response, err error) { got = resp })
+	if got == nil || got.ID != "second" {
+		t.Errorf("Callback did not receive the buffered response, got %v", got)
+	}
+
+	// Only the first result counts
+	if err := ct.CompleteWithError("second", ErrCorrelationTimeout); err != ErrCorrelationNotFound {
+		t.Errorf("Expected ErrCorrelationNotFound, got %v", err)
+	}
+	ct.Register("third")
+	ct.Complete("third", &jsonrpc.Response{ID: "third"})
+	if err := ct.CompleteWithError("third", ErrCorrelationTimeout); err == nil {
+		t.Error("Expected second completion to fail")
+	}
+
+	// Canceling calls back with ErrCorrelationCanceled
+	ct.Register("fourth")
+	var gotErr error
+	ct.OnComplete("fourth", func(_ *jsonrpc.Response, err error) { gotErr = err })
+	ct.Cancel("fourth")
+	if gotErr != ErrCorrelationCanceled {
+		t.Errorf("Expected ErrCorrelationCanceled, got %v", gotErr)
+	}
+}
+
+func TestCorrelationTrackerManyPending(t *testing.T) {
+	ct := NewCorrelationTracker()
+	defer ct.Shutdown()
+
+	const n = 20000
+	before := runtime.NumGoroutine()
+
+	var expired sync.WaitGroup
+	expired.Add(n)
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for i := 0; i < n; i++ {
+		id := ct.GenerateCorrelationID()
+		ct.Register(id)
+		ct.SetDeadline(id, deadline)
+		ct.OnComplete(id, func(_ *jsonrpc.Response, err error) {
+			if err != ErrCorrelationTimeout {
+				t.Errorf("Expected ErrCorrelationTimeout, got %v", err)
+			}
+			expired.Done()
+		})
+	}
+
+	// Pending correlations cost no goroutines
+	if delta := runtime.NumGoroutine() - before; delta > 0 {
+		t.Errorf("Expected no extra goroutines for %d pending correlations, got %d", n, delta)
+	}
+
+	expired.Wait()
+	if stats := ct.Stats(); stats.PendingCount != 0 {
+		t.Errorf("Expected 0 pending after expiry, got %d", stats.PendingCount)
+	}
+}
+
+func BenchmarkCorrelationTrackerWaitForResponse(b *testing.B) {
+	ct := NewCorrelationTracker()
+	defer ct.Shutdown()
+
+	response := &jsonrpc.Response{ID: 1}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		id := strconv.Itoa(i)
+		ct.Register(id)
+		ct.Complete(id, response)
+		if _, err := ct.WaitForResponse(id, time.Second); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
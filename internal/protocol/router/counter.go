@@ -0,0 +1,57 @@
+package router
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// counterShards is the number of shards of a shardedCounter, a power of two
+const counterShards = 16
+
+// shardedCounter is a counter for hot paths. Concurrent adds land on
+// different cache lines, so they do not contend; loads sum the shards.
+type shardedCounter struct {
+	shards [counterShards]counterShard
+}
+
+// counterShard is padded to a cache line so neighbouring shards do not
+// share one
+type counterShard struct {
+	n atomic.Int64
+	_ [56]byte
+}
+
+// Add adds n to the counter
+func (c *shardedCounter) Add(n int64) {
+	c.shards[rand.Uint32()&(counterShards-1)].n.Add(n)
+}
+
+// Load returns the sum of the shards. A load concurrent with adds includes
+// some of them.
+func (c *shardedCounter) Load() int64 {
+	var n int64
+	for i := range c.shards {
+		n += c.shards[i].n.Load()
+	}
+	return n
+}
+
+// maxGauge records the largest value observed
+type maxGauge struct {
+	n atomic.Int64
+}
+
+// Observe raises the gauge to n if n is larger
+func (g *maxGauge) Observe(n int64) {
+	for {
+		cur := g.n.Load()
+		if n <= cur || g.n.CompareAndSwap(cur, n) {
+			return
+		}
+	}
+}
+
+// Load returns the largest value observed
+func (g *maxGauge) Load() int64 {
+	return g.n.Load()
+}
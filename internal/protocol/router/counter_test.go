@@ -0,0 +1,45 @@
+package router
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedCounter(t *testing.T) {
+	var c shardedCounter
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	c.Add(-500)
+
+	if got := c.Load(); got != 7500 {
+		t.Errorf("Expected 7500, got %d", got)
+	}
+}
+
+func TestMaxGauge(t *testing.T) {
+	var g maxGauge
+	for _, n := range []int64{3, 7, 5} {
+		g.Observe(n)
+	}
+	if got := g.Load(); got != 7 {
+		t.Errorf("Expected 7, got %d", got)
+	}
+}
+
+func BenchmarkShardedCounterParallel(b *testing.B) {
+	var c shardedCounter
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Add(1)
+		}
+	})
+}
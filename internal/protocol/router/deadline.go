@@ -0,0 +1,89 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// TimeoutHintKey is the _meta field in which a client states how long, in
+// milliseconds, it will wait for the response to a request
+const TimeoutHintKey = "timeoutMs"
+
+// TimeoutHint returns the timeout in the _meta.timeoutMs field of params,
+// which are raw JSON or decoded JSON values
+func TimeoutHint(params any) (time.Duration, bool) {
+	var raw []byte
+	switch p := params.(type) {
+	case nil:
+		return 0, false
+	case json.RawMessage:
+		raw = p
+	case []byte:
+		raw = p
+	default:
+		var err error
+		if raw, err = json.Marshal(p); err != nil {
+			return 0, false
+		}
+	}
+
+	var hint struct {
+		Meta struct {
+			TimeoutMs *float64 `json:"timeoutMs"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(raw, &hint); err != nil || hint.Meta.TimeoutMs == nil || *hint.Meta.TimeoutMs <= 0 {
+		return 0, false
+	}
+	return time.Duration(*hint.Meta.TimeoutMs * float64(time.Millisecond)), true
+}
+
+// WithDeadlineHint returns a context that expires after the timeout hinted in
+// params, capped at max (0 means no cap). Without a hint ctx is returned
+// unchanged.
+func WithDeadlineHint(ctx context.Context, params any, max time.Duration) (context.Context, context.CancelFunc) {
+	timeout, ok := TimeoutHint(params)
+	if !ok {
+		return ctx, func() {}
+	}
+	if max > 0 && timeout > max {
+		timeout = max
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// RemainingTimeoutHint returns the time left before the deadline of ctx as
+// a timeout hint, for forwarding a request to another server
+func RemainingTimeoutHint(ctx context.Context) (int64, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining < 1 {
+		remaining = 1
+	}
+	return remaining, true
+}
+
+// DeadlineMiddleware applies client timeout hints to requests (see
+// WithDeadlineHint) and records them in the RequestContext, so
+// TimeoutMiddleware reports the client's timeout
+func DeadlineMiddleware(max time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			hinted, cancel := WithDeadlineHint(ctx, req.Params, max)
+			defer cancel()
+
+			if deadline, ok := hinted.Deadline(); ok && hinted != ctx {
+				if rc, ok := GetRequestContext(ctx); ok {
+					rc.Timeout = time.Until(deadline)
+				}
+			}
+			return next.Handle(hinted, req)
+		})
+	}
+}
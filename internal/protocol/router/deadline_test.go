@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestTimeoutHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		params any
+		want   time.Duration
+		ok     bool
+	}{
+		{"raw", json.RawMessage(`{"_meta":{"timeoutMs":1500}}`), 1500 * time.Millisecond, true},
+		{"decoded", map[string]any{"_meta": map[string]any{"timeoutMs": float64(250)}}, 250 * time.Millisecond, true},
+		{"no_meta", json.RawMessage(`{"name":"echo"}`), 0, false},
+		{"zero", json.RawMessage(`{"_meta":{"timeoutMs":0}}`), 0, false},
+		{"not_a_number", json.RawMessage(`{"_meta":{"timeoutMs":"soon"}}`), 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TimeoutHint(tt.params)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("TimeoutHint() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWithDeadlineHint(t *testing.T) {
+	ctx := context.Background()
+
+	// Hints are capped by the server maximum
+	hinted, cancel := WithDeadlineHint(ctx, json.RawMessage(`{"_meta":{"timeoutMs":60000}}`), time.Second)
+	defer cancel()
+	deadline, ok := hinted.Deadline()
+	if !ok || time.Until(deadline) > time.Second {
+		t.Errorf("Expected a deadline within 1s, got %v", time.Until(deadline))
+	}
+
+	remaining, ok := RemainingTimeoutHint(hinted)
+	if !ok || remaining <= 0 || remaining > 1000 {
+		t.Errorf("RemainingTimeoutHint() = %d, %v", remaining, ok)
+	}
+
+	// Requests without a hint keep their context
+	unhinted, cancel := WithDeadlineHint(ctx, json.RawMessage(`{}`), time.Second)
+	defer cancel()
+	if unhinted != ctx {
+		t.Error("Expected the context to be unchanged without a hint")
+	}
+	if _, ok := RemainingTimeoutHint(unhinted); ok {
+		t.Error("Expected no remaining timeout without a deadline")
+	}
+}
+
+func TestDeadlineMiddleware(t *testing.T) {
+	handler := NewChain(DeadlineMiddleware(time.Minute), TimeoutMiddleware(time.Hour)).ThenFunc(
+		func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			<-ctx.Done()
+			return jsonrpc.NewResponse("late", req.ID)
+		})
+
+	rc := NewRequestContext("deadline")
+	ctx := WithRequestContext(context.Background(), rc)
+	start := time.Now()
+	resp := handler.Handle(ctx, &jsonrpc.Request{
+		Method: "slow",
+		ID:     1,
+		Params: map[string]any{"_meta": map[string]any{"timeoutMs": 20}},
+	})
+
+	if resp.Error == nil || resp.Error.Code != jsonrpc.ErrorCodeTimeout {
+		t.Fatalf("Expected a timeout error, got %+v", resp)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the client's timeout to apply, took %v", elapsed)
+	}
+	if rc.Timeout <= 0 || rc.Timeout > 20*time.Millisecond {
+		t.Errorf("Expected the request context to record the hint, got %v", rc.Timeout)
+	}
+}
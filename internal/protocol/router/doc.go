@@ -43,6 +43,17 @@
 //   - RegisterFunc(method, handlerFunc): Register a function as a handler
 //   - RegisterNotification(method, handler): Register a NotificationHandler interface
 //   - RegisterNotificationFunc(method, handlerFunc): Register a function as notification handler
+//   - RegisterReserved(method, handler): Register a handler for a reserved "rpc." method
+//
+// # Reserved Methods
+//
+// The JSON-RPC specification reserves method names starting with "rpc." for
+// rpc-internal methods and extensions. Register and RegisterNotification
+// panic for them. Calls to reserved methods never reach the default
+// handlers: they are answered by a handler set with RegisterReserved, or
+// with a "method not found" error; reserved notifications are ignored.
+// jsonrpc.Parse passes reserved calls in batches through as requests, so
+// they reach RegisterReserved handlers like single requests do.
 //
 // # Default Handlers
 //
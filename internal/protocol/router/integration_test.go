@@ -120,7 +120,7 @@ func TestAsyncRouterIntegration(t *testing.T) {
 		}
 
 		// Check metrics
-		if metrics.TotalRequests < 1 {
+		if metrics.Snapshot().TotalRequests < 1 {
 			t.Error("Expected metrics to be updated")
 		}
 	})
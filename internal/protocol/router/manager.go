@@ -23,7 +23,7 @@ type RequestManager struct {
 	requestCount   int64
 
 	// Metrics
-	metrics *ManagerMetrics
+	metrics *managerCounters
 
 	// Lifecycle
 	shutdown chan struct{}
@@ -52,7 +52,20 @@ type ManagerMetrics struct {
 	TimeoutRequests   int64
 	MaxQueueDepth     int64
 	MaxActiveDuration time.Duration
-	mu                sync.RWMutex
+}
+
+// managerCounters holds the live metrics of a RequestManager. They are
+// updated without locks so metrics do not contend with requests.
+type managerCounters struct {
+	total     shardedCounter
+	rejected  shardedCounter
+	completed shardedCounter
+	timeouts  shardedCounter
+
+	active            atomic.Int64
+	queued            atomic.Int64
+	maxQueueDepth     maxGauge
+	maxActiveDuration atomic.Int64
 }
 
 // ManagerConfig holds configuration for RequestManager
@@ -76,7 +89,7 @@ func NewRequestManager(config ManagerConfig) *RequestManager {
 		maxQueued:     config.MaxQueued,
 		semaphore:     make(chan struct{}, config.MaxConcurrent),
 		queue:         make(chan func(), config.MaxQueued),
-		metrics:       &ManagerMetrics{},
+		metrics:       &managerCounters{},
 		shutdown:      make(chan struct{}),
 	}
 
@@ -116,13 +129,13 @@ func (rm *RequestManager) processQueue() {
 			select {
 			case rm.semaphore <- struct{}{}:
 				// Update metrics
-				atomic.AddInt64(&rm.metrics.QueuedRequests, -1)
-				atomic.AddInt64(&rm.metrics.ActiveRequests, 1)
+				rm.metrics.queued.Add(-1)
+				rm.metrics.active.Add(1)
 
 				// Execute function
 				go func() {
 					defer func() {
-						atomic.AddInt64(&rm.metrics.ActiveRequests, -1)
+						rm.metrics.active.Add(-1)
 						<-rm.semaphore // Release semaphore
 					}()
 					fn()
@@ -155,9 +168,6 @@ func (rm *RequestManager) collectMetrics() {
 
 // updateMetrics updates manager metrics
 func (rm *RequestManager) updateMetrics() {
-	rm.metrics.mu.Lock()
-	defer rm.metrics.mu.Unlock()
-
 	// Calculate max duration of active requests
 	var maxDuration time.Duration
 
@@ -169,13 +179,7 @@ func (rm *RequestManager) updateMetrics() {
 		}
 		return true
 	})
-	rm.metrics.MaxActiveDuration = maxDuration
-
-	// Update max queue depth
-	queueDepth := int64(len(rm.queue))
-	if queueDepth > rm.metrics.MaxQueueDepth {
-		rm.metrics.MaxQueueDepth = queueDepth
-	}
+	rm.metrics.maxActiveDuration.Store(int64(maxDuration))
 }
 
 // Execute executes a request with concurrency control
@@ -187,8 +191,9 @@ func (rm *RequestManager) Execute(ctx context.Context, requestID string, fn func
 	}
 	rm.mu.RUnlock()
 
-	// Update metrics
-	atomic.AddInt64(&rm.metrics.TotalRequests, 1)
+	// Update metrics. The total is counted before the outcome, see
+	// GetMetrics.
+	rm.metrics.total.Add(1)
 
 	// Create cancellable context
 	execCtx, cancel := context.WithCancel(ctx)
@@ -222,14 +227,14 @@ func (rm *RequestManager) Execute(ctx context.Context, requestID string, fn func
 	execFn := func() {
 		defer func() {
 			rm.activeRequests.Delete(requestID)
-			atomic.AddInt64(&rm.metrics.CompletedRequests, 1)
+			rm.metrics.completed.Add(1)
 			cancel() // Ensure context is cancelled
 		}()
 
 		// Check if context already cancelled
 		select {
 		case <-execCtx.Done():
-			atomic.AddInt64(&rm.metrics.TimeoutRequests, 1)
+			rm.metrics.timeouts.Add(1)
 			return
 		default:
 		}
@@ -238,7 +243,7 @@ func (rm *RequestManager) Execute(ctx context.Context, requestID string, fn func
 		if err := fn(execCtx); err != nil {
 			// Error handling could be enhanced here
 			if errors.Is(err, context.DeadlineExceeded) {
-				atomic.AddInt64(&rm.metrics.TimeoutRequests, 1)
+				rm.metrics.timeouts.Add(1)
 			}
 		}
 	}
@@ -247,10 +252,10 @@ func (rm *RequestManager) Execute(ctx context.Context, requestID string, fn func
 	select {
 	case rm.semaphore <- struct{}{}:
 		// Got semaphore, execute directly
-		atomic.AddInt64(&rm.metrics.ActiveRequests, 1)
+		rm.metrics.active.Add(1)
 		go func() {
 			defer func() {
-				atomic.AddInt64(&rm.metrics.ActiveRequests, -1)
+				rm.metrics.active.Add(-1)
 				<-rm.semaphore
 			}()
 			execFn()
@@ -258,15 +263,17 @@ func (rm *RequestManager) Execute(ctx context.Context, requestID string, fn func
 		return nil
 
 	default:
-		// Try to queue
+		// Try to queue, counting the request first so the processor
+		// never takes the count below zero
+		rm.metrics.maxQueueDepth.Observe(rm.metrics.queued.Add(1))
 		select {
 		case rm.queue <- execFn:
-			atomic.AddInt64(&rm.metrics.QueuedRequests, 1)
 			return nil
 
 		default:
 			// Queue full
-			atomic.AddInt64(&rm.metrics.RejectedRequests, 1)
+			rm.metrics.queued.Add(-1)
+			rm.metrics.rejected.Add(1)
 			cancel()
 			return errors.New("request queue full")
 		}
@@ -353,19 +360,19 @@ func (rm *RequestManager) Shutdown(ctx context.Context) error {
 	}
 }
 
-// GetMetrics returns a copy of the current metrics
+// GetMetrics returns a copy of the current metrics. Outcomes are read
+// before the total, so the total never falls behind completed plus
+// rejected requests.
 func (rm *RequestManager) GetMetrics() ManagerMetrics {
-	rm.metrics.mu.RLock()
-	defer rm.metrics.mu.RUnlock()
-
-	return ManagerMetrics{
-		TotalRequests:     atomic.LoadInt64(&rm.metrics.TotalRequests),
-		ActiveRequests:    atomic.LoadInt64(&rm.metrics.ActiveRequests),
-		QueuedRequests:    atomic.LoadInt64(&rm.metrics.QueuedRequests),
-		RejectedRequests:  atomic.LoadInt64(&rm.metrics.RejectedRequests),
-		CompletedRequests: atomic.LoadInt64(&rm.metrics.CompletedRequests),
-		TimeoutRequests:   atomic.LoadInt64(&rm.metrics.TimeoutRequests),
-		MaxQueueDepth:     rm.metrics.MaxQueueDepth,
-		MaxActiveDuration: rm.metrics.MaxActiveDuration,
+	m := ManagerMetrics{
+		RejectedRequests:  rm.metrics.rejected.Load(),
+		CompletedRequests: rm.metrics.completed.Load(),
+		TimeoutRequests:   rm.metrics.timeouts.Load(),
+		ActiveRequests:    rm.metrics.active.Load(),
+		QueuedRequests:    rm.metrics.queued.Load(),
+		MaxQueueDepth:     rm.metrics.maxQueueDepth.Load(),
+		MaxActiveDuration: time.Duration(rm.metrics.maxActiveDuration.Load()),
 	}
+	m.TotalRequests = rm.metrics.total.Load()
+	return m
 }
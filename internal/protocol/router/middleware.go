@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -98,18 +101,57 @@ func LoggingMiddleware(logger *log.Logger) Middleware {
 }
 
 // MetricsMiddleware collects request metrics
+// RequestMetrics counts the requests passing MetricsMiddleware. Counting
+// takes no lock, so concurrent requests do not contend on it.
 type RequestMetrics struct {
+	requests shardedCounter
+	errors   shardedCounter
+	duration shardedCounter // nanoseconds
+	methods  sync.Map       // method -> *atomic.Int64
+}
+
+// RequestMetricsSnapshot is a copy of RequestMetrics
+type RequestMetricsSnapshot struct {
 	TotalRequests int64
 	TotalErrors   int64
 	MethodCounts  map[string]int64
 	TotalDuration time.Duration
-	mu            sync.RWMutex
 }
 
 func NewRequestMetrics() *RequestMetrics {
-	return &RequestMetrics{
+	return &RequestMetrics{}
+}
+
+// observe counts a request. The total is counted first, see Snapshot.
+func (m *RequestMetrics) observe(method string, duration time.Duration, failed bool) {
+	m.requests.Add(1)
+
+	count, ok := m.methods.Load(method)
+	if !ok {
+		count, _ = m.methods.LoadOrStore(method, new(atomic.Int64))
+	}
+	count.(*atomic.Int64).Add(1)
+
+	m.duration.Add(int64(duration))
+	if failed {
+		m.errors.Add(1)
+	}
+}
+
+// Snapshot returns the current counts. The total is read last, so it is
+// never below the errors or the sum of the method counts.
+func (m *RequestMetrics) Snapshot() RequestMetricsSnapshot {
+	snapshot := RequestMetricsSnapshot{
+		TotalErrors:  m.errors.Load(),
 		MethodCounts: make(map[string]int64),
 	}
+	m.methods.Range(func(key, value any) bool {
+		snapshot.MethodCounts[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	snapshot.TotalDuration = time.Duration(m.duration.Load())
+	snapshot.TotalRequests = m.requests.Load()
+	return snapshot
 }
 
 func MetricsMiddleware(metrics *RequestMetrics) Middleware {
@@ -122,14 +164,7 @@ func MetricsMiddleware(metrics *RequestMetrics) Middleware {
 
 			// Update metrics
 			duration := time.Since(start)
-			metrics.mu.Lock()
-			metrics.TotalRequests++
-			metrics.MethodCounts[req.Method]++
-			metrics.TotalDuration += duration
-			if resp.Error != nil {
-				metrics.TotalErrors++
-			}
-			metrics.mu.Unlock()
+			metrics.observe(req.Method, duration, resp.Error != nil)
 
 			// Store duration in context if available
 			if rc, ok := GetRequestContext(ctx); ok {
@@ -141,6 +176,20 @@ func MetricsMiddleware(metrics *RequestMetrics) Middleware {
 	}
 }
 
+// ErrorRecorderMiddleware records the outcome of each request in recorder.
+// Notifications, which get no response, are not recorded.
+func ErrorRecorderMiddleware(recorder *mcperrors.Recorder) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			resp := next.Handle(ctx, req)
+			if resp != nil {
+				recorder.RecordResponse(resp)
+			}
+			return resp
+		})
+	}
+}
+
 // RecoveryMiddleware recovers from panics and returns an error response
 func RecoveryMiddleware(logger *log.Logger) Middleware {
 	if logger == nil {
@@ -158,7 +207,11 @@ func RecoveryMiddleware(logger *log.Logger) Middleware {
 					}
 
 					// Log panic
-					logger.Printf("[%s] Panic recovered: %v", correlationID, r)
+					panicErr := mcperrors.FromPanic(r)
+					logger.Printf("[%s] Panic recovered: %v (%s)", correlationID, r, panicErr.Context[mcperrors.PanicTypeKey])
+					if stack, ok := panicErr.DebugInfo["stack_trace"]; ok {
+						logger.Printf("[%s] %s", correlationID, stack)
+					}
 
 					// Return error response
 					resp = &jsonrpc.Response{
@@ -166,7 +219,7 @@ func RecoveryMiddleware(logger *log.Logger) Middleware {
 						Error: jsonrpc.NewError(
 							jsonrpc.ErrorCodeInternal,
 							"Internal server error",
-							fmt.Sprintf("panic: %v", r),
+							panicErr.Data,
 						),
 					}
 				}
@@ -265,6 +318,13 @@ func ContextEnrichmentMiddleware() Middleware {
 			rc.SetMetadata("method", req.Method)
 			rc.SetMetadata("request_id", req.ID)
 
+			// Logs written with ctx carry the request
+			ctx = logging.WithMethod(ctx, req.Method)
+			ctx = logging.WithRequestID(ctx, logging.FormatRequestID(req.ID))
+			if rc.CorrelationID != "" {
+				ctx = logging.WithCorrelationID(ctx, rc.CorrelationID)
+			}
+
 			return next.Handle(ctx, req)
 		})
 	}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -228,26 +229,124 @@ func TestMetricsMiddleware(t *testing.T) {
 	wrapped.Handle(context.Background(), &jsonrpc.Request{ID: "4", Method: "error.method"})
 
 	// Verify metrics
-	metrics.mu.RLock()
-	defer metrics.mu.RUnlock()
+	snapshot := metrics.Snapshot()
 
-	if metrics.TotalRequests != 4 {
-		t.Errorf("Expected 4 total requests, got %d", metrics.TotalRequests)
+	if snapshot.TotalRequests != 4 {
+		t.Errorf("Expected 4 total requests, got %d", snapshot.TotalRequests)
 	}
 
-	if metrics.TotalErrors != 1 {
-		t.Errorf("Expected 1 error, got %d", metrics.TotalErrors)
+	if snapshot.TotalErrors != 1 {
+		t.Errorf("Expected 1 error, got %d", snapshot.TotalErrors)
 	}
 
-	if metrics.MethodCounts["test.method1"] != 2 {
-		t.Errorf("Expected 2 calls to test.method1, got %d", metrics.MethodCounts["test.method1"])
+	if snapshot.MethodCounts["test.method1"] != 2 {
+		t.Errorf("Expected 2 calls to test.method1, got %d", snapshot.MethodCounts["test.method1"])
 	}
 
-	if metrics.TotalDuration < 40*time.Millisecond {
-		t.Errorf("Expected total duration >= 40ms, got %v", metrics.TotalDuration)
+	if snapshot.TotalDuration < 40*time.Millisecond {
+		t.Errorf("Expected total duration >= 40ms, got %v", snapshot.TotalDuration)
 	}
 }
 
+func TestErrorRecorderMiddleware(t *testing.T) {
+	recorder := mcperrors.NewRecorder(mcperrors.RecorderConfig{})
+	wrapped := ErrorRecorderMiddleware(recorder)(HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		switch {
+		case req.IsNotification():
+			return nil
+		case req.Method == "error.method":
+			return jsonrpc.NewErrorResponse(jsonrpc.NewError(mcperrors.ErrorCodeMCPToolError, "test error", nil), req.ID)
+		default:
+			return jsonrpc.NewResponse("ok", req.ID)
+		}
+	}))
+
+	wrapped.Handle(context.Background(), &jsonrpc.Request{ID: "1", Method: "test.method"})
+	wrapped.Handle(context.Background(), &jsonrpc.Request{ID: "2", Method: "error.method"})
+	wrapped.Handle(context.Background(), &jsonrpc.Request{Method: "test.notify"})
+
+	snapshot := recorder.Snapshot()
+	if snapshot.Requests != 2 {
+		t.Errorf("Expected 2 recorded requests, got %d", snapshot.Requests)
+	}
+	if snapshot.ByCode[mcperrors.ErrorCodeMCPToolError] != 1 {
+		t.Errorf("Expected 1 tool error, got %v", snapshot.ByCode)
+	}
+}
+
+func TestMetricsMiddlewareConcurrentSnapshots(t *testing.T) {
+	metrics := NewRequestMetrics()
+	wrapped := MetricsMiddleware(metrics)(HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		if req.Method == "error.method" {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInternalError(nil), req.ID)
+		}
+		return jsonrpc.NewResponse("ok", req.ID)
+	}))
+
+	const workers, requests = 4, 500
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				method := "test.method"
+				if j%5 == 0 {
+					method = "error.method"
+				}
+				wrapped.Handle(context.Background(), &jsonrpc.Request{ID: j, Method: method})
+			}
+		}(i)
+	}
+
+	// Snapshots taken while requests are counted stay consistent
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		snapshot := metrics.Snapshot()
+		var methods int64
+		for _, n := range snapshot.MethodCounts {
+			methods += n
+		}
+		if snapshot.TotalErrors > snapshot.TotalRequests || methods > snapshot.TotalRequests {
+			t.Fatalf("Inconsistent snapshot: %d requests, %d errors, %d by method",
+				snapshot.TotalRequests, snapshot.TotalErrors, methods)
+		}
+	}
+
+	snapshot := metrics.Snapshot()
+	if snapshot.TotalRequests != workers*requests {
+		t.Errorf("Expected %d requests, got %d", workers*requests, snapshot.TotalRequests)
+	}
+	if snapshot.TotalErrors != workers*requests/5 {
+		t.Errorf("Expected %d errors, got %d", workers*requests/5, snapshot.TotalErrors)
+	}
+}
+
+func BenchmarkMetricsMiddlewareParallel(b *testing.B) {
+	metrics := NewRequestMetrics()
+	response := jsonrpc.NewResponse("ok", 1)
+	wrapped := MetricsMiddleware(metrics)(HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return response
+	}))
+	req := &jsonrpc.Request{ID: 1, Method: "test.method"}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			wrapped.Handle(context.Background(), req)
+		}
+	})
+}
+
 func TestRecoveryMiddleware(t *testing.T) {
 	var buf bytes.Buffer
 	logger := log.New(&buf, "", 0)
@@ -2,7 +2,9 @@ package router
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
@@ -35,9 +37,17 @@ func (f NotificationHandlerFunc) HandleNotification(ctx context.Context, notific
 	f(ctx, notification)
 }
 
-// Router provides message routing for JSON-RPC requests and notifications
+// Router provides message routing for JSON-RPC requests and notifications.
+// Registrations are rare and lookups happen for every message, so the
+// routes are an immutable table swapped atomically on change: lookups take
+// no lock.
 type Router struct {
-	mu                         sync.RWMutex
+	mu     sync.Mutex // serializes writers
+	routes atomic.Pointer[routes]
+}
+
+// routes is an immutable routing table
+type routes struct {
 	handlers                   map[string]Handler
 	notificationHandlers       map[string]NotificationHandler
 	defaultHandler             Handler
@@ -46,17 +56,58 @@ type Router struct {
 
 // New creates a new Router instance
 func New() *Router {
-	return &Router{
+	r := &Router{}
+	r.routes.Store(&routes{
 		handlers:             make(map[string]Handler),
 		notificationHandlers: make(map[string]NotificationHandler),
-	}
+	})
+	return r
 }
 
-// Register registers a handler for the specified method
-func (r *Router) Register(method string, handler Handler) {
+// update replaces the routing table with a modified copy
+func (r *Router) update(modify func(rt *routes)) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.handlers[method] = handler
+
+	old := r.routes.Load()
+	rt := &routes{
+		handlers:                   make(map[string]Handler, len(old.handlers)+1),
+		notificationHandlers:       make(map[string]NotificationHandler, len(old.notificationHandlers)+1),
+		defaultHandler:             old.defaultHandler,
+		defaultNotificationHandler: old.defaultNotificationHandler,
+	}
+	for method, handler := range old.handlers {
+		rt.handlers[method] = handler
+	}
+	for method, handler := range old.notificationHandlers {
+		rt.notificationHandlers[method] = handler
+	}
+	modify(rt)
+	r.routes.Store(rt)
+}
+
+// Register registers a handler for the specified method. It panics for
+// methods with the reserved "rpc." prefix; use RegisterReserved for those.
+func (r *Router) Register(method string, handler Handler) {
+	mustNotBeReserved(method)
+	r.update(func(rt *routes) { rt.handlers[method] = handler })
+}
+
+// RegisterReserved registers a handler for a well-known reserved method,
+// such as an "rpc.discover" extension. Reserved methods without one are
+// answered with a method not found error, even with a default handler.
+func (r *Router) RegisterReserved(method string, handler Handler) {
+	if !jsonrpc.IsReserved(method) {
+		panic(fmt.Sprintf("router: method %q is not reserved", method))
+	}
+	r.update(func(rt *routes) { rt.handlers[method] = handler })
+}
+
+// mustNotBeReserved panics for a reserved method name registered by user code
+func mustNotBeReserved(method string) {
+	if jsonrpc.IsReserved(method) {
+		panic(fmt.Sprintf("router: method %q uses the reserved %q prefix", method, jsonrpc.ReservedPrefix))
+	}
 }
 
 // RegisterFunc registers a handler function for the specified method
@@ -64,11 +115,11 @@ func (r *Router) RegisterFunc(method string, handlerFunc HandlerFunc) {
 	r.Register(method, handlerFunc)
 }
 
-// RegisterNotification registers a notification handler for the specified method
+// RegisterNotification registers a notification handler for the specified
+// method. It panics for methods with the reserved "rpc." prefix.
 func (r *Router) RegisterNotification(method string, handler NotificationHandler) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.notificationHandlers[method] = handler
+	mustNotBeReserved(method)
+	r.update(func(rt *routes) { rt.notificationHandlers[method] = handler })
 }
 
 // RegisterNotificationFunc registers a notification handler function for the specified method
@@ -78,31 +129,24 @@ func (r *Router) RegisterNotificationFunc(method string, handlerFunc Notificatio
 
 // SetDefaultHandler sets a default handler for unregistered methods
 func (r *Router) SetDefaultHandler(handler Handler) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.defaultHandler = handler
+	r.update(func(rt *routes) { rt.defaultHandler = handler })
 }
 
 // SetDefaultNotificationHandler sets a default handler for unregistered notification methods
 func (r *Router) SetDefaultNotificationHandler(handler NotificationHandler) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.defaultNotificationHandler = handler
+	r.update(func(rt *routes) { rt.defaultNotificationHandler = handler })
 }
 
 // Handle routes a JSON-RPC request to the appropriate handler
 func (r *Router) Handle(ctx context.Context, request *jsonrpc.Request) *jsonrpc.Response {
-	r.mu.RLock()
-	handler, exists := r.handlers[request.Method]
-	defaultHandler := r.defaultHandler
-	r.mu.RUnlock()
+	rt := r.routes.Load()
 
-	if exists {
+	if handler, exists := rt.handlers[request.Method]; exists {
 		return handler.Handle(ctx, request)
 	}
 
-	if defaultHandler != nil {
-		return defaultHandler.Handle(ctx, request)
+	if rt.defaultHandler != nil && !jsonrpc.IsReserved(request.Method) {
+		return rt.defaultHandler.Handle(ctx, request)
 	}
 
 	// Return method not found error
@@ -114,31 +158,27 @@ func (r *Router) Handle(ctx context.Context, request *jsonrpc.Request) *jsonrpc.
 
 // HandleNotification routes a JSON-RPC notification to the appropriate handler
 func (r *Router) HandleNotification(ctx context.Context, notification *jsonrpc.Notification) {
-	r.mu.RLock()
-	handler, exists := r.notificationHandlers[notification.Method]
-	defaultHandler := r.defaultNotificationHandler
-	r.mu.RUnlock()
+	rt := r.routes.Load()
 
-	if exists {
+	if handler, exists := rt.notificationHandlers[notification.Method]; exists {
 		handler.HandleNotification(ctx, notification)
 		return
 	}
 
-	if defaultHandler != nil {
-		defaultHandler.HandleNotification(ctx, notification)
+	if rt.defaultNotificationHandler != nil && !jsonrpc.IsReserved(notification.Method) {
+		rt.defaultNotificationHandler.HandleNotification(ctx, notification)
 		return
 	}
 
-	// Notifications don't return responses, so we silently ignore unknown methods
+	// Notifications don't return responses, so we silently ignore unknown
+	// and reserved methods
 }
 
 // GetRegisteredMethods returns a list of all registered method names
 func (r *Router) GetRegisteredMethods() []string {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	methods := make([]string, 0, len(r.handlers))
-	for method := range r.handlers {
+	rt := r.routes.Load()
+	methods := make([]string, 0, len(rt.handlers))
+	for method := range rt.handlers {
 		methods = append(methods, method)
 	}
 	return methods
@@ -146,11 +186,9 @@ func (r *Router) GetRegisteredMethods() []string {
 
 // GetRegisteredNotificationMethods returns a list of all registered notification method names
 func (r *Router) GetRegisteredNotificationMethods() []string {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	methods := make([]string, 0, len(r.notificationHandlers))
-	for method := range r.notificationHandlers {
+	rt := r.routes.Load()
+	methods := make([]string, 0, len(rt.notificationHandlers))
+	for method := range rt.notificationHandlers {
 		methods = append(methods, method)
 	}
 	return methods
@@ -158,42 +196,34 @@ func (r *Router) GetRegisteredNotificationMethods() []string {
 
 // HasMethod checks if a method is registered
 func (r *Router) HasMethod(method string) bool {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	_, exists := r.handlers[method]
+	_, exists := r.routes.Load().handlers[method]
 	return exists
 }
 
 // HasNotificationMethod checks if a notification method is registered
 func (r *Router) HasNotificationMethod(method string) bool {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	_, exists := r.notificationHandlers[method]
+	_, exists := r.routes.Load().notificationHandlers[method]
 	return exists
 }
 
 // Unregister removes a handler for the specified method
 func (r *Router) Unregister(method string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.handlers, method)
+	r.update(func(rt *routes) { delete(rt.handlers, method) })
 }
 
 // UnregisterNotification removes a notification handler for the specified method
 func (r *Router) UnregisterNotification(method string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.notificationHandlers, method)
+	r.update(func(rt *routes) { delete(rt.notificationHandlers, method) })
 }
 
 // Clear removes all registered handlers
 func (r *Router) Clear() {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.handlers = make(map[string]Handler)
-	r.notificationHandlers = make(map[string]NotificationHandler)
-	r.defaultHandler = nil
-	r.defaultNotificationHandler = nil
+	r.update(func(rt *routes) {
+		*rt = routes{
+			handlers:             make(map[string]Handler),
+			notificationHandlers: make(map[string]NotificationHandler),
+		}
+	})
 }
 
 // Stats returns statistics about the router
@@ -206,13 +236,11 @@ type Stats struct {
 
 // GetStats returns router statistics
 func (r *Router) GetStats() Stats {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
+	rt := r.routes.Load()
 	return Stats{
-		RegisteredMethods:             len(r.handlers),
-		RegisteredNotificationMethods: len(r.notificationHandlers),
-		HasDefaultHandler:             r.defaultHandler != nil,
-		HasDefaultNotificationHandler: r.defaultNotificationHandler != nil,
+		RegisteredMethods:             len(rt.handlers),
+		RegisteredNotificationMethods: len(rt.notificationHandlers),
+		HasDefaultHandler:             rt.defaultHandler != nil,
+		HasDefaultNotificationHandler: rt.defaultNotificationHandler != nil,
 	}
 }
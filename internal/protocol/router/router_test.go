@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
@@ -114,6 +115,122 @@ func TestRouter_Handle_DefaultHandler(t *testing.T) {
 	}
 }
 
+func TestRouter_RegisterReservedPanics(t *testing.T) {
+	router := New()
+	handler := &mockHandler{result: "success"}
+
+	for name, register := range map[string]func(){
+		"Register":             func() { router.Register("rpc.discover", handler) },
+		"RegisterFunc":         func() { router.RegisterFunc("rpc.discover", handler.Handle) },
+		"RegisterNotification": func() { router.RegisterNotification("rpc.ping", &mockNotificationHandler{}) },
+		"RegisterReserved":     func() { router.RegisterReserved("discover", handler) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			register()
+		})
+	}
+
+	if stats := router.GetStats(); stats.RegisteredMethods != 0 || stats.RegisteredNotificationMethods != 0 {
+		t.Errorf("Expected no registrations, got %+v", stats)
+	}
+}
+
+func TestRouter_Handle_ReservedMethod(t *testing.T) {
+	router := New()
+	router.SetDefaultHandler(&mockHandler{result: "default result"})
+	defaultNotifications := &mockNotificationHandler{}
+	router.SetDefaultNotificationHandler(defaultNotifications)
+	router.RegisterReserved("rpc.discover", &mockHandler{result: "openrpc"})
+
+	// Configured reserved methods are answered by their handler
+	response := router.Handle(context.Background(), jsonrpc.NewRequest("rpc.discover", nil, 1))
+	if response.Error != nil || response.Result != "openrpc" {
+		t.Errorf("Expected the rpc.discover result, got %+v", response)
+	}
+
+	// Others never reach the default handler
+	response = router.Handle(context.Background(), jsonrpc.NewRequest("rpc.other", nil, 2))
+	if response.Error == nil || response.Error.Code != jsonrpc.ErrorCodeMethodNotFound {
+		t.Fatalf("Expected method not found, got %+v", response)
+	}
+	if response.ID != 2 {
+		t.Errorf("Expected response ID 2, got %v", response.ID)
+	}
+
+	router.HandleNotification(context.Background(), jsonrpc.NewNotification("rpc.other", nil))
+	if defaultNotifications.called {
+		t.Error("Reserved notification should not reach the default handler")
+	}
+}
+
+func TestRouter_HandleBatch_ReservedMethods(t *testing.T) {
+	router := New()
+	router.RegisterFunc("echo", func(ctx context.Context, request *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse("echo", request.ID)
+	})
+	router.SetDefaultHandler(&mockHandler{result: "default result"})
+	router.RegisterReserved("rpc.describe", &mockHandler{result: "description"})
+
+	tests := []struct {
+		name  string
+		batch string
+		want  map[any]int // response ID -> error code, 0 for a result
+	}{
+		{
+			name: "mixed",
+			batch: `[{"jsonrpc":"2.0","id":1,"method":"echo"},
+				{"jsonrpc":"2.0","id":2,"method":"rpc.discover"},
+				{"jsonrpc":"2.0","id":3,"method":"unknown"}]`,
+			want: map[any]int{float64(1): 0, float64(2): jsonrpc.ErrorCodeMethodNotFound, float64(3): 0},
+		},
+		{
+			name: "reserved notifications only",
+			batch: `[{"jsonrpc":"2.0","method":"rpc.ping"},
+				{"jsonrpc":"2.0","method":"rpc.cancel","params":{"id":1}}]`,
+			want: map[any]int{},
+		},
+		{
+			name: "reserved calls only",
+			batch: `[{"jsonrpc":"2.0","id":"a","method":"rpc.discover"},
+				{"jsonrpc":"2.0","id":"b","method":"rpc.describe"}]`,
+			want: map[any]int{"a": jsonrpc.ErrorCodeMethodNotFound, "b": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := jsonrpc.Parse([]byte(tt.batch))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			got := make(map[any]int)
+			for _, message := range messages {
+				switch m := message.(type) {
+				case *jsonrpc.Request:
+					response := router.Handle(context.Background(), m)
+					got[response.ID] = 0
+					if response.Error != nil {
+						got[response.ID] = response.Error.Code
+					}
+				case *jsonrpc.Notification:
+					router.HandleNotification(context.Background(), m)
+				case *jsonrpc.Response:
+					got[m.ID] = m.Error.Code
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected responses %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestRouter_RegisterNotification(t *testing.T) {
 	router := New()
 	handler := &mockNotificationHandler{}
@@ -390,3 +507,72 @@ func BenchmarkRouterConcurrentAccess(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkRouterMessageFlow parses requests, routes them and writes the
+// responses, with and without returning the messages to their pools
+func BenchmarkRouterMessageFlow(b *testing.B) {
+	router := New()
+	router.RegisterFunc("tools/call", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(map[string]interface{}{"content": "ok"}, req.ID)
+	})
+	raw := []byte(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"echo"},"id":1}`)
+	ctx := context.Background()
+
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			msg, err := jsonrpc.ParseMessage(raw)
+			if err != nil {
+				b.Fatal(err)
+			}
+			response := router.Handle(ctx, msg.(*jsonrpc.Request))
+			if _, err := jsonrpc.Marshal(response); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			msg, err := jsonrpc.ParseMessage(raw)
+			if err != nil {
+				b.Fatal(err)
+			}
+			response := router.Handle(ctx, msg.(*jsonrpc.Request))
+			if err := jsonrpc.Encode(io.Discard, response); err != nil {
+				b.Fatal(err)
+			}
+			jsonrpc.ReleaseResponse(response)
+			jsonrpc.ReleaseMessage(msg)
+		}
+	})
+}
+
+// BenchmarkRouterHandleParallel measures lookup throughput with many
+// goroutines routing at once; run with -cpu 1,4,8 to see how it scales
+func BenchmarkRouterHandleParallel(b *testing.B) {
+	router := New()
+	response := jsonrpc.NewResponse("ok", 1)
+	requests := make([]*jsonrpc.Request, 10)
+	for i := range requests {
+		method := fmt.Sprintf("test.method%d", i)
+		router.RegisterFunc(method, func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			return response
+		})
+		requests[i] = jsonrpc.NewRequest(method, nil, i)
+	}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if router.Handle(ctx, requests[i%len(requests)]) != response {
+				b.Fatal("unexpected response")
+			}
+			i++
+		}
+	})
+}
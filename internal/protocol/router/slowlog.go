@@ -0,0 +1,129 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
+)
+
+// UpstreamMetadataKey is the RequestContext metadata key under which proxying
+// handlers record the name of the upstream server serving the request
+const UpstreamMetadataKey = "upstream"
+
+// Default slow request settings
+const (
+	DefaultSlowThreshold   = time.Second
+	DefaultMaxParamsLength = 512
+)
+
+// SlowRequestConfig configures SlowRequestMiddleware
+type SlowRequestConfig struct {
+	// Threshold applies to methods without an entry in Methods
+	// (defaults to DefaultSlowThreshold)
+	Threshold time.Duration
+
+	// Methods overrides the threshold per method. A negative value disables
+	// slow request logging for the method.
+	Methods map[string]time.Duration
+
+	// MaxParamsLength truncates the logged params (defaults to
+	// DefaultMaxParamsLength)
+	MaxParamsLength int
+
+	// Logger receives slow request records (defaults to logging.Default())
+	Logger *logging.Logger
+}
+
+// thresholdFor returns the slow threshold for method
+func (c SlowRequestConfig) thresholdFor(method string) time.Duration {
+	if threshold, ok := c.Methods[method]; ok {
+		return threshold
+	}
+	return c.Threshold
+}
+
+// SlowRequestMiddleware logs a structured warning for requests that take
+// longer than the configured threshold for their method
+func SlowRequestMiddleware(config SlowRequestConfig) Middleware {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultSlowThreshold
+	}
+	if config.MaxParamsLength <= 0 {
+		config.MaxParamsLength = DefaultMaxParamsLength
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			start := time.Now()
+			resp := next.Handle(ctx, req)
+			duration := time.Since(start)
+
+			threshold := config.thresholdFor(req.Method)
+			if threshold < 0 || duration < threshold {
+				return resp
+			}
+
+			logger := config.Logger
+			if logger == nil {
+				logger = logging.Default()
+			}
+			logger.WithFields(slowRequestFields(ctx, req, resp, duration, threshold, config.MaxParamsLength)).
+				Warn(ctx, "Slow request")
+
+			return resp
+		})
+	}
+}
+
+// slowRequestFields builds the structured record for a slow request
+func slowRequestFields(ctx context.Context, req *jsonrpc.Request, resp *jsonrpc.Response, duration, threshold time.Duration, maxParams int) map[string]interface{} {
+	fields := logging.NewLogFields().
+		With(logging.FieldMethod, req.Method).
+		With(logging.FieldDuration, duration.Milliseconds()).
+		With("threshold_ms", threshold.Milliseconds())
+
+	if req.ID != nil {
+		fields[logging.FieldRequestID] = req.ID
+	}
+	if rc, ok := GetRequestContext(ctx); ok {
+		if rc.CorrelationID != "" {
+			fields[logging.FieldCorrelationID] = rc.CorrelationID
+		}
+		if upstream, ok := rc.GetMetadataString(UpstreamMetadataKey); ok {
+			fields["upstream"] = upstream
+		}
+	}
+	if connID, ok := connection.GetConnectionID(ctx); ok {
+		fields[logging.FieldConnectionID] = connID
+	}
+	if params, ok := req.Params.(map[string]interface{}); ok {
+		if name, ok := params["name"].(string); ok && req.Method == "tools/call" {
+			fields["tool"] = name
+		}
+	}
+	if req.Params != nil {
+		fields["params"] = truncateParams(req.Params, maxParams)
+	}
+	if resp != nil && resp.Error != nil {
+		fields[logging.FieldErrorCode] = resp.Error.Code
+	}
+
+	return fields
+}
+
+// truncateParams renders params as redacted JSON limited to max bytes
+func truncateParams(params interface{}, max int) string {
+	data, err := json.Marshal(redact.Value("", params))
+	if err != nil {
+		return "<unmarshalable>"
+	}
+	if len(data) > max {
+		return string(data[:max]) + "...(truncated)"
+	}
+	return string(data)
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestSlowRequestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(logging.Config{Output: &buf, Level: logging.LogLevelDebug})
+
+	handler := NewChain(SlowRequestMiddleware(SlowRequestConfig{
+		Threshold:       time.Hour,
+		Methods:         map[string]time.Duration{"tools/call": time.Millisecond},
+		MaxParamsLength: 64,
+		Logger:          logger,
+	})).ThenFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		time.Sleep(5 * time.Millisecond)
+		return jsonrpc.NewResponse("ok", req.ID)
+	})
+
+	// Fast relative to the default threshold: not logged
+	handler.Handle(context.Background(), &jsonrpc.Request{ID: 1, Method: "tools/list"})
+	if buf.Len() != 0 {
+		t.Fatalf("Expected no log output, got %s", buf.String())
+	}
+
+	rc := NewRequestContext("corr-1")
+	rc.SetMetadata(UpstreamMetadataKey, "github")
+	ctx := WithRequestContext(context.Background(), rc)
+
+	handler.Handle(ctx, &jsonrpc.Request{
+		ID:     2,
+		Method: "tools/call",
+		Params: map[string]interface{}{
+			"name":      "search",
+			"arguments": map[string]interface{}{"query": strings.Repeat("x", 100), "api_key": "secret"},
+		},
+	})
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON record: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"method":         "tools/call",
+		"tool":           "search",
+		"upstream":       "github",
+		"correlation_id": "corr-1",
+		"message":        "Slow request",
+	}
+	for key, want := range expected {
+		if record[key] != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, record[key])
+		}
+	}
+
+	params, _ := record["params"].(string)
+	if !strings.HasSuffix(params, "...(truncated)") {
+		t.Errorf("Expected truncated params, got %q", params)
+	}
+	if strings.Contains(params, "secret") {
+		t.Errorf("Expected params to be redacted, got %q", params)
+	}
+}
@@ -0,0 +1,198 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
+)
+
+// WireLogConfig configures a WireLogger
+type WireLogConfig struct {
+	// Output receives one JSON record per line (required). A
+	// logging.FileSink gives size-based rotation.
+	Output io.Writer
+
+	// SampleRate is the fraction of exchanges recorded, in (0, 1]
+	// (defaults to 1)
+	SampleRate float64
+
+	// Methods restricts recording to these methods (defaults to all)
+	Methods []string
+
+	// Redactor scrubs payloads before they are written (defaults to
+	// redact.Default())
+	Redactor *redact.Redactor
+}
+
+// WireRecord is a recorded request/response exchange. Notifications are
+// recorded without a response.
+type WireRecord struct {
+	Time         time.Time       `json:"time"`
+	Method       string          `json:"method"`
+	ConnectionID string          `json:"connectionId,omitempty"`
+	DurationMS   int64           `json:"durationMs"`
+	Request      json.RawMessage `json:"request"`
+	Response     json.RawMessage `json:"response,omitempty"`
+}
+
+// WireLogger records full JSON-RPC payloads as NDJSON for offline analysis
+type WireLogger struct {
+	config  WireLogConfig
+	methods map[string]bool
+
+	mu   sync.Mutex
+	enc  *json.Encoder
+	rand func() float64
+}
+
+// NewWireLogger creates a new WireLogger
+func NewWireLogger(config WireLogConfig) *WireLogger {
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.Redactor == nil {
+		config.Redactor = redact.Default()
+	}
+
+	wl := &WireLogger{
+		config: config,
+		enc:    json.NewEncoder(config.Output),
+		rand:   rand.Float64,
+	}
+	if len(config.Methods) > 0 {
+		wl.methods = make(map[string]bool, len(config.Methods))
+		for _, method := range config.Methods {
+			wl.methods[method] = true
+		}
+	}
+	return wl
+}
+
+// Conn returns a recorder for the messages of one connection
+func (wl *WireLogger) Conn(connectionID string) *WireConn {
+	return &WireConn{wl: wl, connectionID: connectionID, pending: make(map[string]*wireExchange)}
+}
+
+// WireConn pairs the requests a connection reads with the responses
+// written to it, recording sampled exchanges in its WireLogger. It is safe
+// for concurrent use.
+type WireConn struct {
+	wl           *WireLogger
+	connectionID string
+
+	mu      sync.Mutex
+	pending map[string]*wireExchange // by request ID
+}
+
+// wireExchange is a recorded request waiting for its response
+type wireExchange struct {
+	record *WireRecord
+	start  time.Time
+}
+
+// wireMessage holds the fields of a message that pair requests with
+// responses
+type wireMessage struct {
+	Method string          `json:"method"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// Record records a message the connection read or wrote. Batches and
+// messages that are not JSON-RPC objects are skipped.
+func (c *WireConn) Record(direction connection.Direction, message []byte) {
+	if direction == connection.Inbound {
+		c.inbound(message)
+	} else {
+		c.outbound(message)
+	}
+}
+
+// inbound starts the exchange of a request from the client, or records a
+// notification
+func (c *WireConn) inbound(message []byte) {
+	var msg wireMessage
+	if json.Unmarshal(message, &msg) != nil || msg.Method == "" || !c.wl.sampled(msg.Method) {
+		return
+	}
+
+	record := &WireRecord{
+		Time:         time.Now().UTC(),
+		Method:       msg.Method,
+		ConnectionID: c.connectionID,
+		Request:      c.wl.redact(message),
+	}
+	id := requestKey(msg.ID)
+	if id == "" {
+		c.wl.write(record)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[id] = &wireExchange{record: record, start: time.Now()}
+}
+
+// outbound completes the exchange a response answers. Requests and
+// notifications from the server are not recorded.
+func (c *WireConn) outbound(message []byte) {
+	c.mu.Lock()
+	waiting := len(c.pending) > 0
+	c.mu.Unlock()
+	if !waiting {
+		return
+	}
+
+	var msg wireMessage
+	if json.Unmarshal(message, &msg) != nil || msg.Method != "" {
+		return
+	}
+	id := requestKey(msg.ID)
+
+	c.mu.Lock()
+	exchange, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	exchange.record.DurationMS = time.Since(exchange.start).Milliseconds()
+	exchange.record.Response = c.wl.redact(message)
+	c.wl.write(exchange.record)
+}
+
+// requestKey returns the key of a request ID in the pending exchanges,
+// or "" for a notification
+func requestKey(id json.RawMessage) string {
+	id = bytes.TrimSpace(id)
+	if len(id) == 0 || bytes.Equal(id, []byte("null")) {
+		return ""
+	}
+	return string(id)
+}
+
+// sampled reports whether an exchange for method should be recorded
+func (wl *WireLogger) sampled(method string) bool {
+	if wl.methods != nil && !wl.methods[method] {
+		return false
+	}
+	return wl.config.SampleRate >= 1 || wl.rand() < wl.config.SampleRate
+}
+
+// redact returns message with its sensitive values redacted
+func (wl *WireLogger) redact(message []byte) json.RawMessage {
+	return wl.config.Redactor.JSON(message)
+}
+
+// write appends record to the output
+func (wl *WireLogger) write(record *WireRecord) {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+	wl.enc.Encode(record)
+}
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/testing/golden"
+)
+
+func TestWireLogger(t *testing.T) {
+	var buf bytes.Buffer
+	wl := NewWireLogger(WireLogConfig{Output: &buf, Methods: []string{"tools/call"}})
+	conn := wl.Conn("stdio-1")
+
+	conn.Record(connection.Inbound, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"login","arguments":{"password":"hunter2"}}}`))
+	conn.Record(connection.Inbound, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	conn.Record(connection.Outbound, []byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":1}}`))
+	conn.Record(connection.Outbound, []byte(`{"jsonrpc":"2.0","id":2,"result":{"tools":[]}}`))
+	conn.Record(connection.Outbound, []byte(`{"jsonrpc":"2.0","id":1,"result":{"token":"abc123"}}`))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 NDJSON record, got %d: %s", len(lines), buf.String())
+	}
+
+	if strings.Contains(lines[0], "hunter2") || strings.Contains(lines[0], "abc123") {
+		t.Errorf("Expected payloads to be redacted: %s", lines[0])
+	}
+	golden.AssertJSON(t, "wire_record", []byte(lines[0]), golden.Redact("durationMs"))
+}
+
+func TestWireLoggerNotifications(t *testing.T) {
+	var buf bytes.Buffer
+	wl := NewWireLogger(WireLogConfig{Output: &buf})
+	conn := wl.Conn("stdio-1")
+
+	conn.Record(connection.Inbound, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	conn.Record(connection.Inbound, []byte(`{"jsonrpc":"2.0","id":"a","result":{}}`))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"method":"notifications/initialized"`) || strings.Contains(lines[0], `"response"`) {
+		t.Errorf("Expected only the notification, without a response: %s", buf.String())
+	}
+}
+
+func TestWireLoggerSampling(t *testing.T) {
+	var buf bytes.Buffer
+	wl := NewWireLogger(WireLogConfig{Output: &buf, SampleRate: 0.5})
+
+	rolls := []float64{0.1, 0.9, 0.4, 0.6}
+	wl.rand = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+
+	conn := wl.Conn("stdio-1")
+	for _, id := range []string{"0", "1", "2", "3"} {
+		conn.Record(connection.Inbound, []byte(`{"jsonrpc":"2.0","id":`+id+`,"method":"ping"}`))
+		conn.Record(connection.Outbound, []byte(`{"jsonrpc":"2.0","id":`+id+`,"result":{}}`))
+	}
+
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("Expected 2 sampled records, got %d", got)
+	}
+}
@@ -0,0 +1,183 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// CORSConfig holds origin validation and CORS settings for HTTP-based transports
+type CORSConfig struct {
+	// AllowedOrigins lists permitted browser origins. Entries may be exact
+	// ("https://app.example.com"), use a wildcard port ("http://localhost:*"),
+	// a wildcard subdomain ("https://*.example.com"), or "*" for any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods defaults to GET, POST, DELETE and OPTIONS
+	AllowedMethods []string
+
+	// AllowedHeaders defaults to Content-Type, Authorization,
+	// Mcp-Session-Id and Last-Event-ID
+	AllowedHeaders []string
+
+	// ExposedHeaders lists response headers readable by browser clients
+	ExposedHeaders []string
+
+	// AllowCredentials permits cookies and authorization headers
+	AllowCredentials bool
+
+	// MaxAge is the preflight cache lifetime in seconds
+	MaxAge int
+
+	// RejectMissingOrigin rejects requests without an Origin header. Non-browser
+	// clients normally omit it, so this is off by default.
+	RejectMissingOrigin bool
+}
+
+// DefaultCORSConfig returns a configuration that only admits local browser origins
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"http://localhost:*", "http://127.0.0.1:*"},
+		MaxAge:         600,
+	}
+}
+
+// OriginValidator checks request origins against a CORSConfig
+type OriginValidator struct {
+	config CORSConfig
+}
+
+// NewOriginValidator creates an origin validator, applying defaults for
+// unset methods and headers
+func NewOriginValidator(config CORSConfig) *OriginValidator {
+	if len(config.AllowedMethods) == 0 {
+		config.AllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions}
+	}
+	if len(config.AllowedHeaders) == 0 {
+		config.AllowedHeaders = []string{"Content-Type", "Authorization", "Mcp-Session-Id", "Last-Event-ID"}
+	}
+	return &OriginValidator{config: config}
+}
+
+// AllowOrigin reports whether origin is permitted
+func (v *OriginValidator) AllowOrigin(origin string) bool {
+	if origin == "" {
+		return !v.config.RejectMissingOrigin
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+
+	for _, allowed := range v.config.AllowedOrigins {
+		if allowed == "*" || matchOrigin(allowed, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin validates the Origin header of r. It has the signature
+// expected by WebSocket upgraders.
+func (v *OriginValidator) CheckOrigin(r *http.Request) bool {
+	return v.AllowOrigin(r.Header.Get("Origin"))
+}
+
+// Middleware rejects disallowed origins and adds CORS headers to responses,
+// answering preflight requests directly
+func (v *OriginValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+
+		if !v.AllowOrigin(origin) {
+			writeOriginRejection(w, origin)
+			return
+		}
+
+		if origin != "" {
+			v.setHeaders(w.Header(), origin)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setHeaders writes CORS response headers for an allowed origin
+func (v *OriginValidator) setHeaders(h http.Header, origin string) {
+	h.Add("Vary", "Origin")
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(v.config.AllowedMethods, ", "))
+	h.Set("Access-Control-Allow-Headers", strings.Join(v.config.AllowedHeaders, ", "))
+	if len(v.config.ExposedHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(v.config.ExposedHeaders, ", "))
+	}
+	if v.config.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if v.config.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(v.config.MaxAge))
+	}
+}
+
+// CORSMiddleware is a convenience wrapper creating a validator from config
+func CORSMiddleware(config CORSConfig) func(http.Handler) http.Handler {
+	return NewOriginValidator(config).Middleware
+}
+
+// writeOriginRejection writes a 403 response with a JSON-RPC error body
+func writeOriginRejection(w http.ResponseWriter, origin string) {
+	message := "Origin not allowed"
+	if origin == "" {
+		message = "Origin header required"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(jsonrpc.NewErrorResponse(
+		jsonrpc.NewError(jsonrpc.ErrorCodeForbidden, message,
+			fmt.Sprintf("origin %q is not in the server's allowed origins", origin)),
+		nil,
+	))
+}
+
+// matchOrigin matches an origin URL against a single allowlist pattern
+func matchOrigin(pattern string, origin *url.URL) bool {
+	// Patterns are split by hand because url.Parse rejects wildcard ports
+	scheme, host, ok := strings.Cut(pattern, "://")
+	if !ok || !strings.EqualFold(scheme, origin.Scheme) {
+		return false
+	}
+
+	patternHost, patternPort := splitHostPort(strings.TrimSuffix(host, "/"))
+	originHost, originPort := splitHostPort(origin.Host)
+
+	if patternPort != "*" && patternPort != originPort {
+		return false
+	}
+
+	patternHost = strings.ToLower(patternHost)
+	originHost = strings.ToLower(originHost)
+	if suffix, ok := strings.CutPrefix(patternHost, "*."); ok {
+		return strings.HasSuffix(originHost, "."+suffix)
+	}
+	return patternHost == originHost
+}
+
+// splitHostPort splits host:port, tolerating a missing or wildcard port
+func splitHostPort(hostport string) (host, port string) {
+	if i := strings.LastIndex(hostport, ":"); i >= 0 && !strings.HasSuffix(hostport, "]") {
+		return hostport[:i], hostport[i+1:]
+	}
+	return hostport, ""
+}
@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestOriginValidator_AllowOrigin(t *testing.T) {
+	v := NewOriginValidator(CORSConfig{
+		AllowedOrigins: []string{"http://localhost:*", "https://app.example.com", "https://*.trusted.dev"},
+	})
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},
+		{"http://localhost:3000", true},
+		{"http://localhost", true},
+		{"https://localhost:3000", false},
+		{"https://app.example.com", true},
+		{"https://app.example.com:8443", false},
+		{"https://evil.example.com", false},
+		{"https://ui.trusted.dev", true},
+		{"https://trusted.dev", false},
+		{"https://trusted.dev.evil.com", false},
+		{"null", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := v.AllowOrigin(tt.origin); got != tt.allowed {
+				t.Errorf("AllowOrigin(%q) = %v, want %v", tt.origin, got, tt.allowed)
+			}
+		})
+	}
+
+	strict := NewOriginValidator(CORSConfig{AllowedOrigins: []string{"*"}, RejectMissingOrigin: true})
+	if strict.AllowOrigin("") {
+		t.Error("Expected missing origin to be rejected in strict mode")
+	}
+	if !strict.AllowOrigin("https://anything.example") {
+		t.Error("Expected wildcard to allow any origin")
+	}
+}
+
+func TestOriginValidator_Middleware(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowCredentials = true
+	handler := CORSMiddleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("AllowedOrigin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/message", nil)
+		req.Header.Set("Origin", "http://localhost:5173")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rec.Code)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "http://localhost:5173" {
+			t.Error("Expected allow-origin header to echo the origin")
+		}
+		if rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Error("Expected allow-credentials header")
+		}
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/message", nil)
+		req.Header.Set("Origin", "http://127.0.0.1:8080")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d", rec.Code)
+		}
+		if rec.Header().Get("Access-Control-Max-Age") != "600" {
+			t.Error("Expected max-age header")
+		}
+	})
+
+	t.Run("DisallowedOrigin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/message", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("Expected 403, got %d", rec.Code)
+		}
+
+		var resp jsonrpc.Response
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Expected JSON-RPC error body: %v", err)
+		}
+		if resp.Error == nil || resp.Error.Code != jsonrpc.ErrorCodeForbidden {
+			t.Errorf("Expected forbidden error, got %+v", resp.Error)
+		}
+	})
+}
@@ -28,4 +28,16 @@
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
+//
+// Network transports terminate TLS with a CertManager, which serves static
+// certificate files (reloaded when they change) or ACME certificates:
+//
+//	cm, err := NewCertManager(CertConfig{CertFile: "server.crt", KeyFile: "server.key"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	go cm.Watch(ctx)
+//
+//	srv := &http.Server{Handler: sseServer, TLSConfig: cm.TLSConfig()}
+//	err = srv.ListenAndServeTLS("", "")
 package transport
\ No newline at end of file
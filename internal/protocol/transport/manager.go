@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -161,11 +162,26 @@ func (m *Manager) GetConnectionInfo(id string) (info ConnectionInfo, exists bool
 		pid, running := stdioTransport.GetProcessInfo()
 		info.ProcessID = pid
 		info.Running = running
+		info.Stats = stdioTransport.Stats()
 	}
 
 	return info, true
 }
 
+// Stats returns the traffic counters of every connection that keeps them
+func (m *Manager) Stats() map[string]TransportStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[string]TransportStats, len(m.connections))
+	for id, transport := range m.connections {
+		if counted, ok := transport.(interface{ Stats() TransportStats }); ok {
+			stats[id] = counted.Stats()
+		}
+	}
+	return stats
+}
+
 // Broadcast sends a message to all connected transports
 func (m *Manager) Broadcast(ctx context.Context, message jsonrpc.Message) error {
 	m.mu.RLock()
@@ -229,6 +245,7 @@ type ConnectionInfo struct {
 	// STDIO-specific
 	ProcessID int
 	Running   bool
+	Stats     TransportStats
 }
 
 // createSTDIOTransport creates a new STDIO transport from config
@@ -304,4 +321,27 @@ func (m *Manager) RestartConnection(id string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// RestartConnectionWithRetry restarts a connection like RestartConnection,
+// retrying failures to start the new transport with policy
+func (m *Manager) RestartConnectionWithRetry(ctx context.Context, id string, policy mcperrors.RetryPolicy) error {
+	m.mu.RLock()
+	config, exists := m.configs[id]
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("connection %s not found", id)
+	}
+	configCopy := *config
+
+	if err := m.RemoveConnection(id); err != nil {
+		return fmt.Errorf("failed to remove old connection: %w", err)
+	}
+
+	return mcperrors.Retry(ctx, policy, func() error {
+		if err := m.AddConnection(id, &configCopy); err != nil {
+			return mcperrors.NewConnectionFailedError(id, err)
+		}
+		return nil
+	})
+}
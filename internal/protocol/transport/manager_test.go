@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
@@ -213,6 +214,40 @@ func TestManagerRestartConnection(t *testing.T) {
 	}
 }
 
+// TestManagerRestartConnectionWithRetry tests restarts retried by policy
+func TestManagerRestartConnectionWithRetry(t *testing.T) {
+	manager := NewManager()
+	defer manager.Close()
+
+	if err := manager.AddConnection("test1", &ConnectionConfig{Type: ConnectionTypeSTDIO, Command: "cat"}); err != nil {
+		t.Fatalf("Failed to add connection: %v", err)
+	}
+
+	attempts := 0
+	policy := mcperrors.RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: time.Millisecond,
+		OnRetry:         func(int, error, time.Duration) { attempts++ },
+	}
+	if err := manager.RestartConnectionWithRetry(context.Background(), "test1", policy); err != nil {
+		t.Fatalf("Failed to restart connection: %v", err)
+	}
+	if attempts != 0 {
+		t.Errorf("Expected no retries, got %d", attempts)
+	}
+
+	// A command that cannot start exhausts the policy
+	manager.AddConnection("broken", &ConnectionConfig{Type: ConnectionTypeSTDIO, Command: "cat"})
+	manager.mu.Lock()
+	manager.configs["broken"].Command = "/nonexistent/command"
+	manager.mu.Unlock()
+
+	err := manager.RestartConnectionWithRetry(context.Background(), "broken", policy)
+	if mcperrors.FindMCPError(err) == nil || attempts != 2 {
+		t.Errorf("Expected connection failure after 2 retries, got %v after %d", err, attempts)
+	}
+}
+
 // TestManagerInvalidConnectionType tests invalid connection type
 func TestManagerInvalidConnectionType(t *testing.T) {
 	manager := NewManager()
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
@@ -37,6 +38,44 @@ type STDIOTransport struct {
 	// Process wait result
 	processErr chan error
 	waitOnce   sync.Once
+
+	// Traffic counters, updated without locks
+	messagesSent     atomic.Int64
+	messagesReceived atomic.Int64
+	bytesSent        atomic.Int64
+	bytesReceived    atomic.Int64
+}
+
+// TransportStats counts the traffic of a transport
+type TransportStats struct {
+	MessagesSent     int64
+	MessagesReceived int64
+	BytesSent        int64
+	BytesReceived    int64
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
 }
 
 // NewSTDIOTransport creates a new STDIO transport for the given command.
@@ -72,14 +111,14 @@ func NewSTDIOTransport(cmd *exec.Cmd) (*STDIOTransport, error) {
 		stdin:      stdin,
 		stdout:     stdout,
 		stderr:     stderr,
-		reader:     bufio.NewReader(stdout),
-		writer:     bufio.NewWriter(stdin),
 		codec:      &JSONCodec{},
 		connected:  true,
 		errChan:    make(chan error, 1),
 		done:       make(chan struct{}),
 		processErr: make(chan error, 1),
 	}
+	transport.reader = bufio.NewReader(countingReader{stdout, &transport.bytesReceived})
+	transport.writer = bufio.NewWriter(countingWriter{stdin, &transport.bytesSent})
 
 	// Start monitoring stderr in a goroutine
 	go transport.monitorStderr()
@@ -113,6 +152,7 @@ func (t *STDIOTransport) Send(ctx context.Context, message jsonrpc.Message) erro
 		return fmt.Errorf("failed to flush writer: %w", err)
 	}
 
+	t.messagesSent.Add(1)
 	return nil
 }
 
@@ -146,6 +186,7 @@ func (t *STDIOTransport) Receive(ctx context.Context) (jsonrpc.Message, error) {
 		if res.err != nil {
 			return nil, fmt.Errorf("failed to decode message: %w", res.err)
 		}
+		t.messagesReceived.Add(1)
 		return res.msg, nil
 	case <-t.done:
 		return nil, fmt.Errorf("transport closed")
@@ -175,6 +216,7 @@ func (t *STDIOTransport) SendBatch(ctx context.Context, messages []jsonrpc.Messa
 		return fmt.Errorf("failed to flush writer: %w", err)
 	}
 
+	t.messagesSent.Add(int64(len(messages)))
 	return nil
 }
 
@@ -208,6 +250,7 @@ func (t *STDIOTransport) ReceiveBatch(ctx context.Context) ([]jsonrpc.Message, e
 		if res.err != nil {
 			return nil, fmt.Errorf("failed to decode batch: %w", res.err)
 		}
+		t.messagesReceived.Add(int64(len(res.msgs)))
 		return res.msgs, nil
 	case <-t.done:
 		return nil, fmt.Errorf("transport closed")
@@ -280,6 +323,17 @@ func (t *STDIOTransport) GetProcessInfo() (pid int, running bool) {
 	return
 }
 
+// Stats returns the traffic counters of the transport. Reading them takes
+// no lock, so it does not contend with sends and receives.
+func (t *STDIOTransport) Stats() TransportStats {
+	return TransportStats{
+		MessagesSent:     t.messagesSent.Load(),
+		MessagesReceived: t.messagesReceived.Load(),
+		BytesSent:        t.bytesSent.Load(),
+		BytesReceived:    t.bytesReceived.Load(),
+	}
+}
+
 // monitorStderr monitors the stderr output from the subprocess
 func (t *STDIOTransport) monitorStderr() {
 	scanner := bufio.NewScanner(t.stderr)
@@ -333,8 +387,7 @@ type JSONCodec struct{}
 
 // Encode encodes a message to JSON with newline delimiter
 func (c *JSONCodec) Encode(w io.Writer, message jsonrpc.Message) error {
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(message); err != nil {
+	if err := jsonrpc.Encode(w, message); err != nil {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
 	return nil
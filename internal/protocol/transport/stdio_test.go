@@ -75,6 +75,34 @@ func TestSTDIOTransportSendReceive(t *testing.T) {
 	// For now, we'll test the error cases
 }
 
+// TestSTDIOTransportStats tests the traffic counters
+func TestSTDIOTransportStats(t *testing.T) {
+	transport, err := NewSTDIOTransport(exec.Command("cat"))
+	if err != nil {
+		t.Fatalf("Failed to create STDIO transport: %v", err)
+	}
+	defer transport.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := transport.Send(ctx, jsonrpc.NewRequest("test_method", nil, 1)); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	if _, err := transport.Receive(ctx); err != nil {
+		t.Fatalf("Failed to receive message: %v", err)
+	}
+
+	stats := transport.Stats()
+	if stats.MessagesSent != 1 || stats.MessagesReceived != 1 {
+		t.Errorf("Expected 1 message each way, got %+v", stats)
+	}
+	// cat echoes every byte back
+	if stats.BytesSent == 0 || stats.BytesReceived != stats.BytesSent {
+		t.Errorf("Expected matching byte counts, got %+v", stats)
+	}
+}
+
 // TestSTDIOTransportBatch tests batch send and receive
 func TestSTDIOTransportBatch(t *testing.T) {
 	// Create a mock subprocess
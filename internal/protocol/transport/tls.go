@@ -0,0 +1,282 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+)
+
+// DefaultCertReloadInterval is how often certificate files are checked for changes
+const DefaultCertReloadInterval = 30 * time.Second
+
+// CertConfig holds TLS termination settings for network transports
+type CertConfig struct {
+	// Static certificate and key files (PEM)
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile enables mutual TLS when set
+	ClientCAFile string
+
+	// ReloadInterval controls how often certificate files are checked for
+	// changes. Zero uses DefaultCertReloadInterval; negative disables reload.
+	ReloadInterval time.Duration
+
+	// MinVersion is the minimum TLS version (defaults to TLS 1.2)
+	MinVersion uint16
+
+	// ACME enables automatic certificates instead of static files
+	ACME *ACMEConfig
+}
+
+// ACMEConfig holds settings for automatic certificate management
+type ACMEConfig struct {
+	// Hosts lists the host names certificates may be issued for
+	Hosts []string
+
+	// CacheDir stores issued certificates across restarts
+	CacheDir string
+
+	// Email is the contact address registered with the CA
+	Email string
+
+	// DirectoryURL overrides the ACME directory (defaults to Let's Encrypt)
+	DirectoryURL string
+}
+
+// CertStats contains certificate manager statistics
+type CertStats struct {
+	Reloads      int64
+	ReloadErrors int64
+	LastReload   time.Time
+	LastError    error
+	NotAfter     time.Time
+}
+
+// CertManager provides certificates for TLS listeners, reloading static
+// files when they change or delegating to ACME
+type CertManager struct {
+	config   CertConfig
+	autocert *autocert.Manager
+	clientCA *x509.CertPool
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	certMod  time.Time
+	keyMod   time.Time
+	stats    CertStats
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewCertManager creates a certificate manager from config. Static
+// certificates are loaded immediately so configuration errors surface at
+// startup rather than on the first handshake.
+func NewCertManager(config CertConfig) (*CertManager, error) {
+	if config.ReloadInterval == 0 {
+		config.ReloadInterval = DefaultCertReloadInterval
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	cm := &CertManager{
+		config: config,
+		stopCh: make(chan struct{}),
+	}
+
+	if config.ClientCAFile != "" {
+		pem, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", config.ClientCAFile)
+		}
+		cm.clientCA = pool
+	}
+
+	switch {
+	case config.ACME != nil:
+		if len(config.ACME.Hosts) == 0 {
+			return nil, fmt.Errorf("ACME requires at least one host")
+		}
+		cm.autocert = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.ACME.Hosts...),
+			Email:      config.ACME.Email,
+		}
+		if config.ACME.CacheDir != "" {
+			cm.autocert.Cache = autocert.DirCache(config.ACME.CacheDir)
+		}
+		if config.ACME.DirectoryURL != "" {
+			cm.autocert.Client = &acme.Client{DirectoryURL: config.ACME.DirectoryURL}
+		}
+	case config.CertFile != "" && config.KeyFile != "":
+		if err := cm.Reload(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("TLS requires either cert/key files or ACME configuration")
+	}
+
+	return cm, nil
+}
+
+// Reload loads the static certificate files, replacing the served
+// certificate only if the new pair is valid
+func (cm *CertManager) Reload() error {
+	certInfo, certErr := os.Stat(cm.config.CertFile)
+	keyInfo, keyErr := os.Stat(cm.config.KeyFile)
+
+	cert, err := tls.LoadX509KeyPair(cm.config.CertFile, cm.config.KeyFile)
+	if err == nil && (certErr != nil || keyErr != nil) {
+		err = fmt.Errorf("failed to stat certificate files")
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if err != nil {
+		cm.stats.ReloadErrors++
+		cm.stats.LastError = err
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	if cert.Leaf == nil && len(cert.Certificate) > 0 {
+		cert.Leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	}
+
+	cm.cert = &cert
+	cm.certMod = certInfo.ModTime()
+	cm.keyMod = keyInfo.ModTime()
+	cm.stats.Reloads++
+	cm.stats.LastReload = time.Now()
+	cm.stats.LastError = nil
+	if cert.Leaf != nil {
+		cm.stats.NotAfter = cert.Leaf.NotAfter
+	}
+
+	return nil
+}
+
+// GetCertificate returns the certificate for a TLS handshake
+func (cm *CertManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cm.autocert != nil {
+		return cm.autocert.GetCertificate(hello)
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.cert == nil {
+		return nil, fmt.Errorf("no certificate loaded")
+	}
+	return cm.cert, nil
+}
+
+// TLSConfig returns a server TLS configuration backed by the manager
+func (cm *CertManager) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion:     cm.config.MinVersion,
+		GetCertificate: cm.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if cm.autocert != nil {
+		config.NextProtos = append(config.NextProtos, acme.ALPNProto)
+	}
+	if cm.clientCA != nil {
+		config.ClientCAs = cm.clientCA
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config
+}
+
+// Listen creates a TLS listener on the given network address
+func (cm *CertManager) Listen(network, addr string) (net.Listener, error) {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(ln, cm.TLSConfig()), nil
+}
+
+// HTTPHandler wraps fallback with the ACME HTTP-01 challenge handler. Without
+// ACME configured it returns fallback unchanged.
+func (cm *CertManager) HTTPHandler(fallback http.Handler) http.Handler {
+	if cm.autocert == nil {
+		return fallback
+	}
+	return cm.autocert.HTTPHandler(fallback)
+}
+
+// Watch reloads the static certificate whenever the files change until ctx
+// is cancelled or Stop is called
+func (cm *CertManager) Watch(ctx context.Context) {
+	if cm.autocert != nil || cm.config.ReloadInterval < 0 {
+		return
+	}
+
+	logger := logging.Default().WithComponent("tls")
+	ticker := time.NewTicker(cm.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cm.stopCh:
+			return
+		case <-ticker.C:
+			if !cm.changed() {
+				continue
+			}
+			if err := cm.Reload(); err != nil {
+				logger.Error(ctx, err, "Certificate reload failed, keeping previous certificate")
+				continue
+			}
+			logger.WithField("cert_file", cm.config.CertFile).Info(ctx, "Certificate reloaded")
+		}
+	}
+}
+
+// Stop terminates Watch
+func (cm *CertManager) Stop() {
+	cm.stopOnce.Do(func() {
+		close(cm.stopCh)
+	})
+}
+
+// GetStats returns certificate manager statistics
+func (cm *CertManager) GetStats() CertStats {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.stats
+}
+
+// changed reports whether the certificate or key file has been modified
+func (cm *CertManager) changed() bool {
+	certInfo, err := os.Stat(cm.config.CertFile)
+	if err != nil {
+		return false
+	}
+	keyInfo, err := os.Stat(cm.config.KeyFile)
+	if err != nil {
+		return false
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return !certInfo.ModTime().Equal(cm.certMod) || !keyInfo.ModTime().Equal(cm.keyMod)
+}
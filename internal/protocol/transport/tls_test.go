@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate for commonName to dir
+func writeTestCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestCertManager_Static(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir(), "first.test")
+
+	cm, err := NewCertManager(CertConfig{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	cert, err := cm.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetCertificate failed: %v", err)
+	}
+	if cert.Leaf.Subject.CommonName != "first.test" {
+		t.Errorf("Expected first.test, got %s", cert.Leaf.Subject.CommonName)
+	}
+
+	config := cm.TLSConfig()
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected TLS 1.2 minimum, got %x", config.MinVersion)
+	}
+}
+
+func TestCertManager_WatchReloads(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "first.test")
+
+	cm, err := NewCertManager(CertConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cm.Watch(ctx)
+
+	// Ensure the modification time differs on coarse-grained filesystems
+	writeTestCert(t, dir, "second.test")
+	future := time.Now().Add(time.Second)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		cert, _ := cm.GetCertificate(&tls.ClientHelloInfo{})
+		if cert.Leaf.Subject.CommonName == "second.test" {
+			if cm.GetStats().Reloads != 2 {
+				t.Errorf("Expected 2 reloads, got %d", cm.GetStats().Reloads)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Certificate was not reloaded")
+}
+
+func TestCertManager_InvalidReloadKeepsCertificate(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir(), "first.test")
+
+	cm, err := NewCertManager(CertConfig{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("Failed to create cert manager: %v", err)
+	}
+
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Reload(); err == nil {
+		t.Fatal("Expected reload error for invalid certificate")
+	}
+
+	cert, err := cm.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil || cert.Leaf.Subject.CommonName != "first.test" {
+		t.Error("Expected previous certificate to remain in service")
+	}
+	if cm.GetStats().ReloadErrors != 1 {
+		t.Errorf("Expected 1 reload error, got %d", cm.GetStats().ReloadErrors)
+	}
+}
+
+func TestCertManager_Config(t *testing.T) {
+	if _, err := NewCertManager(CertConfig{}); err == nil {
+		t.Error("Expected error without certificate source")
+	}
+	if _, err := NewCertManager(CertConfig{ACME: &ACMEConfig{}}); err == nil {
+		t.Error("Expected error for ACME without hosts")
+	}
+
+	cm, err := NewCertManager(CertConfig{ACME: &ACMEConfig{Hosts: []string{"mcp.example.com"}}})
+	if err != nil {
+		t.Fatalf("Failed to create ACME cert manager: %v", err)
+	}
+	found := false
+	for _, proto := range cm.TLSConfig().NextProtos {
+		if proto == "acme-tls/1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected ACME ALPN protocol to be advertised")
+	}
+}
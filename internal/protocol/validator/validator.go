@@ -152,7 +152,7 @@ func (v *SchemaValidator) ValidateMessage(ctx context.Context, messageType strin
 	}
 
 	if !result.Valid() {
-		return v.formatValidationErrors(result.Errors())
+		return FormatErrors(result.Errors())
 	}
 
 	return nil
@@ -256,8 +256,8 @@ func (v *SchemaValidator) IsEnabled() bool {
 	return v.enabled
 }
 
-// formatValidationErrors converts gojsonschema errors to our ValidationError format
-func (v *SchemaValidator) formatValidationErrors(errs []gojsonschema.ResultError) error {
+// FormatErrors converts gojsonschema errors to our ValidationError format
+func FormatErrors(errs []gojsonschema.ResultError) error {
 	if len(errs) == 0 {
 		return nil
 	}
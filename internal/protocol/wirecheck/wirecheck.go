@@ -0,0 +1,236 @@
+// Package wirecheck numbers and checksums JSON-RPC messages to isolate
+// transport bugs during interop debugging.
+//
+// In debug mode the server stamps every response and notification it
+// writes to a connection with `_meta.wire`: a sequence number counting the
+// stamped messages of the connection and a CRC-32C checksum of the
+// message's payload, i.e. its params, result or error data. A client that
+// verifies the stamps tells a corrupted message (checksum mismatch) from a
+// dropped or duplicated one (sequence gap or repeat):
+//
+//	stamped, err := wirecheck.StampMessage(message, seq)
+//	...
+//	seq, err := wirecheck.Verify(result)
+//	if err == nil {
+//		err = sequence.Observe(seq)
+//	}
+//
+// Checksums cover the payload re-encoded in a canonical form, with the
+// stamp removed, so they survive decoding and re-encoding by either side.
+package wirecheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"sync"
+)
+
+// MetaKey is the `_meta` key of the stamp
+const MetaKey = "wire"
+
+var (
+	// ErrNoStamp is returned by Verify for payloads without a stamp, e.g.
+	// from a server not in debug mode
+	ErrNoStamp = errors.New("message has no wire stamp")
+
+	// ErrChecksum is returned by Verify for payloads whose checksum does not
+	// match their content
+	ErrChecksum = errors.New("wire checksum mismatch")
+
+	// ErrSequence is returned by Sequence.Observe for messages received
+	// twice or missing
+	ErrSequence = errors.New("wire sequence broken")
+)
+
+// table is the CRC-32C table checksums are computed with
+var table = crc32.MakeTable(crc32.Castagnoli)
+
+// Stamp is the value of `_meta.wire`
+type Stamp struct {
+	Seq      uint64 `json:"seq"`
+	Checksum string `json:"checksum"`
+}
+
+// Stamped reports whether message is a response or notification, the
+// messages StampMessage applies to. Requests are not stamped: the transports of
+// clients answer them without handing them to the client.
+func Stamped(message []byte) bool {
+	var m struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(message, &m); err != nil {
+		return false
+	}
+	return m.Method == "" || m.ID == nil
+}
+
+// StampMessage returns message, an encoded response or notification, with
+// the stamp of seq added to its payload
+func StampMessage(message []byte, seq uint64) ([]byte, error) {
+	var m map[string]any
+	if err := json.Unmarshal(message, &m); err != nil {
+		return nil, fmt.Errorf("decode message: %w", err)
+	}
+
+	var payload map[string]any
+	switch {
+	case m["method"] != nil:
+		payload = object(m, "params")
+	case m["result"] != nil:
+		payload = object(m, "result")
+	case m["error"] != nil:
+		rpcErr, ok := m["error"].(map[string]any)
+		if !ok {
+			return nil, errors.New("error is not an object")
+		}
+		payload = object(rpcErr, "data")
+	}
+	if payload == nil {
+		return nil, errors.New("message has no object payload")
+	}
+
+	checksum, err := checksum(payload)
+	if err != nil {
+		return nil, err
+	}
+	meta, _ := payload["_meta"].(map[string]any)
+	if meta == nil {
+		meta = make(map[string]any)
+		payload["_meta"] = meta
+	}
+	meta[MetaKey] = Stamp{Seq: seq, Checksum: checksum}
+	return json.Marshal(m)
+}
+
+// Verify checks the stamp of payload, the params, result or error data of
+// a message, and returns its sequence number
+func Verify(payload json.RawMessage) (uint64, error) {
+	var p map[string]any
+	if err := json.Unmarshal(payload, &p); err != nil || p == nil {
+		return 0, ErrNoStamp
+	}
+	meta, _ := p["_meta"].(map[string]any)
+	raw, ok := meta[MetaKey]
+	if !ok {
+		return 0, ErrNoStamp
+	}
+	var stamp Stamp
+	data, _ := json.Marshal(raw)
+	if err := json.Unmarshal(data, &stamp); err != nil || stamp.Checksum == "" {
+		return 0, ErrNoStamp
+	}
+
+	delete(meta, MetaKey)
+	sum, err := checksum(p)
+	if err != nil {
+		return stamp.Seq, err
+	}
+	if sum != stamp.Checksum {
+		return stamp.Seq, fmt.Errorf("%w: message %d has checksum %s, stamped %s", ErrChecksum, stamp.Seq, sum, stamp.Checksum)
+	}
+	return stamp.Seq, nil
+}
+
+// object returns the object under key of m, adding an empty one when the
+// key is missing, or nil when the value is not an object
+func object(m map[string]any, key string) map[string]any {
+	value, ok := m[key]
+	if !ok || value == nil {
+		created := make(map[string]any)
+		m[key] = created
+		return created
+	}
+	o, _ := value.(map[string]any)
+	return o
+}
+
+// checksum returns the checksum of the canonical encoding of payload:
+// numbers as float64, object keys sorted as encoding/json writes a decoded
+// document, and without an empty _meta, which the stamp may have added
+func checksum(payload map[string]any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encode payload: %w", err)
+	}
+	var canonical map[string]any
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+	if meta, ok := canonical["_meta"].(map[string]any); ok && len(meta) == 0 {
+		delete(canonical, "_meta")
+	}
+	if data, err = json.Marshal(canonical); err != nil {
+		return "", fmt.Errorf("encode payload: %w", err)
+	}
+	return fmt.Sprintf("%08x", crc32.Checksum(data, table)), nil
+}
+
+// DefaultWindow is how many later messages Sequence waits for a missing one
+const DefaultWindow = 64
+
+// Sequence tracks the sequence numbers of the messages received over one
+// connection. Messages may be observed slightly out of order, e.g. when
+// responses and notifications are handled on different goroutines, so a
+// missing number is only reported once Window later ones were observed.
+// It is safe for concurrent use.
+type Sequence struct {
+	// Window bounds the messages observed past a missing one before it is
+	// reported (defaults to DefaultWindow)
+	Window int
+
+	mu   sync.Mutex
+	next uint64 // lowest number not observed yet
+	seen map[uint64]bool
+}
+
+// Observe records the message numbered seq, returning an ErrSequence error
+// for a message observed twice or for messages now considered missing
+func (s *Sequence) Observe(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next == 0 {
+		s.next = 1
+	}
+	if s.seen == nil {
+		s.seen = make(map[uint64]bool)
+	}
+	if seq < s.next || s.seen[seq] {
+		return fmt.Errorf("%w: message %d received twice", ErrSequence, seq)
+	}
+	s.seen[seq] = true
+	s.advance()
+
+	window := s.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if len(s.seen) <= window {
+		return nil
+	}
+
+	// Give up on the numbers below the oldest message still waiting
+	pending := make([]uint64, 0, len(s.seen))
+	for n := range s.seen {
+		pending = append(pending, n)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i] < pending[j] })
+	first, last := s.next, pending[0]-1
+	s.next = pending[0]
+	s.advance()
+	if first == last {
+		return fmt.Errorf("%w: message %d missing", ErrSequence, first)
+	}
+	return fmt.Errorf("%w: messages %d to %d missing", ErrSequence, first, last)
+}
+
+// advance moves next past the observed numbers
+func (s *Sequence) advance() {
+	for s.seen[s.next] {
+		delete(s.seen, s.next)
+		s.next++
+	}
+}
@@ -0,0 +1,120 @@
+package wirecheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// payloadOf returns the payload of an encoded message the way a client
+// sees it
+func payloadOf(t *testing.T, message []byte) json.RawMessage {
+	t.Helper()
+	var m struct {
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+		Error  struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(message, &m); err != nil {
+		t.Fatalf("decode %s: %v", message, err)
+	}
+	switch {
+	case m.Params != nil:
+		return m.Params
+	case m.Result != nil:
+		return m.Result
+	}
+	return m.Error.Data
+}
+
+func TestStampAndVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{"notification", `{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"t","progress":1.0}}`},
+		{"notification without params", `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`},
+		{"result", `{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"echo"}]}}`},
+		{"result with meta", `{"jsonrpc":"2.0","id":1,"result":{"_meta":{"seq":3},"content":[]}}`},
+		{"empty result", `{"jsonrpc":"2.0","id":1,"result":{}}`},
+		{"error", `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`},
+		{"error with data", `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params","data":{"field":"name"}}}`},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !Stamped([]byte(tt.message)) {
+				t.Fatal("Stamped() = false, want true")
+			}
+			stamped, err := StampMessage([]byte(tt.message), uint64(i+1))
+			if err != nil {
+				t.Fatalf("StampMessage() error = %v", err)
+			}
+			seq, err := Verify(payloadOf(t, stamped))
+			if err != nil {
+				t.Fatalf("Verify(%s) error = %v", stamped, err)
+			}
+			if seq != uint64(i+1) {
+				t.Errorf("Verify() seq = %d, want %d", seq, i+1)
+			}
+		})
+	}
+}
+
+func TestVerify_DetectsCorruption(t *testing.T) {
+	stamped, err := StampMessage([]byte(`{"jsonrpc":"2.0","id":1,"result":{"text":"hello"}}`), 1)
+	if err != nil {
+		t.Fatalf("StampMessage() error = %v", err)
+	}
+	corrupted := bytes.Replace(stamped, []byte("hello"), []byte("hellp"), 1)
+	if _, err := Verify(payloadOf(t, corrupted)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Verify() error = %v, want ErrChecksum", err)
+	}
+
+	if _, err := Verify(json.RawMessage(`{"text":"hello"}`)); !errors.Is(err, ErrNoStamp) {
+		t.Errorf("Verify(unstamped) error = %v, want ErrNoStamp", err)
+	}
+}
+
+func TestStamped_SkipsRequests(t *testing.T) {
+	if Stamped([]byte(`{"jsonrpc":"2.0","id":"srv-1","method":"ping"}`)) {
+		t.Error("Stamped(request) = true, want false")
+	}
+	if Stamped([]byte(`not json`)) {
+		t.Error("Stamped(invalid) = true, want false")
+	}
+}
+
+func TestSequence(t *testing.T) {
+	s := &Sequence{Window: 2}
+
+	// Slight reordering is tolerated
+	for _, seq := range []uint64{1, 3, 2, 4} {
+		if err := s.Observe(seq); err != nil {
+			t.Fatalf("Observe(%d) error = %v", seq, err)
+		}
+	}
+
+	if err := s.Observe(2); !errors.Is(err, ErrSequence) {
+		t.Errorf("Observe(repeated) error = %v, want ErrSequence", err)
+	}
+
+	// 5 is missing; it is reported once the window fills up
+	for _, seq := range []uint64{6, 7} {
+		if err := s.Observe(seq); err != nil {
+			t.Fatalf("Observe(%d) error = %v", seq, err)
+		}
+	}
+	err := s.Observe(8)
+	if !errors.Is(err, ErrSequence) {
+		t.Fatalf("Observe(8) error = %v, want ErrSequence", err)
+	}
+	if want := "wire sequence broken: message 5 missing"; err.Error() != want {
+		t.Errorf("Observe(8) error = %q, want %q", err, want)
+	}
+	if err := s.Observe(9); err != nil {
+		t.Errorf("Observe(9) error = %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// AdminMethod is the admin RPC method reporting quota usage
+const AdminMethod = "admin/quota"
+
+// Middleware enforces the tracker's quotas on the router. Requests for
+// methods outside the tracker's method set pass through untouched. Dry runs
+// of tools/call are checked but not recorded.
+func Middleware(t *Tracker) router.Middleware {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			if !t.Applies(req.Method) {
+				return next.Handle(ctx, req)
+			}
+
+			reservation, err := t.Check(t.Identity(ctx))
+			if err != nil {
+				return jsonrpc.NewErrorResponse(err.ToJSONRPCError(), req.ID)
+			}
+
+			defer settle(reservation, isDryRun(req), time.Now())
+			return next.Handle(ctx, req)
+		})
+	}
+}
+
+// ToolMiddleware enforces the tracker's quotas on mcp-go tool handlers.
+// It is installed with server.WithToolHandlerMiddleware. Dry runs are
+// checked but not recorded.
+func ToolMiddleware(t *Tracker) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			reservation, err := t.Check(t.Identity(ctx))
+			if err != nil {
+				return nil, err
+			}
+
+			defer settle(reservation, tools.IsDryRun(request), time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// settle completes the reservation of a call started at start, releasing it
+// for dry runs. It runs even when the handler panics, so the call does not
+// stay pending.
+func settle(reservation *Reservation, dryRun bool, start time.Time) {
+	if dryRun {
+		reservation.Release()
+		return
+	}
+	reservation.Settle(time.Since(start))
+}
+
+// isDryRun reports whether req is a tools/call asking for a dry run
+func isDryRun(req *jsonrpc.Request) bool {
+	if req.Method != "tools/call" {
+		return false
+	}
+	var params struct {
+		Meta *mcp.Meta `json:"_meta,omitempty"`
+	}
+	if err := req.BindParams(&params); err != nil {
+		return false
+	}
+	return tools.IsDryRunMeta(params.Meta)
+}
+
+// AdminParams are the parameters of the admin/quota method
+type AdminParams struct {
+	// Identity limits the report to a single identity
+	Identity string `json:"identity,omitempty"`
+}
+
+// AdminResult is the result of the admin/quota method
+type AdminResult struct {
+	Usage map[string][]Usage `json:"usage"`
+	Stats Stats              `json:"stats"`
+}
+
+// AdminHandler returns a router handler reporting remaining quota for one
+// or all identities. Register it under AdminMethod.
+func AdminHandler(t *Tracker) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params AdminParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+
+		result := AdminResult{Stats: t.GetStats()}
+		if params.Identity != "" {
+			result.Usage = map[string][]Usage{params.Identity: t.Usage(params.Identity)}
+		} else {
+			result.Usage = t.Snapshot()
+		}
+
+		return jsonrpc.NewResponse(result, req.ID)
+	})
+}
@@ -0,0 +1,489 @@
+// Package quota tracks per-identity tool-call budgets over rolling windows.
+//
+// A Tracker records the number of calls and the cumulative execution time
+// spent on behalf of each identity. Each configured Limit describes a
+// rolling window; calls that would exceed any window are rejected with a
+// quota error whose data carries the remaining budget, so clients can back
+// off intelligently. Check reserves the call it admits, so concurrent calls
+// cannot overrun a limit together; calls still running count with the time
+// they have taken so far.
+//
+// The identity is the one a transport authenticated the client as (see
+// connection.WithIdentity), e.g. the identity that listen.tokens maps the
+// client's bearer token to.
+// Clients of transports that authenticate nobody are accounted per
+// connection, so reconnecting starts them on a fresh budget.
+//
+// Basic usage:
+//
+//	tracker := quota.NewTracker(quota.Config{
+//		Limits: []quota.Limit{
+//			{Window: time.Minute, MaxCalls: 60},
+//			{Window: time.Hour, MaxExecTime: 10 * time.Minute},
+//		},
+//	})
+//
+//	chain := router.NewChain(quota.Middleware(tracker))
+//
+// Set Config.Store to keep ledgers across restarts; recorded calls are
+// written through to the store and reloaded by NewTracker.
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/storage"
+)
+
+// IdentityMetadataKey is the RequestContext metadata key holding the
+// authenticated identity of the caller
+const IdentityMetadataKey = "identity"
+
+// AnonymousIdentity is used when no identity can be resolved
+const AnonymousIdentity = "anonymous"
+
+// StorageBucket is the store bucket holding one ledger per identity
+const StorageBucket = "quota"
+
+// Limit describes a budget over a rolling window. A zero MaxCalls or
+// MaxExecTime disables that dimension of the limit.
+type Limit struct {
+	Window      time.Duration `json:"window"`
+	MaxCalls    int64         `json:"maxCalls,omitempty"`
+	MaxExecTime time.Duration `json:"maxExecTime,omitempty"`
+}
+
+// IdentityFunc resolves the identity a request is accounted against
+type IdentityFunc func(ctx context.Context) string
+
+// Config contains configuration for a Tracker
+type Config struct {
+	// Limits apply to every identity without an override
+	Limits []Limit
+
+	// Overrides replace Limits for specific identities
+	Overrides map[string][]Limit
+
+	// Identity resolves the caller identity (defaults to DefaultIdentity)
+	Identity IdentityFunc
+
+	// Methods lists the router methods subject to quota (defaults to tools/call)
+	Methods []string
+
+	// Now returns the current time (defaults to time.Now)
+	Now func() time.Time
+
+	// Store persists ledgers across restarts (nil keeps them in memory)
+	Store storage.Store
+
+	// Events receives rejected requests (defaults to events.Default())
+	Events *events.Bus
+}
+
+// Usage reports consumption against a single Limit
+type Usage struct {
+	Identity          string        `json:"identity"`
+	Window            time.Duration `json:"window"`
+	Calls             int64         `json:"calls"`
+	CallsLimit        int64         `json:"callsLimit,omitempty"`
+	CallsRemaining    int64         `json:"callsRemaining,omitempty"`
+	ExecTime          time.Duration `json:"execTime"`
+	ExecTimeLimit     time.Duration `json:"execTimeLimit,omitempty"`
+	ExecTimeRemaining time.Duration `json:"execTimeRemaining,omitempty"`
+	ResetAt           time.Time     `json:"resetAt,omitempty"`
+}
+
+// Exceeded reports whether the usage has exhausted its limit
+func (u Usage) Exceeded() bool {
+	if u.CallsLimit > 0 && u.Calls >= u.CallsLimit {
+		return true
+	}
+	if u.ExecTimeLimit > 0 && u.ExecTime >= u.ExecTimeLimit {
+		return true
+	}
+	return false
+}
+
+// Stats contains tracker statistics
+type Stats struct {
+	Identities int   `json:"identities"`
+	Allowed    int64 `json:"allowed"`
+	Rejected   int64 `json:"rejected"`
+}
+
+// event is a single recorded call
+type event struct {
+	at       time.Time
+	duration time.Duration
+
+	// pending is set until the reserved call is settled
+	pending bool
+}
+
+// elapsed returns the duration of the call, or the time it has taken so far
+// while it is pending
+func (e *event) elapsed(now time.Time) time.Duration {
+	if e.pending {
+		return max(now.Sub(e.at), 0)
+	}
+	return e.duration
+}
+
+// storedEvent is the persisted form of an event
+type storedEvent struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ledger holds the recorded calls for one identity
+type ledger struct {
+	events []*event
+}
+
+// Tracker records and enforces per-identity quotas
+type Tracker struct {
+	mu        sync.Mutex
+	config    Config
+	ledgers   map[string]*ledger
+	methods   map[string]bool
+	maxWindow time.Duration
+	allowed   int64
+	rejected  int64
+}
+
+// NewTracker creates a new quota tracker
+func NewTracker(config Config) *Tracker {
+	if config.Identity == nil {
+		config.Identity = DefaultIdentity
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{"tools/call"}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	config.Events = events.Or(config.Events)
+
+	t := &Tracker{
+		config:  config,
+		ledgers: make(map[string]*ledger),
+		methods: make(map[string]bool, len(config.Methods)),
+	}
+	for _, m := range config.Methods {
+		t.methods[m] = true
+	}
+	for _, l := range config.Limits {
+		t.maxWindow = max(t.maxWindow, l.Window)
+	}
+	for _, limits := range config.Overrides {
+		for _, l := range limits {
+			t.maxWindow = max(t.maxWindow, l.Window)
+		}
+	}
+	if config.Store != nil {
+		t.load()
+	}
+
+	return t
+}
+
+// DefaultIdentity resolves the identity from RequestContext metadata or
+// the identity the transport authenticated, falling back to the connection
+// ID and finally AnonymousIdentity
+func DefaultIdentity(ctx context.Context) string {
+	if rc, ok := router.GetRequestContext(ctx); ok {
+		if id, ok := rc.GetMetadataString(IdentityMetadataKey); ok && id != "" {
+			return id
+		}
+	}
+	if id, ok := connection.IdentityFromContext(ctx); ok {
+		return id
+	}
+	if id, ok := connection.GetConnectionID(ctx); ok && id != "" {
+		return id
+	}
+	return AnonymousIdentity
+}
+
+// Identity resolves the identity for ctx using the configured IdentityFunc
+func (t *Tracker) Identity(ctx context.Context) string {
+	return t.config.Identity(ctx)
+}
+
+// Applies reports whether method is subject to quota
+func (t *Tracker) Applies(method string) bool {
+	return t.methods[method]
+}
+
+// Reservation is a call admitted by Check. It counts against the budget of
+// its identity from the start; Settle it with the time the call took, or
+// Release it when the call should not count.
+type Reservation struct {
+	tracker  *Tracker
+	identity string
+	event    *event
+}
+
+// Check reserves a call for identity, or returns a quota exceeded error if
+// identity has no budget left in any window. The error data contains the
+// usage for every window.
+func (t *Tracker) Check(identity string) (*Reservation, *mcperrors.MCPError) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := t.usageLocked(identity)
+	for _, u := range usage {
+		if u.Exceeded() {
+			t.rejected++
+			events.Publish(t.config.Events, events.QuotaExceeded, events.QuotaExceededEvent{
+				Identity: identity,
+				Window:   u.Window.String(),
+				At:       t.config.Now(),
+			})
+			return nil, NewExceededError(identity, usage)
+		}
+	}
+
+	t.allowed++
+	e := &event{at: t.config.Now(), pending: true}
+	l := t.ledgerLocked(identity)
+	l.events = append(l.events, e)
+	return &Reservation{tracker: t, identity: identity, event: e}, nil
+}
+
+// Settle records that the reserved call completed after duration
+func (r *Reservation) Settle(duration time.Duration) {
+	t := r.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r.event.duration = duration
+	r.event.pending = false
+	t.persistLocked(r.identity)
+}
+
+// Release drops the reserved call from the budget, e.g. for a dry run
+func (r *Reservation) Release() {
+	t := r.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l, ok := t.ledgers[r.identity]
+	if !ok {
+		return
+	}
+	for i, e := range l.events {
+		if e == r.event {
+			l.events = append(l.events[:i], l.events[i+1:]...)
+			break
+		}
+	}
+	t.persistLocked(r.identity)
+}
+
+// Record accounts a completed call of the given duration against identity
+// without checking its budget
+func (t *Tracker) Record(identity string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l := t.ledgerLocked(identity)
+	l.events = append(l.events, &event{at: t.config.Now(), duration: duration})
+	t.persistLocked(identity)
+}
+
+// ledgerLocked returns the ledger of identity, creating it. Caller must
+// hold t.mu.
+func (t *Tracker) ledgerLocked(identity string) *ledger {
+	l, ok := t.ledgers[identity]
+	if !ok {
+		l = &ledger{}
+		t.ledgers[identity] = l
+	}
+	return l
+}
+
+// Usage returns the current usage of identity for every configured window
+func (t *Tracker) Usage(identity string) []Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usageLocked(identity)
+}
+
+// Snapshot returns the usage of every tracked identity
+func (t *Tracker) Snapshot() map[string][]Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make(map[string][]Usage, len(t.ledgers))
+	for identity := range t.ledgers {
+		snapshot[identity] = t.usageLocked(identity)
+	}
+	return snapshot
+}
+
+// Identities returns the tracked identities in sorted order
+func (t *Tracker) Identities() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := make([]string, 0, len(t.ledgers))
+	for identity := range t.ledgers {
+		ids = append(ids, identity)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Reset clears the recorded usage of identity
+func (t *Tracker) Reset(identity string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.ledgers, identity)
+	t.persistLocked(identity)
+}
+
+// GetStats returns tracker statistics
+func (t *Tracker) GetStats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return Stats{
+		Identities: len(t.ledgers),
+		Allowed:    t.allowed,
+		Rejected:   t.rejected,
+	}
+}
+
+// load restores the ledgers saved in the store
+func (t *Tracker) load() {
+	ctx := logging.WithComponent(context.Background(), "quota")
+	entries, err := t.config.Store.List(ctx, StorageBucket, "")
+	if err != nil {
+		logging.Default().Error(ctx, err, "Failed to load quota ledgers")
+		return
+	}
+
+	for _, entry := range entries {
+		var stored []storedEvent
+		if err := json.Unmarshal(entry.Value, &stored); err != nil {
+			logging.Default().WithField("identity", entry.Key).Error(ctx, err, "Discarding corrupt quota ledger")
+			continue
+		}
+		l := &ledger{events: make([]*event, len(stored))}
+		for i, e := range stored {
+			l.events[i] = &event{at: e.At, duration: e.Duration}
+		}
+		t.ledgers[entry.Key] = l
+	}
+}
+
+// persistLocked writes the ledger of identity to the store, deleting it once
+// empty. Caller must hold t.mu.
+func (t *Tracker) persistLocked(identity string) {
+	if t.config.Store == nil {
+		return
+	}
+
+	ctx := logging.WithComponent(context.Background(), "quota")
+	l, ok := t.ledgers[identity]
+	if !ok || len(l.events) == 0 {
+		if err := t.config.Store.Delete(ctx, StorageBucket, identity); err != nil {
+			logging.Default().WithField("identity", identity).Error(ctx, err, "Failed to delete quota ledger")
+		}
+		return
+	}
+
+	// Calls still running are stored with the time they took so far
+	now := t.config.Now()
+	stored := make([]storedEvent, len(l.events))
+	for i, e := range l.events {
+		stored[i] = storedEvent{At: e.at, Duration: e.elapsed(now)}
+	}
+	if err := storage.PutJSON(ctx, t.config.Store, StorageBucket, identity, stored); err != nil {
+		logging.Default().WithField("identity", identity).Error(ctx, err, "Failed to persist quota ledger")
+	}
+}
+
+// limitsFor returns the limits that apply to identity
+func (t *Tracker) limitsFor(identity string) []Limit {
+	if limits, ok := t.config.Overrides[identity]; ok {
+		return limits
+	}
+	return t.config.Limits
+}
+
+// usageLocked prunes expired events and computes usage. Caller must hold t.mu.
+func (t *Tracker) usageLocked(identity string) []Usage {
+	now := t.config.Now()
+	limits := t.limitsFor(identity)
+	l := t.ledgers[identity]
+
+	if l != nil {
+		cutoff := now.Add(-t.maxWindow)
+		i := 0
+		for i < len(l.events) && !l.events[i].at.After(cutoff) {
+			i++
+		}
+		l.events = l.events[i:]
+		if len(l.events) == 0 {
+			delete(t.ledgers, identity)
+			l = nil
+		}
+	}
+
+	usage := make([]Usage, 0, len(limits))
+	for _, limit := range limits {
+		u := Usage{
+			Identity:      identity,
+			Window:        limit.Window,
+			CallsLimit:    limit.MaxCalls,
+			ExecTimeLimit: limit.MaxExecTime,
+		}
+
+		if l != nil {
+			cutoff := now.Add(-limit.Window)
+			for _, e := range l.events {
+				if !e.at.After(cutoff) {
+					continue
+				}
+				if u.Calls == 0 {
+					u.ResetAt = e.at.Add(limit.Window)
+				}
+				u.Calls++
+				u.ExecTime += e.elapsed(now)
+			}
+		}
+
+		if limit.MaxCalls > 0 {
+			u.CallsRemaining = max(limit.MaxCalls-u.Calls, 0)
+		}
+		if limit.MaxExecTime > 0 {
+			u.ExecTimeRemaining = max(limit.MaxExecTime-u.ExecTime, 0)
+		}
+
+		usage = append(usage, u)
+	}
+
+	return usage
+}
+
+// NewExceededError creates the error returned when identity is over budget
+func NewExceededError(identity string, usage []Usage) *mcperrors.MCPError {
+	err := mcperrors.NewMCPError(mcperrors.ErrorCodeMCPQuotaExceeded,
+		"Too many requests: quota exceeded", map[string]interface{}{
+			"identity": identity,
+			"usage":    usage,
+		})
+	err.WithContext("identity", identity)
+	return err
+}
@@ -0,0 +1,302 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/storage"
+)
+
+// fakeClock is a manually advanced time source
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// checked checks a call of identity, settling it at once when admitted
+func checked(tracker *Tracker, identity string) *mcperrors.MCPError {
+	reservation, err := tracker.Check(identity)
+	if err == nil {
+		reservation.Settle(0)
+	}
+	return err
+}
+
+func newTestTracker(limits ...Limit) (*Tracker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	return NewTracker(Config{Limits: limits, Now: clock.Now}), clock
+}
+
+func TestTracker_CallLimit(t *testing.T) {
+	tracker, clock := newTestTracker(Limit{Window: time.Minute, MaxCalls: 2})
+
+	for i := 0; i < 2; i++ {
+		require.Nil(t, checked(tracker, "alice"))
+	}
+
+	err := checked(tracker, "alice")
+	require.NotNil(t, err)
+	assert.Equal(t, mcperrors.ErrorCodeMCPQuotaExceeded, err.Code)
+
+	data := err.Data.(map[string]interface{})
+	usage := data["usage"].([]Usage)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(2), usage[0].Calls)
+	assert.Equal(t, int64(0), usage[0].CallsRemaining)
+	assert.Equal(t, clock.now.Add(time.Minute), usage[0].ResetAt)
+
+	// Other identities are unaffected
+	assert.Nil(t, checked(tracker, "bob"))
+
+	// Budget is restored once the window rolls past the recorded calls
+	clock.Advance(time.Minute + time.Second)
+	assert.Nil(t, checked(tracker, "alice"))
+
+	stats := tracker.GetStats()
+	assert.Equal(t, int64(1), stats.Rejected)
+	assert.Equal(t, int64(4), stats.Allowed)
+}
+
+func TestTracker_PublishesExceeded(t *testing.T) {
+	bus := events.New()
+	exceeded := make(chan events.QuotaExceededEvent, 1)
+	events.Subscribe(bus, events.QuotaExceeded, func(e events.QuotaExceededEvent) { exceeded <- e })
+	defer bus.Close()
+
+	tracker := NewTracker(Config{Limits: []Limit{{Window: time.Minute, MaxCalls: 1}}, Events: bus})
+	tracker.Record("alice", time.Millisecond)
+	require.NotNil(t, checked(tracker, "alice"))
+
+	select {
+	case e := <-exceeded:
+		assert.Equal(t, "alice", e.Identity)
+		assert.Equal(t, "1m0s", e.Window)
+	case <-time.After(time.Second):
+		t.Fatal("quota exceeded event not published")
+	}
+}
+
+func TestTracker_ExecTimeLimit(t *testing.T) {
+	tracker, clock := newTestTracker(
+		Limit{Window: time.Minute, MaxCalls: 100},
+		Limit{Window: time.Hour, MaxExecTime: 10 * time.Second},
+	)
+
+	tracker.Record("alice", 6*time.Second)
+	assert.Nil(t, checked(tracker, "alice"))
+
+	clock.Advance(2 * time.Minute)
+	tracker.Record("alice", 5*time.Second)
+
+	usage := tracker.Usage("alice")
+	require.Len(t, usage, 2)
+	assert.Equal(t, int64(1), usage[0].Calls)
+	assert.Equal(t, int64(99), usage[0].CallsRemaining)
+	assert.Equal(t, 11*time.Second, usage[1].ExecTime)
+	assert.Equal(t, time.Duration(0), usage[1].ExecTimeRemaining)
+
+	assert.NotNil(t, checked(tracker, "alice"))
+}
+
+func TestTracker_Reservations(t *testing.T) {
+	tracker, clock := newTestTracker(
+		Limit{Window: time.Minute, MaxCalls: 2},
+		Limit{Window: time.Hour, MaxExecTime: 10 * time.Second},
+	)
+
+	// Reserved calls count before they complete, with the time they took
+	// so far
+	first, err := tracker.Check("alice")
+	require.Nil(t, err)
+	second, err := tracker.Check("alice")
+	require.Nil(t, err)
+	_, err = tracker.Check("alice")
+	assert.NotNil(t, err)
+
+	clock.Advance(4 * time.Second)
+	assert.Equal(t, 8*time.Second, tracker.Usage("alice")[1].ExecTime)
+
+	// Released calls stop counting; settled ones count their duration
+	second.Release()
+	first.Settle(3 * time.Second)
+	usage := tracker.Usage("alice")
+	assert.Equal(t, int64(1), usage[0].Calls)
+	assert.Equal(t, 3*time.Second, usage[1].ExecTime)
+}
+
+func TestToolMiddleware_ConcurrentCalls(t *testing.T) {
+	tracker, _ := newTestTracker(Limit{Window: time.Minute, MaxCalls: 1})
+
+	release := make(chan struct{})
+	var ran atomic.Int64
+	handler := ToolMiddleware(tracker)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ran.Add(1)
+		<-release
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	const calls = 10
+	var rejected atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+				rejected.Add(1)
+			}
+		}()
+	}
+
+	// Every call but the admitted one is rejected while it runs
+	require.Eventually(t, func() bool { return rejected.Load() == calls-1 }, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(1), ran.Load())
+	assert.Equal(t, int64(1), tracker.Usage(AnonymousIdentity)[0].Calls)
+}
+
+func TestTracker_Overrides(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	tracker := NewTracker(Config{
+		Limits:    []Limit{{Window: time.Minute, MaxCalls: 1}},
+		Overrides: map[string][]Limit{"batch-bot": {{Window: time.Minute, MaxCalls: 3}}},
+		Now:       clock.Now,
+	})
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, checked(tracker, "batch-bot"))
+	}
+	assert.NotNil(t, checked(tracker, "batch-bot"))
+
+	tracker.Reset("batch-bot")
+	assert.Nil(t, checked(tracker, "batch-bot"))
+}
+
+func TestTracker_Store(t *testing.T) {
+	store := storage.NewMemoryStore()
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limit := Limit{Window: time.Minute, MaxCalls: 2}
+
+	tracker := NewTracker(Config{Limits: []Limit{limit}, Now: clock.Now, Store: store})
+	tracker.Record("alice", time.Second)
+	tracker.Record("alice", time.Second)
+	tracker.Record("bob", time.Second)
+	tracker.Reset("bob")
+
+	// A restarted tracker picks up where the last one left off
+	restarted := NewTracker(Config{Limits: []Limit{limit}, Now: clock.Now, Store: store})
+	assert.Equal(t, []string{"alice"}, restarted.Identities())
+	_, err := restarted.Check("alice")
+	require.NotNil(t, err)
+	assert.Equal(t, mcperrors.ErrorCodeMCPQuotaExceeded, err.Code)
+	assert.Equal(t, 2*time.Second, restarted.Usage("alice")[0].ExecTime)
+
+	require.NoError(t, store.Put(context.Background(), StorageBucket, "mallory", []byte("{")))
+	restarted = NewTracker(Config{Limits: []Limit{limit}, Now: clock.Now, Store: store})
+	assert.Equal(t, []string{"alice"}, restarted.Identities())
+}
+
+func TestDefaultIdentity(t *testing.T) {
+	assert.Equal(t, AnonymousIdentity, DefaultIdentity(context.Background()))
+
+	ctx := connection.WithConnectionID(context.Background(), "conn-1")
+	assert.Equal(t, "conn-1", DefaultIdentity(ctx))
+
+	// Identities authenticated by the transport outlive the connection
+	authenticated := connection.WithIdentity(ctx, "ops")
+	assert.Equal(t, "ops", DefaultIdentity(authenticated))
+
+	rc := router.NewRequestContext("corr-1")
+	rc.SetMetadata(IdentityMetadataKey, "alice")
+	ctx = router.WithRequestContext(ctx, rc)
+	assert.Equal(t, "alice", DefaultIdentity(ctx))
+}
+
+func TestMiddleware(t *testing.T) {
+	tracker, _ := newTestTracker(Limit{Window: time.Minute, MaxCalls: 1})
+
+	handler := router.NewChain(Middleware(tracker)).ThenFunc(
+		func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			return jsonrpc.NewResponse("ok", req.ID)
+		})
+
+	ctx := connection.WithConnectionID(context.Background(), "conn-1")
+	call := &jsonrpc.Request{ID: 1, Method: "tools/call"}
+
+	// Dry runs are checked without spending the budget
+	resp := handler.Handle(ctx, &jsonrpc.Request{ID: 1, Method: "tools/call",
+		Params: map[string]any{"name": "echo", "_meta": map[string]any{"dryRun": true}}})
+	assert.Nil(t, resp.Error)
+
+	resp = handler.Handle(ctx, call)
+	assert.Nil(t, resp.Error)
+
+	resp = handler.Handle(ctx, call)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPQuotaExceeded, resp.Error.Code)
+	assert.NotNil(t, resp.Error.Data)
+
+	// Methods outside the quota set are not counted
+	resp = handler.Handle(ctx, &jsonrpc.Request{ID: 2, Method: "tools/list"})
+	assert.Nil(t, resp.Error)
+}
+
+func TestToolMiddleware(t *testing.T) {
+	tracker, _ := newTestTracker(Limit{Window: time.Minute, MaxCalls: 1})
+
+	handler := ToolMiddleware(tracker)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	dryRun := mcp.CallToolRequest{}
+	dryRun.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{"dryRun": true}}
+	_, err := handler(context.Background(), dryRun)
+	assert.NoError(t, err)
+
+	_, err = handler(context.Background(), mcp.CallToolRequest{})
+	assert.NoError(t, err)
+
+	_, err = handler(context.Background(), dryRun)
+	var mcpErr *mcperrors.MCPError
+	require.ErrorAs(t, err, &mcpErr)
+	assert.Equal(t, mcperrors.ErrorCodeMCPQuotaExceeded, mcpErr.Code)
+}
+
+func TestAdminHandler(t *testing.T) {
+	tracker, _ := newTestTracker(Limit{Window: time.Minute, MaxCalls: 5})
+	tracker.Record("alice", time.Second)
+	tracker.Record("bob", time.Second)
+
+	handler := AdminHandler(tracker)
+
+	resp := handler.Handle(context.Background(), &jsonrpc.Request{ID: 1, Method: AdminMethod})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(AdminResult)
+	assert.Len(t, result.Usage, 2)
+
+	resp = handler.Handle(context.Background(), &jsonrpc.Request{
+		ID:     2,
+		Method: AdminMethod,
+		Params: map[string]interface{}{"identity": "alice"},
+	})
+	require.Nil(t, resp.Error)
+	result = resp.Result.(AdminResult)
+	require.Len(t, result.Usage["alice"], 1)
+	assert.Equal(t, int64(4), result.Usage["alice"][0].CallsRemaining)
+}
@@ -0,0 +1,270 @@
+// Package redact provides the secret redaction engine shared by error
+// sanitization, structured logging, and audit outputs.
+//
+// A Redactor combines three kinds of rules:
+//
+//   - Key patterns: map keys containing a sensitive fragment ("password",
+//     "token", "api_key", ...) have their whole value replaced.
+//   - Value patterns: regular expressions matched against string values
+//     regardless of key (AWS access keys, JWTs, bearer tokens, PEM blocks).
+//   - Custom rules: arbitrary functions for deployment-specific secrets.
+//
+// Basic usage:
+//
+//	r := redact.New(
+//		redact.WithSensitiveKeys("ssn"),
+//		redact.WithValuePattern("internal_token", regexp.MustCompile(`itk_[a-z0-9]{32}`)),
+//	)
+//
+//	clean := r.Map(map[string]interface{}{"password": "hunter2", "user": "bob"})
+//	// clean["password"] == "[REDACTED]"
+//
+// Packages that need redaction without their own configuration should use
+// the package-level helpers, which delegate to Default().
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Placeholder is the default replacement for redacted values.
+const Placeholder = "[REDACTED]"
+
+// DefaultSensitiveKeys lists key fragments that mark a value as sensitive.
+// Matching is case-insensitive and uses substring comparison.
+var DefaultSensitiveKeys = []string{
+	"password", "passwd", "token", "secret", "auth", "credential",
+	"session", "cookie", "bearer", "api_key", "apikey", "private",
+	"access_key", "secret_key", "private_key", "public_key",
+}
+
+// ValuePattern is a named regular expression matched against string values.
+type ValuePattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultValuePatterns detects common credential formats inside free text.
+var DefaultValuePatterns = []ValuePattern{
+	{Name: "aws_access_key_id", Regexp: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "jwt", Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{Name: "bearer_token", Regexp: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]+=*`)},
+	{Name: "github_token", Regexp: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "private_key_block", Regexp: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// Rule is a custom redaction rule. Redact returns the replacement value and
+// true when the rule applied; key is empty for free-standing strings.
+type Rule interface {
+	Redact(key, value string) (string, bool)
+}
+
+// RuleFunc is a function type that implements Rule
+type RuleFunc func(key, value string) (string, bool)
+
+// Redact implements the Rule interface
+func (f RuleFunc) Redact(key, value string) (string, bool) {
+	return f(key, value)
+}
+
+// Redactor applies key patterns, value patterns, and custom rules.
+// A Redactor is immutable after construction and safe for concurrent use.
+type Redactor struct {
+	keys        []string
+	patterns    []ValuePattern
+	rules       []Rule
+	placeholder string
+}
+
+// Option configures a Redactor
+type Option func(*Redactor)
+
+// WithSensitiveKeys adds key fragments to the sensitive key list
+func WithSensitiveKeys(keys ...string) Option {
+	return func(r *Redactor) {
+		for _, k := range keys {
+			r.keys = append(r.keys, strings.ToLower(k))
+		}
+	}
+}
+
+// WithValuePattern adds a named value pattern
+func WithValuePattern(name string, re *regexp.Regexp) Option {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, ValuePattern{Name: name, Regexp: re})
+	}
+}
+
+// WithRule adds a custom redaction rule
+func WithRule(rule Rule) Option {
+	return func(r *Redactor) {
+		r.rules = append(r.rules, rule)
+	}
+}
+
+// WithPlaceholder overrides the replacement string
+func WithPlaceholder(placeholder string) Option {
+	return func(r *Redactor) {
+		r.placeholder = placeholder
+	}
+}
+
+// WithoutDefaults clears the default key and value patterns so that only
+// explicitly configured rules apply. It must be the first option.
+func WithoutDefaults() Option {
+	return func(r *Redactor) {
+		r.keys = nil
+		r.patterns = nil
+	}
+}
+
+// New creates a Redactor with the default key and value patterns plus any
+// additional rules supplied as options.
+func New(opts ...Option) *Redactor {
+	r := &Redactor{
+		keys:        append([]string(nil), DefaultSensitiveKeys...),
+		patterns:    append([]ValuePattern(nil), DefaultValuePatterns...),
+		placeholder: Placeholder,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// IsSensitiveKey reports whether values stored under key must be redacted
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	keyLower := strings.ToLower(key)
+	for _, sensitive := range r.keys {
+		if strings.Contains(keyLower, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// String redacts secrets embedded in a free-standing string
+func (r *Redactor) String(s string) string {
+	return r.redactString("", s)
+}
+
+// Value redacts a value stored under key. Sensitive keys are replaced
+// wholesale; maps, slices and strings are redacted recursively.
+func (r *Redactor) Value(key string, value interface{}) interface{} {
+	if key != "" && r.IsSensitiveKey(key) {
+		return r.placeholder
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.redactString(key, v)
+	case map[string]interface{}:
+		return r.Map(v)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if r.IsSensitiveKey(k) {
+				out[k] = r.placeholder
+			} else {
+				out[k] = r.redactString(k, s)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.Value("", item)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = r.redactString("", s)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// Map returns a redacted copy of m. The input map is not modified.
+func (r *Redactor) Map(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = r.Value(k, v)
+	}
+	return out
+}
+
+// JSON redacts a raw JSON document. Documents that fail to parse are
+// treated as plain strings so callers always get a safe result.
+func (r *Redactor) JSON(raw []byte) []byte {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return []byte(r.String(string(raw)))
+	}
+	out, err := json.Marshal(r.Value("", doc))
+	if err != nil {
+		return []byte(r.placeholder)
+	}
+	return out
+}
+
+// redactString applies value patterns and custom rules to s
+func (r *Redactor) redactString(key, s string) string {
+	for _, p := range r.patterns {
+		s = p.Regexp.ReplaceAllString(s, r.placeholder)
+	}
+	for _, rule := range r.rules {
+		if replaced, ok := rule.Redact(key, s); ok {
+			s = replaced
+		}
+	}
+	return s
+}
+
+// Global redactor instance
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	defaultRedactor.Store(New())
+}
+
+// SetDefault sets the default global redactor. It is safe to call while
+// other goroutines redact.
+func SetDefault(r *Redactor) {
+	defaultRedactor.Store(r)
+}
+
+// Default returns the default global redactor
+func Default() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// Convenience functions using the default redactor
+
+// IsSensitiveKey reports whether key is sensitive using the default redactor
+func IsSensitiveKey(key string) bool {
+	return Default().IsSensitiveKey(key)
+}
+
+// String redacts s using the default redactor
+func String(s string) string {
+	return Default().String(s)
+}
+
+// Value redacts a keyed value using the default redactor
+func Value(key string, value interface{}) interface{} {
+	return Default().Value(key, value)
+}
+
+// Map redacts m using the default redactor
+func Map(m map[string]interface{}) map[string]interface{} {
+	return Default().Map(m)
+}
@@ -0,0 +1,235 @@
+package resources
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// Validators identify a version of a resource, like HTTP ETag and
+// Last-Modified. The zero value matches nothing.
+type Validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// IsZero reports whether no validator is set
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified.IsZero()
+}
+
+// Version is the result of a conditional read
+type Version struct {
+	// Contents are empty when NotModified is set
+	Contents []mcp.ResourceContents
+
+	// Validators identify the contents; resources without validators are
+	// not cached
+	Validators Validators
+
+	// NotModified reports that the resource still matches the validators
+	// it was read with
+	NotModified bool
+}
+
+// ConditionalReadFunc reads a resource unless it still matches cached, the
+// validators of a cached copy (zero when there is none)
+type ConditionalReadFunc func(ctx context.Context, uri string, cached Validators) (Version, error)
+
+// CacheConfig contains configuration for a Cache
+type CacheConfig struct {
+	// MaxEntries caps the number of cached resources (defaults to 256)
+	MaxEntries int
+
+	// MaxBytes caps the total size of cached contents (defaults to 16 MiB)
+	MaxBytes int64
+
+	// MaxAge is how long an entry is served without revalidation; zero
+	// revalidates on every read
+	MaxAge time.Duration
+
+	// Clock times MaxAge (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// CacheStats counts cache activity
+type CacheStats struct {
+	// Hits were served without asking the provider
+	Hits int64
+
+	// Revalidations were served after the provider reported no change
+	Revalidations int64
+
+	// Misses were read in full
+	Misses int64
+
+	// Evictions were dropped to respect the limits
+	Evictions int64
+
+	Entries int
+	Bytes   int64
+}
+
+// Cache keeps the contents of resources/read results by URI and
+// revalidates them with their provider's validators, so unchanged
+// resources are not read again
+type Cache struct {
+	config CacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
+}
+
+// cacheEntry is a cached resource
+type cacheEntry struct {
+	uri        string
+	contents   []mcp.ResourceContents
+	validators Validators
+	size       int64
+	checked    time.Time
+}
+
+// NewCache creates a resource cache
+func NewCache(config CacheConfig) *Cache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 256
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 16 << 20
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &Cache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Read returns the contents of uri from the cache when they are fresh or
+// read reports them unchanged, and reads them otherwise
+func (c *Cache) Read(ctx context.Context, uri string, read ConditionalReadFunc) ([]mcp.ResourceContents, error) {
+	c.mu.Lock()
+	var cached Validators
+	if e, ok := c.lookup(uri); ok {
+		if c.config.MaxAge > 0 && c.config.Clock.Since(e.checked) < c.config.MaxAge {
+			c.stats.Hits++
+			c.mu.Unlock()
+			return slices.Clone(e.contents), nil
+		}
+		cached = e.validators
+	}
+	c.mu.Unlock()
+
+	version, err := read(ctx, uri, cached)
+	if err != nil {
+		c.Invalidate(uri)
+		return nil, err
+	}
+
+	if version.NotModified {
+		c.mu.Lock()
+		if e, ok := c.lookup(uri); ok && e.validators == cached {
+			c.stats.Revalidations++
+			if !version.Validators.IsZero() {
+				e.validators = version.Validators
+			}
+			e.checked = c.config.Clock.Now()
+			c.mu.Unlock()
+			return slices.Clone(e.contents), nil
+		}
+		c.mu.Unlock()
+
+		// The entry was evicted or replaced while revalidating
+		if version, err = read(ctx, uri, Validators{}); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Misses++
+	c.store(uri, version)
+	return slices.Clone(version.Contents), nil
+}
+
+// Invalidate drops the cached contents of uri
+func (c *Cache) Invalidate(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[uri]; ok {
+		c.remove(elem)
+	}
+}
+
+// Stats returns the cache counters
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// lookup returns the entry of uri, marking it recently used
+func (c *Cache) lookup(uri string) (*cacheEntry, bool) {
+	elem, ok := c.entries[uri]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry), true
+}
+
+// store caches version if it has validators and fits, evicting the least
+// recently used entries as needed
+func (c *Cache) store(uri string, version Version) {
+	if elem, ok := c.entries[uri]; ok {
+		c.remove(elem)
+	}
+	size := contentsSize(version.Contents)
+	if version.Validators.IsZero() || size > c.config.MaxBytes {
+		return
+	}
+
+	c.entries[uri] = c.lru.PushFront(&cacheEntry{
+		uri:        uri,
+		contents:   version.Contents,
+		validators: version.Validators,
+		size:       size,
+		checked:    c.config.Clock.Now(),
+	})
+	c.stats.Bytes += size
+	for c.lru.Len() > c.config.MaxEntries || c.stats.Bytes > c.config.MaxBytes {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove drops an entry
+func (c *Cache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, e.uri)
+	c.stats.Bytes -= e.size
+}
+
+// contentsSize approximates the memory held by contents
+func contentsSize(contents []mcp.ResourceContents) int64 {
+	var size int64
+	for _, content := range contents {
+		switch content := content.(type) {
+		case mcp.TextResourceContents:
+			size += int64(len(content.URI) + len(content.MIMEType) + len(content.Text))
+		case mcp.BlobResourceContents:
+			size += int64(len(content.URI) + len(content.MIMEType) + len(content.Blob))
+		}
+	}
+	return size
+}
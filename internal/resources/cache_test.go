@@ -0,0 +1,190 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// fakeOrigin serves versioned text resources and counts reads
+type fakeOrigin struct {
+	versions map[string]string
+	reads    int
+	full     int
+}
+
+func (o *fakeOrigin) read(ctx context.Context, uri string, cached Validators) (Version, error) {
+	o.reads++
+	etag, ok := o.versions[uri]
+	if !ok {
+		return Version{}, errors.New("not found")
+	}
+	if cached.ETag == etag {
+		return Version{Validators: cached, NotModified: true}, nil
+	}
+	o.full++
+	return Version{
+		Contents:   []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, Text: uri + "@" + etag}},
+		Validators: Validators{ETag: etag},
+	}, nil
+}
+
+func text(t *testing.T, contents []mcp.ResourceContents) string {
+	t.Helper()
+	require.Len(t, contents, 1)
+	return contents[0].(mcp.TextResourceContents).Text
+}
+
+func TestCache_Revalidate(t *testing.T) {
+	origin := &fakeOrigin{versions: map[string]string{"a": "1"}}
+	cache := NewCache(CacheConfig{})
+	ctx := context.Background()
+
+	contents, err := cache.Read(ctx, "a", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, "a@1", text(t, contents))
+
+	// Unchanged resources are revalidated, not read again
+	contents, err = cache.Read(ctx, "a", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, "a@1", text(t, contents))
+	assert.Equal(t, 2, origin.reads)
+	assert.Equal(t, 1, origin.full)
+
+	origin.versions["a"] = "2"
+	contents, err = cache.Read(ctx, "a", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, "a@2", text(t, contents))
+
+	// Failed reads drop the entry
+	delete(origin.versions, "a")
+	_, err = cache.Read(ctx, "a", origin.read)
+	assert.Error(t, err)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Revalidations)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 0, stats.Entries)
+	assert.Zero(t, stats.Bytes)
+}
+
+func TestCache_MaxAge(t *testing.T) {
+	origin := &fakeOrigin{versions: map[string]string{"a": "1"}}
+	fake := clock.NewFake(time.Unix(0, 0))
+	cache := NewCache(CacheConfig{MaxAge: time.Minute, Clock: fake})
+	ctx := context.Background()
+
+	for range 3 {
+		_, err := cache.Read(ctx, "a", origin.read)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, origin.reads)
+	assert.Equal(t, int64(2), cache.Stats().Hits)
+
+	fake.Advance(time.Minute)
+	_, err := cache.Read(ctx, "a", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, 2, origin.reads)
+
+	cache.Invalidate("a")
+	_, err = cache.Read(ctx, "a", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, 2, origin.full)
+}
+
+func TestCache_Limits(t *testing.T) {
+	origin := &fakeOrigin{versions: map[string]string{"a": "1", "b": "1", "c": "1"}}
+	cache := NewCache(CacheConfig{MaxEntries: 2})
+	ctx := context.Background()
+
+	for _, uri := range []string{"a", "b", "a", "c"} {
+		_, err := cache.Read(ctx, uri, origin.read)
+		require.NoError(t, err)
+	}
+	// b was the least recently used
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, int64(1), stats.Evictions)
+	full := origin.full
+	_, err := cache.Read(ctx, "b", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, full+1, origin.full)
+
+	// Contents larger than MaxBytes are not cached
+	cache = NewCache(CacheConfig{MaxBytes: 2})
+	_, err = cache.Read(ctx, "a", origin.read)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestFileProvider_ReadConditional(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o644))
+	p, err := NewFileProvider(FileConfig{Roots: []Root{{Name: "project", Path: dir}}})
+	require.NoError(t, err)
+	defer p.Close()
+	ctx := context.Background()
+	uri := URI("project", "notes.txt")
+
+	version, err := p.ReadConditional(ctx, uri, Validators{})
+	require.NoError(t, err)
+	assert.False(t, version.NotModified)
+	assert.NotEmpty(t, version.Validators.ETag)
+	assert.False(t, version.Validators.LastModified.IsZero())
+
+	again, err := p.ReadConditional(ctx, uri, version.Validators)
+	require.NoError(t, err)
+	assert.True(t, again.NotModified)
+	assert.Empty(t, again.Contents)
+
+	require.NoError(t, os.WriteFile(path, []byte("v2 longer"), 0o644))
+	changed, err := p.ReadConditional(ctx, uri, version.Validators)
+	require.NoError(t, err)
+	assert.False(t, changed.NotModified)
+	assert.Equal(t, "v2 longer", text(t, changed.Contents))
+}
+
+func TestHTTPProvider_ReadConditional(t *testing.T) {
+	var conditional []string
+	srv := newTestHTTPServer(t)
+	srv.Config.Handler = withETag(srv.Config.Handler, &conditional)
+	p := newTestHTTPProvider(t, nil)
+	ctx := context.Background()
+
+	version, err := p.ReadConditional(ctx, srv.URL+"/page", Validators{})
+	require.NoError(t, err)
+	assert.Equal(t, `"page-v1"`, version.Validators.ETag)
+	assert.Equal(t, "<p>hello</p>", text(t, version.Contents))
+
+	again, err := p.ReadConditional(ctx, srv.URL+"/page", version.Validators)
+	require.NoError(t, err)
+	assert.True(t, again.NotModified)
+	assert.Equal(t, []string{"", `"page-v1"`}, conditional)
+}
+
+// withETag adds a fixed ETag to responses and answers matching conditional
+// requests with 304, recording their If-None-Match headers
+func withETag(next http.Handler, conditional *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + strings.TrimPrefix(r.URL.Path, "/") + `-v1"`
+		*conditional = append(*conditional, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
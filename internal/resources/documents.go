@@ -0,0 +1,365 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// MetaVersion is the _meta key of resources/subscribe naming the version
+// of a document the client holds, to catch up from it
+const MetaVersion = "version"
+
+// Position is a place in a text document: a zero-based line and the
+// number of characters (Unicode code points) before it on that line
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is the text between two positions
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextEdit replaces the text in Range of a document with Text
+type TextEdit struct {
+	Range Range  `json:"range"`
+	Text  string `json:"text"`
+}
+
+// DocumentUpdate are the params of the notifications/resources/updated
+// sent to subscribers of a document: applying Edits in order to version
+// BaseVersion gives Version
+type DocumentUpdate struct {
+	URI         string     `json:"uri"`
+	Version     int64      `json:"version"`
+	BaseVersion int64      `json:"baseVersion"`
+	Edits       []TextEdit `json:"edits"`
+}
+
+// SyncResult is the result of resources/subscribe for a document. A client
+// catching up from a version the server still has the edits since gets
+// them in Edits; any other client gets the whole document in Contents.
+type SyncResult struct {
+	Version     int64                  `json:"version"`
+	BaseVersion int64                  `json:"baseVersion,omitempty"`
+	Edits       []TextEdit             `json:"edits,omitempty"`
+	Contents    []mcp.ResourceContents `json:"contents,omitempty"`
+}
+
+// NotifyFunc sends a notification to the connection with the given ID
+type NotifyFunc func(connectionID, method string, params map[string]any) error
+
+// DocumentsConfig contains configuration for Documents
+type DocumentsConfig struct {
+	// Read returns the current contents of a resource; text resources can
+	// be subscribed to
+	Read func(ctx context.Context, uri string) ([]mcp.ResourceContents, error)
+
+	// Notify delivers updates to subscribers
+	Notify NotifyFunc
+
+	// History is the number of updates kept per document for clients
+	// catching up (defaults to 64)
+	History int
+
+	// Events tells when connections close, dropping their subscriptions
+	// (defaults to events.Default())
+	Events *events.Bus
+}
+
+// Documents keeps text resources clients subscribed to, like a language
+// server keeps open documents. Each change bumps the document's version
+// and reaches subscribers as edits from the previous version instead of a
+// bare resources/updated, so they do not have to read the document again.
+// A client that misses an update notices a BaseVersion other than the
+// version it holds and subscribes again, naming that version in
+// _meta.version, to resync.
+type Documents struct {
+	config DocumentsConfig
+	sub    *events.Subscription
+
+	mu   sync.Mutex
+	docs map[string]*document
+}
+
+// document is a subscribed text resource
+type document struct {
+	text        string
+	mimeType    string
+	version     int64
+	history     []DocumentUpdate
+	subscribers map[string]struct{}
+}
+
+// NewDocuments creates an empty document set
+func NewDocuments(config DocumentsConfig) *Documents {
+	if config.History <= 0 {
+		config.History = 64
+	}
+	if config.Notify == nil {
+		config.Notify = func(string, string, map[string]any) error { return nil }
+	}
+	d := &Documents{config: config, docs: make(map[string]*document)}
+	d.sub = events.Subscribe(events.Or(config.Events), events.Connections, func(e events.ConnectionEvent) {
+		if e.Type == events.ConnectionClosed {
+			d.drop(e.ID)
+		}
+	})
+	return d
+}
+
+// Close stops following connections
+func (d *Documents) Close() {
+	d.sub.Unsubscribe()
+}
+
+// Subscribe adds connectionID to the subscribers of uri, reading the
+// document if it has none yet. The result catches the client up from
+// version, or holds the whole document when version is 0 or too old.
+func (d *Documents) Subscribe(ctx context.Context, connectionID, uri string, version int64) (SyncResult, error) {
+	d.mu.Lock()
+	doc, ok := d.docs[uri]
+	d.mu.Unlock()
+	if !ok {
+		text, mimeType, err := d.read(ctx, uri)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		d.mu.Lock()
+		// Another subscriber may have opened it meanwhile
+		if doc, ok = d.docs[uri]; !ok {
+			doc = &document{text: text, mimeType: mimeType, version: 1, subscribers: make(map[string]struct{})}
+			d.docs[uri] = doc
+		}
+		d.mu.Unlock()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	doc.subscribers[connectionID] = struct{}{}
+	if version == doc.version {
+		return SyncResult{Version: doc.version, BaseVersion: version}, nil
+	}
+	if edits, ok := doc.since(version); ok {
+		return SyncResult{Version: doc.version, BaseVersion: version, Edits: edits}, nil
+	}
+	return SyncResult{Version: doc.version, Contents: []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: doc.mimeType, Text: doc.text},
+	}}, nil
+}
+
+// Unsubscribe removes connectionID from the subscribers of uri. A document
+// without subscribers is forgotten.
+func (d *Documents) Unsubscribe(connectionID, uri string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if doc, ok := d.docs[uri]; ok {
+		delete(doc.subscribers, connectionID)
+		if len(doc.subscribers) == 0 {
+			delete(d.docs, uri)
+		}
+	}
+}
+
+// Open reports whether uri has subscribers
+func (d *Documents) Open(uri string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.docs[uri]
+	return ok
+}
+
+// Refresh reads an open document again and sends what changed to its
+// subscribers. It reports whether uri is open.
+func (d *Documents) Refresh(ctx context.Context, uri string) (bool, error) {
+	if !d.Open(uri) {
+		return false, nil
+	}
+	text, _, err := d.read(ctx, uri)
+	if err != nil {
+		return true, err
+	}
+	return d.Update(uri, text), nil
+}
+
+// Update sets the text of an open document, sending the edits to its
+// subscribers. It reports whether uri is open.
+func (d *Documents) Update(uri, text string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	doc, ok := d.docs[uri]
+	if !ok {
+		return false
+	}
+	if text == doc.text {
+		return true
+	}
+
+	update := DocumentUpdate{URI: uri, Version: doc.version + 1, BaseVersion: doc.version, Edits: []TextEdit{diff(doc.text, text)}}
+	doc.text = text
+	doc.version = update.Version
+	doc.history = append(doc.history, update)
+	if len(doc.history) > d.config.History {
+		doc.history = doc.history[len(doc.history)-d.config.History:]
+	}
+
+	// Sent while holding mu so subscribers get updates in version order
+	params := map[string]any{
+		"uri":         update.URI,
+		"version":     update.Version,
+		"baseVersion": update.BaseVersion,
+		"edits":       update.Edits,
+	}
+	for id := range doc.subscribers {
+		if err := d.config.Notify(id, protocolmcp.MethodNotificationResourceUpdated, params); err != nil {
+			// The client resyncs once it sees the next update
+			logging.Default().WithComponent("resources").WithFields(logging.LogFields{
+				logging.FieldConnectionID: id, "uri": uri,
+			}).Warn(context.Background(), "Failed to send document update: "+err.Error())
+		}
+	}
+	return true
+}
+
+// RegisterRoutes serves resources/subscribe and resources/unsubscribe on
+// the router
+func (d *Documents) RegisterRoutes(r *router.Router) {
+	r.RegisterFunc(protocolmcp.MethodSubscribe, func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params struct {
+			URI  string `json:"uri"`
+			Meta struct {
+				Version int64 `json:"version"`
+			} `json:"_meta"`
+		}
+		if err := req.BindParams(&params); err != nil || params.URI == "" {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("uri is required"), req.ID)
+		}
+		id, ok := connection.GetConnectionID(ctx)
+		if !ok {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInternalError("no connection"), req.ID)
+		}
+		result, err := d.Subscribe(ctx, id, params.URI, params.Meta.Version)
+		if err != nil {
+			mcpErr := mcperrors.FindMCPError(err)
+			if mcpErr == nil {
+				mcpErr = mcperrors.NewResourceError(params.URI, err)
+			}
+			return jsonrpc.NewErrorResponse(mcpErr.ToJSONRPCError(), req.ID)
+		}
+		return jsonrpc.NewResponse(result, req.ID)
+	})
+
+	r.RegisterFunc(protocolmcp.MethodUnsubscribe, func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if err := req.BindParams(&params); err != nil || params.URI == "" {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("uri is required"), req.ID)
+		}
+		if id, ok := connection.GetConnectionID(ctx); ok {
+			d.Unsubscribe(id, params.URI)
+		}
+		return jsonrpc.NewResponse(map[string]any{}, req.ID)
+	})
+}
+
+// read returns the text and MIME type of a text resource
+func (d *Documents) read(ctx context.Context, uri string) (string, string, error) {
+	contents, err := d.config.Read(ctx, uri)
+	if err != nil {
+		return "", "", err
+	}
+	if len(contents) != 1 {
+		return "", "", mcperrors.NewResourceError(uri, fmt.Errorf("documents have one item of contents, got %d", len(contents)))
+	}
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok {
+		return "", "", mcperrors.NewResourceError(uri, errors.New("only text resources can be synced"))
+	}
+	return text.Text, text.MIMEType, nil
+}
+
+// drop removes a closed connection from every document
+func (d *Documents) drop(connectionID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for uri, doc := range d.docs {
+		delete(doc.subscribers, connectionID)
+		if len(doc.subscribers) == 0 {
+			delete(d.docs, uri)
+		}
+	}
+}
+
+// since returns the edits from version to the current one, if the history
+// still has them
+func (doc *document) since(version int64) ([]TextEdit, bool) {
+	if version <= 0 || version > doc.version {
+		return nil, false
+	}
+	var edits []TextEdit
+	for _, update := range doc.history {
+		if update.BaseVersion < version {
+			continue
+		}
+		if len(edits) == 0 && update.BaseVersion != version {
+			return nil, false
+		}
+		edits = append(edits, update.Edits...)
+	}
+	return edits, len(edits) > 0
+}
+
+// diff returns the edit replacing the part of before that differs from
+// after: everything between their common prefix and suffix
+func diff(before, after string) TextEdit {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	for prefix > 0 && prefix < len(before) && !utf8.RuneStart(before[prefix]) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !utf8.RuneStart(before[len(before)-suffix]) {
+		suffix--
+	}
+
+	return TextEdit{
+		Range: Range{Start: position(before, prefix), End: position(before, len(before)-suffix)},
+		Text:  after[prefix : len(after)-suffix],
+	}
+}
+
+// position returns the position of byte offset in text
+func position(text string, offset int) Position {
+	var p Position
+	for _, r := range text[:offset] {
+		if r == '\n' {
+			p.Line++
+			p.Character = 0
+			continue
+		}
+		p.Character++
+	}
+	return p
+}
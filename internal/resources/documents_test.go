@@ -0,0 +1,189 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// sentUpdate is a notification recorded by a documents test
+type sentUpdate struct {
+	connectionID string
+	params       map[string]any
+}
+
+// newTestDocuments returns documents reading from texts and the updates
+// they send
+func newTestDocuments(t *testing.T, texts map[string]string, bus *events.Bus) (*Documents, func() []sentUpdate) {
+	t.Helper()
+	var mu sync.Mutex
+	var sent []sentUpdate
+	d := NewDocuments(DocumentsConfig{
+		Read: func(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "text/plain", Text: texts[uri]}}, nil
+		},
+		Notify: func(connectionID, method string, params map[string]any) error {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, mcp.MethodNotificationResourceUpdated, method)
+			sent = append(sent, sentUpdate{connectionID, params})
+			return nil
+		},
+		History: 2,
+		Events:  bus,
+	})
+	t.Cleanup(d.Close)
+	return d, func() []sentUpdate {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]sentUpdate(nil), sent...)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          TextEdit
+	}{
+		{"insert", "hello\nworld", "hello\nbig world", TextEdit{Range{Position{1, 0}, Position{1, 0}}, "big "}},
+		{"delete", "a\nb\nc\n", "a\nc\n", TextEdit{Range{Position{1, 0}, Position{2, 0}}, ""}},
+		{"replace", "one two three", "one 2 three", TextEdit{Range{Position{0, 4}, Position{0, 7}}, "2"}},
+		{"runes", "héllo", "hèllo", TextEdit{Range{Position{0, 1}, Position{0, 2}}, "è"}},
+		{"append", "abc", "abcabc", TextEdit{Range{Position{0, 3}, Position{0, 3}}, "abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diff(tt.before, tt.after))
+		})
+	}
+}
+
+func TestDocuments_Sync(t *testing.T) {
+	texts := map[string]string{"file://p/a.txt": "one\ntwo\n"}
+	d, sent := newTestDocuments(t, texts, events.New())
+	ctx := context.Background()
+
+	result, err := d.Subscribe(ctx, "c1", "file://p/a.txt", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Version)
+	assert.Equal(t, []mcp.ResourceContents{mcp.TextResourceContents{URI: "file://p/a.txt", MIMEType: "text/plain", Text: "one\ntwo\n"}}, result.Contents)
+
+	assert.True(t, d.Update("file://p/a.txt", "one\n2\n"))
+	assert.True(t, d.Update("file://p/a.txt", "one\n2\nthree\n"))
+	assert.False(t, d.Update("file://p/other.txt", "x"), "unsubscribed documents are not tracked")
+
+	updates := sent()
+	require.Len(t, updates, 2)
+	assert.Equal(t, "c1", updates[0].connectionID)
+	assert.Equal(t, int64(2), updates[0].params["version"])
+	assert.Equal(t, int64(1), updates[0].params["baseVersion"])
+	assert.Equal(t, []TextEdit{{Range{Position{1, 0}, Position{1, 3}}, "2"}}, updates[0].params["edits"])
+
+	// A client holding version 1 catches up with the edits since
+	result, err = d.Subscribe(ctx, "c2", "file://p/a.txt", 1)
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{Version: 3, BaseVersion: 1, Edits: []TextEdit{
+		{Range{Position{1, 0}, Position{1, 3}}, "2"},
+		{Range{Position{2, 0}, Position{2, 0}}, "three\n"},
+	}}, result)
+
+	// One that is current gets nothing, one too far behind gets a snapshot
+	result, err = d.Subscribe(ctx, "c2", "file://p/a.txt", 3)
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{Version: 3, BaseVersion: 3}, result)
+	d.Update("file://p/a.txt", "1\n2\nthree\n")
+	result, err = d.Subscribe(ctx, "c2", "file://p/a.txt", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.Version)
+	assert.Empty(t, result.Edits)
+	assert.Equal(t, "1\n2\nthree\n", result.Contents[0].(mcp.TextResourceContents).Text)
+
+	d.Unsubscribe("c1", "file://p/a.txt")
+	d.Unsubscribe("c2", "file://p/a.txt")
+	assert.False(t, d.Open("file://p/a.txt"))
+}
+
+func TestDocuments_RejectsBinary(t *testing.T) {
+	d := NewDocuments(DocumentsConfig{
+		Read: func(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.BlobResourceContents{URI: uri, Blob: "AAE="}}, nil
+		},
+		Events: events.New(),
+	})
+	defer d.Close()
+	_, err := d.Subscribe(context.Background(), "c1", "file://p/a.bin", 0)
+	assert.Error(t, err)
+	assert.False(t, d.Open("file://p/a.bin"))
+}
+
+func TestDocuments_DropsClosedConnections(t *testing.T) {
+	bus := events.New()
+	d, _ := newTestDocuments(t, map[string]string{"file://p/a.txt": "a"}, bus)
+	_, err := d.Subscribe(context.Background(), "c1", "file://p/a.txt", 0)
+	require.NoError(t, err)
+
+	events.Publish(bus, events.Connections, events.ConnectionEvent{Type: events.ConnectionClosed, ID: "c1"})
+	assert.Eventually(t, func() bool { return !d.Open("file://p/a.txt") }, time.Second, 10*time.Millisecond)
+}
+
+func TestDocuments_Routes(t *testing.T) {
+	d, _ := newTestDocuments(t, map[string]string{"file://p/a.txt": "a"}, events.New())
+	r := router.New()
+	d.RegisterRoutes(r)
+	ctx := connection.WithConnectionID(context.Background(), "c1")
+
+	params := map[string]any{"uri": "file://p/a.txt", "_meta": map[string]any{"version": 1}}
+	response := r.Handle(ctx, &jsonrpc.Request{Version: jsonrpc.Version, Method: "resources/subscribe", Params: params, ID: 1})
+	require.Nil(t, response.Error)
+	assert.Equal(t, SyncResult{Version: 1, BaseVersion: 1}, response.Result)
+	assert.True(t, d.Open("file://p/a.txt"))
+
+	response = r.Handle(ctx, &jsonrpc.Request{Version: jsonrpc.Version, Method: "resources/subscribe", Params: map[string]any{}, ID: 2})
+	require.NotNil(t, response.Error)
+
+	params = map[string]any{"uri": "file://p/a.txt"}
+	response = r.Handle(ctx, &jsonrpc.Request{Version: jsonrpc.Version, Method: "resources/unsubscribe", Params: params, ID: 3})
+	require.Nil(t, response.Error)
+	assert.False(t, d.Open("file://p/a.txt"))
+}
+
+func TestFileProvider_Incremental(t *testing.T) {
+	p, dir := newTestProvider(t, func(c *FileConfig) { c.Watch = true; c.Incremental = true })
+	s := newFakeServer()
+	require.NoError(t, p.Register(s))
+	require.NotNil(t, p.Documents())
+
+	_, err := p.Documents().Subscribe(context.Background(), "c1", "file://project/README.md", 0)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Hello world"), 0o644))
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, n := range s.notifications {
+			if n == "c1 "+mcp.MethodNotificationResourceUpdated+" file://project/README.md" {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.NotContains(t, s.notifications, mcp.MethodNotificationResourceUpdated+" file://project/README.md",
+		"subscribers of a document are not sent bare updates")
+}
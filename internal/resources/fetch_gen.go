@@ -0,0 +1,63 @@
+// Code generated by toolgen from fetch.json; DO NOT EDIT.
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fetchInputSchema is the input schema of the fetch tool
+const fetchInputSchema = `{"type":"object","properties":{"url":{"type":"string","description":"The URL to fetch","minLength":1}},"required":["url"]}`
+
+// FetchTool returns the fetch tool definition
+func FetchTool() mcp.Tool {
+	return mcp.NewToolWithRawSchema("fetch", "Fetch a URL with HTTP GET. Only allowlisted domains can be fetched.", json.RawMessage(fetchInputSchema))
+}
+
+// FetchParams is generated from the tool's JSON Schema
+type FetchParams struct {
+	// The URL to fetch
+	URL string `json:"url"`
+}
+
+// validate checks constraints the JSON decoder cannot; raw holds the
+// undecoded arguments so missing required properties are detected
+func (p *FetchParams) validate(raw map[string]interface{}, path string) error {
+	if _, ok := raw["url"]; !ok {
+		return fmt.Errorf("%s is required", path+"url")
+	}
+	if len([]rune(p.URL)) < 1 {
+		return fmt.Errorf("%s must be at least 1 characters", path+"url")
+	}
+	return nil
+}
+
+// ParseFetchParams decodes and validates tool arguments
+func ParseFetchParams(args map[string]interface{}) (FetchParams, error) {
+	var params FetchParams
+	data, err := json.Marshal(args)
+	if err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return params, params.validate(args, "")
+}
+
+// FetchHandler adapts a typed function to an mcp-go tool handler. Invalid
+// arguments are reported as a tool error result.
+func FetchHandler(fn func(ctx context.Context, params FetchParams) (*mcp.CallToolResult, error)) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params, err := ParseFetchParams(request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return fn(ctx, params)
+	}
+}
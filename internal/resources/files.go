@@ -0,0 +1,550 @@
+// Package resources provides built-in MCP resource providers.
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// Server is the part of the mcp-go server the providers publish to
+type Server interface {
+	AddResources(resources ...server.ServerResource)
+	RemoveResource(uri string)
+	AddResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc)
+	SendNotificationToAllClients(method string, params map[string]any)
+	SendNotificationToSpecificClient(sessionID string, method string, params map[string]any) error
+}
+
+// Root is a directory exposed as resources
+type Root struct {
+	// Name is the URI host of the root's resources: file://<name>/<path>
+	Name string `yaml:"name" json:"name"`
+
+	// Path is the directory on disk
+	Path string `yaml:"path" json:"path"`
+}
+
+// FileConfig contains configuration for a FileProvider
+type FileConfig struct {
+	// Roots are the directories to expose
+	Roots []Root `yaml:"roots" json:"roots"`
+
+	// MaxFileSize is the largest file that can be read (defaults to 1 MiB)
+	MaxFileSize int64 `yaml:"maxFileSize" json:"maxFileSize"`
+
+	// MaxFiles caps the number of files listed per root (defaults to 1000).
+	// Unlisted files remain readable through the root's template.
+	MaxFiles int `yaml:"maxFiles" json:"maxFiles"`
+
+	// IncludeHidden lists dot files and directories
+	IncludeHidden bool `yaml:"includeHidden" json:"includeHidden"`
+
+	// Watch publishes file changes: created and removed files update the
+	// resource list and writes are announced with resources/updated. mcp-go
+	// does not track subscriptions, so updates go to every client.
+	Watch bool `yaml:"watch" json:"watch"`
+
+	// Incremental syncs text files clients subscribe to as documents: a
+	// write reaches subscribers as the edits since their version instead of
+	// going to every client, see Documents. It needs Watch.
+	Incremental bool `yaml:"incremental" json:"incremental"`
+
+	// Cache keeps read files, revalidated by size and modification time
+	Cache *Cache `yaml:"-" json:"-"`
+}
+
+// DefaultFileConfig returns a configuration exposing dir as the root
+// "project"
+func DefaultFileConfig(dir string) FileConfig {
+	return FileConfig{
+		Roots:       []Root{{Name: "project", Path: dir}},
+		MaxFileSize: 1 << 20,
+		MaxFiles:    1000,
+	}
+}
+
+// ParseRoots parses a comma-separated list of name=path pairs
+func ParseRoots(spec string) ([]Root, error) {
+	var roots []Root
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, dir, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || dir == "" {
+			return nil, fmt.Errorf("invalid root %q: want name=path", pair)
+		}
+		roots = append(roots, Root{Name: name, Path: dir})
+	}
+	return roots, nil
+}
+
+// fileRoot is an opened root
+type fileRoot struct {
+	name string
+	path string
+	dir  *os.Root
+}
+
+// FileProvider exposes directory trees as MCP resources. All access goes
+// through os.Root, so paths (including symlinks) cannot escape the
+// configured roots.
+type FileProvider struct {
+	config FileConfig
+	roots  map[string]*fileRoot
+
+	documents *Documents
+
+	mu      sync.Mutex
+	server  Server
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewFileProvider opens the configured roots
+func NewFileProvider(config FileConfig) (*FileProvider, error) {
+	if len(config.Roots) == 0 {
+		return nil, errors.New("at least one root is required")
+	}
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = 1 << 20
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = 1000
+	}
+
+	p := &FileProvider{config: config, roots: make(map[string]*fileRoot)}
+	for _, root := range config.Roots {
+		if root.Name == "" || strings.ContainsAny(root.Name, "/{}") {
+			p.Close()
+			return nil, fmt.Errorf("invalid root name %q", root.Name)
+		}
+		if _, ok := p.roots[root.Name]; ok {
+			p.Close()
+			return nil, fmt.Errorf("duplicate root name %q", root.Name)
+		}
+		abs, err := filepath.Abs(root.Path)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("root %s: %w", root.Name, err)
+		}
+		dir, err := os.OpenRoot(abs)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("root %s: %w", root.Name, err)
+		}
+		p.roots[root.Name] = &fileRoot{name: root.Name, path: abs, dir: dir}
+	}
+	if config.Incremental {
+		p.documents = NewDocuments(DocumentsConfig{Read: p.Read, Notify: p.notify})
+	}
+	return p, nil
+}
+
+// URI returns the resource URI of a file relative to a root
+func URI(root, rel string) string {
+	return "file://" + root + "/" + filepath.ToSlash(rel)
+}
+
+// List returns the files under all roots, sorted by URI
+func (p *FileProvider) List() ([]mcp.Resource, error) {
+	var resources []mcp.Resource
+	for _, name := range p.rootNames() {
+		root := p.roots[name]
+		count := 0
+		err := fs.WalkDir(root.dir.FS(), ".", func(rel string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if rel != "." && !p.config.IncludeHidden && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if count >= p.config.MaxFiles {
+				return fs.SkipAll
+			}
+			count++
+			resources = append(resources, p.resource(root.name, rel))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list root %s: %w", name, err)
+		}
+	}
+	return resources, nil
+}
+
+// Read returns the contents of the file identified by uri. Text files are
+// returned as text and anything else as base64 blobs.
+func (p *FileProvider) Read(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	version, err := p.ReadConditional(ctx, uri, Validators{})
+	if err != nil {
+		return nil, err
+	}
+	return version.Contents, nil
+}
+
+// ReadConditional reads the file identified by uri unless its size and
+// modification time still match cached
+func (p *FileProvider) ReadConditional(ctx context.Context, uri string, cached Validators) (Version, error) {
+	root, rel, err := p.resolve(uri)
+	if err != nil {
+		return Version{}, err
+	}
+
+	f, err := root.dir.Open(rel)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Version{}, mcperrors.NewResourceNotFoundError(uri)
+		}
+		return Version{}, mcperrors.NewResourceError(uri, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return Version{}, mcperrors.NewResourceError(uri, err)
+	}
+	if !info.Mode().IsRegular() {
+		return Version{}, mcperrors.NewResourceNotFoundError(uri)
+	}
+	if info.Size() > p.config.MaxFileSize {
+		return Version{}, mcperrors.NewResourceLimitError("file_size", info.Size(), p.config.MaxFileSize)
+	}
+
+	validators := Validators{
+		ETag:         fmt.Sprintf(`"%x-%x"`, info.Size(), info.ModTime().UnixNano()),
+		LastModified: info.ModTime(),
+	}
+	if cached.ETag == validators.ETag {
+		return Version{Validators: validators, NotModified: true}, nil
+	}
+
+	content, err := io.ReadAll(io.LimitReader(f, p.config.MaxFileSize+1))
+	if err != nil {
+		return Version{}, mcperrors.NewResourceError(uri, err)
+	}
+	if int64(len(content)) > p.config.MaxFileSize {
+		return Version{}, mcperrors.NewResourceLimitError("file_size", int64(len(content)), p.config.MaxFileSize)
+	}
+
+	version := Version{Validators: validators}
+	mimeType := DetectMIMEType(rel, content)
+	if isText(mimeType, content) {
+		version.Contents = []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(content)}}
+	} else {
+		version.Contents = []mcp.ResourceContents{mcp.BlobResourceContents{
+			URI:      uri,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(content),
+		}}
+	}
+	return version, nil
+}
+
+// Register publishes the listed files and a template per root, then starts
+// watching for changes if configured
+func (p *FileProvider) Register(s Server) error {
+	resources, err := p.List()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.server = s
+	p.mu.Unlock()
+
+	serverResources := make([]server.ServerResource, len(resources))
+	for i, resource := range resources {
+		serverResources[i] = server.ServerResource{Resource: resource, Handler: p.handleRead}
+	}
+	if len(serverResources) > 0 {
+		s.AddResources(serverResources...)
+	}
+
+	for _, name := range p.rootNames() {
+		template := mcp.NewResourceTemplate("file://"+name+"/{+path}", "Files in "+name,
+			mcp.WithTemplateDescription("Files under the "+name+" root"))
+		s.AddResourceTemplate(template, p.handleRead)
+	}
+
+	if p.config.Watch {
+		return p.watch()
+	}
+	return nil
+}
+
+// Close stops watching and closes the roots
+func (p *FileProvider) Close() error {
+	p.mu.Lock()
+	watcher := p.watcher
+	p.watcher = nil
+	p.mu.Unlock()
+
+	var err error
+	if watcher != nil {
+		err = watcher.Close()
+		<-p.done
+	}
+	if p.documents != nil {
+		p.documents.Close()
+	}
+	for _, root := range p.roots {
+		root.dir.Close()
+	}
+	return err
+}
+
+// Documents returns the subscribed documents of an incremental provider,
+// or nil
+func (p *FileProvider) Documents() *Documents {
+	return p.documents
+}
+
+// notify sends a notification to one client of the registered server
+func (p *FileProvider) notify(connectionID, method string, params map[string]any) error {
+	p.mu.Lock()
+	s := p.server
+	p.mu.Unlock()
+	if s == nil {
+		return errors.New("provider is not registered")
+	}
+	return s.SendNotificationToSpecificClient(connectionID, method, params)
+}
+
+// handleRead serves resources/read for published files and templates
+func (p *FileProvider) handleRead(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if p.config.Cache != nil {
+		return p.config.Cache.Read(ctx, request.Params.URI, p.ReadConditional)
+	}
+	return p.Read(ctx, request.Params.URI)
+}
+
+// resolve maps a URI onto a root and a slash-separated relative path
+func (p *FileProvider) resolve(uri string) (*fileRoot, string, error) {
+	rest, ok := strings.CutPrefix(uri, "file://")
+	if !ok {
+		return nil, "", mcperrors.NewResourceNotFoundError(uri)
+	}
+	name, rel, _ := strings.Cut(rest, "/")
+	root, ok := p.roots[name]
+	if !ok {
+		return nil, "", mcperrors.NewResourceNotFoundError(uri)
+	}
+
+	rel = path.Clean("/" + rel)[1:]
+	if rel == "" {
+		return nil, "", mcperrors.NewResourceNotFoundError(uri)
+	}
+	if !p.config.IncludeHidden && hiddenPath(rel) {
+		return nil, "", mcperrors.NewResourceNotFoundError(uri)
+	}
+	return root, rel, nil
+}
+
+// resource describes a file
+func (p *FileProvider) resource(root, rel string) mcp.Resource {
+	return mcp.NewResource(URI(root, rel), path.Base(rel), mcp.WithMIMEType(DetectMIMEType(rel, nil)))
+}
+
+// rootNames returns the root names in order
+func (p *FileProvider) rootNames() []string {
+	names := make([]string, 0, len(p.roots))
+	for name := range p.roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// watch starts publishing file changes
+func (p *FileProvider) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("start watcher: %w", err)
+	}
+	for _, root := range p.roots {
+		if err := p.watchTree(watcher, root.path); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	p.watcher = watcher
+	p.done = make(chan struct{})
+	p.mu.Unlock()
+
+	go p.processEvents(watcher)
+	return nil
+}
+
+// watchTree adds dir and its subdirectories to the watcher
+func (p *FileProvider) watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if name != dir && !p.config.IncludeHidden && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if err := watcher.Add(name); err != nil {
+			return fmt.Errorf("watch %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// processEvents turns file events into resource notifications
+func (p *FileProvider) processEvents(watcher *fsnotify.Watcher) {
+	defer close(p.done)
+	ctx := logging.WithComponent(context.Background(), "resources")
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			p.handleEvent(watcher, event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logging.Default().Error(ctx, err, "File watcher error")
+		}
+	}
+}
+
+// handleEvent publishes a single file event
+func (p *FileProvider) handleEvent(watcher *fsnotify.Watcher, event fsnotify.Event) {
+	root, rel, ok := p.relative(event.Name)
+	if !ok || (!p.config.IncludeHidden && hiddenPath(rel)) {
+		return
+	}
+	uri := URI(root.name, rel)
+	if p.config.Cache != nil && !event.Has(fsnotify.Create) {
+		p.config.Cache.Invalidate(uri)
+	}
+
+	p.mu.Lock()
+	s := p.server
+	p.mu.Unlock()
+	if s == nil {
+		return
+	}
+
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := root.dir.Lstat(rel)
+		if err != nil {
+			return
+		}
+		if info.IsDir() {
+			p.watchTree(watcher, event.Name)
+			return
+		}
+		if info.Mode().IsRegular() {
+			s.AddResources(server.ServerResource{Resource: p.resource(root.name, rel), Handler: p.handleRead})
+		}
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		s.RemoveResource(uri)
+	case event.Has(fsnotify.Write):
+		if p.documents != nil {
+			// Subscribers of an open document get the edits instead
+			open, err := p.documents.Refresh(context.Background(), uri)
+			if err != nil {
+				logging.Default().WithComponent("resources").WithField("uri", uri).
+					Warn(context.Background(), "Failed to refresh document: "+err.Error())
+			}
+			if open {
+				return
+			}
+		}
+		n := protocolmcp.NewResourceUpdatedNotification(uri)
+		s.SendNotificationToAllClients(n.Method, n.Params)
+	}
+}
+
+// relative maps an absolute path onto its root
+func (p *FileProvider) relative(name string) (*fileRoot, string, bool) {
+	for _, root := range p.roots {
+		rel, err := filepath.Rel(root.path, name)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return root, filepath.ToSlash(rel), true
+	}
+	return nil, "", false
+}
+
+// hiddenPath reports whether any element of a slash-separated path is a dot
+// file
+func hiddenPath(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectMIMEType guesses the MIME type of a file from its extension, then
+// from its content when available
+func DetectMIMEType(name string, content []byte) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		return "text/markdown"
+	case ".go", ".txt", ".yaml", ".yml", ".toml", ".sh":
+		return "text/plain; charset=utf-8"
+	}
+	if content == nil {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(content)
+}
+
+// isText reports whether content should be returned as text
+func isText(mimeType string, content []byte) bool {
+	base, _, _ := strings.Cut(mimeType, ";")
+	switch {
+	case strings.HasPrefix(base, "text/"),
+		base == "application/json",
+		base == "application/xml",
+		base == "application/javascript",
+		base == "application/x-yaml":
+		return utf8.Valid(content)
+	case base == "application/octet-stream":
+		return utf8.Valid(content) && !strings.ContainsRune(string(content), 0)
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer records what the provider publishes
+type fakeServer struct {
+	mu            sync.Mutex
+	resources     map[string]server.ServerResource
+	templates     []mcp.ResourceTemplate
+	notifications []string
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{resources: make(map[string]server.ServerResource)}
+}
+
+func (s *fakeServer) AddResources(resources ...server.ServerResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range resources {
+		s.resources[r.Resource.URI] = r
+	}
+}
+
+func (s *fakeServer) RemoveResource(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.resources, uri)
+}
+
+func (s *fakeServer) AddResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = append(s.templates, template)
+}
+
+func (s *fakeServer) SendNotificationToAllClients(method string, params map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, method+" "+params["uri"].(string))
+}
+
+func (s *fakeServer) SendNotificationToSpecificClient(sessionID string, method string, params map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifications = append(s.notifications, sessionID+" "+method+" "+params["uri"].(string))
+	return nil
+}
+
+func (s *fakeServer) has(uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.resources[uri]
+	return ok
+}
+
+func writeFile(t *testing.T, dir, name string, content []byte) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+}
+
+func newTestProvider(t *testing.T, configure func(*FileConfig)) (*FileProvider, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", []byte("# Hello"))
+	writeFile(t, dir, "docs/guide.txt", []byte("guide"))
+	writeFile(t, dir, "img/logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00"))
+	writeFile(t, dir, ".env", []byte("SECRET=1"))
+
+	config := DefaultFileConfig(dir)
+	if configure != nil {
+		configure(&config)
+	}
+	p, err := NewFileProvider(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Close() })
+	return p, dir
+}
+
+func TestFileProvider_List(t *testing.T) {
+	p, _ := newTestProvider(t, nil)
+
+	resources, err := p.List()
+	require.NoError(t, err)
+
+	var uris []string
+	for _, r := range resources {
+		uris = append(uris, r.URI)
+	}
+	assert.Equal(t, []string{
+		"file://project/README.md",
+		"file://project/docs/guide.txt",
+		"file://project/img/logo.png",
+	}, uris)
+
+	p, _ = newTestProvider(t, func(c *FileConfig) { c.MaxFiles = 1 })
+	resources, err = p.List()
+	require.NoError(t, err)
+	assert.Len(t, resources, 1)
+}
+
+func TestFileProvider_Read(t *testing.T) {
+	p, _ := newTestProvider(t, nil)
+	ctx := context.Background()
+
+	contents, err := p.Read(ctx, "file://project/README.md")
+	require.NoError(t, err)
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "# Hello", text.Text)
+	assert.Contains(t, text.MIMEType, "markdown")
+
+	contents, err = p.Read(ctx, "file://project/img/logo.png")
+	require.NoError(t, err)
+	blob := contents[0].(mcp.BlobResourceContents)
+	assert.Equal(t, "image/png", blob.MIMEType)
+	raw, err := base64.StdEncoding.DecodeString(blob.Blob)
+	require.NoError(t, err)
+	assert.Equal(t, "\x89PNG\r\n\x1a\n\x00\x00", string(raw))
+}
+
+func TestFileProvider_Confinement(t *testing.T) {
+	p, dir := newTestProvider(t, nil)
+	ctx := context.Background()
+
+	outside := t.TempDir()
+	writeFile(t, outside, "secret.txt", []byte("secret"))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "link.txt")))
+
+	for _, uri := range []string{
+		"file://project/../../etc/passwd",
+		"file://project/link.txt",
+		"file://project/.env",
+		"file://other/README.md",
+		"https://project/README.md",
+		"file://project/docs",
+	} {
+		_, err := p.Read(ctx, uri)
+		assert.Error(t, err, uri)
+	}
+
+	// Dot-dot segments are cleaned, never resolved above the root
+	contents, err := p.Read(ctx, "file://project/docs/../README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "file://project/docs/../README.md", contents[0].(mcp.TextResourceContents).URI)
+}
+
+func TestFileProvider_MaxFileSize(t *testing.T) {
+	p, _ := newTestProvider(t, func(c *FileConfig) { c.MaxFileSize = 4 })
+
+	_, err := p.Read(context.Background(), "file://project/README.md")
+	require.Error(t, err)
+	assert.Equal(t, mcperrors.ErrorCodeMCPResourceLimit, mcperrors.FindMCPError(err).Code)
+
+	_, err = p.Read(context.Background(), "file://project/missing.md")
+	assert.Equal(t, mcperrors.ErrorCodeMCPResourceNotFound, mcperrors.FindMCPError(err).Code)
+}
+
+func TestFileProvider_RegisterAndWatch(t *testing.T) {
+	p, dir := newTestProvider(t, func(c *FileConfig) { c.Watch = true })
+	s := newFakeServer()
+	require.NoError(t, p.Register(s))
+
+	assert.True(t, s.has("file://project/README.md"))
+	require.Len(t, s.templates, 1)
+	assert.Equal(t, "file://project/{+path}", s.templates[0].URITemplate.Raw())
+
+	writeFile(t, dir, "docs/new.txt", []byte("new"))
+	assert.Eventually(t, func() bool { return s.has("file://project/docs/new.txt") }, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed"), 0o644))
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, n := range s.notifications {
+			if n == mcp.MethodNotificationResourceUpdated+" file://project/README.md" {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "docs/new.txt")))
+	assert.Eventually(t, func() bool { return !s.has("file://project/docs/new.txt") }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestNewFileProvider_Errors(t *testing.T) {
+	_, err := NewFileProvider(FileConfig{})
+	assert.Error(t, err)
+
+	_, err = NewFileProvider(FileConfig{Roots: []Root{{Name: "a/b", Path: t.TempDir()}}})
+	assert.Error(t, err)
+
+	_, err = NewFileProvider(FileConfig{Roots: []Root{{Name: "missing", Path: filepath.Join(t.TempDir(), "nope")}}})
+	assert.Error(t, err)
+}
+
+func TestParseRoots(t *testing.T) {
+	roots, err := ParseRoots("docs=/srv/docs, data=./data")
+	require.NoError(t, err)
+	assert.Equal(t, []Root{{Name: "docs", Path: "/srv/docs"}, {Name: "data", Path: "./data"}}, roots)
+
+	_, err = ParseRoots("docs")
+	assert.Error(t, err)
+}
+
+func TestFileProvider_MCPServer(t *testing.T) {
+	p, dir := newTestProvider(t, nil)
+	s := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(true, true))
+	require.NoError(t, p.Register(s))
+
+	// Files created after registration are read through the template
+	writeFile(t, dir, "docs/later.txt", []byte("later"))
+	msg := s.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"file://project/docs/later.txt"}}`))
+
+	resp, ok := msg.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected message %#v", msg)
+	result := resp.Result.(mcp.ReadResourceResult)
+	assert.Equal(t, "later", result.Contents[0].(mcp.TextResourceContents).Text)
+}
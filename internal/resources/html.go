@@ -0,0 +1,196 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToMarkdown converts HTML pages to markdown, keeping headings,
+// paragraphs, lists, links, emphasis and code and dropping scripts, styles
+// and other markup
+var HTMLToMarkdown = Converter{From: "text/html", To: "text/markdown", Convert: htmlToMarkdown}
+
+// blankLines matches runs of blank lines left by nested blocks
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// htmlToMarkdown renders the HTML document content as markdown
+func htmlToMarkdown(ctx context.Context, content []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	w := &markdownWriter{}
+	w.node(doc)
+	out := blankLines.ReplaceAllString(w.String(), "\n\n")
+	return []byte(strings.TrimSpace(out) + "\n"), nil
+}
+
+// markdownWriter renders an HTML tree as markdown
+type markdownWriter struct {
+	strings.Builder
+	lists []listState
+	pre   bool
+}
+
+// listState numbers the items of an open list; 0 marks a bulleted list
+type listState struct {
+	next int
+}
+
+// children renders the children of n
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+// block renders n as a block separated by blank lines, prefixed with
+// prefix
+func (w *markdownWriter) block(n *html.Node, prefix string) {
+	w.WriteString("\n\n" + prefix)
+	w.children(n)
+	w.WriteString("\n\n")
+}
+
+// inline renders the children of n wrapped in mark
+func (w *markdownWriter) inline(n *html.Node, mark string) {
+	w.WriteString(mark)
+	w.children(n)
+	w.WriteString(mark)
+}
+
+// node renders n and its children
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if w.pre {
+			w.WriteString(n.Data)
+			return
+		}
+		// Runs of whitespace collapse to a space, as browsers render them
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			if n.Data != "" {
+				w.space()
+			}
+			return
+		}
+		if isSpace(n.Data[0]) {
+			w.space()
+		}
+		w.WriteString(text)
+		if isSpace(n.Data[len(n.Data)-1]) {
+			w.space()
+		}
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Head, atom.Iframe, atom.Svg:
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		w.block(n, strings.Repeat("#", level)+" ")
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Table:
+		w.block(n, "")
+	case atom.Tr:
+		w.children(n)
+		w.WriteString("\n")
+	case atom.Td, atom.Th:
+		w.children(n)
+		w.WriteString(" ")
+	case atom.Blockquote:
+		w.block(n, "> ")
+	case atom.Br:
+		w.WriteString("  \n")
+	case atom.Hr:
+		w.WriteString("\n\n---\n\n")
+	case atom.Ul, atom.Ol:
+		state := listState{}
+		if n.DataAtom == atom.Ol {
+			state.next = 1
+		}
+		if len(w.lists) == 0 {
+			w.WriteString("\n")
+		}
+		w.lists = append(w.lists, state)
+		w.children(n)
+		w.lists = w.lists[:len(w.lists)-1]
+		w.WriteString("\n")
+	case atom.Li:
+		w.WriteString("\n" + strings.Repeat("  ", max(len(w.lists)-1, 0)))
+		if len(w.lists) > 0 && w.lists[len(w.lists)-1].next > 0 {
+			state := &w.lists[len(w.lists)-1]
+			w.WriteString(strconv.Itoa(state.next) + ". ")
+			state.next++
+		} else {
+			w.WriteString("- ")
+		}
+		w.children(n)
+	case atom.Strong, atom.B:
+		w.inline(n, "**")
+	case atom.Em, atom.I:
+		w.inline(n, "_")
+	case atom.Code:
+		if w.pre {
+			w.children(n)
+			return
+		}
+		w.inline(n, "`")
+	case atom.Pre:
+		w.WriteString("\n\n```\n")
+		w.pre = true
+		w.children(n)
+		w.pre = false
+		w.WriteString("\n```\n\n")
+	case atom.A:
+		href := attr(n, "href")
+		if href == "" || strings.HasPrefix(href, "javascript:") {
+			w.children(n)
+			return
+		}
+		w.WriteString("[")
+		w.children(n)
+		w.WriteString("](" + href + ")")
+	case atom.Img:
+		if src := attr(n, "src"); src != "" {
+			w.WriteString("![" + attr(n, "alt") + "](" + src + ")")
+		}
+	default:
+		w.children(n)
+	}
+}
+
+// space writes a single space unless the output already ends in
+// whitespace
+func (w *markdownWriter) space() {
+	s := w.String()
+	if s == "" || strings.HasSuffix(s, " ") || strings.HasSuffix(s, "\n") {
+		return
+	}
+	w.WriteString(" ")
+}
+
+// isSpace reports whether c is HTML whitespace
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r' || c == '\f'
+}
+
+// attr returns the value of the attribute key of n
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	page := `<!doctype html>
+<html><head><title>Ignored</title><style>p { color: red }</style></head>
+<body>
+  <h1>Release   notes</h1>
+  <p>Version <strong>2.0</strong> adds <em>streaming</em>, see
+     <a href="https://example.com/docs">the docs</a>.<br>Thanks!</p>
+  <script>alert("x")</script>
+  <ul><li>Faster</li><li>Smaller<ol><li>binary</li><li>images</li></ol></li></ul>
+  <pre><code>go install ./...
+make test</code></pre>
+  <p>Run <code>make</code> and <a href="javascript:void(0)">click</a>.</p>
+</body></html>`
+
+	out, err := HTMLToMarkdown.Convert(context.Background(), []byte(page))
+	require.NoError(t, err)
+	assert.Equal(t, "# Release notes\n\n"+
+		"Version **2.0** adds _streaming_, see [the docs](https://example.com/docs).  \nThanks!\n\n"+
+		"- Faster\n- Smaller\n  1. binary\n  2. images\n\n"+
+		"```\ngo install ./...\nmake test\n```\n\n"+
+		"Run `make` and click.\n", string(out))
+}
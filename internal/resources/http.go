@@ -0,0 +1,292 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/policy"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+//go:generate go run ../../cmd/toolgen -in fetch.json -out fetch_gen.go
+
+// HTTPConfig contains configuration for an HTTPProvider
+type HTTPConfig struct {
+	// Allow restricts the URLs that can be fetched (schemes default to
+	// https); redirects are checked too
+	Allow policy.URLRule `yaml:"allow" json:"allow"`
+
+	// MaxBodySize is the largest response body accepted (defaults to 1 MiB)
+	MaxBodySize int64 `yaml:"maxBodySize" json:"maxBodySize"`
+
+	// Timeout bounds each fetch including redirects (defaults to 10s)
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+
+	// MaxRedirects bounds the redirects followed (defaults to 5)
+	MaxRedirects int `yaml:"maxRedirects" json:"maxRedirects"`
+
+	// ContentTypes lists accepted media types; "text/*" matches any text
+	// type (empty accepts all)
+	ContentTypes []string `yaml:"contentTypes" json:"contentTypes"`
+
+	// UserAgent is sent with each request
+	UserAgent string `yaml:"userAgent" json:"userAgent"`
+
+	// AllowPrivateNetworks permits connections to loopback, private and
+	// link-local addresses, which are refused by default so allowlisted
+	// names resolving to internal hosts cannot be used to reach them
+	AllowPrivateNetworks bool `yaml:"allowPrivateNetworks" json:"allowPrivateNetworks"`
+
+	// Cache keeps read resources, revalidated with If-None-Match and
+	// If-Modified-Since
+	Cache *Cache `yaml:"-" json:"-"`
+}
+
+// FetchResult is a fetched HTTP response
+type FetchResult struct {
+	URL         string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+
+	// Validators are the response's ETag and Last-Modified headers
+	Validators Validators
+}
+
+// HTTPProvider exposes controlled HTTP GET as https:// resources and a
+// fetch tool
+type HTTPProvider struct {
+	config HTTPConfig
+	client *http.Client
+}
+
+// NewHTTPProvider creates a provider; at least one allowed domain is
+// required
+func NewHTTPProvider(config HTTPConfig) (*HTTPProvider, error) {
+	if len(config.Allow.Domains) == 0 {
+		return nil, errors.New("at least one allowed domain is required")
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = 5
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = "meta-mcp-server"
+	}
+
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = refusePrivate
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.Timeout,
+		ResponseHeaderTimeout: config.Timeout,
+	}
+
+	p := &HTTPProvider{config: config}
+	p.client = &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+			}
+			return p.config.Allow.Check(req.URL.String())
+		},
+	}
+	return p, nil
+}
+
+// Fetch performs a GET request for rawURL
+func (p *HTTPProvider) Fetch(ctx context.Context, rawURL string) (*FetchResult, error) {
+	return p.fetch(ctx, rawURL, Validators{})
+}
+
+// fetch performs a GET request for rawURL, conditional on cached. A
+// response with status 304 has no body.
+func (p *HTTPProvider) fetch(ctx context.Context, rawURL string, cached Validators) (*FetchResult, error) {
+	if err := p.config.Allow.Check(rawURL); err != nil {
+		return nil, mcperrors.NewMCPError(mcperrors.ErrorCodeMCPForbidden, err.Error(), nil).
+			WithContext("operation", "fetch")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, mcperrors.NewResourceError(rawURL, err)
+	}
+	req.Header.Set("User-Agent", p.config.UserAgent)
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if !cached.LastModified.IsZero() {
+		req.Header.Set("If-Modified-Since", cached.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, mcperrors.NewResourceError(rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	validators := Validators{ETag: resp.Header.Get("ETag")}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		validators.LastModified = lastModified
+	}
+	if resp.StatusCode == http.StatusNotModified && !cached.IsZero() {
+		if validators.IsZero() {
+			validators = cached
+		}
+		return &FetchResult{URL: resp.Request.URL.String(), StatusCode: resp.StatusCode, Validators: validators}, nil
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, mcperrors.NewResourceError(rawURL, fmt.Errorf("HTTP status %d", resp.StatusCode)).
+			WithContext("status_code", resp.StatusCode)
+	}
+	if resp.ContentLength > p.config.MaxBodySize {
+		return nil, mcperrors.NewResourceLimitError("response_size", resp.ContentLength, p.config.MaxBodySize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !p.acceptsContentType(contentType) {
+		return nil, mcperrors.NewResourceError(rawURL, fmt.Errorf("content type %q is not allowed", contentType))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, p.config.MaxBodySize+1))
+	if err != nil {
+		return nil, mcperrors.NewResourceError(rawURL, err)
+	}
+	if int64(len(body)) > p.config.MaxBodySize {
+		return nil, mcperrors.NewResourceLimitError("response_size", int64(len(body)), p.config.MaxBodySize)
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+
+	return &FetchResult{
+		URL:         resp.Request.URL.String(),
+		StatusCode:  resp.StatusCode,
+		ContentType: contentType,
+		Body:        body,
+		Validators:  validators,
+	}, nil
+}
+
+// Read fetches uri as resource contents
+func (p *HTTPProvider) Read(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	result, err := p.Fetch(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{result.contents(uri)}, nil
+}
+
+// ReadConditional fetches uri unless the server reports that it still
+// matches cached
+func (p *HTTPProvider) ReadConditional(ctx context.Context, uri string, cached Validators) (Version, error) {
+	result, err := p.fetch(ctx, uri, cached)
+	if err != nil {
+		return Version{}, err
+	}
+	if result.StatusCode == http.StatusNotModified {
+		return Version{Validators: result.Validators, NotModified: true}, nil
+	}
+	return Version{Contents: []mcp.ResourceContents{result.contents(uri)}, Validators: result.Validators}, nil
+}
+
+// Register publishes an https:// resource template reading through the
+// provider
+func (p *HTTPProvider) Register(s Server) {
+	template := mcp.NewResourceTemplate("https://{host}/{+path}", "Web pages",
+		mcp.WithTemplateDescription("HTTPS resources on allowlisted domains"))
+	s.AddResourceTemplate(template, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if p.config.Cache != nil {
+			return p.config.Cache.Read(ctx, request.Params.URI, p.ReadConditional)
+		}
+		return p.Read(ctx, request.Params.URI)
+	})
+}
+
+// ToolDefinition returns the fetch tool for the local tool registry
+func (p *HTTPProvider) ToolDefinition() tools.Definition {
+	return tools.Definition{
+		Tool:    FetchTool(),
+		Handler: FetchHandler(p.fetchTool),
+		Tags:    []string{"builtin", "network"},
+	}
+}
+
+// fetchTool implements the fetch tool. Fetch failures are reported as tool
+// errors so the model can react to them.
+func (p *HTTPProvider) fetchTool(ctx context.Context, params FetchParams) (*mcp.CallToolResult, error) {
+	result, err := p.Fetch(ctx, params.URL)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	contents := result.contents(result.URL)
+	if text, ok := contents.(mcp.TextResourceContents); ok {
+		return mcp.NewToolResultText(text.Text), nil
+	}
+	summary := fmt.Sprintf("Fetched %d bytes of %s", len(result.Body), result.ContentType)
+	return mcp.NewToolResultResource(summary, contents), nil
+}
+
+// contents converts the response into text or base64 blob contents
+func (r *FetchResult) contents(uri string) mcp.ResourceContents {
+	if isText(r.ContentType, r.Body) {
+		return mcp.TextResourceContents{URI: uri, MIMEType: r.ContentType, Text: string(r.Body)}
+	}
+	return mcp.BlobResourceContents{
+		URI:      uri,
+		MIMEType: r.ContentType,
+		Blob:     base64.StdEncoding.EncodeToString(r.Body),
+	}
+}
+
+// acceptsContentType reports whether the response media type is allowed
+func (p *HTTPProvider) acceptsContentType(contentType string) bool {
+	if len(p.config.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range p.config.ContentTypes {
+		if matchMediaType(allowed, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// refusePrivate is a dialer control rejecting non-public addresses
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("connection to %s is not allowed", host)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/policy"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHTTPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<p>hello</p>"))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("x", 2048)))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/escape", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://evil.example/", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestHTTPProvider(t *testing.T, configure func(*HTTPConfig)) *HTTPProvider {
+	t.Helper()
+	config := HTTPConfig{
+		Allow:                policy.URLRule{Domains: []string{"127.0.0.1"}, Schemes: []string{"http"}},
+		MaxBodySize:          1024,
+		AllowPrivateNetworks: true,
+	}
+	if configure != nil {
+		configure(&config)
+	}
+	p, err := NewHTTPProvider(config)
+	require.NoError(t, err)
+	return p
+}
+
+func TestHTTPProvider_Read(t *testing.T) {
+	srv := newTestHTTPServer(t)
+	p := newTestHTTPProvider(t, nil)
+	ctx := context.Background()
+
+	contents, err := p.Read(ctx, srv.URL+"/page")
+	require.NoError(t, err)
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "<p>hello</p>", text.Text)
+	assert.Equal(t, "text/html; charset=utf-8", text.MIMEType)
+
+	contents, err = p.Read(ctx, srv.URL+"/image")
+	require.NoError(t, err)
+	blob := contents[0].(mcp.BlobResourceContents)
+	raw, _ := base64.StdEncoding.DecodeString(blob.Blob)
+	assert.Equal(t, "\x89PNG\r\n\x1a\n", string(raw))
+}
+
+func TestHTTPProvider_Limits(t *testing.T) {
+	srv := newTestHTTPServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		configure func(*HTTPConfig)
+		url       string
+		code      int
+	}{
+		{"size cap", nil, srv.URL + "/large", mcperrors.ErrorCodeMCPResourceLimit},
+		{"http error", nil, srv.URL + "/missing", mcperrors.ErrorCodeMCPResourceError},
+		{"domain not allowed", nil, "http://example.com/", mcperrors.ErrorCodeMCPForbidden},
+		{"scheme not allowed", nil, "ftp://127.0.0.1/", mcperrors.ErrorCodeMCPForbidden},
+		{"redirect off allowlist", nil, srv.URL + "/escape", mcperrors.ErrorCodeMCPResourceError},
+		{"content type", func(c *HTTPConfig) { c.ContentTypes = []string{"text/*"} }, srv.URL + "/image", mcperrors.ErrorCodeMCPResourceError},
+		{"private network", func(c *HTTPConfig) { c.AllowPrivateNetworks = false }, srv.URL + "/page", mcperrors.ErrorCodeMCPResourceError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestHTTPProvider(t, tt.configure)
+			_, err := p.Read(ctx, tt.url)
+			require.Error(t, err)
+			assert.Equal(t, tt.code, mcperrors.FindMCPError(err).Code)
+		})
+	}
+
+	_, err := NewHTTPProvider(HTTPConfig{})
+	assert.Error(t, err)
+}
+
+func TestHTTPProvider_FetchTool(t *testing.T) {
+	srv := newTestHTTPServer(t)
+	p := newTestHTTPProvider(t, nil)
+	def := p.ToolDefinition()
+	assert.Equal(t, "fetch", def.Tool.Name)
+
+	call := func(url string) *mcp.CallToolResult {
+		var request mcp.CallToolRequest
+		request.Params.Name = "fetch"
+		request.Params.Arguments = map[string]interface{}{"url": url}
+		result, err := def.Handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(srv.URL + "/page")
+	assert.False(t, result.IsError)
+	assert.Equal(t, "<p>hello</p>", result.Content[0].(mcp.TextContent).Text)
+
+	result = call(srv.URL + "/image")
+	assert.False(t, result.IsError)
+	assert.IsType(t, mcp.EmbeddedResource{}, result.Content[1])
+
+	result = call("http://example.com/")
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not in the allowlist")
+}
@@ -0,0 +1,326 @@
+package resources
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+)
+
+// _meta keys of resources/read naming the representation the client
+// prefers
+const (
+	// MetaAccept lists MIME types in order of preference, as an array or
+	// a comma separated string; "text/*" and "*/*" match any subtype
+	MetaAccept = "accept"
+
+	// MetaEncoding asks for contents as EncodingText or EncodingBlob
+	MetaEncoding = "encoding"
+)
+
+// Encodings of resource contents
+const (
+	EncodingText = "text"
+	EncodingBlob = "blob"
+)
+
+// Preferences are the representation a client asks resources/read for
+type Preferences struct {
+	Accept   []string
+	Encoding string
+}
+
+// IsZero reports whether the client expressed no preference
+func (p Preferences) IsZero() bool {
+	return len(p.Accept) == 0 && p.Encoding == ""
+}
+
+// ParsePreferences returns the preferences in the _meta of resources/read
+// params. Malformed preferences are ignored.
+func ParsePreferences(params json.RawMessage) Preferences {
+	var read struct {
+		Meta struct {
+			Accept   json.RawMessage `json:"accept"`
+			Encoding string          `json:"encoding"`
+		} `json:"_meta"`
+	}
+	if len(params) == 0 || json.Unmarshal(params, &read) != nil {
+		return Preferences{}
+	}
+
+	prefs := Preferences{Encoding: read.Meta.Encoding}
+	var accept []string
+	if err := json.Unmarshal(read.Meta.Accept, &accept); err != nil {
+		var list string
+		if json.Unmarshal(read.Meta.Accept, &list) == nil {
+			accept = strings.Split(list, ",")
+		}
+	}
+	for _, mediaType := range accept {
+		// Preference follows order; q values are not weighed
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		if mediaType = strings.ToLower(strings.TrimSpace(mediaType)); mediaType != "" {
+			prefs.Accept = append(prefs.Accept, mediaType)
+		}
+	}
+	return prefs
+}
+
+// ConvertFunc converts the content of a resource to another MIME type
+type ConvertFunc func(ctx context.Context, content []byte) ([]byte, error)
+
+// Converter produces one representation of resources from another, e.g.
+// markdown from HTML
+type Converter struct {
+	// From is the MIME type converted; "text/*" matches any subtype
+	From string
+
+	// To is the MIME type produced
+	To string
+
+	Convert ConvertFunc
+}
+
+// NegotiatorConfig contains configuration for a Negotiator
+type NegotiatorConfig struct {
+	// CacheEntries caps the number of cached conversions (defaults to 128)
+	CacheEntries int
+
+	// CacheBytes caps the total size of cached conversions (defaults to
+	// 16 MiB)
+	CacheBytes int64
+}
+
+// NegotiatorStats counts conversions
+type NegotiatorStats struct {
+	// Conversions ran a converter
+	Conversions int64
+
+	// Hits were served from the cache
+	Hits int64
+
+	// Failures are conversions that returned an error; the contents were
+	// served as they were
+	Failures int64
+
+	Entries int
+	Bytes   int64
+}
+
+// Negotiator adapts resources/read results to the representation clients
+// prefer. Contents of an accepted type are returned as they are; others
+// are converted by the first registered converter producing an accepted
+// type. Contents nothing can convert are returned unchanged, so clients
+// must check the MIME type they got. Conversions are cached by content, so
+// a changed resource is converted again.
+type Negotiator struct {
+	config NegotiatorConfig
+
+	mu         sync.Mutex
+	converters []Converter
+	entries    map[string]*list.Element
+	lru        *list.List
+	stats      NegotiatorStats
+}
+
+// conversion is a cached conversion
+type conversion struct {
+	key     string
+	content []byte
+}
+
+// NewNegotiator creates a negotiator without converters
+func NewNegotiator(config NegotiatorConfig) *Negotiator {
+	if config.CacheEntries <= 0 {
+		config.CacheEntries = 128
+	}
+	if config.CacheBytes <= 0 {
+		config.CacheBytes = 16 << 20
+	}
+	return &Negotiator{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Register adds converters; those registered first are preferred
+func (n *Negotiator) Register(converters ...Converter) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, c := range converters {
+		c.From, c.To = strings.ToLower(c.From), strings.ToLower(c.To)
+		n.converters = append(n.converters, c)
+	}
+}
+
+// Stats returns the conversion counters
+func (n *Negotiator) Stats() NegotiatorStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	stats := n.stats
+	stats.Entries = n.lru.Len()
+	return stats
+}
+
+// Negotiate returns contents in the representation of prefs
+func (n *Negotiator) Negotiate(ctx context.Context, prefs Preferences, contents []mcp.ResourceContents) []mcp.ResourceContents {
+	if prefs.IsZero() {
+		return contents
+	}
+	negotiated := make([]mcp.ResourceContents, len(contents))
+	for i, c := range contents {
+		negotiated[i] = n.negotiate(ctx, prefs, c)
+	}
+	return negotiated
+}
+
+// negotiate converts one item of contents
+func (n *Negotiator) negotiate(ctx context.Context, prefs Preferences, contents mcp.ResourceContents) mcp.ResourceContents {
+	uri, mimeType, content, ok := decodeContents(contents)
+	if !ok {
+		return contents
+	}
+	source := mediaType(mimeType, contents)
+
+	for _, accepted := range prefs.Accept {
+		if matchMediaType(accepted, source) {
+			break
+		}
+		converted, to, ok := n.convert(ctx, uri, source, accepted, content)
+		if ok {
+			content, mimeType = converted, to
+			contents = encodeContents(uri, mimeType, content)
+			break
+		}
+	}
+
+	switch prefs.Encoding {
+	case EncodingText:
+		if utf8.Valid(content) {
+			return mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(content)}
+		}
+	case EncodingBlob:
+		return mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(content)}
+	}
+	return contents
+}
+
+// convert runs the converters from source to accepted until one succeeds
+func (n *Negotiator) convert(ctx context.Context, uri, source, accepted string, content []byte) ([]byte, string, bool) {
+	n.mu.Lock()
+	var candidates []Converter
+	for _, c := range n.converters {
+		if matchMediaType(c.From, source) && matchMediaType(accepted, c.To) {
+			candidates = append(candidates, c)
+		}
+	}
+	n.mu.Unlock()
+
+	digest := sha256.Sum256(content)
+	for _, c := range candidates {
+		key := fmt.Sprintf("%x\x00%s\x00%s", digest, c.From, c.To)
+		if converted, ok := n.cached(key); ok {
+			return converted, c.To, true
+		}
+		converted, err := c.Convert(ctx, content)
+		if err != nil {
+			n.mu.Lock()
+			n.stats.Failures++
+			n.mu.Unlock()
+			logging.Default().WithComponent("resources").WithFields(logging.LogFields{
+				"uri": uri, "from": source, "to": c.To,
+			}).Warn(ctx, "Conversion failed: "+err.Error())
+			continue
+		}
+		n.store(key, converted)
+		return converted, c.To, true
+	}
+	return nil, "", false
+}
+
+// cached returns a cached conversion, marking it recently used
+func (n *Negotiator) cached(key string) ([]byte, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	elem, ok := n.entries[key]
+	if !ok {
+		return nil, false
+	}
+	n.lru.MoveToFront(elem)
+	n.stats.Hits++
+	return elem.Value.(*conversion).content, true
+}
+
+// store caches a conversion if it fits, evicting the least recently used
+// ones as needed
+func (n *Negotiator) store(key string, content []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stats.Conversions++
+	if _, ok := n.entries[key]; ok || int64(len(content)) > n.config.CacheBytes {
+		return
+	}
+	n.entries[key] = n.lru.PushFront(&conversion{key: key, content: content})
+	n.stats.Bytes += int64(len(content))
+	for n.lru.Len() > n.config.CacheEntries || n.stats.Bytes > n.config.CacheBytes {
+		c := n.lru.Remove(n.lru.Back()).(*conversion)
+		delete(n.entries, c.key)
+		n.stats.Bytes -= int64(len(c.content))
+	}
+}
+
+// decodeContents returns the URI, MIME type and raw content of contents
+func decodeContents(contents mcp.ResourceContents) (uri, mimeType string, content []byte, ok bool) {
+	switch c := contents.(type) {
+	case mcp.TextResourceContents:
+		return c.URI, c.MIMEType, []byte(c.Text), true
+	case mcp.BlobResourceContents:
+		content, err := base64.StdEncoding.DecodeString(c.Blob)
+		return c.URI, c.MIMEType, content, err == nil
+	}
+	return "", "", nil, false
+}
+
+// encodeContents returns content as text when mimeType is textual and as a
+// blob otherwise
+func encodeContents(uri, mimeType string, content []byte) mcp.ResourceContents {
+	if isText(mimeType, content) {
+		return mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(content)}
+	}
+	return mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(content)}
+}
+
+// mediaType returns the lower case MIME type of contents without
+// parameters, assuming plain text or binary data when it is unknown
+func mediaType(mimeType string, contents mcp.ResourceContents) string {
+	if base, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return base
+	}
+	if _, ok := contents.(mcp.TextResourceContents); ok {
+		return "text/plain"
+	}
+	return "application/octet-stream"
+}
+
+// matchMediaType reports whether mediaType matches pattern, which may end
+// in "/*" to match any subtype or be "*/*"
+func matchMediaType(pattern, mediaType string) bool {
+	if pattern == "*/*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(strings.ToLower(mediaType), strings.ToLower(prefix)+"/")
+	}
+	return strings.EqualFold(pattern, mediaType)
+}
@@ -0,0 +1,117 @@
+package resources
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		want   Preferences
+	}{
+		{"array", `{"uri":"x","_meta":{"accept":["text/markdown","TEXT/*"]}}`, Preferences{Accept: []string{"text/markdown", "text/*"}}},
+		{"header", `{"_meta":{"accept":"text/markdown, text/plain;q=0.5","encoding":"blob"}}`, Preferences{Accept: []string{"text/markdown", "text/plain"}, Encoding: EncodingBlob}},
+		{"none", `{"uri":"x"}`, Preferences{}},
+		{"malformed", `{"_meta":{"accept":7}}`, Preferences{}},
+		{"empty", ``, Preferences{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParsePreferences(json.RawMessage(tt.params)))
+		})
+	}
+}
+
+// upper is a converter from plain text to an upper case type, counting its
+// calls
+func upper(calls *int) Converter {
+	return Converter{From: "text/plain", To: "text/x-upper", Convert: func(ctx context.Context, content []byte) ([]byte, error) {
+		*calls++
+		return []byte(strings.ToUpper(string(content))), nil
+	}}
+}
+
+func TestNegotiator_Accept(t *testing.T) {
+	var calls int
+	n := NewNegotiator(NegotiatorConfig{})
+	n.Register(upper(&calls))
+	contents := []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: "file://a/notes.txt", MIMEType: "text/plain; charset=utf-8", Text: "hello"},
+		mcp.BlobResourceContents{URI: "file://a/logo.png", MIMEType: "image/png", Blob: "iVBORw0K"},
+	}
+
+	// An accepted type is served as it is, even when a converter exists
+	same := n.Negotiate(context.Background(), Preferences{Accept: []string{"text/*", "text/x-upper"}}, contents)
+	assert.Equal(t, contents, same)
+	assert.Zero(t, calls)
+
+	converted := n.Negotiate(context.Background(), Preferences{Accept: []string{"text/x-upper"}}, contents)
+	assert.Equal(t, mcp.TextResourceContents{URI: "file://a/notes.txt", MIMEType: "text/x-upper", Text: "HELLO"}, converted[0])
+	assert.Equal(t, contents[1], converted[1], "contents nothing converts are served unchanged")
+
+	// Conversions are cached by content
+	n.Negotiate(context.Background(), Preferences{Accept: []string{"text/x-upper"}}, contents)
+	assert.Equal(t, 1, calls)
+	contents[0] = mcp.TextResourceContents{URI: "file://a/notes.txt", MIMEType: "text/plain", Text: "changed"}
+	converted = n.Negotiate(context.Background(), Preferences{Accept: []string{"text/x-upper"}}, contents)
+	assert.Equal(t, "CHANGED", converted[0].(mcp.TextResourceContents).Text)
+	assert.Equal(t, 2, calls)
+
+	stats := n.Stats()
+	assert.Equal(t, int64(2), stats.Conversions)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, 2, stats.Entries)
+}
+
+func TestNegotiator_Encoding(t *testing.T) {
+	n := NewNegotiator(NegotiatorConfig{})
+	text := mcp.TextResourceContents{URI: "file://a/a.json", MIMEType: "application/json", Text: `{"a":1}`}
+	blob := mcp.BlobResourceContents{URI: "file://a/a.json", MIMEType: "application/json", Blob: base64.StdEncoding.EncodeToString([]byte(`{"a":1}`))}
+
+	assert.Equal(t, []mcp.ResourceContents{blob}, n.Negotiate(context.Background(), Preferences{Encoding: EncodingBlob}, []mcp.ResourceContents{text}))
+	assert.Equal(t, []mcp.ResourceContents{text}, n.Negotiate(context.Background(), Preferences{Encoding: EncodingText}, []mcp.ResourceContents{blob}))
+
+	// Binary data cannot be served as text
+	binary := mcp.BlobResourceContents{URI: "file://a/a.bin", Blob: base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe})}
+	assert.Equal(t, []mcp.ResourceContents{binary}, n.Negotiate(context.Background(), Preferences{Encoding: EncodingText}, []mcp.ResourceContents{binary}))
+}
+
+func TestNegotiator_FallsBack(t *testing.T) {
+	var calls int
+	n := NewNegotiator(NegotiatorConfig{})
+	n.Register(
+		Converter{From: "text/*", To: "text/x-upper", Convert: func(context.Context, []byte) ([]byte, error) {
+			return nil, errors.New("broken")
+		}},
+		upper(&calls),
+	)
+	contents := []mcp.ResourceContents{mcp.TextResourceContents{URI: "file://a/a.txt", Text: "hi"}}
+
+	// Untyped text is plain text; the next converter runs when one fails
+	converted := n.Negotiate(context.Background(), Preferences{Accept: []string{"text/x-upper"}}, contents)
+	assert.Equal(t, "HI", converted[0].(mcp.TextResourceContents).Text)
+	assert.Equal(t, int64(1), n.Stats().Failures)
+}
+
+func TestNegotiator_CacheLimits(t *testing.T) {
+	var calls int
+	n := NewNegotiator(NegotiatorConfig{CacheEntries: 1})
+	n.Register(upper(&calls))
+	for _, text := range []string{"a", "b", "a"} {
+		converted := n.Negotiate(context.Background(), Preferences{Accept: []string{"text/x-upper"}},
+			[]mcp.ResourceContents{mcp.TextResourceContents{URI: "file://a/a.txt", MIMEType: "text/plain", Text: text}})
+		require.Len(t, converted, 1)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, NegotiatorStats{Conversions: 3, Entries: 1, Bytes: 1}, n.Stats())
+}
@@ -0,0 +1,260 @@
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/yosida95/uritemplate/v3"
+	"gopkg.in/yaml.v3"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// ToolCaller invokes a tool. tools.Registry.Call implements it, reaching
+// local and upstream tools alike.
+type ToolCaller func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// VirtualResource declares a resource whose contents are produced by
+// calling a tool on every read
+type VirtualResource struct {
+	// URI identifies the resource. A URI containing {variables} is
+	// published as a template, and the variables of a read URI can be
+	// referenced in Arguments.
+	URI         string `yaml:"uri" json:"uri"`
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// MIMEType is reported for text produced by the tool (defaults to
+	// text/plain)
+	MIMEType string `yaml:"mimeType,omitempty" json:"mimeType,omitempty"`
+
+	// Tool is called to produce the contents
+	Tool string `yaml:"tool" json:"tool"`
+
+	// Arguments are passed to the tool; "{name}" in string values is
+	// replaced by the URI variable name
+	Arguments map[string]any `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+
+	// CacheTTL serves the contents of a URI from cache for this long (zero
+	// calls the tool on every read)
+	CacheTTL time.Duration `yaml:"cacheTTL,omitempty" json:"cacheTTL,omitempty"`
+}
+
+// VirtualConfig contains configuration for a VirtualProvider
+type VirtualConfig struct {
+	Resources []VirtualResource `yaml:"resources" json:"resources"`
+
+	// Call invokes the tools (required)
+	Call ToolCaller `yaml:"-" json:"-"`
+}
+
+// LoadVirtualConfig reads virtual resource declarations from a YAML file
+func LoadVirtualConfig(path string) (VirtualConfig, error) {
+	var config VirtualConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// virtualResource is a validated declaration
+type virtualResource struct {
+	VirtualResource
+	template *uritemplate.Template
+	cache    *Cache
+}
+
+// VirtualProvider publishes resources computed by tools
+type VirtualProvider struct {
+	call      ToolCaller
+	resources []*virtualResource
+}
+
+// NewVirtualProvider validates the declared resources
+func NewVirtualProvider(config VirtualConfig) (*VirtualProvider, error) {
+	if config.Call == nil {
+		return nil, errors.New("a tool caller is required")
+	}
+
+	p := &VirtualProvider{call: config.Call}
+	seen := make(map[string]bool)
+	for _, def := range config.Resources {
+		switch {
+		case def.URI == "":
+			return nil, errors.New("virtual resource uri is required")
+		case def.Tool == "":
+			return nil, fmt.Errorf("virtual resource %s: tool is required", def.URI)
+		case seen[def.URI]:
+			return nil, fmt.Errorf("duplicate virtual resource %s", def.URI)
+		}
+		seen[def.URI] = true
+
+		template, err := uritemplate.New(def.URI)
+		if err != nil {
+			return nil, fmt.Errorf("virtual resource %s: %w", def.URI, err)
+		}
+		if def.Name == "" {
+			def.Name = def.URI
+		}
+		if def.MIMEType == "" {
+			def.MIMEType = "text/plain"
+		}
+		r := &virtualResource{VirtualResource: def, template: template}
+		if def.CacheTTL > 0 {
+			r.cache = NewCache(CacheConfig{MaxAge: def.CacheTTL})
+		}
+		p.resources = append(p.resources, r)
+	}
+	return p, nil
+}
+
+// Read calls the tool of the resource matching uri
+func (p *VirtualProvider) Read(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	for _, r := range p.resources {
+		vars := r.match(uri)
+		if vars == nil {
+			continue
+		}
+		if r.cache != nil {
+			return r.cache.Read(ctx, uri, func(ctx context.Context, uri string, cached Validators) (Version, error) {
+				return p.compute(ctx, r, uri, vars)
+			})
+		}
+		version, err := p.compute(ctx, r, uri, vars)
+		return version.Contents, err
+	}
+	return nil, mcperrors.NewResourceNotFoundError(uri)
+}
+
+// Register publishes the resources, as templates when their URI has
+// variables
+func (p *VirtualProvider) Register(s Server) {
+	for _, r := range p.resources {
+		if len(r.template.Varnames()) == 0 {
+			resource := mcp.NewResource(r.URI, r.Name,
+				mcp.WithResourceDescription(r.Description), mcp.WithMIMEType(r.MIMEType))
+			s.AddResources(server.ServerResource{Resource: resource, Handler: p.handleRead})
+			continue
+		}
+		template := mcp.NewResourceTemplate(r.URI, r.Name,
+			mcp.WithTemplateDescription(r.Description), mcp.WithTemplateMIMEType(r.MIMEType))
+		s.AddResourceTemplate(template, p.handleRead)
+	}
+}
+
+// handleRead serves resources/read for virtual resources
+func (p *VirtualProvider) handleRead(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return p.Read(ctx, request.Params.URI)
+}
+
+// match returns the variables of uri, or nil if it is not this resource
+func (r *virtualResource) match(uri string) map[string]string {
+	if len(r.template.Varnames()) == 0 {
+		if uri != r.URI {
+			return nil
+		}
+		return map[string]string{}
+	}
+	values := r.template.Match(uri)
+	if values == nil {
+		return nil
+	}
+	vars := make(map[string]string, len(values))
+	for name, value := range values {
+		vars[name] = value.String()
+	}
+	return vars
+}
+
+// compute calls the tool of r and converts its result into contents
+// versioned by their digest
+func (p *VirtualProvider) compute(ctx context.Context, r *virtualResource, uri string, vars map[string]string) (Version, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = r.Tool
+	request.Params.Arguments = expandArguments(r.Arguments, vars)
+
+	result, err := p.call(ctx, request)
+	if err != nil {
+		return Version{}, mcperrors.NewResourceError(uri, err)
+	}
+
+	var text strings.Builder
+	var contents []mcp.ResourceContents
+	for _, content := range result.Content {
+		switch content := content.(type) {
+		case mcp.TextContent:
+			text.WriteString(content.Text)
+		case mcp.ImageContent:
+			contents = append(contents, mcp.BlobResourceContents{URI: uri, MIMEType: content.MIMEType, Blob: content.Data})
+		case mcp.AudioContent:
+			contents = append(contents, mcp.BlobResourceContents{URI: uri, MIMEType: content.MIMEType, Blob: content.Data})
+		case mcp.EmbeddedResource:
+			contents = append(contents, content.Resource)
+		}
+	}
+	if result.IsError {
+		return Version{}, mcperrors.NewResourceError(uri, fmt.Errorf("tool %s failed: %s", r.Tool, text.String()))
+	}
+	if text.Len() > 0 || len(contents) == 0 {
+		contents = append([]mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: r.MIMEType, Text: text.String()}}, contents...)
+	}
+
+	digest := sha256.New()
+	for _, content := range contents {
+		fmt.Fprintf(digest, "%#v", content)
+	}
+	return Version{
+		Contents:   contents,
+		Validators: Validators{ETag: `"` + hex.EncodeToString(digest.Sum(nil)[:16]) + `"`},
+	}, nil
+}
+
+// expandArguments replaces "{name}" in the string values of args with the
+// URI variables
+func expandArguments(args map[string]any, vars map[string]string) map[string]any {
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	var expand func(value any) any
+	expand = func(value any) any {
+		switch value := value.(type) {
+		case string:
+			return replacer.Replace(value)
+		case map[string]any:
+			expanded := make(map[string]any, len(value))
+			for key, v := range value {
+				expanded[key] = expand(v)
+			}
+			return expanded
+		case []any:
+			expanded := make([]any, len(value))
+			for i, v := range value {
+				expanded[i] = expand(v)
+			}
+			return expanded
+		default:
+			return value
+		}
+	}
+
+	expanded := make(map[string]any, len(args))
+	for key, value := range args {
+		expanded[key] = expand(value)
+	}
+	return expanded
+}
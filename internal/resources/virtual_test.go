@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusTool reports the status of its "service" argument and counts calls
+type statusTool struct {
+	calls int
+}
+
+func (s *statusTool) call(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.calls++
+	switch request.Params.Name {
+	case "status":
+		return mcp.NewToolResultText("status of " + request.GetString("service", "") + ": ok"), nil
+	case "snapshot":
+		return &mcp.CallToolResult{Content: []mcp.Content{mcp.NewImageContent("aGVsbG8=", "image/png")}}, nil
+	default:
+		return mcp.NewToolResultError("unknown tool"), nil
+	}
+}
+
+func TestVirtualProvider(t *testing.T) {
+	tool := &statusTool{}
+	p, err := NewVirtualProvider(VirtualConfig{
+		Call: tool.call,
+		Resources: []VirtualResource{
+			{URI: "status://all", Tool: "status", Arguments: map[string]any{"service": "all"}},
+			{URI: "status://services/{name}", Name: "Service status", Tool: "status",
+				Arguments: map[string]any{"service": "svc-{name}"}, CacheTTL: time.Hour},
+			{URI: "status://snapshot", Tool: "snapshot"},
+			{URI: "status://broken", Tool: "missing"},
+		},
+	})
+	require.NoError(t, err)
+
+	s := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, true))
+	p.Register(s)
+	read := func(uri string) ([]mcp.ResourceContents, *mcp.JSONRPCError) {
+		t.Helper()
+		message := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"`+uri+`"}}`))
+		if rpcErr, ok := message.(mcp.JSONRPCError); ok {
+			return nil, &rpcErr
+		}
+		return message.(mcp.JSONRPCResponse).Result.(mcp.ReadResourceResult).Contents, nil
+	}
+
+	contents, rpcErr := read("status://all")
+	require.Nil(t, rpcErr)
+	assert.Equal(t, mcp.TextResourceContents{URI: "status://all", MIMEType: "text/plain", Text: "status of all: ok"}, contents[0])
+
+	// Template variables reach the arguments, and results are cached per URI
+	for range 2 {
+		contents, rpcErr = read("status://services/web")
+		require.Nil(t, rpcErr)
+		assert.Equal(t, "status of svc-web: ok", contents[0].(mcp.TextResourceContents).Text)
+	}
+	_, rpcErr = read("status://services/db")
+	require.Nil(t, rpcErr)
+	assert.Equal(t, 3, tool.calls)
+
+	contents, rpcErr = read("status://snapshot")
+	require.Nil(t, rpcErr)
+	assert.Equal(t, mcp.BlobResourceContents{URI: "status://snapshot", MIMEType: "image/png", Blob: "aGVsbG8="}, contents[0])
+
+	_, rpcErr = read("status://broken")
+	assert.NotNil(t, rpcErr)
+
+	_, err = p.Read(context.Background(), "status://unknown")
+	assert.Error(t, err)
+}
+
+func TestNewVirtualProvider_Errors(t *testing.T) {
+	tool := &statusTool{}
+	_, err := NewVirtualProvider(VirtualConfig{Resources: []VirtualResource{{URI: "a://b", Tool: "t"}}})
+	assert.Error(t, err)
+
+	for _, resources := range [][]VirtualResource{
+		{{Tool: "t"}},
+		{{URI: "a://b"}},
+		{{URI: "a://b", Tool: "t"}, {URI: "a://b", Tool: "t"}},
+		{{URI: "a://{unclosed", Tool: "t"}},
+	} {
+		_, err := NewVirtualProvider(VirtualConfig{Call: tool.call, Resources: resources})
+		assert.Error(t, err)
+	}
+}
+
+func TestLoadVirtualConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "virtual.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+resources:
+  - uri: status://services/{name}
+    tool: status
+    arguments: {service: "{name}"}
+    cacheTTL: 30s
+`), 0o644))
+
+	config, err := LoadVirtualConfig(path)
+	require.NoError(t, err)
+	require.Len(t, config.Resources, 1)
+	assert.Equal(t, "status", config.Resources[0].Tool)
+	assert.Equal(t, 30*time.Second, config.Resources[0].CacheTTL)
+	assert.Equal(t, map[string]any{"service": "{name}"}, config.Resources[0].Arguments)
+}
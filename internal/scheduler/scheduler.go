@@ -0,0 +1,368 @@
+// Package scheduler runs registered background jobs on interval or cron
+// schedules and delivers their results to clients as notifications or
+// resource updates. Runs are jittered, may not overlap unless allowed, and
+// are counted for metrics.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// MethodResourceUpdated is the notification announcing a changed resource
+const MethodResourceUpdated = mcp.MethodNotificationResourceUpdated
+
+var (
+	// ErrJobExists is returned when adding a job whose name is taken
+	ErrJobExists = errors.New("job already exists")
+
+	// ErrJobNotFound is returned for operations on unknown jobs
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrSchedulerStopped is returned when starting a stopped scheduler
+	ErrSchedulerStopped = errors.New("scheduler is stopped")
+)
+
+// JobFunc performs a job. Its result is delivered with the job's
+// notification.
+type JobFunc func(ctx context.Context) (interface{}, error)
+
+// Notifier delivers notifications to connected clients
+type Notifier interface {
+	SendNotificationToAllClients(method string, params map[string]any)
+}
+
+// Job is a registered background task
+type Job struct {
+	// Name identifies the job
+	Name string
+
+	// Schedule decides when the job runs
+	Schedule Schedule
+
+	// Run performs the job
+	Run JobFunc
+
+	// Jitter delays each run by a random duration up to this value
+	Jitter time.Duration
+
+	// Timeout bounds each run (0 means no limit)
+	Timeout time.Duration
+
+	// AllowOverlap lets a run start while the previous one is still going;
+	// otherwise the later run is skipped
+	AllowOverlap bool
+
+	// Notification is the method sent to clients after each run, with the
+	// job name and its result or error
+	Notification string
+
+	// ResourceURI is announced with notifications/resources/updated after
+	// each successful run
+	ResourceURI string
+}
+
+// JobStats describes the runs of a job
+type JobStats struct {
+	Name         string        `json:"name"`
+	Runs         int64         `json:"runs"`
+	Failures     int64         `json:"failures"`
+	Skipped      int64         `json:"skipped"`
+	Running      int           `json:"running"`
+	LastRun      time.Time     `json:"lastRun,omitempty"`
+	LastDuration time.Duration `json:"lastDuration"`
+	LastError    string        `json:"lastError,omitempty"`
+	NextRun      time.Time     `json:"nextRun,omitempty"`
+}
+
+// Config contains configuration for a Scheduler
+type Config struct {
+	// Notifier delivers job notifications (optional)
+	Notifier Notifier
+
+	// Clock schedules the runs (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// jobState is a job and its bookkeeping
+type jobState struct {
+	job    Job
+	stop   chan struct{}
+	mu     sync.Mutex
+	stats  JobStats
+	runNow chan struct{}
+}
+
+// Scheduler runs jobs in the background
+type Scheduler struct {
+	config Config
+
+	mu      sync.Mutex
+	jobs    map[string]*jobState
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler; jobs run once it is started
+func New(config Config) *Scheduler {
+	config.Clock = clock.Or(config.Clock)
+	return &Scheduler{
+		config: config,
+		jobs:   make(map[string]*jobState),
+	}
+}
+
+// Add registers a job, starting it if the scheduler is running
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return errors.New("job name, schedule and run function are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+	}
+	state := &jobState{
+		job:    job,
+		stop:   make(chan struct{}),
+		runNow: make(chan struct{}, 1),
+		stats:  JobStats{Name: job.Name},
+	}
+	s.jobs[job.Name] = state
+	if s.started && !s.stopped {
+		s.startJob(state)
+	}
+	return nil
+}
+
+// Remove unregisters a job; a run in progress completes
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	delete(s.jobs, name)
+	close(state.stop)
+	return nil
+}
+
+// RunNow triggers a job immediately, subject to overlap prevention
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	state, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+
+	select {
+	case state.runNow <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start begins running jobs
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+	if s.started {
+		return nil
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, state := range s.jobs {
+		s.startJob(state)
+	}
+	return nil
+}
+
+// Shutdown stops scheduling, cancels running jobs and waits for them to
+// return or ctx to end
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started || s.stopped {
+		s.stopped = true
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	s.cancel()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the statistics of every job sorted by name
+func (s *Scheduler) Stats() []JobStats {
+	s.mu.Lock()
+	states := make([]*jobState, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		states = append(states, state)
+	}
+	s.mu.Unlock()
+
+	stats := make([]JobStats, len(states))
+	for i, state := range states {
+		state.mu.Lock()
+		stats[i] = state.stats
+		state.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// startJob launches the scheduling loop of a job; s.mu must be held
+func (s *Scheduler) startJob(state *jobState) {
+	s.wg.Add(1)
+	go s.loop(s.ctx, state)
+}
+
+// loop waits for each scheduled time and launches runs
+func (s *Scheduler) loop(ctx context.Context, state *jobState) {
+	defer s.wg.Done()
+
+	for {
+		next := state.job.Schedule.Next(s.config.Clock.Now())
+		if next.IsZero() {
+			return
+		}
+		if state.job.Jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(state.job.Jitter))))
+		}
+		state.mu.Lock()
+		state.stats.NextRun = next
+		state.mu.Unlock()
+
+		timer := s.config.Clock.NewTimer(s.config.Clock.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-state.stop:
+			timer.Stop()
+			return
+		case <-state.runNow:
+			timer.Stop()
+		case <-timer.C():
+		}
+
+		if !state.begin() {
+			continue
+		}
+		s.wg.Add(1)
+		go s.run(ctx, state)
+	}
+}
+
+// begin marks a run as started unless it would overlap
+func (st *jobState) begin() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.stats.Running > 0 && !st.job.AllowOverlap {
+		st.stats.Skipped++
+		return false
+	}
+	st.stats.Running++
+	return true
+}
+
+// run performs one run of a job and delivers its outcome
+func (s *Scheduler) run(ctx context.Context, state *jobState) {
+	defer s.wg.Done()
+	job := state.job
+
+	runCtx := ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := s.config.Clock.Now()
+	result, err := safeRun(runCtx, job.Run)
+	duration := s.config.Clock.Since(start)
+
+	state.mu.Lock()
+	state.stats.Running--
+	state.stats.Runs++
+	state.stats.LastRun = start
+	state.stats.LastDuration = duration
+	state.stats.LastError = ""
+	if err != nil {
+		state.stats.Failures++
+		state.stats.LastError = err.Error()
+	}
+	state.mu.Unlock()
+
+	if err != nil {
+		logging.Default().WithField("job", job.Name).Error(
+			logging.WithComponent(ctx, "scheduler"), err, "Scheduled job failed")
+	}
+	s.deliver(job, result, err)
+}
+
+// deliver sends the notifications configured for a job
+func (s *Scheduler) deliver(job Job, result interface{}, err error) {
+	if s.config.Notifier == nil {
+		return
+	}
+
+	if job.Notification != "" {
+		params := map[string]any{"job": job.Name}
+		if err != nil {
+			params["error"] = err.Error()
+		} else {
+			params["result"] = result
+		}
+		s.config.Notifier.SendNotificationToAllClients(job.Notification, params)
+	}
+	if job.ResourceURI != "" && err == nil {
+		n := mcp.NewResourceUpdatedNotification(job.ResourceURI)
+		s.config.Notifier.SendNotificationToAllClients(n.Method, n.Params)
+	}
+}
+
+// safeRun calls fn, converting a panic into an error
+func safeRun(ctx context.Context, fn JobFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = mcperrors.FromPanic(r)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// recordingNotifier collects sent notifications
+type recordingNotifier struct {
+	mu    sync.Mutex
+	sent  []string
+	param []map[string]any
+}
+
+func (n *recordingNotifier) SendNotificationToAllClients(method string, params map[string]any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, method)
+	n.param = append(n.param, params)
+}
+
+func (n *recordingNotifier) methods() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.sent...)
+}
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // a Saturday
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@every 90s", base.Add(90 * time.Second)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2026, 3, 16, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"5,10 10 14 3 *", time.Date(2026, 3, 14, 10, 10, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, s.Next(base))
+		})
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every -1s", "a * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+
+	// February 30th never happens
+	never := MustParseSchedule("0 0 30 2 *")
+	assert.True(t, never.Next(base).IsZero())
+}
+
+func TestScheduler_RunsAndDelivers(t *testing.T) {
+	notifier := &recordingNotifier{}
+	s := New(Config{Notifier: notifier})
+
+	var calls atomic.Int32
+	require.NoError(t, s.Add(Job{
+		Name:         "refresh",
+		Schedule:     Every(10 * time.Millisecond),
+		Run:          func(ctx context.Context) (interface{}, error) { return calls.Add(1), nil },
+		Notification: "notifications/jobs/refresh",
+		ResourceURI:  "cache://status",
+	}))
+	assert.ErrorIs(t, s.Add(Job{Name: "refresh", Schedule: Every(time.Second), Run: func(ctx context.Context) (interface{}, error) { return nil, nil }}), ErrJobExists)
+
+	require.NoError(t, s.Start())
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	methods := notifier.methods()
+	require.GreaterOrEqual(t, len(methods), 4)
+	assert.Equal(t, "notifications/jobs/refresh", methods[0])
+	assert.Equal(t, MethodResourceUpdated, methods[1])
+	assert.Equal(t, "refresh", notifier.param[0]["job"])
+
+	stats := s.Stats()
+	require.Len(t, stats, 1)
+	assert.GreaterOrEqual(t, stats[0].Runs, int64(2))
+	assert.ErrorIs(t, s.Start(), ErrSchedulerStopped)
+}
+
+func TestScheduler_FakeClock(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC)
+	fake := clock.NewFake(base)
+	s := New(Config{Clock: fake})
+
+	runs := make(chan time.Time, 1)
+	require.NoError(t, s.Add(Job{
+		Name:     "report",
+		Schedule: MustParseSchedule("*/15 * * * *"),
+		Run: func(ctx context.Context) (interface{}, error) {
+			runs <- fake.Now()
+			return nil, nil
+		},
+	}))
+	require.NoError(t, s.Start())
+	defer s.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, fake.BlockUntil(ctx, 1))
+	assert.Equal(t, base.Add(7*time.Minute+30*time.Second), s.Stats()[0].NextRun)
+
+	fake.Advance(7 * time.Minute)
+	select {
+	case <-runs:
+		t.Fatal("job ran before its time")
+	default:
+	}
+
+	fake.Advance(30 * time.Second)
+	select {
+	case at := <-runs:
+		assert.Equal(t, time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC), at)
+	case <-ctx.Done():
+		t.Fatal("job did not run")
+	}
+}
+
+func TestScheduler_OverlapAndFailures(t *testing.T) {
+	s := New(Config{})
+	release := make(chan struct{})
+
+	require.NoError(t, s.Add(Job{
+		Name:     "slow",
+		Schedule: Every(time.Hour),
+		Run: func(ctx context.Context) (interface{}, error) {
+			<-release
+			return nil, errors.New("upstream unavailable")
+		},
+	}))
+	require.NoError(t, s.Add(Job{
+		Name:     "panics",
+		Schedule: Every(time.Hour),
+		Run:      func(ctx context.Context) (interface{}, error) { panic("boom") },
+	}))
+	require.NoError(t, s.Start())
+
+	require.NoError(t, s.RunNow("slow"))
+	assert.Eventually(t, func() bool { return s.Stats()[1].Running == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, s.RunNow("slow"))
+	assert.Eventually(t, func() bool { return s.Stats()[1].Skipped == 1 }, time.Second, time.Millisecond)
+	close(release)
+
+	require.NoError(t, s.RunNow("panics"))
+	assert.Eventually(t, func() bool {
+		stats := s.Stats()
+		return stats[0].Failures == 1 && stats[1].Failures == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "upstream unavailable", s.Stats()[1].LastError)
+
+	assert.ErrorIs(t, s.RunNow("missing"), ErrJobNotFound)
+	require.NoError(t, s.Remove("slow"))
+	assert.ErrorIs(t, s.Remove("slow"), ErrJobNotFound)
+	require.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	s := New(Config{})
+	done := make(chan error, 1)
+	require.NoError(t, s.Add(Job{
+		Name:     "bounded",
+		Schedule: Every(time.Hour),
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			done <- ctx.Err()
+			return nil, ctx.Err()
+		},
+	}))
+	require.NoError(t, s.Start())
+	defer s.Shutdown(context.Background())
+
+	require.NoError(t, s.RunNow("bounded"))
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("job was not cancelled")
+	}
+}
@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// AdminMethod is the router method served by AdminHandler
+const AdminMethod = "admin/schemas"
+
+// AdminParams are the parameters of the admin/schemas method
+type AdminParams struct {
+	// Kind limits the dump to schemas of that kind
+	Kind Kind `json:"kind,omitempty"`
+
+	// Name selects a single schema of Kind
+	Name string `json:"name,omitempty"`
+}
+
+// AdminResult is the result of the admin/schemas method
+type AdminResult struct {
+	Schemas []Entry `json:"schemas"`
+}
+
+// AdminHandler returns a router handler dumping the registered schemas.
+// Register it under AdminMethod.
+func AdminHandler(r *Registry) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params AdminParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+
+		if params.Name == "" {
+			return jsonrpc.NewResponse(AdminResult{Schemas: r.List(params.Kind)}, req.ID)
+		}
+		if params.Kind == "" {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("kind is required with name"), req.ID)
+		}
+		entry, ok := r.Get(params.Kind, params.Name)
+		if !ok {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(ErrNotFound.Error()+": "+ID(params.Kind, params.Name)), req.ID)
+		}
+		return jsonrpc.NewResponse(AdminResult{Schemas: []Entry{entry}}, req.ID)
+	})
+}
@@ -0,0 +1,240 @@
+// Package schema is the central registry of the JSON Schemas the server
+// publishes: the input schemas of local and upstream tools, the arguments of
+// prompts, and shared definitions they reference.
+//
+// Schemas are compiled on first validation and the compiled form is cached
+// until the schema, or a schema it references, changes. A schema refers to
+// a shared definition, or any other registered schema, by RefPrefix and
+// its ID:
+//
+//	registry := schema.New()
+//	registry.Put(schema.KindDefinition, "address", "local", addressSchema)
+//	registry.Put(schema.KindTool, "ship", "local", json.RawMessage(`{
+//		"type": "object",
+//		"properties": {"to": {"$ref": "mcp-schema:///defs/address"}}
+//	}`))
+//	err := registry.Validate(schema.KindTool, "ship", arguments)
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/validator"
+)
+
+// Kind is what a schema describes
+type Kind string
+
+// Schema kinds
+const (
+	// KindTool is the input schema of a tool
+	KindTool Kind = "tools"
+
+	// KindPrompt describes the arguments of a prompt
+	KindPrompt Kind = "prompts"
+
+	// KindResource describes the JSON contents of a resource
+	KindResource Kind = "resources"
+
+	// KindDefinition is a shared definition referenced by other schemas
+	KindDefinition Kind = "defs"
+)
+
+// RefPrefix starts the $ref of a registered schema, followed by its ID
+const RefPrefix = "mcp-schema:///"
+
+// refPattern matches references to registered schemas, capturing the ID
+var refPattern = regexp.MustCompile(`"` + regexp.QuoteMeta(RefPrefix) + `([^"#]+)`)
+
+// ErrNotFound is returned for schemas that are not registered
+var ErrNotFound = errors.New("schema not registered")
+
+// ID returns the ID of the schema of name, e.g. "tools/echo"
+func ID(kind Kind, name string) string {
+	return string(kind) + "/" + name
+}
+
+// Entry is a registered schema
+type Entry struct {
+	ID     string          `json:"id"`
+	Kind   Kind            `json:"kind"`
+	Name   string          `json:"name"`
+	Source string          `json:"source,omitempty"`
+	Schema json.RawMessage `json:"schema"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// entry is a registered schema with its compiled form
+type entry struct {
+	Entry
+	compiled *gojsonschema.Schema
+	err      error
+}
+
+// Registry holds schemas by ID
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// New creates an empty registry
+func New() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Put registers the schema of name, replacing the previous one. source
+// tells where the schema comes from, e.g. "local" or an upstream name.
+func (r *Registry) Put(kind Kind, name, source string, schema json.RawMessage) error {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema); err != nil {
+		return fmt.Errorf("schema %s: %w", ID(kind, name), err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := ID(kind, name)
+	if e, ok := r.entries[id]; ok && e.Source == source && bytes.Equal(e.Schema, compact.Bytes()) {
+		return nil
+	}
+	r.entries[id] = &entry{Entry: Entry{
+		ID:        id,
+		Kind:      kind,
+		Name:      name,
+		Source:    source,
+		Schema:    compact.Bytes(),
+		UpdatedAt: time.Now(),
+	}}
+	r.invalidate()
+	return nil
+}
+
+// Remove withdraws the schema of name
+func (r *Registry) Remove(kind Kind, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[ID(kind, name)]; ok {
+		delete(r.entries, ID(kind, name))
+		r.invalidate()
+	}
+}
+
+// Get returns the schema of name
+func (r *Registry) Get(kind Kind, name string) (Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[ID(kind, name)]
+	if !ok {
+		return Entry{}, false
+	}
+	return e.Entry, true
+}
+
+// List returns the schemas of kind, or all of them when kind is empty,
+// sorted by ID
+func (r *Registry) List(kind Kind) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []Entry
+	for _, e := range r.entries {
+		if kind == "" || e.Kind == kind {
+			entries = append(entries, e.Entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// Validate checks document, a JSON-serializable value, against the schema
+// of name. Violations are reported as *validator.ValidationError or
+// *validator.MultiValidationError.
+func (r *Registry) Validate(kind Kind, name string, document any) error {
+	compiled, err := r.compiled(kind, name)
+	if err != nil {
+		return err
+	}
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return fmt.Errorf("validate against %s: %w", ID(kind, name), err)
+	}
+	if !result.Valid() {
+		return validator.FormatErrors(result.Errors())
+	}
+	return nil
+}
+
+// compiled returns the compiled schema of name, compiling it on first use
+func (r *Registry) compiled(kind Kind, name string) (*gojsonschema.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[ID(kind, name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ID(kind, name))
+	}
+	if e.compiled == nil && e.err == nil {
+		e.compiled, e.err = r.compile(e)
+	}
+	return e.compiled, e.err
+}
+
+// compile compiles the schema of e, resolving references to the other
+// registered schemas. It must be called with r.mu held.
+func (r *Registry) compile(e *entry) (*gojsonschema.Schema, error) {
+	loader := gojsonschema.NewSchemaLoader()
+	added := map[string]bool{e.ID: true}
+	var add func(schema json.RawMessage) error
+	add = func(schema json.RawMessage) error {
+		for _, match := range refPattern.FindAllSubmatch(schema, -1) {
+			id := string(match[1])
+			if added[id] {
+				continue
+			}
+			added[id] = true
+			ref, ok := r.entries[id]
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+			// Referenced schemas are added before those referencing them
+			if err := add(ref.Schema); err != nil {
+				return err
+			}
+			if err := loader.AddSchema(RefPrefix+id, gojsonschema.NewBytesLoader(ref.Schema)); err != nil {
+				return fmt.Errorf("schema %s: %w", id, err)
+			}
+		}
+		return nil
+	}
+	if err := add(e.Schema); err != nil {
+		return nil, fmt.Errorf("compile schema %s: %w", e.ID, err)
+	}
+
+	compiled, err := loader.Compile(gojsonschema.NewBytesLoader(e.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("compile schema %s: %w", e.ID, err)
+	}
+	return compiled, nil
+}
+
+// invalidate drops the compiled schemas referencing other schemas after
+// one of them changed. It must be called with r.mu held.
+func (r *Registry) invalidate() {
+	for _, e := range r.entries {
+		if references(e.Schema) {
+			e.compiled, e.err = nil, nil
+		}
+	}
+}
+
+// references reports whether schema refers to registered schemas
+func references(schema json.RawMessage) bool {
+	return bytes.Contains(schema, []byte(RefPrefix))
+}
@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/validator"
+)
+
+const shipSchema = `{
+	"type": "object",
+	"properties": {"to": {"$ref": "mcp-schema:///defs/address"}},
+	"required": ["to"]
+}`
+
+func TestRegistry_Validate(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Put(KindDefinition, "city", "local", json.RawMessage(`{"type":"string","minLength":1}`)))
+	require.NoError(t, r.Put(KindDefinition, "address", "local", json.RawMessage(
+		`{"type":"object","properties":{"city":{"$ref":"mcp-schema:///defs/city"}},"required":["city"]}`)))
+	require.NoError(t, r.Put(KindTool, "ship", "local", json.RawMessage(shipSchema)))
+
+	assert.NoError(t, r.Validate(KindTool, "ship", map[string]any{"to": map[string]any{"city": "Oslo"}}))
+
+	err := r.Validate(KindTool, "ship", map[string]any{"to": map[string]any{"city": ""}})
+	var violation *validator.ValidationError
+	require.ErrorAs(t, err, &violation)
+	assert.Equal(t, "(root).to.city", violation.InstancePath)
+
+	// Changing a referenced definition recompiles the schemas using it
+	require.NoError(t, r.Put(KindDefinition, "city", "local", json.RawMessage(`{"type":"string"}`)))
+	assert.NoError(t, r.Validate(KindTool, "ship", map[string]any{"to": map[string]any{"city": ""}}))
+
+	// References to missing schemas fail to compile
+	r.Remove(KindDefinition, "city")
+	assert.ErrorIs(t, r.Validate(KindTool, "ship", map[string]any{}), ErrNotFound)
+	assert.ErrorIs(t, r.Validate(KindTool, "missing", map[string]any{}), ErrNotFound)
+
+	assert.Error(t, r.Put(KindTool, "broken", "local", json.RawMessage(`{`)))
+}
+
+func TestRegistry_List(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Put(KindTool, "b", "github", json.RawMessage(`{"type": "object"}`)))
+	require.NoError(t, r.Put(KindTool, "a", "local", json.RawMessage(`{"type":"object"}`)))
+	require.NoError(t, r.Put(KindPrompt, "greet", "local", json.RawMessage(`{"type":"object"}`)))
+
+	entries := r.List(KindTool)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "tools/a", entries[0].ID)
+	assert.Equal(t, "github", entries[1].Source)
+	assert.JSONEq(t, `{"type":"object"}`, string(entries[1].Schema))
+	assert.Len(t, r.List(""), 3)
+
+	// Putting an unchanged schema keeps the entry
+	before, _ := r.Get(KindTool, "b")
+	require.NoError(t, r.Put(KindTool, "b", "github", json.RawMessage(`{"type":"object"}`)))
+	after, _ := r.Get(KindTool, "b")
+	assert.Equal(t, before.UpdatedAt, after.UpdatedAt)
+}
+
+func TestAdminHandler(t *testing.T) {
+	r := New()
+	require.NoError(t, r.Put(KindTool, "ship", "local", json.RawMessage(shipSchema)))
+	require.NoError(t, r.Put(KindPrompt, "greet", "local", json.RawMessage(`{"type":"object"}`)))
+	handler := AdminHandler(r)
+
+	resp := handler.Handle(context.Background(), &jsonrpc.Request{ID: 1, Method: AdminMethod})
+	require.Nil(t, resp.Error)
+	assert.Len(t, resp.Result.(AdminResult).Schemas, 2)
+
+	resp = handler.Handle(context.Background(), &jsonrpc.Request{
+		ID:     2,
+		Method: AdminMethod,
+		Params: map[string]interface{}{"kind": "tools", "name": "ship"},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(AdminResult)
+	require.Len(t, result.Schemas, 1)
+	assert.Equal(t, "tools/ship", result.Schemas[0].ID)
+
+	resp = handler.Handle(context.Background(), &jsonrpc.Request{
+		ID:     3,
+		Method: AdminMethod,
+		Params: map[string]interface{}{"kind": "tools", "name": "missing"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, resp.Error.Code)
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/wirecheck"
+	"github.com/meta-mcp/meta-mcp-server/internal/throttle"
+)
+
+// Conn carries JSON-RPC messages between the server and one client
+type Conn interface {
+	// ReadMessage returns the next message from the client
+	ReadMessage() ([]byte, error)
+
+	// WriteMessage sends one message to the client. It is safe for
+	// concurrent use.
+	WriteMessage(message []byte) error
+
+	// Close ends the connection, unblocking ReadMessage
+	Close() error
+}
+
+// batchConn is a Conn that can send several messages with one write
+type batchConn interface {
+	// WriteMessages sends messages in order. It is safe for concurrent use.
+	WriteMessages(messages [][]byte) error
+}
+
+// maxMessageSize bounds a single newline-delimited message
+const maxMessageSize = 16 << 20
+
+// writeBufferSize is the size of the write buffer of a lineConn. Messages
+// that do not fit are written directly.
+const writeBufferSize = 64 * 1024
+
+// lineConn frames messages as newline-delimited JSON. Writes go through a
+// buffer that is flushed once no other write is waiting, so a burst of
+// messages from concurrent writers or WriteMessages reaches w in as few
+// writes as the buffer allows.
+type lineConn struct {
+	scanner *bufio.Scanner
+	closer  io.Closer
+
+	wmu     sync.Mutex // serializes writes to w
+	w       *bufio.Writer
+	waiting atomic.Int32 // writers holding or waiting for wmu
+
+	closeOnce sync.Once
+}
+
+var _ batchConn = (*lineConn)(nil)
+
+// NewLineConn creates a Conn reading newline-delimited messages from r and
+// writing them to w. Close closes c when it is not nil.
+func NewLineConn(r io.Reader, w io.Writer, c io.Closer) Conn {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	return &lineConn{scanner: scanner, w: bufio.NewWriterSize(w, writeBufferSize), closer: c}
+}
+
+func (c *lineConn) ReadMessage() ([]byte, error) {
+	for c.scanner.Scan() {
+		line := bytes.TrimSpace(c.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		// The scanner reuses its buffer
+		return append([]byte(nil), line...), nil
+	}
+	if err := c.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (c *lineConn) WriteMessage(message []byte) error {
+	return c.WriteMessages([][]byte{message})
+}
+
+func (c *lineConn) WriteMessages(messages [][]byte) error {
+	c.waiting.Add(1)
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	var err error
+	for _, message := range messages {
+		if _, err = c.w.Write(message); err != nil {
+			break
+		}
+		if err = c.w.WriteByte('\n'); err != nil {
+			break
+		}
+	}
+
+	// The last writer in line flushes for the others queued behind this one
+	if c.waiting.Add(-1) == 0 && err == nil {
+		err = c.w.Flush()
+	}
+	return err
+}
+
+func (c *lineConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.closer != nil {
+			err = c.closer.Close()
+		}
+	})
+	return err
+}
+
+// recorder keeps the messages a connection carries, e.g. a
+// connection.History or a router.WireConn
+type recorder interface {
+	Record(direction connection.Direction, message []byte)
+}
+
+// recordConn returns conn recording the messages it carries in r.
+// WriteMessages batching is preserved when conn supports it.
+func recordConn(conn Conn, r recorder) Conn {
+	recording := &recordingConn{Conn: conn, recorder: r}
+	if batcher, ok := conn.(batchConn); ok {
+		return &recordingBatchConn{recordingConn: recording, batcher: batcher}
+	}
+	return recording
+}
+
+// recordingConn is a Conn recording its messages
+type recordingConn struct {
+	Conn
+	recorder recorder
+}
+
+func (c *recordingConn) ReadMessage() ([]byte, error) {
+	message, err := c.Conn.ReadMessage()
+	if err == nil {
+		c.recorder.Record(connection.Inbound, message)
+	}
+	return message, err
+}
+
+func (c *recordingConn) WriteMessage(message []byte) error {
+	c.recorder.Record(connection.Outbound, message)
+	return c.Conn.WriteMessage(message)
+}
+
+// recordingBatchConn is a recordingConn over a batchConn
+type recordingBatchConn struct {
+	*recordingConn
+	batcher batchConn
+}
+
+func (c *recordingBatchConn) WriteMessages(messages [][]byte) error {
+	for _, message := range messages {
+		c.recorder.Record(connection.Outbound, message)
+	}
+	return c.batcher.WriteMessages(messages)
+}
+
+// stampConn stamps the responses and notifications written to a Conn with
+// their sequence number and checksum (see wirecheck). Writes are
+// serialized so the numbers follow the order of the messages on the wire.
+type stampConn struct {
+	Conn
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+func (c *stampConn) WriteMessage(message []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Conn.WriteMessage(c.stamp(message))
+}
+
+// WriteMessages writes messages with one write when the Conn supports it
+func (c *stampConn) WriteMessages(messages [][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stamped := make([][]byte, len(messages))
+	for i, message := range messages {
+		stamped[i] = c.stamp(message)
+	}
+	if batcher, ok := c.Conn.(batchConn); ok {
+		return batcher.WriteMessages(stamped)
+	}
+	for _, message := range stamped {
+		if err := c.Conn.WriteMessage(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stamp returns message stamped with the next sequence number; requests
+// and messages that cannot be stamped are returned unchanged
+func (c *stampConn) stamp(message []byte) []byte {
+	if !wirecheck.Stamped(message) {
+		return message
+	}
+	stamped, err := wirecheck.StampMessage(message, c.seq+1)
+	if err != nil {
+		return message
+	}
+	c.seq++
+	return stamped
+}
+
+// throttleConn makes writes to a Conn wait for the bandwidth of its
+// connection and of the server (see throttle). Closing it cancels writes
+// waiting their turn.
+type throttleConn struct {
+	Conn
+	limiter *throttle.Connection
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// throttleWrites returns conn limited by t, or conn when t does not limit
+// anything
+func throttleWrites(conn Conn, t *throttle.Throttle) Conn {
+	if !t.Enabled() {
+		return conn
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &throttleConn{Conn: conn, limiter: t.Connection(), ctx: ctx, cancel: cancel}
+}
+
+func (c *throttleConn) WriteMessage(message []byte) error {
+	if err := c.wait(len(message)); err != nil {
+		return err
+	}
+	return c.Conn.WriteMessage(message)
+}
+
+// WriteMessages writes messages with one write when the Conn supports it
+func (c *throttleConn) WriteMessages(messages [][]byte) error {
+	size := 0
+	for _, message := range messages {
+		size += len(message)
+	}
+	if err := c.wait(size); err != nil {
+		return err
+	}
+	if batcher, ok := c.Conn.(batchConn); ok {
+		return batcher.WriteMessages(messages)
+	}
+	for _, message := range messages {
+		if err := c.Conn.WriteMessage(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *throttleConn) Close() error {
+	c.cancel()
+	return c.Conn.Close()
+}
+
+// wait blocks until n bytes may be written, failing like a closed
+// connection once the connection is closed
+func (c *throttleConn) wait(n int) error {
+	if err := c.limiter.Wait(c.ctx, n); err != nil {
+		return net.ErrClosed
+	}
+	return nil
+}
+
+// errStalled fails writes to a client that stopped reading. It wraps
+// net.ErrClosed since the connection is closed by then.
+var errStalled = fmt.Errorf("client stopped reading: %w", net.ErrClosed)
+
+// stallConn closes a Conn once a write has not completed within timeout,
+// so a client that stops reading cannot block the server's writers for
+// good. Closing unblocks writes in progress on sockets and WebSockets;
+// on streams without deadline support only later writes fail.
+type stallConn struct {
+	Conn
+	timeout time.Duration
+
+	stalled chan struct{} // closed on the first stall
+	once    sync.Once
+}
+
+// watchWrites returns conn closed when a write stalls for timeout
+func watchWrites(conn Conn, timeout time.Duration) *stallConn {
+	return &stallConn{Conn: conn, timeout: timeout, stalled: make(chan struct{})}
+}
+
+// Stalled returns a channel closed once a write stalled and the connection
+// was closed
+func (c *stallConn) Stalled() <-chan struct{} {
+	return c.stalled
+}
+
+func (c *stallConn) WriteMessage(message []byte) error {
+	return c.bounded(func() error { return c.Conn.WriteMessage(message) })
+}
+
+// WriteMessages writes messages with one write when the Conn supports it
+func (c *stallConn) WriteMessages(messages [][]byte) error {
+	return c.bounded(func() error {
+		if batcher, ok := c.Conn.(batchConn); ok {
+			return batcher.WriteMessages(messages)
+		}
+		for _, message := range messages {
+			if err := c.Conn.WriteMessage(message); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// bounded runs write, closing the connection if it takes longer than the
+// timeout
+func (c *stallConn) bounded(write func() error) error {
+	select {
+	case <-c.stalled:
+		return errStalled
+	default:
+	}
+	timer := time.AfterFunc(c.timeout, c.stall)
+	err := write()
+	if !timer.Stop() {
+		return errStalled
+	}
+	return err
+}
+
+// stall closes the connection once
+func (c *stallConn) stall() {
+	c.once.Do(func() {
+		close(c.stalled)
+		c.Conn.Close()
+	})
+}
+
+// isClosed reports whether err means the peer or the server closed the
+// connection
+func isClosed(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, os.ErrClosed) || errors.Is(err, io.ErrClosedPipe)
+}
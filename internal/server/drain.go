@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// DrainEvent marks the notifications/message telling a client that its
+// connection is about to be closed
+const DrainEvent = "server/draining"
+
+// drainLogger names the source of drain notices
+const drainLogger = "meta-mcp-server"
+
+// DrainNotice is the data of the notifications/message sent to clients
+// before their connections are closed, so they can finish their work or
+// move to another server:
+//
+//	{"jsonrpc": "2.0", "method": "notifications/message", "params": {
+//		"level": "warning",
+//		"logger": "meta-mcp-server",
+//		"data": {"event": "server/draining", "reason": "shutdown",
+//			"graceMs": 10000, "deadline": "2026-01-02T03:04:15Z"}
+//	}}
+type DrainNotice struct {
+	Event  string `json:"event"`
+	Reason string `json:"reason"`
+
+	// GraceMs is how long the server waits for the client to disconnect
+	GraceMs int64 `json:"graceMs"`
+
+	// Deadline is when the server closes the connection
+	Deadline time.Time `json:"deadline"`
+}
+
+// notification returns the notifications/message carrying n
+func (n DrainNotice) notification() mcpgo.JSONRPCNotification {
+	return mcpgo.JSONRPCNotification{
+		JSONRPC: mcpgo.JSONRPC_VERSION,
+		Notification: mcpgo.Notification{
+			Method: mcp.MethodNotificationMessage,
+			Params: mcpgo.NotificationParams{AdditionalFields: map[string]any{
+				"level":  mcpgo.LoggingLevelWarning,
+				"logger": drainLogger,
+				"data":   n,
+			}},
+		},
+	}
+}
+
+// sessionSet tracks the sessions served by ServeConn
+type sessionSet struct {
+	mu   sync.Mutex
+	byID map[string]*session
+
+	// left is closed and replaced when a session leaves
+	left chan struct{}
+
+	// notice is sent to sessions joining while the server drains
+	notice *DrainNotice
+}
+
+// add tracks s, returning the drain notice it must be sent, if any
+func (set *sessionSet) add(s *session) *DrainNotice {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.byID == nil {
+		set.byID = make(map[string]*session)
+		set.left = make(chan struct{})
+	}
+	set.byID[s.id] = s
+	return set.notice
+}
+
+// remove stops tracking s
+func (set *sessionSet) remove(s *session) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	delete(set.byID, s.id)
+	close(set.left)
+	set.left = make(chan struct{})
+}
+
+// drain records notice for joining sessions and returns the current ones
+func (set *sessionSet) drain(notice DrainNotice) []*session {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	set.notice = &notice
+	sessions := make([]*session, 0, len(set.byID))
+	for _, s := range set.byID {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// remaining returns the number of sessions and a channel closed when one
+// leaves
+func (set *sessionSet) remaining() (int, <-chan struct{}) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	return len(set.byID), set.left
+}
+
+// Drain tells every client served by ServeConn that its connection will be
+// closed for reason, with a notifications/message carrying a DrainNotice,
+// and waits until they all disconnected, DrainGrace expired or ctx is
+// done. Clients connecting meanwhile get the notice too. It returns the
+// number of clients still connected; Shutdown closes their connections.
+func (s *Server) Drain(ctx context.Context, reason string) int {
+	grace := s.config.DrainGrace
+	timer := s.config.Clock.NewTimer(grace)
+	defer timer.Stop()
+	notice := DrainNotice{
+		Event:    DrainEvent,
+		Reason:   reason,
+		GraceMs:  grace.Milliseconds(),
+		Deadline: s.config.Clock.Now().Add(grace),
+	}
+	logger := logging.Default().WithComponent("server")
+
+	// The notice is written before any connection closes, even without a
+	// grace period. Clients not reading it are not waited for past the
+	// grace period; without one, their writes time out after WriteTimeout.
+	sessions := s.sessions.drain(notice)
+	logger.WithFields(logging.LogFields{"reason": reason, "grace": grace, "clients": len(sessions)}).
+		Info(ctx, "Draining client connections")
+	var sent sync.WaitGroup
+	for _, session := range sessions {
+		sent.Add(1)
+		go func() {
+			defer sent.Done()
+			session.write(notice.notification())
+		}()
+	}
+	written := make(chan struct{})
+	go func() {
+		sent.Wait()
+		close(written)
+	}()
+	var expired <-chan time.Time
+	if grace > 0 {
+		expired = timer.C()
+	}
+	select {
+	case <-written:
+	case <-expired:
+		remaining, _ := s.sessions.remaining()
+		logger.WithField("clients", remaining).Warn(ctx, "Drain grace period expired while telling clients; closing remaining connections")
+		return remaining
+	case <-ctx.Done():
+		return len(sessions)
+	}
+
+	for {
+		remaining, left := s.sessions.remaining()
+		if remaining == 0 {
+			return 0
+		}
+		select {
+		case <-left:
+		case <-timer.C():
+			if grace > 0 {
+				logger.WithField("clients", remaining).Warn(ctx, "Drain grace period expired; closing remaining connections")
+			}
+			return remaining
+		case <-ctx.Done():
+			return remaining
+		}
+	}
+}
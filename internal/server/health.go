@@ -0,0 +1,46 @@
+package server
+
+import (
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/upstream"
+)
+
+// HealthCheck reports, by name, whether each transport is serving, with the
+// error a stopped transport failed with. Transports are down before Start
+// and after the server stopped.
+func (s *Server) HealthCheck() map[string]transport.HealthStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make(map[string]transport.HealthStatus, len(s.config.Transports))
+	for _, t := range s.config.Transports {
+		status, ok := s.serving[t.Name()]
+		if !ok {
+			status = transport.HealthStatus{ID: t.Name()}
+		}
+		statuses[t.Name()] = status
+	}
+	return statuses
+}
+
+// UpstreamHealth reports, by name, whether each upstream is connected
+func (s *Server) UpstreamHealth() map[string]bool {
+	if s.upstreams == nil {
+		return nil
+	}
+	statuses := s.upstreams.Status()
+	healthy := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		healthy[status.Name] = status.State == upstream.StateConnected
+	}
+	return healthy
+}
+
+// setServing records whether the transport name is serving
+func (s *Server) setServing(name string, serving bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.serving == nil {
+		s.serving = make(map[string]transport.HealthStatus)
+	}
+	s.serving[name] = transport.HealthStatus{ID: name, Connected: serving, LastError: err}
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/websocket"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/tracing"
+)
+
+// HTTPConfig contains configuration for the HTTP-based transports
+type HTTPConfig struct {
+	// Addr is the listen address, e.g. ":8080"
+	Addr string
+
+	// Path is the WebSocket endpoint (defaults to "/ws"), or the base path
+	// of the SSE endpoints "<Path>/sse" and "<Path>/message"
+	Path string
+
+	// CORS restricts browser origins (defaults to DefaultCORSConfig)
+	CORS *transport.CORSConfig
+
+	// TLS enables HTTPS when set, e.g. from CertManager.TLSConfig
+	TLS *tls.Config
+
+	// ShutdownTimeout bounds graceful shutdown (defaults to 5s)
+	ShutdownTimeout time.Duration
+
+	// Tokens maps the bearer tokens clients may present in the
+	// Authorization header to the identity they authenticate. When set,
+	// requests without one of them are refused; when empty, clients are
+	// served without an identity.
+	Tokens map[string]string
+}
+
+// withDefaults fills unset fields
+func (c HTTPConfig) withDefaults(path string) HTTPConfig {
+	if c.Path == "" {
+		c.Path = path
+	}
+	if c.CORS == nil {
+		cors := transport.DefaultCORSConfig()
+		c.CORS = &cors
+	}
+	if c.ShutdownTimeout <= 0 {
+		c.ShutdownTimeout = 5 * time.Second
+	}
+	return c
+}
+
+// authenticate returns the identity of the bearer token of r. It reports
+// false when tokens are configured and r presents none of them.
+func (c HTTPConfig) authenticate(r *http.Request) (string, bool) {
+	if len(c.Tokens) == 0 {
+		return "", true
+	}
+	presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+	identity, found := "", false
+	// Compare with every token so the time taken does not tell which
+	// token was close
+	for token, id := range c.Tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			identity, found = id, true
+		}
+	}
+	return identity, found
+}
+
+// withIdentity adds the identity r authenticated as to ctx
+func (c HTTPConfig) withIdentity(ctx context.Context, r *http.Request) context.Context {
+	if identity, ok := c.authenticate(r); ok && identity != "" {
+		return connection.WithIdentity(ctx, identity)
+	}
+	return ctx
+}
+
+// requireToken refuses requests without a configured bearer token
+func requireToken(config HTTPConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := config.authenticate(r); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpListener opens the listener for a transport, recording its address
+type httpListener struct {
+	config HTTPConfig
+
+	mu    sync.Mutex
+	addr  net.Addr
+	ready chan struct{}
+}
+
+// Addr returns the listening address once the transport is serving
+func (l *httpListener) Addr() net.Addr {
+	<-l.ready
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.addr
+}
+
+// serve runs srv until ctx is done, then calls shutdown
+func (l *httpListener) serve(ctx context.Context, srv *http.Server, shutdown func(context.Context) error) error {
+	listener, err := net.Listen("tcp", l.config.Addr)
+	if err == nil {
+		l.mu.Lock()
+		l.addr = listener.Addr()
+		l.mu.Unlock()
+	}
+	close(l.ready)
+	if err != nil {
+		return err
+	}
+	if l.config.TLS != nil {
+		listener = tls.NewListener(listener, l.config.TLS)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(listener) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.config.ShutdownTimeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		srv.Close()
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// sseTransport serves clients over Server-Sent Events
+type sseTransport struct {
+	httpListener
+}
+
+// NewSSE returns a transport serving clients over Server-Sent Events
+func NewSSE(config HTTPConfig) Transport {
+	return &sseTransport{httpListener{config: config.withDefaults(""), ready: make(chan struct{})}}
+}
+
+func (t *sseTransport) Name() string { return "sse" }
+
+func (t *sseTransport) Serve(ctx context.Context, s *Server) error {
+	srv := &http.Server{ReadHeaderTimeout: 10 * time.Second}
+	sse := mcpserver.NewSSEServer(s.mcp.MCPServer,
+		mcpserver.WithHTTPServer(srv),
+		mcpserver.WithStaticBasePath(t.config.Path),
+		mcpserver.WithKeepAlive(true),
+		mcpserver.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+			return t.config.withIdentity(s.mcp.SessionContext(ctx), r)
+		}),
+	)
+	srv.Handler = transport.NewOriginValidator(*t.config.CORS).Middleware(
+		tracing.HTTPMiddleware(requireToken(t.config, writeDeadlines(sse, s.config.WriteTimeout))))
+	return t.serve(ctx, srv, sse.Shutdown)
+}
+
+// writeDeadlines bounds each write of an event stream to timeout, so a
+// client that stops reading fails the stream instead of blocking the
+// server's writes to it. Other requests are passed through.
+func writeDeadlines(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&deadlineWriter{ResponseWriter: w, rc: http.NewResponseController(w), timeout: timeout}, r)
+	})
+}
+
+// deadlineWriter sets a write deadline before every write and flush
+type deadlineWriter struct {
+	http.ResponseWriter
+	rc      *http.ResponseController
+	timeout time.Duration
+}
+
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	w.rc.SetWriteDeadline(time.Now().Add(w.timeout))
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *deadlineWriter) Flush() {
+	w.rc.SetWriteDeadline(time.Now().Add(w.timeout))
+	w.rc.Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// webSocketTransport serves clients over WebSocket, one JSON-RPC message
+// per text frame
+type webSocketTransport struct {
+	httpListener
+}
+
+// NewWebSocket returns a transport serving clients over WebSocket
+func NewWebSocket(config HTTPConfig) Transport {
+	return &webSocketTransport{httpListener{config: config.withDefaults("/ws"), ready: make(chan struct{})}}
+}
+
+func (t *webSocketTransport) Name() string { return "websocket" }
+
+func (t *webSocketTransport) Serve(ctx context.Context, s *Server) error {
+	validator := transport.NewOriginValidator(*t.config.CORS)
+	var clients sync.WaitGroup
+	handler := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			if !validator.CheckOrigin(r) {
+				return fmt.Errorf("origin %q not allowed", r.Header.Get("Origin"))
+			}
+			if _, ok := t.config.authenticate(r); !ok {
+				return errors.New("missing or unknown bearer token")
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			clients.Add(1)
+			defer clients.Done()
+			// Requests continue the trace of the upgrade request unless
+			// they carry their own
+			ctx := trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(ws.Request().Context()))
+			s.ServeConn(t.config.withIdentity(ctx, ws.Request()), t.Name(), &wsConn{ws: ws})
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(t.config.Path, tracing.HTTPMiddleware(handler))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	// Hijacked connections outlive srv.Shutdown; ServeConn closes them
+	// once ctx is done
+	err := t.serve(ctx, srv, srv.Shutdown)
+	clients.Wait()
+	return err
+}
+
+// wsConn adapts a WebSocket connection to Conn
+type wsConn struct {
+	ws *websocket.Conn
+}
+
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	err := websocket.Message.Receive(c.ws, &message)
+	return message, err
+}
+
+func (c *wsConn) WriteMessage(message []byte) error {
+	return websocket.Message.Send(c.ws, string(message))
+}
+
+func (c *wsConn) Close() error {
+	return c.ws.Close()
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+)
+
+// ReloadFunc re-reads configuration and applies it to a running server
+type ReloadFunc func(ctx context.Context) error
+
+var (
+	// ErrStarted is returned by Start when the server was started before
+	ErrStarted = errors.New("server already started")
+
+	// ErrReloadUnsupported is returned by Reload for a server built without
+	// WithReload
+	ErrReloadUnsupported = errors.New("reload not supported")
+)
+
+// lifecycle tracks a server started by Start
+type lifecycle struct {
+	started bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+	err     error
+}
+
+// Start connects the upstreams and serves every transport in the
+// background until Shutdown is called, ctx is done or a transport stops.
+// Upstreams that fail to connect are logged; the server starts without
+// their tools.
+func (s *Server) Start(ctx context.Context) error {
+	if len(s.config.Transports) == 0 {
+		return errors.New("no transports configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lifecycle.started {
+		return ErrStarted
+	}
+	if s.async != nil {
+		if err := s.async.Start(); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.lifecycle = lifecycle{started: true, cancel: cancel, done: make(chan struct{})}
+
+	if s.upstreams != nil {
+		if err := s.upstreams.Start(ctx); err != nil {
+			logging.Default().WithComponent("server").Error(ctx, err, "Some upstreams failed to connect")
+		}
+	}
+
+	done := s.lifecycle.done
+	go func() {
+		err := s.Serve(ctx)
+		s.mu.Lock()
+		s.lifecycle.err = err
+		s.mu.Unlock()
+		close(done)
+	}()
+	return nil
+}
+
+// Done returns a channel closed once a started server stopped serving, or
+// nil before Start
+func (s *Server) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lifecycle.done
+}
+
+// Wait blocks until a started server stopped serving and returns the errors
+// of failed transports
+func (s *Server) Wait() error {
+	done := s.Done()
+	if done == nil {
+		return nil
+	}
+	<-done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lifecycle.err
+}
+
+// Shutdown tells the connected clients the server is going away, waiting
+// up to DrainGrace for them to disconnect (see Drain), then stops serving
+// and the async router's workers and disconnects the upstreams. It returns
+// the errors of failed transports, or ctx's error if they did not stop in
+// time.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.lifecycle.cancel, s.lifecycle.done
+	s.mu.Unlock()
+
+	var errs []error
+	if cancel != nil {
+		select {
+		case <-done:
+		default:
+			s.Drain(ctx, "shutdown")
+		}
+		cancel()
+		select {
+		case <-done:
+			s.mu.Lock()
+			errs = append(errs, s.lifecycle.err)
+			s.mu.Unlock()
+		case <-ctx.Done():
+			errs = append(errs, ctx.Err())
+		}
+	}
+	if s.async != nil {
+		errs = append(errs, s.async.Shutdown(ctx))
+	}
+	if s.upstreams != nil {
+		errs = append(errs, s.upstreams.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Reload re-reads configuration with the function set by WithReload
+func (s *Server) Reload(ctx context.Context) error {
+	if s.reload == nil {
+		return ErrReloadUnsupported
+	}
+	return s.reload(ctx)
+}
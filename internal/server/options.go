@@ -0,0 +1,189 @@
+package server
+
+import (
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+	"github.com/meta-mcp/meta-mcp-server/internal/tracing"
+	"github.com/meta-mcp/meta-mcp-server/internal/upstream"
+)
+
+// Option configures a Server built by NewServer
+type Option func(*options)
+
+// options collects the Options of NewServer
+type options struct {
+	handshake mcp.HandshakeConfig
+	hooks     []func(*mcpserver.Hooks)
+	config    Config
+	router    *router.Router
+	async     *router.AsyncRouterConfig
+	tools     tools.Config
+	upstreams *upstream.Config
+	reload    ReloadFunc
+}
+
+// WithHandshake configures the handshake server: name, version, protocol
+// versions and mcp-go options (defaults to mcp.DefaultHandshakeConfig())
+func WithHandshake(config mcp.HandshakeConfig) Option {
+	return func(o *options) {
+		o.handshake = config
+	}
+}
+
+// WithHooks registers mcp-go hooks (metrics, auditing, ...) alongside the
+// handshake hooks
+func WithHooks(configure ...func(hooks *mcpserver.Hooks)) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, configure...)
+	}
+}
+
+// WithTransports adds transports to serve
+func WithTransports(transports ...Transport) Option {
+	return func(o *options) {
+		o.config.Transports = append(o.config.Transports, transports...)
+	}
+}
+
+// WithConfig sets how clients are served. Its transports are added to
+// those of WithTransports; VisibleTools defaults to the tool registry.
+func WithConfig(config Config) Option {
+	return func(o *options) {
+		transports := o.config.Transports
+		o.config = config
+		o.config.Transports = append(transports, config.Transports...)
+	}
+}
+
+// WithRouter serves the JSON-RPC methods of r, e.g. the admin/* namespace,
+// beside MCP. Messages for methods r has no handler for go to the handshake
+// server.
+func WithRouter(r *router.Router) Option {
+	return func(o *options) {
+		o.router = r
+	}
+}
+
+// WithAsync handles the requests of the router set by WithRouter on a pool
+// of workers fed by a bounded queue, configured by config. Its Router is
+// the server's router; the server starts the workers in Start and stops
+// them in Shutdown.
+func WithAsync(config router.AsyncRouterConfig) Option {
+	return func(o *options) {
+		o.async = &config
+	}
+}
+
+// WithTools configures the tool registry; its Server is the handshake
+// server
+func WithTools(config tools.Config) Option {
+	return func(o *options) {
+		o.tools = config
+	}
+}
+
+// WithUpstreams connects the upstreams of config when the server starts
+// and publishes their tools in the registry
+func WithUpstreams(config upstream.Config) Option {
+	return func(o *options) {
+		o.upstreams = &config
+	}
+}
+
+// WithReload sets what Reload does
+func WithReload(reload ReloadFunc) Option {
+	return func(o *options) {
+		o.reload = reload
+	}
+}
+
+// NewServer creates a server owning the handshake server with its
+// connection manager and hooks, the tool registry, the upstreams and the
+// router, configured by opts. Register tools, resources and prompts, then
+// Start it.
+func NewServer(opts ...Option) *Server {
+	o := options{handshake: mcp.DefaultHandshakeConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	handshake := o.handshake
+	handshake.ConfigureHooks = append(append([]func(*mcpserver.Hooks){}, handshake.ConfigureHooks...), o.hooks...)
+	hs := mcp.NewHandshakeServer(handshake)
+
+	toolConfig := o.tools
+	toolConfig.Server = hs
+	registry := tools.New(toolConfig)
+
+	config := o.config
+	if config.VisibleTools == nil {
+		config.VisibleTools = registry.Visible
+	}
+	s := New(hs, config)
+	s.router = o.router
+	if o.router != nil {
+		// Router requests are traced as children of the caller's span;
+		// those queued for a worker start a trace linked to it
+		s.routed = o.router
+		if o.async != nil {
+			asyncConfig := *o.async
+			asyncConfig.Router = o.router
+			asyncConfig.Middleware = append([]router.Middleware{tracing.AsyncMiddleware()}, asyncConfig.Middleware...)
+			s.async = router.NewAsyncRouter(asyncConfig)
+			s.routed = s.async
+		}
+		s.chain = router.NewChain(tracing.Middleware())
+		s.dispatch = s.chain.Then(s.routed)
+	}
+	s.tools = registry
+	s.reload = o.reload
+	if o.upstreams != nil {
+		upstreamConfig := *o.upstreams
+		upstreamConfig.Registry = registry
+		if upstreamConfig.Broadcast == nil {
+			upstreamConfig.Broadcast = hs.SendNotificationToAllClients
+		}
+		s.upstreams = upstream.New(upstreamConfig)
+	}
+	return s
+}
+
+// Connections returns the connection manager of the handshake server
+func (s *Server) Connections() *connection.Manager {
+	return s.mcp.GetConnectionManager()
+}
+
+// Router returns the router set by WithRouter, or nil
+func (s *Server) Router() *router.Router {
+	return s.router
+}
+
+// Use wraps the requests of the router set by WithRouter in middleware,
+// e.g. admin.API.DrainMiddleware, inside the tracing middleware. Call it
+// before Start.
+func (s *Server) Use(middleware ...router.Middleware) {
+	if s.router == nil {
+		return
+	}
+	s.chain = s.chain.Append(middleware...)
+	s.dispatch = s.chain.Then(s.routed)
+}
+
+// Async returns the async router set up by WithAsync, or nil
+func (s *Server) Async() *router.AsyncRouter {
+	return s.async
+}
+
+// Tools returns the tool registry of a server built by NewServer, or nil
+func (s *Server) Tools() *tools.Registry {
+	return s.tools
+}
+
+// Upstreams returns the upstream manager set up by WithUpstreams, or nil
+func (s *Server) Upstreams() *upstream.Manager {
+	return s.upstreams
+}
@@ -0,0 +1,1043 @@
+// Package server serves one MCP server over several transports at once,
+// for example stdio for the parent client plus a Unix socket, SSE and
+// WebSocket for additional clients. All transports share the handshake
+// server and with it the router, connection manager and tool set.
+//
+// Basic usage:
+//
+//	err := server.Serve(ctx, hs,
+//		server.Stdio(),
+//		server.NewSocket("unix", "/run/meta-mcp.sock"),
+//		server.NewSSE(server.HTTPConfig{Addr: ":8080"}),
+//	)
+//
+// Serve returns when ctx is done or any transport stops; the stdio
+// transport stops when the parent closes stdin.
+//
+// NewServer builds a server that also owns the handshake server, the tool
+// registry, the upstreams and a router for methods outside MCP, and runs
+// in the background between Start and Shutdown:
+//
+//	srv := server.NewServer(
+//		server.WithHandshake(handshakeConfig),
+//		server.WithTransports(server.Stdio()),
+//		server.WithReload(reload),
+//	)
+//	srv.Tools().MustRegister(definition)
+//	if err := srv.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer srv.Shutdown(context.Background())
+//	return srv.Wait()
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/buildinfo"
+	"github.com/meta-mcp/meta-mcp-server/internal/chaos"
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/delivery"
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/memguard"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/compat"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/throttle"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+	"github.com/meta-mcp/meta-mcp-server/internal/truncate"
+	"github.com/meta-mcp/meta-mcp-server/internal/upstream"
+)
+
+// Transport accepts clients and serves them through a Server
+type Transport interface {
+	// Name identifies the transport in logs and session IDs
+	Name() string
+
+	// Serve runs until ctx is done or the transport fails. It returns nil
+	// when it stopped because of ctx or because its only client left.
+	Serve(ctx context.Context, s *Server) error
+}
+
+// Config contains configuration for a Server
+type Config struct {
+	// Transports are served concurrently
+	Transports []Transport
+
+	// ShutdownTimeout bounds how long transports may take to stop once
+	// one of them has (defaults to 5s)
+	ShutdownTimeout time.Duration
+
+	// Guard sheds messages when in-flight bytes approach its limit
+	// (defaults to memguard.Default())
+	Guard *memguard.Guard
+
+	// Faults are injected into every client connection; for resilience
+	// testing only
+	Faults chaos.Faults
+
+	// OrderedNotifications numbers the notifications of each connection in
+	// _meta.seq and writes every response after the notifications queued
+	// before it, so a client can rely on their order, e.g. progress before
+	// the result of a tool call
+	OrderedNotifications bool
+
+	// MaxRequestTimeout caps the deadline a client may request for a
+	// request in _meta.timeoutMs (0 means no cap)
+	MaxRequestTimeout time.Duration
+
+	// VisibleTools returns the tools the connection of ctx may see, as
+	// tools/list reports them. When set, notifications/tools/list_changed
+	// only reaches connections whose visible tools differ from those they
+	// were last told about, so a reload touching many tools, or tools some
+	// connections cannot see, does not send every client to tools/list.
+	VisibleTools func(ctx context.Context) []mcpgo.Tool
+
+	// Events receives connections opening and closing (defaults to
+	// events.Default())
+	Events *events.Bus
+
+	// Shims adapt messages for legacy clients, selected by the protocol
+	// version and client name of each connection once its handshake
+	// completes, e.g. compat.Builtin
+	Shims compat.Table
+
+	// WriteTimeout bounds each write to a client. A client that does not
+	// accept a message for this long is considered stalled: its connection
+	// is marked unhealthy and closed (defaults to 30s)
+	WriteTimeout time.Duration
+
+	// WireChecks stamps every response and notification with a sequence
+	// number and checksum in _meta.wire, for clients to detect corrupted
+	// and dropped messages; for debugging only
+	WireChecks bool
+
+	// History keeps the last messages of each connection, redacted, for
+	// admin/history and for the log when the connection fails (0 disables
+	// it)
+	History int
+
+	// WireLog records sampled requests and their responses, redacted, for
+	// offline analysis and replay (nil disables it)
+	WireLog *router.WireLogger
+
+	// Delivery keeps critical notifications to clients that opted in with
+	// _meta.resumeToken until they acknowledge them, and sends them again
+	// when the client reconnects (nil disables it)
+	Delivery *delivery.Outbox
+
+	// Responses truncates tool and resource results above its size limit
+	// and serves their remainders at continuation URIs (nil disables it)
+	Responses *truncate.Limiter
+
+	// Negotiator converts resources/read results to the representation
+	// the client asks for in _meta.accept and _meta.encoding (nil disables
+	// it)
+	Negotiator *resources.Negotiator
+
+	// Throttle caps the bytes per second written to each connection and
+	// to all of them, so one client reading huge results cannot starve the
+	// others (nil disables it)
+	Throttle *throttle.Throttle
+
+	// BuildInfo is added to the serverInfo of initialize results as
+	// serverInfo.build (nil leaves serverInfo as it is)
+	BuildInfo *buildinfo.Info
+
+	// DrainGrace is how long Shutdown waits for clients to disconnect
+	// after telling them the server is going away, before closing their
+	// connections (0 closes them once they were told)
+	DrainGrace time.Duration
+
+	// Clock times the drain grace period (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// Server runs a handshake server on several transports
+type Server struct {
+	mcp      *mcp.HandshakeServer
+	config   Config
+	active   atomic.Int64
+	sessions sessionSet
+
+	// Owned by a server built by NewServer; nil otherwise
+	router    *router.Router
+	async     *router.AsyncRouter
+	routed    router.Handler
+	chain     *router.Chain
+	dispatch  router.Handler
+	tools     *tools.Registry
+	upstreams *upstream.Manager
+	reload    ReloadFunc
+
+	mu        sync.Mutex
+	lifecycle lifecycle
+	serving   map[string]transport.HealthStatus
+}
+
+// New creates a server for hs
+func New(hs *mcp.HandshakeServer, config Config) *Server {
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = 5 * time.Second
+	}
+	if config.Guard == nil {
+		config.Guard = memguard.Default()
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 30 * time.Second
+	}
+	config.Events = events.Or(config.Events)
+	config.Clock = clock.Or(config.Clock)
+	return &Server{mcp: hs, config: config}
+}
+
+// Serve runs hs on transports until ctx is done or any transport stops
+func Serve(ctx context.Context, hs *mcp.HandshakeServer, transports ...Transport) error {
+	return New(hs, Config{Transports: transports}).Serve(ctx)
+}
+
+// MCP returns the shared handshake server
+func (s *Server) MCP() *mcp.HandshakeServer {
+	return s.mcp
+}
+
+// ActiveSessions returns the number of sessions served by ServeConn
+func (s *Server) ActiveSessions() int {
+	return int(s.active.Load())
+}
+
+// publish announces a connection opening or closing on the event bus
+func (s *Server) publish(id, transport string, eventType events.ConnectionEventType) {
+	events.Publish(s.config.Events, events.Connections, events.ConnectionEvent{
+		ID:        id,
+		Transport: transport,
+		Type:      eventType,
+		At:        time.Now(),
+	})
+}
+
+// Serve runs every transport until ctx is done or any of them stops, then
+// stops the others. It returns the errors of failed transports.
+func (s *Server) Serve(ctx context.Context) error {
+	if len(s.config.Transports) == 0 {
+		return errors.New("no transports configured")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	logger := logging.Default().WithComponent("server")
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(s.config.Transports))
+	for _, t := range s.config.Transports {
+		s.setServing(t.Name(), true, nil)
+		go func(t Transport) {
+			logger.WithField("transport", t.Name()).Info(ctx, "Serving transport")
+			err := t.Serve(ctx, s)
+			s.setServing(t.Name(), false, err)
+			results <- result{t.Name(), err}
+		}(t)
+	}
+
+	var errs []error
+	collect := func(r result) {
+		if r.err != nil {
+			logger.WithField("transport", r.name).Error(ctx, r.err, "Transport failed")
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+		} else {
+			logger.WithField("transport", r.name).Info(ctx, "Transport stopped")
+		}
+	}
+
+	var first result
+	select {
+	case first = <-results:
+	case <-ctx.Done():
+		first = <-results
+	}
+	collect(first)
+	cancel()
+
+	timeout := time.NewTimer(s.config.ShutdownTimeout)
+	defer timeout.Stop()
+	for remaining := len(s.config.Transports) - 1; remaining > 0; remaining-- {
+		select {
+		case r := <-results:
+			collect(r)
+		case <-timeout.C:
+			errs = append(errs, fmt.Errorf("%d transports did not stop within %s", remaining, s.config.ShutdownTimeout))
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ServeConn serves one client over conn until the client disconnects or
+// ctx is done. The client gets its own session and handshake state.
+func (s *Server) ServeConn(ctx context.Context, transport string, conn Conn) error {
+	stalls := watchWrites(conn, s.config.WriteTimeout)
+	conn = chaos.WrapConn(stalls, s.config.Faults)
+	conn = throttleWrites(conn, s.config.Throttle)
+	var history *connection.History
+	if s.config.History > 0 {
+		history = connection.NewHistory(s.config.History, nil)
+		conn = recordConn(conn, history)
+	}
+	if s.config.WireChecks {
+		conn = &stampConn{Conn: conn}
+	}
+	id := transport + "-" + uuid.NewString()
+	if s.config.WireLog != nil {
+		// Outside stampConn, so records replay without _meta.wire
+		conn = recordConn(conn, s.config.WireLog.Conn(id))
+	}
+	session := newSession(id, conn)
+	session.ordered = s.config.OrderedNotifications
+	session.shims = s.config.Shims
+	session.outbox = s.config.Delivery
+	base := s.mcp.MCPServer
+
+	if err := base.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer base.UnregisterSession(context.Background(), session.id)
+	if client, ok := s.mcp.GetConnectionManager().GetConnection(session.id); ok {
+		client.AttachSender(session.request)
+		client.SetHistory(history)
+		session.client = client
+	}
+	s.active.Add(1)
+	defer s.active.Add(-1)
+	s.publish(session.id, transport, events.ConnectionOpened)
+	defer s.publish(session.id, transport, events.ConnectionClosed)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ctx = base.WithContext(ctx, session)
+	if session.client != nil {
+		ctx = connection.WithConnection(ctx, session.client)
+	} else {
+		ctx = connection.WithConnectionID(ctx, session.id)
+	}
+	if visible := s.config.VisibleTools; visible != nil {
+		session.tools = &toolSet{visible: func() []mcpgo.Tool { return visible(ctx) }}
+		session.tools.advertise()
+	}
+
+	logger := logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, session.id)
+	logger.Debug(ctx, "Client connected")
+	defer logger.Debug(context.Background(), "Client disconnected")
+
+	// Closing conn unblocks a pending read when ctx ends first or a write
+	// stalls
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stalls.Stalled():
+		}
+		conn.Close()
+	}()
+	go session.forwardNotifications(ctx)
+	defer session.detach()
+	if notice := s.sessions.add(session); notice != nil {
+		go session.write(notice.notification())
+	}
+	defer s.sessions.remove(session)
+
+	var calls sync.WaitGroup
+	defer calls.Wait()
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			if stalled := s.stalled(ctx, stalls, session, logger); stalled != nil {
+				dumpHistory(ctx, logger, history, stalled.Message)
+				return stalled
+			}
+			if ctx.Err() != nil || isClosed(err) {
+				return nil
+			}
+			dumpHistory(ctx, logger, history, "Connection failed: "+err.Error())
+			return err
+		}
+		message = session.adapter().Inbound(message)
+
+		var request struct {
+			Method string          `json:"method"`
+			ID     mcpgo.RequestId `json:"id"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(message, &request); err != nil {
+			dumpHistory(ctx, logger, history, "Parse error: "+err.Error())
+			session.write(mcpgo.NewJSONRPCError(mcpgo.RequestId{}, mcpgo.PARSE_ERROR, "Parse error", nil))
+			continue
+		}
+
+		if session.outbox != nil && s.deliver(ctx, session, request.Method, request.Params) {
+			continue
+		}
+
+		// Responses to requests the server sent go to the request waiting
+		// for them; late or unknown responses are dropped
+		if request.Method == "" && (request.Result != nil || request.Error != nil) {
+			if session.client == nil || !session.client.HandleResponse(message) {
+				logger.Debug(ctx, "Dropping response to unknown request")
+			}
+			continue
+		}
+
+		// The message is held until it has been answered
+		size := int64(len(message))
+		if err := s.config.Guard.Acquire(size, memguard.MethodPriority(request.Method)); err != nil {
+			s.shed(ctx, session, request.Method, request.ID, err)
+			continue
+		}
+
+		if s.continueRead(session, request.Method, request.ID, request.Params) ||
+			s.route(requestContext(ctx, request.Method, request.ID), session, message, request.Method) {
+			s.config.Guard.Release(size)
+			continue
+		}
+
+		if request.Method == string(mcpgo.MethodToolsList) && session.tools != nil {
+			session.tools.advertise()
+		}
+
+		// Handlers and upstream calls stop once the client stops waiting
+		requestCtx, cancelRequest := router.WithDeadlineHint(ctx, request.Params, s.config.MaxRequestTimeout)
+		requestCtx = requestContext(requestCtx, request.Method, request.ID)
+		if token := mcp.ProgressTokenFromParams(request.Params); token != nil {
+			requestCtx = mcp.WithProgressToken(requestCtx, token)
+		}
+
+		// Tool calls may run for a long time; handle them concurrently so
+		// pings and cancellations still get through
+		if request.Method == string(mcpgo.MethodToolsCall) {
+			calls.Add(1)
+			go func() {
+				defer calls.Done()
+				defer s.config.Guard.Release(size)
+				defer cancelRequest()
+				session.write(s.handle(requestCtx, session, message, request.Method, request.Params))
+			}()
+			continue
+		}
+		session.write(s.handle(requestCtx, session, message, request.Method, request.Params))
+		cancelRequest()
+		s.config.Guard.Release(size)
+	}
+}
+
+// requestContext adds the method and request ID of a message to ctx for
+// logging
+func requestContext(ctx context.Context, method string, id mcpgo.RequestId) context.Context {
+	ctx = logging.WithMethod(ctx, method)
+	if !id.IsNil() {
+		ctx = logging.WithRequestID(ctx, logging.FormatRequestID(id.Value()))
+	}
+	return ctx
+}
+
+// route handles a message for a method of the router, reporting whether it
+// did; other messages are left to the handshake server
+func (s *Server) route(ctx context.Context, session *session, message []byte, method string) bool {
+	if s.router != nil && jsonrpc.IsReserved(method) {
+		s.routeReserved(ctx, session, message)
+		return true
+	}
+	if s.router == nil || (!s.router.HasMethod(method) && !s.router.HasNotificationMethod(method)) {
+		return false
+	}
+	parsed, err := jsonrpc.ParseMessage(message)
+	if err != nil {
+		return false
+	}
+	defer jsonrpc.ReleaseMessage(parsed)
+
+	switch m := parsed.(type) {
+	case *jsonrpc.Request:
+		if !s.router.HasMethod(method) {
+			return false
+		}
+		if response := s.dispatch.Handle(ctx, m); response != nil {
+			session.write(response)
+		}
+	case *jsonrpc.Notification:
+		if !s.router.HasNotificationMethod(method) {
+			return false
+		}
+		s.router.HandleNotification(ctx, m)
+	default:
+		return false
+	}
+	return true
+}
+
+// routeReserved answers a call to a reserved "rpc." method from the router,
+// which rejects it unless a handler was set with RegisterReserved.
+// Notifications to reserved methods are dropped.
+func (s *Server) routeReserved(ctx context.Context, session *session, message []byte) {
+	var request jsonrpc.Request
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil {
+		return
+	}
+	if response := s.dispatch.Handle(ctx, &request); response != nil {
+		session.write(response)
+	}
+}
+
+// handle answers message with the handshake server, adapting resource
+// contents to the client's preferences, then truncating oversized results
+func (s *Server) handle(ctx context.Context, session *session, message []byte, method string, params json.RawMessage) mcpgo.JSONRPCMessage {
+	response := s.mcp.HandleMessage(ctx, message)
+	switch method {
+	case string(mcpgo.MethodInitialize):
+		response = s.describe(response)
+	case string(mcpgo.MethodResourcesRead):
+		response = s.negotiate(ctx, params, response)
+	}
+	return s.limit(session, response)
+}
+
+// initializeResult is an initialize result whose serverInfo describes the
+// build of the server
+type initializeResult struct {
+	mcpgo.InitializeResult
+	ServerInfo serverInfo `json:"serverInfo"`
+}
+
+// serverInfo extends the serverInfo of initialize results
+type serverInfo struct {
+	mcpgo.Implementation
+	Build *buildinfo.Info `json:"build,omitempty"`
+}
+
+// describe adds the build information to an initialize response
+func (s *Server) describe(response mcpgo.JSONRPCMessage) mcpgo.JSONRPCMessage {
+	r, ok := response.(mcpgo.JSONRPCResponse)
+	if s.config.BuildInfo == nil || !ok {
+		return response
+	}
+	result, ok := r.Result.(mcpgo.InitializeResult)
+	if !ok {
+		return response
+	}
+	r.Result = initializeResult{
+		InitializeResult: result,
+		ServerInfo:       serverInfo{Implementation: result.ServerInfo, Build: s.config.BuildInfo},
+	}
+	return r
+}
+
+// negotiate converts the contents of a resources/read response to the
+// representation asked for in params
+func (s *Server) negotiate(ctx context.Context, params json.RawMessage, response mcpgo.JSONRPCMessage) mcpgo.JSONRPCMessage {
+	r, ok := response.(mcpgo.JSONRPCResponse)
+	if s.config.Negotiator == nil || !ok {
+		return response
+	}
+	result, ok := r.Result.(mcpgo.ReadResourceResult)
+	prefs := resources.ParsePreferences(params)
+	if !ok || prefs.IsZero() {
+		return response
+	}
+	result.Contents = s.config.Negotiator.Negotiate(ctx, prefs, result.Contents)
+	r.Result = result
+	return r
+}
+
+// limit truncates an oversized tool or resource result in response
+func (s *Server) limit(session *session, response mcpgo.JSONRPCMessage) mcpgo.JSONRPCMessage {
+	r, ok := response.(mcpgo.JSONRPCResponse)
+	if s.config.Responses == nil || !ok {
+		return response
+	}
+	switch result := r.Result.(type) {
+	case mcpgo.CallToolResult:
+		r.Result, ok = s.config.Responses.ToolResult(session.id, result)
+	case mcpgo.ReadResourceResult:
+		r.Result, ok = s.config.Responses.ReadResult(session.id, result)
+	default:
+		ok = false
+	}
+	if !ok {
+		return response
+	}
+	return r
+}
+
+// continueRead answers a resources/read of the remainder of a truncated
+// result, reporting whether it did
+func (s *Server) continueRead(session *session, method string, id mcpgo.RequestId, params json.RawMessage) bool {
+	if s.config.Responses == nil || method != string(mcpgo.MethodResourcesRead) || id.IsNil() {
+		return false
+	}
+	var read struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &read); err != nil || !truncate.IsContinuation(read.URI) {
+		return false
+	}
+	result, err := s.config.Responses.Continue(session.id, read.URI)
+	if err != nil {
+		session.write(mcperrors.NewResourceNotFoundError(read.URI).ToMCPError(id))
+		return true
+	}
+	session.write(mcpgo.JSONRPCResponse{JSONRPC: mcpgo.JSONRPC_VERSION, ID: id, Result: result})
+	return true
+}
+
+// deliver handles the messages of at-least-once delivery: initialize names
+// the outbox of the client, notifications/initialized sends its pending
+// notifications again and notifications/delivered acknowledges them. It
+// reports whether message was consumed.
+func (s *Server) deliver(ctx context.Context, session *session, method string, params json.RawMessage) bool {
+	switch method {
+	case string(mcpgo.MethodInitialize):
+		if token := delivery.ResumeToken(params); token != "" {
+			session.token.Store(&token)
+		}
+	case "notifications/initialized":
+		token := session.resumeToken()
+		if token == "" {
+			return false
+		}
+		messages, err := session.outbox.Attach(ctx, token)
+		if err != nil {
+			logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, session.id).
+				Error(ctx, err, "Failed to resume notifications")
+		}
+		for _, message := range messages {
+			session.write(message)
+		}
+	case delivery.MethodDelivered:
+		if token := session.resumeToken(); token != "" {
+			if _, err := session.outbox.Ack(ctx, token, delivery.DeliveredKeys(params)); err != nil {
+				logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, session.id).
+					Error(ctx, err, "Failed to acknowledge notifications")
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// stalled returns the transport timeout error closing a connection whose
+// client stopped reading, marking the connection unhealthy, or nil if no
+// write stalled
+func (s *Server) stalled(ctx context.Context, stalls *stallConn, session *session, logger *logging.Logger) *mcperrors.MCPError {
+	select {
+	case <-stalls.Stalled():
+	default:
+		return nil
+	}
+	err := mcperrors.NewTransportTimeoutError("write to client", s.config.WriteTimeout.String())
+	if session.client != nil {
+		session.client.MarkUnhealthy(err.Message)
+	}
+	logger.Warn(ctx, "Client stopped reading; connection closed")
+	return err
+}
+
+// dumpHistory logs the recorded messages of a connection that ran into a
+// protocol failure
+func dumpHistory(ctx context.Context, logger *logging.Logger, history *connection.History, reason string) {
+	if history == nil {
+		return
+	}
+	logger.WithField("history", history.Entries()).Warn(ctx, reason)
+}
+
+// shed rejects a message the guard did not admit. Requests get an error
+// response; notifications are dropped.
+func (s *Server) shed(ctx context.Context, session *session, method string, id mcpgo.RequestId, err *mcperrors.MCPError) {
+	logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, session.id).
+		WithField("method", method).Warn(ctx, "Shedding message: "+err.Message)
+	if !id.IsNil() {
+		session.write(err.ToMCPError(id))
+	}
+}
+
+// session is the client session of a connection served by ServeConn
+type session struct {
+	id            string
+	conn          Conn
+	notifications chan mcpgo.JSONRPCNotification
+	initialized   atomic.Bool
+
+	// ordered sessions pass every write to forwardNotifications, which
+	// numbers notifications with seq until it stops
+	ordered bool
+	writes  chan orderedWrite
+	stopped chan struct{}
+	seq     uint64
+
+	// tools filters notifications/tools/list_changed when the server
+	// tracks visible tools
+	tools *toolSet
+
+	// client sends server-initiated requests over the connection
+	client *connection.Connection
+
+	// shims are selected into adapted once the handshake completes
+	shims    compat.Table
+	adapted  atomic.Pointer[compat.Adapter]
+	selected atomic.Bool
+
+	// outbox tracks notifications once the client sent its resume token
+	// in initialize
+	outbox *delivery.Outbox
+	token  atomic.Pointer[string]
+}
+
+// toolSet tracks the tools a connection was last told about by their
+// digest
+type toolSet struct {
+	visible func() []mcpgo.Tool
+
+	mu     sync.Mutex
+	digest [sha256.Size]byte
+}
+
+// current returns the digest of the tools now visible
+func (t *toolSet) current() [sha256.Size]byte {
+	// Tools encode as they do in tools/list results
+	data, _ := json.Marshal(t.visible())
+	return sha256.Sum256(data)
+}
+
+// advertise records the visible tools as known to the client, e.g. when it
+// lists them
+func (t *toolSet) advertise() {
+	digest := t.current()
+	t.mu.Lock()
+	t.digest = digest
+	t.mu.Unlock()
+}
+
+// changed reports whether the visible tools differ from those last
+// advertised, advertising them if so
+func (t *toolSet) changed() bool {
+	digest := t.current()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if digest == t.digest {
+		return false
+	}
+	t.digest = digest
+	return true
+}
+
+// orderedWrite is a message written by an ordered session, and the channel
+// closed once it has been
+type orderedWrite struct {
+	message any
+	done    chan struct{}
+}
+
+var (
+	_ mcpserver.ClientSession       = (*session)(nil)
+	_ mcpserver.SessionWithSampling = (*session)(nil)
+)
+
+// newSession creates a session writing to conn
+func newSession(id string, conn Conn) *session {
+	return &session{
+		id:            id,
+		conn:          conn,
+		notifications: make(chan mcpgo.JSONRPCNotification, 100),
+		writes:        make(chan orderedWrite),
+		stopped:       make(chan struct{}),
+	}
+}
+
+func (s *session) SessionID() string { return s.id }
+
+func (s *session) NotificationChannel() chan<- mcpgo.JSONRPCNotification { return s.notifications }
+
+func (s *session) Initialize() { s.initialized.Store(true) }
+
+func (s *session) Initialized() bool { return s.initialized.Load() }
+
+// RequestSampling sends sampling/createMessage to the client, so the mcp-go
+// server's RequestSampling works over these sessions
+func (s *session) RequestSampling(ctx context.Context, request mcpgo.CreateMessageRequest) (*mcpgo.CreateMessageResult, error) {
+	if s.client == nil {
+		return nil, connection.ErrNoSender
+	}
+	return s.client.CreateMessage(ctx, request.CreateMessageParams)
+}
+
+// request sends a server-initiated request; ordered sessions write it after
+// the notifications queued before it
+func (s *session) request(message []byte) error {
+	if s.ordered {
+		s.writeOrdered(json.RawMessage(message))
+		return nil
+	}
+	return s.conn.WriteMessage(s.adapter().Outbound(message))
+}
+
+// adapter returns the shims for the client, or nil when none apply or the
+// handshake has not completed yet
+func (s *session) adapter() *compat.Adapter {
+	if s.selected.Load() {
+		return s.adapted.Load()
+	}
+	if len(s.shims) == 0 || s.client == nil || !s.client.IsReady() {
+		return nil
+	}
+
+	info := s.client.Info()
+	name, _ := info.ClientInfo["name"].(string)
+	adapter := s.shims.Select(info.ProtocolVersion, name)
+	if s.adapted.CompareAndSwap(nil, adapter) && adapter != nil {
+		logging.Default().WithComponent("server").WithFields(logging.LogFields{
+			logging.FieldConnectionID:    s.id,
+			logging.FieldProtocolVersion: info.ProtocolVersion,
+			"shims":                      adapter.Names(),
+		}).Info(context.Background(), "Adapting messages for legacy client")
+	}
+	s.selected.Store(true)
+	return s.adapted.Load()
+}
+
+// maxNotificationBatch bounds how many queued notifications are written
+// together
+const maxNotificationBatch = 64
+
+// write sends a response or notification; a nil message is skipped
+func (s *session) write(message any) {
+	if s.ordered {
+		s.writeOrdered(message)
+		return
+	}
+	if data := s.encode(message); data != nil {
+		s.send(s.conn.WriteMessage(data))
+	}
+}
+
+// send reports a failed write unless the connection was closed
+func (s *session) send(err error) {
+	if err != nil && !isClosed(err) {
+		logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, s.id).
+			Error(context.Background(), err, "Failed to write message")
+	}
+}
+
+// encodeMessage marshals message, returning nil for a nil message or on
+// failure
+func encodeMessage(message any) []byte {
+	if message == nil {
+		return nil
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		logging.Default().WithComponent("server").Error(context.Background(), err, "Failed to encode message")
+		return nil
+	}
+	return data
+}
+
+// encode marshals message and adapts it for the client, returning nil for
+// a nil message or on failure
+func (s *session) encode(message any) []byte {
+	if notification, ok := message.(mcpgo.JSONRPCNotification); ok {
+		message = s.track(notification)
+	}
+	data := encodeMessage(message)
+	if data == nil {
+		return nil
+	}
+	return s.adapter().Outbound(data)
+}
+
+// appendMessage appends the encoding of message to batch, skipping it when
+// it cannot be encoded
+func (s *session) appendMessage(batch [][]byte, message any) [][]byte {
+	if data := s.encode(message); data != nil {
+		batch = append(batch, data)
+	}
+	return batch
+}
+
+// forwardNotifications writes queued notifications until ctx is done. A
+// burst of notifications, e.g. from an upstream, is written in batches.
+func (s *session) forwardNotifications(ctx context.Context) {
+	defer close(s.stopped)
+	if s.ordered {
+		// Only this loop takes notifications from the queue, so a write
+		// follows everything queued before it
+		for {
+			select {
+			case notification := <-s.notifications:
+				if s.skip(notification) {
+					continue
+				}
+				s.flush(notification)
+			case w := <-s.writes:
+				s.flush(w.message)
+				close(w.done)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	batcher, canBatch := s.conn.(batchConn)
+	batch := make([][]byte, 0, maxNotificationBatch)
+	for {
+		select {
+		case notification := <-s.notifications:
+			if s.skip(notification) {
+				continue
+			}
+			if !canBatch {
+				s.write(notification)
+				continue
+			}
+			batch = s.appendMessage(batch[:0], notification)
+		drain:
+			for len(batch) < maxNotificationBatch {
+				select {
+				case notification := <-s.notifications:
+					if !s.skip(notification) {
+						batch = s.appendMessage(batch, notification)
+					}
+				default:
+					break drain
+				}
+			}
+			if len(batch) > 0 {
+				s.send(batcher.WriteMessages(batch))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resumeToken returns the token naming the outbox of the client, or ""
+// when it did not opt in to at-least-once delivery
+func (s *session) resumeToken() string {
+	if token := s.token.Load(); token != nil {
+		return *token
+	}
+	return ""
+}
+
+// track records a critical notification in the outbox of the client,
+// returning it with its dedupe key
+func (s *session) track(notification mcpgo.JSONRPCNotification) mcpgo.JSONRPCNotification {
+	token := s.resumeToken()
+	if s.outbox == nil || token == "" {
+		return notification
+	}
+	tracked, err := s.outbox.Track(context.Background(), token, notification)
+	if err != nil {
+		logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, s.id).
+			Error(context.Background(), err, "Failed to track notification")
+	}
+	return tracked
+}
+
+// detach starts the resume window of the client's outbox once its
+// connection closed
+func (s *session) detach() {
+	token := s.resumeToken()
+	if s.outbox == nil || token == "" {
+		return
+	}
+	if err := s.outbox.Detach(context.Background(), token); err != nil {
+		logging.Default().WithComponent("server").WithField(logging.FieldConnectionID, s.id).
+			Error(context.Background(), err, "Failed to detach outbox")
+	}
+}
+
+// skip reports whether notification is a tools/list_changed the client does
+// not need because the tools it may see are unchanged
+func (s *session) skip(notification mcpgo.JSONRPCNotification) bool {
+	if s.tools == nil || notification.Method != mcpgo.MethodNotificationToolsListChanged {
+		return false
+	}
+	return !s.tools.changed()
+}
+
+// writeOrdered hands message to forwardNotifications and waits until it
+// has been written; it is dropped once the session has stopped
+func (s *session) writeOrdered(message any) {
+	w := orderedWrite{message: message, done: make(chan struct{})}
+	select {
+	case s.writes <- w:
+		<-w.done
+	case <-s.stopped:
+	}
+}
+
+// flush writes message with the notifications still queued: after them,
+// or before them when message is a notification taken from the queue. A
+// nil message only flushes the queue.
+func (s *session) flush(message any) {
+	var batch [][]byte
+	notification, isNotification := message.(mcpgo.JSONRPCNotification)
+	if isNotification {
+		batch = s.appendMessage(batch, s.number(notification))
+	}
+	for drained := false; !drained; {
+		select {
+		case queued := <-s.notifications:
+			if !s.skip(queued) {
+				batch = s.appendMessage(batch, s.number(queued))
+			}
+		default:
+			drained = true
+		}
+	}
+	if !isNotification {
+		batch = s.appendMessage(batch, message)
+	}
+
+	if batcher, ok := s.conn.(batchConn); ok {
+		if len(batch) > 0 {
+			s.send(batcher.WriteMessages(batch))
+		}
+		return
+	}
+	for _, data := range batch {
+		s.send(s.conn.WriteMessage(data))
+	}
+}
+
+// number stamps the next sequence number into a copy of the notification's
+// _meta. The original is shared with other sessions when it is broadcast.
+func (s *session) number(notification mcpgo.JSONRPCNotification) mcpgo.JSONRPCNotification {
+	s.seq++
+	meta := make(map[string]any, len(notification.Params.Meta)+1)
+	for key, value := range notification.Params.Meta {
+		meta[key] = value
+	}
+	meta["seq"] = s.seq
+	notification.Params.Meta = meta
+	return notification
+}
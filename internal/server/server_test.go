@@ -0,0 +1,1705 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/net/websocket"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/admin"
+	"github.com/meta-mcp/meta-mcp-server/internal/buildinfo"
+	"github.com/meta-mcp/meta-mcp-server/internal/chaos"
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/delivery"
+	"github.com/meta-mcp/meta-mcp-server/internal/health"
+	"github.com/meta-mcp/meta-mcp-server/internal/memguard"
+	"github.com/meta-mcp/meta-mcp-server/internal/profiling"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/compat"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	transportpkg "github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/wirecheck"
+	"github.com/meta-mcp/meta-mcp-server/internal/quota"
+	"github.com/meta-mcp/meta-mcp-server/internal/resources"
+	"github.com/meta-mcp/meta-mcp-server/internal/throttle"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+	"github.com/meta-mcp/meta-mcp-server/internal/truncate"
+)
+
+func newHandshakeServer(t *testing.T) *mcp.HandshakeServer {
+	t.Helper()
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+	hs := mcp.NewHandshakeServer(config)
+	hs.AddTool(mcpgo.NewTool("echo", mcpgo.WithString("message")),
+		func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+			return mcpgo.NewToolResultText(request.GetString("message", "")), nil
+		})
+	return hs
+}
+
+// startServer serves transports in the background, returning a channel
+// receiving the result of Serve
+func startServer(t *testing.T, hs *mcp.HandshakeServer, transports ...Transport) <-chan error {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	stopped := make(chan struct{})
+	go func() {
+		done <- Serve(ctx, hs, transports...)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Error("server did not stop")
+		}
+	})
+	return done
+}
+
+// connectClient initializes a client over t
+func connectClient(t *testing.T, tr transport.Interface) *client.Client {
+	t.Helper()
+	c := client.NewClient(tr)
+	// The SSE stream lives as long as the context passed to Start
+	require.NoError(t, c.Start(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request := mcpgo.InitializeRequest{}
+	request.Params.ProtocolVersion = mcpgo.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcpgo.Implementation{Name: "test", Version: "1.0.0"}
+	_, err := c.Initialize(ctx, request)
+	require.NoError(t, err)
+	return c
+}
+
+func callEcho(t *testing.T, c *client.Client, message string) {
+	t.Helper()
+	request := mcpgo.CallToolRequest{}
+	request.Params.Name = "echo"
+	request.Params.Arguments = map[string]any{"message": message}
+	result, err := c.CallTool(context.Background(), request)
+	require.NoError(t, err)
+	text, ok := mcpgo.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	assert.Equal(t, message, text.Text)
+}
+
+func TestServe_SharedAcrossTransports(t *testing.T) {
+	hs := newHandshakeServer(t)
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	socket := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	done := startServer(t, hs, NewStdio(stdinR, stdoutW), socket)
+
+	stdio := connectClient(t, transport.NewIO(stdoutR, stdinW, io.NopCloser(strings.NewReader(""))))
+	callEcho(t, stdio, "over stdio")
+
+	conn, err := net.Dial("unix", socket.(*socketTransport).Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	sock := connectClient(t, transport.NewIO(conn, conn, io.NopCloser(strings.NewReader(""))))
+	callEcho(t, sock, "over socket")
+
+	// Both clients share one connection manager and see notifications
+	assert.Len(t, hs.GetConnectionManager().Snapshot(), 2)
+	received := make(chan string, 2)
+	for _, c := range []*client.Client{stdio, sock} {
+		c.OnNotification(func(n mcpgo.JSONRPCNotification) { received <- n.Method })
+	}
+	hs.SendNotificationToAllClients("notifications/test", nil)
+	for i := 0; i < 2; i++ {
+		select {
+		case method := <-received:
+			assert.Equal(t, "notifications/test", method)
+		case <-time.After(2 * time.Second):
+			t.Fatal("notification not delivered")
+		}
+	}
+
+	// Closing stdin stops the stdio transport and with it the server
+	stdinW.Close()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after stdin closed")
+	}
+	assert.Empty(t, hs.GetConnectionManager().Snapshot())
+}
+
+func TestServeConn_HandshakeRequired(t *testing.T) {
+	hs := newHandshakeServer(t)
+	socket := NewSocket("tcp", "127.0.0.1:0")
+	startServer(t, hs, socket)
+
+	conn, err := net.Dial("tcp", socket.(*socketTransport).Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}` + "\n" + "not json\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(conn)
+	var response struct {
+		Error struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, decoder.Decode(&response))
+	assert.Equal(t, mcp.ErrorCodeServerNotInitialized, response.Error.Code)
+	require.NoError(t, decoder.Decode(&response))
+	assert.Equal(t, mcpgo.PARSE_ERROR, response.Error.Code)
+}
+
+func TestServeConn_ShedsNearMemoryLimit(t *testing.T) {
+	guard := memguard.New(memguard.Config{Limit: 1000})
+	require.Nil(t, guard.Acquire(920, memguard.PriorityCritical))
+	s := New(newHandshakeServer(t), Config{Guard: guard})
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n"
+	w := &recordingWriter{}
+	require.NoError(t, s.ServeConn(context.Background(), "test", NewLineConn(strings.NewReader(input), w, nil)))
+
+	// The list request is shed, the notification dropped and the ping
+	// passed on to the handshake server
+	decoder := json.NewDecoder(&w.buf)
+	var response struct {
+		ID    int `json:"id"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, decoder.Decode(&response))
+	assert.Equal(t, 1, response.ID)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPMemoryLimit, response.Error.Code)
+
+	require.NoError(t, decoder.Decode(&response))
+	assert.Equal(t, 2, response.ID)
+	if response.Error != nil {
+		assert.NotEqual(t, mcperrors.ErrorCodeMCPMemoryLimit, response.Error.Code)
+	}
+	assert.False(t, decoder.More())
+
+	assert.Equal(t, int64(920), guard.Used())
+	assert.Equal(t, map[string]int64{"low": 1, "high": 1}, guard.Stats().Shed)
+}
+
+func TestServeConn_InjectsFaults(t *testing.T) {
+	s := New(newHandshakeServer(t), Config{Faults: chaos.Faults{
+		Inbound:  chaos.New(chaos.Config{Schedule: map[int]chaos.Fault{1: chaos.FaultDrop}}),
+		Outbound: chaos.New(chaos.Config{Schedule: map[int]chaos.Fault{1: chaos.FaultDuplicate}}),
+	}})
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n"
+	w := &recordingWriter{}
+	require.NoError(t, s.ServeConn(context.Background(), "test", NewLineConn(strings.NewReader(input), w, nil)))
+
+	// The first ping is dropped and the response to the second duplicated
+	decoder := json.NewDecoder(&w.buf)
+	for range 2 {
+		var response struct {
+			ID int `json:"id"`
+		}
+		require.NoError(t, decoder.Decode(&response))
+		assert.Equal(t, 2, response.ID)
+	}
+	assert.False(t, decoder.More())
+}
+
+func TestSSE(t *testing.T) {
+	hs := newHandshakeServer(t)
+	sse := NewSSE(HTTPConfig{Addr: "127.0.0.1:0"})
+	startServer(t, hs, sse)
+
+	tr, err := transport.NewSSE("http://" + sse.(*sseTransport).Addr().String() + "/sse")
+	require.NoError(t, err)
+	c := connectClient(t, tr)
+	defer c.Close()
+	callEcho(t, c, "over sse")
+}
+
+func TestWebSocket(t *testing.T) {
+	hs := newHandshakeServer(t)
+	ws := NewWebSocket(HTTPConfig{Addr: "127.0.0.1:0"})
+	startServer(t, hs, ws)
+	url := "ws://" + ws.(*webSocketTransport).Addr().String() + "/ws"
+
+	conn, err := websocket.Dial(url, "", "http://localhost:3000")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`))
+	var response map[string]any
+	require.NoError(t, websocket.JSON.Receive(conn, &response))
+	assert.Contains(t, response, "result")
+
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"message":"over websocket"}}}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(conn, &response))
+	assert.Contains(t, response["result"].(map[string]any)["content"].([]any)[0], "text")
+
+	// Origins outside the allowlist are refused during the upgrade
+	_, err = websocket.Dial(url, "", "https://evil.example.com")
+	assert.Error(t, err)
+}
+
+func TestHTTP_Tokens(t *testing.T) {
+	hs := newHandshakeServer(t)
+	hs.AddTool(mcpgo.NewTool("whoami"), func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+		identity, _ := connection.IdentityFromContext(ctx)
+		return mcpgo.NewToolResultText(identity), nil
+	})
+	tokens := map[string]string{"s3cret": "ops"}
+	ws := NewWebSocket(HTTPConfig{Addr: "127.0.0.1:0", Tokens: tokens})
+	sse := NewSSE(HTTPConfig{Addr: "127.0.0.1:0", Tokens: tokens})
+	startServer(t, hs, ws, sse)
+
+	// Clients without a known token are refused
+	url := "ws://" + ws.(*webSocketTransport).Addr().String() + "/ws"
+	_, err := websocket.Dial(url, "", "http://localhost:3000")
+	assert.Error(t, err)
+	wsConfig, err := websocket.NewConfig(url, "http://localhost:3000")
+	require.NoError(t, err)
+	wsConfig.Header.Set("Authorization", "Bearer wrong")
+	_, err = websocket.DialConfig(wsConfig)
+	assert.Error(t, err)
+
+	sseURL := "http://" + sse.(*sseTransport).Addr().String() + "/sse"
+	response, err := http.Get(sseURL)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// Tool handlers see the identity of the token
+	wsConfig.Header.Set("Authorization", "Bearer s3cret")
+	conn, err := websocket.DialConfig(wsConfig)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`))
+	var reply map[string]any
+	require.NoError(t, websocket.JSON.Receive(conn, &reply))
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami"}}`))
+	reply = nil
+	require.NoError(t, websocket.JSON.Receive(conn, &reply))
+	assert.Equal(t, "ops", reply["result"].(map[string]any)["content"].([]any)[0].(map[string]any)["text"])
+
+	tr, err := transport.NewSSE(sseURL, transport.WithHeaders(map[string]string{"Authorization": "Bearer s3cret"}))
+	require.NoError(t, err)
+	c := connectClient(t, tr)
+	defer c.Close()
+	request := mcpgo.CallToolRequest{}
+	request.Params.Name = "whoami"
+	result, err := c.CallTool(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "ops", result.Content[0].(mcpgo.TextContent).Text)
+}
+
+func TestServe_NoTransports(t *testing.T) {
+	assert.Error(t, Serve(context.Background(), newHandshakeServer(t)))
+}
+
+func TestServe_TransportFailureStopsOthers(t *testing.T) {
+	hs := newHandshakeServer(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// The SSE transport cannot bind the taken address; the socket must stop
+	done := startServer(t, hs, NewSocket("tcp", "127.0.0.1:0"), NewSSE(HTTPConfig{Addr: listener.Addr().String()}))
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "sse")
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+// recordingWriter records the size of each write
+type recordingWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes []int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, len(p))
+	return w.buf.Write(p)
+}
+
+func TestLineConn_Writes(t *testing.T) {
+	w := &recordingWriter{}
+	conn := NewLineConn(strings.NewReader(""), w, nil)
+
+	// A batch reaches the writer in one write
+	require.NoError(t, conn.(batchConn).WriteMessages([][]byte{[]byte(`{"a":1}`), []byte(`{"b":2}`), []byte(`{"c":3}`)}))
+	assert.Equal(t, "{\"a\":1}\n{\"b\":2}\n{\"c\":3}\n", w.buf.String())
+	assert.Len(t, w.writes, 1)
+
+	// The framing newline does not touch the caller's slice
+	message := make([]byte, 2, 8)
+	copy(message, "{}")
+	require.NoError(t, conn.WriteMessage(message))
+	assert.Equal(t, []byte("{}\x00"), message[:3])
+
+	// Concurrent writers never interleave within a message
+	w.buf.Reset()
+	var writers sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, conn.WriteMessage([]byte(strings.Repeat("x", 100*i+j))))
+			}
+		}()
+	}
+	writers.Wait()
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 1000)
+	for _, line := range lines {
+		assert.Equal(t, strings.Repeat("x", len(line)), line)
+	}
+}
+
+// batchRecorder is a Conn recording the batches written to it
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][][]byte
+}
+
+func (c *batchRecorder) ReadMessage() ([]byte, error)      { return nil, io.EOF }
+func (c *batchRecorder) WriteMessage(message []byte) error { return c.WriteMessages([][]byte{message}) }
+func (c *batchRecorder) Close() error                      { return nil }
+
+func (c *batchRecorder) WriteMessages(messages [][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, append([][]byte(nil), messages...))
+	return nil
+}
+
+func TestSession_BatchesNotifications(t *testing.T) {
+	conn := &batchRecorder{}
+	s := newSession("test", conn)
+	for i := 0; i < 10; i++ {
+		s.notifications <- mcpgo.JSONRPCNotification{JSONRPC: mcpgo.JSONRPC_VERSION}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.forwardNotifications(ctx)
+
+	require.Eventually(t, func() bool {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		return len(conn.batches) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	require.Len(t, conn.batches, 1)
+	assert.Len(t, conn.batches[0], 10)
+}
+
+func TestSession_OrderedNotifications(t *testing.T) {
+	conn := &batchRecorder{}
+	s := newSession("test", conn)
+	s.ordered = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.forwardNotifications(ctx)
+
+	// Producers queue notifications while responses are written
+	const producers, perProducer = 8, 50
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				notification := mcpgo.JSONRPCNotification{JSONRPC: mcpgo.JSONRPC_VERSION}
+				notification.Method = "notifications/test"
+				notification.Params.AdditionalFields = map[string]any{"producer": p, "i": i}
+				s.notifications <- notification
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				s.write(mcpgo.NewJSONRPCResponse(mcpgo.NewRequestId(p*100+i), mcpgo.Result{}))
+			}
+		}()
+	}
+	wg.Wait()
+	s.write(nil) // flush what is still queued
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	var seqs []int
+	next := make(map[int]int)
+	for _, batch := range conn.batches {
+		for _, data := range batch {
+			var message struct {
+				Method string `json:"method"`
+				Params struct {
+					Meta     struct{ Seq int } `json:"_meta"`
+					Producer int               `json:"producer"`
+					I        int               `json:"i"`
+				} `json:"params"`
+			}
+			require.NoError(t, json.Unmarshal(data, &message))
+			if message.Method == "" {
+				continue
+			}
+			seqs = append(seqs, message.Params.Meta.Seq)
+
+			// Each producer's notifications arrive in the order it sent them
+			assert.Equal(t, next[message.Params.Producer], message.Params.I)
+			next[message.Params.Producer]++
+		}
+	}
+	require.Len(t, seqs, producers*perProducer)
+	for i, seq := range seqs {
+		assert.Equal(t, i+1, seq)
+	}
+}
+
+func TestSession_SkipsUnchangedToolLists(t *testing.T) {
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ordered=%v", ordered), func(t *testing.T) {
+			conn := &batchRecorder{}
+			s := newSession("test", conn)
+			s.ordered = ordered
+			var mu sync.Mutex
+			visible := []mcpgo.Tool{mcpgo.NewTool("echo")}
+			s.tools = &toolSet{visible: func() []mcpgo.Tool {
+				mu.Lock()
+				defer mu.Unlock()
+				return visible
+			}}
+			s.tools.advertise()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.forwardNotifications(ctx)
+
+			changed := mcpgo.JSONRPCNotification{JSONRPC: mcpgo.JSONRPC_VERSION}
+			changed.Method = mcpgo.MethodNotificationToolsListChanged
+			other := mcpgo.JSONRPCNotification{JSONRPC: mcpgo.JSONRPC_VERSION}
+			other.Method = "notifications/test"
+
+			methods := func() []string {
+				s.write(nil)
+				conn.mu.Lock()
+				defer conn.mu.Unlock()
+				var methods []string
+				for _, batch := range conn.batches {
+					for _, data := range batch {
+						var message struct {
+							Method string `json:"method"`
+						}
+						require.NoError(t, json.Unmarshal(data, &message))
+						methods = append(methods, message.Method)
+					}
+				}
+				return methods
+			}
+
+			// A change to tools this connection cannot see is not announced
+			s.notifications <- changed
+			s.notifications <- other
+			require.Eventually(t, func() bool { return len(methods()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+			// The first notification of a burst announces the change
+			mu.Lock()
+			visible = append(visible, mcpgo.NewTool("add"))
+			mu.Unlock()
+			s.notifications <- changed
+			s.notifications <- changed
+			want := []string{"notifications/test", mcpgo.MethodNotificationToolsListChanged}
+			require.Eventually(t, func() bool { return len(methods()) >= 2 }, 5*time.Second, 10*time.Millisecond)
+			assert.Equal(t, want, methods())
+		})
+	}
+}
+
+func TestServeConn_OrderedNotificationsPrecedeResponse(t *testing.T) {
+	hs := newHandshakeServer(t)
+	hs.AddTool(mcpgo.NewTool("count"), func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+		srv := mcpserver.ServerFromContext(ctx)
+		for i := 1; i <= 20; i++ {
+			if err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": i}); err != nil {
+				return nil, err
+			}
+		}
+		return mcpgo.NewToolResultText("done"), nil
+	})
+	s := New(hs, Config{OrderedNotifications: true})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"count"}}` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	var progress []int
+	for {
+		var message struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Meta     struct{ Seq int } `json:"_meta"`
+				Progress int               `json:"progress"`
+			} `json:"params"`
+		}
+		require.NoError(t, decoder.Decode(&message))
+		if message.ID == 2 {
+			break
+		}
+		if message.Method == "notifications/progress" {
+			assert.Equal(t, len(progress)+1, message.Params.Meta.Seq)
+			progress = append(progress, message.Params.Progress)
+		}
+	}
+
+	// Every notification sent by the tool came before its result
+	require.Len(t, progress, 20)
+	for i, p := range progress {
+		assert.Equal(t, i+1, p)
+	}
+}
+
+func TestServeConn_TimeoutHint(t *testing.T) {
+	hs := newHandshakeServer(t)
+	deadlines := make(chan time.Duration, 1)
+	hs.AddTool(mcpgo.NewTool("wait"), func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadlines <- 0
+			return mcpgo.NewToolResultError("no deadline"), nil
+		}
+		deadlines <- time.Until(deadline)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	s := New(hs, Config{MaxRequestTimeout: 50 * time.Millisecond})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	// The client asks for an hour; the server caps it
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait","_meta":{"timeoutMs":3600000}}}` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	for {
+		var message struct {
+			ID     int             `json:"id"`
+			Error  json.RawMessage `json:"error"`
+			Result json.RawMessage `json:"result"`
+		}
+		require.NoError(t, decoder.Decode(&message))
+		if message.ID == 2 {
+			assert.True(t, message.Error != nil || message.Result != nil)
+			break
+		}
+	}
+
+	remaining := <-deadlines
+	assert.Greater(t, remaining, time.Duration(0))
+	assert.LessOrEqual(t, remaining, 50*time.Millisecond)
+}
+
+func TestServeConn_ServerInitiatedRequests(t *testing.T) {
+	hs := newHandshakeServer(t)
+	hs.AddTool(mcpgo.NewTool("summarize"), func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+		result, err := hs.RequestSampling(ctx, mcpgo.CreateMessageRequest{
+			CreateMessageParams: mcpgo.CreateMessageParams{MaxTokens: 100},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcpgo.NewToolResultText(result.Model), nil
+	})
+	s := New(hs, Config{})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"sampling":{}},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"summarize"}}` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	for {
+		var message struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+			Result struct {
+				Content []struct{ Text string } `json:"content"`
+			} `json:"result"`
+		}
+		require.NoError(t, decoder.Decode(&message))
+		if message.Method == string(mcpgo.MethodSamplingCreateMessage) {
+			// The client answers the server's request over the same connection
+			id, _ := json.Marshal(message.ID)
+			_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":` + string(id) +
+				`,"result":{"role":"assistant","content":{"type":"text","text":"ok"},"model":"test-model"}}` + "\n"))
+			require.NoError(t, err)
+			continue
+		}
+		if message.ID == float64(2) {
+			require.Len(t, message.Result.Content, 1)
+			assert.Equal(t, "test-model", message.Result.Content[0].Text)
+			break
+		}
+	}
+}
+
+func TestServeConn_ShimsLegacyClients(t *testing.T) {
+	hs := newHandshakeServer(t)
+	s := New(hs, Config{Shims: compat.Builtin})
+
+	listTools := func(version string) map[string]any {
+		clientConn, serverConn := net.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+		defer clientConn.Close()
+
+		_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"` + version + `","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+			`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+			`{"jsonrpc":"2.0","id":2,"method":"tools/list"}` + "\n"))
+		require.NoError(t, err)
+
+		decoder := json.NewDecoder(clientConn)
+		for {
+			var message struct {
+				ID     int `json:"id"`
+				Result struct {
+					Tools []map[string]any `json:"tools"`
+				} `json:"result"`
+			}
+			require.NoError(t, decoder.Decode(&message))
+			if message.ID == 2 {
+				require.Len(t, message.Result.Tools, 1)
+				return message.Result.Tools[0]
+			}
+		}
+	}
+
+	assert.Contains(t, listTools(mcpgo.LATEST_PROTOCOL_VERSION), "annotations")
+	assert.NotContains(t, listTools("2024-11-05"), "annotations")
+}
+
+func TestServeConn_RecordsHistory(t *testing.T) {
+	hs := newHandshakeServer(t)
+	s := New(hs, Config{History: 3})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0","password":"hunter2"}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{not json` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	for {
+		var message struct {
+			Error *struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		}
+		require.NoError(t, decoder.Decode(&message))
+		if message.Error != nil {
+			assert.Equal(t, mcpgo.PARSE_ERROR, message.Error.Code)
+			break
+		}
+	}
+
+	conns := hs.GetConnectionManager().Snapshot()
+	require.Len(t, conns, 1)
+	conn, ok := hs.GetConnectionManager().GetConnection(conns[0].ID)
+	require.True(t, ok)
+	require.NotNil(t, conn.History())
+
+	// The initialize request was evicted by its response, the initialized
+	// notification, the malformed line and the parse error
+	entries := conn.History().Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, connection.Inbound, entries[0].Direction)
+	assert.Contains(t, entries[0].Message, "notifications/initialized")
+	assert.Equal(t, connection.Inbound, entries[1].Direction)
+	assert.Equal(t, connection.Outbound, entries[2].Direction)
+	assert.Contains(t, entries[2].Message, "Parse error")
+}
+
+func TestServeConn_RedactsHistory(t *testing.T) {
+	hs := newHandshakeServer(t)
+	s := New(hs, Config{History: 10})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0","password":"hunter2"}}}` + "\n"))
+	require.NoError(t, err)
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(clientConn).Decode(&response))
+
+	conns := hs.GetConnectionManager().Snapshot()
+	require.Len(t, conns, 1)
+	conn, _ := hs.GetConnectionManager().GetConnection(conns[0].ID)
+	entries := conn.History().Entries()
+	require.Len(t, entries, 2)
+	assert.Contains(t, entries[0].Message, `"name":"test"`)
+	assert.NotContains(t, entries[0].Message, "hunter2")
+	assert.Equal(t, connection.Outbound, entries[1].Direction)
+}
+
+func TestServeConn_ClosesStalledClients(t *testing.T) {
+	hs := newHandshakeServer(t)
+	s := New(hs, Config{WriteTimeout: 50 * time.Millisecond})
+
+	// net.Pipe writes block until the client reads, which it never does
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeConn(context.Background(), "test", NewLineConn(serverConn, serverConn, serverConn))
+	}()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n"))
+	require.NoError(t, err)
+	var conn *connection.Connection
+	require.Eventually(t, func() bool {
+		conns := hs.GetConnectionManager().Snapshot()
+		if len(conns) == 1 {
+			conn, _ = hs.GetConnectionManager().GetConnection(conns[0].ID)
+		}
+		return conn != nil
+	}, time.Second, time.Millisecond)
+
+	select {
+	case err := <-done:
+		mcpErr := mcperrors.FindMCPError(err)
+		require.NotNil(t, mcpErr, "error = %v", err)
+		assert.Equal(t, mcperrors.ErrorCodeMCPTransportTimeout, mcpErr.Code)
+		assert.False(t, conn.Healthy())
+		assert.Contains(t, conn.Info().Unhealthy, "write to client")
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeConn did not close the stalled connection")
+	}
+}
+
+func TestStallConn(t *testing.T) {
+	recorder := &batchRecorder{}
+	conn := watchWrites(recorder, time.Second)
+	require.NoError(t, conn.WriteMessage([]byte("a")))
+	require.NoError(t, conn.WriteMessages([][]byte{[]byte("b"), []byte("c")}))
+	assert.Equal(t, [][][]byte{{[]byte("a")}, {[]byte("b"), []byte("c")}}, recorder.batches)
+
+	stalled := watchWrites(blockingConn{make(chan struct{})}, 10*time.Millisecond)
+	err := stalled.WriteMessage([]byte("a"))
+	assert.ErrorIs(t, err, errStalled)
+	assert.True(t, isClosed(err))
+	select {
+	case <-stalled.Stalled():
+	default:
+		t.Fatal("Stalled() not closed")
+	}
+	assert.ErrorIs(t, stalled.WriteMessage([]byte("b")), errStalled)
+}
+
+func TestThrottleConn(t *testing.T) {
+	recorder := &batchRecorder{}
+	assert.Same(t, Conn(recorder), throttleWrites(recorder, nil))
+
+	fake := clock.NewFake(time.Unix(0, 0))
+	conn := throttleWrites(recorder, throttle.New(throttle.Config{PerConnection: 10, Clock: fake}))
+	require.NoError(t, conn.WriteMessage([]byte("0123456789")))
+
+	// The bucket is empty: the next write waits for it to refill
+	written := make(chan error, 1)
+	go func() { written <- conn.(batchConn).WriteMessages([][]byte{[]byte("ab"), []byte("c")}) }()
+	require.NoError(t, fake.BlockUntil(context.Background(), 1))
+	fake.Advance(300 * time.Millisecond)
+	require.NoError(t, <-written)
+	assert.Equal(t, [][][]byte{{[]byte("0123456789")}, {[]byte("ab"), []byte("c")}}, recorder.batches)
+
+	// Closing the connection fails writes waiting their turn
+	go func() { written <- conn.WriteMessage([]byte("late")) }()
+	require.NoError(t, fake.BlockUntil(context.Background(), 1))
+	require.NoError(t, conn.Close())
+	assert.True(t, isClosed(<-written))
+	assert.Len(t, recorder.batches, 2)
+}
+
+// blockingConn is a Conn whose writes block until it is closed
+type blockingConn struct {
+	closed chan struct{}
+}
+
+func (c blockingConn) ReadMessage() ([]byte, error) { return nil, io.EOF }
+func (c blockingConn) WriteMessage([]byte) error {
+	<-c.closed
+	return net.ErrClosed
+}
+func (c blockingConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestServeConn_WireChecks(t *testing.T) {
+	hs := newHandshakeServer(t)
+	s := New(hs, Config{WireChecks: true})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}` + "\n" +
+		`{"jsonrpc":"2.0","id":3,"method":"unknown/method"}` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	for want := uint64(1); want <= 3; want++ {
+		var message struct {
+			Result json.RawMessage `json:"result"`
+			Error  struct {
+				Data json.RawMessage `json:"data"`
+			} `json:"error"`
+		}
+		require.NoError(t, decoder.Decode(&message))
+		payload := message.Result
+		if payload == nil {
+			payload = message.Error.Data
+		}
+		seq, err := wirecheck.Verify(payload)
+		require.NoError(t, err)
+		assert.Equal(t, want, seq)
+	}
+}
+
+func TestServeConn_WireLog(t *testing.T) {
+	hs := newHandshakeServer(t)
+	var buf bytes.Buffer
+	s := New(hs, Config{WireChecks: true, WireLog: router.NewWireLogger(router.WireLogConfig{Output: &buf})})
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}` + "\n"))
+	require.NoError(t, err)
+
+	// Records are written before the responses reach the client
+	decoder := json.NewDecoder(clientConn)
+	for i := 0; i < 2; i++ {
+		var response json.RawMessage
+		require.NoError(t, decoder.Decode(&response))
+	}
+
+	var methods []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record router.WireRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.True(t, strings.HasPrefix(record.ConnectionID, "test-"))
+		if record.Method != "notifications/initialized" {
+			assert.NotEmpty(t, record.Response, record.Method)
+			assert.NotContains(t, string(record.Response), `"wire"`, "records are taken before wire checks")
+		}
+		methods = append(methods, record.Method)
+	}
+	assert.ElementsMatch(t, []string{"initialize", "notifications/initialized", "tools/list"}, methods)
+}
+
+// BenchmarkLineConn_Notifications writes notifications to a pipe one at a
+// time and in batches; ns/op is per notification
+func TestServeConn_ResumesUnacknowledgedNotifications(t *testing.T) {
+	hs := newHandshakeServer(t)
+	outbox := delivery.New(delivery.Config{})
+	s := New(hs, Config{Delivery: outbox})
+
+	// connect serves a client sending resume token abc and returns its
+	// decoder, past the initialize response
+	connect := func() (net.Conn, *json.Decoder, <-chan error) {
+		clientConn, serverConn := net.Pipe()
+		done := make(chan error, 1)
+		go func() {
+			done <- s.ServeConn(context.Background(), "test", NewLineConn(serverConn, serverConn, serverConn))
+		}()
+		_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"},"_meta":{"resumeToken":"abc"}}}` + "\n" +
+			`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"))
+		require.NoError(t, err)
+		decoder := json.NewDecoder(clientConn)
+		var initialized struct {
+			ID int `json:"id"`
+		}
+		require.NoError(t, decoder.Decode(&initialized))
+		require.Equal(t, 1, initialized.ID)
+		return clientConn, decoder, done
+	}
+	type notification struct {
+		Method string `json:"method"`
+		Params struct {
+			Meta map[string]any `json:"_meta"`
+		} `json:"params"`
+	}
+
+	clientConn, decoder, done := connect()
+	require.Eventually(t, func() bool { return s.ActiveSessions() == 1 }, time.Second, 10*time.Millisecond)
+	hs.SendNotificationToAllClients(mcpgo.MethodNotificationToolsListChanged, nil)
+	var changed notification
+	require.NoError(t, decoder.Decode(&changed))
+	assert.Equal(t, mcpgo.MethodNotificationToolsListChanged, changed.Method)
+	assert.Equal(t, "1", changed.Params.Meta[delivery.MetaDedupeKey])
+
+	// The client drops before acknowledging; it gets the notification
+	// again with the same key once it reconnects
+	clientConn.Close()
+	require.NoError(t, <-done)
+	clientConn, decoder, done = connect()
+	var resent notification
+	require.NoError(t, decoder.Decode(&resent))
+	assert.Equal(t, changed, resent)
+
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/delivered","params":{"dedupeKeys":["1"]}}` + "\n"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		pending, err := outbox.Pending(context.Background(), "abc")
+		return err == nil && pending == 0
+	}, time.Second, 10*time.Millisecond)
+	clientConn.Close()
+	require.NoError(t, <-done)
+	assert.Equal(t, delivery.Stats{Tracked: 1, Acknowledged: 1, Resent: 1}, outbox.Stats())
+}
+
+func TestServeConn_TruncatesLargeResults(t *testing.T) {
+	hs := newHandshakeServer(t)
+	s := New(hs, Config{Responses: truncate.New(truncate.Config{MaxBytes: 8, TTL: time.Minute})})
+
+	clientConn, serverConn := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeConn(context.Background(), "test", NewLineConn(serverConn, serverConn, serverConn))
+	}()
+	decoder := json.NewDecoder(clientConn)
+	type response struct {
+		ID     int `json:"id"`
+		Result struct {
+			Meta     map[string]truncate.Marker   `json:"_meta"`
+			Content  []mcpgo.TextContent          `json:"content"`
+			Contents []mcpgo.TextResourceContents `json:"contents"`
+		} `json:"result"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	send := func(message string) response {
+		t.Helper()
+		_, err := clientConn.Write([]byte(message + "\n"))
+		require.NoError(t, err)
+		var r response
+		require.NoError(t, decoder.Decode(&r))
+		return r
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"))
+	require.NoError(t, err)
+
+	// Short results are untouched
+	called := send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"message":"short"}}}`)
+	assert.Equal(t, "short", called.Result.Content[0].Text)
+	assert.NotContains(t, called.Result.Meta, truncate.MetaTruncated)
+
+	called = send(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"héllo, wörld!"}}}`)
+	assert.Equal(t, "héllo, ", called.Result.Content[0].Text)
+	marker := called.Result.Meta[truncate.MetaTruncated]
+	assert.Equal(t, 15, marker.OriginalBytes)
+	assert.Equal(t, 8, marker.ReturnedBytes)
+	require.True(t, truncate.IsContinuation(marker.Continuation))
+
+	read := send(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"` + marker.Continuation + `"}}`)
+	require.Len(t, read.Result.Contents, 1)
+	assert.Equal(t, "wörld!", read.Result.Contents[0].Text)
+	assert.Equal(t, marker.Continuation, read.Result.Contents[0].URI)
+	assert.NotContains(t, read.Result.Meta, truncate.MetaTruncated)
+
+	// A remainder is read once
+	read = send(`{"jsonrpc":"2.0","id":5,"method":"resources/read","params":{"uri":"` + marker.Continuation + `"}}`)
+	require.NotNil(t, read.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPResourceNotFound, read.Error.Code)
+
+	clientConn.Close()
+	require.NoError(t, <-done)
+}
+
+func TestServeConn_NegotiatesResourceContents(t *testing.T) {
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithResourceCapabilities(false, false)}
+	hs := mcp.NewHandshakeServer(config)
+	hs.AddResource(mcpgo.NewResource("https://example.com/", "Example"),
+		func(ctx context.Context, request mcpgo.ReadResourceRequest) ([]mcpgo.ResourceContents, error) {
+			return []mcpgo.ResourceContents{mcpgo.TextResourceContents{
+				URI: request.Params.URI, MIMEType: "text/html; charset=utf-8", Text: "<h1>Hi</h1><p>there</p>",
+			}}, nil
+		})
+	negotiator := resources.NewNegotiator(resources.NegotiatorConfig{})
+	negotiator.Register(resources.HTMLToMarkdown)
+	s := New(hs, Config{Negotiator: negotiator})
+
+	clientConn, serverConn := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeConn(context.Background(), "test", NewLineConn(serverConn, serverConn, serverConn))
+	}()
+	decoder := json.NewDecoder(clientConn)
+	read := func(message string) []map[string]any {
+		t.Helper()
+		_, err := clientConn.Write([]byte(message + "\n"))
+		require.NoError(t, err)
+		var r struct {
+			Result struct {
+				Contents []map[string]any `json:"contents"`
+			} `json:"result"`
+		}
+		require.NoError(t, decoder.Decode(&r))
+		return r.Result.Contents
+	}
+	read(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"))
+	require.NoError(t, err)
+
+	contents := read(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"https://example.com/"}}`)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "text/html; charset=utf-8", contents[0]["mimeType"])
+
+	contents = read(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"https://example.com/","_meta":{"accept":["text/markdown"]}}}`)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "text/markdown", contents[0]["mimeType"])
+	assert.Equal(t, "# Hi\n\nthere\n", contents[0]["text"])
+
+	contents = read(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"https://example.com/","_meta":{"encoding":"blob"}}}`)
+	require.Len(t, contents, 1)
+	assert.NotEmpty(t, contents[0]["blob"])
+
+	clientConn.Close()
+	require.NoError(t, <-done)
+}
+
+func TestServeConn_DescribesBuild(t *testing.T) {
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithResourceCapabilities(false, false)}
+	hs := mcp.NewHandshakeServer(config)
+	info := buildinfo.Info{Version: "1.2.3", GitCommit: "abc", Features: []string{"metrics"}, ProtocolVersions: config.SupportedVersions}
+	buildinfo.Register(hs, info)
+	s := New(hs, Config{BuildInfo: &info})
+
+	clientConn, serverConn := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeConn(context.Background(), "test", NewLineConn(serverConn, serverConn, serverConn))
+	}()
+	decoder := json.NewDecoder(clientConn)
+	call := func(message string) map[string]any {
+		t.Helper()
+		_, err := clientConn.Write([]byte(message + "\n"))
+		require.NoError(t, err)
+		var r struct {
+			Result map[string]any `json:"result"`
+		}
+		require.NoError(t, decoder.Decode(&r))
+		return r.Result
+	}
+
+	result := call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	serverInfo := result["serverInfo"].(map[string]any)
+	assert.Equal(t, config.Name, serverInfo["name"])
+	build := serverInfo["build"].(map[string]any)
+	assert.Equal(t, "1.2.3", build["version"])
+	assert.Equal(t, []any{"metrics"}, build["features"])
+	assert.NotNil(t, result["capabilities"], "the rest of the result is kept")
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"))
+	require.NoError(t, err)
+
+	result = call(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"meta://server/info"}}`)
+	contents := result["contents"].([]any)
+	require.Len(t, contents, 1)
+	var read map[string]any
+	require.NoError(t, json.Unmarshal([]byte(contents[0].(map[string]any)["text"].(string)), &read))
+	assert.Equal(t, "abc", read["gitCommit"])
+	assert.Equal(t, "2025-03-26", read["protocolVersion"])
+
+	clientConn.Close()
+	require.NoError(t, <-done)
+}
+
+func TestNewServer_Lifecycle(t *testing.T) {
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+
+	var sessions atomic.Int32
+	reloads := 0
+	socket := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	srv := NewServer(
+		WithHandshake(config),
+		WithHooks(func(hooks *mcpserver.Hooks) {
+			hooks.AddOnRegisterSession(func(context.Context, mcpserver.ClientSession) { sessions.Add(1) })
+		}),
+		WithTransports(socket),
+		WithReload(func(ctx context.Context) error {
+			reloads++
+			return nil
+		}),
+	)
+	srv.Tools().MustRegister(tools.Definition{
+		Tool: mcpgo.NewTool("echo", mcpgo.WithString("message")),
+		Handler: func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+			return mcpgo.NewToolResultText(request.GetString("message", "")), nil
+		},
+	})
+	assert.Nil(t, srv.Done())
+
+	require.NoError(t, srv.Start(context.Background()))
+	assert.ErrorIs(t, srv.Start(context.Background()), ErrStarted)
+
+	conn, err := net.Dial("unix", socket.(*socketTransport).Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	c := connectClient(t, transport.NewIO(conn, conn, io.NopCloser(strings.NewReader(""))))
+	callEcho(t, c, "through the facade")
+	assert.Len(t, srv.Connections().Snapshot(), 1)
+	assert.Equal(t, int32(1), sessions.Load())
+
+	require.NoError(t, srv.Reload(context.Background()))
+	assert.Equal(t, 1, reloads)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	select {
+	case <-srv.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	assert.NoError(t, srv.Wait())
+}
+
+func TestNewServer_RoutesRouterMethods(t *testing.T) {
+	r := router.New()
+	r.RegisterFunc("admin/ping", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params struct {
+			Echo string `json:"echo"`
+		}
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+		return jsonrpc.NewResponse(map[string]any{"echo": params.Echo}, req.ID)
+	})
+	srv := NewServer(WithRouter(r))
+	assert.Same(t, r, srv.Router())
+	assert.Nil(t, srv.Upstreams())
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	// Router methods are answered whatever the handshake state; others
+	// still go through the handshake server
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"admin/ping","params":{"echo":"hi"}}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	var pong struct {
+		ID     int               `json:"id"`
+		Result map[string]string `json:"result"`
+	}
+	require.NoError(t, decoder.Decode(&pong))
+	assert.Equal(t, 1, pong.ID)
+	assert.Equal(t, map[string]string{"echo": "hi"}, pong.Result)
+
+	var rejected struct {
+		ID    int             `json:"id"`
+		Error json.RawMessage `json:"error"`
+	}
+	require.NoError(t, decoder.Decode(&rejected))
+	assert.Equal(t, 2, rejected.ID)
+	assert.NotNil(t, rejected.Error)
+}
+
+func TestNewServer_ReservedMethods(t *testing.T) {
+	r := router.New()
+	r.RegisterReserved("rpc.discover", router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(map[string]string{"openrpc": "1.3.2"}, req.ID)
+	}))
+	srv := NewServer(WithRouter(r))
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	defer clientConn.Close()
+
+	// The reserved notification is dropped, so the responses come in order
+	_, err := clientConn.Write([]byte(`{"jsonrpc":"2.0","method":"rpc.ping"}` + "\n" +
+		`{"jsonrpc":"2.0","id":1,"method":"rpc.discover"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"rpc.other"}` + "\n"))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(clientConn)
+	var discovered struct {
+		ID     int               `json:"id"`
+		Result map[string]string `json:"result"`
+	}
+	require.NoError(t, decoder.Decode(&discovered))
+	assert.Equal(t, 1, discovered.ID)
+	assert.Equal(t, "1.3.2", discovered.Result["openrpc"])
+
+	var rejected struct {
+		ID    int            `json:"id"`
+		Error *jsonrpc.Error `json:"error"`
+	}
+	require.NoError(t, decoder.Decode(&rejected))
+	assert.Equal(t, 2, rejected.ID)
+	require.NotNil(t, rejected.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeMethodNotFound, rejected.Error.Code)
+}
+
+func TestNewServer_TracesRouterMethods(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	r := router.New()
+	r.RegisterFunc("admin/ping", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse("pong", req.ID)
+	})
+	ws := NewWebSocket(HTTPConfig{Addr: "127.0.0.1:0"})
+	srv := NewServer(WithRouter(r), WithAsync(router.AsyncRouterConfig{Workers: 1}), WithTransports(ws))
+	require.NoError(t, srv.Start(context.Background()))
+	defer srv.Shutdown(context.Background())
+	assert.True(t, srv.Async().Stats().Running)
+
+	// Requests continue the trace of the upgrade request
+	config, err := websocket.NewConfig("ws://"+ws.(*webSocketTransport).Addr().String()+"/ws", "http://localhost:3000")
+	require.NoError(t, err)
+	config.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	conn, err := websocket.DialConfig(config)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"admin/ping"}`))
+	var response map[string]any
+	require.NoError(t, websocket.JSON.Receive(conn, &response))
+	assert.Equal(t, "pong", response["result"])
+
+	// The worker's span starts a trace linked to the caller's
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	async, caller := spans[0], spans[1]
+	assert.Equal(t, "admin/ping", caller.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", caller.SpanContext().TraceID().String())
+	assert.Equal(t, "admin/ping (async)", async.Name())
+	assert.NotEqual(t, caller.SpanContext().TraceID(), async.SpanContext().TraceID())
+	require.Len(t, async.Links(), 1)
+	assert.Equal(t, caller.SpanContext(), async.Links()[0].SpanContext)
+}
+
+func TestServer_HealthCheck(t *testing.T) {
+	socket := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	srv := NewServer(WithRouter(router.New()), WithAsync(router.AsyncRouterConfig{}), WithTransports(socket))
+	transports := health.TransportCheck(srv)
+	queue := health.QueueCheck(srv.Async(), 0.9)
+	assert.Equal(t, health.StatusDown, transports(context.Background()).Status)
+	assert.Equal(t, health.StatusDown, queue(context.Background()).Status)
+	assert.Nil(t, srv.UpstreamHealth())
+
+	require.NoError(t, srv.Start(context.Background()))
+	socket.(*socketTransport).Addr() // serving
+	assert.Equal(t, health.StatusOK, transports(context.Background()).Status)
+	assert.Equal(t, health.StatusOK, queue(context.Background()).Status)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	assert.Equal(t, map[string]transportpkg.HealthStatus{"unix": {ID: "unix"}}, srv.HealthCheck())
+}
+
+func TestNewServer_AdminMethods(t *testing.T) {
+	r := router.New()
+	r.RegisterFunc("admin/ping", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse("pong", req.ID)
+	})
+	r.RegisterFunc("status", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse("up", req.ID)
+	})
+	local := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	remote := NewSocket("tcp", "127.0.0.1:0")
+	srv := NewServer(WithRouter(r), WithTransports(local, remote))
+	api, err := admin.New(admin.Config{Auth: admin.AllowIdentities(LocalIdentity), Connections: srv.Connections()})
+	require.NoError(t, err)
+	api.Register(r)
+	srv.Use(api.DrainMiddleware())
+	require.NoError(t, srv.Start(context.Background()))
+	defer srv.Shutdown(context.Background())
+
+	call := func(transport Transport, method string) *jsonrpc.Response {
+		t.Helper()
+		addr := transport.(*socketTransport).Addr()
+		conn, err := net.Dial(addr.Network(), addr.String())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":1,"method":%q}`+"\n", method)
+		require.NoError(t, err)
+		var response jsonrpc.Response
+		require.NoError(t, json.NewDecoder(conn).Decode(&response))
+		return &response
+	}
+
+	// Only local clients may call admin methods
+	assert.Equal(t, "up", call(remote, "status").Result)
+	denied := call(remote, admin.MethodConnections)
+	require.NotNil(t, denied.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeUnauthorized, denied.Error.Code)
+	assert.Nil(t, call(local, admin.MethodConnections).Error)
+
+	// Draining rejects router requests outside the admin namespace
+	assert.Nil(t, call(local, admin.MethodDrain).Error)
+	rejected := call(remote, "status")
+	require.NotNil(t, rejected.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPServiceUnavail, rejected.Error.Code)
+}
+
+func TestNewServer_ProfilesLocalClients(t *testing.T) {
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+	profiler := profiling.New(profiling.Config{Authorize: admin.AllowIdentities(LocalIdentity)})
+	local := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	remote := NewSocket("tcp", "127.0.0.1:0")
+	srv := NewServer(
+		WithHandshake(config),
+		WithTransports(local, remote),
+		WithTools(tools.Config{Middleware: []mcpserver.ToolHandlerMiddleware{profiler.Middleware}}),
+	)
+	srv.Tools().MustRegister(tools.Definition{
+		Tool: mcpgo.NewTool("echo"),
+		Handler: func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+			return mcpgo.NewToolResultText("echo"), nil
+		},
+	})
+	require.NoError(t, srv.Start(context.Background()))
+	defer srv.Shutdown(context.Background())
+
+	profiled := func(socket Transport) bool {
+		t.Helper()
+		addr := socket.(*socketTransport).Addr()
+		conn, err := net.Dial(addr.Network(), addr.String())
+		require.NoError(t, err)
+		defer conn.Close()
+		c := connectClient(t, transport.NewIO(conn, conn, io.NopCloser(strings.NewReader(""))))
+		request := mcpgo.CallToolRequest{}
+		request.Params.Name = "echo"
+		request.Params.Meta = &mcpgo.Meta{AdditionalFields: map[string]any{profiling.MetaProfile: true}}
+		result, err := c.CallTool(context.Background(), request)
+		require.NoError(t, err)
+		_, ok := result.Meta[profiling.MetaProfile]
+		return ok
+	}
+
+	// Clients over the Unix socket are local; TCP clients are anonymous
+	assert.True(t, profiled(local))
+	assert.False(t, profiled(remote))
+	assert.Len(t, profiler.List(), 1)
+}
+
+func TestNewServer_ScopesReceiptsByIdentity(t *testing.T) {
+	config := mcp.DefaultHandshakeConfig()
+	config.SupportedVersions = mcpgo.ValidProtocolVersions
+	config.ServerOptions = []mcpserver.ServerOption{mcpserver.WithToolCapabilities(true)}
+	receipts := tools.NewReceiptStore(tools.ReceiptConfig{Scope: quota.DefaultIdentity})
+	local := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	remote := NewSocket("tcp", "127.0.0.1:0")
+	srv := NewServer(
+		WithHandshake(config),
+		WithTransports(local, remote),
+		WithTools(tools.Config{Receipts: receipts}),
+	)
+	var runs atomic.Int32
+	srv.Tools().MustRegister(tools.Definition{
+		Tool: mcpgo.NewTool("write"),
+		Handler: func(ctx context.Context, request mcpgo.CallToolRequest) (*mcpgo.CallToolResult, error) {
+			runs.Add(1)
+			return mcpgo.NewToolResultText("written"), nil
+		},
+	})
+	require.NoError(t, srv.Start(context.Background()))
+	defer srv.Shutdown(context.Background())
+
+	connect := func(socket Transport) *client.Client {
+		t.Helper()
+		addr := socket.(*socketTransport).Addr()
+		conn, err := net.Dial(addr.Network(), addr.String())
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return connectClient(t, transport.NewIO(conn, conn, io.NopCloser(strings.NewReader(""))))
+	}
+	call := func(c *client.Client, receipt string) (*mcpgo.CallToolResult, error) {
+		request := mcpgo.CallToolRequest{}
+		request.Params.Name = "write"
+		if receipt != "" {
+			request.Params.Meta = &mcpgo.Meta{AdditionalFields: map[string]any{tools.ReceiptMeta: receipt}}
+		}
+		return c.CallTool(context.Background(), request)
+	}
+	issue := func(c *client.Client) string {
+		t.Helper()
+		result, err := call(c, "")
+		require.NoError(t, err)
+		receipt, ok := result.Meta[tools.ReceiptMeta].(map[string]any)
+		require.True(t, ok)
+		return receipt["id"].(string)
+	}
+
+	// Anonymous clients only see the receipts of their own connection
+	first, second := connect(remote), connect(remote)
+	id := issue(first)
+	_, err := call(first, id)
+	assert.NoError(t, err)
+	_, err = call(second, id)
+	assert.Error(t, err)
+
+	// Local clients share theirs
+	id = issue(connect(local))
+	_, err = call(connect(local), id)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestServer_ReloadUnsupported(t *testing.T) {
+	srv := NewServer()
+	assert.ErrorIs(t, srv.Reload(context.Background()), ErrReloadUnsupported)
+	assert.Error(t, srv.Start(context.Background()), "no transports")
+	assert.NoError(t, srv.Shutdown(context.Background()))
+	assert.NoError(t, srv.Wait())
+}
+
+// drainNotice reads a notifications/message from decoder and returns its
+// drain notice
+func drainNotice(t *testing.T, decoder *json.Decoder) DrainNotice {
+	t.Helper()
+	var notification struct {
+		Method string `json:"method"`
+		Params struct {
+			Level string      `json:"level"`
+			Data  DrainNotice `json:"data"`
+		} `json:"params"`
+	}
+	require.NoError(t, decoder.Decode(&notification))
+	assert.Equal(t, mcp.MethodNotificationMessage, notification.Method)
+	assert.Equal(t, "warning", notification.Params.Level)
+	assert.Equal(t, DrainEvent, notification.Params.Data.Event)
+	return notification.Params.Data
+}
+
+func TestServer_DrainEndsWhenClientsLeave(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	s := New(newHandshakeServer(t), Config{DrainGrace: time.Minute, Clock: fake})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serve := func() (net.Conn, *json.Decoder) {
+		clientConn, serverConn := net.Pipe()
+		go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+		return clientConn, json.NewDecoder(clientConn)
+	}
+	first, firstDecoder := serve()
+	require.Eventually(t, func() bool {
+		n, _ := s.sessions.remaining()
+		return n == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	remaining := make(chan int, 1)
+	go func() { remaining <- s.Drain(ctx, "maintenance") }()
+	notice := drainNotice(t, firstDecoder)
+	assert.Equal(t, "maintenance", notice.Reason)
+	assert.Equal(t, int64(60000), notice.GraceMs)
+	assert.Equal(t, fake.Now().Add(time.Minute), notice.Deadline)
+
+	// Clients connecting while draining are told at once
+	second, secondDecoder := serve()
+	assert.Equal(t, "maintenance", drainNotice(t, secondDecoder).Reason)
+
+	first.Close()
+	second.Close()
+	select {
+	case n := <-remaining:
+		assert.Equal(t, 0, n, "draining ends before the grace period expires")
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not end when the clients left")
+	}
+}
+
+func TestServer_DrainDoesNotWaitForStalledClients(t *testing.T) {
+	s := New(newHandshakeServer(t), Config{DrainGrace: 100 * time.Millisecond, WriteTimeout: 0})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go s.ServeConn(ctx, "test", NewLineConn(serverConn, serverConn, serverConn))
+	require.Eventually(t, func() bool {
+		n, _ := s.sessions.remaining()
+		return n == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The client never reads its notice, so writing it blocks until the
+	// write times out
+	remaining := make(chan int, 1)
+	go func() { remaining <- s.Drain(ctx, "shutdown") }()
+	select {
+	case n := <-remaining:
+		assert.Equal(t, 1, n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain waited past the grace period for the notice to be written")
+	}
+}
+
+func TestServer_ShutdownClosesConnectionsAfterGrace(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	socket := NewSocket("unix", filepath.Join(t.TempDir(), "mcp.sock"))
+	srv := NewServer(
+		WithHandshake(mcp.DefaultHandshakeConfig()),
+		WithTransports(socket),
+		WithConfig(Config{DrainGrace: 10 * time.Second, Clock: fake}),
+	)
+	require.NoError(t, srv.Start(context.Background()))
+	conn, err := net.Dial("unix", socket.(*socketTransport).Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool {
+		n, _ := srv.sessions.remaining()
+		return n == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+	decoder := json.NewDecoder(conn)
+	notice := drainNotice(t, decoder)
+	assert.Equal(t, "shutdown", notice.Reason)
+	assert.Equal(t, int64(10000), notice.GraceMs)
+
+	// The client stays; its connection is kept open until the grace
+	// period expires
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown did not wait for the grace period")
+	case <-time.After(50 * time.Millisecond):
+	}
+	fake.Advance(10 * time.Second)
+	select {
+	case err := <-shutdown:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not end once the grace period expired")
+	}
+	var message json.RawMessage
+	assert.Error(t, decoder.Decode(&message), "the connection is closed")
+}
+
+func BenchmarkLineConn_Notifications(b *testing.B) {
+	message := []byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"t","progress":1}}`)
+	batch := make([][]byte, maxNotificationBatch)
+	for i := range batch {
+		batch[i] = message
+	}
+
+	for _, size := range []int{1, maxNotificationBatch} {
+		b.Run(fmt.Sprintf("batch%d", size), func(b *testing.B) {
+			r, w, err := os.Pipe()
+			require.NoError(b, err)
+			defer r.Close()
+			go io.Copy(io.Discard, r)
+			conn := NewLineConn(strings.NewReader(""), w, w).(batchConn)
+			defer w.Close()
+
+			b.SetBytes(int64(len(message) + 1))
+			b.ResetTimer()
+			for i := 0; i < b.N; i += size {
+				if err := conn.WriteMessages(batch[:size]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+)
+
+// socketTransport serves newline-delimited JSON-RPC to every client of a
+// stream listener
+type socketTransport struct {
+	network string
+	addr    string
+
+	mu       sync.Mutex
+	listener net.Listener
+	ready    chan struct{}
+}
+
+// NewSocket returns a transport listening on a Unix socket ("unix") or
+// TCP address ("tcp"). A stale Unix socket file is replaced; the new one
+// is only accessible to the current user.
+func NewSocket(network, addr string) Transport {
+	return &socketTransport{network: network, addr: addr, ready: make(chan struct{})}
+}
+
+func (t *socketTransport) Name() string { return t.network }
+
+// Addr returns the listening address once the transport is serving
+func (t *socketTransport) Addr() net.Addr {
+	<-t.ready
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listener == nil {
+		return nil
+	}
+	return t.listener.Addr()
+}
+
+func (t *socketTransport) Serve(ctx context.Context, s *Server) error {
+	listener, err := t.listen()
+	t.mu.Lock()
+	t.listener = listener
+	t.mu.Unlock()
+	close(t.ready)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	logger := logging.Default().WithComponent("server").WithField("transport", t.Name())
+	// TCP clients are not authenticated
+	clientCtx := ctx
+	if t.network == "unix" {
+		clientCtx = connection.WithIdentity(ctx, LocalIdentity)
+	}
+	var clients sync.WaitGroup
+	defer clients.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		clients.Add(1)
+		go func() {
+			defer clients.Done()
+			if err := s.ServeConn(clientCtx, t.Name(), NewLineConn(conn, conn, conn)); err != nil {
+				logger.Error(ctx, err, "Connection failed")
+			}
+		}()
+	}
+}
+
+// listen opens the listener, preparing the socket file for Unix sockets
+func (t *socketTransport) listen() (net.Listener, error) {
+	if t.network != "unix" {
+		return net.Listen(t.network, t.addr)
+	}
+
+	// Only replace a socket nobody is listening on
+	if _, err := os.Stat(t.addr); err == nil {
+		if conn, err := net.Dial("unix", t.addr); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use", t.addr)
+		}
+		if err := os.Remove(t.addr); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", t.addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(t.addr, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
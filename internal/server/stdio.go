@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+)
+
+// LocalIdentity is the identity of clients of the stdio transport and of
+// Unix sockets. Both only reach processes of the user running the server:
+// its parent, and whoever may open the socket file, which only its owner
+// can.
+const LocalIdentity = "local"
+
+// stdioTransport serves the single client on a pair of streams
+type stdioTransport struct {
+	in  io.Reader
+	out io.Writer
+}
+
+// Stdio returns a transport serving the parent process over stdin/stdout
+func Stdio() Transport {
+	return NewStdio(os.Stdin, os.Stdout)
+}
+
+// NewStdio returns a transport serving one client over in and out. It
+// stops when in reaches EOF.
+func NewStdio(in io.Reader, out io.Writer) Transport {
+	return &stdioTransport{in: in, out: out}
+}
+
+func (t *stdioTransport) Name() string { return "stdio" }
+
+func (t *stdioTransport) Serve(ctx context.Context, s *Server) error {
+	var closer io.Closer
+	if c, ok := t.in.(io.Closer); ok {
+		closer = c
+	}
+	return s.ServeConn(connection.WithIdentity(ctx, LocalIdentity), t.Name(), NewLineConn(t.in, t.out, closer))
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltOpenTimeout bounds how long OpenBolt waits for the file lock held by
+// another process
+const boltOpenTimeout = 5 * time.Second
+
+// BoltStore is a Store persisted to a BoltDB file. Each bucket maps to a
+// BoltDB bucket; writes are durable once the call returns.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates the BoltDB file at path
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("storage: open %s: %w", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Get returns the value of key, or ErrNotFound
+func (s *BoltStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrNotFound
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return ErrNotFound
+		}
+		value = clone(v)
+		return nil
+	})
+	return value, err
+}
+
+// Put sets the value of key
+func (s *BoltStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), nonNil(value))
+	})
+}
+
+// Delete removes key
+func (s *BoltStore) Delete(ctx context.Context, bucket, key string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// List returns the entries whose keys start with prefix, sorted by key
+func (s *BoltStore) List(ctx context.Context, bucket, prefix string) ([]Entry, error) {
+	var entries []Entry
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		p := []byte(prefix)
+		c := b.Cursor()
+		for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+			entries = append(entries, Entry{Key: string(k), Value: clone(v)})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Update atomically replaces the value of key with the result of fn
+func (s *BoltStore) Update(ctx context.Context, bucket, key string, fn UpdateFunc) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		current := b.Get([]byte(key))
+		value, err := fn(clone(current), current != nil)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			return b.Delete([]byte(key))
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+// Close closes the underlying file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// view runs fn in a read-only transaction
+func (s *BoltStore) view(fn func(tx *bolt.Tx) error) error {
+	return translateBoltError(s.db.View(fn))
+}
+
+// update runs fn in a read-write transaction
+func (s *BoltStore) update(fn func(tx *bolt.Tx) error) error {
+	return translateBoltError(s.db.Update(fn))
+}
+
+// translateBoltError maps BoltDB errors onto this package's errors
+func translateBoltError(err error) error {
+	if errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		return ErrClosed
+	}
+	return err
+}
+
+// nonNil returns an empty slice for nil, which BoltDB would otherwise reject
+func nonNil(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a Store held in process memory
+type MemoryStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+	closed  bool
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]map[string][]byte)}
+}
+
+// Get returns the value of key, or ErrNotFound
+func (m *MemoryStore) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+	value, ok := m.buckets[bucket][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(value), nil
+}
+
+// Put sets the value of key
+func (m *MemoryStore) Put(ctx context.Context, bucket, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	m.putLocked(bucket, key, value)
+	return nil
+}
+
+// Delete removes key
+func (m *MemoryStore) Delete(ctx context.Context, bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	delete(m.buckets[bucket], key)
+	return nil
+}
+
+// List returns the entries whose keys start with prefix, sorted by key
+func (m *MemoryStore) List(ctx context.Context, bucket, prefix string) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+	var entries []Entry
+	for key, value := range m.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) {
+			entries = append(entries, Entry{Key: key, Value: clone(value)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// Update atomically replaces the value of key with the result of fn
+func (m *MemoryStore) Update(ctx context.Context, bucket, key string, fn UpdateFunc) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrClosed
+	}
+	current, found := m.buckets[bucket][key]
+	value, err := fn(clone(current), found)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		delete(m.buckets[bucket], key)
+		return nil
+	}
+	m.putLocked(bucket, key, value)
+	return nil
+}
+
+// Close discards the store's contents
+func (m *MemoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.buckets = nil
+	return nil
+}
+
+// putLocked stores a copy of value. Caller must hold m.mu.
+func (m *MemoryStore) putLocked(bucket, key string, value []byte) {
+	b, ok := m.buckets[bucket]
+	if !ok {
+		b = make(map[string][]byte)
+		m.buckets[bucket] = b
+	}
+	b[key] = clone(value)
+}
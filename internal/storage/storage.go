@@ -0,0 +1,115 @@
+// Package storage provides a small bucketed key/value store for server-side
+// state that must survive restarts, such as quota ledgers, sessions, audit
+// buffers and workflow progress.
+//
+// Values are opaque bytes grouped into named buckets; GetJSON and PutJSON
+// layer a document API on top. Two implementations are provided: an
+// in-memory store for tests and ephemeral deployments, and a BoltDB store
+// backed by a single file.
+//
+// Basic usage:
+//
+//	store, err := storage.Open("bolt:///var/lib/meta-mcp/state.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//
+//	err = storage.PutJSON(ctx, store, "sessions", id, session)
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when a key does not exist
+	ErrNotFound = errors.New("storage: key not found")
+
+	// ErrClosed is returned by operations on a closed store
+	ErrClosed = errors.New("storage: store closed")
+)
+
+// Entry is a key and its value
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// UpdateFunc computes the new value of a key from its current value. found
+// is false if the key does not exist. Returning a nil value deletes the key;
+// returning an error aborts the update.
+type UpdateFunc func(value []byte, found bool) ([]byte, error)
+
+// Store is a bucketed key/value store. Buckets are created on first write.
+// Implementations must be safe for concurrent use and must not retain or
+// share the byte slices passed to or returned from them.
+type Store interface {
+	// Get returns the value of key, or ErrNotFound
+	Get(ctx context.Context, bucket, key string) ([]byte, error)
+
+	// Put sets the value of key
+	Put(ctx context.Context, bucket, key string, value []byte) error
+
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, bucket, key string) error
+
+	// List returns the entries whose keys start with prefix, sorted by key
+	List(ctx context.Context, bucket, prefix string) ([]Entry, error)
+
+	// Update atomically replaces the value of key with the result of fn
+	Update(ctx context.Context, bucket, key string, fn UpdateFunc) error
+
+	// Close releases the store's resources
+	Close() error
+}
+
+// Open opens a store from a DSN: "memory://" (or empty) for an in-memory
+// store, "bolt://<path>" for a BoltDB file
+func Open(dsn string) (Store, error) {
+	scheme, path, _ := strings.Cut(dsn, "://")
+	switch scheme {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "bolt":
+		if path == "" {
+			return nil, fmt.Errorf("storage: bolt DSN %q has no path", dsn)
+		}
+		return OpenBolt(path)
+	default:
+		return nil, fmt.Errorf("storage: unsupported DSN %q", dsn)
+	}
+}
+
+// GetJSON decodes the value of key into v
+func GetJSON(ctx context.Context, s Store, bucket, key string, v any) error {
+	data, err := s.Get(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("storage: decode %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// PutJSON stores v encoded as JSON under key
+func PutJSON(ctx context.Context, s Store, bucket, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("storage: encode %s/%s: %w", bucket, key, err)
+	}
+	return s.Put(ctx, bucket, key, data)
+}
+
+// clone copies b so callers cannot alias stored data
+func clone(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
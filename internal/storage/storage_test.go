@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stores returns a fresh instance of every implementation
+func stores(t *testing.T) map[string]Store {
+	bolt, err := OpenBolt(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"bolt":   bolt,
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+
+			_, err := s.Get(ctx, "sessions", "a")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			value := []byte("one")
+			require.NoError(t, s.Put(ctx, "sessions", "a", value))
+			value[0] = 'X'
+			got, err := s.Get(ctx, "sessions", "a")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("one"), got)
+
+			require.NoError(t, s.Put(ctx, "sessions", "b:1", []byte("two")))
+			require.NoError(t, s.Put(ctx, "sessions", "b:0", []byte("three")))
+			require.NoError(t, s.Put(ctx, "quota", "b:2", []byte("other bucket")))
+
+			entries, err := s.List(ctx, "sessions", "b:")
+			require.NoError(t, err)
+			assert.Equal(t, []Entry{{Key: "b:0", Value: []byte("three")}, {Key: "b:1", Value: []byte("two")}}, entries)
+
+			entries, err = s.List(ctx, "missing", "")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			require.NoError(t, s.Delete(ctx, "sessions", "a"))
+			require.NoError(t, s.Delete(ctx, "sessions", "a"))
+			require.NoError(t, s.Delete(ctx, "missing", "a"))
+			_, err = s.Get(ctx, "sessions", "a")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, s.Close())
+			_, err = s.Get(ctx, "sessions", "b:0")
+			assert.ErrorIs(t, err, ErrClosed)
+		})
+	}
+}
+
+func TestStore_Update(t *testing.T) {
+	ctx := context.Background()
+
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			defer s.Close()
+
+			increment := func(value []byte, found bool) ([]byte, error) {
+				if !found {
+					return []byte{1}, nil
+				}
+				return []byte{value[0] + 1}, nil
+			}
+
+			var wg sync.WaitGroup
+			for range 20 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					assert.NoError(t, s.Update(ctx, "counters", "calls", increment))
+				}()
+			}
+			wg.Wait()
+
+			got, err := s.Get(ctx, "counters", "calls")
+			require.NoError(t, err)
+			assert.Equal(t, []byte{20}, got)
+
+			abort := errors.New("abort")
+			err = s.Update(ctx, "counters", "calls", func([]byte, bool) ([]byte, error) { return nil, abort })
+			assert.ErrorIs(t, err, abort)
+			got, _ = s.Get(ctx, "counters", "calls")
+			assert.Equal(t, []byte{20}, got)
+
+			require.NoError(t, s.Update(ctx, "counters", "calls", func([]byte, bool) ([]byte, error) { return nil, nil }))
+			_, err = s.Get(ctx, "counters", "calls")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	type session struct {
+		ID    string `json:"id"`
+		Turns int    `json:"turns"`
+	}
+
+	require.NoError(t, PutJSON(ctx, s, "sessions", "s1", session{ID: "s1", Turns: 3}))
+	var got session
+	require.NoError(t, GetJSON(ctx, s, "sessions", "s1", &got))
+	assert.Equal(t, session{ID: "s1", Turns: 3}, got)
+
+	assert.ErrorIs(t, GetJSON(ctx, s, "sessions", "s2", &got), ErrNotFound)
+
+	require.NoError(t, s.Put(ctx, "sessions", "bad", []byte("{")))
+	assert.Error(t, GetJSON(ctx, s, "sessions", "bad", &got))
+}
+
+func TestOpen(t *testing.T) {
+	s, err := Open("")
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, s)
+
+	path := filepath.Join(t.TempDir(), "state.db")
+	s, err = Open("bolt://" + path)
+	require.NoError(t, err)
+	require.NoError(t, s.Put(context.Background(), "b", "k", []byte("v")))
+	require.NoError(t, s.Close())
+
+	// Data survives reopening
+	s, err = Open("bolt://" + path)
+	require.NoError(t, err)
+	defer s.Close()
+	got, err := s.Get(context.Background(), "b", "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v"), got)
+
+	_, err = Open("bolt://")
+	assert.Error(t, err)
+	_, err = Open("redis://localhost")
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+// Package dispatch benchmarks the JSON-RPC protocol path end to end: parse,
+// route, handle and marshal. TestAllocationBudgets fails when a change adds
+// allocations to that path.
+package dispatch
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// Wire messages of the benchmarked calls
+var (
+	callRaw     = []byte(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"}},"id":1}`)
+	notifyRaw   = []byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":1}}`)
+	notFoundRaw = []byte(`{"jsonrpc":"2.0","method":"no/such/method","id":1}`)
+)
+
+// result is the preallocated result of the echo handler, so the budgets
+// measure the protocol path rather than the handler
+var result = map[string]any{"content": []any{map[string]any{"type": "text", "text": "hi"}}}
+
+// newRouter returns a router with the benchmarked methods registered
+func newRouter() *router.Router {
+	r := router.New()
+	r.RegisterFunc("tools/call", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return jsonrpc.NewResponse(result, req.ID)
+	})
+	r.RegisterNotificationFunc("notifications/progress", func(ctx context.Context, notif *jsonrpc.Notification) {})
+	return r
+}
+
+// dispatch runs one message through the protocol path the way a transport
+// does, releasing the pooled messages afterwards
+func dispatch(ctx context.Context, r *router.Router, w io.Writer, raw []byte) error {
+	msg, err := jsonrpc.ParseMessage(raw)
+	if err != nil {
+		return err
+	}
+	defer jsonrpc.ReleaseMessage(msg)
+
+	switch m := msg.(type) {
+	case *jsonrpc.Request:
+		resp := r.Handle(ctx, m)
+		defer jsonrpc.ReleaseResponse(resp)
+		return jsonrpc.Encode(w, resp)
+	case *jsonrpc.Notification:
+		r.HandleNotification(ctx, m)
+	}
+	return nil
+}
+
+// budget is the most allocations one run of a stage may make
+type budget struct {
+	name   string
+	allocs float64
+	run    func() error
+}
+
+// budgets lists the allocation budget of each stage. Lower a budget when an
+// optimization lands; raise one only with a reason in the commit message.
+func budgets() []budget {
+	ctx := context.Background()
+	r := newRouter()
+	req := jsonrpc.NewRequest("tools/call", nil, 1)
+	resp := jsonrpc.NewResponse(result, 1)
+	static := router.New()
+	static.RegisterFunc("tools/call", func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		return resp
+	})
+
+	return []budget{
+		{"route", 0, func() error {
+			static.Handle(ctx, req)
+			return nil
+		}},
+		{"parse", 16, func() error {
+			msg, err := jsonrpc.ParseMessage(callRaw)
+			jsonrpc.ReleaseMessage(msg)
+			return err
+		}},
+		{"encode", 3, func() error {
+			return jsonrpc.Encode(io.Discard, resp)
+		}},
+		{"dispatch request", 18, func() error {
+			return dispatch(ctx, r, io.Discard, callRaw)
+		}},
+		{"dispatch notification", 7, func() error {
+			return dispatch(ctx, r, io.Discard, notifyRaw)
+		}},
+		{"dispatch not found", 6, func() error {
+			return dispatch(ctx, r, io.Discard, notFoundRaw)
+		}},
+	}
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector changes allocation counts")
+	}
+	for _, b := range budgets() {
+		t.Run(b.name, func(t *testing.T) {
+			if err := b.run(); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			allocs := testing.AllocsPerRun(100, func() {
+				if e := b.run(); e != nil {
+					err = e
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("%.0f allocs/op (budget %.0f)", allocs, b.allocs)
+			if allocs > b.allocs {
+				t.Errorf("%.0f allocs/op exceeds the budget of %.0f", allocs, b.allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkDispatch(b *testing.B) {
+	ctx := context.Background()
+	r := newRouter()
+	for _, bc := range []struct {
+		name string
+		raw  []byte
+	}{
+		{"request", callRaw},
+		{"notification", notifyRaw},
+		{"not found", notFoundRaw},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bc.raw)))
+			for i := 0; i < b.N; i++ {
+				if err := dispatch(ctx, r, io.Discard, bc.raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDispatchParallel(b *testing.B) {
+	ctx := context.Background()
+	r := newRouter()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := dispatch(ctx, r, io.Discard, callRaw); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkStages(b *testing.B) {
+	for _, bg := range budgets() {
+		b.Run(bg.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := bg.run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
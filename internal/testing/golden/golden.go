@@ -0,0 +1,211 @@
+// Package golden provides snapshot assertions for wire messages.
+//
+// A message is marshaled with stable member ordering, volatile values are
+// replaced with placeholders, and the result is compared with a golden
+// file under testdata/golden. Run the tests with -update (or with
+// UPDATE_GOLDEN=1) to write the golden files instead:
+//
+//	golden.AssertMessage(t, "initialize_response", response,
+//		golden.Redact("result.serverInfo.version"))
+//
+//	go test ./internal/server -run TestInitialize -update
+//
+// Strings that parse as RFC 3339 timestamps or UUIDs are redacted
+// automatically, so records carrying times and generated IDs compare
+// equal across runs.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files instead of comparing against them")
+
+// Placeholders that replace volatile values in golden files.
+const (
+	// TimestampPlaceholder replaces RFC 3339 timestamps.
+	TimestampPlaceholder = "<timestamp>"
+
+	// UUIDPlaceholder replaces UUIDs.
+	UUIDPlaceholder = "<uuid>"
+
+	// RedactedPlaceholder replaces values at paths given to Redact.
+	RedactedPlaceholder = "<redacted>"
+)
+
+// DefaultDir is the directory golden files are kept in, relative to the
+// package under test.
+const DefaultDir = "testdata/golden"
+
+// Option configures an assertion.
+type Option func(*options)
+
+type options struct {
+	dir        string
+	redact     [][]string
+	autoRedact bool
+}
+
+// Redact replaces the values at dotted paths, such as "result.sessionId"
+// or "params.items.*.createdAt", with RedactedPlaceholder. A "*" segment
+// matches any object member or array element.
+func Redact(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			o.redact = append(o.redact, strings.Split(path, "."))
+		}
+	}
+}
+
+// Dir keeps the golden files in dir instead of DefaultDir.
+func Dir(dir string) Option {
+	return func(o *options) { o.dir = dir }
+}
+
+// KeepVolatile disables the automatic redaction of timestamps and UUIDs.
+func KeepVolatile() Option {
+	return func(o *options) { o.autoRedact = false }
+}
+
+// Updating reports whether golden files are being rewritten.
+func Updating() bool {
+	return *update || os.Getenv("UPDATE_GOLDEN") != ""
+}
+
+// AssertMessage compares msg with the golden file name.json.
+func AssertMessage(t testing.TB, name string, msg jsonrpc.Message, opts ...Option) {
+	t.Helper()
+	data, err := jsonrpc.MarshalStable(msg)
+	require.NoError(t, err, "marshal message")
+	AssertJSON(t, name, data, opts...)
+}
+
+// AssertMessages compares a sequence of messages, such as everything a
+// server wrote on a connection, with the golden file name.json.
+func AssertMessages(t testing.TB, name string, msgs []jsonrpc.Message, opts ...Option) {
+	t.Helper()
+	encoded := make([]json.RawMessage, len(msgs))
+	for i, msg := range msgs {
+		data, err := jsonrpc.MarshalStable(msg)
+		require.NoError(t, err, "marshal message %d", i)
+		encoded[i] = data
+	}
+	data, err := json.Marshal(encoded)
+	require.NoError(t, err)
+	AssertJSON(t, name, data, opts...)
+}
+
+// AssertJSON compares a JSON document with the golden file name.json.
+func AssertJSON(t testing.TB, name string, data []byte, opts ...Option) {
+	t.Helper()
+	o := options{dir: DefaultDir, autoRedact: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	got, err := normalize(data, o)
+	require.NoError(t, err, "normalize %s", name)
+
+	path := filepath.Join(o.dir, name+".json")
+	if Updating() {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist; run the test with -update to create it", path)
+	}
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "%s differs; run the test with -update to accept the change", path)
+}
+
+// normalize returns the golden form of a JSON document: indented, with
+// object members sorted and volatile values redacted.
+func normalize(data []byte, o options) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	for _, path := range o.redact {
+		v = redactPath(v, path)
+	}
+	if o.autoRedact {
+		v = redactVolatile(v)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// redactPath replaces the values at path in a decoded JSON value.
+func redactPath(v any, path []string) any {
+	if len(path) == 0 {
+		return RedactedPlaceholder
+	}
+	key, rest := path[0], path[1:]
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if key == "*" || k == key {
+				node[k] = redactPath(child, rest)
+			}
+		}
+	case []any:
+		for i, child := range node {
+			if key == "*" || key == strconv.Itoa(i) {
+				node[i] = redactPath(child, rest)
+			}
+		}
+	}
+	return v
+}
+
+// redactVolatile replaces timestamps and UUIDs anywhere in a decoded JSON
+// value.
+func redactVolatile(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			node[k] = redactVolatile(child)
+		}
+	case []any:
+		for i, child := range node {
+			node[i] = redactVolatile(child)
+		}
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, node); err == nil {
+			return TimestampPlaceholder
+		}
+		if len(node) == 36 {
+			if _, err := uuid.Parse(node); err == nil {
+				return UUIDPlaceholder
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,86 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestNormalize(t *testing.T) {
+	data := []byte(`{"z":1,"a":{"time":"2026-10-16T15:42:46.123Z","id":"8b3e0c1e-9a4f-4b9e-8f0e-2f1c3d4e5f60","token":"s3cret"},"items":[{"at":3},{"at":4}],"html":"<b>"}`)
+
+	got, err := normalize(data, options{autoRedact: true, redact: [][]string{{"a", "token"}, {"items", "*", "at"}}})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "a": {
+    "id": "<uuid>",
+    "time": "<timestamp>",
+    "token": "<redacted>"
+  },
+  "html": "<b>",
+  "items": [
+    {
+      "at": "<redacted>"
+    },
+    {
+      "at": "<redacted>"
+    }
+  ],
+  "z": 1
+}
+`, string(got))
+
+	got, err = normalize([]byte(`{"time":"2026-10-16T15:42:46Z"}`), options{})
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "2026-10-16T15:42:46Z")
+}
+
+func TestAssertMessage_Update(t *testing.T) {
+	dir := t.TempDir()
+	msg := jsonrpc.NewRequest("tools/call", map[string]any{"name": "echo", "arguments": map[string]any{"b": 2, "a": 1}}, 1)
+
+	*update = true
+	AssertMessage(t, "request", msg, Dir(dir))
+	*update = false
+
+	data, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "id": 1,
+  "jsonrpc": "2.0",
+  "method": "tools/call",
+  "params": {
+    "arguments": {
+      "a": 1,
+      "b": 2
+    },
+    "name": "echo"
+  }
+}
+`, string(data))
+
+	// The same message in a different member order still matches
+	AssertJSON(t, "request", []byte(`{"params":{"name":"echo","arguments":{"a":1,"b":2}},"method":"tools/call","jsonrpc":"2.0","id":1}`), Dir(dir))
+}
+
+func TestAssertMessages(t *testing.T) {
+	AssertMessages(t, "exchange", []jsonrpc.Message{
+		jsonrpc.NewRequest("ping", nil, "req-1"),
+		jsonrpc.NewResponse(map[string]any{}, "req-1"),
+		jsonrpc.NewErrorResponse(jsonrpc.NewMethodNotFoundError("nope"), 2),
+	})
+}
+
+func TestAssertJSON_Mismatch(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.json"), []byte("{\n  \"a\": 1\n}\n"), 0o644))
+
+	mock := &testing.T{}
+	AssertJSON(mock, "doc", []byte(`{"a":2}`), Dir(dir))
+	assert.True(t, mock.Failed())
+}
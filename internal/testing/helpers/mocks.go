@@ -2,6 +2,7 @@
 package helpers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,9 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/chaos"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 )
 
 // MockTransport provides a mock implementation of MCP transport
@@ -26,7 +30,47 @@ type MockTransport struct {
 	failAfterCount int
 	sendCount      int
 	receiveCount   int
+	partialWrite   int
+	partialRead    int
+	scripts        map[string]*script
+	awaited        map[int]bool
+	changed        chan struct{}
+	faulty         chaos.Conn
 	t              *testing.T
+
+	// sendMu and receiveMu serialize faulted sends and receives, so the
+	// fault injector reaches the caller's context through sendCtx and
+	// receiveCtx
+	sendMu     sync.Mutex
+	sendCtx    context.Context
+	receiveMu  sync.Mutex
+	receiveCtx context.Context
+}
+
+// Reply is a scripted reply to a request sent through a MockTransport
+type Reply struct {
+	// Result or Error form a response with the request's ID; a reply with
+	// neither has an empty result
+	Result interface{}
+	Error  *jsonrpc.Error
+
+	// Raw, when set, is queued verbatim instead
+	Raw []byte
+}
+
+// script is a sequence of replies, of which the last repeats
+type script struct {
+	replies []Reply
+	next    int
+}
+
+// reply returns the next reply in the script
+func (s *script) reply() Reply {
+	reply := s.replies[s.next]
+	if s.next < len(s.replies)-1 {
+		s.next++
+	}
+	return reply
 }
 
 // NewMockTransport creates a new mock transport
@@ -35,15 +79,53 @@ func NewMockTransport(t *testing.T) *MockTransport {
 		t:            t,
 		sentMessages: make([][]byte, 0),
 		receiveQueue: make([][]byte, 0),
+		scripts:      make(map[string]*script),
+		awaited:      make(map[int]bool),
+		changed:      make(chan struct{}),
 	}
 }
 
-// Send sends a message through the transport
-func (mt *MockTransport) Send(data []byte) error {
+// SetFaults injects faults into the messages sent and received through the
+// transport; Inbound applies to Receive and Outbound to Send
+func (mt *MockTransport) SetFaults(faults chaos.Faults) {
 	mt.mu.Lock()
 	defer mt.mu.Unlock()
+	mt.faulty = chaos.WrapConn(mockConn{mt}, faults)
+}
+
+// mockConn exposes the unfaulted transport to the fault injector
+type mockConn struct {
+	mt *MockTransport
+}
+
+func (c mockConn) ReadMessage() ([]byte, error)   { return c.mt.receive(c.mt.receiveCtx) }
+func (c mockConn) WriteMessage(data []byte) error { return c.mt.send(c.mt.sendCtx, data) }
+func (c mockConn) Close() error                   { return c.mt.Close() }
+
+// Send sends a message through the transport. A request matching a script
+// queues the scripted reply for Receive.
+func (mt *MockTransport) Send(ctx context.Context, data []byte) error {
+	mt.mu.RLock()
+	faulty := mt.faulty
+	mt.mu.RUnlock()
+	if faulty != nil {
+		mt.sendMu.Lock()
+		defer mt.sendMu.Unlock()
+		mt.sendCtx = ctx
+		return faulty.WriteMessage(data)
+	}
+	return mt.send(ctx, data)
+}
+
+// send sends a message without injected faults
+func (mt *MockTransport) send(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
+	mt.mu.Lock()
 	if mt.closed {
+		mt.mu.Unlock()
 		return errors.New("transport closed")
 	}
 
@@ -51,12 +133,25 @@ func (mt *MockTransport) Send(data []byte) error {
 
 	// Check if we should fail
 	if mt.failAfterCount > 0 && mt.sendCount > mt.failAfterCount {
+		mt.mu.Unlock()
 		return errors.New("send failure triggered")
 	}
+	delay := mt.writeDelay
+	mt.mu.Unlock()
 
 	// Apply write delay
-	if mt.writeDelay > 0 {
-		time.Sleep(mt.writeDelay)
+	if err := sleep(ctx, delay); err != nil {
+		return err
+	}
+
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	defer mt.broadcast()
+
+	// A partial write stores only the start of the message
+	if mt.partialWrite > 0 && len(data) > mt.partialWrite {
+		mt.sentMessages = append(mt.sentMessages, append([]byte(nil), data[:mt.partialWrite]...))
+		return io.ErrShortWrite
 	}
 
 	// Store sent message
@@ -64,6 +159,10 @@ func (mt *MockTransport) Send(data []byte) error {
 	copy(msgCopy, data)
 	mt.sentMessages = append(mt.sentMessages, msgCopy)
 
+	if reply, ok := mt.scriptedReply(msgCopy); ok {
+		mt.receiveQueue = append(mt.receiveQueue, reply)
+	}
+
 	// Call custom handler if set
 	if mt.onSend != nil {
 		return mt.onSend(data)
@@ -72,35 +171,71 @@ func (mt *MockTransport) Send(data []byte) error {
 	return nil
 }
 
-// Receive receives a message from the transport
-func (mt *MockTransport) Receive() ([]byte, error) {
-	mt.mu.Lock()
-	defer mt.mu.Unlock()
+// Receive receives a message from the transport, waiting until one is
+// queued, the transport is closed (io.EOF) or ctx is done
+func (mt *MockTransport) Receive(ctx context.Context) ([]byte, error) {
+	mt.mu.RLock()
+	faulty := mt.faulty
+	mt.mu.RUnlock()
+	if faulty != nil {
+		mt.receiveMu.Lock()
+		defer mt.receiveMu.Unlock()
+		mt.receiveCtx = ctx
+		return faulty.ReadMessage()
+	}
+	return mt.receive(ctx)
+}
 
+// receive receives a message without injected faults
+func (mt *MockTransport) receive(ctx context.Context) ([]byte, error) {
+	mt.mu.Lock()
 	if mt.closed {
+		mt.mu.Unlock()
 		return nil, io.EOF
 	}
 
 	mt.receiveCount++
+	delay, onReceive := mt.readDelay, mt.onReceive
+	mt.mu.Unlock()
 
 	// Apply read delay
-	if mt.readDelay > 0 {
-		time.Sleep(mt.readDelay)
+	if err := sleep(ctx, delay); err != nil {
+		return nil, err
 	}
 
 	// Call custom handler if set
-	if mt.onReceive != nil {
-		return mt.onReceive()
+	if onReceive != nil {
+		return onReceive()
 	}
 
 	// Return from queue
-	if len(mt.receiveQueue) > 0 {
-		msg := mt.receiveQueue[0]
-		mt.receiveQueue = mt.receiveQueue[1:]
-		return msg, nil
+	for {
+		mt.mu.Lock()
+		if len(mt.receiveQueue) > 0 {
+			msg := mt.receiveQueue[0]
+			mt.receiveQueue = mt.receiveQueue[1:]
+
+			// A partial read leaves the rest of the message queued
+			if mt.partialRead > 0 && len(msg) > mt.partialRead {
+				mt.receiveQueue = append([][]byte{msg[mt.partialRead:]}, mt.receiveQueue...)
+				msg = msg[:mt.partialRead]
+			}
+			mt.mu.Unlock()
+			return msg, nil
+		}
+		if mt.closed {
+			mt.mu.Unlock()
+			return nil, io.EOF
+		}
+		changed := mt.changedChan()
+		mt.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
-
-	return nil, io.EOF
 }
 
 // Close closes the transport
@@ -113,6 +248,7 @@ func (mt *MockTransport) Close() error {
 	}
 
 	mt.closed = true
+	mt.broadcast()
 	return mt.closeErr
 }
 
@@ -124,6 +260,7 @@ func (mt *MockTransport) QueueReceive(data []byte) {
 	msgCopy := make([]byte, len(data))
 	copy(msgCopy, data)
 	mt.receiveQueue = append(mt.receiveQueue, msgCopy)
+	mt.broadcast()
 }
 
 // QueueJSON adds a JSON message to the receive queue
@@ -136,6 +273,124 @@ func (mt *MockTransport) QueueJSON(v interface{}) error {
 	return nil
 }
 
+// RespondTo scripts the replies to requests for method. Each request
+// sent takes the next reply, and the last one repeats.
+func (mt *MockTransport) RespondTo(method string, replies ...Reply) {
+	mt.setScript("method "+method, replies)
+}
+
+// RespondToID scripts the replies to requests with the given ID. They take
+// precedence over replies scripted for the request's method.
+func (mt *MockTransport) RespondToID(id interface{}, replies ...Reply) {
+	data, err := json.Marshal(id)
+	if err != nil {
+		mt.t.Fatalf("invalid request ID %v: %v", id, err)
+	}
+	mt.setScript("id "+string(data), replies)
+}
+
+// setScript sets or, with no replies, removes a script
+func (mt *MockTransport) setScript(key string, replies []Reply) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if mt.scripts == nil {
+		mt.scripts = make(map[string]*script)
+	}
+	if len(replies) == 0 {
+		delete(mt.scripts, key)
+		return
+	}
+	mt.scripts[key] = &script{replies: replies}
+}
+
+// messageHeader contains the routing members of a JSON-RPC message
+type messageHeader struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// scriptedReply returns the scripted reply to a sent message, if any; the
+// caller holds mt.mu
+func (mt *MockTransport) scriptedReply(data []byte) ([]byte, bool) {
+	var header messageHeader
+	if json.Unmarshal(data, &header) != nil || header.Method == "" || len(header.ID) == 0 || string(header.ID) == "null" {
+		return nil, false
+	}
+	var id bytes.Buffer
+	if json.Compact(&id, header.ID) != nil {
+		return nil, false
+	}
+	s, ok := mt.scripts["id "+id.String()]
+	if !ok {
+		s, ok = mt.scripts["method "+header.Method]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	reply := s.reply()
+	if reply.Raw != nil {
+		return append([]byte(nil), reply.Raw...), true
+	}
+	response := &jsonrpc.Response{Version: jsonrpc.Version, Result: reply.Result, Error: reply.Error, ID: json.RawMessage(id.Bytes())}
+	if reply.Error == nil && reply.Result == nil {
+		response.Result = map[string]interface{}{}
+	}
+	encoded, err := jsonrpc.Marshal(response)
+	if err != nil {
+		mt.t.Errorf("invalid scripted reply to %s: %v", header.Method, err)
+		return nil, false
+	}
+	return encoded, true
+}
+
+// AwaitSend waits until a message with the given method is sent and
+// returns it. Each sent message is returned by one AwaitSend or ExpectSend
+// only, in the order they were sent.
+func (mt *MockTransport) AwaitSend(ctx context.Context, method string) ([]byte, error) {
+	for {
+		mt.mu.Lock()
+		if mt.awaited == nil {
+			mt.awaited = make(map[int]bool)
+		}
+		for i, msg := range mt.sentMessages {
+			var header messageHeader
+			if mt.awaited[i] || json.Unmarshal(msg, &header) != nil || header.Method != method {
+				continue
+			}
+			mt.awaited[i] = true
+			mt.mu.Unlock()
+			return append([]byte(nil), msg...), nil
+		}
+		if mt.closed {
+			mt.mu.Unlock()
+			return nil, errors.New("transport closed")
+		}
+		changed := mt.changedChan()
+		mt.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// ExpectSend waits up to timeout for a message with the given method to be
+// sent and returns it, failing the test if none is
+func (mt *MockTransport) ExpectSend(method string, timeout time.Duration) []byte {
+	mt.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	msg, err := mt.AwaitSend(ctx, method)
+	if err != nil {
+		mt.t.Fatalf("expected %s to be sent within %s: %v", method, timeout, err)
+	}
+	return msg
+}
+
 // GetSentMessages returns all sent messages
 func (mt *MockTransport) GetSentMessages() [][]byte {
 	mt.mu.RLock()
@@ -171,6 +426,7 @@ func (mt *MockTransport) ClearSent() {
 	defer mt.mu.Unlock()
 
 	mt.sentMessages = make([][]byte, 0)
+	mt.awaited = make(map[int]bool)
 }
 
 // SetOnSend sets a custom send handler
@@ -201,6 +457,22 @@ func (mt *MockTransport) SetWriteDelay(delay time.Duration) {
 	mt.writeDelay = delay
 }
 
+// SetPartialWrites makes each Send store at most n bytes of its message
+// and fail with io.ErrShortWrite when the message is longer; 0 disables
+func (mt *MockTransport) SetPartialWrites(n int) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.partialWrite = n
+}
+
+// SetPartialReads makes each Receive return at most n bytes, leaving the
+// rest of the message for the next Receive; 0 disables
+func (mt *MockTransport) SetPartialReads(n int) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.partialRead = n
+}
+
 // SetFailAfterCount sets the transport to fail after n operations
 func (mt *MockTransport) SetFailAfterCount(count int) {
 	mt.mu.Lock()
@@ -222,6 +494,38 @@ func (mt *MockTransport) IsClosed() bool {
 	return mt.closed
 }
 
+// changedChan returns the channel closed on the next change; the caller
+// holds mt.mu
+func (mt *MockTransport) changedChan() chan struct{} {
+	if mt.changed == nil {
+		mt.changed = make(chan struct{})
+	}
+	return mt.changed
+}
+
+// broadcast wakes goroutines waiting for a change; the caller holds mt.mu
+func (mt *MockTransport) broadcast() {
+	if mt.changed != nil {
+		close(mt.changed)
+	}
+	mt.changed = make(chan struct{})
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // MockHandler provides a mock implementation of request handlers
 type MockHandler struct {
 	mu             sync.RWMutex
@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/chaos"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+func TestMockTransport_ScriptedReplies(t *testing.T) {
+	mt := NewMockTransport(t)
+	mt.RespondTo("tools/list", Reply{Result: map[string]any{"tools": []any{}}}, Reply{Error: jsonrpc.NewInternalError(nil)})
+	mt.RespondToID("special", Reply{Raw: []byte(`{"jsonrpc":"2.0","id":"special","result":"raw"}`)})
+	ctx := context.Background()
+
+	// Replies are taken in order and the last one repeats
+	for id, want := range []string{
+		`{"jsonrpc":"2.0","result":{"tools":[]},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":1}`,
+		`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":2}`,
+	} {
+		require.NoError(t, mt.Send(ctx, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"tools/list","id":%d}`, id))))
+		got, err := mt.Receive(ctx)
+		require.NoError(t, err)
+		assert.JSONEq(t, want, string(got))
+	}
+
+	// An ID script takes precedence over the method's
+	require.NoError(t, mt.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"tools/list","id":"special"}`)))
+	got, err := mt.Receive(ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"special","result":"raw"}`, string(got))
+
+	// Notifications and unscripted requests get no reply
+	require.NoError(t, mt.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"tools/list"}`)))
+	require.NoError(t, mt.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"ping","id":9}`)))
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = mt.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMockTransport_ReceiveWaits(t *testing.T) {
+	mt := NewMockTransport(t)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		mt.QueueReceive([]byte(`{"jsonrpc":"2.0","method":"late"}`))
+	}()
+	got, err := mt.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "late")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		mt.Close()
+	}()
+	_, err = mt.Receive(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMockTransport_ContextCancelsDelays(t *testing.T) {
+	mt := NewMockTransport(t)
+	mt.SetWriteDelay(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, mt.Send(ctx, []byte(`{}`)), context.Canceled)
+	assert.Empty(t, mt.GetSentMessages())
+}
+
+func TestMockTransport_ExpectSend(t *testing.T) {
+	mt := NewMockTransport(t)
+
+	go func() {
+		ctx := context.Background()
+		mt.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+		time.Sleep(10 * time.Millisecond)
+		mt.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"tools/call","id":1}`))
+		mt.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"tools/call","id":2}`))
+	}()
+
+	assert.Contains(t, string(mt.ExpectSend("tools/call", time.Second)), `"id":1`)
+	assert.Contains(t, string(mt.ExpectSend("tools/call", time.Second)), `"id":2`)
+	mt.ExpectSend("notifications/initialized", time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := mt.AwaitSend(ctx, "tools/call")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestMockTransport_PartialWritesAndReads(t *testing.T) {
+	mt := NewMockTransport(t)
+	ctx := context.Background()
+
+	mt.SetPartialWrites(5)
+	assert.ErrorIs(t, mt.Send(ctx, []byte(`{"jsonrpc":"2.0"}`)), io.ErrShortWrite)
+	assert.Equal(t, []byte(`{"jso`), mt.GetLastSent())
+
+	mt.SetPartialReads(4)
+	mt.QueueReceive([]byte("0123456789"))
+	var chunks []string
+	for range 3 {
+		chunk, err := mt.Receive(ctx)
+		require.NoError(t, err)
+		chunks = append(chunks, string(chunk))
+	}
+	assert.Equal(t, []string{"0123", "4567", "89"}, chunks)
+}
+
+func TestMockTransport_FaultsUseContext(t *testing.T) {
+	mt := NewMockTransport(t)
+	mt.SetFaults(chaos.Faults{Inbound: chaos.New(chaos.Config{Schedule: map[int]chaos.Fault{1: chaos.FaultDrop}})})
+	mt.QueueReceive([]byte(`{"n":1}`))
+	mt.QueueReceive([]byte(`{"n":2}`))
+
+	got, err := mt.Receive(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, `{"n":2}`, string(got))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = mt.Receive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
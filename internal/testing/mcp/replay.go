@@ -0,0 +1,240 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
+)
+
+// ReplayConfig configures the replay of a captured session.
+type ReplayConfig struct {
+	// IgnoreFields are dotted paths into responses that may differ from the
+	// recording, such as "result.serverInfo.version". A "*" segment
+	// matches any object key or array index.
+	IgnoreFields []string
+
+	// ProtocolVersion, when set, is used to perform the handshake before
+	// replaying a capture that does not start with initialize.
+	ProtocolVersion string
+
+	// Timeout bounds how long each request waits for its response
+	// (defaults to DefaultScenarioTimeout).
+	Timeout time.Duration
+}
+
+// LoadCapture reads an NDJSON session capture, one router.WireRecord per
+// line, as written by router.WireLogger.
+func LoadCapture(path string) ([]router.WireRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := ReadCapture(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// ReadCapture reads an NDJSON session capture.
+func ReadCapture(r io.Reader) ([]router.WireRecord, error) {
+	var records []router.WireRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var record router.WireRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record.Request) == 0 {
+			return nil, fmt.Errorf("line %d: record has no request", line)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// SplitSessions groups records by connection, keeping the order of records
+// within each connection and of connections by first appearance.
+func SplitSessions(records []router.WireRecord) [][]router.WireRecord {
+	index := make(map[string]int)
+	var sessions [][]router.WireRecord
+	for _, record := range records {
+		i, ok := index[record.ConnectionID]
+		if !ok {
+			i = len(sessions)
+			index[record.ConnectionID] = i
+			sessions = append(sessions, nil)
+		}
+		sessions[i] = append(sessions[i], record)
+	}
+	return sessions
+}
+
+// Replay sends the recorded requests of one session to target in order and
+// compares every response with the recorded one, ignoring the configured
+// fields. Recorded values redacted with redact.Placeholder match anything.
+// Records without a response are sent without waiting for one. It returns
+// every mismatch, not just the first.
+func Replay(ctx context.Context, target ScenarioTarget, records []router.WireRecord, config ReplayConfig) error {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultScenarioTimeout
+	}
+
+	if config.ProtocolVersion != "" && (len(records) == 0 || records[0].Method != "initialize") {
+		handshake := NewScenario("handshake").Timeout(timeout).Initialize(config.ProtocolVersion).Build()
+		if err := handshake.Run(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for i, record := range records {
+		if err := replayRecord(ctx, target, record, config.IgnoreFields, timeout); err != nil {
+			errs = append(errs, fmt.Errorf("record %d (%s): %w", i, record.Method, err))
+		}
+
+		// Captures restricted to some methods or sampled may not include
+		// the initialized notification
+		if record.Method == "initialize" && (i+1 == len(records) || records[i+1].Method != "notifications/initialized") {
+			message, _ := encodeMessage(nil, "notifications/initialized", nil)
+			if _, err := target.Send(ctx, message); err != nil {
+				errs = append(errs, fmt.Errorf("record %d (%s): %w", i, record.Method, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// replayRecord sends the request of record and checks the response.
+func replayRecord(ctx context.Context, target ScenarioTarget, record router.WireRecord, ignore []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	response, err := target.Send(ctx, record.Request)
+	if err != nil {
+		return err
+	}
+	if len(record.Response) == 0 {
+		return nil
+	}
+	if len(response) == 0 {
+		return fmt.Errorf("no response")
+	}
+
+	var recorded, actual interface{}
+	if err := json.Unmarshal(record.Response, &recorded); err != nil {
+		return fmt.Errorf("invalid recorded response: %w", err)
+	}
+	if err := json.Unmarshal(response, &actual); err != nil {
+		return fmt.Errorf("invalid response %s: %w", response, err)
+	}
+	for _, path := range ignore {
+		removePath(recorded, strings.Split(path, "."))
+		removePath(actual, strings.Split(path, "."))
+	}
+	return diffJSON(recorded, actual, "response")
+}
+
+// removePath deletes the value at path from a decoded JSON value.
+func removePath(v interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	key, rest := path[0], path[1:]
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for k, child := range node {
+			if key != "*" && k != key {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(node, k)
+			} else {
+				removePath(child, rest)
+			}
+		}
+	case []interface{}:
+		for i, child := range node {
+			if key != "*" && key != strconv.Itoa(i) {
+				continue
+			}
+			if len(rest) > 0 {
+				removePath(child, rest)
+			}
+		}
+	}
+}
+
+// diffJSON reports the first place where actual differs from recorded.
+// Both are decoded JSON values.
+func diffJSON(recorded, actual interface{}, path string) error {
+	if recorded == redact.Placeholder {
+		return nil
+	}
+	switch want := recorded.(type) {
+	case map[string]interface{}:
+		got, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: recorded object, got %v", path, actual)
+		}
+		keys := make([]string, 0, len(want)+len(got))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		for key := range got {
+			if _, ok := want[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			w, inWant := want[key]
+			g, inGot := got[key]
+			switch {
+			case !inGot:
+				return fmt.Errorf("%s.%s: missing", path, key)
+			case !inWant:
+				return fmt.Errorf("%s.%s: unexpected value %v", path, key, g)
+			}
+			if err := diffJSON(w, g, path+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		got, ok := actual.([]interface{})
+		if !ok || len(got) != len(want) {
+			return fmt.Errorf("%s: recorded %d elements, got %v", path, len(want), actual)
+		}
+		for i := range want {
+			if err := diffJSON(want[i], got[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(recorded, actual) {
+			return fmt.Errorf("%s: recorded %v, got %v", path, recorded, actual)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
+)
+
+func TestReplay_CapturedSession(t *testing.T) {
+	records, err := LoadCapture("testdata/echo_session.ndjson")
+	require.NoError(t, err)
+	sessions := SplitSessions(records)
+	require.Len(t, sessions, 2)
+	require.Len(t, sessions[0], 4)
+	require.Len(t, sessions[1], 1)
+
+	config := ReplayConfig{
+		IgnoreFields:    []string{"result.serverInfo.version"},
+		ProtocolVersion: "2025-03-26",
+	}
+	for _, session := range sessions {
+		target := newServerTarget(t, newScenarioServer(t))
+		require.NoError(t, Replay(context.Background(), target, session, config))
+	}
+}
+
+func TestReplay_ReportsMismatches(t *testing.T) {
+	records, err := LoadCapture("testdata/echo_session.ndjson")
+	require.NoError(t, err)
+	session := SplitSessions(records)[0]
+
+	// A server whose echo tool changed behaviour no longer matches
+	hs := newScenarioServer(t)
+	hs.AddTool(mcp.NewTool("echo", mcp.WithString("message")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("HELLO"), nil
+		})
+
+	err = Replay(context.Background(), newServerTarget(t, hs), session, ReplayConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "record 0 (initialize): response.result.serverInfo.version: recorded 0.9.0, got 1.0.0")
+	assert.Contains(t, err.Error(), "record 2 (tools/call): response.result.content[0].text: recorded hello, got HELLO")
+	assert.NotContains(t, err.Error(), "record 1")
+}
+
+func TestReadCapture_Invalid(t *testing.T) {
+	_, err := ReadCapture(strings.NewReader("{\"method\":\"ping\"}\n"))
+	assert.ErrorContains(t, err, "line 1: record has no request")
+
+	_, err = ReadCapture(strings.NewReader("\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestDiffJSON(t *testing.T) {
+	recorded := normalizeJSON(map[string]interface{}{
+		"token": redact.Placeholder,
+		"items": []interface{}{map[string]interface{}{"id": 1, "at": "10:00"}, map[string]interface{}{"id": 2, "at": "10:01"}},
+	})
+	actual := normalizeJSON(map[string]interface{}{
+		"token": "abc",
+		"items": []interface{}{map[string]interface{}{"id": 1, "at": "11:00"}, map[string]interface{}{"id": 2, "at": "11:01"}},
+		"extra": true,
+	})
+
+	assert.EqualError(t, diffJSON(recorded, actual, "r"), "r.extra: unexpected value true")
+	for _, v := range []interface{}{recorded, actual} {
+		removePath(v, []string{"items", "*", "at"})
+		removePath(v, []string{"extra"})
+	}
+	assert.NoError(t, diffJSON(recorded, actual, "r"))
+}
@@ -0,0 +1,513 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultScenarioTimeout bounds how long a step waits for a response or an
+// expected notification when the scenario sets no timeout.
+const DefaultScenarioTimeout = 5 * time.Second
+
+// Scenario describes an MCP flow declaratively: the messages a client sends,
+// the responses and notifications it expects and assertions on connection
+// state. Scenarios are written in YAML or with a ScenarioBuilder and run
+// against any ScenarioTarget, such as a MockServer or an in-process server.
+//
+// A YAML scenario looks like:
+//
+//	name: echo
+//	steps:
+//	  - send: initialize
+//	    params: {protocolVersion: "2025-03-26", clientInfo: {name: test, version: "1.0"}, capabilities: {}}
+//	    expect:
+//	      result: {serverInfo: {name: Mock MCP Server}}
+//	  - notify: notifications/initialized
+//	  - state: Ready
+//	  - send: tools/call
+//	    params: {name: echo, arguments: {message: hi}}
+//	    expect:
+//	      result: {content: [{type: text, text: hi}]}
+type Scenario struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Steps       []Step        `yaml:"steps"`
+}
+
+// Step is a single step of a Scenario. Exactly one of Send, Notify, Wait,
+// State and Check is set; Expect may accompany any of them.
+type Step struct {
+	// Send is the method of a request to send.
+	Send string `yaml:"send"`
+
+	// Notify is the method of a notification to send.
+	Notify string `yaml:"notify"`
+
+	// Params are the parameters of the request or notification.
+	Params interface{} `yaml:"params"`
+
+	// Wait pauses the scenario.
+	Wait time.Duration `yaml:"wait"`
+
+	// State is the connection state the target must be in, e.g. "Ready".
+	State string `yaml:"state"`
+
+	// Check runs custom assertions; it cannot be expressed in YAML.
+	Check func(ctx context.Context) error `yaml:"-"`
+
+	// Expect describes the expected outcome of the step.
+	Expect *Expectation `yaml:"expect"`
+}
+
+// Expectation describes the response to a request and the notifications a
+// step must produce.
+type Expectation struct {
+	// Result must be contained in the result of the response: objects may
+	// have additional fields, arrays must match element by element.
+	Result interface{} `yaml:"result"`
+
+	// Error is the error the response must carry; without it the response
+	// must succeed.
+	Error *ExpectedError `yaml:"error"`
+
+	// Notifications must all be received, in any order, before the step
+	// times out.
+	Notifications []ExpectedNotification `yaml:"notifications"`
+}
+
+// ExpectedError describes an expected error response.
+type ExpectedError struct {
+	// Code is the JSON-RPC error code.
+	Code int `yaml:"code"`
+
+	// Message must be contained in the error message when set.
+	Message string `yaml:"message"`
+}
+
+// ExpectedNotification describes a notification the server must send.
+type ExpectedNotification struct {
+	Method string `yaml:"method"`
+
+	// Params must be contained in the parameters of the notification.
+	Params interface{} `yaml:"params"`
+}
+
+// ScenarioTarget is a server a Scenario runs against.
+type ScenarioTarget interface {
+	// Send delivers a client message. For a request it returns the
+	// response; for a notification it returns nil.
+	Send(ctx context.Context, message []byte) ([]byte, error)
+
+	// Notifications returns every notification received from the server
+	// so far.
+	Notifications() [][]byte
+
+	// State returns the state of the client's connection, or "" when
+	// there is none.
+	State() string
+}
+
+// LoadScenario reads a YAML scenario from path.
+func LoadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario parses a YAML scenario.
+func ParseScenario(data []byte) (Scenario, error) {
+	var scenario Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		return Scenario{}, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return Scenario{}, err
+	}
+	return scenario, nil
+}
+
+// Validate checks that every step has exactly one action.
+func (s Scenario) Validate() error {
+	for i, step := range s.Steps {
+		actions := 0
+		for _, set := range []bool{step.Send != "", step.Notify != "", step.Wait > 0, step.State != "", step.Check != nil} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("step %d: want exactly one of send, notify, wait, state or check, got %d", i, actions)
+		}
+		if step.Expect != nil && step.Send == "" && (step.Expect.Result != nil || step.Expect.Error != nil) {
+			return fmt.Errorf("step %d: only send steps can expect a result or error", i)
+		}
+	}
+	return nil
+}
+
+// Run executes the scenario against target, returning the first failed
+// step.
+func (s Scenario) Run(ctx context.Context, target ScenarioTarget) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultScenarioTimeout
+	}
+
+	run := &scenarioRun{target: target, timeout: timeout}
+	for i, step := range s.Steps {
+		if err := run.step(ctx, i, step); err != nil {
+			return fmt.Errorf("scenario %s: step %d (%s): %w", s.Name, i, step.describe(), err)
+		}
+	}
+	return nil
+}
+
+// describe names the action of a step for error messages.
+func (step Step) describe() string {
+	switch {
+	case step.Send != "":
+		return "send " + step.Send
+	case step.Notify != "":
+		return "notify " + step.Notify
+	case step.Wait > 0:
+		return "wait " + step.Wait.String()
+	case step.State != "":
+		return "state " + step.State
+	default:
+		return "check"
+	}
+}
+
+// scenarioRun holds the state of one execution of a scenario.
+type scenarioRun struct {
+	target  ScenarioTarget
+	timeout time.Duration
+
+	// seen marks the notifications already matched by earlier steps.
+	seen map[int]bool
+}
+
+// step executes a single step.
+func (r *scenarioRun) step(parent context.Context, index int, step Step) error {
+	ctx, cancel := context.WithTimeout(parent, r.timeout)
+	defer cancel()
+
+	switch {
+	case step.Send != "":
+		message, err := encodeMessage(index+1, step.Send, step.Params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		response, err := r.target.Send(ctx, message)
+		if err != nil {
+			return err
+		}
+		if err := checkResponse(response, step.Expect); err != nil {
+			return err
+		}
+
+	case step.Notify != "":
+		message, err := encodeMessage(nil, step.Notify, step.Params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification: %w", err)
+		}
+		if _, err := r.target.Send(ctx, message); err != nil {
+			return err
+		}
+
+	case step.Wait > 0:
+		select {
+		case <-time.After(step.Wait):
+		case <-parent.Done():
+			return parent.Err()
+		}
+
+	case step.State != "":
+		if err := r.waitFor(ctx, func() bool { return r.target.State() == step.State }); err != nil {
+			return fmt.Errorf("connection is %q", r.target.State())
+		}
+
+	case step.Check != nil:
+		if err := step.Check(ctx); err != nil {
+			return fmt.Errorf("check failed: %w", err)
+		}
+	}
+
+	if step.Expect != nil {
+		for _, expected := range step.Expect.Notifications {
+			if err := r.waitFor(ctx, func() bool { return r.takeNotification(expected) }); err != nil {
+				return fmt.Errorf("notification %s not received", expected.Method)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeMessage encodes a request, or a notification when id is nil.
+func encodeMessage(id interface{}, method string, params interface{}) ([]byte, error) {
+	message := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if id != nil {
+		message["id"] = id
+	}
+	if params != nil {
+		message["params"] = normalizeJSON(params)
+	}
+	return json.Marshal(message)
+}
+
+// waitFor polls cond until it holds or ctx is done.
+func (r *scenarioRun) waitFor(ctx context.Context, cond func() bool) error {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for !cond() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// takeNotification marks the first unmatched notification matching expected
+// as seen, reporting whether there was one.
+func (r *scenarioRun) takeNotification(expected ExpectedNotification) bool {
+	if r.seen == nil {
+		r.seen = make(map[int]bool)
+	}
+	for i, data := range r.target.Notifications() {
+		if r.seen[i] {
+			continue
+		}
+		var notification struct {
+			Method string      `json:"method"`
+			Params interface{} `json:"params"`
+		}
+		if json.Unmarshal(data, &notification) != nil || notification.Method != expected.Method {
+			continue
+		}
+		if expected.Params != nil && matchJSON(normalizeJSON(expected.Params), notification.Params, "params") != nil {
+			continue
+		}
+		r.seen[i] = true
+		return true
+	}
+	return false
+}
+
+// checkResponse compares a response with the expected result or error.
+func checkResponse(data []byte, expect *Expectation) error {
+	var response struct {
+		Result interface{} `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("no response")
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("invalid response %s: %w", data, err)
+	}
+
+	if expect == nil || expect.Error == nil {
+		if response.Error != nil {
+			return fmt.Errorf("unexpected error %d: %s", response.Error.Code, response.Error.Message)
+		}
+		if expect != nil && expect.Result != nil {
+			return matchJSON(normalizeJSON(expect.Result), response.Result, "result")
+		}
+		return nil
+	}
+
+	if response.Error == nil {
+		return fmt.Errorf("expected error %d, got result %s", expect.Error.Code, data)
+	}
+	if response.Error.Code != expect.Error.Code {
+		return fmt.Errorf("expected error %d, got %d: %s", expect.Error.Code, response.Error.Code, response.Error.Message)
+	}
+	if !strings.Contains(response.Error.Message, expect.Error.Message) {
+		return fmt.Errorf("expected error message containing %q, got %q", expect.Error.Message, response.Error.Message)
+	}
+	return nil
+}
+
+// matchJSON reports where actual does not contain expected. Both are
+// decoded JSON values.
+func matchJSON(expected, actual interface{}, path string) error {
+	switch want := expected.(type) {
+	case map[string]interface{}:
+		got, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %v", path, actual)
+		}
+		for key, value := range want {
+			field, ok := got[key]
+			if !ok {
+				return fmt.Errorf("%s.%s: missing", path, key)
+			}
+			if err := matchJSON(value, field, path+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		got, ok := actual.([]interface{})
+		if !ok || len(got) != len(want) {
+			return fmt.Errorf("%s: expected %d elements, got %v", path, len(want), actual)
+		}
+		for i := range want {
+			if err := matchJSON(want[i], got[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("%s: expected %v, got %v", path, expected, actual)
+		}
+		return nil
+	}
+}
+
+// normalizeJSON converts a value, e.g. decoded from YAML, to the types
+// encoding/json decodes into.
+func normalizeJSON(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+// ScenarioBuilder builds a Scenario in Go. Expectations apply to the most
+// recently added step.
+type ScenarioBuilder struct {
+	scenario Scenario
+}
+
+// NewScenario starts building a scenario.
+func NewScenario(name string) *ScenarioBuilder {
+	return &ScenarioBuilder{scenario: Scenario{Name: name}}
+}
+
+// Describe sets the description of the scenario.
+func (b *ScenarioBuilder) Describe(description string) *ScenarioBuilder {
+	b.scenario.Description = description
+	return b
+}
+
+// Timeout sets how long each step waits for responses and notifications.
+func (b *ScenarioBuilder) Timeout(timeout time.Duration) *ScenarioBuilder {
+	b.scenario.Timeout = timeout
+	return b
+}
+
+// Initialize adds the handshake: an initialize request with protocolVersion
+// followed by the initialized notification.
+func (b *ScenarioBuilder) Initialize(protocolVersion string) *ScenarioBuilder {
+	return b.Request("initialize", map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"clientInfo":      map[string]interface{}{"name": "scenario", "version": "1.0.0"},
+		"capabilities":    map[string]interface{}{},
+	}).ExpectResult(map[string]interface{}{"protocolVersion": protocolVersion}).
+		Notify("notifications/initialized", nil)
+}
+
+// Request adds a request.
+func (b *ScenarioBuilder) Request(method string, params interface{}) *ScenarioBuilder {
+	return b.add(Step{Send: method, Params: params})
+}
+
+// Notify adds a notification.
+func (b *ScenarioBuilder) Notify(method string, params interface{}) *ScenarioBuilder {
+	return b.add(Step{Notify: method, Params: params})
+}
+
+// Wait adds a pause.
+func (b *ScenarioBuilder) Wait(d time.Duration) *ScenarioBuilder {
+	return b.add(Step{Wait: d})
+}
+
+// ExpectState adds an assertion that the connection reaches state.
+func (b *ScenarioBuilder) ExpectState(state string) *ScenarioBuilder {
+	return b.add(Step{State: state})
+}
+
+// Check adds custom assertions.
+func (b *ScenarioBuilder) Check(check func(ctx context.Context) error) *ScenarioBuilder {
+	return b.add(Step{Check: check})
+}
+
+// ExpectResult expects the last request to succeed with a result
+// containing result.
+func (b *ScenarioBuilder) ExpectResult(result interface{}) *ScenarioBuilder {
+	b.expectation().Result = result
+	return b
+}
+
+// ExpectError expects the last request to fail with code.
+func (b *ScenarioBuilder) ExpectError(code int) *ScenarioBuilder {
+	b.expectation().Error = &ExpectedError{Code: code}
+	return b
+}
+
+// ExpectNotification expects the last step to make the server send a
+// notification with params containing params.
+func (b *ScenarioBuilder) ExpectNotification(method string, params interface{}) *ScenarioBuilder {
+	expect := b.expectation()
+	expect.Notifications = append(expect.Notifications, ExpectedNotification{Method: method, Params: params})
+	return b
+}
+
+// Build returns the scenario.
+func (b *ScenarioBuilder) Build() Scenario {
+	return b.scenario
+}
+
+// add appends a step.
+func (b *ScenarioBuilder) add(step Step) *ScenarioBuilder {
+	b.scenario.Steps = append(b.scenario.Steps, step)
+	return b
+}
+
+// expectation returns the expectation of the last step, creating it if
+// needed.
+func (b *ScenarioBuilder) expectation() *Expectation {
+	if len(b.scenario.Steps) == 0 {
+		b.add(Step{Check: func(context.Context) error { return nil }})
+	}
+	step := &b.scenario.Steps[len(b.scenario.Steps)-1]
+	if step.Expect == nil {
+		step.Expect = &Expectation{}
+	}
+	return step.Expect
+}
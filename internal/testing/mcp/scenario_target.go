@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	mcpserver "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+)
+
+// Target returns a ScenarioTarget sending messages to the mock server as
+// connection connID. The mock server does not deliver notifications.
+func (ms *MockServer) Target(connID string) ScenarioTarget {
+	return &mockTarget{server: ms, connID: connID}
+}
+
+// mockTarget runs scenarios against a MockServer.
+type mockTarget struct {
+	server *MockServer
+	connID string
+}
+
+func (t *mockTarget) Send(ctx context.Context, message []byte) ([]byte, error) {
+	response, err := t.server.HandleRequest(ctx, t.connID, message)
+	if err != nil || bytes.Equal(response, []byte("null")) {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (t *mockTarget) Notifications() [][]byte {
+	return nil
+}
+
+func (t *mockTarget) State() string {
+	if conn, ok := t.server.GetConnectionManager().GetConnection(t.connID); ok {
+		return conn.GetState().String()
+	}
+	return ""
+}
+
+// serverTargets numbers ServerTargets so their connections can be told
+// apart.
+var serverTargets atomic.Int64
+
+// ServerTarget runs scenarios against a real server, serving one client
+// connection in-process exactly as the socket and stdio transports do.
+type ServerTarget struct {
+	hs     *mcpserver.HandshakeServer
+	prefix string
+	conn   net.Conn
+	cancel context.CancelFunc
+	done   chan error
+
+	mu            sync.Mutex
+	pending       map[string]chan []byte
+	notifications [][]byte
+}
+
+// NewServerTarget connects a client to hs. Close disconnects it.
+func NewServerTarget(hs *mcpserver.HandshakeServer) *ServerTarget {
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	transport := fmt.Sprintf("scenario%d", serverTargets.Add(1))
+	t := &ServerTarget{
+		hs:      hs,
+		prefix:  transport + "-",
+		conn:    clientConn,
+		cancel:  cancel,
+		done:    make(chan error, 1),
+		pending: make(map[string]chan []byte),
+	}
+
+	go func() {
+		t.done <- server.New(hs, server.Config{}).ServeConn(ctx, transport, server.NewLineConn(serverConn, serverConn, serverConn))
+	}()
+	go t.read()
+	return t
+}
+
+// read dispatches messages from the server until the connection closes.
+func (t *ServerTarget) read() {
+	scanner := bufio.NewScanner(t.conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		message := append([]byte(nil), scanner.Bytes()...)
+		var header struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if json.Unmarshal(message, &header) != nil {
+			continue
+		}
+
+		t.mu.Lock()
+		if header.Method != "" {
+			t.notifications = append(t.notifications, message)
+		} else if ch, ok := t.pending[string(header.ID)]; ok {
+			delete(t.pending, string(header.ID))
+			ch <- message
+		}
+		t.mu.Unlock()
+	}
+}
+
+// Send writes message and, for a request, waits for the response with the
+// same ID.
+func (t *ServerTarget) Send(ctx context.Context, message []byte) ([]byte, error) {
+	var header struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(message, &header); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	var response chan []byte
+	if len(header.ID) > 0 {
+		response = make(chan []byte, 1)
+		t.mu.Lock()
+		t.pending[string(header.ID)] = response
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			delete(t.pending, string(header.ID))
+			t.mu.Unlock()
+		}()
+	}
+
+	if _, err := t.conn.Write(append(message, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+	if response == nil {
+		return nil, nil
+	}
+
+	select {
+	case data := <-response:
+		return data, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no response: %w", ctx.Err())
+	}
+}
+
+// Notifications returns every notification received so far.
+func (t *ServerTarget) Notifications() [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([][]byte(nil), t.notifications...)
+}
+
+// State returns the state of the target's connection.
+func (t *ServerTarget) State() string {
+	for _, info := range t.hs.GetConnectionManager().Snapshot() {
+		if strings.HasPrefix(info.ID, t.prefix) {
+			return info.State
+		}
+	}
+	return ""
+}
+
+// Close disconnects the client and waits for the server to finish with it.
+func (t *ServerTarget) Close() error {
+	t.cancel()
+	t.conn.Close()
+	return <-t.done
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpserver "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+func newScenarioServer(t *testing.T) *mcpserver.HandshakeServer {
+	t.Helper()
+	config := mcpserver.DefaultHandshakeConfig()
+	config.Name = "scenario-server"
+	config.SupportedVersions = mcp.ValidProtocolVersions
+	config.ServerOptions = []server.ServerOption{server.WithToolCapabilities(true)}
+	hs := mcpserver.NewHandshakeServer(config)
+	hs.AddTool(mcp.NewTool("echo", mcp.WithString("message")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(request.GetString("message", "")), nil
+		})
+	return hs
+}
+
+func newServerTarget(t *testing.T, hs *mcpserver.HandshakeServer) *ServerTarget {
+	t.Helper()
+	target := NewServerTarget(hs)
+	t.Cleanup(func() { target.Close() })
+	return target
+}
+
+func TestScenario_YAMLAgainstServer(t *testing.T) {
+	scenario, err := LoadScenario("testdata/echo.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "echo", scenario.Name)
+
+	target := newServerTarget(t, newScenarioServer(t))
+	require.NoError(t, scenario.Run(context.Background(), target))
+}
+
+func TestScenario_BuilderAgainstServer(t *testing.T) {
+	hs := newScenarioServer(t)
+	target := newServerTarget(t, hs)
+
+	scenario := NewScenario("list-changed").
+		Initialize("2025-03-26").
+		ExpectState("Ready").
+		Check(func(ctx context.Context) error {
+			hs.AddTool(mcp.NewTool("added"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("added"), nil
+			})
+			return nil
+		}).
+		ExpectNotification("notifications/tools/list_changed", nil).
+		Request("tools/call", map[string]interface{}{"name": "added"}).
+		ExpectResult(map[string]interface{}{"content": []interface{}{map[string]interface{}{"text": "added"}}}).
+		Request("no/such/method", nil).
+		ExpectError(mcp.METHOD_NOT_FOUND).
+		Build()
+
+	require.NoError(t, scenario.Run(context.Background(), target))
+}
+
+func TestScenario_AgainstMockServer(t *testing.T) {
+	config := DefaultMockServerConfig()
+	config.SupportedVersions = mcp.ValidProtocolVersions
+	ms := NewMockServer(config)
+
+	scenario := NewScenario("mock-handshake").
+		Initialize("2025-03-26").
+		ExpectState("Ready").
+		Build()
+
+	require.NoError(t, scenario.Run(context.Background(), ms.Target("conn-1")))
+	assert.Equal(t, 1, ms.GetRequestCount("initialize"))
+}
+
+func TestScenario_ReportsFailedStep(t *testing.T) {
+	target := newServerTarget(t, newScenarioServer(t))
+
+	scenario := NewScenario("mismatch").
+		Initialize("2025-03-26").
+		Request("tools/call", map[string]interface{}{"name": "echo", "arguments": map[string]interface{}{"message": "hi"}}).
+		ExpectResult(map[string]interface{}{"content": []interface{}{map[string]interface{}{"text": "bye"}}}).
+		Build()
+
+	err := scenario.Run(context.Background(), target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step 2 (send tools/call)")
+	assert.Contains(t, err.Error(), "result.content[0].text: expected bye, got hi")
+}
+
+func TestParseScenario_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"no action", "steps:\n  - params: {}\n", "want exactly one of"},
+		{"two actions", "steps:\n  - send: ping\n    notify: ping\n", "got 2"},
+		{"result on notification", "steps:\n  - notify: ping\n    expect: {result: {}}\n", "only send steps"},
+		{"unknown field", "steps:\n  - sned: ping\n", "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScenario([]byte(tt.yaml))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestMatchJSON(t *testing.T) {
+	actual := normalizeJSON(map[string]interface{}{
+		"name":  "echo",
+		"count": 2,
+		"items": []interface{}{map[string]interface{}{"a": 1, "b": 2}},
+	})
+
+	assert.NoError(t, matchJSON(normalizeJSON(map[string]interface{}{"count": 2}), actual, "v"))
+	assert.NoError(t, matchJSON(normalizeJSON(map[string]interface{}{"items": []interface{}{map[string]interface{}{"a": 1}}}), actual, "v"))
+	assert.EqualError(t, matchJSON(normalizeJSON(map[string]interface{}{"missing": 1}), actual, "v"), "v.missing: missing")
+	assert.Error(t, matchJSON(normalizeJSON(map[string]interface{}{"items": []interface{}{}}), actual, "v"))
+	assert.Error(t, matchJSON(normalizeJSON(map[string]interface{}{"count": "2"}), actual, "v"))
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
 	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
 	mcpserver "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
 )
@@ -24,6 +26,13 @@ type MockServerConfig struct {
 	SupportedVersions []string
 	ServerOptions     []server.ServerOption
 
+	// Strict makes the mock enforce the connection state machine like the
+	// real server: every message is checked against the handshake state of
+	// its connection, a connection that has not completed the handshake
+	// within HandshakeTimeout is closed, messages to a closed connection
+	// are rejected and a closed connection is never recreated.
+	Strict bool
+
 	// Response configuration
 	ResponseDelay time.Duration
 	ErrorRate     float64 // Probability of returning an error (0.0-1.0)
@@ -111,14 +120,61 @@ func NewMockServer(config MockServerConfig) *MockServer {
 	return ms
 }
 
+// CreateConnection creates a connection like the HandshakeServer and
+// tracks its state. In strict mode it also starts the handshake timeout.
+func (ms *MockServer) CreateConnection(ctx context.Context, connID string) (context.Context, error) {
+	ctx, err := ms.HandshakeServer.CreateConnection(ctx, connID)
+	if err != nil {
+		return ctx, err
+	}
+
+	now := time.Now()
+	ms.mu.Lock()
+	ms.connections[connID] = &ConnectionState{
+		ID:        connID,
+		State:     connection.StateNew.String(),
+		StartTime: now,
+		LastSeen:  now,
+		Metadata:  make(map[string]interface{}),
+	}
+	ms.mu.Unlock()
+
+	if ms.config.Strict {
+		if conn, ok := ms.GetConnectionManager().GetConnection(connID); ok {
+			time.AfterFunc(conn.HandshakeTimeout, func() { ms.expireHandshake(conn) })
+		}
+	}
+	return ctx, nil
+}
+
+// expireHandshake closes conn if it has not completed the handshake.
+func (ms *MockServer) expireHandshake(conn *connection.Connection) {
+	if state := conn.GetState(); state == connection.StateReady || state == connection.StateClosed {
+		return
+	}
+	conn.Close()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if state, ok := ms.connections[conn.ID]; ok {
+		state.State = connection.StateClosed.String()
+		state.Metadata["closeReason"] = "handshake timeout"
+	}
+}
+
 // HandleRequest processes a JSON-RPC request and returns a response.
 // This wraps the HandshakeServer's HandleMessage with additional tracking.
 func (ms *MockServer) HandleRequest(ctx context.Context, connID string, request []byte) ([]byte, error) {
-	// Ensure connection exists
-	ctx, err := ms.CreateConnection(ctx, connID)
-	if err != nil {
-		// Connection might already exist
-		ctx = ms.GetConnectionContext(ctx, connID)
+	if ms.config.Strict {
+		ctx = ms.strictConnectionContext(ctx, connID)
+	} else {
+		// Ensure connection exists
+		var err error
+		ctx, err = ms.CreateConnection(ctx, connID)
+		if err != nil {
+			// Connection might already exist
+			ctx = ms.GetConnectionContext(ctx, connID)
+		}
 	}
 
 	// Parse request to track it
@@ -127,6 +183,12 @@ func (ms *MockServer) HandleRequest(ctx context.Context, connID string, request
 		ms.recordRequest(req.Method, req.Params, req.ID)
 	}
 
+	if ms.config.Strict {
+		if rejection := ms.checkClosed(connID, req.ID); rejection != nil {
+			return rejection, nil
+		}
+	}
+
 	// Apply configured delay
 	if ms.config.ResponseDelay > 0 {
 		time.Sleep(ms.config.ResponseDelay)
@@ -145,10 +207,65 @@ func (ms *MockServer) HandleRequest(ctx context.Context, connID string, request
 	if req.Method != "" {
 		ms.updateRequestRecord(req.ID, response, nil)
 	}
+	ms.touchConnection(connID)
 
 	return respBytes, nil
 }
 
+// strictConnectionContext returns ctx carrying connID, creating the
+// connection on its first message only. A connection that was closed and
+// removed stays unknown, so its messages fail as on the real server.
+func (ms *MockServer) strictConnectionContext(ctx context.Context, connID string) context.Context {
+	ms.mu.RLock()
+	_, seen := ms.connections[connID]
+	ms.mu.RUnlock()
+	if !seen {
+		ms.CreateConnection(ctx, connID)
+	}
+	return connection.WithConnectionID(ctx, connID)
+}
+
+// checkClosed returns the response rejecting a message with id to a closed
+// connection, or nil when the connection is not closed. Notifications are
+// dropped silently.
+func (ms *MockServer) checkClosed(connID string, id interface{}) []byte {
+	conn, ok := ms.GetConnectionManager().GetConnection(connID)
+	if !ok || conn.GetState() != connection.StateClosed {
+		return nil
+	}
+	if id == nil {
+		return []byte("null")
+	}
+
+	err := mcperrors.NewConnectionLostError("connection closed")
+	ms.mu.RLock()
+	if state, ok := ms.connections[connID]; ok && state.Metadata["closeReason"] == "handshake timeout" {
+		err = mcperrors.NewHandshakeTimeoutError(conn.HandshakeTimeout.String())
+	}
+	ms.mu.RUnlock()
+
+	response := jsonrpc.NewErrorResponse(err.ToJSONRPCError(), id)
+	data, _ := json.Marshal(response)
+	ms.updateRequestRecord(id, response, err)
+	return data
+}
+
+// touchConnection records activity on a connection.
+func (ms *MockServer) touchConnection(connID string) {
+	conn, ok := ms.GetConnectionManager().GetConnection(connID)
+	if !ok {
+		return
+	}
+	state := conn.GetState().String()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if tracked, ok := ms.connections[connID]; ok {
+		tracked.State = state
+		tracked.LastSeen = time.Now()
+	}
+}
+
 // GetRequests returns all recorded requests.
 func (ms *MockServer) GetRequests() []RequestRecord {
 	ms.mu.RLock()
@@ -165,12 +282,20 @@ func (ms *MockServer) GetRequestCount(method string) int {
 	return ms.requestCounts[method]
 }
 
-// GetConnectionState returns the state of a specific connection.
+// GetConnectionState returns a copy of the state of a specific connection.
 func (ms *MockServer) GetConnectionState(connID string) (*ConnectionState, bool) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 	state, ok := ms.connections[connID]
-	return state, ok
+	if !ok {
+		return nil, false
+	}
+	copied := *state
+	copied.Metadata = make(map[string]interface{}, len(state.Metadata))
+	for k, v := range state.Metadata {
+		copied.Metadata[k] = v
+	}
+	return &copied, true
 }
 
 // Reset clears all recorded data.
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	mcpserver "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+func newStrictMockServer(timeout time.Duration) *MockServer {
+	config := DefaultMockServerConfig()
+	config.SupportedVersions = mcp.ValidProtocolVersions
+	config.HandshakeTimeout = timeout
+	config.Strict = true
+	return NewMockServer(config)
+}
+
+func TestMockServer_StrictValidatesEveryMessage(t *testing.T) {
+	ms := newStrictMockServer(time.Minute)
+
+	// Both requests before the handshake are rejected, not just the first
+	scenario := NewScenario("pre-handshake").
+		Request("ping", nil).ExpectError(mcpserver.ErrorCodeServerNotInitialized).
+		Request("ping", nil).ExpectError(mcpserver.ErrorCodeServerNotInitialized).
+		Initialize("2025-03-26").
+		ExpectState("Ready").
+		Request("ping", nil).
+		Build()
+	require.NoError(t, scenario.Run(context.Background(), ms.Target("conn-1")))
+
+	state, ok := ms.GetConnectionState("conn-1")
+	require.True(t, ok)
+	assert.Equal(t, "Ready", state.State)
+}
+
+func TestMockServer_StrictHandshakeTimeout(t *testing.T) {
+	ms := newStrictMockServer(20 * time.Millisecond)
+
+	scenario := NewScenario("timeout").
+		Request("ping", nil).ExpectError(mcpserver.ErrorCodeServerNotInitialized).
+		ExpectState("Closed").
+		Request("initialize", map[string]interface{}{
+			"protocolVersion": "2025-03-26",
+			"clientInfo":      map[string]interface{}{"name": "late", "version": "1.0.0"},
+			"capabilities":    map[string]interface{}{},
+		}).ExpectError(mcperrors.ErrorCodeMCPHandshakeTimeout).
+		Build()
+	require.NoError(t, scenario.Run(context.Background(), ms.Target("conn-1")))
+
+	state, ok := ms.GetConnectionState("conn-1")
+	require.True(t, ok)
+	assert.Equal(t, "handshake timeout", state.Metadata["closeReason"])
+}
+
+func TestMockServer_StrictClosedConnection(t *testing.T) {
+	ms := newStrictMockServer(time.Minute)
+	target := ms.Target("conn-1")
+
+	require.NoError(t, NewScenario("handshake").Initialize("2025-03-26").Build().Run(context.Background(), target))
+
+	// A closed connection rejects messages; a removed one is not recreated
+	conn, ok := ms.GetConnectionManager().GetConnection("conn-1")
+	require.True(t, ok)
+	conn.Close()
+	require.NoError(t, NewScenario("closed").
+		Request("ping", nil).ExpectError(mcperrors.ErrorCodeMCPConnectionLost).
+		Build().Run(context.Background(), target))
+
+	ms.CloseConnection("conn-1")
+	require.NoError(t, NewScenario("removed").
+		Request("ping", nil).ExpectError(-32002).
+		Build().Run(context.Background(), target))
+	_, ok = ms.GetConnectionManager().GetConnection("conn-1")
+	assert.False(t, ok)
+}
+
+func TestMockServer_LenientSkipsValidationAfterFirstMessage(t *testing.T) {
+	config := DefaultMockServerConfig()
+	config.HandshakeTimeout = 20 * time.Millisecond
+	ms := NewMockServer(config)
+
+	scenario := NewScenario("lenient").
+		Request("ping", nil).ExpectError(mcpserver.ErrorCodeServerNotInitialized).
+		Request("ping", nil).
+		Wait(40 * time.Millisecond).
+		ExpectState("New").
+		Build()
+	require.NoError(t, scenario.Run(context.Background(), ms.Target("conn-1")))
+}
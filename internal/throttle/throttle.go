@@ -0,0 +1,184 @@
+// Package throttle caps the rate at which the server writes to clients, so
+// a single client reading huge resources cannot take the bandwidth of the
+// others sharing the process.
+//
+// A Throttle holds a token bucket per connection and one shared by all of
+// them. Writing a message takes its size in tokens from both; when either
+// runs short, the write waits until it has refilled. A message larger than
+// the burst is let through once its debt is paid, so no message is ever
+// rejected for its size. Large messages are paid for in chunks, taking
+// turns with the other connections, so they do not reserve the shared
+// bucket ahead of everyone else.
+//
+// Basic usage:
+//
+//	t := throttle.New(throttle.Config{PerConnection: 1 << 20, Global: 8 << 20})
+//	srv := server.New(hs, server.Config{Throttle: t})
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+// chunkSize is the most a write takes from the buckets at once
+const chunkSize = 16 << 10
+
+// Config contains configuration for a Throttle
+type Config struct {
+	// PerConnection caps the bytes per second written to each connection
+	// (0 disables the limit)
+	PerConnection int64
+
+	// Global caps the bytes per second written to all connections together
+	// (0 disables the limit)
+	Global int64
+
+	// Burst is how many bytes a bucket holds when full, written without
+	// waiting after a quiet period (defaults to one second of its rate)
+	Burst int64
+
+	// Clock times the refills (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// Stats counts throttled writes
+type Stats struct {
+	// Bytes were written through the throttle
+	Bytes int64 `json:"bytes"`
+
+	// Delayed writes had to wait for tokens
+	Delayed int64 `json:"delayed"`
+
+	// Waited is the total time writes waited
+	Waited time.Duration `json:"waited"`
+}
+
+// Throttle limits the outbound bandwidth of connections
+type Throttle struct {
+	config Config
+	global *bucket
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New creates a throttle. A nil *Throttle does not limit anything.
+func New(config Config) *Throttle {
+	config.Clock = clock.Or(config.Clock)
+	return &Throttle{config: config, global: newBucket(config.Global, config.Burst, config.Clock)}
+}
+
+// Enabled reports whether t limits any rate
+func (t *Throttle) Enabled() bool {
+	return t != nil && (t.config.PerConnection > 0 || t.config.Global > 0)
+}
+
+// Connection returns the limiter of a new connection
+func (t *Throttle) Connection() *Connection {
+	if t == nil {
+		return nil
+	}
+	return &Connection{throttle: t, bucket: newBucket(t.config.PerConnection, t.config.Burst, t.config.Clock)}
+}
+
+// Stats returns the counters of writes so far
+func (t *Throttle) Stats() Stats {
+	if t == nil {
+		return Stats{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// Connection is the limiter of one connection
+type Connection struct {
+	throttle *Throttle
+	bucket   *bucket
+}
+
+// Wait blocks until n bytes may be written to the connection, or returns
+// the error of ctx once it is done. A nil *Connection never waits.
+func (c *Connection) Wait(ctx context.Context, n int) error {
+	if c == nil || n <= 0 {
+		return nil
+	}
+	t := c.throttle
+	var waited time.Duration
+	for remaining := int64(n); remaining > 0; {
+		chunk := min(remaining, chunkSize)
+		remaining -= chunk
+		delay := max(c.bucket.take(chunk), t.global.take(chunk))
+		if delay <= 0 {
+			continue
+		}
+		waited += delay
+		timer := t.config.Clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			t.record(0, waited)
+			return ctx.Err()
+		}
+	}
+	t.record(int64(n), waited)
+	return nil
+}
+
+// record counts a write
+func (t *Throttle) record(n int64, waited time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Bytes += n
+	if waited > 0 {
+		t.stats.Delayed++
+		t.stats.Waited += waited
+	}
+}
+
+// bucket is a token bucket holding up to burst tokens, refilled at rate
+// tokens per second. Tokens may go negative: a take is always granted,
+// and the caller waits for the debt to be paid.
+type bucket struct {
+	rate  float64
+	burst float64
+	clock clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newBucket returns a full bucket, or nil when rate is 0
+func newBucket(rate, burst int64, c clock.Clock) *bucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = rate
+	}
+	return &bucket{rate: float64(rate), burst: float64(burst), clock: c, tokens: float64(burst), last: c.Now()}
+}
+
+// take removes n tokens, returning how long the caller must wait until
+// the bucket is no longer in debt. A nil bucket never waits.
+func (b *bucket) take(n int64) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
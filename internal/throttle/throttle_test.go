@@ -0,0 +1,74 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+func TestBucket_Take(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	b := newBucket(1000, 500, fake)
+
+	assert.Zero(t, b.take(500), "a full bucket pays for its burst")
+	assert.Equal(t, 100*time.Millisecond, b.take(100))
+
+	// Refills are capped at the burst
+	fake.Advance(time.Hour)
+	assert.Zero(t, b.take(500))
+	assert.Equal(t, 2*time.Second, b.take(2000), "larger writes run into debt")
+
+	assert.Zero(t, (*bucket)(nil).take(1<<30))
+	assert.Nil(t, newBucket(0, 0, fake))
+}
+
+func TestConnection_Wait(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	th := New(Config{PerConnection: 1000, Clock: fake})
+	require.True(t, th.Enabled())
+	c := th.Connection()
+	ctx := context.Background()
+
+	require.NoError(t, c.Wait(ctx, 1000))
+
+	done := make(chan error, 1)
+	go func() { done <- c.Wait(ctx, 500) }()
+	require.NoError(t, fake.BlockUntil(ctx, 1))
+	select {
+	case <-done:
+		t.Fatal("write went through an empty bucket")
+	default:
+	}
+	fake.Advance(500 * time.Millisecond)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, Stats{Bytes: 1500, Delayed: 1, Waited: 500 * time.Millisecond}, th.Stats())
+
+	// Another connection has its own bucket
+	require.NoError(t, th.Connection().Wait(ctx, 1000))
+}
+
+func TestConnection_WaitGlobal(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	th := New(Config{Global: 1000, Clock: fake})
+	require.NoError(t, th.Connection().Wait(context.Background(), 1000))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- th.Connection().Wait(ctx, 10) }()
+	require.NoError(t, fake.BlockUntil(context.Background(), 1))
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestThrottle_Disabled(t *testing.T) {
+	var th *Throttle
+	assert.False(t, th.Enabled())
+	assert.NoError(t, th.Connection().Wait(context.Background(), 1<<30))
+	assert.False(t, New(Config{}).Enabled())
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// BatchToolName is the name of the meta-tool calling several tools at once
+const BatchToolName = "batch_call"
+
+// BatchConfig contains configuration for the batch meta-tool
+type BatchConfig struct {
+	// MaxCalls bounds the calls in one batch (defaults to 50)
+	MaxCalls int
+
+	// Concurrency bounds how many calls of a batch run at once (defaults
+	// to 4)
+	Concurrency int
+}
+
+// BatchCall is one call of a batch
+type BatchCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// BatchResult is the outcome of one call of a batch. Succeeded calls carry
+// their result, which may be a tool error; failed calls the protocol error
+// they failed with; skipped calls, not started before the batch was
+// cancelled, the reason.
+type BatchResult struct {
+	Name   string               `json:"name"`
+	Status mcperrors.ItemStatus `json:"status"`
+	Result *mcp.CallToolResult  `json:"result,omitempty"`
+	Error  *mcperrors.ItemError `json:"error,omitempty"`
+	Reason string               `json:"reason,omitempty"`
+}
+
+// BatchDefinition returns the batch meta-tool, which calls tools of the
+// registry with bounded concurrency and returns their results in the order
+// of the calls. Register it like any other tool.
+func (r *Registry) BatchDefinition(config BatchConfig) Definition {
+	if config.MaxCalls <= 0 {
+		config.MaxCalls = 50
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+
+	tool := mcp.NewTool(BatchToolName,
+		mcp.WithDescription("Call several tools in one request. Results are returned in the order of the calls; a failed call does not stop the others."),
+		mcp.WithArray("calls",
+			mcp.Required(),
+			mcp.Description("Tool calls to make"),
+			mcp.MaxItems(config.MaxCalls),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":      map[string]any{"type": "string"},
+					"arguments": map[string]any{"type": "object"},
+				},
+				"required": []string{"name"},
+			}),
+		),
+	)
+	return Definition{
+		Tool:    tool,
+		Handler: r.batchHandler(config),
+		Tags:    []string{"builtin"},
+	}
+}
+
+// batchHandler runs the calls of a batch
+func (r *Registry) batchHandler(config BatchConfig) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var params struct {
+			Calls []BatchCall `json:"calls"`
+		}
+		if err := request.BindArguments(&params); err != nil {
+			return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams, "invalid batch: "+err.Error(), nil)
+		}
+		if len(params.Calls) == 0 {
+			return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams, "batch has no calls", nil)
+		}
+		if len(params.Calls) > config.MaxCalls {
+			return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+				fmt.Sprintf("batch has %d calls, at most %d are allowed", len(params.Calls), config.MaxCalls), nil)
+		}
+
+		results := make([]BatchResult, len(params.Calls))
+		ids := make([]string, len(params.Calls))
+		for i, call := range params.Calls {
+			ids[i] = strconv.Itoa(i)
+			results[i].Name = call.Name
+		}
+		items := mcperrors.FanOut(ctx, ids, config.Concurrency, func(ctx context.Context, id string) error {
+			i, _ := strconv.Atoi(id)
+			result, err := r.batchCall(ctx, params.Calls[i])
+			results[i].Result = result
+			return err
+		})
+		for i, item := range items {
+			results[i].Status = item.Status
+			results[i].Reason = item.Reason
+			if item.Status == mcperrors.ItemFailed {
+				results[i].Result = nil
+				results[i].Error = itemError(item.Err)
+			}
+		}
+
+		data, err := json.Marshal(map[string]any{"results": results})
+		if err != nil {
+			return nil, fmt.Errorf("encode batch results: %w", err)
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// batchCall makes one call of a batch. Batches cannot nest, and calls are
+// limited to the tools the caller may see.
+func (r *Registry) batchCall(ctx context.Context, call BatchCall) (*mcp.CallToolResult, error) {
+	if call.Name == BatchToolName || !r.visible(ctx, call.Name) {
+		return nil, mcperrors.NewToolNotFoundError(call.Name)
+	}
+	request := mcp.CallToolRequest{}
+	request.Method = string(mcp.MethodToolsCall)
+	request.Params.Name = call.Name
+	request.Params.Arguments = call.Arguments
+	return r.Call(ctx, request)
+}
+
+// itemError is the client-safe form of the error a call failed with;
+// errors that are not MCPErrors are reported as internal errors without
+// their details
+func itemError(err error) *mcperrors.ItemError {
+	if mcpErr := mcperrors.FindMCPError(err); mcpErr != nil {
+		return &mcperrors.ItemError{Code: mcpErr.Code, Message: mcpErr.Message}
+	}
+	return &mcperrors.ItemError{
+		Code:    jsonrpc.ErrorCodeInternal,
+		Message: mcperrors.GetMCPErrorMessage(jsonrpc.ErrorCodeInternal),
+	}
+}
+
+// visible reports whether the connection of ctx may see the tool name
+func (r *Registry) visible(ctx context.Context, name string) bool {
+	if r.config.Filter == nil {
+		return true
+	}
+	for _, tool := range r.Visible(ctx) {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
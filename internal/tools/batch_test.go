@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// batchRequest calls the batch tool with calls
+func batchRequest(calls ...BatchCall) mcp.CallToolRequest {
+	request := callRequest(BatchToolName)
+	request.Params.Arguments = map[string]any{"calls": calls}
+	return request
+}
+
+// decodedBatchResult is a BatchResult as a client decodes it
+type decodedBatchResult struct {
+	Name   string               `json:"name"`
+	Status mcperrors.ItemStatus `json:"status"`
+	Result *struct {
+		Content []mcp.TextContent `json:"content"`
+		IsError bool              `json:"isError"`
+	} `json:"result"`
+	Error *mcperrors.ItemError `json:"error"`
+}
+
+// batchResults decodes the results of a batch
+func batchResults(t *testing.T, result *mcp.CallToolResult) []decodedBatchResult {
+	t.Helper()
+	var decoded struct {
+		Results []decodedBatchResult `json:"results"`
+	}
+	require.Len(t, result.Content, 1)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &decoded))
+	return decoded.Results
+}
+
+func TestBatch(t *testing.T) {
+	type roleKey struct{}
+	r := New(Config{Filter: func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		var visible []mcp.Tool
+		for _, tool := range tools {
+			if tool.Name != "shutdown" || ctx.Value(roleKey{}) == "admin" {
+				visible = append(visible, tool)
+			}
+		}
+		return visible
+	}})
+	r.MustRegister(Definition{
+		Tool: mcp.NewTool("upper", mcp.WithString("text")),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text := request.GetString("text", "")
+			if text == "" {
+				return mcp.NewToolResultError("text is empty"), nil
+			}
+			return mcp.NewToolResultText(text + "!"), nil
+		},
+	})
+	r.MustRegister(Definition{
+		Tool: mcp.NewTool("broken"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errors.New("database password leaked in error")
+		},
+	})
+	shutdown := echoDefinition()
+	shutdown.Tool.Name = "shutdown"
+	r.MustRegister(shutdown)
+	r.MustRegister(r.BatchDefinition(BatchConfig{}))
+
+	result, err := r.Call(context.Background(), batchRequest(
+		BatchCall{Name: "upper", Arguments: map[string]any{"text": "a"}},
+		BatchCall{Name: "upper"},
+		BatchCall{Name: "missing"},
+		BatchCall{Name: "broken"},
+		BatchCall{Name: "shutdown"},
+		BatchCall{Name: BatchToolName},
+		BatchCall{Name: "upper", Arguments: map[string]any{"text": "b"}},
+	))
+	require.NoError(t, err)
+	results := batchResults(t, result)
+	require.Len(t, results, 7)
+
+	assert.Equal(t, mcperrors.ItemSucceeded, results[0].Status)
+	assert.Equal(t, "a!", results[0].Result.Content[0].Text)
+	assert.True(t, results[1].Result.IsError, "tool errors are results")
+	for _, i := range []int{2, 4, 5} {
+		assert.Equal(t, mcperrors.ItemFailed, results[i].Status, results[i].Name)
+		assert.Equal(t, mcperrors.ErrorCodeMCPToolNotFound, results[i].Error.Code, results[i].Name)
+	}
+	assert.Equal(t, jsonrpc.ErrorCodeInternal, results[3].Error.Code)
+	assert.NotContains(t, results[3].Error.Message, "password")
+	assert.Equal(t, "upper", results[6].Name)
+	assert.Equal(t, "b!", results[6].Result.Content[0].Text)
+
+	// Hidden tools are callable by those who may see them
+	admin := context.WithValue(context.Background(), roleKey{}, "admin")
+	result, err = r.Call(admin, batchRequest(BatchCall{Name: "shutdown"}))
+	require.NoError(t, err)
+	assert.Equal(t, mcperrors.ItemSucceeded, batchResults(t, result)[0].Status)
+
+	// Batches are bounded
+	_, err = r.Call(context.Background(), batchRequest())
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+}
+
+func TestBatch_Concurrency(t *testing.T) {
+	r := New(Config{})
+	var running, peak atomic.Int64
+	r.MustRegister(Definition{
+		Tool: mcp.NewTool("slow"),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return mcp.NewToolResultText("done"), nil
+		},
+	})
+	r.MustRegister(r.BatchDefinition(BatchConfig{MaxCalls: 8, Concurrency: 2}))
+
+	calls := make([]BatchCall, 8)
+	for i := range calls {
+		calls[i] = BatchCall{Name: "slow"}
+	}
+	result, err := r.Call(context.Background(), batchRequest(calls...))
+	require.NoError(t, err)
+	for _, res := range batchResults(t, result) {
+		assert.Equal(t, mcperrors.ItemSucceeded, res.Status)
+	}
+	assert.LessOrEqual(t, peak.Load(), int64(2))
+
+	_, err = r.Call(context.Background(), batchRequest(append(calls, BatchCall{Name: "slow"})...))
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+}
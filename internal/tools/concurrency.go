@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// GroupStatus describes a concurrency group
+type GroupStatus struct {
+	Name    string `json:"name"`
+	Limit   int    `json:"limit"`
+	Running int    `json:"running"`
+	Waiting int    `json:"waiting"`
+}
+
+// concurrencyGroups limits how many calls of the tools sharing a group run
+// at once, across all connections. Waiting calls get a slot in arrival
+// order.
+type concurrencyGroups struct {
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// group is the state of one concurrency group
+type group struct {
+	limit   int
+	running int
+	waiters []chan struct{}
+}
+
+// newConcurrencyGroups creates groups with the given limits; groups
+// without one run a single call at a time
+func newConcurrencyGroups(limits map[string]int) *concurrencyGroups {
+	g := &concurrencyGroups{groups: make(map[string]*group)}
+	for name, limit := range limits {
+		g.setLimit(name, limit)
+	}
+	return g
+}
+
+// get returns the group called name, creating it; the caller holds mu
+func (g *concurrencyGroups) get(name string) *group {
+	gr, ok := g.groups[name]
+	if !ok {
+		gr = &group{limit: 1}
+		g.groups[name] = gr
+	}
+	return gr
+}
+
+// setLimit changes the number of calls of a group running at once; a
+// limit below 1 serializes them. Calls already running above a lowered
+// limit finish undisturbed.
+func (g *concurrencyGroups) setLimit(name string, limit int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	gr := g.get(name)
+	gr.limit = max(limit, 1)
+	for gr.running < gr.limit && len(gr.waiters) > 0 {
+		gr.running++
+		close(gr.waiters[0])
+		gr.waiters = gr.waiters[1:]
+	}
+}
+
+// acquire waits for a slot in the group called name until ctx is done
+func (g *concurrencyGroups) acquire(ctx context.Context, name string) error {
+	g.mu.Lock()
+	gr := g.get(name)
+	if gr.running < gr.limit && len(gr.waiters) == 0 {
+		gr.running++
+		g.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	gr.waiters = append(gr.waiters, ready)
+	g.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, w := range gr.waiters {
+		if w == ready {
+			gr.waiters = append(gr.waiters[:i], gr.waiters[i+1:]...)
+			return ctx.Err()
+		}
+	}
+	// The slot was handed over as ctx ended; pass it on
+	g.releaseLocked(gr)
+	return ctx.Err()
+}
+
+// release frees a slot of the group called name
+func (g *concurrencyGroups) release(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.releaseLocked(g.get(name))
+}
+
+// releaseLocked hands a freed slot to the next waiting call; the caller
+// holds mu
+func (g *concurrencyGroups) releaseLocked(gr *group) {
+	if gr.running <= gr.limit && len(gr.waiters) > 0 {
+		close(gr.waiters[0])
+		gr.waiters = gr.waiters[1:]
+		return
+	}
+	gr.running--
+}
+
+// status returns the state of every group sorted by name
+func (g *concurrencyGroups) status() []GroupStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	statuses := make([]GroupStatus, 0, len(g.groups))
+	for name, gr := range g.groups {
+		statuses = append(statuses, GroupStatus{Name: name, Limit: gr.limit, Running: gr.running, Waiting: len(gr.waiters)})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// middleware runs calls within the group called name. A call whose context
+// ends while it waits fails without running.
+func (g *concurrencyGroups) middleware(name string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := g.acquire(ctx, name); err != nil {
+				return nil, mcperrors.NewServiceUnavailableError("concurrency group "+name, "gave up waiting: "+err.Error())
+			}
+			defer g.release(name)
+			return next(ctx, request)
+		}
+	}
+}
+
+// SetConcurrencyLimit sets how many calls of the tools in a concurrency
+// group may run at once, across all connections. Groups without a limit
+// run one call at a time.
+func (r *Registry) SetConcurrencyLimit(name string, limit int) {
+	r.groups.setLimit(name, limit)
+}
+
+// ConcurrencyGroups returns the state of the concurrency groups in use
+func (r *Registry) ConcurrencyGroups() []GroupStatus {
+	return r.groups.status()
+}
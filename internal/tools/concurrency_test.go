@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingDefinition returns a tool in group whose calls block until
+// release is closed, counting the calls running at once
+func blockingDefinition(name, group string, running, peak *atomic.Int32, release <-chan struct{}) Definition {
+	return Definition{
+		Tool:             mcp.NewTool(name),
+		ConcurrencyGroup: group,
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			return mcp.NewToolResultText("ok"), nil
+		},
+	}
+}
+
+func TestConcurrencyGroups_Limit(t *testing.T) {
+	for _, limit := range []int{0, 1, 2} {
+		r := New(Config{ConcurrencyLimits: map[string]int{"db-write": limit}})
+		var running, peak atomic.Int32
+		release := make(chan struct{})
+		// Tools sharing a group share its slots
+		r.MustRegister(blockingDefinition("insert", "db-write", &running, &peak, release))
+		r.MustRegister(blockingDefinition("update", "db-write", &running, &peak, release))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				_, err := r.Call(context.Background(), callRequest(name))
+				assert.NoError(t, err)
+			}([]string{"insert", "update"}[i%2])
+		}
+
+		want := max(limit, 1)
+		require.Eventually(t, func() bool {
+			groups := r.ConcurrencyGroups()
+			return len(groups) == 1 && groups[0].Running == want && groups[0].Waiting == 6-want
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(want), peak.Load(), "limit %d", limit)
+		assert.Equal(t, []GroupStatus{{Name: "db-write", Limit: want}}, r.ConcurrencyGroups())
+	}
+}
+
+func TestConcurrencyGroups_GiveUpWaiting(t *testing.T) {
+	r := New(Config{})
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	r.MustRegister(blockingDefinition("commit", "git", &running, &peak, release))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Call(context.Background(), callRequest("commit"))
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return running.Load() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := r.Call(ctx, callRequest("commit"))
+	require.Error(t, err)
+	assert.Equal(t, mcperrors.ErrorCodeMCPServiceUnavail, mcperrors.FindMCPError(err).Code)
+	assert.Equal(t, []GroupStatus{{Name: "git", Limit: 1, Running: 1}}, r.ConcurrencyGroups())
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.Equal(t, []GroupStatus{{Name: "git", Limit: 1}}, r.ConcurrencyGroups())
+}
+
+func TestConcurrencyGroups_RaiseLimit(t *testing.T) {
+	r := New(Config{})
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	r.MustRegister(blockingDefinition("push", "git", &running, &peak, release))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.Call(context.Background(), callRequest("push"))
+			assert.NoError(t, err)
+		}()
+	}
+	require.Eventually(t, func() bool { return running.Load() == 1 }, time.Second, time.Millisecond)
+
+	// Waiting calls start as soon as the limit allows
+	r.SetConcurrencyLimit("git", 3)
+	require.Eventually(t, func() bool { return running.Load() == 3 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	info, _ := r.Get("push")
+	assert.Equal(t, "git", info.Group)
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DryRunMeta is the _meta key of a tools/call asking for a dry run: the
+// arguments are validated and the middleware (policy, quota) checks the
+// call as usual, but nothing is executed. Tools declaring DryRun get the
+// call and report what they would do; for the others the registry answers
+// with a DryRunPreview. Results of dry runs carry DryRunMeta set to true.
+const DryRunMeta = "dryRun"
+
+// DryRunPreview is the text content, as JSON, of a dry run of a tool that
+// does not support dry runs
+type DryRunPreview struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+
+	// Executed is always false; the call passed every check
+	Executed bool `json:"executed"`
+}
+
+// IsDryRun reports whether request asks for a dry run
+func IsDryRun(request mcp.CallToolRequest) bool {
+	return IsDryRunMeta(request.Params.Meta)
+}
+
+// IsDryRunMeta reports whether the _meta of a tools/call asks for a dry run
+func IsDryRunMeta(meta *mcp.Meta) bool {
+	if meta == nil {
+		return false
+	}
+	dryRun, _ := meta.AdditionalFields[DryRunMeta].(bool)
+	return dryRun
+}
+
+// dryRun answers dry runs of a tool, passing them to handler if the tool
+// supports them and previewing the call otherwise. Other calls go to next.
+func dryRun(def Definition, handler, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !IsDryRun(request) {
+			return next(ctx, request)
+		}
+
+		var result *mcp.CallToolResult
+		if def.DryRun {
+			var err error
+			if result, err = handler(ctx, request); err != nil || result == nil {
+				return result, err
+			}
+		} else {
+			arguments := request.GetArguments()
+			if arguments == nil {
+				arguments = map[string]any{}
+			}
+			data, err := json.Marshal(DryRunPreview{Tool: request.Params.Name, Arguments: arguments})
+			if err != nil {
+				return nil, err
+			}
+			result = mcp.NewToolResultText(string(data))
+		}
+		if result.Meta == nil {
+			result.Meta = make(map[string]any)
+		}
+		result.Meta[DryRunMeta] = true
+		return result, nil
+	}
+}
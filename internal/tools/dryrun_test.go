@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/schema"
+)
+
+// dryRunRequest is a dry run of name with arguments
+func dryRunRequest(name string, arguments map[string]any) mcp.CallToolRequest {
+	request := callRequest(name)
+	if arguments != nil {
+		request.Params.Arguments = arguments
+	}
+	request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{DryRunMeta: true, ids.IdempotencyKeyMeta: "k1"}}
+	return request
+}
+
+func TestRegistry_DryRun(t *testing.T) {
+	// The middleware stands for policy checks, which see dry runs too
+	denied := errors.New("denied")
+	policy := func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.GetString("path", "") == "/etc" {
+				return nil, denied
+			}
+			return next(ctx, request)
+		}
+	}
+	r := New(Config{
+		Middleware:        []server.ToolHandlerMiddleware{policy},
+		Idempotency:       NewIdempotencyCache(IdempotencyConfig{}),
+		Schemas:           schema.New(),
+		ValidateArguments: true,
+	})
+
+	var deletes, plans atomic.Int64
+	def := countingDefinition("delete", &deletes)
+	def.Tool = mcp.NewTool("delete", mcp.WithString("path", mcp.Required()), mcp.WithIdempotentHintAnnotation(true))
+	r.MustRegister(def)
+	r.MustRegister(Definition{
+		Tool:   mcp.NewTool("deploy"),
+		DryRun: true,
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if IsDryRun(request) {
+				plans.Add(1)
+				return mcp.NewToolResultText("would deploy v2"), nil
+			}
+			return mcp.NewToolResultText("deployed v2"), nil
+		},
+	})
+	ctx := context.Background()
+
+	result, err := r.Call(ctx, dryRunRequest("delete", map[string]any{"path": "/tmp/x"}))
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Meta[DryRunMeta])
+	var preview DryRunPreview
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &preview))
+	assert.Equal(t, DryRunPreview{Tool: "delete", Arguments: map[string]any{"path": "/tmp/x"}}, preview)
+	assert.Zero(t, deletes.Load())
+
+	// Checks still reject the call
+	_, err = r.Call(ctx, dryRunRequest("delete", nil))
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+	_, err = r.Call(ctx, dryRunRequest("delete", map[string]any{"path": "/etc"}))
+	assert.ErrorIs(t, err, denied)
+
+	// The dry run is not remembered under its idempotency key
+	_, err = r.Call(ctx, keyedRequest("delete", "k1", map[string]any{"path": "/tmp/x"}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deletes.Load())
+
+	// Tools declaring support plan the call themselves
+	result, err = r.Call(ctx, dryRunRequest("deploy", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "would deploy v2", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, true, result.Meta[DryRunMeta])
+	assert.Equal(t, int64(1), plans.Load())
+	info, _ := r.Get("deploy")
+	assert.True(t, info.DryRun)
+}
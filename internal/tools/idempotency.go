@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// IdempotencyConfig contains configuration for an IdempotencyCache
+type IdempotencyConfig struct {
+	// MaxEntries caps the number of remembered calls (defaults to 1000)
+	MaxEntries int
+
+	// TTL is how long the result of a call is remembered (defaults to 10m)
+	TTL time.Duration
+
+	// Scope separates the keys of different callers, e.g. by tenant; keys
+	// are shared by all callers when nil
+	Scope func(ctx context.Context) string
+
+	// Clock times TTL (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// IdempotencyCache remembers the results of calls to idempotent tools by
+// the idempotency key in their _meta, so a retried call returns the result
+// of the first one instead of running again. A retry arriving while the
+// first call is still running waits for it.
+type IdempotencyCache struct {
+	config IdempotencyConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// idempotentCall is a call remembered by its key
+type idempotentCall struct {
+	key       string
+	arguments [sha256.Size]byte
+	done      chan struct{}
+	result    *mcp.CallToolResult
+	err       error
+	completed time.Time
+}
+
+// NewIdempotencyCache creates an empty idempotency cache
+func NewIdempotencyCache(config IdempotencyConfig) *IdempotencyCache {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1000
+	}
+	if config.TTL <= 0 {
+		config.TTL = 10 * time.Minute
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &IdempotencyCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Middleware deduplicates calls carrying the same idempotency key. A key
+// reused with different arguments is rejected, and a call that fails with
+// an error is forgotten so that its retry runs again.
+func (c *IdempotencyCache) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key := idempotencyKey(request)
+		if key == "" {
+			return next(ctx, request)
+		}
+		if c.config.Scope != nil {
+			key = c.config.Scope(ctx) + "\x00" + key
+		}
+		key = request.Params.Name + "\x00" + key
+		data, _ := json.Marshal(request.Params.Arguments)
+		arguments := sha256.Sum256(data)
+
+		c.mu.Lock()
+		if call, ok := c.lookup(key); ok {
+			c.mu.Unlock()
+			if call.arguments != arguments {
+				return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+					fmt.Sprintf("idempotency key %s was used with different arguments", idempotencyKey(request)), nil)
+			}
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return cloneResult(call.result), call.err
+		}
+		call := &idempotentCall{key: key, arguments: arguments, done: make(chan struct{})}
+		c.store(call)
+		c.mu.Unlock()
+
+		call.result, call.err = next(ctx, request)
+		c.mu.Lock()
+		call.completed = c.config.Clock.Now()
+		if call.err != nil {
+			c.remove(call)
+		}
+		c.mu.Unlock()
+		close(call.done)
+		return cloneResult(call.result), call.err
+	}
+}
+
+// Len returns the number of remembered calls, including those in progress
+func (c *IdempotencyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// lookup returns the call of key unless it expired, marking it recently
+// used. It must be called with c.mu held.
+func (c *IdempotencyCache) lookup(key string) (*idempotentCall, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	call := elem.Value.(*idempotentCall)
+	if !call.completed.IsZero() && c.config.Clock.Since(call.completed) >= c.config.TTL {
+		c.remove(call)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return call, true
+}
+
+// store remembers call, forgetting the least recently used calls beyond
+// MaxEntries. Calls still running when forgotten complete normally.
+func (c *IdempotencyCache) store(call *idempotentCall) {
+	c.entries[call.key] = c.lru.PushFront(call)
+	for c.lru.Len() > c.config.MaxEntries {
+		c.remove(c.lru.Back().Value.(*idempotentCall))
+	}
+}
+
+// remove forgets call unless its key was stored again since
+func (c *IdempotencyCache) remove(call *idempotentCall) {
+	if elem, ok := c.entries[call.key]; ok && elem.Value == call {
+		delete(c.entries, call.key)
+		c.lru.Remove(elem)
+	}
+}
+
+// idempotencyKey returns the idempotency key in the _meta of request
+func idempotencyKey(request mcp.CallToolRequest) string {
+	if request.Params.Meta == nil {
+		return ""
+	}
+	key, _ := request.Params.Meta.AdditionalFields[ids.IdempotencyKeyMeta].(string)
+	return key
+}
+
+// isIdempotent reports whether tool is annotated as idempotent
+func isIdempotent(tool mcp.Tool) bool {
+	return tool.Annotations.IdempotentHint != nil && *tool.Annotations.IdempotentHint
+}
+
+// cloneResult copies result so that callers sharing it cannot modify each
+// other's content list
+func cloneResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil {
+		return nil
+	}
+	clone := *result
+	clone.Content = slices.Clone(result.Content)
+	return &clone
+}
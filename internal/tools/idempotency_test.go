@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// keyedRequest is a call of name with an idempotency key
+func keyedRequest(name, key string, arguments map[string]any) mcp.CallToolRequest {
+	request := callRequest(name)
+	request.Params.Arguments = arguments
+	request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{ids.IdempotencyKeyMeta: key}}
+	return request
+}
+
+// countingDefinition is an idempotent tool counting its calls
+func countingDefinition(name string, calls *atomic.Int64) Definition {
+	return Definition{
+		Tool: mcp.NewTool(name, mcp.WithIdempotentHintAnnotation(true)),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls.Add(1)
+			return mcp.NewToolResultText("ok"), nil
+		},
+	}
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1700000000, 0))
+	r := New(Config{Idempotency: NewIdempotencyCache(IdempotencyConfig{TTL: time.Minute, Clock: fake})})
+
+	var idempotent, other atomic.Int64
+	r.MustRegister(countingDefinition("put", &idempotent))
+	def := countingDefinition("append", &other)
+	def.Tool.Annotations.IdempotentHint = mcp.ToBoolPtr(false)
+	r.MustRegister(def)
+
+	ctx := context.Background()
+	args := map[string]any{"value": 1}
+	for i := 0; i < 3; i++ {
+		result, err := r.Call(ctx, keyedRequest("put", "k1", args))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result.Content[0].(mcp.TextContent).Text)
+		_, err = r.Call(ctx, keyedRequest("append", "k1", args))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), idempotent.Load())
+	assert.Equal(t, int64(3), other.Load(), "tools not annotated idempotent always run")
+
+	// Calls without a key, or with another key, run
+	_, err := r.Call(ctx, callRequest("put"))
+	require.NoError(t, err)
+	_, err = r.Call(ctx, keyedRequest("put", "k2", args))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), idempotent.Load())
+
+	// Reusing a key with other arguments is an error
+	_, err = r.Call(ctx, keyedRequest("put", "k1", map[string]any{"value": 2}))
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+
+	// Results expire after TTL
+	fake.Advance(time.Minute)
+	_, err = r.Call(ctx, keyedRequest("put", "k1", args))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), idempotent.Load())
+}
+
+func TestIdempotencyCache_ConcurrentRetries(t *testing.T) {
+	cache := NewIdempotencyCache(IdempotencyConfig{})
+	release := make(chan struct{})
+	var calls atomic.Int64
+	handler := cache.Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls.Add(1)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*mcp.CallToolResult, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = handler(context.Background(), keyedRequest("put", "k", nil))
+		}()
+	}
+	require.Eventually(t, func() bool { return calls.Load() == 1 && cache.Len() == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), calls.Load())
+	for _, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, "done", result.Content[0].(mcp.TextContent).Text)
+	}
+}
+
+func TestIdempotencyCache_ForgetsFailuresAndBounds(t *testing.T) {
+	cache := NewIdempotencyCache(IdempotencyConfig{
+		MaxEntries: 2,
+		Scope:      func(ctx context.Context) string { return "tenant" },
+	})
+	var calls atomic.Int64
+	fail := true
+	handler := cache.Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls.Add(1)
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	ctx := context.Background()
+	_, err := handler(ctx, keyedRequest("put", "a", nil))
+	assert.Error(t, err)
+	fail = false
+	_, err = handler(ctx, keyedRequest("put", "a", nil))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), calls.Load(), "a failed call is retried")
+
+	for _, key := range []string{"b", "c"} {
+		_, err = handler(ctx, keyedRequest("put", key, nil))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, cache.Len())
+
+	// "a" was the least recently used and runs again
+	_, err = handler(ctx, keyedRequest("put", "a", nil))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), calls.Load())
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// AdminMethod is the admin RPC method listing and toggling local tools
+const AdminMethod = "admin/tools"
+
+// AuthFunc decides whether the caller may invoke a tool
+type AuthFunc func(ctx context.Context, tool string) error
+
+// RequireAuth rejects calls for which authFunc returns an error
+func RequireAuth(authFunc AuthFunc) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := authFunc(ctx, request.Params.Name); err != nil {
+				return nil, mcperrors.NewUnauthorizedError(request.Params.Name).
+					WithDebugInfo("reason", err.Error())
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// RateLimit allows at most limit calls per window across all callers
+func RateLimit(limit int, window time.Duration) server.ToolHandlerMiddleware {
+	var (
+		mu          sync.Mutex
+		windowStart time.Time
+		count       int
+	)
+
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			mu.Lock()
+			now := time.Now()
+			if now.Sub(windowStart) >= window {
+				windowStart, count = now, 0
+			}
+			allowed := count < limit
+			if allowed {
+				count++
+			}
+			mu.Unlock()
+
+			if !allowed {
+				return nil, mcperrors.NewRateLimitError(limit, window.String())
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// AdminParams are the parameters of the admin/tools method
+type AdminParams struct {
+	// Action is "list" (default), "enable" or "disable"
+	Action string `json:"action,omitempty"`
+
+	// Name is the tool to enable or disable
+	Name string `json:"name,omitempty"`
+
+	// Tag limits the list to tools carrying it
+	Tag string `json:"tag,omitempty"`
+}
+
+// AdminResult is the result of the admin/tools method
+type AdminResult struct {
+	Tools  []Info        `json:"tools"`
+	Groups []GroupStatus `json:"concurrencyGroups,omitempty"`
+}
+
+// AdminHandler returns a router handler listing tools and enabling or
+// disabling them at runtime. Register it under AdminMethod.
+func AdminHandler(r *Registry) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params AdminParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+
+		var err error
+		switch params.Action {
+		case "", "list":
+		case "enable":
+			err = r.Enable(params.Name)
+		case "disable":
+			err = r.Disable(params.Name)
+		default:
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unknown action: "+params.Action), req.ID)
+		}
+		if err != nil {
+			return jsonrpc.NewErrorResponse(mcperrors.NewToolNotFoundError(params.Name).ToJSONRPCError(), req.ID)
+		}
+
+		return jsonrpc.NewResponse(AdminResult{Tools: r.List(params.Tag), Groups: r.ConcurrencyGroups()}, req.ID)
+	})
+}
@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// ReceiptMeta is the _meta key of the execution receipt in the result of a
+// side-effecting tool. A client unsure whether a call ran re-submits it with
+// the receipt's ID under this key in the call's _meta; the recorded result
+// is returned instead of running the tool again.
+const ReceiptMeta = "receipt"
+
+// ReceiptMethod is the RPC method looking up an execution receipt
+const ReceiptMethod = "tools/receipt"
+
+// Receipt records that a call of a side-effecting tool was executed
+type Receipt struct {
+	ID         string    `json:"id"`
+	Tool       string    `json:"tool"`
+	ExecutedAt time.Time `json:"executedAt"`
+
+	// ArgumentsHash is "sha256:" and the hex digest of the call's arguments
+	// as JSON
+	ArgumentsHash string `json:"argumentsHash"`
+
+	// IsError is set when the tool reported a failure in its result
+	IsError bool `json:"isError,omitempty"`
+
+	// DuplicateOf names an earlier receipt within the window for the same
+	// tool and arguments, e.g. when an agent repeated a step
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+
+	// Replayed is set on results returned for a re-submission
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// ReceiptConfig contains configuration for a ReceiptStore
+type ReceiptConfig struct {
+	// Window is how long receipts and their results are kept (defaults to
+	// 1h)
+	Window time.Duration
+
+	// MaxEntries caps the number of kept receipts (defaults to 10000)
+	MaxEntries int
+
+	// Scope separates the receipts of different callers, e.g. by tenant;
+	// receipts are shared by all callers when nil
+	Scope func(ctx context.Context) string
+
+	// Clock times Window and receipts (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// ReceiptStore issues execution receipts for calls of tools not annotated
+// read-only and keeps them, with their results, for a window
+type ReceiptStore struct {
+	config ReceiptConfig
+
+	mu    sync.Mutex
+	byID  map[string]*list.Element
+	calls map[string]string
+	order *list.List
+}
+
+// receiptEntry is a kept receipt
+type receiptEntry struct {
+	receipt Receipt
+	scope   string
+	call    string
+	result  *mcp.CallToolResult
+}
+
+// NewReceiptStore creates an empty receipt store
+func NewReceiptStore(config ReceiptConfig) *ReceiptStore {
+	if config.Window <= 0 {
+		config.Window = time.Hour
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &ReceiptStore{
+		config: config,
+		byID:   make(map[string]*list.Element),
+		calls:  make(map[string]string),
+		order:  list.New(),
+	}
+}
+
+// Middleware adds a receipt to the results of calls and answers
+// re-submissions carrying one. A re-submission whose receipt is unknown,
+// expired or issued for other arguments is rejected rather than run.
+// Calls failing with an error get no receipt.
+func (s *ReceiptStore) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		scope := ""
+		if s.config.Scope != nil {
+			scope = s.config.Scope(ctx)
+		}
+		hash := argumentsHash(request.Params.Arguments)
+		if id := receiptID(request); id != "" {
+			return s.replay(scope, id, request.Params.Name, hash)
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		receipt := s.issue(scope, request.Params.Name, hash, result)
+		if result.Meta == nil {
+			result.Meta = make(map[string]any)
+		}
+		result.Meta[ReceiptMeta] = receipt
+		return result, nil
+	}
+}
+
+// Get returns the receipt id issued in scope unless it expired
+func (s *ReceiptStore) Get(scope, id string) (Receipt, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.lookup(scope, id)
+	if !ok {
+		return Receipt{}, false
+	}
+	return entry.receipt, true
+}
+
+// Len returns the number of kept receipts, including expired ones not yet
+// dropped
+func (s *ReceiptStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// replay returns the recorded result of receipt id for a re-submitted call
+func (s *ReceiptStore) replay(scope, id, tool, hash string) (*mcp.CallToolResult, error) {
+	s.mu.Lock()
+	entry, ok := s.lookup(scope, id)
+	s.mu.Unlock()
+	if !ok {
+		return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+			fmt.Sprintf("receipt %s is unknown or expired; the call was not run", id), nil)
+	}
+	if entry.receipt.Tool != tool || entry.receipt.ArgumentsHash != hash {
+		return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+			fmt.Sprintf("receipt %s was issued for another call", id), nil)
+	}
+
+	result := cloneResult(entry.result)
+	result.Meta = maps.Clone(entry.result.Meta)
+	if result.Meta == nil {
+		result.Meta = make(map[string]any)
+	}
+	receipt := entry.receipt
+	receipt.Replayed = true
+	result.Meta[ReceiptMeta] = receipt
+	return result, nil
+}
+
+// issue records a receipt for a completed call with result
+func (s *ReceiptStore) issue(scope, tool, hash string, result *mcp.CallToolResult) Receipt {
+	receipt := Receipt{
+		ID:            ids.NewUUIDv7(),
+		Tool:          tool,
+		ExecutedAt:    s.config.Clock.Now(),
+		ArgumentsHash: hash,
+		IsError:       result.IsError,
+	}
+	call := scope + "\x00" + tool + "\x00" + hash
+	// Middleware outside adds to the _meta of the result being returned
+	stored := cloneResult(result)
+	stored.Meta = maps.Clone(result.Meta)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	if previous, ok := s.calls[call]; ok {
+		receipt.DuplicateOf = previous
+	}
+	s.calls[call] = receipt.ID
+	s.byID[receipt.ID] = s.order.PushBack(&receiptEntry{
+		receipt: receipt,
+		scope:   scope,
+		call:    call,
+		result:  stored,
+	})
+	for s.order.Len() > s.config.MaxEntries {
+		s.remove(s.order.Front())
+	}
+	return receipt
+}
+
+// lookup returns the unexpired entry of id issued in scope. It must be
+// called with s.mu held.
+func (s *ReceiptStore) lookup(scope, id string) (*receiptEntry, bool) {
+	s.expire()
+	elem, ok := s.byID[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*receiptEntry)
+	if entry.scope != scope {
+		return nil, false
+	}
+	return entry, true
+}
+
+// expire drops the receipts older than the window. It must be called with
+// s.mu held.
+func (s *ReceiptStore) expire() {
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		if s.config.Clock.Since(elem.Value.(*receiptEntry).receipt.ExecutedAt) < s.config.Window {
+			return
+		}
+		s.remove(elem)
+	}
+}
+
+// remove drops the receipt of elem. It must be called with s.mu held.
+func (s *ReceiptStore) remove(elem *list.Element) {
+	entry := elem.Value.(*receiptEntry)
+	s.order.Remove(elem)
+	delete(s.byID, entry.receipt.ID)
+	if s.calls[entry.call] == entry.receipt.ID {
+		delete(s.calls, entry.call)
+	}
+}
+
+// ReceiptParams are the parameters of the tools/receipt method
+type ReceiptParams struct {
+	ID string `json:"id"`
+}
+
+// ReceiptResult is the result of the tools/receipt method
+type ReceiptResult struct {
+	Receipt Receipt `json:"receipt"`
+}
+
+// ReceiptHandler returns a router handler looking up a receipt issued to
+// the caller's scope. Register it under ReceiptMethod.
+func ReceiptHandler(s *ReceiptStore) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+		var params ReceiptParams
+		if err := req.BindParams(&params); err != nil {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError(err.Error()), req.ID)
+		}
+		if params.ID == "" {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("id is required"), req.ID)
+		}
+
+		scope := ""
+		if s.config.Scope != nil {
+			scope = s.config.Scope(ctx)
+		}
+		receipt, ok := s.Get(scope, params.ID)
+		if !ok {
+			return jsonrpc.NewErrorResponse(jsonrpc.NewInvalidParamsError("unknown or expired receipt: "+params.ID), req.ID)
+		}
+		return jsonrpc.NewResponse(ReceiptResult{Receipt: receipt}, req.ID)
+	})
+}
+
+// receiptID returns the receipt ID in the _meta of request
+func receiptID(request mcp.CallToolRequest) string {
+	if request.Params.Meta == nil {
+		return ""
+	}
+	id, _ := request.Params.Meta.AdditionalFields[ReceiptMeta].(string)
+	return id
+}
+
+// argumentsHash returns the hash of arguments as recorded in receipts
+func argumentsHash(arguments any) string {
+	data, _ := json.Marshal(arguments)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// isReadOnly reports whether tool is annotated as read-only
+func isReadOnly(tool mcp.Tool) bool {
+	return tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+}
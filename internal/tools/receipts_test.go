@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// resubmitted is a call of name carrying receipt id
+func resubmitted(name, id string, arguments map[string]any) mcp.CallToolRequest {
+	request := callRequest(name)
+	request.Params.Arguments = arguments
+	request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{ReceiptMeta: id}}
+	return request
+}
+
+func TestReceiptStore(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1700000000, 0))
+	receipts := NewReceiptStore(ReceiptConfig{Window: time.Minute, Clock: fake})
+	r := New(Config{Receipts: receipts})
+
+	var sends, reads atomic.Int64
+	send := countingDefinition("send", &sends)
+	send.Tool = mcp.NewTool("send")
+	r.MustRegister(send)
+	read := countingDefinition("read", &reads)
+	read.Tool = mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true))
+	r.MustRegister(read)
+
+	ctx := context.Background()
+	args := map[string]any{"to": "ops"}
+	request := callRequest("send")
+	request.Params.Arguments = args
+	result, err := r.Call(ctx, request)
+	require.NoError(t, err)
+	receipt := result.Meta[ReceiptMeta].(Receipt)
+	assert.NotEmpty(t, receipt.ID)
+	assert.Equal(t, "send", receipt.Tool)
+	assert.Equal(t, fake.Now(), receipt.ExecutedAt)
+	assert.Contains(t, receipt.ArgumentsHash, "sha256:")
+
+	result, err = r.Call(ctx, callRequest("read"))
+	require.NoError(t, err)
+	assert.NotContains(t, result.Meta, ReceiptMeta, "read-only tools get no receipt")
+
+	// Re-submitting returns the recorded result without running again
+	result, err = r.Call(ctx, resubmitted("send", receipt.ID, args))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Content[0].(mcp.TextContent).Text)
+	replayed := result.Meta[ReceiptMeta].(Receipt)
+	assert.True(t, replayed.Replayed)
+	assert.Equal(t, receipt.ID, replayed.ID)
+	assert.Equal(t, int64(1), sends.Load())
+
+	_, err = r.Call(ctx, resubmitted("send", receipt.ID, map[string]any{"to": "dev"}))
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+
+	// Repeating the call runs it, pointing at the earlier receipt
+	result, err = r.Call(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, receipt.ID, result.Meta[ReceiptMeta].(Receipt).DuplicateOf)
+	assert.Equal(t, int64(2), sends.Load())
+
+	// Receipts expire after the window; an expired one is not run again
+	fake.Advance(time.Minute)
+	_, err = r.Call(ctx, resubmitted("send", receipt.ID, args))
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+	assert.Equal(t, int64(2), sends.Load())
+	assert.Zero(t, receipts.Len())
+}
+
+func TestReceiptHandler(t *testing.T) {
+	type tenantKey struct{}
+	receipts := NewReceiptStore(ReceiptConfig{
+		Scope: func(ctx context.Context) string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return tenant
+		},
+	})
+	r := New(Config{Receipts: receipts})
+	r.MustRegister(echoDefinition())
+
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	result, err := r.Call(acme, callRequest("echo"))
+	require.NoError(t, err)
+	id := result.Meta[ReceiptMeta].(Receipt).ID
+
+	handler := ReceiptHandler(receipts)
+	response := handler.Handle(acme, &jsonrpc.Request{Version: jsonrpc.Version, Method: ReceiptMethod,
+		Params: map[string]any{"id": id}, ID: 1})
+	require.Nil(t, response.Error)
+	assert.Equal(t, "echo", response.Result.(ReceiptResult).Receipt.Tool)
+
+	// Other scopes cannot see it
+	response = handler.Handle(context.Background(), &jsonrpc.Request{Version: jsonrpc.Version, Method: ReceiptMethod,
+		Params: map[string]any{"id": id}, ID: 2})
+	require.NotNil(t, response.Error)
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, response.Error.Code)
+	_, err = r.Call(context.Background(), resubmitted("echo", id, nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// PartialResultsCapability is the experimental client capability announcing
+// that the client assembles partial tool results
+const PartialResultsCapability = "partialResults"
+
+// Fields of partial results
+const (
+	// PartialContentParam is the progress notification param carrying a
+	// content fragment: {"index": n, "content": [...]}. mcp-go drops _meta
+	// from notifications sent with params, so it is not used here.
+	PartialContentParam = "partialContent"
+
+	// PartialChunksMeta is the _meta key of a final result telling how many
+	// fragments preceded it
+	PartialChunksMeta = "partialChunks"
+)
+
+// ErrNotStreaming is returned by SendPartial outside the call of a streaming
+// tool, including after the handler returned
+var ErrNotStreaming = errors.New("tool does not stream results")
+
+// stream collects the partial results of one call of a streaming tool
+type stream struct {
+	// send delivers a fragment to the client; nil means fragments are
+	// assembled into the final result instead
+	send func(index int, content []mcp.Content) error
+
+	mu     sync.Mutex
+	chunks []mcp.Content
+	sent   int
+	done   bool
+}
+
+// streamKey is the context key of the stream of a call
+type streamKey struct{}
+
+// SendPartial delivers content as a partial result of the streaming tool
+// call of ctx. Clients that accept partial results get it immediately in a
+// progress notification; for the others it is prepended to the final
+// result.
+func SendPartial(ctx context.Context, content ...mcp.Content) error {
+	s, ok := ctx.Value(streamKey{}).(*stream)
+	if !ok {
+		return ErrNotStreaming
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return ErrNotStreaming
+	}
+	if s.send == nil {
+		s.chunks = append(s.chunks, content...)
+		return nil
+	}
+	s.sent++
+	return s.send(s.sent, content)
+}
+
+// streaming lets handler send partial results with SendPartial. Fragments
+// are sent live when the client accepts partial results and asked for
+// progress; the final result then references them in _meta.
+func (r *Registry) streaming(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s := &stream{}
+		var token mcp.ProgressToken
+		if request.Params.Meta != nil {
+			token = request.Params.Meta.ProgressToken
+		}
+		srv := server.ServerFromContext(ctx)
+		if token != nil && srv != nil && r.config.PartialResults != nil && r.config.PartialResults(ctx) {
+			s.send = func(index int, content []mcp.Content) error {
+				n := protocolmcp.NewProgressNotification(token, float64(index), 0, "")
+				n.Params[PartialContentParam] = map[string]any{"index": index, "content": content}
+				return srv.SendNotificationToClient(ctx, n.Method, n.Params)
+			}
+		}
+
+		result, err := handler(context.WithValue(ctx, streamKey{}, s), request)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.done = true
+		if err != nil || result == nil {
+			return result, err
+		}
+		if s.send == nil {
+			if len(s.chunks) > 0 {
+				result.Content = append(s.chunks, result.Content...)
+			}
+			return result, nil
+		}
+		if result.Meta == nil {
+			result.Meta = make(map[string]any)
+		}
+		result.Meta[PartialChunksMeta] = s.sent
+		return result, nil
+	}
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storyDefinition is a streaming tool sending two fragments before its
+// result
+func storyDefinition() Definition {
+	return Definition{
+		Tool:      mcp.NewTool("story"),
+		Streaming: true,
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			for _, part := range []string{"once", "upon"} {
+				if err := SendPartial(ctx, mcp.NewTextContent(part)); err != nil {
+					return nil, err
+				}
+			}
+			return mcp.NewToolResultText("the end"), nil
+		},
+	}
+}
+
+// testSession is a client session collecting notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) SessionID() string { return "test" }
+
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+
+func (s *testSession) Initialize() {}
+
+func (s *testSession) Initialized() bool { return true }
+
+func TestStreaming_Assembled(t *testing.T) {
+	r := New(Config{})
+	r.MustRegister(storyDefinition())
+
+	result, err := r.Call(context.Background(), callRequest("story"))
+	require.NoError(t, err)
+	var texts []string
+	for _, content := range result.Content {
+		texts = append(texts, content.(mcp.TextContent).Text)
+	}
+	assert.Equal(t, []string{"once", "upon", "the end"}, texts)
+	assert.NotContains(t, result.Meta, PartialChunksMeta)
+
+	info, _ := r.Get("story")
+	assert.True(t, info.Streaming)
+	assert.ErrorIs(t, SendPartial(context.Background(), mcp.NewTextContent("x")), ErrNotStreaming)
+}
+
+func TestStreaming_Live(t *testing.T) {
+	srv := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	partial := true
+	r := New(Config{Server: srv, PartialResults: func(ctx context.Context) bool { return partial }})
+	r.MustRegister(storyDefinition())
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+	ctx := srv.WithContext(context.Background(), session)
+	call := func(meta string) (content []string, chunks any) {
+		response := srv.HandleMessage(ctx, json.RawMessage(
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"story"`+meta+`}}`))
+		data, err := json.Marshal(response)
+		require.NoError(t, err)
+		var decoded struct {
+			Result struct {
+				Meta    map[string]any    `json:"_meta"`
+				Content []mcp.TextContent `json:"content"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		for _, c := range decoded.Result.Content {
+			content = append(content, c.Text)
+		}
+		return content, decoded.Result.Meta[PartialChunksMeta]
+	}
+
+	content, chunks := call(`,"_meta":{"progressToken":"tok"}`)
+	assert.Equal(t, []string{"the end"}, content)
+	assert.EqualValues(t, 2, chunks)
+	for i, want := range []string{"once", "upon"} {
+		n := <-session.notifications
+		assert.Equal(t, "notifications/progress", n.Method)
+		assert.Equal(t, "tok", n.Params.AdditionalFields["progressToken"])
+		assert.Equal(t, float64(i+1), n.Params.AdditionalFields["progress"])
+		fragment := n.Params.AdditionalFields[PartialContentParam].(map[string]any)
+		assert.Equal(t, i+1, fragment["index"])
+		assert.Equal(t, want, fragment["content"].([]mcp.Content)[0].(mcp.TextContent).Text)
+	}
+
+	// Without a progress token, or the capability, fragments are assembled
+	content, _ = call(``)
+	assert.Equal(t, []string{"once", "upon", "the end"}, content)
+	partial = false
+	content, _ = call(`,"_meta":{"progressToken":"tok"}`)
+	assert.Equal(t, []string{"once", "upon", "the end"}, content)
+	assert.Empty(t, session.notifications)
+}
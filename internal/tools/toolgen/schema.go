@@ -0,0 +1,99 @@
+package toolgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Schema is the subset of JSON Schema understood by the generator
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+}
+
+// isStruct reports whether the schema maps to a generated struct
+func (s *Schema) isStruct() bool {
+	return s.Type == "object" && len(s.Properties) > 0
+}
+
+// isRequired reports whether name is a required property
+func (s *Schema) isRequired(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyNames returns the property names in a stable order
+func (s *Schema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scalarType returns the Go type of a non-struct schema
+func scalarType(s *Schema) (string, error) {
+	switch s.Type {
+	case "string":
+		return "string", nil
+	case "number":
+		return "float64", nil
+	case "integer":
+		return "int64", nil
+	case "boolean":
+		return "bool", nil
+	case "object":
+		return "map[string]interface{}", nil
+	case "", "any":
+		return "interface{}", nil
+	default:
+		return "", fmt.Errorf("unsupported schema type %q", s.Type)
+	}
+}
+
+// initialisms are written in upper case in Go identifiers
+var initialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "uri": true, "url": true, "uuid": true,
+}
+
+// exportedName converts a property or tool name into an exported Go
+// identifier
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
@@ -0,0 +1,156 @@
+// Code generated by toolgen from search.json; DO NOT EDIT.
+
+package toolgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// searchIssuesInputSchema is the input schema of the search_issues tool
+const searchIssuesInputSchema = `{"type":"object","properties":{"query":{"type":"string","description":"Search terms","minLength":1,"maxLength":256},"limit":{"type":"integer","minimum":1,"maximum":100},"state":{"type":"string","enum":["open","closed"]},"labels":{"type":"array","items":{"type":"string","minLength":1},"maxItems":5},"repo":{"type":"object","properties":{"owner":{"type":"string"},"name":{"type":"string"}},"required":["owner","name"]},"filters":{"type":"array","items":{"type":"object","properties":{"field":{"type":"string"},"value":{}},"required":["field"]}}},"required":["query","repo"]}`
+
+// SearchIssuesTool returns the search_issues tool definition
+func SearchIssuesTool() mcp.Tool {
+	return mcp.NewToolWithRawSchema("search_issues", "Search issues in a repository", json.RawMessage(searchIssuesInputSchema))
+}
+
+// SearchIssuesParams is generated from the tool's JSON Schema
+type SearchIssuesParams struct {
+	Filters []SearchIssuesParamsFiltersItem `json:"filters,omitempty"`
+	Labels  []string                        `json:"labels,omitempty"`
+	Limit   *int64                          `json:"limit,omitempty"`
+	// Search terms
+	Query string                 `json:"query"`
+	Repo  SearchIssuesParamsRepo `json:"repo"`
+	State *string                `json:"state,omitempty"`
+}
+
+// validate checks constraints the JSON decoder cannot; raw holds the
+// undecoded arguments so missing required properties are detected
+func (p *SearchIssuesParams) validate(raw map[string]interface{}, path string) error {
+	items, _ := raw["filters"].([]interface{})
+	for i := range p.Filters {
+		m, _ := items[i].(map[string]interface{})
+		if err := p.Filters[i].validate(m, fmt.Sprintf("%s[%d]", path+"filters", i)+"."); err != nil {
+			return err
+		}
+	}
+	if len(p.Labels) > 5 {
+		return fmt.Errorf("%s must have at most 5 items", path+"labels")
+	}
+	for i, v := range p.Labels {
+		if len([]rune(v)) < 1 {
+			return fmt.Errorf("%s must be at least 1 characters", fmt.Sprintf("%s[%d]", path+"labels", i))
+		}
+	}
+	if p.Limit != nil {
+		if float64(*p.Limit) < 1 {
+			return fmt.Errorf("%s must be >= 1", path+"limit")
+		}
+		if float64(*p.Limit) > 100 {
+			return fmt.Errorf("%s must be <= 100", path+"limit")
+		}
+	}
+	if _, ok := raw["query"]; !ok {
+		return fmt.Errorf("%s is required", path+"query")
+	}
+	if len([]rune(p.Query)) < 1 {
+		return fmt.Errorf("%s must be at least 1 characters", path+"query")
+	}
+	if len([]rune(p.Query)) > 256 {
+		return fmt.Errorf("%s must be at most 256 characters", path+"query")
+	}
+	if _, ok := raw["repo"]; !ok {
+		return fmt.Errorf("%s is required", path+"repo")
+	}
+	if m, ok := raw["repo"].(map[string]interface{}); ok {
+		if err := p.Repo.validate(m, path+"repo"+"."); err != nil {
+			return err
+		}
+	}
+	if p.State != nil {
+		switch *p.State {
+		case "open", "closed":
+		default:
+			return fmt.Errorf("%s must be one of open, closed", path+"state")
+		}
+	}
+	return nil
+}
+
+// SearchIssuesResult is generated from the tool's JSON Schema
+type SearchIssuesResult struct {
+	IssueIds []int64 `json:"issueIds"`
+	Total    int64   `json:"total"`
+}
+
+// SearchIssuesParamsFiltersItem is generated from the tool's JSON Schema
+type SearchIssuesParamsFiltersItem struct {
+	Field string      `json:"field"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// validate checks constraints the JSON decoder cannot; raw holds the
+// undecoded arguments so missing required properties are detected
+func (p *SearchIssuesParamsFiltersItem) validate(raw map[string]interface{}, path string) error {
+	if _, ok := raw["field"]; !ok {
+		return fmt.Errorf("%s is required", path+"field")
+	}
+	return nil
+}
+
+// SearchIssuesParamsRepo is generated from the tool's JSON Schema
+type SearchIssuesParamsRepo struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+// validate checks constraints the JSON decoder cannot; raw holds the
+// undecoded arguments so missing required properties are detected
+func (p *SearchIssuesParamsRepo) validate(raw map[string]interface{}, path string) error {
+	if _, ok := raw["name"]; !ok {
+		return fmt.Errorf("%s is required", path+"name")
+	}
+	if _, ok := raw["owner"]; !ok {
+		return fmt.Errorf("%s is required", path+"owner")
+	}
+	return nil
+}
+
+// ParseSearchIssuesParams decodes and validates tool arguments
+func ParseSearchIssuesParams(args map[string]interface{}) (SearchIssuesParams, error) {
+	var params SearchIssuesParams
+	data, err := json.Marshal(args)
+	if err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return params, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return params, params.validate(args, "")
+}
+
+// SearchIssuesHandler adapts a typed function to an mcp-go tool handler. Invalid
+// arguments are reported as a tool error result.
+func SearchIssuesHandler(fn func(ctx context.Context, params SearchIssuesParams) (SearchIssuesResult, error)) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params, err := ParseSearchIssuesParams(request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result, err := fn(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
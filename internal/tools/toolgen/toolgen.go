@@ -0,0 +1,371 @@
+// Package toolgen generates typed handlers for MCP tools from their JSON
+// Schema. For a tool definition it emits a params struct, argument parsing
+// with validation, an optional result struct and an adapter turning a typed
+// function into an mcp-go tool handler. It backs the toolgen command, which
+// is meant to be run from go:generate.
+package toolgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// Config controls code generation
+type Config struct {
+	// Package is the package name of the generated file
+	Package string
+
+	// TypeName prefixes the generated identifiers (defaults to the tool
+	// name in Go case)
+	TypeName string
+
+	// Source names the definition file in the generated header
+	Source string
+}
+
+// Definition is an MCP tool definition with an optional output schema
+type Definition struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  json.RawMessage `json:"inputSchema"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+}
+
+// pendingType is a struct waiting to be emitted
+type pendingType struct {
+	name     string
+	schema   *Schema
+	validate bool
+}
+
+// generator accumulates the generated source
+type generator struct {
+	buf     bytes.Buffer
+	pending []pendingType
+}
+
+// Generate returns gofmt-ed Go source for the tool definition in JSON
+func Generate(definition []byte, config Config) ([]byte, error) {
+	var def Definition
+	if err := json.Unmarshal(definition, &def); err != nil {
+		return nil, fmt.Errorf("parse tool definition: %w", err)
+	}
+	if def.Name == "" {
+		return nil, errors.New("tool definition has no name")
+	}
+	if config.Package == "" {
+		return nil, errors.New("package name is required")
+	}
+	if config.TypeName == "" {
+		config.TypeName = exportedName(def.Name)
+	}
+
+	input, err := parseSchema(def.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("input schema: %w", err)
+	}
+	if input.Type != "object" {
+		return nil, errors.New("input schema must be an object")
+	}
+
+	var output *Schema
+	if len(def.OutputSchema) > 0 {
+		if output, err = parseSchema(def.OutputSchema); err != nil {
+			return nil, fmt.Errorf("output schema: %w", err)
+		}
+		if !output.isStruct() {
+			return nil, errors.New("output schema must be an object with properties")
+		}
+	}
+
+	compact := new(bytes.Buffer)
+	if err := json.Compact(compact, def.InputSchema); err != nil {
+		return nil, fmt.Errorf("input schema: %w", err)
+	}
+
+	g := &generator{}
+	g.header(config, def, compact.String())
+
+	name := config.TypeName
+	g.pending = append(g.pending, pendingType{name + "Params", input, true})
+	if output != nil {
+		g.pending = append(g.pending, pendingType{name + "Result", output, false})
+	}
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := g.writeStruct(next); err != nil {
+			return nil, err
+		}
+	}
+
+	g.writeParse(name)
+	g.writeHandler(name, output != nil)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// parseSchema decodes a JSON Schema document
+func parseSchema(raw json.RawMessage) (*Schema, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("schema is empty")
+	}
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// printf appends formatted source
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// header writes the package clause, imports and tool definition
+func (g *generator) header(config Config, def Definition, inputSchema string) {
+	source := config.Source
+	if source == "" {
+		source = def.Name
+	}
+
+	g.printf("// Code generated by toolgen from %s; DO NOT EDIT.\n\n", source)
+	g.printf("package %s\n\n", config.Package)
+	g.printf("import (\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\n")
+	g.printf("\t\"github.com/mark3labs/mcp-go/mcp\"\n\t\"github.com/mark3labs/mcp-go/server\"\n)\n\n")
+
+	g.printf("// %sInputSchema is the input schema of the %s tool\n", lowerFirst(config.TypeName), def.Name)
+	g.printf("const %sInputSchema = %s\n\n", lowerFirst(config.TypeName), stringLiteral(inputSchema))
+
+	g.printf("// %sTool returns the %s tool definition\n", config.TypeName, def.Name)
+	g.printf("func %sTool() mcp.Tool {\n", config.TypeName)
+	g.printf("\treturn mcp.NewToolWithRawSchema(%s, %s, json.RawMessage(%sInputSchema))\n}\n\n",
+		strconv.Quote(def.Name), strconv.Quote(def.Description), lowerFirst(config.TypeName))
+}
+
+// writeStruct emits a struct and, for inputs, its validate method
+func (g *generator) writeStruct(t pendingType) error {
+	g.printf("// %s is generated from the tool's JSON Schema\n", t.name)
+	g.printf("type %s struct {\n", t.name)
+	for _, prop := range t.schema.propertyNames() {
+		ps := t.schema.Properties[prop]
+		required := t.schema.isRequired(prop)
+		goType, err := g.goType(t.name+exportedName(prop), ps, required, t.validate)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop, err)
+		}
+		if ps.Description != "" {
+			g.printf("\t// %s\n", ps.Description)
+		}
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:%q`\n", exportedName(prop), goType, tag)
+	}
+	g.printf("}\n\n")
+
+	if t.validate {
+		g.writeValidate(t)
+	}
+	return nil
+}
+
+// goType returns the Go type for a property, queueing nested structs
+func (g *generator) goType(name string, s *Schema, required, validate bool) (string, error) {
+	switch {
+	case s.isStruct():
+		g.pending = append(g.pending, pendingType{name, s, validate})
+		if !required {
+			return "*" + name, nil
+		}
+		return name, nil
+	case s.Type == "array":
+		if s.Items == nil {
+			return "[]interface{}", nil
+		}
+		item, err := g.goType(name+"Item", s.Items, true, validate)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	}
+
+	goType, err := scalarType(s)
+	if err != nil {
+		return "", err
+	}
+	if !required && !strings.HasPrefix(goType, "map") && goType != "interface{}" {
+		return "*" + goType, nil
+	}
+	return goType, nil
+}
+
+// writeValidate emits the validate method of an input struct
+func (g *generator) writeValidate(t pendingType) {
+	g.printf("// validate checks constraints the JSON decoder cannot; raw holds the\n")
+	g.printf("// undecoded arguments so missing required properties are detected\n")
+	g.printf("func (p *%s) validate(raw map[string]interface{}, path string) error {\n", t.name)
+
+	for _, prop := range t.schema.propertyNames() {
+		ps := t.schema.Properties[prop]
+		field := "p." + exportedName(prop)
+		label := fmt.Sprintf("path+%q", prop)
+		required := t.schema.isRequired(prop)
+
+		if required {
+			g.printf("\tif _, ok := raw[%q]; !ok {\n", prop)
+			g.printf("\t\treturn fmt.Errorf(\"%%s is required\", %s)\n\t}\n", label)
+		}
+
+		switch {
+		case ps.isStruct():
+			g.printf("\tif m, ok := raw[%q].(map[string]interface{}); ok {\n", prop)
+			g.printf("\t\tif err := %s.validate(m, %s+\".\"); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n", field, label)
+		case ps.Type == "array":
+			g.writeArrayChecks(prop, field, label, ps)
+		default:
+			if required || !hasScalarChecks(ps) {
+				g.writeScalarChecks(field, label, ps)
+				continue
+			}
+			g.printf("\tif %s != nil {\n", field)
+			g.writeScalarChecks("*"+field, label, ps)
+			g.printf("\t}\n")
+		}
+	}
+
+	g.printf("\treturn nil\n}\n\n")
+}
+
+// writeArrayChecks emits length checks and per-item validation
+func (g *generator) writeArrayChecks(prop, field, label string, s *Schema) {
+	if s.MinItems != nil {
+		g.printf("\tif len(%s) < %d {\n", field, *s.MinItems)
+		g.printf("\t\treturn fmt.Errorf(\"%%s must have at least %d items\", %s)\n\t}\n", *s.MinItems, label)
+	}
+	if s.MaxItems != nil {
+		g.printf("\tif len(%s) > %d {\n", field, *s.MaxItems)
+		g.printf("\t\treturn fmt.Errorf(\"%%s must have at most %d items\", %s)\n\t}\n", *s.MaxItems, label)
+	}
+	if s.Items == nil {
+		return
+	}
+
+	itemLabel := fmt.Sprintf("fmt.Sprintf(\"%%s[%%d]\", %s, i)", label)
+	switch {
+	case s.Items.isStruct():
+		g.printf("\titems, _ := raw[%q].([]interface{})\n", prop)
+		g.printf("\tfor i := range %s {\n", field)
+		g.printf("\t\tm, _ := items[i].(map[string]interface{})\n")
+		g.printf("\t\tif err := %s[i].validate(m, %s+\".\"); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n", field, itemLabel)
+	case hasScalarChecks(s.Items):
+		g.printf("\tfor i, v := range %s {\n", field)
+		g.writeScalarChecks("v", itemLabel, s.Items)
+		g.printf("\t}\n")
+	}
+}
+
+// hasScalarChecks reports whether a scalar schema has constraints
+func hasScalarChecks(s *Schema) bool {
+	return len(s.Enum) > 0 || s.Minimum != nil || s.Maximum != nil || s.MinLength != nil || s.MaxLength != nil
+}
+
+// writeScalarChecks emits enum, range and length checks for value
+func (g *generator) writeScalarChecks(value, label string, s *Schema) {
+	if len(s.Enum) > 0 {
+		literals := make([]string, len(s.Enum))
+		names := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			literals[i] = enumLiteral(v)
+			names[i] = fmt.Sprint(v)
+		}
+		g.printf("\tswitch %s {\n\tcase %s:\n\tdefault:\n", value, strings.Join(literals, ", "))
+		g.printf("\t\treturn fmt.Errorf(\"%%s must be one of %s\", %s)\n\t}\n", strings.Join(names, ", "), label)
+	}
+	if s.Minimum != nil {
+		g.printf("\tif float64(%s) < %v {\n", value, *s.Minimum)
+		g.printf("\t\treturn fmt.Errorf(\"%%s must be >= %v\", %s)\n\t}\n", *s.Minimum, label)
+	}
+	if s.Maximum != nil {
+		g.printf("\tif float64(%s) > %v {\n", value, *s.Maximum)
+		g.printf("\t\treturn fmt.Errorf(\"%%s must be <= %v\", %s)\n\t}\n", *s.Maximum, label)
+	}
+	if s.MinLength != nil {
+		g.printf("\tif len([]rune(%s)) < %d {\n", value, *s.MinLength)
+		g.printf("\t\treturn fmt.Errorf(\"%%s must be at least %d characters\", %s)\n\t}\n", *s.MinLength, label)
+	}
+	if s.MaxLength != nil {
+		g.printf("\tif len([]rune(%s)) > %d {\n", value, *s.MaxLength)
+		g.printf("\t\treturn fmt.Errorf(\"%%s must be at most %d characters\", %s)\n\t}\n", *s.MaxLength, label)
+	}
+}
+
+// writeParse emits the argument parser
+func (g *generator) writeParse(name string) {
+	g.printf("// Parse%[1]sParams decodes and validates tool arguments\n", name)
+	g.printf("func Parse%[1]sParams(args map[string]interface{}) (%[1]sParams, error) {\n", name)
+	g.printf("\tvar params %sParams\n", name)
+	g.printf("\tdata, err := json.Marshal(args)\n\tif err != nil {\n\t\treturn params, fmt.Errorf(\"invalid arguments: %%w\", err)\n\t}\n")
+	g.printf("\tif err := json.Unmarshal(data, &params); err != nil {\n\t\treturn params, fmt.Errorf(\"invalid arguments: %%w\", err)\n\t}\n")
+	g.printf("\treturn params, params.validate(args, \"\")\n}\n\n")
+}
+
+// writeHandler emits the typed handler adapter
+func (g *generator) writeHandler(name string, hasResult bool) {
+	result := "*mcp.CallToolResult"
+	if hasResult {
+		result = name + "Result"
+	}
+
+	g.printf("// %[1]sHandler adapts a typed function to an mcp-go tool handler. Invalid\n", name)
+	g.printf("// arguments are reported as a tool error result.\n")
+	g.printf("func %[1]sHandler(fn func(ctx context.Context, params %[1]sParams) (%[2]s, error)) server.ToolHandlerFunc {\n", name, result)
+	g.printf("\treturn func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {\n")
+	g.printf("\t\tparams, err := Parse%sParams(request.GetArguments())\n", name)
+	g.printf("\t\tif err != nil {\n\t\t\treturn mcp.NewToolResultError(err.Error()), nil\n\t\t}\n")
+	if !hasResult {
+		g.printf("\t\treturn fn(ctx, params)\n\t}\n}\n")
+		return
+	}
+	g.printf("\t\tresult, err := fn(ctx, params)\n\t\tif err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+	g.printf("\t\tdata, err := json.Marshal(result)\n\t\tif err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+	g.printf("\t\treturn mcp.NewToolResultText(string(data)), nil\n\t}\n}\n")
+}
+
+// enumLiteral formats an enum value as a Go literal
+func enumLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// stringLiteral formats s as a Go string literal, preferring raw strings
+func stringLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// lowerFirst lower-cases the first letter of s
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
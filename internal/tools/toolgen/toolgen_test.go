@@ -0,0 +1,123 @@
+package toolgen
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:generate go run ../../../cmd/toolgen -in testdata/search.json -out search_gen_test.go -package toolgen
+
+func TestGenerate_Golden(t *testing.T) {
+	definition, err := os.ReadFile("testdata/search.json")
+	require.NoError(t, err)
+
+	src, err := Generate(definition, Config{Package: "toolgen", Source: "search.json"})
+	require.NoError(t, err)
+
+	golden, err := os.ReadFile("search_gen_test.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(src), "run go generate to update search_gen_test.go")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+	}{
+		{"invalid json", `{`},
+		{"missing name", `{"inputSchema": {"type": "object"}}`},
+		{"non-object input", `{"name": "x", "inputSchema": {"type": "string"}}`},
+		{"unsupported type", `{"name": "x", "inputSchema": {"type": "object", "properties": {"a": {"type": "date"}}}}`},
+		{"empty output", `{"name": "x", "inputSchema": {"type": "object"}, "outputSchema": {"type": "object"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate([]byte(tt.definition), Config{Package: "p"})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	assert.Equal(t, "SearchIssues", exportedName("search_issues"))
+	assert.Equal(t, "UserID", exportedName("user-id"))
+	assert.Equal(t, "IssueIds", exportedName("issueIds"))
+	assert.Equal(t, "X2fa", exportedName("2fa"))
+}
+
+func validArgs() map[string]interface{} {
+	return map[string]interface{}{
+		"query":   "crash",
+		"limit":   10,
+		"labels":  []interface{}{"bug"},
+		"repo":    map[string]interface{}{"owner": "acme", "name": "widgets"},
+		"filters": []interface{}{map[string]interface{}{"field": "author", "value": "bob"}},
+	}
+}
+
+func TestGeneratedParse(t *testing.T) {
+	params, err := ParseSearchIssuesParams(validArgs())
+	require.NoError(t, err)
+	assert.Equal(t, "crash", params.Query)
+	assert.Equal(t, int64(10), *params.Limit)
+	assert.Nil(t, params.State)
+	assert.Equal(t, "widgets", params.Repo.Name)
+	assert.Equal(t, "author", params.Filters[0].Field)
+
+	tests := []struct {
+		name   string
+		mutate func(args map[string]interface{})
+		msg    string
+	}{
+		{"missing required", func(a map[string]interface{}) { delete(a, "query") }, "query is required"},
+		{"wrong type", func(a map[string]interface{}) { a["limit"] = "ten" }, "invalid arguments"},
+		{"below minimum", func(a map[string]interface{}) { a["limit"] = 0 }, "limit must be >= 1"},
+		{"enum", func(a map[string]interface{}) { a["state"] = "merged" }, "state must be one of open, closed"},
+		{"too many items", func(a map[string]interface{}) {
+			a["labels"] = []interface{}{"a", "b", "c", "d", "e", "f"}
+		}, "labels must have at most 5 items"},
+		{"item constraint", func(a map[string]interface{}) { a["labels"] = []interface{}{""} }, "labels[0] must be at least 1 characters"},
+		{"nested required", func(a map[string]interface{}) {
+			a["repo"] = map[string]interface{}{"owner": "acme"}
+		}, "repo.name is required"},
+		{"array item required", func(a map[string]interface{}) {
+			a["filters"] = []interface{}{map[string]interface{}{"value": 1}}
+		}, "filters[0].field is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := validArgs()
+			tt.mutate(args)
+			_, err := ParseSearchIssuesParams(args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.msg)
+		})
+	}
+}
+
+func TestGeneratedHandler(t *testing.T) {
+	handler := SearchIssuesHandler(func(ctx context.Context, params SearchIssuesParams) (SearchIssuesResult, error) {
+		return SearchIssuesResult{Total: 1, IssueIds: []int64{42}}, nil
+	})
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = validArgs()
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, `{"issueIds":[42],"total":1}`, result.Content[0].(mcp.TextContent).Text)
+
+	request.Params.Arguments = map[string]interface{}{}
+	result, err = handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	assert.Equal(t, "search_issues", SearchIssuesTool().Name)
+}
@@ -0,0 +1,510 @@
+// Package tools provides a registry for locally implemented MCP tools.
+// Registered tools carry metadata (version, tags), can be enabled and
+// disabled at runtime, and are wrapped with per-tool middleware. Enabled
+// tools are published to an mcp-go server, which emits
+// notifications/tools/list_changed whenever the published set changes.
+//
+// A call whose _meta sets DryRunMeta is checked like any other but not
+// executed: tools declaring DryRun plan it themselves, and the registry
+// previews calls of the others.
+//
+// With a ReceiptStore, results of tools not annotated read-only carry an
+// execution receipt, and a call re-submitted with it replays the recorded
+// result instead of running twice.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/validator"
+	"github.com/meta-mcp/meta-mcp-server/internal/schema"
+)
+
+var (
+	// ErrToolExists is returned when registering a name already in use
+	ErrToolExists = errors.New("tool already registered")
+
+	// ErrToolNotFound is returned for operations on unknown tools
+	ErrToolNotFound = errors.New("tool not registered")
+)
+
+// Server is the part of the mcp-go server the registry publishes tools to
+type Server interface {
+	AddTools(tools ...server.ServerTool)
+	DeleteTools(names ...string)
+}
+
+// Definition describes a tool to register. The schema and annotations are
+// part of Tool.
+type Definition struct {
+	// Tool is the MCP tool definition
+	Tool mcp.Tool
+
+	// Handler implements the tool
+	Handler server.ToolHandlerFunc
+
+	// Version identifies the tool implementation
+	Version string
+
+	// Tags group tools for listing and administration
+	Tags []string
+
+	// Middleware wraps the handler of this tool only; the first entry is
+	// outermost
+	Middleware []server.ToolHandlerMiddleware
+
+	// Disabled registers the tool without publishing it
+	Disabled bool
+
+	// Source tells where the tool comes from, e.g. an upstream or plugin
+	// name (defaults to "local")
+	Source string
+
+	// Streaming lets the handler send partial results with SendPartial
+	Streaming bool
+
+	// ConcurrencyGroup limits how many calls of the tools sharing it run at
+	// once across all connections, e.g. "git" or "db-write" for tools that
+	// must not write concurrently. The limit is set with
+	// Config.ConcurrencyLimits or SetConcurrencyLimit (defaults to 1).
+	ConcurrencyGroup string
+
+	// DryRun declares that the handler honors the DryRunMeta of a call,
+	// checking it and reporting what it would do without doing it. Dry runs
+	// of other tools are answered by the registry.
+	DryRun bool
+}
+
+// EventType is the kind of registry change
+type EventType string
+
+// Registry change events
+const (
+	EventRegistered   EventType = "registered"
+	EventUpdated      EventType = "updated"
+	EventUnregistered EventType = "unregistered"
+	EventEnabled      EventType = "enabled"
+	EventDisabled     EventType = "disabled"
+)
+
+// Event describes a registry change
+type Event struct {
+	Type    EventType `json:"type"`
+	Tool    string    `json:"tool"`
+	Version string    `json:"version,omitempty"`
+}
+
+// Info describes a registered tool
+type Info struct {
+	Name         string             `json:"name"`
+	Description  string             `json:"description,omitempty"`
+	Version      string             `json:"version,omitempty"`
+	Tags         []string           `json:"tags,omitempty"`
+	Annotations  mcp.ToolAnnotation `json:"annotations"`
+	Enabled      bool               `json:"enabled"`
+	Streaming    bool               `json:"streaming,omitempty"`
+	DryRun       bool               `json:"dryRun,omitempty"`
+	Group        string             `json:"concurrencyGroup,omitempty"`
+	RegisteredAt time.Time          `json:"registeredAt"`
+}
+
+// Config contains configuration for a Registry
+type Config struct {
+	// Server receives enabled tools (optional)
+	Server Server
+
+	// Middleware wraps every tool's handler, outside its own middleware
+	Middleware []server.ToolHandlerMiddleware
+
+	// OnChange is called after each registry change
+	OnChange func(Event)
+
+	// Filter limits the tools Visible reports to a connection. Install the
+	// same filter with server.WithToolFilter so tools/list agrees.
+	Filter server.ToolFilterFunc
+
+	// Idempotency deduplicates retried calls of tools annotated with
+	// idempotentHint; calls of other tools are not deduplicated
+	Idempotency *IdempotencyCache
+
+	// Schemas receives the input schemas of registered tools (optional)
+	Schemas *schema.Registry
+
+	// ValidateArguments rejects calls whose arguments do not match the
+	// tool's input schema in Schemas, before any middleware runs
+	ValidateArguments bool
+
+	// PartialResults reports whether the client of ctx accepts partial
+	// results of streaming tools as progress notifications; when nil, or
+	// false, partial results are assembled into the final result
+	PartialResults func(ctx context.Context) bool
+
+	// ConcurrencyLimits sets how many calls of each concurrency group run
+	// at once; groups not listed run one call at a time
+	ConcurrencyLimits map[string]int
+
+	// Receipts issues execution receipts for calls of tools not annotated
+	// with readOnlyHint, and answers their re-submission (optional)
+	Receipts *ReceiptStore
+}
+
+// entry is a registered tool
+type entry struct {
+	def          Definition
+	handler      server.ToolHandlerFunc
+	enabled      bool
+	registeredAt time.Time
+}
+
+// Registry holds locally implemented tools
+type Registry struct {
+	config Config
+
+	mu    sync.RWMutex
+	tools map[string]*entry
+
+	groups *concurrencyGroups
+}
+
+// New creates an empty registry
+func New(config Config) *Registry {
+	return &Registry{
+		config: config,
+		tools:  make(map[string]*entry),
+		groups: newConcurrencyGroups(config.ConcurrencyLimits),
+	}
+}
+
+// Register adds a tool, publishing it unless it is disabled
+func (r *Registry) Register(def Definition) error {
+	if err := validate(def); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if _, ok := r.tools[def.Tool.Name]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrToolExists, def.Tool.Name)
+	}
+	e := r.newEntry(def, time.Now())
+	r.tools[def.Tool.Name] = e
+	r.mu.Unlock()
+	r.putSchema(def)
+
+	if e.enabled {
+		r.publish(e)
+	}
+	r.notify(Event{Type: EventRegistered, Tool: def.Tool.Name, Version: def.Version})
+	return nil
+}
+
+// MustRegister is like Register but panics on error
+func (r *Registry) MustRegister(def Definition) {
+	if err := r.Register(def); err != nil {
+		panic(err)
+	}
+}
+
+// Update replaces the definition of a registered tool, keeping its enabled
+// state. Clients are notified if the tool is published.
+func (r *Registry) Update(def Definition) error {
+	if err := validate(def); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	old, ok := r.tools[def.Tool.Name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrToolNotFound, def.Tool.Name)
+	}
+	e := r.newEntry(def, old.registeredAt)
+	e.enabled = old.enabled
+	r.tools[def.Tool.Name] = e
+	r.mu.Unlock()
+	r.putSchema(def)
+
+	if e.enabled {
+		r.publish(e)
+	}
+	r.notify(Event{Type: EventUpdated, Tool: def.Tool.Name, Version: def.Version})
+	return nil
+}
+
+// Unregister removes a tool
+func (r *Registry) Unregister(name string) error {
+	r.mu.Lock()
+	e, ok := r.tools[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	delete(r.tools, name)
+	r.mu.Unlock()
+	if r.config.Schemas != nil {
+		r.config.Schemas.Remove(schema.KindTool, name)
+	}
+
+	if e.enabled {
+		r.unpublish(name)
+	}
+	r.notify(Event{Type: EventUnregistered, Tool: name, Version: e.def.Version})
+	return nil
+}
+
+// Enable publishes a registered tool
+func (r *Registry) Enable(name string) error {
+	return r.setEnabled(name, true)
+}
+
+// Disable withdraws a registered tool without forgetting it
+func (r *Registry) Disable(name string) error {
+	return r.setEnabled(name, false)
+}
+
+// Get returns information about a registered tool
+func (r *Registry) Get(name string) (Info, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.tools[name]
+	if !ok {
+		return Info{}, false
+	}
+	return e.info(), true
+}
+
+// List returns the registered tools sorted by name. A non-empty tag limits
+// the result to tools carrying it.
+func (r *Registry) List(tag string) []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]Info, 0, len(r.tools))
+	for _, e := range r.tools {
+		if tag != "" && !hasTag(e.def.Tags, tag) {
+			continue
+		}
+		infos = append(infos, e.info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Visible returns the published tools the connection of ctx may see,
+// sorted by name like tools/list
+func (r *Registry) Visible(ctx context.Context) []mcp.Tool {
+	r.mu.RLock()
+	tools := make([]mcp.Tool, 0, len(r.tools))
+	for _, e := range r.tools {
+		if e.enabled {
+			tools = append(tools, e.def.Tool)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	if r.config.Filter != nil {
+		tools = r.config.Filter(ctx, tools)
+	}
+	return tools
+}
+
+// Call invokes an enabled tool through its middleware
+func (r *Registry) Call(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	r.mu.RLock()
+	e, ok := r.tools[request.Params.Name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, mcperrors.NewToolNotFoundError(request.Params.Name)
+	}
+	return e.handler(ctx, request)
+}
+
+// setEnabled changes whether a tool is published
+func (r *Registry) setEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	e, ok := r.tools[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	if e.enabled == enabled {
+		r.mu.Unlock()
+		return nil
+	}
+	e.enabled = enabled
+	r.mu.Unlock()
+
+	eventType := EventDisabled
+	if enabled {
+		eventType = EventEnabled
+		r.publish(e)
+	} else {
+		r.unpublish(name)
+	}
+	r.notify(Event{Type: eventType, Tool: name, Version: e.def.Version})
+	return nil
+}
+
+// newEntry wraps the definition's handler with the registry and tool
+// middleware
+func (r *Registry) newEntry(def Definition, registeredAt time.Time) *entry {
+	e := &entry{def: def, enabled: !def.Disabled, registeredAt: registeredAt}
+
+	handler := def.Handler
+	if def.Streaming {
+		handler = r.streaming(handler)
+	}
+	direct := handler
+	// Replayed idempotent calls do not take a slot
+	if def.ConcurrencyGroup != "" {
+		handler = r.groups.middleware(def.ConcurrencyGroup)(handler)
+	}
+	// Re-submissions replay without taking a slot, and idempotent retries
+	// get the receipt of the first call
+	if r.config.Receipts != nil && !isReadOnly(def.Tool) {
+		handler = r.config.Receipts.Middleware(handler)
+	}
+	if r.config.Idempotency != nil && isIdempotent(def.Tool) {
+		handler = r.config.Idempotency.Middleware(handler)
+	}
+	// Dry runs neither take a slot nor are remembered as idempotent calls
+	handler = dryRun(def, direct, handler)
+	for i := len(def.Middleware) - 1; i >= 0; i-- {
+		handler = def.Middleware[i](handler)
+	}
+	for i := len(r.config.Middleware) - 1; i >= 0; i-- {
+		handler = r.config.Middleware[i](handler)
+	}
+
+	// Reject calls racing with Disable
+	e.handler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r.mu.RLock()
+		enabled := e.enabled
+		r.mu.RUnlock()
+		if !enabled {
+			return nil, mcperrors.NewToolNotFoundError(def.Tool.Name)
+		}
+		if err := r.validateArguments(request); err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+	return e
+}
+
+// putSchema records the input schema of a tool in the schema registry
+func (r *Registry) putSchema(def Definition) {
+	if r.config.Schemas == nil {
+		return
+	}
+	source := def.Source
+	if source == "" {
+		source = "local"
+	}
+	// validate checked the schema is valid JSON
+	r.config.Schemas.Put(schema.KindTool, def.Tool.Name, source, inputSchema(def.Tool))
+}
+
+// validateArguments checks the arguments of a call against the tool's
+// input schema when ValidateArguments is set. Tools whose schema is missing
+// or does not compile are not validated.
+func (r *Registry) validateArguments(request mcp.CallToolRequest) error {
+	if !r.config.ValidateArguments || r.config.Schemas == nil {
+		return nil
+	}
+	arguments := request.Params.Arguments
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	err := r.config.Schemas.Validate(schema.KindTool, request.Params.Name, arguments)
+	var single *validator.ValidationError
+	var multiple *validator.MultiValidationError
+	if errors.As(err, &single) || errors.As(err, &multiple) {
+		return mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+			fmt.Sprintf("invalid arguments for tool %s: %v", request.Params.Name, err), err)
+	}
+	return nil
+}
+
+// publish adds a tool to the server
+func (r *Registry) publish(e *entry) {
+	if r.config.Server != nil {
+		r.config.Server.AddTools(server.ServerTool{Tool: e.def.Tool, Handler: e.handler})
+	}
+}
+
+// unpublish removes a tool from the server
+func (r *Registry) unpublish(name string) {
+	if r.config.Server != nil {
+		r.config.Server.DeleteTools(name)
+	}
+}
+
+// notify reports a change to the OnChange hook
+func (r *Registry) notify(event Event) {
+	if r.config.OnChange != nil {
+		r.config.OnChange(event)
+	}
+}
+
+// info returns the entry's public description
+func (e *entry) info() Info {
+	return Info{
+		Name:         e.def.Tool.Name,
+		Description:  e.def.Tool.Description,
+		Version:      e.def.Version,
+		Tags:         append([]string(nil), e.def.Tags...),
+		Annotations:  e.def.Tool.Annotations,
+		Enabled:      e.enabled,
+		Streaming:    e.def.Streaming,
+		DryRun:       e.def.DryRun,
+		Group:        e.def.ConcurrencyGroup,
+		RegisteredAt: e.registeredAt,
+	}
+}
+
+// validate checks a definition can be registered
+func validate(def Definition) error {
+	if def.Tool.Name == "" {
+		return errors.New("tool name is required")
+	}
+	if def.Handler == nil {
+		return fmt.Errorf("tool %s: handler is required", def.Tool.Name)
+	}
+	if len(def.Tool.RawInputSchema) > 0 && !json.Valid(def.Tool.RawInputSchema) {
+		return fmt.Errorf("tool %s: input schema is not valid JSON", def.Tool.Name)
+	}
+	return nil
+}
+
+// inputSchema returns the input schema of tool as JSON
+func inputSchema(tool mcp.Tool) json.RawMessage {
+	if len(tool.RawInputSchema) > 0 {
+		return tool.RawInputSchema
+	}
+	data, _ := json.Marshal(tool.InputSchema)
+	return data
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer records published tools
+type fakeServer struct {
+	mu      sync.Mutex
+	tools   map[string]server.ServerTool
+	changes int
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{tools: make(map[string]server.ServerTool)}
+}
+
+func (s *fakeServer) AddTools(tools ...server.ServerTool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tool := range tools {
+		s.tools[tool.Tool.Name] = tool
+	}
+	s.changes++
+}
+
+func (s *fakeServer) DeleteTools(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		delete(s.tools, name)
+	}
+	s.changes++
+}
+
+func echoDefinition() Definition {
+	return Definition{
+		Tool: mcp.NewTool("echo", mcp.WithDescription("Echo a message")),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		},
+		Version: "1.0.0",
+		Tags:    []string{"builtin"},
+	}
+}
+
+func callRequest(name string) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	return request
+}
+
+func TestRegistry_Lifecycle(t *testing.T) {
+	srv := newFakeServer()
+	var events []EventType
+	r := New(Config{Server: srv, OnChange: func(e Event) { events = append(events, e.Type) }})
+
+	require.NoError(t, r.Register(echoDefinition()))
+	assert.ErrorIs(t, r.Register(echoDefinition()), ErrToolExists)
+	assert.Contains(t, srv.tools, "echo")
+
+	info, ok := r.Get("echo")
+	require.True(t, ok)
+	assert.Equal(t, "1.0.0", info.Version)
+	assert.True(t, info.Enabled)
+
+	require.NoError(t, r.Disable("echo"))
+	assert.NotContains(t, srv.tools, "echo")
+	_, err := r.Call(context.Background(), callRequest("echo"))
+	assert.Equal(t, mcperrors.ErrorCodeMCPToolNotFound, mcperrors.FindMCPError(err).Code)
+
+	// Disabling twice is not a change
+	require.NoError(t, r.Disable("echo"))
+	require.NoError(t, r.Enable("echo"))
+	assert.Contains(t, srv.tools, "echo")
+
+	def := echoDefinition()
+	def.Version = "1.1.0"
+	require.NoError(t, r.Update(def))
+	info, _ = r.Get("echo")
+	assert.Equal(t, "1.1.0", info.Version)
+
+	require.NoError(t, r.Unregister("echo"))
+	assert.ErrorIs(t, r.Unregister("echo"), ErrToolNotFound)
+	assert.NotContains(t, srv.tools, "echo")
+
+	assert.Equal(t, []EventType{EventRegistered, EventDisabled, EventEnabled, EventUpdated, EventUnregistered}, events)
+	assert.Equal(t, 5, srv.changes)
+}
+
+func TestRegistry_List(t *testing.T) {
+	r := New(Config{})
+	r.MustRegister(echoDefinition())
+
+	other := echoDefinition()
+	other.Tool.Name = "add"
+	other.Tags = nil
+	other.Disabled = true
+	r.MustRegister(other)
+
+	infos := r.List("")
+	require.Len(t, infos, 2)
+	assert.Equal(t, "add", infos[0].Name)
+	assert.False(t, infos[0].Enabled)
+
+	assert.Len(t, r.List("builtin"), 1)
+	assert.Error(t, r.Register(Definition{Tool: mcp.NewTool("nohandler")}))
+}
+
+func TestRegistry_Visible(t *testing.T) {
+	type roleKey struct{}
+	r := New(Config{Filter: func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		if ctx.Value(roleKey{}) == "admin" {
+			return tools
+		}
+		var visible []mcp.Tool
+		for _, tool := range tools {
+			if tool.Name != "shutdown" {
+				visible = append(visible, tool)
+			}
+		}
+		return visible
+	}})
+	for _, name := range []string{"shutdown", "echo", "add"} {
+		def := echoDefinition()
+		def.Tool.Name = name
+		def.Disabled = name == "add"
+		r.MustRegister(def)
+	}
+
+	names := func(tools []mcp.Tool) []string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	admin := context.WithValue(context.Background(), roleKey{}, "admin")
+	assert.Equal(t, []string{"echo", "shutdown"}, names(r.Visible(admin)))
+	assert.Equal(t, []string{"echo"}, names(r.Visible(context.Background())))
+}
+
+func TestRegistry_Schemas(t *testing.T) {
+	schemas := schema.New()
+	r := New(Config{Schemas: schemas, ValidateArguments: true})
+
+	def := echoDefinition()
+	def.Tool = mcp.NewTool("echo", mcp.WithString("message", mcp.Required()))
+	def.Source = "github"
+	r.MustRegister(def)
+	entry, ok := schemas.Get(schema.KindTool, "echo")
+	require.True(t, ok)
+	assert.Equal(t, "github", entry.Source)
+
+	_, err := r.Call(context.Background(), callRequest("echo"))
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+	request := callRequest("echo")
+	request.Params.Arguments = map[string]any{"message": "hi"}
+	_, err = r.Call(context.Background(), request)
+	assert.NoError(t, err)
+
+	require.NoError(t, r.Unregister("echo"))
+	_, ok = schemas.Get(schema.KindTool, "echo")
+	assert.False(t, ok)
+
+	def.Tool = mcp.NewToolWithRawSchema("raw", "", json.RawMessage(`{"type":`))
+	assert.Error(t, r.Register(def))
+}
+
+func TestRegistry_Middleware(t *testing.T) {
+	var order []string
+	trace := func(name string) server.ToolHandlerMiddleware {
+		return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				order = append(order, name)
+				return next(ctx, request)
+			}
+		}
+	}
+
+	r := New(Config{Middleware: []server.ToolHandlerMiddleware{trace("registry")}})
+	def := echoDefinition()
+	def.Middleware = []server.ToolHandlerMiddleware{trace("first"), trace("second")}
+	r.MustRegister(def)
+
+	_, err := r.Call(context.Background(), callRequest("echo"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry", "first", "second"}, order)
+}
+
+func TestRequireAuth(t *testing.T) {
+	r := New(Config{})
+	def := echoDefinition()
+	def.Middleware = []server.ToolHandlerMiddleware{RequireAuth(func(ctx context.Context, tool string) error {
+		return errors.New("no credentials")
+	})}
+	r.MustRegister(def)
+
+	_, err := r.Call(context.Background(), callRequest("echo"))
+	assert.Equal(t, mcperrors.ErrorCodeMCPUnauthorized, mcperrors.FindMCPError(err).Code)
+}
+
+func TestRateLimit(t *testing.T) {
+	r := New(Config{})
+	def := echoDefinition()
+	def.Middleware = []server.ToolHandlerMiddleware{RateLimit(2, time.Hour)}
+	r.MustRegister(def)
+
+	for i := 0; i < 2; i++ {
+		_, err := r.Call(context.Background(), callRequest("echo"))
+		require.NoError(t, err)
+	}
+	_, err := r.Call(context.Background(), callRequest("echo"))
+	assert.Equal(t, mcperrors.ErrorCodeMCPRateLimit, mcperrors.FindMCPError(err).Code)
+}
+
+func TestAdminHandler(t *testing.T) {
+	r := New(Config{})
+	r.MustRegister(echoDefinition())
+	handler := AdminHandler(r)
+
+	resp := handler.Handle(context.Background(), &jsonrpc.Request{
+		ID:     1,
+		Method: AdminMethod,
+		Params: map[string]interface{}{"action": "disable", "name": "echo"},
+	})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(AdminResult)
+	require.Len(t, result.Tools, 1)
+	assert.False(t, result.Tools[0].Enabled)
+
+	resp = handler.Handle(context.Background(), &jsonrpc.Request{
+		ID:     2,
+		Method: AdminMethod,
+		Params: map[string]interface{}{"action": "enable", "name": "missing"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcperrors.ErrorCodeMCPToolNotFound, resp.Error.Code)
+}
@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+// Middleware traces router requests as children of the trace context found
+// in params `_meta`, falling back to the span already in ctx
+func Middleware() router.Middleware {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			ctx = ExtractRequest(ctx, req)
+			ctx, span := Tracer().Start(ctx, req.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(requestAttributes(ctx, req)...),
+			)
+			defer span.End()
+
+			resp := next.Handle(ctx, req)
+			recordResponse(span, resp)
+			return resp
+		})
+	}
+}
+
+// AsyncMiddleware traces requests processed by the AsyncRouter. Each request
+// starts a new trace linked to the span that enqueued it and tagged with the
+// correlation ID, so asynchronous work can be followed without being
+// attributed to the caller's latency.
+func AsyncMiddleware() router.Middleware {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			ctx = ExtractRequest(ctx, req)
+
+			opts := []trace.SpanStartOption{
+				trace.WithNewRoot(),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(requestAttributes(ctx, req)...),
+			}
+			if parent := trace.SpanContextFromContext(ctx); parent.IsValid() {
+				link := trace.Link{SpanContext: parent}
+				if rc, ok := router.GetRequestContext(ctx); ok && rc.CorrelationID != "" {
+					link.Attributes = []attribute.KeyValue{AttrCorrelationID.String(rc.CorrelationID)}
+				}
+				opts = append(opts, trace.WithLinks(link))
+			}
+
+			ctx, span := Tracer().Start(ctx, req.Method+" (async)", opts...)
+			defer span.End()
+
+			resp := next.Handle(ctx, req)
+			recordResponse(span, resp)
+			return resp
+		})
+	}
+}
+
+// ToolMiddleware traces mcp-go tool calls using the trace context in the
+// request's `_meta`. It is installed with server.WithToolHandlerMiddleware.
+func ToolMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Meta != nil {
+				ctx = ExtractMeta(ctx, request.Params.Meta.AdditionalFields)
+			}
+
+			attrs := []attribute.KeyValue{
+				AttrRPCSystem.String("jsonrpc"),
+				AttrRPCMethod.String(string(mcp.MethodToolsCall)),
+				AttrToolName.String(request.Params.Name),
+			}
+			if connID, ok := connection.GetConnectionID(ctx); ok {
+				attrs = append(attrs, AttrConnectionID.String(connID))
+			}
+
+			ctx, span := Tracer().Start(ctx, "tools/call "+request.Params.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			result, err := next(ctx, request)
+			switch {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case result != nil && result.IsError:
+				span.SetStatus(codes.Error, "tool returned an error result")
+			}
+			return result, err
+		}
+	}
+}
+
+// HTTPMiddleware extracts trace context from HTTP headers into the request
+// context so transports served over HTTP continue the caller's trace
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := ExtractHTTP(r.Context(), r.Header)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// StartUpstreamSpan starts a client span for a request proxied to an
+// upstream server and injects the new span context into the request's
+// `_meta`. The caller must end the returned span.
+func StartUpstreamSpan(ctx context.Context, upstream string, req *jsonrpc.Request) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, fmt.Sprintf("%s %s", upstream, req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttrRPCSystem.String("jsonrpc"),
+			AttrRPCMethod.String(req.Method),
+			AttrUpstream.String(upstream),
+		),
+	)
+	InjectRequest(ctx, req)
+	return ctx, span
+}
+
+// EndWithResponse records the outcome of resp on span and ends it
+func EndWithResponse(span trace.Span, resp *jsonrpc.Response) {
+	recordResponse(span, resp)
+	span.End()
+}
+
+// requestAttributes returns the standard attributes for a router request
+func requestAttributes(ctx context.Context, req *jsonrpc.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		AttrRPCSystem.String("jsonrpc"),
+		AttrRPCMethod.String(req.Method),
+	}
+	if req.ID != nil {
+		attrs = append(attrs, AttrRequestID.String(fmt.Sprint(req.ID)))
+	}
+	if rc, ok := router.GetRequestContext(ctx); ok && rc.CorrelationID != "" {
+		attrs = append(attrs, AttrCorrelationID.String(rc.CorrelationID))
+	}
+	if connID, ok := connection.GetConnectionID(ctx); ok {
+		attrs = append(attrs, AttrConnectionID.String(connID))
+	}
+	return attrs
+}
+
+// recordResponse marks span as failed when resp carries an error
+func recordResponse(span trace.Span, resp *jsonrpc.Response) {
+	if resp == nil || resp.Error == nil {
+		return
+	}
+	span.SetAttributes(AttrErrorCode.Int(resp.Error.Code))
+	span.SetStatus(codes.Error, resp.Error.Message)
+}
@@ -0,0 +1,173 @@
+// Package tracing propagates OpenTelemetry trace context across the
+// Meta-MCP hop: from the client (via params `_meta` or HTTP headers),
+// through the router and handlers, and on to proxied upstream calls.
+//
+// Trace context travels in `_meta` using the W3C field names:
+//
+//	{"method": "tools/call", "params": {"name": "search", "_meta": {
+//	    "traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+//	}}}
+//
+// Synchronous handlers run as child spans of the incoming context. Requests
+// processed by the AsyncRouter start a new trace linked to the enqueuing
+// span and tagged with the correlation ID, so long-running work does not
+// stretch the caller's trace.
+//
+// The package uses the global OpenTelemetry tracer provider unless a
+// provider is installed with Init, or with InitOTLP to export spans to an
+// OpenTelemetry collector.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+)
+
+// TracerName is the instrumentation name used for spans
+const TracerName = "github.com/meta-mcp/meta-mcp-server"
+
+// MetaKey is the params field carrying trace context
+const MetaKey = "_meta"
+
+// Span attribute keys
+const (
+	AttrRPCSystem     = attribute.Key("rpc.system")
+	AttrRPCMethod     = attribute.Key("rpc.method")
+	AttrRequestID     = attribute.Key("rpc.jsonrpc.request_id")
+	AttrErrorCode     = attribute.Key("rpc.jsonrpc.error_code")
+	AttrCorrelationID = attribute.Key("mcp.correlation_id")
+	AttrConnectionID  = attribute.Key("mcp.connection_id")
+	AttrToolName      = attribute.Key("mcp.tool.name")
+	AttrUpstream      = attribute.Key("mcp.upstream")
+)
+
+// propagator handles W3C trace context and baggage
+var propagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Tracer returns the package tracer from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Init installs an SDK tracer provider exporting to exporter and returns a
+// function that flushes and shuts it down
+func Init(serviceName string, exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) func(context.Context) error {
+	res := sdkresource.NewSchemaless(attribute.String("service.name", serviceName))
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	}, opts...)
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return provider.Shutdown
+}
+
+// InitOTLP installs an SDK tracer provider exporting over OTLP/HTTP to
+// endpoint, a collector URL such as http://localhost:4318, and returns a
+// function that flushes and shuts it down
+func InitOTLP(ctx context.Context, serviceName, endpoint string) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	return Init(serviceName, exporter), nil
+}
+
+// MetaCarrier adapts a `_meta` object to propagation.TextMapCarrier
+type MetaCarrier map[string]interface{}
+
+// Get returns the value stored under key
+func (c MetaCarrier) Get(key string) string {
+	if s, ok := c[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// Set stores a value under key
+func (c MetaCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys lists the carrier's keys
+func (c MetaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// ExtractMeta returns ctx with the remote span context found in meta
+func ExtractMeta(ctx context.Context, meta map[string]interface{}) context.Context {
+	if len(meta) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, MetaCarrier(meta))
+}
+
+// InjectMeta writes the span context of ctx into meta
+func InjectMeta(ctx context.Context, meta map[string]interface{}) {
+	propagator.Inject(ctx, MetaCarrier(meta))
+}
+
+// ExtractHTTP returns ctx with the remote span context found in header
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// InjectHTTP writes the span context of ctx into header
+func InjectHTTP(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractRequest returns ctx with the remote span context found in the
+// request's params `_meta`
+func ExtractRequest(ctx context.Context, req *jsonrpc.Request) context.Context {
+	return ExtractMeta(ctx, requestMeta(req, false))
+}
+
+// InjectRequest writes the span context of ctx into the request's params
+// `_meta`, creating it when needed. It reports false when the params are
+// not an object and cannot carry trace context.
+func InjectRequest(ctx context.Context, req *jsonrpc.Request) bool {
+	meta := requestMeta(req, true)
+	if meta == nil {
+		return false
+	}
+	InjectMeta(ctx, meta)
+	return true
+}
+
+// requestMeta returns the `_meta` object of req's params, optionally creating it
+func requestMeta(req *jsonrpc.Request, create bool) map[string]interface{} {
+	if req.Params == nil && create {
+		req.Params = map[string]interface{}{}
+	}
+	params, ok := req.Params.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	meta, ok := params[MetaKey].(map[string]interface{})
+	if !ok && create {
+		meta = map[string]interface{}{}
+		params[MetaKey] = meta
+	}
+	return meta
+}
@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// setupRecorder installs a recording tracer provider for the test
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestInitOTLP(t *testing.T) {
+	exported := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case exported <- r.URL.Path:
+		default:
+		}
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	shutdown, err := InitOTLP(context.Background(), "meta-mcp", collector.URL)
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "tools/call")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, "/v1/traces", <-exported)
+}
+
+func TestMetaRoundTrip(t *testing.T) {
+	ctx := ExtractMeta(context.Background(), map[string]interface{}{"traceparent": testTraceparent})
+	sc := trace.SpanContextFromContext(ctx)
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+
+	req := &jsonrpc.Request{Method: "tools/call", Params: map[string]interface{}{"name": "echo"}}
+	require.True(t, InjectRequest(ctx, req))
+	meta := req.Params.(map[string]interface{})[MetaKey].(map[string]interface{})
+	assert.Equal(t, testTraceparent, meta["traceparent"])
+
+	// Positional params cannot carry trace context
+	assert.False(t, InjectRequest(ctx, &jsonrpc.Request{Params: []interface{}{1}}))
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	handler := router.NewChain(Middleware()).ThenFunc(
+		func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			// Proxy the call upstream inside the server span
+			upstreamReq := &jsonrpc.Request{ID: 9, Method: req.Method}
+			_, span := StartUpstreamSpan(ctx, "github", upstreamReq)
+			EndWithResponse(span, jsonrpc.NewErrorResponse(jsonrpc.NewInternalError(nil), 9))
+			return jsonrpc.NewResponse("ok", req.ID)
+		})
+
+	handler.Handle(context.Background(), &jsonrpc.Request{
+		ID:     1,
+		Method: "tools/call",
+		Params: map[string]interface{}{MetaKey: map[string]interface{}{"traceparent": testTraceparent}},
+	})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	upstream, server := spans[0], spans[1]
+	assert.Equal(t, "tools/call", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+
+	assert.Equal(t, "github tools/call", upstream.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), upstream.Parent().SpanID())
+	assert.Equal(t, codes.Error, upstream.Status().Code)
+}
+
+func TestAsyncMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	parentCtx, parent := otel.Tracer("test").Start(context.Background(), "enqueue")
+	rc := router.NewRequestContext("corr-42")
+	parentCtx = router.WithRequestContext(parentCtx, rc)
+
+	handler := router.NewChain(AsyncMiddleware()).ThenFunc(
+		func(ctx context.Context, req *jsonrpc.Request) *jsonrpc.Response {
+			return jsonrpc.NewResponse("ok", req.ID)
+		})
+	handler.Handle(parentCtx, &jsonrpc.Request{ID: 1, Method: "tools/call"})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	async := spans[0]
+	assert.NotEqual(t, parent.SpanContext().TraceID(), async.SpanContext().TraceID())
+	require.Len(t, async.Links(), 1)
+	assert.Equal(t, parent.SpanContext().SpanID(), async.Links()[0].SpanContext.SpanID())
+	assert.Equal(t, "corr-42", async.Links()[0].Attributes[0].Value.AsString())
+}
+
+func TestToolMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	handler := ToolMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("boom"), nil
+	})
+
+	var request mcp.CallToolRequest
+	request.Params.Name = "echo"
+	request.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{"traceparent": testTraceparent}}
+	handler(context.Background(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "tools/call echo", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	var sc trace.SpanContext
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc = trace.SpanContextFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/message", nil)
+	req.Header.Set("traceparent", testTraceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+}
@@ -0,0 +1,405 @@
+// Package truncate caps the size of tool and resource results sent to
+// clients, so a single oversized result does not flood the context window
+// of the model behind the client.
+//
+// A Limiter counts the text and base64 data of a result against
+// Config.MaxBytes. Content past the limit is cut, text at a character
+// boundary and resource blobs at a base64 quantum, and the result is
+// marked in _meta.truncated:
+//
+//	"_meta": {"truncated": {
+//		"originalBytes": 1048576,
+//		"returnedBytes": 65536,
+//		"continuation": "continuation://3f0c..."
+//	}}
+//
+// When Config.TTL is set, the remainder is kept for that long and the
+// client fetches it by reading the continuation URI with resources/read.
+// That result is limited the same way, with a new continuation for what
+// still does not fit. Images and audio inside tool results are never cut:
+// one that does not fit moves to the remainder whole, where it is served
+// as a blob.
+//
+// Basic usage:
+//
+//	limiter := truncate.New(truncate.Config{MaxBytes: 64 << 10, TTL: 5 * time.Minute})
+//	srv := server.New(hs, server.Config{Responses: limiter})
+package truncate
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+const (
+	// MetaTruncated is the _meta key of a truncated result, holding its
+	// Marker
+	MetaTruncated = "truncated"
+
+	// ContinuationPrefix starts the URIs of remainders
+	ContinuationPrefix = "continuation://"
+
+	// DefaultMaxPending is the default number of remainders kept
+	DefaultMaxPending = 256
+)
+
+// ErrUnknownContinuation is returned for a continuation URI that expired,
+// was already read or belongs to another connection
+var ErrUnknownContinuation = errors.New("unknown or expired continuation")
+
+// Marker describes a truncated result in _meta.truncated
+type Marker struct {
+	// OriginalBytes counts the text and data of the whole result
+	OriginalBytes int `json:"originalBytes"`
+
+	// ReturnedBytes counts the text and data kept in the result
+	ReturnedBytes int `json:"returnedBytes"`
+
+	// Continuation is the URI to read the remainder from; empty when it
+	// was dropped
+	Continuation string `json:"continuation,omitempty"`
+}
+
+// Config contains configuration for a Limiter
+type Config struct {
+	// MaxBytes caps the text and data of a result (0 disables the limit)
+	MaxBytes int
+
+	// TTL is how long the remainder of a truncated result can be read
+	// (0 drops remainders)
+	TTL time.Duration
+
+	// MaxPending caps the remainders kept; the oldest are dropped first
+	// (defaults to DefaultMaxPending)
+	MaxPending int
+
+	// Clock times TTL (defaults to the system clock)
+	Clock clock.Clock
+}
+
+// Stats counts truncated results
+type Stats struct {
+	// Truncated results were cut to MaxBytes
+	Truncated int64 `json:"truncated"`
+
+	// Continued remainders were read by their client
+	Continued int64 `json:"continued"`
+
+	// Dropped remainders expired or were evicted unread
+	Dropped int64 `json:"dropped"`
+
+	// Pending remainders can still be read
+	Pending int `json:"pending"`
+}
+
+// Limiter truncates oversized results and keeps their remainders
+type Limiter struct {
+	config Config
+
+	mu      sync.Mutex
+	pending map[string]*remainder
+	order   []string
+	stats   Stats
+}
+
+// remainder is the content cut from a result
+type remainder struct {
+	owner    string
+	contents []mcp.ResourceContents
+	expires  time.Time
+}
+
+// New creates a limiter
+func New(config Config) *Limiter {
+	if config.MaxBytes > 0 {
+		// Room for a character or base64 quantum, so every continuation
+		// returns something
+		config.MaxBytes = max(config.MaxBytes, utf8.UTFMax)
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = DefaultMaxPending
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &Limiter{config: config, pending: make(map[string]*remainder)}
+}
+
+// IsContinuation reports whether uri names the remainder of a truncated
+// result
+func IsContinuation(uri string) bool {
+	return strings.HasPrefix(uri, ContinuationPrefix)
+}
+
+// MaxBytes returns the limit on the text and data of a result
+func (l *Limiter) MaxBytes() int {
+	return l.config.MaxBytes
+}
+
+// ToolResult limits the content of a tool result sent to the connection
+// owner, reporting whether it was truncated
+func (l *Limiter) ToolResult(owner string, result mcp.CallToolResult) (mcp.CallToolResult, bool) {
+	if l.config.MaxBytes <= 0 {
+		return result, false
+	}
+	b := budget{left: l.config.MaxBytes}
+	var rest []mcp.ResourceContents
+	kept := make([]mcp.Content, 0, len(result.Content))
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			text, cut := b.take(c.Text, runeBoundary)
+			if text != "" || cut == "" {
+				c.Text = text
+				kept = append(kept, c)
+			}
+			if cut != "" {
+				rest = append(rest, mcp.TextResourceContents{MIMEType: "text/plain", Text: cut})
+			}
+		case mcp.ImageContent:
+			data, cut := b.take(c.Data, nothing)
+			if cut == "" {
+				kept = append(kept, c)
+			} else {
+				rest = append(rest, mcp.BlobResourceContents{MIMEType: c.MIMEType, Blob: data + cut})
+			}
+		case mcp.AudioContent:
+			data, cut := b.take(c.Data, nothing)
+			if cut == "" {
+				kept = append(kept, c)
+			} else {
+				rest = append(rest, mcp.BlobResourceContents{MIMEType: c.MIMEType, Blob: data + cut})
+			}
+		case mcp.EmbeddedResource:
+			resource, cut := b.contents(c.Resource)
+			if resource != nil {
+				c.Resource = resource
+				kept = append(kept, c)
+			}
+			if cut != nil {
+				rest = append(rest, cut)
+			}
+		default:
+			// Links and other small content are always kept
+			kept = append(kept, content)
+		}
+	}
+	if !b.cut {
+		return result, false
+	}
+	result.Content = kept
+	result.Meta = l.mark(owner, result.Meta, b, rest)
+	return result, true
+}
+
+// ReadResult limits the contents of a resources/read result sent to the
+// connection owner, reporting whether it was truncated
+func (l *Limiter) ReadResult(owner string, result mcp.ReadResourceResult) (mcp.ReadResourceResult, bool) {
+	if l.config.MaxBytes <= 0 {
+		return result, false
+	}
+	b := budget{left: l.config.MaxBytes}
+	var rest []mcp.ResourceContents
+	kept := make([]mcp.ResourceContents, 0, len(result.Contents))
+	for _, contents := range result.Contents {
+		resource, cut := b.contents(contents)
+		if resource != nil {
+			kept = append(kept, resource)
+		}
+		if cut != nil {
+			rest = append(rest, cut)
+		}
+	}
+	if !b.cut {
+		return result, false
+	}
+	result.Contents = kept
+	result.Meta = l.mark(owner, result.Meta, b, rest)
+	return result, true
+}
+
+// Continue returns the remainder named by uri, limited like any result.
+// A remainder can be read once, by the connection it was cut for.
+func (l *Limiter) Continue(owner, uri string) (mcp.ReadResourceResult, error) {
+	id := strings.TrimPrefix(uri, ContinuationPrefix)
+
+	l.mu.Lock()
+	l.sweep()
+	r, ok := l.pending[id]
+	if ok && r.owner == owner {
+		l.remove(id)
+		l.stats.Continued++
+	}
+	l.mu.Unlock()
+	if !ok || r.owner != owner {
+		return mcp.ReadResourceResult{}, ErrUnknownContinuation
+	}
+
+	contents := make([]mcp.ResourceContents, len(r.contents))
+	for i, c := range r.contents {
+		contents[i] = withURI(c, uri)
+	}
+	result, _ := l.ReadResult(owner, mcp.ReadResourceResult{Contents: contents})
+	return result, nil
+}
+
+// Stats returns counts of truncated results
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep()
+	stats := l.stats
+	stats.Pending = len(l.pending)
+	return stats
+}
+
+// mark returns a copy of meta with the Marker of a truncated result,
+// keeping its remainder
+func (l *Limiter) mark(owner string, meta map[string]any, b budget, rest []mcp.ResourceContents) map[string]any {
+	marker := Marker{OriginalBytes: b.original, ReturnedBytes: b.returned}
+
+	l.mu.Lock()
+	l.stats.Truncated++
+	if l.config.TTL > 0 && len(rest) > 0 {
+		marker.Continuation = ContinuationPrefix + l.keep(owner, rest)
+	}
+	l.mu.Unlock()
+
+	meta = maps.Clone(meta)
+	if meta == nil {
+		meta = make(map[string]any, 1)
+	}
+	meta[MetaTruncated] = marker
+	return meta
+}
+
+// keep stores a remainder, returning its ID; the caller holds mu
+func (l *Limiter) keep(owner string, contents []mcp.ResourceContents) string {
+	l.sweep()
+	for len(l.order) >= l.config.MaxPending {
+		l.remove(l.order[0])
+		l.stats.Dropped++
+	}
+	id := uuid.NewString()
+	l.pending[id] = &remainder{owner: owner, contents: contents, expires: l.config.Clock.Now().Add(l.config.TTL)}
+	l.order = append(l.order, id)
+	return id
+}
+
+// sweep drops expired remainders; the caller holds mu
+func (l *Limiter) sweep() {
+	now := l.config.Clock.Now()
+	for len(l.order) > 0 && !now.Before(l.pending[l.order[0]].expires) {
+		l.remove(l.order[0])
+		l.stats.Dropped++
+	}
+}
+
+// remove forgets a remainder; the caller holds mu
+func (l *Limiter) remove(id string) {
+	delete(l.pending, id)
+	for i, pending := range l.order {
+		if pending == id {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// budget hands out the bytes a result may carry
+type budget struct {
+	left     int
+	original int
+	returned int
+	cut      bool
+}
+
+// take returns the part of s within the budget and the rest. Once
+// anything was cut, everything after it is cut too so the remainder keeps
+// its order. boundary returns where to cut s to at most n bytes.
+func (b *budget) take(s string, boundary func(s string, n int) int) (kept, rest string) {
+	b.original += len(s)
+	if b.cut {
+		return "", s
+	}
+	if len(s) <= b.left {
+		b.left -= len(s)
+		b.returned += len(s)
+		return s, ""
+	}
+	n := boundary(s, b.left)
+	b.left = 0
+	b.returned += n
+	b.cut = true
+	return s[:n], s[n:]
+}
+
+// contents splits resource contents at the budget, returning nil for an
+// empty part
+func (b *budget) contents(contents mcp.ResourceContents) (kept, rest mcp.ResourceContents) {
+	switch c := contents.(type) {
+	case mcp.TextResourceContents:
+		text, cut := b.take(c.Text, runeBoundary)
+		if text != "" || cut == "" {
+			kept = mcp.TextResourceContents{URI: c.URI, MIMEType: c.MIMEType, Text: text}
+		}
+		if cut != "" {
+			rest = mcp.TextResourceContents{URI: c.URI, MIMEType: c.MIMEType, Text: cut}
+		}
+	case mcp.BlobResourceContents:
+		blob, cut := b.take(c.Blob, base64Boundary)
+		if blob != "" || cut == "" {
+			kept = mcp.BlobResourceContents{URI: c.URI, MIMEType: c.MIMEType, Blob: blob}
+		}
+		if cut != "" {
+			rest = mcp.BlobResourceContents{URI: c.URI, MIMEType: c.MIMEType, Blob: cut}
+		}
+	default:
+		kept = contents
+	}
+	return kept, rest
+}
+
+// runeBoundary cuts text between characters
+func runeBoundary(s string, n int) int {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
+
+// base64Boundary cuts base64 data between quanta, so each part decodes on
+// its own
+func base64Boundary(_ string, n int) int {
+	return n - n%4
+}
+
+// nothing keeps content that cannot be cut out of the result whole
+func nothing(string, int) int {
+	return 0
+}
+
+// withURI sets the URI of remainder contents cut from tool content, which
+// has none
+func withURI(contents mcp.ResourceContents, uri string) mcp.ResourceContents {
+	switch c := contents.(type) {
+	case mcp.TextResourceContents:
+		if c.URI == "" {
+			c.URI = uri
+		}
+		return c
+	case mcp.BlobResourceContents:
+		if c.URI == "" {
+			c.URI = uri
+		}
+		return c
+	}
+	return contents
+}
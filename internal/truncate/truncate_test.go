@@ -0,0 +1,143 @@
+package truncate
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+)
+
+func TestLimiter_ToolResult(t *testing.T) {
+	l := New(Config{MaxBytes: 10, TTL: time.Minute})
+
+	small := mcp.NewToolResultText("fits")
+	result, truncated := l.ToolResult("conn-1", *small)
+	assert.False(t, truncated)
+	assert.Equal(t, *small, result)
+
+	image := mcp.ImageContent{Type: "image", Data: "aGVsbG8=", MIMEType: "image/png"}
+	original := mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewTextContent("0123456"),
+		image,
+		mcp.NewTextContent("tail"),
+	}}
+	original.Meta = map[string]any{"trace": "t-1"}
+	result, truncated = l.ToolResult("conn-1", original)
+	require.True(t, truncated)
+
+	// The image does not fit whole, so it and everything after it move to
+	// the remainder
+	assert.Equal(t, []mcp.Content{mcp.NewTextContent("0123456")}, result.Content)
+	marker := result.Meta[MetaTruncated].(Marker)
+	assert.Equal(t, 19, marker.OriginalBytes)
+	assert.Equal(t, 7, marker.ReturnedBytes)
+	assert.Equal(t, "t-1", result.Meta["trace"])
+	assert.NotContains(t, original.Meta, MetaTruncated, "the handler's result must not change")
+
+	rest, err := l.Continue("conn-1", marker.Continuation)
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.ResourceContents{
+		mcp.BlobResourceContents{URI: marker.Continuation, MIMEType: "image/png", Blob: "aGVsbG8="},
+		mcp.TextResourceContents{URI: marker.Continuation, MIMEType: "text/plain", Text: "ta"},
+	}, rest.Contents)
+	next := rest.Meta[MetaTruncated].(Marker)
+	assert.Equal(t, Marker{OriginalBytes: 12, ReturnedBytes: 10, Continuation: next.Continuation}, next)
+
+	rest, err = l.Continue("conn-1", next.Continuation)
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: marker.Continuation, MIMEType: "text/plain", Text: "il"},
+	}, rest.Contents)
+	assert.Nil(t, rest.Meta)
+	assert.Equal(t, Stats{Truncated: 2, Continued: 2}, l.Stats())
+}
+
+func TestLimiter_ReadResult(t *testing.T) {
+	l := New(Config{MaxBytes: 10, TTL: time.Minute})
+	data := []byte("binary payload")
+	blob := base64.StdEncoding.EncodeToString(data)
+
+	result, truncated := l.ReadResult("conn-1", mcp.ReadResourceResult{Contents: []mcp.ResourceContents{
+		mcp.BlobResourceContents{URI: "file:///a.bin", MIMEType: "application/octet-stream", Blob: blob},
+	}})
+	require.True(t, truncated)
+
+	// Blobs are cut between base64 quanta, so the parts decode and
+	// concatenate to the original
+	var decoded []byte
+	for {
+		require.Len(t, result.Contents, 1)
+		part := result.Contents[0].(mcp.BlobResourceContents)
+		assert.Equal(t, "file:///a.bin", part.URI)
+		assert.LessOrEqual(t, len(part.Blob), 10)
+		chunk, err := base64.StdEncoding.DecodeString(part.Blob)
+		require.NoError(t, err)
+		decoded = append(decoded, chunk...)
+
+		marker, ok := result.Meta[MetaTruncated].(Marker)
+		if !ok {
+			break
+		}
+		result, err = l.Continue("conn-1", marker.Continuation)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, data, decoded)
+}
+
+func TestLimiter_CutsBetweenCharacters(t *testing.T) {
+	l := New(Config{MaxBytes: 5})
+
+	result, truncated := l.ReadResult("conn-1", mcp.ReadResourceResult{Contents: []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: "file:///a.txt", Text: "añoñoño"},
+	}})
+	require.True(t, truncated)
+	assert.Equal(t, "año", result.Contents[0].(mcp.TextResourceContents).Text)
+
+	// Without a TTL the remainder is dropped
+	assert.Equal(t, Marker{OriginalBytes: 10, ReturnedBytes: 4}, result.Meta[MetaTruncated])
+	assert.Zero(t, l.Stats().Pending)
+}
+
+func TestLimiter_Continue(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := New(Config{MaxBytes: 4, TTL: time.Minute, MaxPending: 2, Clock: fake})
+	long := mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent(strings.Repeat("x", 6))}}
+
+	continuation := func() string {
+		result, truncated := l.ToolResult("conn-1", long)
+		require.True(t, truncated)
+		return result.Meta[MetaTruncated].(Marker).Continuation
+	}
+	first, second := continuation(), continuation()
+
+	// Remainders belong to the connection they were cut for
+	_, err := l.Continue("conn-2", first)
+	assert.ErrorIs(t, err, ErrUnknownContinuation)
+
+	// The oldest remainder is dropped above MaxPending
+	third := continuation()
+	_, err = l.Continue("conn-1", first)
+	assert.ErrorIs(t, err, ErrUnknownContinuation)
+
+	fake.Advance(2 * time.Minute)
+	for _, uri := range []string{second, third, ContinuationPrefix + "unknown"} {
+		_, err = l.Continue("conn-1", uri)
+		assert.ErrorIs(t, err, ErrUnknownContinuation)
+	}
+	assert.Equal(t, Stats{Truncated: 3, Dropped: 3}, l.Stats())
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := New(Config{})
+	long := mcp.NewToolResultText(strings.Repeat("x", 1<<20))
+	result, truncated := l.ToolResult("conn-1", *long)
+	assert.False(t, truncated)
+	assert.Equal(t, *long, result)
+	assert.Zero(t, l.MaxBytes())
+}
@@ -0,0 +1,263 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+)
+
+// AllTools keys the ArgumentRules applying to every tool of an upstream
+const AllTools = "*"
+
+// ArgumentRules adjusts the arguments of calls to an upstream tool before
+// they are proxied. String values are text/template templates over the
+// call:
+//
+//	{{.connection.id}}             connection ID
+//	{{.connection.clientName}}     client name and version sent in initialize
+//	{{.connection.clientVersion}}
+//	{{.connection.root}}           path of the client's first root
+//	{{.connection.roots}}          paths of all the client's roots
+//	{{.arguments.name}}            argument sent by the client
+//	{{.tool}} {{.upstream}}        upstream tool and upstream name
+//
+// A value referring to something the call lacks, e.g. a root when the
+// client has none, fails the call.
+type ArgumentRules struct {
+	// Defaults are added to calls that omit them. The parameters become
+	// optional in the advertised input schema.
+	Defaults map[string]any `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+
+	// Inject is always passed, replacing what the client sent. The
+	// parameters are removed from the advertised input schema, so clients
+	// cannot see or override them.
+	Inject map[string]any `yaml:"inject,omitempty" json:"inject,omitempty"`
+}
+
+// argumentValue is a compiled argument of ArgumentRules
+type argumentValue struct {
+	value    any
+	template *template.Template
+	roots    bool
+}
+
+// argumentRules are compiled ArgumentRules
+type argumentRules struct {
+	defaults map[string]argumentValue
+	inject   map[string]argumentValue
+}
+
+// compileArguments compiles the argument rules of spec by upstream tool
+// name
+func compileArguments(spec Spec) (map[string]*argumentRules, error) {
+	compiled := make(map[string]*argumentRules, len(spec.Arguments))
+	for tool, rules := range spec.Arguments {
+		defaults, err := compileValues(rules.Defaults)
+		if err != nil {
+			return nil, fmt.Errorf("arguments of %s: %w", tool, err)
+		}
+		inject, err := compileValues(rules.Inject)
+		if err != nil {
+			return nil, fmt.Errorf("arguments of %s: %w", tool, err)
+		}
+		compiled[tool] = &argumentRules{defaults: defaults, inject: inject}
+	}
+	return compiled, nil
+}
+
+// compileValues parses the templates among values
+func compileValues(values map[string]any) (map[string]argumentValue, error) {
+	compiled := make(map[string]argumentValue, len(values))
+	for name, value := range values {
+		text, ok := value.(string)
+		if !ok || !strings.Contains(text, "{{") {
+			compiled[name] = argumentValue{value: value}
+			continue
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		compiled[name] = argumentValue{template: tmpl, roots: strings.Contains(text, ".connection.root")}
+	}
+	return compiled, nil
+}
+
+// argumentsFor merges the rules of all tools with those of the upstream
+// tool name, returning nil when none apply
+func argumentsFor(compiled map[string]*argumentRules, name string) *argumentRules {
+	all, tool := compiled[AllTools], compiled[name]
+	if all == nil || tool == nil {
+		if tool != nil {
+			return tool
+		}
+		return all
+	}
+	merged := &argumentRules{defaults: maps.Clone(all.defaults), inject: maps.Clone(all.inject)}
+	maps.Copy(merged.defaults, tool.defaults)
+	maps.Copy(merged.inject, tool.inject)
+	return merged
+}
+
+// apply returns request with the defaults and injections of r. The
+// client's arguments are copied, never modified.
+func (r *argumentRules) apply(ctx context.Context, m *Manager, upstream, tool string, request mcp.CallToolRequest) (mcp.CallToolRequest, error) {
+	if r == nil {
+		return request, nil
+	}
+	sent := request.GetArguments()
+	arguments := maps.Clone(sent)
+	if arguments == nil {
+		arguments = make(map[string]any)
+	}
+
+	var data map[string]any
+	render := func(name string, v argumentValue) error {
+		if v.template == nil {
+			arguments[name] = v.value
+			return nil
+		}
+		if data == nil || (v.roots && !hasRoots(data)) {
+			var err error
+			if data, err = m.templateData(ctx, upstream, tool, sent, v.roots); err != nil {
+				return err
+			}
+		}
+		var out bytes.Buffer
+		if err := v.template.Execute(&out, data); err != nil {
+			return fmt.Errorf("argument %s: %w", name, err)
+		}
+		arguments[name] = out.String()
+		return nil
+	}
+
+	for name, v := range r.defaults {
+		if _, ok := arguments[name]; ok {
+			continue
+		}
+		if err := render(name, v); err != nil {
+			return request, err
+		}
+	}
+	for name, v := range r.inject {
+		if err := render(name, v); err != nil {
+			return request, err
+		}
+	}
+	request.Params.Arguments = arguments
+	return request, nil
+}
+
+// templateData returns the values argument templates refer to. Roots are
+// asked from the client only when a template needs them.
+func (m *Manager) templateData(ctx context.Context, upstream, tool string, arguments map[string]any, withRoots bool) (map[string]any, error) {
+	conn := map[string]any{}
+	if id, ok := connection.GetConnectionID(ctx); ok {
+		conn["id"] = id
+	}
+	if c, ok := connection.FromContext(ctx); ok && c.IsReady() {
+		info := c.Info()
+		conn["clientName"] = info.ClientInfo["name"]
+		conn["clientVersion"] = info.ClientInfo["version"]
+		conn["protocolVersion"] = info.ProtocolVersion
+	}
+	if withRoots {
+		roots, err := m.config.Roots(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list roots: %w", err)
+		}
+		conn["roots"] = roots
+		if len(roots) > 0 {
+			conn["root"] = roots[0]
+		}
+	}
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	return map[string]any{
+		"connection": conn,
+		"arguments":  arguments,
+		"tool":       tool,
+		"upstream":   upstream,
+	}, nil
+}
+
+// hasRoots reports whether template data includes the client's roots
+func hasRoots(data map[string]any) bool {
+	_, ok := data["connection"].(map[string]any)["roots"]
+	return ok
+}
+
+// clientRoots asks the client of ctx for its roots with roots/list,
+// returning file roots as paths
+func clientRoots(ctx context.Context) ([]string, error) {
+	c, ok := connection.FromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	result, err := c.ListRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]string, 0, len(result.Roots))
+	for _, root := range result.Roots {
+		if u, err := url.Parse(root.URI); err == nil && u.Scheme == "file" {
+			roots = append(roots, u.Path)
+			continue
+		}
+		roots = append(roots, root.URI)
+	}
+	return roots, nil
+}
+
+// hideArguments returns tool with the injected parameters of r removed
+// from its input schema and the defaulted ones made optional
+func hideArguments(tool mcp.Tool, r *argumentRules) mcp.Tool {
+	if r == nil {
+		return tool
+	}
+	hidden := func(name string) bool {
+		_, ok := r.inject[name]
+		return ok
+	}
+	optional := func(name string) bool {
+		_, defaulted := r.defaults[name]
+		return defaulted || hidden(name)
+	}
+
+	if len(tool.RawInputSchema) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(tool.RawInputSchema, &schema); err != nil {
+			return tool
+		}
+		if properties, ok := schema["properties"].(map[string]any); ok {
+			maps.DeleteFunc(properties, func(name string, _ any) bool { return hidden(name) })
+		}
+		if required, ok := schema["required"].([]any); ok {
+			schema["required"] = slices.DeleteFunc(required, func(name any) bool {
+				s, _ := name.(string)
+				return optional(s)
+			})
+		}
+		if data, err := json.Marshal(schema); err == nil {
+			tool.RawInputSchema = data
+		}
+		return tool
+	}
+
+	properties := maps.Clone(tool.InputSchema.Properties)
+	maps.DeleteFunc(properties, func(name string, _ any) bool { return hidden(name) })
+	tool.InputSchema.Properties = properties
+	tool.InputSchema.Required = slices.DeleteFunc(slices.Clone(tool.InputSchema.Required), optional)
+	return tool
+}
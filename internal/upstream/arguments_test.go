@@ -0,0 +1,133 @@
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+func TestArgumentRules_Apply(t *testing.T) {
+	compiled, err := compileArguments(Spec{Arguments: map[string]ArgumentRules{
+		AllTools: {
+			Defaults: map[string]any{"limit": 10, "format": "text"},
+			Inject:   map[string]any{"caller": "{{.connection.id}}"},
+		},
+		"search": {
+			Defaults: map[string]any{"format": "json"},
+			Inject:   map[string]any{"workspace": "{{.connection.root}}", "label": "{{.upstream}}/{{.tool}}: {{.arguments.query}}"},
+		},
+	}})
+	require.NoError(t, err)
+	m := &Manager{config: Config{Roots: func(ctx context.Context) ([]string, error) {
+		return []string{"/home/dev/project", "/tmp"}, nil
+	}}}
+
+	request := mcp.CallToolRequest{}
+	sent := map[string]any{"query": "todo", "limit": 5, "workspace": "/etc", "caller": "spoofed"}
+	request.Params.Arguments = sent
+	ctx := connection.WithConnectionID(context.Background(), "conn-1")
+
+	request, err = argumentsFor(compiled, "search").apply(ctx, m, "fs", "search", request)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"query":     "todo",
+		"limit":     5,
+		"format":    "json",
+		"workspace": "/home/dev/project",
+		"caller":    "conn-1",
+		"label":     "fs/search: todo",
+	}, request.GetArguments())
+	assert.Equal(t, "/etc", sent["workspace"], "the client's arguments must not change")
+
+	// Other tools only get the rules for all tools
+	request.Params.Arguments = nil
+	request, err = argumentsFor(compiled, "read").apply(ctx, m, "fs", "read", request)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"limit": 10, "format": "text", "caller": "conn-1"}, request.GetArguments())
+
+	// Without rules the request is unchanged
+	var none *argumentRules
+	unchanged, err := none.apply(ctx, m, "fs", "read", request)
+	require.NoError(t, err)
+	assert.Equal(t, request, unchanged)
+}
+
+func TestArgumentRules_ApplyErrors(t *testing.T) {
+	compiled, err := compileArguments(Spec{Arguments: map[string]ArgumentRules{
+		"search": {Inject: map[string]any{"workspace": "{{.connection.root}}"}},
+	}})
+	require.NoError(t, err)
+	rules := argumentsFor(compiled, "search")
+
+	// A client without roots cannot call the tool
+	m := &Manager{config: Config{Roots: func(ctx context.Context) ([]string, error) { return nil, nil }}}
+	_, err = rules.apply(context.Background(), m, "fs", "search", mcp.CallToolRequest{})
+	assert.ErrorContains(t, err, "argument workspace")
+
+	m.config.Roots = func(ctx context.Context) ([]string, error) { return nil, errors.New("roots not supported") }
+	_, err = rules.apply(context.Background(), m, "fs", "search", mcp.CallToolRequest{})
+	assert.ErrorContains(t, err, "roots not supported")
+
+	_, err = compileArguments(Spec{Arguments: map[string]ArgumentRules{
+		"search": {Inject: map[string]any{"workspace": "{{.connection.root"}},
+	}})
+	assert.ErrorContains(t, err, "arguments of search: workspace")
+}
+
+func TestHideArguments(t *testing.T) {
+	compiled, err := compileArguments(Spec{Arguments: map[string]ArgumentRules{
+		"search": {Defaults: map[string]any{"limit": 10}, Inject: map[string]any{"workspace": "/srv"}},
+	}})
+	require.NoError(t, err)
+	rules := argumentsFor(compiled, "search")
+
+	tool := mcp.NewTool("search",
+		mcp.WithString("query", mcp.Required()),
+		mcp.WithNumber("limit", mcp.Required()),
+		mcp.WithString("workspace", mcp.Required()))
+	hidden := hideArguments(tool, rules)
+	assert.Equal(t, []string{"query"}, hidden.InputSchema.Required)
+	assert.Contains(t, hidden.InputSchema.Properties, "limit")
+	assert.NotContains(t, hidden.InputSchema.Properties, "workspace")
+	assert.Contains(t, tool.InputSchema.Properties, "workspace", "the upstream's tool must not change")
+	assert.Len(t, tool.InputSchema.Required, 3)
+
+	raw := mcp.NewToolWithRawSchema("search", "", json.RawMessage(
+		`{"type":"object","properties":{"query":{"type":"string"},"workspace":{"type":"string"}},"required":["query","workspace","limit"]}`))
+	hidden = hideArguments(raw, rules)
+	assert.JSONEq(t, `{"type":"object","properties":{"query":{"type":"string"}},"required":["query"]}`, string(hidden.RawInputSchema))
+}
+
+func TestManager_Arguments(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry, CallTimeout: 5 * time.Second})
+	defer manager.Shutdown(context.Background())
+
+	spec := helperSpec(t, "helper")
+	spec.Arguments = map[string]ArgumentRules{"echo": {Inject: map[string]any{"message": "from {{.upstream}}"}}}
+	require.NoError(t, manager.Add(context.Background(), spec))
+
+	published := registry.Visible(context.Background())
+	for _, tool := range published {
+		if tool.Name == "helper_echo" {
+			assert.NotContains(t, tool.InputSchema.Properties, "message")
+		}
+	}
+
+	result, err := call(registry, "helper_echo", map[string]any{"message": "override"})
+	require.NoError(t, err)
+	assert.Equal(t, "from helper", result.Content[0].(mcp.TextContent).Text)
+
+	spec = helperSpec(t, "broken")
+	spec.Arguments = map[string]ArgumentRules{"echo": {Defaults: map[string]any{"message": "{{"}}}
+	assert.ErrorContains(t, manager.Add(context.Background(), spec), "upstream broken: arguments of echo")
+}
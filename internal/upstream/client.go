@@ -0,0 +1,636 @@
+package upstream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/client"
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	"github.com/meta-mcp/meta-mcp-server/internal/ids"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/router"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// stopTimeout is how long a stdio upstream may take to exit after its stdin
+// is closed before it is killed
+const stopTimeout = 5 * time.Second
+
+// errProcessExited fails requests in flight when a stdio upstream exits
+var errProcessExited = errors.New("upstream process exited")
+
+// upstream is a connected upstream server
+type upstream struct {
+	manager *Manager
+	spec    Spec
+
+	// arguments are the compiled argument rules of spec
+	arguments map[string]*argumentRules
+
+	// identity presents this server in the handshake
+	identity client.Config
+
+	// notifications decide which notifications of the upstream are
+	// forwarded
+	notifications []*notificationRule
+
+	// progress maps the progress tokens sent to the upstream to the calls
+	// they report on
+	progressMu sync.Mutex
+	progress   map[string]progressTarget
+
+	// syncMu serializes tool list synchronization
+	syncMu sync.Mutex
+
+	// startMu serializes starting and stopping the server
+	startMu sync.Mutex
+
+	mu     sync.Mutex
+	client *client.Client
+
+	// process and exited are set while a stdio upstream runs; exited is
+	// closed once the process has been waited for
+	process *exec.Cmd
+	exited  chan struct{}
+
+	state           State
+	server          mcp.Implementation
+	protocolVersion string
+	tools           []string
+	connectedAt     time.Time
+	lastError       string
+	closing         bool
+
+	// noiseLines counts the non-JSON output of a stdio upstream on stdout
+	noiseLines int
+
+	// forwarded and dropped count the notifications of the upstream
+	forwarded int
+	dropped   int
+
+	// inFlight counts calls in progress and lastUsed is when the last one
+	// ended; idleTimer stops the upstream once unused for IdleTimeout
+	inFlight  int
+	lastUsed  time.Time
+	idleTimer *time.Timer
+}
+
+// connect starts the upstream of spec, performs the handshake and
+// registers the upstream's tools. A lazy upstream is stopped again once
+// its tools are known.
+func connect(ctx context.Context, m *Manager, spec Spec, arguments map[string]*argumentRules, identity client.Config, notifications []*notificationRule) (*upstream, error) {
+	u := &upstream{manager: m, spec: spec, arguments: arguments, identity: identity, notifications: notifications}
+	fail := func(err error) (*upstream, error) {
+		u.close()
+		return nil, fmt.Errorf("upstream %s: %w", spec.Name, err)
+	}
+
+	if err := u.start(ctx); err != nil {
+		return fail(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.InitTimeout)
+	defer cancel()
+	ctx, cancelExit := u.withExit(ctx)
+	defer cancelExit()
+	if err := u.syncTools(ctx); err != nil {
+		return fail(err)
+	}
+
+	if spec.Lazy {
+		if err := u.stop(); err != nil {
+			return fail(err)
+		}
+	}
+	return u, nil
+}
+
+// start opens the transport of the spec and performs the handshake
+func (u *upstream) start(ctx context.Context) error {
+	t, err := u.newTransport()
+	if err != nil {
+		return err
+	}
+	config := u.identity
+	config.Middleware = []client.Middleware{client.Tracing(u.spec.Name)}
+	config.RequestIDs = ids.NewUUIDv7
+	c := client.New(t, config)
+	u.mu.Lock()
+	u.client = c
+	u.mu.Unlock()
+
+	// An SSE stream lives as long as the context passed to Start, so it
+	// must not be bounded by the connect timeout
+	if err := c.Start(context.Background()); err != nil {
+		return err
+	}
+	c.Subscribe("", u.onNotification)
+
+	ctx, cancel := context.WithTimeout(ctx, u.manager.config.InitTimeout)
+	defer cancel()
+	ctx, cancelExit := u.withExit(ctx)
+	defer cancelExit()
+
+	result, err := c.Initialize(ctx)
+	if errors.Is(context.Cause(ctx), errProcessExited) {
+		u.mu.Lock()
+		err = fmt.Errorf("%w: %s", errProcessExited, u.lastError)
+		u.mu.Unlock()
+	}
+	if err != nil {
+		return fmt.Errorf("initialize: %w", err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.state = StateConnected
+	u.publish(StateConnected, "")
+	u.server = result.ServerInfo
+	u.protocolVersion = result.ProtocolVersion
+	u.connectedAt = time.Now()
+	u.lastUsed = u.connectedAt
+	if u.exited != nil {
+		go u.supervise(u.exited)
+		u.armIdleTimer()
+	}
+	return nil
+}
+
+// stop shuts a stdio upstream down while keeping its tools published; the
+// next call starts it again
+func (u *upstream) stop() error {
+	u.mu.Lock()
+	u.state = StateIdle
+	c, process, exited := u.client, u.process, u.exited
+	u.client, u.process, u.exited = nil, nil, nil
+	u.mu.Unlock()
+	u.publish(StateIdle, "")
+
+	var err error
+	if c != nil {
+		err = c.Close()
+	}
+	waitProcess(process, exited)
+
+	// The exit of a stopped process is not an error
+	u.mu.Lock()
+	u.lastError = ""
+	u.mu.Unlock()
+	return err
+}
+
+// acquire starts an idle upstream and counts a call in progress until
+// release
+func (u *upstream) acquire(ctx context.Context) (*client.Client, error) {
+	u.startMu.Lock()
+	defer u.startMu.Unlock()
+
+	u.mu.Lock()
+	state := u.state
+	u.mu.Unlock()
+	if state == StateIdle {
+		if err := u.start(ctx); err != nil {
+			u.stop()
+			u.mu.Lock()
+			u.lastError = err.Error()
+			u.mu.Unlock()
+			return nil, mcperrors.NewServiceUnavailableError("upstream "+u.spec.Name, err.Error())
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state != StateConnected {
+		return nil, mcperrors.NewServiceUnavailableError("upstream "+u.spec.Name, string(u.state))
+	}
+	u.inFlight++
+	return u.client, nil
+}
+
+// release ends a call counted by acquire
+func (u *upstream) release() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.inFlight--
+	u.lastUsed = time.Now()
+	if u.inFlight == 0 {
+		u.armIdleTimer()
+	}
+}
+
+// armIdleTimer schedules an idle check of a running stdio upstream. It must
+// be called with u.mu held.
+func (u *upstream) armIdleTimer() {
+	timeout := u.spec.IdleTimeout
+	if timeout <= 0 {
+		return
+	}
+	if u.idleTimer == nil {
+		u.idleTimer = time.AfterFunc(timeout, u.stopIfIdle)
+	} else {
+		u.idleTimer.Reset(timeout)
+	}
+}
+
+// stopIfIdle stops the upstream if it has not been used for IdleTimeout
+func (u *upstream) stopIfIdle() {
+	u.startMu.Lock()
+	defer u.startMu.Unlock()
+
+	u.mu.Lock()
+	idle := u.state == StateConnected && !u.closing && u.inFlight == 0 &&
+		time.Since(u.lastUsed) >= u.spec.IdleTimeout
+	u.mu.Unlock()
+	if !idle {
+		return
+	}
+
+	ctx := logging.WithComponent(context.Background(), "upstream")
+	logger := logging.Default().WithField("upstream", u.spec.Name)
+	if err := u.stop(); err != nil {
+		logger.Error(ctx, err, "Failed to stop idle upstream")
+		return
+	}
+	logger.Info(ctx, "Stopped idle upstream")
+}
+
+// warmUp starts an idle upstream ahead of its first call
+func (u *upstream) warmUp(ctx context.Context) error {
+	if _, err := u.acquire(ctx); err != nil {
+		return err
+	}
+	u.release()
+	return nil
+}
+
+// newTransport creates the stdio or SSE transport of the spec
+func (u *upstream) newTransport() (client.Transport, error) {
+	if u.spec.Command != "" {
+		return u.startProcess()
+	}
+	return client.NewSSE(u.spec.URL, u.spec.Headers)
+}
+
+// startProcess starts a stdio upstream. Its stdin and stdout are pipes
+// owned by the upstream rather than the command, so waiting for the process
+// cannot close them under the transport, whose reader stops at EOF. Stderr
+// is logged, as is non-JSON output on stdout, which also stops a strict
+// upstream.
+func (u *upstream) startProcess() (client.Transport, error) {
+	cmd := exec.Command(u.spec.Command, u.spec.Args...)
+	cmd.Dir = u.spec.Dir
+	cmd.Env = append(os.Environ(), u.spec.Env...)
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdinR, stdin, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, stdoutW, err := os.Pipe()
+	if err != nil {
+		stdinR.Close()
+		stdin.Close()
+		return nil, err
+	}
+	cmd.Stdin, cmd.Stdout = stdinR, stdoutW
+	err = cmd.Start()
+	stdinR.Close()
+	stdoutW.Close()
+	if err != nil {
+		stdin.Close()
+		stdout.Close()
+		return nil, err
+	}
+
+	exited := make(chan struct{})
+	u.mu.Lock()
+	u.process, u.exited = cmd, exited
+	u.mu.Unlock()
+	logger := logging.Default().WithField("upstream", u.spec.Name)
+	ctx := logging.WithComponent(context.Background(), "upstream")
+	var polluted atomic.Pointer[string]
+	filter := newStdoutFilter(closeOnEOF{stdout}, u.spec.StrictStdout, func(line []byte) {
+		u.mu.Lock()
+		u.noiseLines++
+		u.mu.Unlock()
+		if !u.spec.StrictStdout {
+			logger.WithField("pid", cmd.Process.Pid).Warn(ctx, "Skipped non-JSON output on stdout: "+quoteNoise(line))
+			return
+		}
+		reason := "wrote non-JSON output to stdout: " + quoteNoise(line)
+		polluted.Store(&reason)
+		logger.WithField("pid", cmd.Process.Pid).Error(ctx, errors.New(reason), "Stopping strict upstream")
+		cmd.Process.Kill()
+	})
+	go func() {
+		// Stderr must be drained before Wait closes it
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.WithField("pid", cmd.Process.Pid).Info(ctx, scanner.Text())
+		}
+		err := cmd.Wait()
+		reason := exitReason(err)
+		if p := polluted.Load(); p != nil {
+			reason = *p
+		}
+		u.mu.Lock()
+		u.lastError = reason
+		u.mu.Unlock()
+		close(exited)
+	}()
+
+	return client.NewIO(filter, stdin), nil
+}
+
+// withExit returns a context that is also cancelled when a stdio
+// upstream's process exits, since the transport leaves requests in flight
+// waiting for their deadline
+func (u *upstream) withExit(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	u.mu.Lock()
+	exited := u.exited
+	u.mu.Unlock()
+	if exited != nil {
+		go func() {
+			select {
+			case <-exited:
+				cancel(errProcessExited)
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, func() { cancel(nil) }
+}
+
+// supervise marks a stdio upstream disconnected when its process exits,
+// unless it was stopped
+func (u *upstream) supervise(exited chan struct{}) {
+	<-exited
+
+	u.mu.Lock()
+	if u.closing || u.exited != exited {
+		u.mu.Unlock()
+		return
+	}
+	u.state = StateDisconnected
+	names, reason := u.tools, u.lastError
+	u.mu.Unlock()
+
+	u.manager.disableTools(names)
+	u.publish(StateDisconnected, reason)
+	logging.Default().WithField("upstream", u.spec.Name).
+		Error(logging.WithComponent(context.Background(), "upstream"), errors.New(reason), "Upstream process exited; tools disabled")
+}
+
+// close disconnects the upstream and unregisters its tools
+func (u *upstream) close() error {
+	u.mu.Lock()
+	u.closing = true
+	u.state = StateDisconnected
+	names := u.tools
+	u.tools = nil
+	c, process, exited := u.client, u.process, u.exited
+	if u.idleTimer != nil {
+		u.idleTimer.Stop()
+	}
+	u.mu.Unlock()
+
+	u.manager.unregisterTools(u, names)
+	u.publish(StateDisconnected, "")
+	var err error
+	if c != nil {
+		err = c.Close()
+	}
+	waitProcess(process, exited)
+	return err
+}
+
+// publish announces a state change of the upstream on the event bus
+func (u *upstream) publish(state State, reason string) {
+	events.Publish(events.Or(u.manager.config.Events), events.UpstreamHealth, events.UpstreamHealthEvent{
+		Upstream: u.spec.Name,
+		State:    string(state),
+		Error:    reason,
+		At:       time.Now(),
+	})
+}
+
+// waitProcess waits for a stdio upstream whose stdin was closed to exit,
+// killing it after stopTimeout
+func waitProcess(process *exec.Cmd, exited chan struct{}) {
+	if process == nil {
+		return
+	}
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		process.Process.Kill()
+		<-exited
+	}
+}
+
+// status describes the upstream
+func (u *upstream) status() Status {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	name := "sse"
+	if u.spec.Command != "" {
+		name = "stdio"
+	}
+	return Status{
+		Name:            u.spec.Name,
+		Transport:       name,
+		State:           u.state,
+		ServerName:      u.server.Name,
+		ServerVersion:   u.server.Version,
+		ProtocolVersion: u.protocolVersion,
+		Tools:           append([]string{}, u.tools...),
+		Overridden:      u.spec.overridden(u.tools),
+		ConnectedAt:     u.connectedAt,
+		LastError:       u.lastError,
+		NoiseLines:      u.noiseLines,
+		Notifications:   NotificationStats{Forwarded: u.forwarded, Dropped: u.dropped},
+	}
+}
+
+// syncTools registers the upstream's current tools, updating those it
+// already owns and unregistering those no longer provided
+func (u *upstream) syncTools(ctx context.Context) error {
+	u.syncMu.Lock()
+	defer u.syncMu.Unlock()
+
+	u.mu.Lock()
+	c := u.client
+	u.mu.Unlock()
+	if c == nil || u.spec.Hidden {
+		// Stopped; the list is refreshed when the upstream next starts.
+		// Hidden upstreams publish nothing.
+		return nil
+	}
+	upstreamTools, err := c.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("list tools: %w", err)
+	}
+
+	u.mu.Lock()
+	if u.closing {
+		u.mu.Unlock()
+		return nil
+	}
+	owned, version := u.tools, u.server.Version
+	u.mu.Unlock()
+
+	defs := make([]tools.Definition, len(upstreamTools))
+	for i, tool := range upstreamTools {
+		upstreamName := tool.Name
+		tool = hideArguments(tool, argumentsFor(u.arguments, upstreamName))
+		tool.Name = u.spec.toolName(upstreamName)
+		defs[i] = tools.Definition{
+			Tool:    tool,
+			Handler: u.handler(upstreamName),
+			Version: version,
+			Tags:    append([]string{"upstream", "upstream:" + u.spec.Name}, u.spec.Tags...),
+			Source:  u.spec.Name,
+
+			ConcurrencyGroup: u.spec.groupOf(upstreamName),
+			DryRun:           u.spec.dryRun(upstreamName),
+		}
+	}
+
+	names, err := u.manager.syncTools(u, owned, defs)
+	u.mu.Lock()
+	u.tools = names
+	u.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("upstream %s: %w", u.spec.Name, err)
+	}
+	return nil
+}
+
+// handler forwards calls of a tool to the upstream, mirroring them to the
+// shadow upstream if the spec names one
+func (u *upstream) handler(name string) server.ToolHandlerFunc {
+	local := u.spec.toolName(name)
+	arguments := argumentsFor(u.arguments, name)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		request, err := arguments.apply(ctx, u.manager, u.spec.Name, name, request)
+		if err != nil {
+			return nil, mcperrors.NewToolError(local, err)
+		}
+
+		var primary chan<- shadowOutcome
+		if u.spec.Shadow != "" {
+			primary = u.manager.mirror(ctx, u.spec, name, request)
+		}
+
+		result, err := u.call(ctx, name, request)
+		if primary != nil {
+			primary <- newShadowOutcome(result, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := u.manager.transform(ctx, u.source(name, local), result); err != nil {
+			return nil, mcperrors.NewToolError(local, err)
+		}
+		return result, nil
+	}
+}
+
+// call invokes the upstream tool name, normalizing errors
+func (u *upstream) call(ctx context.Context, name string, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c, err := u.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer u.release()
+
+	// The client's deadline applies when it is sooner than ours
+	timeout := u.manager.config.CallTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx, cancelExit := u.withExit(ctx)
+	defer cancelExit()
+
+	request.Params.Name = name
+	meta, done := u.trackProgress(ctx, withTimeoutHint(ctx, request.Params.Meta))
+	defer done()
+	request.Params.Meta = meta
+	result, err := c.CallTool(ctx, request)
+	switch {
+	case err == nil:
+		return result, nil
+	case errors.Is(context.Cause(ctx), errProcessExited):
+		return nil, mcperrors.NewServiceUnavailableError("upstream "+u.spec.Name, "process exited")
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, mcperrors.NewTransportTimeoutError("upstream "+u.spec.Name+" tools/call", timeout.String())
+	default:
+		return nil, u.manager.config.Translator.TranslateError(u.spec.Name, err)
+	}
+}
+
+// source describes a call of the upstream tool name
+func (u *upstream) source(name, local string) Source {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return Source{Upstream: u.spec.Name, Tool: name, LocalName: local, Server: u.server}
+}
+
+// withTimeoutHint returns a copy of meta telling the upstream how long the
+// call may take, so it stops working once we stop waiting
+func withTimeoutHint(ctx context.Context, meta *mcp.Meta) *mcp.Meta {
+	remaining, ok := router.RemainingTimeoutHint(ctx)
+	if !ok {
+		return meta
+	}
+	hinted := &mcp.Meta{AdditionalFields: map[string]any{}}
+	if meta != nil {
+		hinted.ProgressToken = meta.ProgressToken
+		for key, value := range meta.AdditionalFields {
+			hinted.AdditionalFields[key] = value
+		}
+	}
+	hinted.AdditionalFields[router.TimeoutHintKey] = remaining
+	return hinted
+}
+
+// closeOnEOF closes a pipe once it has been read to the end
+type closeOnEOF struct {
+	*os.File
+}
+
+// Read implements io.Reader
+func (r closeOnEOF) Read(p []byte) (int, error) {
+	n, err := r.File.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.File.Close()
+	}
+	return n, err
+}
+
+// exitReason describes how a process ended
+func exitReason(err error) string {
+	if err == nil {
+		return "exited with status 0"
+	}
+	return err.Error()
+}
@@ -0,0 +1,69 @@
+package upstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/client"
+)
+
+// InitializeOverrides change what this server presents to an upstream in
+// the initialize handshake, e.g. for an upstream that behaves differently
+// depending on the client name. Empty fields keep the manager's defaults:
+//
+//	initialize:
+//	  clientName: claude-desktop
+//	  protocolVersion: "2024-11-05"
+//	  capabilities:
+//	    roots: {listChanged: true}
+type InitializeOverrides struct {
+	ClientName    string `yaml:"clientName,omitempty" json:"clientName,omitempty"`
+	ClientVersion string `yaml:"clientVersion,omitempty" json:"clientVersion,omitempty"`
+
+	// ProtocolVersion is proposed instead of the latest version
+	ProtocolVersion string `yaml:"protocolVersion,omitempty" json:"protocolVersion,omitempty"`
+
+	// Capabilities replace the advertised client capabilities, in their
+	// initialize form
+	Capabilities map[string]any `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
+}
+
+// identity returns the client configuration presenting this server to the
+// upstream of spec
+func (m *Manager) identity(spec Spec) (client.Config, error) {
+	config := client.Config{Name: m.config.ClientName, Version: m.config.ClientVersion}
+	overrides := spec.Initialize
+	if overrides == nil {
+		return config, nil
+	}
+
+	if overrides.ClientName != "" {
+		config.Name = overrides.ClientName
+	}
+	if overrides.ClientVersion != "" {
+		config.Version = overrides.ClientVersion
+	}
+	if version := overrides.ProtocolVersion; version != "" {
+		if !slices.Contains(mcp.ValidProtocolVersions, version) {
+			return config, fmt.Errorf("initialize: unknown protocol version %q (use %s)", version, strings.Join(mcp.ValidProtocolVersions, ", "))
+		}
+		config.ProtocolVersion = version
+	}
+	if overrides.Capabilities != nil {
+		data, err := json.Marshal(overrides.Capabilities)
+		if err != nil {
+			return config, fmt.Errorf("initialize: capabilities: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&config.Capabilities); err != nil {
+			return config, fmt.Errorf("initialize: capabilities: %w", err)
+		}
+	}
+	return config, nil
+}
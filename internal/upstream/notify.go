@@ -0,0 +1,336 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// NotificationAction is what happens to an upstream notification
+type NotificationAction string
+
+// Notification actions
+const (
+	// Forward passes the notification on: progress to the client whose
+	// call it reports on, tools/list_changed by refreshing the upstream's
+	// tools, anything else to every client
+	Forward NotificationAction = "forward"
+
+	// Drop discards the notification
+	Drop NotificationAction = "drop"
+
+	// Limit forwards at most Rate notifications per second after a Burst,
+	// dropping the rest
+	Limit NotificationAction = "limit"
+)
+
+// NotificationRule decides what happens to the notifications of an
+// upstream whose method it matches. Rules are tried in order and the first
+// match applies; notifications no rule matches are dropped.
+type NotificationRule struct {
+	// Method selects notifications by method; a trailing "*" matches any
+	// suffix and an empty Method matches every notification
+	Method string `yaml:"method,omitempty" json:"method,omitempty"`
+
+	Action NotificationAction `yaml:"action" json:"action"`
+
+	// Rate and Burst bound the Limit action, in notifications per second
+	// (Burst defaults to Rate, rounded up)
+	Rate  float64 `yaml:"rate,omitempty" json:"rate,omitempty"`
+	Burst int     `yaml:"burst,omitempty" json:"burst,omitempty"`
+
+	// MinLevel drops notifications/message below this level
+	MinLevel mcp.LoggingLevel `yaml:"minLevel,omitempty" json:"minLevel,omitempty"`
+
+	// Transform rewrites the notifications that are forwarded
+	Transform *NotificationTransform `yaml:"transform,omitempty" json:"transform,omitempty"`
+}
+
+// NotificationTransform rewrites a forwarded notification
+type NotificationTransform struct {
+	// Logger replaces the logger of notifications/message; "{upstream}"
+	// and "{logger}" expand to the upstream name and the original logger
+	Logger string `yaml:"logger,omitempty" json:"logger,omitempty"`
+
+	// Level replaces the level of notifications/message
+	Level mcp.LoggingLevel `yaml:"level,omitempty" json:"level,omitempty"`
+
+	// Meta is added to the notification's _meta, e.g. to tag its source
+	Meta map[string]any `yaml:"meta,omitempty" json:"meta,omitempty"`
+}
+
+// DefaultNotificationRules forward progress and tool list changes, and
+// log messages from info up at a bounded rate. Other notifications, e.g.
+// about resources and prompts the server does not proxy, are dropped.
+func DefaultNotificationRules() []NotificationRule {
+	return []NotificationRule{
+		{Method: protocolmcp.MethodNotificationProgress, Action: Forward},
+		{Method: mcp.MethodNotificationToolsListChanged, Action: Forward},
+		{Method: protocolmcp.MethodNotificationMessage, Action: Limit, Rate: 5, Burst: 20, MinLevel: mcp.LoggingLevelInfo,
+			Transform: &NotificationTransform{Logger: "{upstream}/{logger}"}},
+	}
+}
+
+// notificationRule is a NotificationRule with its rate limit state
+type notificationRule struct {
+	NotificationRule
+	bucket *eventBucket
+}
+
+// compileNotifications checks the notification rules of spec, followed by
+// those of config and the defaults
+func compileNotifications(spec Spec, config Config, c clock.Clock) ([]*notificationRule, error) {
+	rules := append(append(append([]NotificationRule{}, spec.Notifications...), config.Notifications...), DefaultNotificationRules()...)
+	compiled := make([]*notificationRule, len(rules))
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("notification rule %d (%s): %w", i+1, rule.Method, err)
+		}
+		compiled[i] = &notificationRule{NotificationRule: rule}
+		if rule.Action == Limit {
+			compiled[i].bucket = newEventBucket(rule.Rate, rule.Burst, c)
+		}
+	}
+	return compiled, nil
+}
+
+// validate checks the action, rate and levels of r
+func (r NotificationRule) validate() error {
+	switch r.Action {
+	case Forward, Drop:
+	case Limit:
+		if r.Rate <= 0 {
+			return errors.New("limit requires a positive rate")
+		}
+	default:
+		return fmt.Errorf("unknown action %q (use forward, drop or limit)", r.Action)
+	}
+	if r.MinLevel != "" && !r.MinLevel.ShouldSendTo(mcp.LoggingLevelDebug) {
+		return fmt.Errorf("unknown level %q", r.MinLevel)
+	}
+	if r.Transform != nil && r.Transform.Level != "" && !r.Transform.Level.ShouldSendTo(mcp.LoggingLevelDebug) {
+		return fmt.Errorf("unknown level %q", r.Transform.Level)
+	}
+	return nil
+}
+
+// matches reports whether r applies to method
+func (r *notificationRule) matches(method string) bool {
+	if prefix, ok := strings.CutSuffix(r.Method, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return r.Method == "" || r.Method == method
+}
+
+// admit reports whether a notification passes r
+func (r *notificationRule) admit(notification mcp.JSONRPCNotification) bool {
+	if r.Action == Drop {
+		return false
+	}
+	if r.MinLevel != "" && notification.Method == protocolmcp.MethodNotificationMessage {
+		level, _ := notification.Params.AdditionalFields["level"].(string)
+		if !mcp.LoggingLevel(level).ShouldSendTo(r.MinLevel) {
+			return false
+		}
+	}
+	return r.bucket.allow()
+}
+
+// transform returns the params of notification rewritten by r, for
+// forwarding
+func (r *notificationRule) transform(upstream string, notification mcp.JSONRPCNotification) map[string]any {
+	params := make(map[string]any, len(notification.Params.AdditionalFields)+1)
+	for key, value := range notification.Params.AdditionalFields {
+		params[key] = value
+	}
+	meta := make(map[string]any, len(notification.Params.Meta))
+	for key, value := range notification.Params.Meta {
+		meta[key] = value
+	}
+
+	if t := r.Transform; t != nil {
+		if notification.Method == protocolmcp.MethodNotificationMessage {
+			if t.Logger != "" {
+				logger, _ := params["logger"].(string)
+				if logger == "" {
+					logger = "-"
+				}
+				params["logger"] = strings.NewReplacer("{upstream}", upstream, "{logger}", logger).Replace(t.Logger)
+			}
+			if t.Level != "" {
+				params["level"] = t.Level
+			}
+		}
+		for key, value := range t.Meta {
+			meta[key] = value
+		}
+	}
+	if len(meta) > 0 {
+		params["_meta"] = meta
+	}
+	return params
+}
+
+// eventBucket is a token bucket admitting events at a rate
+type eventBucket struct {
+	rate  float64
+	burst float64
+	clock clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newEventBucket returns a full bucket
+func newEventBucket(rate float64, burst int, c clock.Clock) *eventBucket {
+	b := float64(burst)
+	if burst <= 0 {
+		b = max(1, float64(int(rate+0.999)))
+	}
+	return &eventBucket{rate: rate, burst: b, clock: c, tokens: b, last: c.Now()}
+}
+
+// allow takes a token if one is left. A nil bucket always allows.
+func (b *eventBucket) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// progressTarget is the call a progress token of the upstream reports on
+type progressTarget struct {
+	ctx   context.Context
+	token mcp.ProgressToken
+}
+
+// trackProgress returns meta with the progress token of the call of ctx
+// replaced by one unique to the upstream, so progress of calls from
+// different clients using the same token reaches the right one. done stops
+// forwarding the call's progress.
+func (u *upstream) trackProgress(ctx context.Context, meta *mcp.Meta) (tracked *mcp.Meta, done func()) {
+	if meta == nil || meta.ProgressToken == nil {
+		return meta, func() {}
+	}
+	token := uuid.NewString()
+	u.progressMu.Lock()
+	if u.progress == nil {
+		u.progress = make(map[string]progressTarget)
+	}
+	u.progress[token] = progressTarget{ctx: ctx, token: meta.ProgressToken}
+	u.progressMu.Unlock()
+
+	tracked = &mcp.Meta{ProgressToken: token, AdditionalFields: meta.AdditionalFields}
+	return tracked, func() {
+		u.progressMu.Lock()
+		delete(u.progress, token)
+		u.progressMu.Unlock()
+	}
+}
+
+// onNotification applies the notification rules to a notification of the
+// upstream and forwards it if they let it through
+func (u *upstream) onNotification(notification mcp.JSONRPCNotification) {
+	var rule *notificationRule
+	for _, r := range u.notifications {
+		if r.matches(notification.Method) {
+			rule = r
+			break
+		}
+	}
+	if rule == nil || !rule.admit(notification) || !u.forward(rule, notification) {
+		u.count(false)
+		return
+	}
+	u.count(true)
+}
+
+// count records a forwarded or dropped notification
+func (u *upstream) count(forwarded bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if forwarded {
+		u.forwarded++
+	} else {
+		u.dropped++
+	}
+}
+
+// forward passes an admitted notification on, reporting whether it went
+// anywhere
+func (u *upstream) forward(rule *notificationRule, notification mcp.JSONRPCNotification) bool {
+	config := u.manager.config
+	switch notification.Method {
+	case mcp.MethodNotificationToolsListChanged:
+		u.refreshTools()
+		return true
+
+	case protocolmcp.MethodNotificationProgress:
+		token, _ := notification.Params.AdditionalFields["progressToken"].(string)
+		u.progressMu.Lock()
+		target, ok := u.progress[token]
+		u.progressMu.Unlock()
+		if !ok {
+			return false
+		}
+		params := rule.transform(u.spec.Name, notification)
+		params["progressToken"] = target.token
+		if err := config.Notify(target.ctx, notification.Method, params); err != nil {
+			logging.Default().WithField("upstream", u.spec.Name).
+				Debug(logging.WithComponent(target.ctx, "upstream"), "Dropping progress: "+err.Error())
+			return false
+		}
+		return true
+
+	default:
+		if config.Broadcast == nil {
+			return false
+		}
+		config.Broadcast(notification.Method, rule.transform(u.spec.Name, notification))
+		return true
+	}
+}
+
+// refreshTools resynchronizes tools in the background when the upstream's
+// list changes
+func (u *upstream) refreshTools() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), u.manager.config.CallTimeout)
+		defer cancel()
+		if err := u.syncTools(ctx); err != nil {
+			logging.Default().WithField("upstream", u.spec.Name).
+				Error(logging.WithComponent(ctx, "upstream"), err, "Failed to refresh upstream tools")
+		}
+	}()
+}
+
+// notifyClient sends a notification to the client of ctx through the
+// mcp-go server handling its request
+func notifyClient(ctx context.Context, method string, params map[string]any) error {
+	s := server.ServerFromContext(ctx)
+	if s == nil {
+		return errors.New("no client to notify")
+	}
+	return s.SendNotificationToClient(ctx, method, params)
+}
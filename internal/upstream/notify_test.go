@@ -0,0 +1,127 @@
+package upstream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+func logMessage(level mcp.LoggingLevel, logger string) mcp.JSONRPCNotification {
+	return mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: "notifications/message", Params: mcp.NotificationParams{
+			AdditionalFields: map[string]any{"level": string(level), "logger": logger, "data": "hello"},
+		}},
+	}
+}
+
+func TestNotificationRules(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	spec := Spec{Notifications: []NotificationRule{
+		{Method: "notifications/resources/*", Action: Drop},
+		{Method: "notifications/message", Action: Limit, Rate: 1, Burst: 2, MinLevel: mcp.LoggingLevelWarning,
+			Transform: &NotificationTransform{Logger: "{upstream}:{logger}", Meta: map[string]any{"upstream": "fs"}}},
+	}}
+	rules, err := compileNotifications(spec, Config{}, fake)
+	require.NoError(t, err)
+	match := func(method string) *notificationRule {
+		for _, r := range rules {
+			if r.matches(method) {
+				return r
+			}
+		}
+		return nil
+	}
+
+	assert.Equal(t, Drop, match("notifications/resources/updated").Action)
+	assert.Equal(t, Forward, match("notifications/progress").Action, "defaults follow the configured rules")
+	assert.Nil(t, match("notifications/custom"))
+
+	rule := match("notifications/message")
+	assert.False(t, rule.admit(logMessage(mcp.LoggingLevelInfo, "x")), "below the minimum level")
+	assert.True(t, rule.admit(logMessage(mcp.LoggingLevelError, "x")))
+	assert.True(t, rule.admit(logMessage(mcp.LoggingLevelError, "x")))
+	assert.False(t, rule.admit(logMessage(mcp.LoggingLevelError, "x")), "burst exhausted")
+	fake.Advance(time.Second)
+	assert.True(t, rule.admit(logMessage(mcp.LoggingLevelError, "x")))
+
+	params := rule.transform("fs", logMessage(mcp.LoggingLevelError, "watcher"))
+	assert.Equal(t, "fs:watcher", params["logger"])
+	assert.Equal(t, "hello", params["data"])
+	assert.Equal(t, map[string]any{"upstream": "fs"}, params["_meta"])
+
+	for _, bad := range []NotificationRule{
+		{Method: "x", Action: "mute"},
+		{Method: "x", Action: Limit},
+		{Method: "x", Action: Forward, MinLevel: "loud"},
+	} {
+		_, err := compileNotifications(Spec{Notifications: []NotificationRule{bad}}, Config{}, fake)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestManager_ForwardsNotifications(t *testing.T) {
+	s := server.NewMCPServer("chatty", "1.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		srv := server.ServerFromContext(ctx)
+		token := request.Params.Meta.ProgressToken
+		srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progressToken": token, "progress": 1, "total": 2})
+		srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{"level": "debug", "logger": "w", "data": "noise"})
+		srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{"level": "error", "logger": "w", "data": "failed"})
+		srv.SendNotificationToClient(ctx, "notifications/resources/list_changed", nil)
+		return mcp.NewToolResultText("done"), nil
+	})
+	httpServer := server.NewTestServer(s)
+	defer httpServer.Close()
+
+	type sent struct {
+		method string
+		params map[string]any
+	}
+	var mu sync.Mutex
+	var progress, broadcast []sent
+	registry := tools.New(tools.Config{})
+	manager := New(Config{
+		Registry: registry,
+		Notify: func(ctx context.Context, method string, params map[string]any) error {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, sent{method, params})
+			return nil
+		},
+		Broadcast: func(method string, params map[string]any) {
+			mu.Lock()
+			defer mu.Unlock()
+			broadcast = append(broadcast, sent{method, params})
+		},
+	})
+	defer manager.Shutdown(context.Background())
+	require.NoError(t, manager.Add(context.Background(), Spec{Name: "chatty", URL: httpServer.URL + "/sse"}))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "chatty_work"
+	request.Params.Meta = &mcp.Meta{ProgressToken: 7}
+	_, err := registry.Call(context.Background(), request)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return manager.Status()[0].Notifications == NotificationStats{Forwarded: 2, Dropped: 2}
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, progress, 1)
+	assert.Equal(t, mcp.ProgressToken(7), progress[0].params["progressToken"], "the client's own token is restored")
+	require.Len(t, broadcast, 1)
+	assert.Equal(t, "notifications/message", broadcast[0].method)
+	assert.Equal(t, "chatty/w", broadcast[0].params["logger"])
+	assert.Equal(t, "failed", broadcast[0].params["data"])
+}
@@ -0,0 +1,39 @@
+package upstream
+
+import (
+	"slices"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// overrides reports whether a local tool acknowledged in Overrides replaces
+// the tool of the upstream published as local
+func (s Spec) overrides(local string) bool {
+	return slices.ContainsFunc(s.Overrides, func(name string) bool {
+		return s.toolName(name) == local
+	})
+}
+
+// overridden returns the local names of the tools in names replaced by
+// local tools
+func (s Spec) overridden(names []string) []string {
+	var overridden []string
+	for _, name := range names {
+		if s.overrides(name) {
+			overridden = append(overridden, name)
+		}
+	}
+	return overridden
+}
+
+// Overridden returns a handler calling the upstream tool replaced by the
+// local tool name, so the local tool can wrap it, e.g. validating arguments
+// before passing the call on:
+//
+//	registry.Register(tools.Definition{Tool: writeFile, Handler: validate(manager.Overridden("fs_write_file"))})
+//
+// The upstream must list the tool in its Spec.Overrides. Calls fail with
+// tool not found while no upstream serves it.
+func (m *Manager) Overridden(name string) server.ToolHandlerFunc {
+	return m.balance(name)
+}
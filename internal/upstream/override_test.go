@@ -0,0 +1,58 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+func TestManager_Overrides(t *testing.T) {
+	httpServer := server.NewTestServer(newHelperServer())
+	defer httpServer.Close()
+
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry})
+	defer manager.Shutdown(context.Background())
+
+	// The local tool wraps the upstream one, shouting its messages
+	upstreamEcho := manager.Overridden("remote_echo")
+	registry.MustRegister(tools.Definition{
+		Tool: mcp.NewTool("remote_echo", mcp.WithString("message")),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			request.Params.Arguments = map[string]any{"message": request.GetString("message", "") + "!"}
+			return upstreamEcho(ctx, request)
+		},
+	})
+	registry.MustRegister(tools.Definition{Tool: mcp.NewTool("other_fail"), Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("local"), nil
+	}})
+
+	spec := Spec{Name: "remote", URL: httpServer.URL + "/sse", Overrides: []string{"echo"}}
+	require.NoError(t, manager.Add(context.Background(), spec))
+	result, err := call(registry, "remote_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi!", result.Content[0].(mcp.TextContent).Text)
+	info, _ := registry.Get("remote_echo")
+	assert.NotContains(t, info.Tags, "upstream", "the local tool stays published")
+	status := manager.Status()[0]
+	assert.Contains(t, status.Tools, "remote_fail")
+	assert.Equal(t, []string{"remote_echo"}, status.Overridden)
+
+	// Without acknowledgment a clash fails the upstream tool
+	err = manager.Add(context.Background(), Spec{Name: "other", URL: httpServer.URL + "/sse"})
+	assert.ErrorIs(t, err, tools.ErrToolExists)
+	assert.ErrorContains(t, err, "overrides")
+
+	// Removing the upstream leaves the local tool, which no longer reaches it
+	require.NoError(t, manager.Remove("remote"))
+	_, ok := registry.Get("remote_echo")
+	assert.True(t, ok)
+	_, err = call(registry, "remote_echo", map[string]any{"message": "hi"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,351 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// Strategy selects the replica serving a call when several upstreams
+// publish a tool under the same name, e.g. by sharing a Prefix
+type Strategy string
+
+// Balancing strategies
+const (
+	// RoundRobin rotates through the replicas
+	RoundRobin Strategy = "round-robin"
+
+	// LeastInFlight picks the replica with the fewest calls in progress
+	LeastInFlight Strategy = "least-in-flight"
+
+	// Weighted rotates through the replicas in proportion to their Weight
+	Weighted Strategy = "weighted"
+)
+
+// valid reports whether s is a known strategy
+func (s Strategy) valid() bool {
+	switch s {
+	case RoundRobin, LeastInFlight, Weighted:
+		return true
+	}
+	return false
+}
+
+// Keys of a tools/call _meta overriding balancing for that call
+const (
+	// UpstreamKey pins the call to the named replica
+	UpstreamKey = "upstream"
+
+	// BalanceKey selects the Strategy of the call
+	BalanceKey = "balance"
+)
+
+// ejectTime is how long a replica is skipped after it failed to serve a
+// call, unless every replica has failed
+const ejectTime = 10 * time.Second
+
+// replica is an upstream serving a pooled tool
+type replica struct {
+	upstream *upstream
+	handler  server.ToolHandlerFunc
+
+	// current is the smooth weighted round-robin state
+	current int
+
+	// ejectedUntil is set when the replica failed to serve a call
+	ejectedUntil time.Time
+}
+
+// available reports whether the replica can serve calls: connected, or
+// idle and started on demand
+func (r *replica) available() bool {
+	r.upstream.mu.Lock()
+	defer r.upstream.mu.Unlock()
+	return r.upstream.state != StateDisconnected && !r.upstream.closing
+}
+
+// pool holds the replicas publishing one tool
+type pool struct {
+	strategy Strategy
+
+	// overridden is set when a local tool replaces the pooled one, which
+	// is then never registered
+	overridden bool
+
+	replicas []*replica
+	next     int
+}
+
+// join adds u serving the tool with handler unless it is a replica
+// already
+func (p *pool) join(u *upstream, handler server.ToolHandlerFunc) {
+	for _, r := range p.replicas {
+		if r.upstream == u {
+			return
+		}
+	}
+	p.replicas = append(p.replicas, &replica{upstream: u, handler: handler})
+}
+
+// leave removes u from the pool
+func (p *pool) leave(u *upstream) {
+	for i, r := range p.replicas {
+		if r.upstream == u {
+			p.replicas = append(p.replicas[:i], p.replicas[i+1:]...)
+			return
+		}
+	}
+}
+
+// available reports whether any replica is connected or idle
+func (p *pool) available() bool {
+	for _, r := range p.replicas {
+		if r.available() {
+			return true
+		}
+	}
+	return false
+}
+
+// pick selects a replica with strategy among the healthy ones. Ejected
+// replicas are only used when no other is left.
+func (p *pool) pick(strategy Strategy) *replica {
+	now := time.Now()
+	var candidates, ejected []*replica
+	for _, r := range p.replicas {
+		switch {
+		case !r.available():
+		case now.Before(r.ejectedUntil):
+			ejected = append(ejected, r)
+		default:
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = ejected
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	start := p.next % len(candidates)
+	p.next++
+	switch strategy {
+	case LeastInFlight:
+		var best *replica
+		bestInFlight := 0
+		for i := range candidates {
+			r := candidates[(start+i)%len(candidates)]
+			r.upstream.mu.Lock()
+			inFlight := r.upstream.inFlight
+			r.upstream.mu.Unlock()
+			if best == nil || inFlight < bestInFlight {
+				best, bestInFlight = r, inFlight
+			}
+		}
+		return best
+	case Weighted:
+		var best *replica
+		total := 0
+		for _, r := range candidates {
+			weight := r.upstream.spec.Weight
+			r.current += weight
+			total += weight
+			if best == nil || r.current > best.current {
+				best = r
+			}
+		}
+		best.current -= total
+		return best
+	default:
+		return candidates[start]
+	}
+}
+
+// balance returns the registry handler of a pooled tool, dispatching each
+// call to a replica
+func (m *Manager) balance(name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r, err := m.pick(name, request.Params.Meta)
+		if err != nil {
+			return nil, err
+		}
+		request.Params.Meta = withoutBalanceKeys(request.Params.Meta)
+		result, err := r.handler(ctx, request)
+
+		var mcpErr *mcperrors.MCPError
+		if errors.As(err, &mcpErr) && (mcpErr.Code == mcperrors.ErrorCodeMCPServiceUnavail ||
+			mcpErr.Code == mcperrors.ErrorCodeMCPTransportTimeout) {
+			m.poolMu.Lock()
+			r.ejectedUntil = time.Now().Add(ejectTime)
+			m.poolMu.Unlock()
+		}
+		return result, err
+	}
+}
+
+// pick selects the replica serving a call of the pooled tool name,
+// honoring the overrides in meta
+func (m *Manager) pick(name string, meta *mcp.Meta) (*replica, error) {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+
+	p := m.pools[name]
+	if p == nil {
+		return nil, mcperrors.NewToolNotFoundError(name)
+	}
+
+	strategy := p.strategy
+	if meta != nil {
+		if pinned, ok := meta.AdditionalFields[UpstreamKey].(string); ok {
+			for _, r := range p.replicas {
+				if r.upstream.spec.Name == pinned {
+					return r, nil
+				}
+			}
+			return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+				fmt.Sprintf("upstream %s does not serve %s", pinned, name), nil)
+		}
+		if override, ok := meta.AdditionalFields[BalanceKey].(string); ok {
+			strategy = Strategy(override)
+			if !strategy.valid() {
+				return nil, mcperrors.NewMCPError(jsonrpc.ErrorCodeInvalidParams,
+					"unknown balance strategy: "+override, nil)
+			}
+		}
+	}
+
+	r := p.pick(strategy)
+	if r == nil {
+		return nil, mcperrors.NewServiceUnavailableError("tool "+name, "no upstream available")
+	}
+	return r, nil
+}
+
+// withoutBalanceKeys returns meta without the balancing overrides, which
+// are not meant for the upstream
+func withoutBalanceKeys(meta *mcp.Meta) *mcp.Meta {
+	if meta == nil {
+		return nil
+	}
+	_, pinned := meta.AdditionalFields[UpstreamKey]
+	_, balanced := meta.AdditionalFields[BalanceKey]
+	if !pinned && !balanced {
+		return meta
+	}
+	stripped := &mcp.Meta{ProgressToken: meta.ProgressToken, AdditionalFields: map[string]any{}}
+	for key, value := range meta.AdditionalFields {
+		if key != UpstreamKey && key != BalanceKey {
+			stripped.AdditionalFields[key] = value
+		}
+	}
+	return stripped
+}
+
+// syncTools makes u a replica of the tools in defs, registering those no
+// upstream provided yet, and withdraws it from tools in owned that are no
+// longer provided. It returns the names now owned.
+func (m *Manager) syncTools(u *upstream, owned []string, defs []tools.Definition) ([]string, error) {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+
+	registry := m.config.Registry
+	provided := make(map[string]bool, len(defs))
+	names := make([]string, 0, len(defs))
+	var errs []error
+	for _, def := range defs {
+		name := def.Tool.Name
+		handler := def.Handler
+		def.Handler = m.balance(name)
+
+		p := m.pools[name]
+		var err error
+		switch {
+		case p != nil && p.overridden:
+		case p != nil:
+			err = registry.Update(def)
+			if err == nil {
+				err = registry.Enable(name)
+			}
+		case u.spec.overrides(name):
+			if _, ok := registry.Get(name); !ok {
+				logging.Default().WithFields(logging.LogFields{"upstream": u.spec.Name, "tool": name}).
+					Warn(context.Background(), "No local tool overrides the upstream tool; it is not published")
+			}
+		default:
+			err = registry.Register(def)
+			if errors.Is(err, tools.ErrToolExists) {
+				err = fmt.Errorf("%w (list it in the upstream's overrides to let the local tool replace it)", err)
+			}
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if p == nil {
+			p = &pool{strategy: u.spec.Balance, overridden: u.spec.overrides(name)}
+			m.pools[name] = p
+		}
+		p.join(u, handler)
+		provided[name] = true
+		names = append(names, name)
+	}
+
+	for _, name := range owned {
+		if !provided[name] {
+			m.leave(u, name)
+		}
+	}
+	return names, errors.Join(errs...)
+}
+
+// leave withdraws u from the pool of a tool, unregistering the tool once
+// no replica is left and disabling it while none is available, unless a
+// local tool overrides it. It must be called with m.poolMu held.
+func (m *Manager) leave(u *upstream, name string) {
+	p := m.pools[name]
+	if p == nil {
+		return
+	}
+	p.leave(u)
+	switch {
+	case len(p.replicas) == 0:
+		delete(m.pools, name)
+		if !p.overridden {
+			m.config.Registry.Unregister(name)
+		}
+	case p.overridden:
+	case !p.available():
+		m.config.Registry.Disable(name)
+	}
+}
+
+// disableTools hides tools of an unavailable upstream unless another
+// replica still serves them
+func (m *Manager) disableTools(names []string) {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+	for _, name := range names {
+		if p := m.pools[name]; p == nil || !p.overridden && !p.available() {
+			m.config.Registry.Disable(name)
+		}
+	}
+}
+
+// unregisterTools withdraws a closed upstream from its tools
+func (m *Manager) unregisterTools(u *upstream, names []string) {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+	for _, name := range names {
+		m.leave(u, name)
+	}
+}
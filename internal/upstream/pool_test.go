@@ -0,0 +1,104 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// testPool returns a pool of connected upstreams with the given weights
+func testPool(weights ...int) *pool {
+	p := &pool{strategy: RoundRobin}
+	for i, weight := range weights {
+		u := &upstream{spec: Spec{Name: string(rune('a' + i)), Weight: weight}, state: StateConnected}
+		p.join(u, nil)
+	}
+	return p
+}
+
+// picks returns the names of n replicas picked with strategy
+func picks(p *pool, strategy Strategy, n int) string {
+	var names string
+	for range n {
+		names += p.pick(strategy).upstream.spec.Name
+	}
+	return names
+}
+
+func TestPool_Pick(t *testing.T) {
+	assert.Equal(t, "abcabc", picks(testPool(1, 1, 1), RoundRobin, 6))
+	assert.Equal(t, "aabaaaba", picks(testPool(3, 1), Weighted, 8))
+
+	p := testPool(1, 1, 1)
+	p.replicas[0].upstream.inFlight = 2
+	p.replicas[2].upstream.inFlight = 1
+	assert.Equal(t, "bbb", picks(p, LeastInFlight, 3))
+
+	// Unavailable replicas are skipped; ejected ones only as a last resort
+	p = testPool(1, 1, 1)
+	p.replicas[1].upstream.state = StateDisconnected
+	p.replicas[2].upstream.state = StateIdle
+	assert.Equal(t, "acac", picks(p, RoundRobin, 4))
+	p.replicas[0].ejectedUntil = time.Now().Add(time.Hour)
+	assert.Equal(t, "cc", picks(p, RoundRobin, 2))
+	p.replicas[2].ejectedUntil = time.Now().Add(time.Hour)
+	assert.Contains(t, []string{"a", "c"}, picks(p, RoundRobin, 1))
+	p.replicas[0].upstream.state = StateDisconnected
+	p.replicas[2].upstream.state = StateDisconnected
+	assert.Nil(t, p.pick(RoundRobin))
+	assert.False(t, p.available())
+}
+
+func TestManager_Replicas(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry, CallTimeout: 5 * time.Second})
+	defer manager.Shutdown(context.Background())
+
+	for _, name := range []string{"one", "two"} {
+		spec := helperSpec(t, name)
+		spec.Prefix = "pool_"
+		require.NoError(t, manager.Add(context.Background(), spec))
+	}
+	assert.Len(t, registry.List(""), 4)
+
+	callWith := func(tool string, meta map[string]any) (*mcp.CallToolResult, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		request.Params.Arguments = map[string]any{"message": "hi"}
+		if meta != nil {
+			request.Params.Meta = &mcp.Meta{AdditionalFields: meta}
+		}
+		return registry.Call(context.Background(), request)
+	}
+	_, err := callWith("pool_echo", map[string]any{BalanceKey: "random"})
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+	_, err = callWith("pool_echo", map[string]any{UpstreamKey: "three"})
+	assert.Equal(t, jsonrpc.ErrorCodeInvalidParams, mcperrors.FindMCPError(err).Code)
+
+	// A crashed replica is left out while the other keeps the tools up
+	callWith("pool_exit", map[string]any{UpstreamKey: "one"})
+	assert.Eventually(t, func() bool {
+		return manager.Status()[0].State == StateDisconnected
+	}, 5*time.Second, 10*time.Millisecond)
+	for range 3 {
+		result, err := callWith("pool_echo", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "hi", result.Content[0].(mcp.TextContent).Text)
+	}
+	info, _ := registry.Get("pool_echo")
+	assert.True(t, info.Enabled)
+
+	// The tools go with the last replica
+	require.NoError(t, manager.Remove("one"))
+	assert.Len(t, registry.List(""), 4)
+	require.NoError(t, manager.Remove("two"))
+	assert.Empty(t, registry.List(""))
+}
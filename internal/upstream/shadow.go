@@ -0,0 +1,166 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/logging"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// maxShadowCalls bounds the mirrored calls in flight; calls beyond it are
+// not mirrored, so a slow shadow cannot pile up work
+const maxShadowCalls = 64
+
+// maxShadowLogBytes bounds the results included in mismatch logs
+const maxShadowLogBytes = 1024
+
+// ShadowResult compares a call with its mirror on a shadow upstream
+type ShadowResult struct {
+	// Upstream served the call; Shadow received the mirror
+	Upstream string
+	Shadow   string
+
+	// Tool is the upstream name of the tool called on both
+	Tool string
+
+	// Match reports whether both returned the same content and error
+	// status, or failed with the same error code
+	Match bool
+
+	// Diff describes the first difference when they do not match
+	Diff string
+
+	// Latency and ShadowLatency are how long each call took
+	Latency       time.Duration
+	ShadowLatency time.Duration
+}
+
+// shadowOutcome is what a call returned, reduced to what is compared
+type shadowOutcome struct {
+	isError bool
+	content []json.RawMessage
+	err     string
+	code    int
+	done    time.Time
+}
+
+// newShadowOutcome records the result of a call. Metadata is ignored as it
+// legitimately differs between servers.
+func newShadowOutcome(result *mcp.CallToolResult, err error) shadowOutcome {
+	outcome := shadowOutcome{done: time.Now()}
+	if err != nil {
+		outcome.err = err.Error()
+		if mcpErr := mcperrors.FindMCPError(err); mcpErr != nil {
+			outcome.code = mcpErr.Code
+		}
+		return outcome
+	}
+	outcome.isError = result.IsError
+	for _, content := range result.Content {
+		data, _ := json.Marshal(content)
+		outcome.content = append(outcome.content, data)
+	}
+	return outcome
+}
+
+// mirror sends a copy of a call of the upstream tool name to the shadow
+// upstream of spec in the background. The returned channel takes the
+// outcome of the primary call, which the mirror is compared with; it is
+// nil when the call is not mirrored.
+func (m *Manager) mirror(ctx context.Context, spec Spec, name string, request mcp.CallToolRequest) chan<- shadowOutcome {
+	logger := logging.Default().WithField("upstream", spec.Name).WithField("shadow", spec.Shadow).WithField("tool", name)
+	ctx = logging.WithComponent(context.WithoutCancel(ctx), "upstream")
+
+	m.mu.Lock()
+	shadow := m.upstreams[spec.Shadow]
+	m.mu.Unlock()
+	if shadow == nil {
+		logger.Debug(ctx, "Shadow upstream not connected; call not mirrored")
+		return nil
+	}
+	select {
+	case m.shadowCalls <- struct{}{}:
+	default:
+		logger.Debug(ctx, "Too many shadow calls in flight; call not mirrored")
+		return nil
+	}
+
+	// The client only hears about the progress of the primary call
+	if meta := request.Params.Meta; meta != nil && meta.ProgressToken != nil {
+		request.Params.Meta = &mcp.Meta{AdditionalFields: meta.AdditionalFields}
+	}
+
+	primary := make(chan shadowOutcome, 1)
+	go func() {
+		defer func() { <-m.shadowCalls }()
+		start := time.Now()
+		mirrored := newShadowOutcome(shadow.call(ctx, name, request))
+		outcome := <-primary
+
+		result := compareShadow(outcome, mirrored)
+		result.Upstream, result.Shadow, result.Tool = spec.Name, spec.Shadow, name
+		result.Latency = outcome.done.Sub(start)
+		result.ShadowLatency = mirrored.done.Sub(start)
+
+		logger = logger.WithField("latency_ms", result.Latency.Milliseconds()).
+			WithField("shadow_latency_ms", result.ShadowLatency.Milliseconds())
+		if result.Match {
+			logger.Debug(ctx, "Shadow call matched")
+		} else {
+			logger.WithField("diff", result.Diff).
+				WithField("primary", outcome.summary()).
+				WithField("mirrored", mirrored.summary()).
+				Warn(ctx, "Shadow call differed")
+		}
+		if m.config.OnShadow != nil {
+			m.config.OnShadow(result)
+		}
+	}()
+	return primary
+}
+
+// compareShadow compares the primary outcome a with the shadow outcome b
+func compareShadow(a, b shadowOutcome) ShadowResult {
+	diff := func(format string, args ...any) ShadowResult {
+		return ShadowResult{Diff: fmt.Sprintf(format, args...)}
+	}
+	switch {
+	case a.err != "" || b.err != "":
+		// Messages name the upstream, so only codes are compared
+		if a.err != "" && b.err != "" && a.code == b.code {
+			return ShadowResult{Match: true}
+		}
+		return diff("error: %q vs %q", a.err, b.err)
+	case a.isError != b.isError:
+		return diff("isError: %v vs %v", a.isError, b.isError)
+	case len(a.content) != len(b.content):
+		return diff("content: %d vs %d items", len(a.content), len(b.content))
+	}
+	for i := range a.content {
+		if !bytes.Equal(a.content[i], b.content[i]) {
+			return diff("content[%d] differs", i)
+		}
+	}
+	return ShadowResult{Match: true}
+}
+
+// summary returns the outcome for logs, truncated to maxShadowLogBytes
+func (o shadowOutcome) summary() string {
+	if o.err != "" {
+		return "error: " + o.err
+	}
+	data, _ := json.Marshal(struct {
+		IsError bool              `json:"isError,omitempty"`
+		Content []json.RawMessage `json:"content"`
+	}{o.isError, o.content})
+	if len(data) > maxShadowLogBytes {
+		return string(data[:maxShadowLogBytes]) + "..."
+	}
+	return string(data)
+}
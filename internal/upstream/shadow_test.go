@@ -0,0 +1,100 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// newShoutingServer returns a replacement for the helper server whose echo
+// shouts
+func newShoutingServer() *server.MCPServer {
+	s := server.NewMCPServer("shouting", "3.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("message")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		message := request.GetString("message", "")
+		if message == "same" {
+			return mcp.NewToolResultText(message), nil
+		}
+		return mcp.NewToolResultText(strings.ToUpper(message)), nil
+	})
+	s.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("bang")
+	})
+	return s
+}
+
+func TestManager_Shadow(t *testing.T) {
+	primary := server.NewTestServer(newHelperServer())
+	defer primary.Close()
+	replacement := server.NewTestServer(newShoutingServer())
+	defer replacement.Close()
+
+	results := make(chan ShadowResult, 10)
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry, OnShadow: func(r ShadowResult) { results <- r }})
+	defer manager.Shutdown(context.Background())
+
+	require.NoError(t, manager.Add(context.Background(), Spec{Name: "next", URL: replacement.URL + "/sse", Hidden: true}))
+	require.NoError(t, manager.Add(context.Background(), Spec{Name: "main", URL: primary.URL + "/sse", Shadow: "next"}))
+	_, ok := registry.Get("next_echo")
+	assert.False(t, ok, "hidden upstreams publish no tools")
+
+	next := func() ShadowResult {
+		t.Helper()
+		select {
+		case r := <-results:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("no shadow result")
+			return ShadowResult{}
+		}
+	}
+
+	// Clients only ever see the primary's result
+	result, err := call(registry, "main_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Content[0].(mcp.TextContent).Text)
+	r := next()
+	assert.False(t, r.Match)
+	assert.Equal(t, "content[0] differs", r.Diff)
+	assert.Equal(t, ShadowResult{Upstream: "main", Shadow: "next", Tool: "echo"},
+		ShadowResult{Upstream: r.Upstream, Shadow: r.Shadow, Tool: r.Tool})
+
+	_, err = call(registry, "main_echo", map[string]any{"message": "same"})
+	require.NoError(t, err)
+	assert.True(t, next().Match)
+
+	// Both failing alike is a match
+	_, err = call(registry, "main_fail", nil)
+	assert.Error(t, err)
+	assert.True(t, next().Match)
+
+	// The shadow lacks grow
+	_, err = call(registry, "main_grow", nil)
+	require.NoError(t, err)
+	assert.Contains(t, next().Diff, "error")
+}
+
+func TestCompareShadow(t *testing.T) {
+	text := func(texts ...string) shadowOutcome {
+		result := &mcp.CallToolResult{}
+		for _, s := range texts {
+			result.Content = append(result.Content, mcp.NewTextContent(s))
+		}
+		return newShadowOutcome(result, nil)
+	}
+
+	assert.True(t, compareShadow(text("a", "b"), text("a", "b")).Match)
+	assert.Equal(t, "content: 2 vs 1 items", compareShadow(text("a", "b"), text("a")).Diff)
+	failed := newShadowOutcome(mcp.NewToolResultError("no"), nil)
+	assert.Equal(t, "isError: true vs false", compareShadow(failed, text("no")).Diff)
+}
@@ -0,0 +1,96 @@
+package upstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// maxNoiseReport bounds the bytes of a polluting line quoted in warnings
+const maxNoiseReport = 200
+
+// stdoutFilter passes the JSON-RPC messages of a stdio upstream's stdout
+// and reports the rest, e.g. banners or debug prints of the process. A
+// message preceded by noise on the same line, as when a print lacks its
+// newline, is recovered. In strict mode the first noise ends the stream
+// instead, closing the pipe.
+type stdoutFilter struct {
+	r      *bufio.Reader
+	closer io.Closer
+	strict bool
+	noise  func(line []byte)
+
+	pending []byte // filtered output not yet read
+	err     error  // ends the stream once pending is read
+}
+
+// newStdoutFilter filters r, calling noise with every line, or part of a
+// line, that is not JSON
+func newStdoutFilter(r io.ReadCloser, strict bool, noise func(line []byte)) *stdoutFilter {
+	return &stdoutFilter{r: bufio.NewReader(r), closer: r, strict: strict, noise: noise}
+}
+
+// Read implements io.Reader
+func (f *stdoutFilter) Read(p []byte) (int, error) {
+	for len(f.pending) == 0 {
+		if f.err != nil {
+			return 0, f.err
+		}
+		line, err := f.r.ReadBytes('\n')
+		f.err = err
+		f.pending = f.filter(line)
+	}
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
+
+// filter returns the message of line with its newline, or nil when line
+// holds none
+func (f *stdoutFilter) filter(line []byte) []byte {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	if json.Valid(trimmed) && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return append(trimmed, '\n')
+	}
+
+	message := recoverMessage(trimmed)
+	f.noise(bytes.TrimSpace(trimmed[:len(trimmed)-len(message)]))
+	if f.strict {
+		// mcp-go prints read errors other than EOF to our stdout
+		f.err = io.EOF
+		f.closer.Close()
+		return nil
+	}
+	if message == nil {
+		return nil
+	}
+	return append(message, '\n')
+}
+
+// recoverMessage returns the JSON message ending line after noise printed
+// without a newline, or nil when line does not end with one
+func recoverMessage(line []byte) []byte {
+	for start := 1; start < len(line); start++ {
+		next := bytes.IndexAny(line[start:], "{[")
+		if next < 0 {
+			return nil
+		}
+		start += next
+		if json.Valid(line[start:]) {
+			return line[start:]
+		}
+	}
+	return nil
+}
+
+// quoteNoise shortens a polluting line for a warning
+func quoteNoise(line []byte) string {
+	if len(line) > maxNoiseReport {
+		return string(line[:maxNoiseReport]) + "..."
+	}
+	return string(line)
+}
@@ -0,0 +1,62 @@
+package upstream
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdoutFilter(t *testing.T) {
+	input := "starting up\n" +
+		`{"jsonrpc":"2.0","id":1,"result":{}}` + "\n" +
+		"\n" +
+		`loading [1/2]... {"jsonrpc":"2.0","id":2,"result":{}}` + "\n" +
+		"42\n" +
+		`{"jsonrpc":"2.0","id":3,"result":{}}`
+
+	var noise []string
+	filter := newStdoutFilter(io.NopCloser(strings.NewReader(input)), false, func(line []byte) {
+		noise = append(noise, string(line))
+	})
+	out, err := io.ReadAll(filter)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":{}}`+"\n"+
+		`{"jsonrpc":"2.0","id":2,"result":{}}`+"\n"+
+		`{"jsonrpc":"2.0","id":3,"result":{}}`+"\n", string(out))
+	assert.Equal(t, []string{"starting up", "loading [1/2]...", "42"}, noise)
+}
+
+// closeRecorder records whether it was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestStdoutFilter_Strict(t *testing.T) {
+	r := &closeRecorder{Reader: strings.NewReader(`{"id":1}` + "\nbanner\n" + `{"id":2}` + "\n")}
+	var noise []string
+	filter := newStdoutFilter(r, true, func(line []byte) {
+		noise = append(noise, string(line))
+	})
+	out, err := io.ReadAll(filter)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"id":1}`+"\n", string(out))
+	assert.Equal(t, []string{"banner"}, noise)
+	assert.True(t, r.closed)
+}
+
+func TestQuoteNoise(t *testing.T) {
+	assert.Equal(t, "short", quoteNoise([]byte("short")))
+	long := quoteNoise([]byte(strings.Repeat("x", maxNoiseReport+10)))
+	assert.Equal(t, strings.Repeat("x", maxNoiseReport)+"...", long)
+}
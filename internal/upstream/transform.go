@@ -0,0 +1,197 @@
+package upstream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/redact"
+)
+
+// ProvenanceKey is the `_meta` key under which Annotate records where a
+// result came from
+const ProvenanceKey = "provenance"
+
+// Source describes the upstream call that produced a tool result
+type Source struct {
+	// Upstream is the name of the upstream
+	Upstream string
+
+	// Tool is the tool's upstream name and LocalName the name it is
+	// published under
+	Tool      string
+	LocalName string
+
+	// Server identifies the upstream server
+	Server mcp.Implementation
+}
+
+// Transformer rewrites the result of a proxied tool call before it is
+// returned to the client. It may modify result in place; an error fails the
+// call.
+type Transformer func(ctx context.Context, source Source, result *mcp.CallToolResult) error
+
+// Truncate shortens text content longer than maxBytes, noting how much was
+// cut
+func Truncate(maxBytes int) Transformer {
+	return func(ctx context.Context, source Source, result *mcp.CallToolResult) error {
+		for i, content := range result.Content {
+			text, ok := content.(mcp.TextContent)
+			if !ok || len(text.Text) <= maxBytes {
+				continue
+			}
+			// Cut at a character boundary
+			n := maxBytes
+			for n > 0 && !utf8.RuneStart(text.Text[n]) {
+				n--
+			}
+			text.Text = fmt.Sprintf("%s\n[truncated %d of %d bytes]", text.Text[:n], len(text.Text)-n, len(text.Text))
+			result.Content[i] = text
+		}
+		return nil
+	}
+}
+
+// StripImages replaces images with a text placeholder
+func StripImages() Transformer {
+	return func(ctx context.Context, source Source, result *mcp.CallToolResult) error {
+		for i, content := range result.Content {
+			if image, ok := content.(mcp.ImageContent); ok {
+				result.Content[i] = mcp.NewTextContent(fmt.Sprintf("[%s image of %d bytes omitted]", image.MIMEType, decodedLen(image.Data)))
+			}
+		}
+		return nil
+	}
+}
+
+// ResourceServer publishes externalized content as resources. The mcp-go
+// server implements it.
+type ResourceServer interface {
+	AddResources(resources ...server.ServerResource)
+	RemoveResource(uri string)
+}
+
+// ExternalizeImages publishes images as resources on s and replaces them
+// with links, so clients fetch them only when needed. At most limit images
+// are kept; the oldest are withdrawn first.
+func ExternalizeImages(s ResourceServer, limit int) Transformer {
+	store := &imageStore{server: s, limit: limit, uris: make(map[string]bool)}
+	return func(ctx context.Context, source Source, result *mcp.CallToolResult) error {
+		for i, content := range result.Content {
+			if image, ok := content.(mcp.ImageContent); ok {
+				result.Content[i] = store.add(source, image)
+			}
+		}
+		return nil
+	}
+}
+
+// imageStore holds the images published by ExternalizeImages
+type imageStore struct {
+	server ResourceServer
+	limit  int
+
+	mu    sync.Mutex
+	order []string
+	uris  map[string]bool
+}
+
+// add publishes image, unless an identical one already is, and returns a
+// link to it
+func (s *imageStore) add(source Source, image mcp.ImageContent) mcp.ResourceLink {
+	sum := sha256.Sum256([]byte(image.Data))
+	name := "image-" + hex.EncodeToString(sum[:8])
+	uri := "upstream://" + source.Upstream + "/images/" + name
+	description := fmt.Sprintf("Image returned by %s (%d bytes)", source.LocalName, decodedLen(image.Data))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.uris[uri] {
+		resource := mcp.NewResource(uri, name, mcp.WithResourceDescription(description), mcp.WithMIMEType(image.MIMEType))
+		contents := []mcp.ResourceContents{mcp.BlobResourceContents{URI: uri, MIMEType: image.MIMEType, Blob: image.Data}}
+		s.server.AddResources(server.ServerResource{
+			Resource: resource,
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return contents, nil
+			},
+		})
+		s.uris[uri] = true
+		s.order = append(s.order, uri)
+		for len(s.order) > s.limit {
+			s.server.RemoveResource(s.order[0])
+			delete(s.uris, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	return mcp.NewResourceLink(uri, name, description, image.MIMEType)
+}
+
+// Redact replaces secrets found in text content and in the result's
+// `_meta` using r (defaults to redact.Default())
+func Redact(r *redact.Redactor) Transformer {
+	return func(ctx context.Context, source Source, result *mcp.CallToolResult) error {
+		r := r
+		if r == nil {
+			r = redact.Default()
+		}
+		for i, content := range result.Content {
+			switch content := content.(type) {
+			case mcp.TextContent:
+				content.Text = r.String(content.Text)
+				result.Content[i] = content
+			case mcp.EmbeddedResource:
+				if text, ok := content.Resource.(mcp.TextResourceContents); ok {
+					text.Text = r.String(text.Text)
+					content.Resource = text
+					result.Content[i] = content
+				}
+			}
+		}
+		if result.Meta != nil {
+			result.Meta = r.Map(result.Meta)
+		}
+		return nil
+	}
+}
+
+// Annotate records the upstream, tool and server that produced a result
+// in its `_meta`
+func Annotate() Transformer {
+	return func(ctx context.Context, source Source, result *mcp.CallToolResult) error {
+		if result.Meta == nil {
+			result.Meta = make(map[string]any)
+		}
+		result.Meta[ProvenanceKey] = map[string]any{
+			"upstream":      source.Upstream,
+			"tool":          source.Tool,
+			"server":        source.Server.Name,
+			"serverVersion": source.Server.Version,
+		}
+		return nil
+	}
+}
+
+// transform applies the configured transformers to a result in order
+func (m *Manager) transform(ctx context.Context, source Source, result *mcp.CallToolResult) error {
+	for _, transformer := range m.config.Transformers {
+		if err := transformer(ctx, source, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodedLen returns the size of base64 data once decoded
+func decodedLen(data string) int {
+	n := len(data) * 3 / 4
+	for i := len(data) - 1; i >= 0 && data[i] == '='; i-- {
+		n--
+	}
+	return n
+}
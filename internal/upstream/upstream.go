@@ -0,0 +1,513 @@
+// Package upstream connects to other MCP servers and republishes their tools
+// through a tools.Registry, so clients of this server can call them as if
+// they were local.
+//
+// An upstream is either a process speaking MCP over stdin and stdout or a
+// server reachable over SSE. Its tools are registered under a prefix
+// (by default the upstream name and an underscore) and kept in sync when the
+// upstream sends notifications/tools/list_changed. Errors returned by an
+// upstream are normalized with an errors.Translator, and results pass
+// through the configured Transformers (truncation, image externalization,
+// redaction, provenance) before reaching clients.
+//
+// A stdio upstream can be lazy, publishing its tools from a warm-up probe
+// and starting on first use, and can be stopped after an idle period.
+//
+// Upstreams publishing the same tools, e.g. replicas sharing a Prefix, form
+// a pool per tool: each call goes to one healthy replica, chosen
+// round-robin, by fewest calls in flight or by weight. A call can pin a
+// replica or pick the strategy with the "upstream" and "balance" keys of
+// its _meta.
+//
+// Calls can get default and injected arguments, e.g. the client's root
+// directory, before they are proxied; injected parameters are hidden from
+// the tools' advertised input schemas.
+//
+// Tools of an upstream that corrupts state under concurrent writes can be
+// put in a concurrency group with ConcurrencyGroup or ToolGroups; calls
+// within a group are limited by Config.ConcurrencyGroups across all
+// connections, and serialized by default.
+//
+// What this server presents to an upstream in the initialize handshake,
+// its client info, capabilities and protocol version, can be overridden per
+// upstream with Spec.Initialize.
+//
+// Notifications of upstreams pass through NotificationRules deciding
+// whether each is forwarded, dropped or rate limited, and how it is
+// rewritten, so a noisy upstream cannot flood every client. Progress goes
+// to the client whose call it reports on.
+//
+// A local tool can replace an upstream tool of the same local name, e.g. to
+// validate its arguments, once the upstream acknowledges it in
+// Spec.Overrides; Manager.Overridden calls the replaced tool.
+//
+// Dry runs reach only the upstream tools listed in Spec.DryRun; the
+// registry previews the others without calling the upstream.
+//
+// Calls can be mirrored to a shadow upstream, typically a hidden one, to
+// compare a new implementation against the current one under real traffic.
+// Shadow calls run in the background and their results are only logged.
+//
+// Basic usage:
+//
+//	manager := upstream.New(upstream.Config{Registry: registry})
+//	err := manager.Add(ctx, upstream.Spec{Name: "fs", Command: "mcp-server-filesystem", Args: []string{"/srv"}})
+//	defer manager.Shutdown(context.Background())
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/clock"
+	"github.com/meta-mcp/meta-mcp-server/internal/events"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+var (
+	// ErrUpstreamExists is returned when adding a name already in use
+	ErrUpstreamExists = errors.New("upstream already added")
+
+	// ErrUpstreamNotFound is returned for operations on unknown upstreams
+	ErrUpstreamNotFound = errors.New("upstream not found")
+
+	// ErrManagerStopped is returned when adding to a shut down manager
+	ErrManagerStopped = errors.New("upstream manager stopped")
+)
+
+// State is the connection state of an upstream
+type State string
+
+// Upstream states
+const (
+	StateConnected    State = "connected"
+	StateDisconnected State = "disconnected"
+
+	// StateIdle is a stopped stdio upstream whose tools stay published; it
+	// starts on the next call
+	StateIdle State = "idle"
+)
+
+// Spec declares an upstream MCP server
+type Spec struct {
+	// Name identifies the upstream in tool names, tags and errors
+	Name string `yaml:"name" json:"name"`
+
+	// Command, Args, Env and Dir start a stdio upstream. Env entries are
+	// added to the server's environment.
+	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
+	Env     []string `yaml:"env,omitempty" json:"env,omitempty"`
+	Dir     string   `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+	// URL connects to an SSE upstream; Headers are sent with every request
+	URL     string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Prefix is prepended to the upstream's tool names (defaults to Name
+	// followed by an underscore)
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// KeepNames publishes tools under their upstream names, ignoring Prefix
+	KeepNames bool `yaml:"keepNames,omitempty" json:"keepNames,omitempty"`
+
+	// Tags are added to every tool of the upstream
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// Lazy stops a stdio upstream once a warm-up probe (initialize and
+	// tools/list) has published its tools, and starts it again on first
+	// use
+	Lazy bool `yaml:"lazy,omitempty" json:"lazy,omitempty"`
+
+	// IdleTimeout stops a stdio upstream after this long without calls;
+	// it starts again on the next call (zero keeps it running)
+	IdleTimeout time.Duration `yaml:"idleTimeout,omitempty" json:"idleTimeout,omitempty"`
+
+	// Shadow names another upstream that receives a copy of every call,
+	// e.g. a replacement being migrated to. Its results are compared with
+	// ours and logged, never returned to clients.
+	Shadow string `yaml:"shadow,omitempty" json:"shadow,omitempty"`
+
+	// Hidden connects the upstream without publishing its tools, e.g. to
+	// serve only as another upstream's Shadow
+	Hidden bool `yaml:"hidden,omitempty" json:"hidden,omitempty"`
+
+	// Balance selects how calls are spread when other upstreams publish
+	// the same tools, e.g. replicas sharing a Prefix (defaults to
+	// round-robin). The first replica of a tool decides.
+	Balance Strategy `yaml:"balance,omitempty" json:"balance,omitempty"`
+
+	// Weight is the share of calls the weighted strategy sends to this
+	// upstream (defaults to 1)
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// StrictStdout stops a stdio upstream that writes anything but
+	// JSON-RPC to stdout. By default such output is skipped and logged as
+	// a warning, and a message following it on the same line is kept.
+	StrictStdout bool `yaml:"strictStdout,omitempty" json:"strictStdout,omitempty"`
+
+	// ConcurrencyGroup puts every tool of the upstream in a concurrency
+	// group, limiting how many of their calls run at once across all
+	// connections, e.g. for an upstream that corrupts state under
+	// concurrent writes
+	ConcurrencyGroup string `yaml:"concurrencyGroup,omitempty" json:"concurrencyGroup,omitempty"`
+
+	// ToolGroups puts single tools, by upstream name, in a concurrency
+	// group, overriding ConcurrencyGroup
+	ToolGroups map[string]string `yaml:"toolGroups,omitempty" json:"toolGroups,omitempty"`
+
+	// Arguments adds defaults and injected values to the arguments of
+	// calls, by upstream tool name; AllTools applies to every tool, and a
+	// tool's own rules take precedence
+	Arguments map[string]ArgumentRules `yaml:"arguments,omitempty" json:"arguments,omitempty"`
+
+	// Initialize overrides the client info, capabilities and protocol
+	// version presented to the upstream in the handshake
+	Initialize *InitializeOverrides `yaml:"initialize,omitempty" json:"initialize,omitempty"`
+
+	// Notifications decide which notifications of the upstream reach
+	// clients, before the rules of Config.Notifications
+	Notifications []NotificationRule `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+
+	// Overrides acknowledges local tools replacing tools of the upstream,
+	// by upstream name. Those tools are not published; the local tool of
+	// the same local name serves their calls and can pass them on with
+	// Manager.Overridden. A local tool replacing one not listed fails its
+	// registration.
+	Overrides []string `yaml:"overrides,omitempty" json:"overrides,omitempty"`
+
+	// DryRun lists the tools, by upstream name, that honor the dryRun key
+	// of a call's _meta; AllTools stands for every tool. Dry runs of the
+	// others are answered without calling the upstream.
+	DryRun []string `yaml:"dryRun,omitempty" json:"dryRun,omitempty"`
+}
+
+// dryRun reports whether the upstream tool name supports dry runs
+func (s Spec) dryRun(name string) bool {
+	return slices.Contains(s.DryRun, name) || slices.Contains(s.DryRun, AllTools)
+}
+
+// groupOf returns the concurrency group of an upstream tool, or "" for none
+func (s Spec) groupOf(name string) string {
+	if group, ok := s.ToolGroups[name]; ok {
+		return group
+	}
+	return s.ConcurrencyGroup
+}
+
+// toolName returns the local name of an upstream tool
+func (s Spec) toolName(name string) string {
+	if s.KeepNames {
+		return name
+	}
+	if s.Prefix != "" {
+		return s.Prefix + name
+	}
+	return s.Name + "_" + name
+}
+
+// Status describes an added upstream
+type Status struct {
+	Name          string `json:"name"`
+	Transport     string `json:"transport"`
+	State         State  `json:"state"`
+	ServerName    string `json:"serverName,omitempty"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// ProtocolVersion is the version the upstream agreed to
+	ProtocolVersion string   `json:"protocolVersion,omitempty"`
+	Tools           []string `json:"tools"`
+	// Overridden lists the tools replaced by local tools
+	Overridden  []string  `json:"overridden,omitempty"`
+	ConnectedAt time.Time `json:"connectedAt,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+
+	// NoiseLines counts the non-JSON output skipped on stdout
+	NoiseLines int `json:"noiseLines,omitempty"`
+
+	// Notifications counts the notifications forwarded and dropped
+	Notifications NotificationStats `json:"notifications"`
+}
+
+// NotificationStats counts the notifications of an upstream by what the
+// notification rules did with them
+type NotificationStats struct {
+	Forwarded int `json:"forwarded"`
+	Dropped   int `json:"dropped"`
+}
+
+// Config contains configuration for a Manager
+type Config struct {
+	// Registry receives the upstreams' tools (required)
+	Registry *tools.Registry `yaml:"-"`
+
+	// Translator normalizes upstream errors (defaults to the
+	// DefaultTranslationRules)
+	Translator *mcperrors.Translator `yaml:"-"`
+
+	// ClientName and ClientVersion identify this server to upstreams
+	// (default to "meta-mcp-server" and "1.0.0")
+	ClientName    string `yaml:"-"`
+	ClientVersion string `yaml:"-"`
+
+	// Upstreams are added by Start
+	Upstreams []Spec `yaml:"upstreams"`
+
+	// InitTimeout bounds connecting and the initialize handshake (defaults
+	// to 10s)
+	InitTimeout time.Duration `yaml:"initTimeout,omitempty"`
+
+	// CallTimeout bounds each tool call (defaults to 30s)
+	CallTimeout time.Duration `yaml:"callTimeout,omitempty"`
+
+	// ConcurrencyGroups sets how many calls of each concurrency group run
+	// at once; groups not listed run one call at a time
+	ConcurrencyGroups map[string]int `yaml:"concurrencyGroups,omitempty"`
+
+	// Transformers rewrite the results of tool calls, in order, before they
+	// are returned to clients
+	Transformers []Transformer `yaml:"-"`
+
+	// OnShadow is called with the comparison of each mirrored call, after
+	// it has been logged
+	OnShadow func(ShadowResult) `yaml:"-"`
+
+	// Events receives the state changes of upstreams (defaults to
+	// events.Default())
+	Events *events.Bus `yaml:"-"`
+
+	// Roots returns the root paths of the client of ctx for argument
+	// templates (defaults to asking the client with roots/list)
+	Roots func(ctx context.Context) ([]string, error) `yaml:"-"`
+
+	// Notifications decide which upstream notifications reach clients,
+	// after the rules of each Spec and before DefaultNotificationRules
+	Notifications []NotificationRule `yaml:"notifications,omitempty"`
+
+	// Notify sends the progress of a call to the client of ctx (defaults
+	// to the mcp-go server handling its request)
+	Notify func(ctx context.Context, method string, params map[string]any) error `yaml:"-"`
+
+	// Broadcast sends other forwarded notifications to every client, e.g.
+	// the mcp-go server's SendNotificationToAllClients (nil drops them)
+	Broadcast func(method string, params map[string]any) `yaml:"-"`
+
+	// Clock paces rate-limited notifications (defaults to the system
+	// clock)
+	Clock clock.Clock `yaml:"-"`
+}
+
+// LoadConfig reads upstream declarations from a YAML file
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Manager connects upstreams and publishes their tools
+type Manager struct {
+	config Config
+
+	mu        sync.Mutex
+	upstreams map[string]*upstream
+	stopped   bool
+
+	// shadowCalls holds a token per mirrored call in flight
+	shadowCalls chan struct{}
+
+	// poolMu guards pools, the replicas of each published tool
+	poolMu sync.Mutex
+	pools  map[string]*pool
+}
+
+// New creates an upstream manager
+func New(config Config) *Manager {
+	if config.Translator == nil {
+		config.Translator, _ = mcperrors.NewTranslator(mcperrors.TranslationConfig{
+			Rules: mcperrors.DefaultTranslationRules(),
+		})
+	}
+	if config.ClientName == "" {
+		config.ClientName = "meta-mcp-server"
+	}
+	if config.ClientVersion == "" {
+		config.ClientVersion = "1.0.0"
+	}
+	if config.InitTimeout <= 0 {
+		config.InitTimeout = 10 * time.Second
+	}
+	if config.CallTimeout <= 0 {
+		config.CallTimeout = 30 * time.Second
+	}
+	if config.Roots == nil {
+		config.Roots = clientRoots
+	}
+	if config.Notify == nil {
+		config.Notify = notifyClient
+	}
+	config.Clock = clock.Or(config.Clock)
+	for group, limit := range config.ConcurrencyGroups {
+		config.Registry.SetConcurrencyLimit(group, limit)
+	}
+
+	return &Manager{
+		config:      config,
+		upstreams:   make(map[string]*upstream),
+		shadowCalls: make(chan struct{}, maxShadowCalls),
+		pools:       make(map[string]*pool),
+	}
+}
+
+// Start adds every configured upstream, returning the errors of those that
+// failed to connect
+func (m *Manager) Start(ctx context.Context) error {
+	var errs []error
+	for _, spec := range m.config.Upstreams {
+		if err := m.Add(ctx, spec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Add connects to an upstream and registers its tools
+func (m *Manager) Add(ctx context.Context, spec Spec) error {
+	if spec.Name == "" {
+		return errors.New("upstream name is required")
+	}
+	if (spec.Command == "") == (spec.URL == "") {
+		return fmt.Errorf("upstream %s: exactly one of command or url is required", spec.Name)
+	}
+	if spec.URL != "" && (spec.Lazy || spec.IdleTimeout > 0) {
+		return fmt.Errorf("upstream %s: lazy and idleTimeout apply to command upstreams only", spec.Name)
+	}
+	if spec.Shadow == spec.Name {
+		return fmt.Errorf("upstream %s: cannot shadow itself", spec.Name)
+	}
+	if spec.Balance == "" {
+		spec.Balance = RoundRobin
+	}
+	if !spec.Balance.valid() {
+		return fmt.Errorf("upstream %s: unknown balance strategy %q", spec.Name, spec.Balance)
+	}
+	if spec.Weight < 0 {
+		return fmt.Errorf("upstream %s: weight must not be negative", spec.Name)
+	}
+	if spec.Weight == 0 {
+		spec.Weight = 1
+	}
+	arguments, err := compileArguments(spec)
+	if err != nil {
+		return fmt.Errorf("upstream %s: %w", spec.Name, err)
+	}
+	identity, err := m.identity(spec)
+	if err != nil {
+		return fmt.Errorf("upstream %s: %w", spec.Name, err)
+	}
+	notifications, err := compileNotifications(spec, m.config, m.config.Clock)
+	if err != nil {
+		return fmt.Errorf("upstream %s: %w", spec.Name, err)
+	}
+
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return ErrManagerStopped
+	}
+	if _, ok := m.upstreams[spec.Name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUpstreamExists, spec.Name)
+	}
+	// Reserve the name while connecting
+	m.upstreams[spec.Name] = nil
+	m.mu.Unlock()
+
+	u, err := connect(ctx, m, spec, arguments, identity, notifications)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		delete(m.upstreams, spec.Name)
+		return err
+	}
+	m.upstreams[spec.Name] = u
+	return nil
+}
+
+// Remove disconnects an upstream and unregisters its tools
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	u, ok := m.upstreams[name]
+	if !ok || u == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUpstreamNotFound, name)
+	}
+	delete(m.upstreams, name)
+	m.mu.Unlock()
+
+	return u.close()
+}
+
+// WarmUp starts an idle upstream ahead of its first call
+func (m *Manager) WarmUp(ctx context.Context, name string) error {
+	m.mu.Lock()
+	u, ok := m.upstreams[name]
+	m.mu.Unlock()
+	if !ok || u == nil {
+		return fmt.Errorf("%w: %s", ErrUpstreamNotFound, name)
+	}
+	return u.warmUp(ctx)
+}
+
+// Status returns the status of every upstream sorted by name
+func (m *Manager) Status() []Status {
+	m.mu.Lock()
+	upstreams := make([]*upstream, 0, len(m.upstreams))
+	for _, u := range m.upstreams {
+		if u != nil {
+			upstreams = append(upstreams, u)
+		}
+	}
+	m.mu.Unlock()
+
+	statuses := make([]Status, len(upstreams))
+	for i, u := range upstreams {
+		statuses[i] = u.status()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Shutdown disconnects every upstream; none can be added afterwards
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopped = true
+	upstreams := m.upstreams
+	m.upstreams = make(map[string]*upstream)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, u := range upstreams {
+		if u == nil {
+			continue
+		}
+		if err := u.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,348 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/jsonrpc"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// TestMain lets the test binary act as a stdio upstream
+func TestMain(m *testing.M) {
+	if os.Getenv("UPSTREAM_HELPER") != "" {
+		fmt.Fprintln(os.Stderr, "helper starting")
+		server.ServeStdio(newHelperServer())
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// newHelperServer returns an MCP server with tools exercising the proxy
+func newHelperServer() *server.MCPServer {
+	s := server.NewMCPServer("helper", "2.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("message")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if os.Getenv("UPSTREAM_HELPER_NOISY") != "" {
+			// No newline, so the response follows on the same line
+			fmt.Print("debug: noisy")
+		}
+		return mcp.NewToolResultText(request.GetString("message", "")), nil
+	})
+	s.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+	s.AddTool(mcp.NewTool("grow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.AddTool(mcp.NewTool("extra"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("extra"), nil
+		})
+		return mcp.NewToolResultText("grown"), nil
+	})
+	s.AddTool(mcp.NewTool("exit"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		os.Exit(1)
+		return nil, nil
+	})
+	return s
+}
+
+// helperSpec returns a spec starting the test binary as an upstream
+func helperSpec(t *testing.T, name string) Spec {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	return Spec{Name: name, Command: exe, Env: []string{"UPSTREAM_HELPER=1"}, Tags: []string{"test"}}
+}
+
+func call(registry *tools.Registry, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return registry.Call(context.Background(), request)
+}
+
+func TestManager_Stdio(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry, CallTimeout: 5 * time.Second})
+	defer manager.Shutdown(context.Background())
+
+	require.NoError(t, manager.Add(context.Background(), helperSpec(t, "helper")))
+
+	info, ok := registry.Get("helper_echo")
+	require.True(t, ok)
+	assert.Equal(t, "2.0.0", info.Version)
+	assert.Equal(t, []string{"upstream", "upstream:helper", "test"}, info.Tags)
+
+	result, err := call(registry, "helper_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Content[0].(mcp.TextContent).Text)
+
+	// Upstream errors keep their JSON-RPC code
+	_, err = call(registry, "helper_fail", nil)
+	mcpErr := mcperrors.FindMCPError(err)
+	require.NotNil(t, mcpErr)
+	assert.Equal(t, jsonrpc.ErrorCodeInternal, mcpErr.Code)
+	assert.Contains(t, mcpErr.Message, "boom")
+	assert.Equal(t, "helper", mcpErr.Context["upstream"])
+
+	// Tools added upstream are picked up from list_changed
+	_, err = call(registry, "helper_grow", nil)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, ok := registry.Get("helper_extra")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := manager.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, StateConnected, status[0].State)
+	assert.Equal(t, "stdio", status[0].Transport)
+	assert.Equal(t, "helper", status[0].ServerName)
+	assert.Len(t, status[0].Tools, 5)
+
+	// A crashed upstream's tools are disabled
+	call(registry, "helper_exit", nil)
+	assert.Eventually(t, func() bool {
+		return manager.Status()[0].State == StateDisconnected
+	}, 5*time.Second, 10*time.Millisecond)
+	info, _ = registry.Get("helper_echo")
+	assert.False(t, info.Enabled)
+	_, err = call(registry, "helper_echo", nil)
+	assert.Equal(t, mcperrors.ErrorCodeMCPToolNotFound, mcperrors.FindMCPError(err).Code)
+
+	require.NoError(t, manager.Remove("helper"))
+	assert.Empty(t, registry.List(""))
+	assert.ErrorIs(t, manager.Remove("helper"), ErrUpstreamNotFound)
+}
+
+func TestManager_StdoutNoise(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry})
+	defer manager.Shutdown(context.Background())
+	lenient := helperSpec(t, "lenient")
+	lenient.Env = append(lenient.Env, "UPSTREAM_HELPER_NOISY=1")
+	require.NoError(t, manager.Add(context.Background(), lenient))
+	strict := helperSpec(t, "strict")
+	strict.Env = append(strict.Env, "UPSTREAM_HELPER_NOISY=1")
+	strict.StrictStdout = true
+	require.NoError(t, manager.Add(context.Background(), strict))
+
+	result, err := call(registry, "lenient_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Content[0].(mcp.TextContent).Text)
+
+	_, err = call(registry, "strict_echo", map[string]any{"message": "hi"})
+	require.Error(t, err)
+	require.Eventually(t, func() bool {
+		for _, status := range manager.Status() {
+			if status.Name == "strict" {
+				return status.State == StateDisconnected
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, status := range manager.Status() {
+		assert.Equal(t, 1, status.NoiseLines, status.Name)
+		if status.Name == "strict" {
+			assert.Contains(t, status.LastError, "debug: noisy")
+		}
+	}
+}
+
+func TestManager_LazyAndIdle(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry, CallTimeout: 5 * time.Second})
+	defer manager.Shutdown(context.Background())
+
+	spec := helperSpec(t, "helper")
+	spec.Lazy = true
+	spec.IdleTimeout = 100 * time.Millisecond
+	require.NoError(t, manager.Add(context.Background(), spec))
+
+	// The warm-up probe published the tools without keeping the process
+	status := manager.Status()[0]
+	assert.Equal(t, StateIdle, status.State)
+	assert.Equal(t, "stdio", status.Transport)
+	assert.Len(t, status.Tools, 4)
+	info, ok := registry.Get("helper_echo")
+	require.True(t, ok)
+	assert.True(t, info.Enabled)
+
+	// The first call starts it
+	result, err := call(registry, "helper_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, StateConnected, manager.Status()[0].State)
+
+	// Unused, it stops again
+	assert.Eventually(t, func() bool {
+		return manager.Status()[0].State == StateIdle
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, manager.Status()[0].LastError)
+
+	require.NoError(t, manager.WarmUp(context.Background(), "helper"))
+	assert.Equal(t, StateConnected, manager.Status()[0].State)
+	assert.ErrorIs(t, manager.WarmUp(context.Background(), "missing"), ErrUpstreamNotFound)
+}
+
+func TestManager_SSE(t *testing.T) {
+	httpServer := server.NewTestServer(newHelperServer())
+	defer httpServer.Close()
+
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry})
+	defer manager.Shutdown(context.Background())
+
+	require.NoError(t, manager.Add(context.Background(), Spec{Name: "remote", URL: httpServer.URL + "/sse", Prefix: "r."}))
+	require.NoError(t, manager.Add(context.Background(), Spec{Name: "plain", URL: httpServer.URL + "/sse", KeepNames: true}))
+
+	result, err := call(registry, "r.echo", map[string]any{"message": "over sse"})
+	require.NoError(t, err)
+	assert.Equal(t, "over sse", result.Content[0].(mcp.TextContent).Text)
+
+	_, ok := registry.Get("echo")
+	assert.True(t, ok)
+	assert.Equal(t, "sse", manager.Status()[0].Transport)
+}
+
+func TestManager_InitializeOverrides(t *testing.T) {
+	initialized := make(chan mcp.InitializeParams, 2)
+	hooks := &server.Hooks{}
+	hooks.AddBeforeInitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest) {
+		initialized <- request.Params
+	})
+	s := server.NewMCPServer("helper", "2.0.0", server.WithToolCapabilities(true), server.WithHooks(hooks))
+	httpServer := server.NewTestServer(s)
+	defer httpServer.Close()
+
+	manager := New(Config{Registry: tools.New(tools.Config{})})
+	defer manager.Shutdown(context.Background())
+
+	require.NoError(t, manager.Add(context.Background(), Spec{Name: "plain", URL: httpServer.URL + "/sse"}))
+	params := <-initialized
+	assert.Equal(t, mcp.Implementation{Name: "meta-mcp-server", Version: "1.0.0"}, params.ClientInfo)
+	assert.Equal(t, mcp.LATEST_PROTOCOL_VERSION, params.ProtocolVersion)
+
+	var config Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+upstreams:
+  - name: picky
+    initialize:
+      clientName: claude-desktop
+      protocolVersion: "2024-11-05"
+      capabilities:
+        roots: {listChanged: true}
+        experimental: {tracing: {}}
+`), &config))
+	spec := config.Upstreams[0]
+	spec.URL = httpServer.URL + "/sse"
+	require.NoError(t, manager.Add(context.Background(), spec))
+	params = <-initialized
+	assert.Equal(t, mcp.Implementation{Name: "claude-desktop", Version: "1.0.0"}, params.ClientInfo)
+	assert.Equal(t, "2024-11-05", params.ProtocolVersion)
+	require.NotNil(t, params.Capabilities.Roots)
+	assert.True(t, params.Capabilities.Roots.ListChanged)
+	assert.Contains(t, params.Capabilities.Experimental, "tracing")
+	assert.Nil(t, params.Capabilities.Sampling)
+
+	statuses := manager.Status()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "2024-11-05", statuses[0].ProtocolVersion)
+	assert.Equal(t, mcp.LATEST_PROTOCOL_VERSION, statuses[1].ProtocolVersion)
+}
+
+func TestManager_AddErrors(t *testing.T) {
+	manager := New(Config{Registry: tools.New(tools.Config{}), InitTimeout: 5 * time.Second})
+
+	assert.Error(t, manager.Add(context.Background(), Spec{Command: "x"}))
+	assert.ErrorContains(t, manager.Add(context.Background(), Spec{Name: "both", Command: "x", URL: "http://x"}), "exactly one")
+	assert.ErrorContains(t, manager.Add(context.Background(), Spec{Name: "lazy", URL: "http://x", Lazy: true}), "command upstreams only")
+	assert.ErrorContains(t, manager.Add(context.Background(), Spec{Name: "self", URL: "http://x", Shadow: "self"}), "shadow itself")
+	assert.ErrorContains(t, manager.Add(context.Background(), Spec{Name: "odd", URL: "http://x", Balance: "random"}), "balance strategy")
+	assert.ErrorContains(t, manager.Add(context.Background(), Spec{Name: "future", URL: "http://x",
+		Initialize: &InitializeOverrides{ProtocolVersion: "2099-01-01"}}), "unknown protocol version")
+	assert.ErrorContains(t, manager.Add(context.Background(), Spec{Name: "typo", URL: "http://x",
+		Initialize: &InitializeOverrides{Capabilities: map[string]any{"sampeling": map[string]any{}}}}), "capabilities")
+	assert.Error(t, manager.Add(context.Background(), Spec{Name: "missing", Command: "/does/not/exist"}))
+	assert.Error(t, manager.Add(context.Background(), Spec{Name: "silent", Command: "true"}))
+	assert.Empty(t, manager.Status())
+
+	require.NoError(t, manager.Add(context.Background(), helperSpec(t, "helper")))
+	assert.ErrorIs(t, manager.Add(context.Background(), helperSpec(t, "helper")), ErrUpstreamExists)
+
+	require.NoError(t, manager.Shutdown(context.Background()))
+	assert.ErrorIs(t, manager.Add(context.Background(), helperSpec(t, "late")), ErrManagerStopped)
+}
+
+func TestManager_ConcurrencyGroups(t *testing.T) {
+	registry := tools.New(tools.Config{})
+	manager := New(Config{Registry: registry, CallTimeout: 5 * time.Second, ConcurrencyGroups: map[string]int{"helper": 2}})
+	defer manager.Shutdown(context.Background())
+
+	spec := helperSpec(t, "helper")
+	spec.ConcurrencyGroup = "helper"
+	spec.ToolGroups = map[string]string{"fail": "failing"}
+	require.NoError(t, manager.Add(context.Background(), spec))
+
+	echo, _ := registry.Get("helper_echo")
+	assert.Equal(t, "helper", echo.Group)
+	fail, _ := registry.Get("helper_fail")
+	assert.Equal(t, "failing", fail.Group)
+
+	_, err := call(registry, "helper_echo", map[string]any{"message": "hi"})
+	require.NoError(t, err)
+	assert.Contains(t, registry.ConcurrencyGroups(), tools.GroupStatus{Name: "helper", Limit: 2})
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upstreams.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+callTimeout: 5s
+concurrencyGroups:
+  git: 1
+upstreams:
+  - name: fs
+    command: mcp-server-filesystem
+    args: ["/srv"]
+    concurrencyGroup: git
+  - name: search
+    url: http://search.internal/sse
+    headers:
+      Authorization: Bearer token
+`), 0o644))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, config.CallTimeout)
+	require.Len(t, config.Upstreams, 2)
+	assert.Equal(t, []string{"/srv"}, config.Upstreams[0].Args)
+	assert.Equal(t, "git", config.Upstreams[0].ConcurrencyGroup)
+	assert.Equal(t, map[string]int{"git": 1}, config.ConcurrencyGroups)
+	assert.Equal(t, "Bearer token", config.Upstreams[1].Headers["Authorization"])
+}
+
+func TestWithTimeoutHint(t *testing.T) {
+	// Without a deadline the meta is forwarded as is
+	meta := &mcp.Meta{ProgressToken: "p", AdditionalFields: map[string]any{"trace": "t"}}
+	assert.Same(t, meta, withTimeoutHint(context.Background(), meta))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	hinted := withTimeoutHint(ctx, meta)
+	assert.Equal(t, mcp.ProgressToken("p"), hinted.ProgressToken)
+	assert.Equal(t, "t", hinted.AdditionalFields["trace"])
+	assert.InDelta(t, 2000, hinted.AdditionalFields["timeoutMs"], 100)
+	assert.NotContains(t, meta.AdditionalFields, "timeoutMs")
+
+	assert.Contains(t, withTimeoutHint(ctx, nil).AdditionalFields, "timeoutMs")
+}
@@ -0,0 +1,106 @@
+// Package mcpcontext gives tool, resource and prompt handlers access to the
+// connection and request they serve. It is the supported way for handler
+// code to read per-request information; the context keys behind it are
+// internal and may change.
+//
+// Basic usage:
+//
+//	func handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+//		client, _ := mcpcontext.ClientInfo(ctx)
+//		progress := mcpcontext.ProgressReporter(ctx)
+//		for i, item := range items {
+//			process(item)
+//			progress.Report(float64(i+1), float64(len(items)), "processing "+client.Name+"'s items")
+//		}
+//		...
+//	}
+//
+// Every helper returns a zero value outside a request served by the
+// Meta-MCP server, e.g. in unit tests of a handler.
+package mcpcontext
+
+import (
+	"context"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/connection"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+)
+
+// ConnectionID returns the ID of the connection serving the request, as it
+// appears in logs and admin/connections, or "" outside a connection
+func ConnectionID(ctx context.Context) string {
+	id, _ := connection.GetConnectionID(ctx)
+	return id
+}
+
+// ClientInfo returns the name and version the client sent in initialize.
+// The result is false before the handshake completed.
+func ClientInfo(ctx context.Context) (mcpgo.Implementation, bool) {
+	conn, ok := connection.FromContext(ctx)
+	if !ok || !conn.IsReady() {
+		return mcpgo.Implementation{}, false
+	}
+	info := conn.Info()
+	name, _ := info.ClientInfo["name"].(string)
+	version, _ := info.ClientInfo["version"].(string)
+	return mcpgo.Implementation{Name: name, Version: version}, true
+}
+
+// ClientCapabilities returns the capabilities the client advertised in
+// initialize. The result is false before the handshake completed.
+func ClientCapabilities(ctx context.Context) (mcpgo.ClientCapabilities, bool) {
+	conn, ok := connection.FromContext(ctx)
+	if !ok {
+		return mcpgo.ClientCapabilities{}, false
+	}
+	client, _, ok := conn.Capabilities()
+	return client, ok
+}
+
+// NegotiatedVersion returns the protocol version agreed on in the
+// handshake, or "" before it completed
+func NegotiatedVersion(ctx context.Context) string {
+	conn, ok := connection.FromContext(ctx)
+	if !ok || !conn.IsReady() {
+		return ""
+	}
+	return conn.Info().ProtocolVersion
+}
+
+// Reporter sends progress notifications for the request that asked for
+// them with _meta.progressToken
+type Reporter struct {
+	ctx   context.Context
+	token mcpgo.ProgressToken
+	srv   *mcpserver.MCPServer
+}
+
+// ProgressReporter returns the progress reporter of the request being
+// handled. It is never nil; when the client asked for no progress, Report
+// sends nothing.
+func ProgressReporter(ctx context.Context) *Reporter {
+	r := &Reporter{ctx: ctx}
+	if token, ok := mcp.ProgressTokenFromContext(ctx); ok {
+		r.token = token
+		r.srv = mcpserver.ServerFromContext(ctx)
+	}
+	return r
+}
+
+// Enabled reports whether Report reaches the client
+func (r *Reporter) Enabled() bool {
+	return r.token != nil && r.srv != nil
+}
+
+// Report tells the client how far the request got. progress must increase
+// with every call; total and message are omitted when zero.
+func (r *Reporter) Report(progress, total float64, message string) error {
+	if !r.Enabled() {
+		return nil
+	}
+	n := mcp.NewProgressNotification(r.token, progress, total, message)
+	return r.srv.SendNotificationToClient(r.ctx, n.Method, n.Params)
+}
@@ -0,0 +1,102 @@
+package mcpcontext_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/meta-mcp/meta-mcp-server/pkg/mcpcontext"
+	"github.com/meta-mcp/meta-mcp-server/pkg/metamcp"
+)
+
+func TestHelpers_OutsideRequest(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, mcpcontext.ConnectionID(ctx))
+	_, ok := mcpcontext.ClientInfo(ctx)
+	assert.False(t, ok)
+	_, ok = mcpcontext.ClientCapabilities(ctx)
+	assert.False(t, ok)
+	assert.Empty(t, mcpcontext.NegotiatedVersion(ctx))
+
+	progress := mcpcontext.ProgressReporter(ctx)
+	assert.False(t, progress.Enabled())
+	assert.NoError(t, progress.Report(1, 2, "ignored"))
+}
+
+func TestHelpers_InHandler(t *testing.T) {
+	type seen struct {
+		connectionID string
+		client       mcp.Implementation
+		version      string
+		sampling     bool
+	}
+	calls := make(chan seen, 1)
+
+	srv := metamcp.New(metamcp.Config{})
+	defer srv.Close()
+	require.NoError(t, srv.AddTool(mcp.NewTool("inspect"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, _ := mcpcontext.ClientInfo(ctx)
+		capabilities, _ := mcpcontext.ClientCapabilities(ctx)
+		calls <- seen{
+			connectionID: mcpcontext.ConnectionID(ctx),
+			client:       client,
+			version:      mcpcontext.NegotiatedVersion(ctx),
+			sampling:     capabilities.Sampling != nil,
+		}
+		progress := mcpcontext.ProgressReporter(ctx)
+		if err := progress.Report(1, 2, "halfway"); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("done"), nil
+	}))
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Serve(ctx, metamcp.StdioStreams(stdinR, stdoutW))
+
+	c := client.NewClient(transport.NewIO(stdoutR, stdinW, io.NopCloser(strings.NewReader(""))))
+	require.NoError(t, c.Start(context.Background()))
+	progress := make(chan mcp.JSONRPCNotification, 1)
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == "notifications/progress" {
+			progress <- n
+		}
+	})
+
+	initialize := mcp.InitializeRequest{}
+	initialize.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initialize.Params.ClientInfo = mcp.Implementation{Name: "inspector", Version: "2.1.0"}
+	initialize.Params.Capabilities.Sampling = &struct{}{}
+	_, err := c.Initialize(context.Background(), initialize)
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "inspect"
+	request.Params.Meta = &mcp.Meta{ProgressToken: "p1"}
+	_, err = c.CallTool(context.Background(), request)
+	require.NoError(t, err)
+
+	got := <-calls
+	assert.True(t, strings.HasPrefix(got.connectionID, "stdio-"), got.connectionID)
+	assert.Equal(t, mcp.Implementation{Name: "inspector", Version: "2.1.0"}, got.client)
+	assert.Equal(t, mcp.LATEST_PROTOCOL_VERSION, got.version)
+	assert.True(t, got.sampling)
+
+	select {
+	case n := <-progress:
+		assert.Equal(t, "p1", n.Params.AdditionalFields["progressToken"])
+		assert.Equal(t, "halfway", n.Params.AdditionalFields["message"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no progress notification")
+	}
+}
@@ -0,0 +1,52 @@
+package metamcp
+
+import (
+	"github.com/meta-mcp/meta-mcp-server/internal/client"
+	mcperrors "github.com/meta-mcp/meta-mcp-server/internal/protocol/errors"
+)
+
+// Client calls an MCP server, such as one served by a Server
+type Client = client.Client
+
+// ClientConfig contains configuration for a Client
+type ClientConfig = client.Config
+
+// ClientTransport carries a Client's messages; any mcp-go client transport
+// can be used
+type ClientTransport = client.Transport
+
+// ClientMiddleware wraps every request sent by a Client
+type ClientMiddleware = client.Middleware
+
+// RetryPolicy controls the retries of the Retry middleware
+type RetryPolicy = mcperrors.RetryPolicy
+
+// TokenSource returns the credentials sent by the Auth middleware
+type TokenSource = client.TokenSource
+
+// NewClient creates a client speaking over t. Call Start and then
+// Initialize before other requests.
+func NewClient(t ClientTransport, config ClientConfig) *Client {
+	return client.New(t, config)
+}
+
+// Retry repeats requests that fail with a retryable error
+func Retry(policy RetryPolicy) ClientMiddleware {
+	return client.Retry(policy)
+}
+
+// DefaultRetryPolicy returns the retry policy used by the server itself
+func DefaultRetryPolicy() RetryPolicy {
+	return mcperrors.DefaultRetryPolicy()
+}
+
+// Tracing records every request in a client span and propagates it to the
+// server
+func Tracing(server string) ClientMiddleware {
+	return client.Tracing(server)
+}
+
+// Auth sends a bearer token with every request over HTTP transports
+func Auth(tokens TokenSource) ClientMiddleware {
+	return client.Auth(tokens)
+}
@@ -0,0 +1,196 @@
+// Package metamcp embeds the Meta-MCP server in other Go programs.
+//
+// A Server publishes local tools and the tools of upstream MCP servers to
+// clients connected over any mix of transports, with the same handshake,
+// error handling and connection management as the meta-mcp-server binary.
+//
+// Basic usage:
+//
+//	srv := metamcp.New(metamcp.Config{Name: "my-server", Version: "1.0.0"})
+//	defer srv.Close()
+//
+//	srv.AddTool(mcp.NewTool("hello", mcp.WithString("name")), helloHandler)
+//	if err := srv.AddUpstream(ctx, metamcp.Upstream{Name: "fs", Command: "mcp-server-filesystem", Args: []string{"/srv"}}); err != nil {
+//		return err
+//	}
+//	return srv.Serve(ctx, metamcp.Stdio(), metamcp.WebSocket("127.0.0.1:8081"))
+package metamcp
+
+import (
+	"context"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/config"
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+	"github.com/meta-mcp/meta-mcp-server/internal/upstream"
+)
+
+// Tool is an MCP tool definition, built with the mcp-go helpers such as
+// mcp.NewTool
+type Tool = mcpgo.Tool
+
+// ToolHandler implements a tool
+type ToolHandler = mcpserver.ToolHandlerFunc
+
+// Upstream declares an upstream MCP server whose tools are republished.
+// Set Command to start a stdio server or URL to connect over SSE.
+type Upstream = upstream.Spec
+
+// UpstreamStatus describes a connected upstream
+type UpstreamStatus = upstream.Status
+
+// ResultTransformer rewrites the result of a proxied tool call
+type ResultTransformer = upstream.Transformer
+
+// ShadowResult compares a proxied call with its mirror on the upstream
+// named by Upstream.Shadow
+type ShadowResult = upstream.ShadowResult
+
+// Config contains configuration for a Server
+type Config struct {
+	// Name and Version identify the server to clients (default to
+	// "Meta-MCP Server" and "1.0.0")
+	Name    string
+	Version string
+
+	// HandshakeTimeout bounds the initialize handshake (defaults to 30s)
+	HandshakeTimeout time.Duration
+
+	// SupportedVersions lists the accepted protocol versions (defaults to
+	// the versions accepted by meta-mcp-server)
+	SupportedVersions []string
+
+	// ShutdownTimeout bounds how long Serve waits for transports to stop
+	// (defaults to 5s)
+	ShutdownTimeout time.Duration
+
+	// UpstreamTimeout bounds connecting to an upstream and each proxied
+	// tool call (defaults to 10s and 30s)
+	UpstreamTimeout time.Duration
+
+	// ResultTransformers rewrite the results of proxied tool calls, in
+	// order, before they are returned to clients
+	ResultTransformers []ResultTransformer
+
+	// OnShadow receives the comparison of each call mirrored to a shadow
+	// upstream; mismatches are logged either way
+	OnShadow func(ShadowResult)
+}
+
+// Server is an embeddable MCP server
+type Server struct {
+	server *server.Server
+	config Config
+}
+
+// New creates a server with no tools
+func New(cfg Config) *Server {
+	defaults := config.Default().Server
+	if cfg.Name == "" {
+		cfg.Name = defaults.Name
+	}
+	if cfg.Version == "" {
+		cfg.Version = defaults.Version
+	}
+	if cfg.HandshakeTimeout <= 0 {
+		cfg.HandshakeTimeout = defaults.HandshakeTimeout
+	}
+	if len(cfg.SupportedVersions) == 0 {
+		cfg.SupportedVersions = defaults.SupportedVersions
+	}
+
+	srv := server.NewServer(
+		server.WithHandshake(mcp.HandshakeConfig{
+			Name:              cfg.Name,
+			Version:           cfg.Version,
+			HandshakeTimeout:  cfg.HandshakeTimeout,
+			SupportedVersions: cfg.SupportedVersions,
+			ServerOptions: []mcpserver.ServerOption{
+				mcp.WithToolCapabilities(true),
+				mcp.WithRecovery(),
+			},
+		}),
+		server.WithTools(tools.Config{
+			Idempotency: tools.NewIdempotencyCache(tools.IdempotencyConfig{}),
+		}),
+		server.WithUpstreams(upstream.Config{
+			ClientName:    cfg.Name,
+			ClientVersion: cfg.Version,
+			InitTimeout:   cfg.UpstreamTimeout,
+			CallTimeout:   cfg.UpstreamTimeout,
+			Transformers:  cfg.ResultTransformers,
+			OnShadow:      cfg.OnShadow,
+		}),
+	)
+	return &Server{server: srv, config: cfg}
+}
+
+// AddTool publishes a local tool. It fails if a tool of the same name is
+// already published.
+func (s *Server) AddTool(tool Tool, handler ToolHandler) error {
+	return s.server.Tools().Register(tools.Definition{
+		Tool:    tool,
+		Handler: handler,
+		Version: s.config.Version,
+	})
+}
+
+// RemoveTool withdraws a local tool
+func (s *Server) RemoveTool(name string) error {
+	return s.server.Tools().Unregister(name)
+}
+
+// AddUpstream connects to an upstream server and publishes its tools,
+// prefixed with the upstream name unless Prefix or KeepNames say otherwise.
+// The tool list follows the upstream's list_changed notifications.
+func (s *Server) AddUpstream(ctx context.Context, u Upstream) error {
+	return s.server.Upstreams().Add(ctx, u)
+}
+
+// OverriddenTool returns a handler calling the upstream tool a local tool
+// of the same name replaces, for local tools wrapping it. The upstream must
+// list the tool in Upstream.Overrides.
+func (s *Server) OverriddenTool(name string) ToolHandler {
+	return s.server.Upstreams().Overridden(name)
+}
+
+// RemoveUpstream disconnects an upstream and withdraws its tools
+func (s *Server) RemoveUpstream(name string) error {
+	return s.server.Upstreams().Remove(name)
+}
+
+// WarmUpUpstream starts a lazy or idle upstream ahead of its first call
+func (s *Server) WarmUpUpstream(ctx context.Context, name string) error {
+	return s.server.Upstreams().WarmUp(ctx, name)
+}
+
+// Upstreams returns the status of every upstream sorted by name
+func (s *Server) Upstreams() []UpstreamStatus {
+	return s.server.Upstreams().Status()
+}
+
+// MCPServer returns the underlying mcp-go server, for registering
+// resources and prompts
+func (s *Server) MCPServer() *mcpserver.MCPServer {
+	return s.server.MCP().MCPServer
+}
+
+// Serve serves clients on every transport until ctx is done or any
+// transport stops. It returns the errors of failed transports.
+func (s *Server) Serve(ctx context.Context, transports ...Transport) error {
+	return server.New(s.server.MCP(), server.Config{
+		Transports:      transports,
+		ShutdownTimeout: s.config.ShutdownTimeout,
+		VisibleTools:    s.server.Tools().Visible,
+	}).Serve(ctx)
+}
+
+// Close disconnects every upstream
+func (s *Server) Close() error {
+	return s.server.Shutdown(context.Background())
+}
@@ -0,0 +1,96 @@
+package metamcp
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textHandler(text string) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(text + request.GetString("name", "")), nil
+	}
+}
+
+func callTool(t *testing.T, c *client.Client, name string, args map[string]any) string {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := c.CallTool(context.Background(), request)
+	require.NoError(t, err)
+	text, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestServer(t *testing.T) {
+	upstreamServer := server.NewMCPServer("remote", "2.0.0", server.WithToolCapabilities(true))
+	upstreamServer.AddTool(mcp.NewTool("greet", mcp.WithString("name")), textHandler("hi "))
+	httpServer := server.NewTestServer(upstreamServer)
+	defer httpServer.Close()
+
+	srv := New(Config{Name: "embedded", Version: "0.1.0"})
+	defer srv.Close()
+	require.NoError(t, srv.AddTool(mcp.NewTool("hello", mcp.WithString("name")), textHandler("hello ")))
+	assert.Error(t, srv.AddTool(mcp.NewTool("hello"), textHandler("")))
+	require.NoError(t, srv.AddUpstream(context.Background(), Upstream{Name: "remote", URL: httpServer.URL + "/sse"}))
+	assert.Equal(t, "remote", srv.Upstreams()[0].ServerName)
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, StdioStreams(stdinR, stdoutW)) }()
+
+	c := client.NewClient(transport.NewIO(stdoutR, stdinW, io.NopCloser(strings.NewReader(""))))
+	require.NoError(t, c.Start(context.Background()))
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0.0"}
+	result, err := c.Initialize(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "embedded", result.ServerInfo.Name)
+
+	list, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	names := make([]string, len(list.Tools))
+	for i, tool := range list.Tools {
+		names[i] = tool.Name
+	}
+	assert.ElementsMatch(t, []string{"hello", "remote_greet"}, names)
+
+	assert.Equal(t, "hello you", callTool(t, c, "hello", map[string]any{"name": "you"}))
+	assert.Equal(t, "hi you", callTool(t, c, "remote_greet", map[string]any{"name": "you"}))
+
+	require.NoError(t, srv.RemoveUpstream("remote"))
+	require.NoError(t, srv.RemoveTool("hello"))
+	list, err = c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Tools)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+}
+
+func TestServer_Defaults(t *testing.T) {
+	srv := New(Config{})
+	assert.Equal(t, "Meta-MCP Server", srv.config.Name)
+	assert.NotEmpty(t, srv.config.SupportedVersions)
+	assert.NotNil(t, srv.MCPServer())
+	assert.Error(t, srv.Serve(context.Background()))
+}
@@ -0,0 +1,37 @@
+package metamcp
+
+import "github.com/meta-mcp/meta-mcp-server/internal/upstream"
+
+// ResultSource describes the upstream call that produced a tool result
+type ResultSource = upstream.Source
+
+// ResourceServer publishes externalized images as resources
+type ResourceServer = upstream.ResourceServer
+
+// TruncateText shortens text content longer than maxBytes
+func TruncateText(maxBytes int) ResultTransformer {
+	return upstream.Truncate(maxBytes)
+}
+
+// StripImages replaces images with a text placeholder
+func StripImages() ResultTransformer {
+	return upstream.StripImages()
+}
+
+// ExternalizeImages publishes images as resources on s, keeping at most
+// limit, and replaces them with resource links
+func ExternalizeImages(s ResourceServer, limit int) ResultTransformer {
+	return upstream.ExternalizeImages(s, limit)
+}
+
+// RedactSecrets replaces credentials found in text content with the
+// default redaction rules
+func RedactSecrets() ResultTransformer {
+	return upstream.Redact(nil)
+}
+
+// AnnotateProvenance records the upstream, tool and server that produced a
+// result in its `_meta`
+func AnnotateProvenance() ResultTransformer {
+	return upstream.Annotate()
+}
@@ -0,0 +1,56 @@
+package metamcp
+
+import (
+	"io"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/protocol/transport"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+)
+
+// Transport accepts clients for a Server
+type Transport = server.Transport
+
+// Stdio serves the parent process over stdin and stdout. It stops when the
+// parent closes stdin.
+func Stdio() Transport {
+	return server.Stdio()
+}
+
+// StdioStreams serves a single client speaking newline-delimited JSON-RPC
+// over in and out
+func StdioStreams(in io.Reader, out io.Writer) Transport {
+	return server.NewStdio(in, out)
+}
+
+// Socket serves clients on a Unix socket at path
+func Socket(path string) Transport {
+	return server.NewSocket("unix", path)
+}
+
+// TCP serves clients speaking newline-delimited JSON-RPC on a TCP address
+func TCP(addr string) Transport {
+	return server.NewSocket("tcp", addr)
+}
+
+// SSE serves clients over Server-Sent Events on addr. Browser origins
+// default to localhost.
+func SSE(addr string, origins ...string) Transport {
+	return server.NewSSE(httpConfig(addr, origins))
+}
+
+// WebSocket serves clients over WebSocket on addr at /ws. Browser origins
+// default to localhost.
+func WebSocket(addr string, origins ...string) Transport {
+	return server.NewWebSocket(httpConfig(addr, origins))
+}
+
+// httpConfig returns the configuration of an HTTP transport
+func httpConfig(addr string, origins []string) server.HTTPConfig {
+	config := server.HTTPConfig{Addr: addr}
+	if len(origins) > 0 {
+		cors := transport.DefaultCORSConfig()
+		cors.AllowedOrigins = origins
+		config.CORS = &cors
+	}
+	return config
+}
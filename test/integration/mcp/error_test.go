@@ -181,6 +181,7 @@ func TestTimeoutScenarios(t *testing.T) {
 	t.Run("HandshakeTimeout", func(t *testing.T) {
 		config := mcpmock.DefaultMockServerConfig()
 		config.HandshakeTimeout = 50 * time.Millisecond
+		config.Strict = true
 		server := mcpmock.NewMockServer(config)
 		defer server.Reset()
 
@@ -193,22 +194,13 @@ func TestTimeoutScenarios(t *testing.T) {
 			t.Fatalf("Failed to create connection: %v", err)
 		}
 
-		// Start the handshake timeout
-		conn, _ := server.GetConnectionManager().GetConnection(connID)
-		if conn != nil {
-			// This should start the timeout timer
-			_ = conn.StartHandshake(nil)
-		}
-
 		// Wait for timeout
 		time.Sleep(100 * time.Millisecond)
 
 		// Check if connection is closed
-		finalState := conn.GetState()
-		if finalState != connection.StateClosed {
-			// In the mock implementation, the connection might not auto-close
-			// This is acceptable behavior for a mock
-			t.Logf("Mock server connection state after timeout: %s (mock may not implement auto-close)", finalState)
+		conn, _ := server.GetConnectionManager().GetConnection(connID)
+		if finalState := conn.GetState(); finalState != connection.StateClosed {
+			t.Errorf("Expected connection to be closed after handshake timeout, got %s", finalState)
 		}
 	})
 
@@ -4,6 +4,7 @@ package mcp_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -132,9 +133,10 @@ func TestPreHandshakeRequestRejection(t *testing.T) {
 
 // TestHandshakeTimeout tests that handshake timeout is enforced.
 func TestHandshakeTimeout(t *testing.T) {
-	// Create server with very short timeout
+	// Create a strict server with very short timeout
 	config := mcpmock.DefaultMockServerConfig()
 	config.HandshakeTimeout = 50 * time.Millisecond
+	config.Strict = true
 	server := mcpmock.NewMockServer(config)
 	defer server.Reset()
 
@@ -147,32 +149,17 @@ func TestHandshakeTimeout(t *testing.T) {
 		t.Fatalf("Failed to create connection: %v", err)
 	}
 
-	// Get connection and start handshake
-	conn, _ := server.GetConnectionManager().GetConnection(connID)
-	if conn != nil {
-		// This should start the timeout timer
-		_ = conn.StartHandshake(nil)
-	}
-
 	// Wait for timeout
 	time.Sleep(100 * time.Millisecond)
 
-	// Try to send a request
-	_, err = server.SimulateClientMessage(ctx, connID, "tools/list", nil, "after-timeout")
-
-	// The mock server might still accept the request even if the connection should be timed out
-	// This is acceptable behavior for a mock implementation
-	if err != nil {
-		t.Logf("Request correctly rejected after timeout: %v", err)
+	// The connection is closed and rejects further requests
+	conn, _ := server.GetConnectionManager().GetConnection(connID)
+	if conn == nil || conn.GetState() != connection.StateClosed {
+		t.Fatalf("Expected connection to be closed after handshake timeout")
 	}
-
-	// Verify connection state
-	if conn != nil {
-		finalState := conn.GetState()
-		if finalState != connection.StateClosed {
-			// Mock server might not implement auto-close on timeout
-			t.Logf("Mock server connection state after timeout: %s (mock may not implement auto-close)", finalState)
-		}
+	_, err = server.SimulateClientMessage(ctx, connID, "tools/list", nil, "after-timeout")
+	if err == nil || !strings.Contains(err.Error(), "Handshake timeout") {
+		t.Errorf("Expected handshake timeout error, got %v", err)
 	}
 }
 
@@ -0,0 +1,153 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+	"github.com/meta-mcp/meta-mcp-server/test/testutil"
+)
+
+// echoTool is a tool definition that echoes its message argument.
+func echoTool(name string) tools.Definition {
+	return tools.Definition{
+		Tool: mcp.NewTool(name, mcp.WithString("message", mcp.Required())),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			message, err := request.RequireString("message")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(message), nil
+		},
+	}
+}
+
+// TestRealServerToolFlow tests the handshake, tool listing and tool calls
+// against the real server stack.
+func TestRealServerToolFlow(t *testing.T) {
+	ts := testutil.StartTestServer(t, testutil.TestServerOptions{
+		Name:  "integration",
+		Tools: []tools.Definition{echoTool("echo")},
+		Resources: []mcpserver.ServerResource{{
+			Resource: mcp.NewResource("test://readme", "readme"),
+			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "hello"}}, nil
+			},
+		}},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if got := ts.InitializeResult.ServerInfo.Name; got != "integration" {
+		t.Errorf("Expected server name integration, got %s", got)
+	}
+	if ts.InitializeResult.Capabilities.Tools == nil {
+		t.Error("Expected tool capabilities")
+	}
+
+	list, err := ts.Client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(list.Tools) != 1 || list.Tools[0].Name != "echo" {
+		t.Fatalf("Expected the echo tool, got %+v", list.Tools)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "echo"
+	request.Params.Arguments = map[string]any{"message": "hello"}
+	result, err := ts.Client.CallTool(ctx, request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text, ok := result.Content[0].(mcp.TextContent); !ok || text.Text != "hello" {
+		t.Errorf("Expected hello, got %+v", result.Content)
+	}
+
+	read := mcp.ReadResourceRequest{}
+	read.Params.URI = "test://readme"
+	contents, err := ts.Client.ReadResource(ctx, read)
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if text, ok := contents.Contents[0].(mcp.TextResourceContents); !ok || text.Text != "hello" {
+		t.Errorf("Expected hello, got %+v", contents.Contents)
+	}
+
+	// Unknown tools are errors from the real server, not empty results
+	request.Params.Name = "missing"
+	if _, err := ts.Client.CallTool(ctx, request); err == nil {
+		t.Error("Expected an error calling an unknown tool")
+	}
+}
+
+// TestRealServerListChanged tests that tools registered on a live
+// connection are announced to the client.
+func TestRealServerListChanged(t *testing.T) {
+	notifications := make(chan string, 10)
+	ts := testutil.StartTestServer(t, testutil.TestServerOptions{
+		OnNotification: func(notification mcp.JSONRPCNotification) {
+			notifications <- notification.Method
+		},
+	})
+
+	if err := ts.Tools.Register(echoTool("late")); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	select {
+	case method := <-notifications:
+		if method != "notifications/tools/list_changed" {
+			t.Errorf("Expected a tools list_changed notification, got %s", method)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for list_changed")
+	}
+}
+
+// TestRealServerNegotiatesVersion tests that the real server answers an
+// unsupported protocol version with its latest one.
+func TestRealServerNegotiatesVersion(t *testing.T) {
+	ts := testutil.StartTestServer(t, testutil.TestServerOptions{SkipInitialize: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if ts.InitializeResult != nil {
+		t.Error("Expected no initialize result")
+	}
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = "1999-01-01"
+	request.Params.ClientInfo = mcp.Implementation{Name: "old-client", Version: "0.1.0"}
+	result, err := ts.Client.Initialize(ctx, request)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if result.ProtocolVersion != mcp.LATEST_PROTOCOL_VERSION {
+		t.Errorf("Expected protocol version %s, got %s", mcp.LATEST_PROTOCOL_VERSION, result.ProtocolVersion)
+	}
+}
+
+// TestRealServerRequireInitialized tests that a client sending
+// notifications/initialized is served by a strict server.
+func TestRealServerRequireInitialized(t *testing.T) {
+	ts := testutil.StartTestServer(t, testutil.TestServerOptions{
+		Tools:              []tools.Definition{echoTool("echo")},
+		RequireInitialized: true,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ts.Client.Ping(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	list, err := ts.Client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(list.Tools) != 1 {
+		t.Errorf("Expected 1 tool, got %d", len(list.Tools))
+	}
+}
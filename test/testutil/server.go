@@ -0,0 +1,175 @@
+package testutil
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	protocolmcp "github.com/meta-mcp/meta-mcp-server/internal/protocol/mcp"
+	"github.com/meta-mcp/meta-mcp-server/internal/server"
+	"github.com/meta-mcp/meta-mcp-server/internal/tools"
+)
+
+// TestServerOptions configures the server started by StartTestServer.
+// The zero value starts a server with no tools and an initialized client.
+type TestServerOptions struct {
+	// Name and Version identify the server in the handshake.
+	Name    string
+	Version string
+
+	// Tools are registered in the server's tool registry before the
+	// client connects.
+	Tools []tools.Definition
+
+	// Resources and Prompts are added to the server before the client
+	// connects.
+	Resources []mcpserver.ServerResource
+	Prompts   []mcpserver.ServerPrompt
+
+	// ServerOptions are applied to the mcp-go server after the default
+	// tool, resource and prompt capabilities.
+	ServerOptions []mcpserver.ServerOption
+
+	// Config configures the session server (guard, limits, faults, ...).
+	Config server.Config
+
+	// ProtocolVersion is proposed by the client. It defaults to the latest
+	// version.
+	ProtocolVersion string
+
+	// SkipInitialize leaves the client connected but before the handshake.
+	SkipInitialize bool
+
+	// OnNotification receives every notification the server sends.
+	OnNotification func(mcp.JSONRPCNotification)
+
+	// RequireInitialized makes the server reject requests until the client
+	// sends notifications/initialized.
+	RequireInitialized bool
+}
+
+// TestServer is a real server serving one in-memory connection, and the
+// client connected to it.
+type TestServer struct {
+	// Client is connected to the server, and initialized unless
+	// SkipInitialize was set.
+	Client *client.Client
+
+	// InitializeResult is the server's handshake response, or nil when
+	// SkipInitialize was set.
+	InitializeResult *mcp.InitializeResult
+
+	// MCP is the handshake server, which owns the connection manager.
+	MCP *protocolmcp.HandshakeServer
+
+	// Server serves the connection.
+	Server *server.Server
+
+	// Tools is the server's tool registry. Tools registered while the
+	// client is connected are announced with list_changed notifications.
+	Tools *tools.Registry
+
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// StartTestServer starts the real server stack (handshake server,
+// connection manager, session server and tool registry) on an in-memory
+// pipe and returns a connected client. The server is stopped when the test
+// ends.
+func StartTestServer(t *testing.T, opts TestServerOptions) *TestServer {
+	t.Helper()
+
+	hsConfig := protocolmcp.DefaultHandshakeConfig()
+	if opts.Name != "" {
+		hsConfig.Name = opts.Name
+	}
+	if opts.Version != "" {
+		hsConfig.Version = opts.Version
+	}
+	hsConfig.SupportedVersions = mcp.ValidProtocolVersions
+	hsConfig.RequireInitialized = opts.RequireInitialized
+	hsConfig.ServerOptions = append([]mcpserver.ServerOption{
+		protocolmcp.WithToolCapabilities(true),
+		protocolmcp.WithResourceCapabilities(true, true),
+		mcpserver.WithPromptCapabilities(true),
+		protocolmcp.WithRecovery(),
+	}, opts.ServerOptions...)
+	hs := protocolmcp.NewHandshakeServer(hsConfig)
+
+	registry := tools.New(tools.Config{Server: hs})
+	for _, def := range opts.Tools {
+		if err := registry.Register(def); err != nil {
+			t.Fatalf("Failed to register tool %s: %v", def.Tool.Name, err)
+		}
+	}
+	if len(opts.Resources) > 0 {
+		hs.AddResources(opts.Resources...)
+	}
+	if len(opts.Prompts) > 0 {
+		hs.AddPrompts(opts.Prompts...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := &TestServer{
+		MCP:    hs,
+		Server: server.New(hs, opts.Config),
+		Tools:  registry,
+		cancel: cancel,
+		done:   make(chan error, 1),
+	}
+
+	clientConn, serverConn := net.Pipe()
+	go func() {
+		ts.done <- ts.Server.ServeConn(ctx, "test", server.NewLineConn(serverConn, serverConn, serverConn))
+	}()
+
+	ts.Client = client.NewClient(transport.NewIO(clientConn, clientConn, io.NopCloser(strings.NewReader(""))))
+	t.Cleanup(ts.Close)
+	if opts.OnNotification != nil {
+		ts.Client.OnNotification(opts.OnNotification)
+	}
+
+	startCtx, stop := context.WithTimeout(ctx, 10*time.Second)
+	defer stop()
+	if err := ts.Client.Start(startCtx); err != nil {
+		t.Fatalf("Failed to start test client: %v", err)
+	}
+	if opts.SkipInitialize {
+		return ts
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = opts.ProtocolVersion
+	if request.Params.ProtocolVersion == "" {
+		request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	}
+	request.Params.ClientInfo = mcp.Implementation{Name: "testutil", Version: "1.0.0"}
+	result, err := ts.Client.Initialize(startCtx, request)
+	if err != nil {
+		t.Fatalf("Failed to initialize test client: %v", err)
+	}
+	ts.InitializeResult = result
+	return ts
+}
+
+// Close disconnects the client and stops the server. It is safe to call
+// more than once.
+func (ts *TestServer) Close() {
+	ts.Client.Close()
+	ts.cancel()
+	select {
+	case err := <-ts.done:
+		// Keep the result for a later Close
+		ts.done <- err
+	case <-time.After(5 * time.Second):
+	}
+}